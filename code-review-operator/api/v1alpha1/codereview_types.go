@@ -0,0 +1,741 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CodeReviewPhase is the lifecycle phase of a CodeReview
+type CodeReviewPhase string
+
+const (
+	// CodeReviewPhasePending means the review has not started yet
+	CodeReviewPhasePending CodeReviewPhase = "Pending"
+
+	// CodeReviewPhaseInProgress means the review is running
+	CodeReviewPhaseInProgress CodeReviewPhase = "InProgress"
+
+	// CodeReviewPhaseCompleted means the review was posted
+	CodeReviewPhaseCompleted CodeReviewPhase = "Completed"
+
+	// CodeReviewPhaseFailed means the review could not be completed
+	CodeReviewPhaseFailed CodeReviewPhase = "Failed"
+
+	// CodeReviewPhaseSuperseded means a review of a newer commit replaced this one
+	CodeReviewPhaseSuperseded CodeReviewPhase = "Superseded"
+
+	// CodeReviewPhaseTimedOut means the review exceeded its deadline. Any
+	// findings gathered before the deadline were posted as a partial review.
+	CodeReviewPhaseTimedOut CodeReviewPhase = "TimedOut"
+
+	// CodeReviewPhaseSkipped means a developer asked to skip the review
+	CodeReviewPhaseSkipped CodeReviewPhase = "Skipped"
+
+	// CodeReviewPhaseCancelled means an operator cancelled the review
+	// through the queue API
+	CodeReviewPhaseCancelled CodeReviewPhase = "Cancelled"
+)
+
+// CriticalLabel marks a CodeReview of a critical repository. Such reviews run
+// in multi-model consensus mode when the operator has a consensus model configured.
+const CriticalLabel = "review.code-review.io/critical"
+
+// RequestedByAnnotation holds the login of the developer who requested a
+// CodeReview with a slash command in a pull request comment
+const RequestedByAnnotation = "review.code-review.io/requested-by"
+
+// PriorityAnnotation holds the priority of a queued CodeReview, an integer.
+// Reviews of higher priority are admitted first when slots free up; the
+// default is 0.
+const PriorityAnnotation = "review.code-review.io/priority"
+
+// Condition types reported on a CodeReview
+const (
+	// ConditionConfigDrift is true when the spec and the in-repo configuration disagree
+	ConditionConfigDrift = "ConfigDrift"
+
+	// ConditionBudgetExceeded is true while the review is paused because a
+	// ReviewBudget covering it has used its monthly tokens
+	ConditionBudgetExceeded = "BudgetExceeded"
+
+	// ConditionLLMUnavailable is true while the review waits for its LLM
+	// backend, which failed repeatedly and is paused by its circuit breaker
+	ConditionLLMUnavailable = "LLMUnavailable"
+
+	// ConditionCRDVersionSkew is true while the review is held because the
+	// installed CRDs do not match the operator and it runs read-only
+	ConditionCRDVersionSkew = "CRDVersionSkew"
+)
+
+// SecretKeyReference references a key of a Secret in the CodeReview's namespace
+type SecretKeyReference struct {
+	// Name is the name of the Secret
+	Name string `json:"name"`
+
+	// Key is the key within the Secret
+	Key string `json:"key"`
+}
+
+// Authentication types of provider credentials
+const (
+	// AuthTypeToken authenticates with the token held in the referenced key
+	AuthTypeToken = "token"
+
+	// AuthTypeGitHubApp authenticates as a GitHub App installation: the
+	// referenced key holds the app's PEM encoded private key, and the app-id
+	// and installation-id keys of the Secret the app and installation IDs
+	AuthTypeGitHubApp = "githubApp"
+
+	// AuthTypeOAuth2 authenticates to GitLab with OAuth2 access tokens
+	// refreshed with the refresh token held in the referenced key, which is
+	// replaced as GitLab rotates it, and the client-id and client-secret keys
+	// of the Secret the OAuth2 application's credentials. The Secret must be
+	// labeled review.code-review.io/refresh-token=true.
+	AuthTypeOAuth2 = "oauth2"
+)
+
+// TokenSecretReference references the key of a Secret in the CodeReview's
+// namespace holding the provider credentials, and how they authenticate
+type TokenSecretReference struct {
+	SecretKeyReference `json:",inline"`
+
+	// Type is how the credentials authenticate to the provider
+	// +kubebuilder:validation:Enum=token;githubApp;oauth2
+	// +kubebuilder:default=token
+	// +optional
+	Type string `json:"type,omitempty"`
+}
+
+// AuthType returns the authentication type of the credentials, defaulting
+// to AuthTypeToken
+func (r TokenSecretReference) AuthType() string {
+	if r.Type == "" {
+		return AuthTypeToken
+	}
+
+	return r.Type
+}
+
+// ConfigMapKeyReference references a key of a ConfigMap in the CodeReview's namespace
+type ConfigMapKeyReference struct {
+	// Name is the name of the ConfigMap
+	Name string `json:"name"`
+
+	// Key is the key within the ConfigMap
+	Key string `json:"key"`
+}
+
+// GatingPolicy controls how review findings gate a pull request
+type GatingPolicy struct {
+	// FailOn is the minimum severity that fails the review
+	// +kubebuilder:validation:Enum=critical;major;minor;suggestion
+	// +optional
+	FailOn string `json:"failOn,omitempty"`
+
+	// MaxFindings is the number of findings at or above FailOn tolerated before failing
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxFindings *int `json:"maxFindings,omitempty"`
+}
+
+// ReviewSettings holds the settings that control a review
+type ReviewSettings struct {
+	// Rules is the list of review rules to apply
+	// +optional
+	Rules []string `json:"rules,omitempty"`
+
+	// SeverityLevels is the list of severity levels to report
+	// +optional
+	SeverityLevels []string `json:"severityLevels,omitempty"`
+
+	// Include is a list of path globs to review; when set, other files are
+	// not reviewed
+	// +optional
+	Include []string `json:"include,omitempty"`
+
+	// Exclude is a list of path globs that should not be reviewed
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+
+	// DefaultExcludes controls whether vendored dependencies, lockfiles and
+	// generated code are skipped (defaults to true)
+	// +optional
+	DefaultExcludes *bool `json:"defaultExcludes,omitempty"`
+
+	// Tone controls the writing style of review comments
+	// +kubebuilder:validation:Enum=neutral;friendly;concise;strict
+	// +optional
+	Tone string `json:"tone,omitempty"`
+
+	// Glossary maps internal terms, service names and acronyms to their
+	// meaning. Terms are combined with the org-wide and in-repo glossaries.
+	// +optional
+	Glossary map[string]string `json:"glossary,omitempty"`
+
+	// ModelTier selects the class of model used for the review
+	// +kubebuilder:validation:Enum=economy;standard;premium
+	// +optional
+	ModelTier string `json:"modelTier,omitempty"`
+
+	// Gating controls whether findings block the pull request
+	// +optional
+	Gating *GatingPolicy `json:"gating,omitempty"`
+
+	// Language is a hint naming the main language of the code
+	// +optional
+	Language string `json:"language,omitempty"`
+
+	// MaxComments is the maximum number of inline comments posted per
+	// review; the remaining findings are listed in the summary
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxComments *int `json:"maxComments,omitempty"`
+
+	// MinSeverity is the least severe finding posted; less severe findings
+	// are dropped
+	// +kubebuilder:validation:Enum=critical;major;minor;suggestion
+	// +optional
+	MinSeverity string `json:"minSeverity,omitempty"`
+
+	// DisabledRules lists rules, or globs matching them, whose findings are
+	// dropped
+	// +optional
+	DisabledRules []string `json:"disabledRules,omitempty"`
+}
+
+// PublisherSpec selects a destination for review results
+type PublisherSpec struct {
+	// Type is the kind of destination
+	// +kubebuilder:validation:Enum=pullRequest;checkRun;commitStatus
+	Type string `json:"type"`
+}
+
+// CodeReviewSpec defines the desired state of CodeReview
+type CodeReviewSpec struct {
+	// Provider is the Git provider hosting the repository
+	// +kubebuilder:validation:Enum=github;gitlab
+	Provider string `json:"provider"`
+
+	// Owner is the owner/organization of the repository
+	Owner string `json:"owner"`
+
+	// Repository is the name of the repository
+	Repository string `json:"repository"`
+
+	// PullRequest is the number of the pull request to review
+	// +optional
+	PullRequest int `json:"pullRequest,omitempty"`
+
+	// CommitSHA is the head commit being reviewed
+	// +optional
+	CommitSHA string `json:"commitSHA,omitempty"`
+
+	// Author is the login of the pull request author, used to recall what the
+	// reviewer remembers about the author's previous pull requests
+	// +optional
+	Author string `json:"author,omitempty"`
+
+	// TokenSecretRef references the Secret key holding the provider
+	// credentials. Defaults to the credentials of the review's ReviewerConfig.
+	// +optional
+	TokenSecretRef *TokenSecretReference `json:"tokenSecretRef,omitempty"`
+
+	// ReviewerConfig is the name of the ReviewerConfig in the review's
+	// namespace supplying its defaults. Defaults to the one named default,
+	// if any.
+	// +optional
+	ReviewerConfig string `json:"reviewerConfig,omitempty"`
+
+	// Review holds the review settings. Settings given here take precedence
+	// over the repository's .ai-review.yaml and the org-wide defaults.
+	// +optional
+	Review *ReviewSettings `json:"review,omitempty"`
+
+	// Publishers lists where the review results are published. Defaults to
+	// a review on the pull request.
+	// +optional
+	Publishers []PublisherSpec `json:"publishers,omitempty"`
+
+	// Decision decides whether the posted review approves the pull request,
+	// requests changes, or only comments. Reviews only comment when unset.
+	// +optional
+	Decision *DecisionPolicy `json:"decision,omitempty"`
+
+	// ReportProgress shows the review's progress in a check run on the head
+	// commit while it runs. The check run is replaced by the final review.
+	// +optional
+	ReportProgress bool `json:"reportProgress,omitempty"`
+
+	// LLM selects the LLM backend for this review. Defaults to the backend
+	// the operator was started with.
+	// +optional
+	LLM *LLMSpec `json:"llm,omitempty"`
+
+	// Prompt is the name of a ReviewPrompt in the review's namespace whose
+	// templates replace the built-in review prompts. Only backends that build
+	// prompts themselves use it, not the bundled LLM service.
+	// +optional
+	Prompt string `json:"prompt,omitempty"`
+
+	// ActiveDeadlineSeconds is how long the review may run, counted from its
+	// start, before it is stopped and marked TimedOut. Findings gathered by
+	// then are posted as a partial review.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// Incremental reviews only the changes pushed since the last completed
+	// review of the pull request, so earlier findings are not posted again.
+	// The whole pull request is reviewed if there is no earlier review or its
+	// commit no longer exists.
+	// +optional
+	Incremental bool `json:"incremental,omitempty"`
+
+	// BypassCache asks the LLM backend even if it reviewed the same diff with
+	// the same settings before, instead of reusing its previous review
+	// +optional
+	BypassCache bool `json:"bypassCache,omitempty"`
+
+	// DryRun runs the whole review but posts nothing: the comments and
+	// summary it would have posted are written to status.dryRun instead
+	// +optional
+	DryRun *DryRunSpec `json:"dryRun,omitempty"`
+
+	// Report exports the findings of the review as a JSON report and a SARIF
+	// log, for archiving or uploading to code scanning
+	// +optional
+	Report *ReportSpec `json:"report,omitempty"`
+
+	// Notifications lists where the outcome of the review is announced: a
+	// severity breakdown and a link when it is posted, and the error when
+	// it fails or times out. Dry runs notify nothing.
+	// +optional
+	Notifications []NotificationSpec `json:"notifications,omitempty"`
+
+	// Diff is a pre-computed unified diff reviewed instead of the diff
+	// fetched from the provider, such as an artifact of the CI build
+	// +optional
+	Diff *DiffSource `json:"diff,omitempty"`
+}
+
+// DiffSource locates a pre-computed unified diff. Exactly one of url,
+// urlSecretRef, configMapKeyRef and secretKeyRef is set. Diffs may be
+// gzip-compressed.
+// +kubebuilder:validation:XValidation:rule="[has(self.url), has(self.urlSecretRef), has(self.configMapKeyRef), has(self.secretKeyRef)].filter(x, x).size() == 1",message="exactly one of url, urlSecretRef, configMapKeyRef and secretKeyRef must be set"
+type DiffSource struct {
+	// URL is the http or https URL the diff is downloaded from
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// URLSecretRef references the Secret key holding the URL, for URLs
+	// carrying credentials such as pre-signed URLs
+	// +optional
+	URLSecretRef *SecretKeyReference `json:"urlSecretRef,omitempty"`
+
+	// ConfigMapKeyRef references the ConfigMap key holding the diff, in its
+	// data or binaryData
+	// +optional
+	ConfigMapKeyRef *ConfigMapKeyReference `json:"configMapKeyRef,omitempty"`
+
+	// SecretKeyRef references the Secret key holding the diff
+	// +optional
+	SecretKeyRef *SecretKeyReference `json:"secretKeyRef,omitempty"`
+
+	// SHA256 is the hex SHA-256 digest of the diff as stored. The review
+	// fails if the diff does not match it.
+	// +kubebuilder:validation:Pattern=`^[0-9a-f]{64}$`
+	// +optional
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// NotificationSpec configures a notification sink
+type NotificationSpec struct {
+	// Type is the kind of sink. webhook posts the outcome as JSON.
+	// +kubebuilder:validation:Enum=slack;teams;webhook
+	Type string `json:"type"`
+
+	// URLSecretRef references the Secret key holding the webhook URL
+	URLSecretRef SecretKeyReference `json:"urlSecretRef"`
+
+	// On lists the outcomes notified (defaults to completed and failed)
+	// +optional
+	On []NotificationEvent `json:"on,omitempty"`
+}
+
+// NotificationEvent is a review outcome that is notified
+// +kubebuilder:validation:Enum=completed;failed
+type NotificationEvent string
+
+// ReportSpec configures the report a review exports
+type ReportSpec struct {
+	// ConfigMap is the name of a ConfigMap in the review's namespace the
+	// report is written to, under report.json and report.sarif
+	// +kubebuilder:validation:MinLength=1
+	ConfigMap string `json:"configMap"`
+
+	// Formats are the formats the report is written in (defaults to json and sarif)
+	// +optional
+	Formats []ReportFormat `json:"formats,omitempty"`
+}
+
+// ReportFormat is the format of an exported report
+// +kubebuilder:validation:Enum=json;sarif
+type ReportFormat string
+
+// DryRunSpec configures a review that is rendered instead of posted
+type DryRunSpec struct {
+	// ConfigMap is the name of a ConfigMap in the review's namespace the
+	// full rendered review is also written to, for reviews with more
+	// comments than the status holds
+	// +optional
+	ConfigMap string `json:"configMap,omitempty"`
+}
+
+// DecisionPolicy decides the event a review is posted with
+type DecisionPolicy struct {
+	// ApproveWhenClean approves the pull request when the review has no findings
+	// +optional
+	ApproveWhenClean bool `json:"approveWhenClean,omitempty"`
+
+	// RequestChangesOn is the minimum severity that counts towards requesting
+	// changes. Changes are never requested when unset.
+	// +kubebuilder:validation:Enum=critical;major;minor;suggestion
+	// +optional
+	RequestChangesOn string `json:"requestChangesOn,omitempty"`
+
+	// MaxFindings is the number of findings at or above RequestChangesOn
+	// tolerated before requesting changes
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxFindings int `json:"maxFindings,omitempty"`
+}
+
+// LLMSpec selects and configures an LLM backend
+type LLMSpec struct {
+	// Provider is the LLM backend. vertex, bedrock and azure-openai
+	// authenticate with the operator's workload identity.
+	// +kubebuilder:validation:Enum=http;openai;anthropic;ollama;local;vertex;bedrock;azure-openai
+	Provider string `json:"provider"`
+
+	// Endpoint is the service URL or API base URL. Defaults to the
+	// provider's public API where it has one.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Model is the model name, or the deployment name for azure-openai
+	// +optional
+	Model string `json:"model,omitempty"`
+
+	// Region is the cloud region of the vertex and bedrock providers
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// Project is the Google Cloud project of the vertex provider
+	// +optional
+	Project string `json:"project,omitempty"`
+
+	// ContextWindow is the model's context window in tokens, used by the
+	// ollama and local providers
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ContextWindow int `json:"contextWindow,omitempty"`
+
+	// APIKeySecretRef references the Secret key holding the API key
+	// +optional
+	APIKeySecretRef *SecretKeyReference `json:"apiKeySecretRef,omitempty"`
+}
+
+// EffectiveConfig records the settings that were applied to a review
+type EffectiveConfig struct {
+	ReviewSettings `json:",inline"`
+
+	// Sources maps each applied setting to where it came from (default, org, repo, spec)
+	// +optional
+	Sources map[string]string `json:"sources,omitempty"`
+}
+
+// ConfigConflict describes a setting where the spec overrode a different in-repo value
+type ConfigConflict struct {
+	// Field is the name of the conflicting setting
+	Field string `json:"field"`
+
+	// SpecValue is the value from the CodeReview spec, which was applied
+	SpecValue string `json:"specValue"`
+
+	// RepoValue is the value from the in-repo configuration, which was ignored
+	RepoValue string `json:"repoValue"`
+}
+
+// CodeReviewStatus defines the observed state of CodeReview
+type CodeReviewStatus struct {
+	// Phase is the lifecycle phase of the review
+	// +optional
+	Phase CodeReviewPhase `json:"phase,omitempty"`
+
+	// ReviewURL is the URL of the posted review
+	// +optional
+	ReviewURL string `json:"reviewURL,omitempty"`
+
+	// ReviewedSHA is the head commit the review covers
+	// +optional
+	ReviewedSHA string `json:"reviewedSHA,omitempty"`
+
+	// BaseSHA is the commit an incremental review compared against, empty if
+	// the whole pull request was reviewed
+	// +optional
+	BaseSHA string `json:"baseSHA,omitempty"`
+
+	// SupersededBy is the name of the review that replaced this one
+	// +optional
+	SupersededBy string `json:"supersededBy,omitempty"`
+
+	// Decision is the event the review was posted with (COMMENT, APPROVE or REQUEST_CHANGES)
+	// +optional
+	Decision string `json:"decision,omitempty"`
+
+	// CommentCount is the number of comments posted
+	// +optional
+	CommentCount int `json:"commentCount,omitempty"`
+
+	// LintOnly is set when no LLM backend was available and the review
+	// posted only the findings of the deterministic checks
+	// +optional
+	LintOnly bool `json:"lintOnly,omitempty"`
+
+	// Usage is the LLM tokens the review used and their estimated cost
+	// +optional
+	Usage *TokenUsage `json:"usage,omitempty"`
+
+	// DryRun is the review a dry run would have posted
+	// +optional
+	DryRun *DryRunResult `json:"dryRun,omitempty"`
+
+	// Report is the name of the ConfigMap holding the review's report
+	// +optional
+	Report string `json:"report,omitempty"`
+
+	// EffectiveConfig is the configuration that was applied to the review
+	// +optional
+	EffectiveConfig *EffectiveConfig `json:"effectiveConfig,omitempty"`
+
+	// ConfigGeneration identifies the version of the operator configuration
+	// and prompt templates the review ran with
+	// +optional
+	ConfigGeneration string `json:"configGeneration,omitempty"`
+
+	// ConfigConflicts lists settings where the spec and in-repo configuration disagree
+	// +optional
+	ConfigConflicts []ConfigConflict `json:"configConflicts,omitempty"`
+
+	// Compliance attests the compliance mode settings the review ran under;
+	// unset when compliance mode is off
+	// +optional
+	Compliance *ComplianceAttestation `json:"compliance,omitempty"`
+
+	// Provenance records what produced the posted review
+	// +optional
+	Provenance *ReviewProvenance `json:"provenance,omitempty"`
+
+	// Progress is how far the review got: the files reviewed and the
+	// findings the LLM reported so far, updated while the review runs
+	// +optional
+	Progress *ReviewProgress `json:"progress,omitempty"`
+
+	// StartTime is when the review started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the review finished
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Conditions represent the latest available observations of the review
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// DryRunResult is the review a dry run would have posted
+type DryRunResult struct {
+	// Summary is the review summary
+	// +optional
+	Summary string `json:"summary,omitempty"`
+
+	// Comments are the inline comments, up to the first 50
+	// +optional
+	Comments []RenderedComment `json:"comments,omitempty"`
+
+	// OmittedComments is the number of comments left out of Comments
+	// +optional
+	OmittedComments int `json:"omittedComments,omitempty"`
+
+	// ConfigMap is the name of the ConfigMap holding the full rendered review
+	// +optional
+	ConfigMap string `json:"configMap,omitempty"`
+}
+
+// RenderedComment is an inline comment a dry run would have posted
+type RenderedComment struct {
+	// File is the path of the commented file
+	File string `json:"file"`
+
+	// Line is the commented line, or the last line of a range
+	Line int `json:"line"`
+
+	// StartLine is the first line of a range, 0 for a single line
+	// +optional
+	StartLine int `json:"startLine,omitempty"`
+
+	// Side is LEFT for lines of the old version of the file
+	// +optional
+	Side string `json:"side,omitempty"`
+
+	// Severity is the severity of the finding
+	// +optional
+	Severity string `json:"severity,omitempty"`
+
+	// Rule is the rule the finding was reported under
+	// +optional
+	Rule string `json:"rule,omitempty"`
+
+	// Language is the language of the commented file
+	// +optional
+	Language string `json:"language,omitempty"`
+
+	// Content is the text of the comment
+	Content string `json:"content"`
+
+	// SuggestedCode is the replacement suggested for the commented lines
+	// +optional
+	SuggestedCode string `json:"suggestedCode,omitempty"`
+}
+
+// ReviewProgress records how far a review got
+type ReviewProgress struct {
+	// FilesReviewed is the number of files of the diff reviewed so far
+	FilesReviewed int `json:"filesReviewed"`
+
+	// FilesTotal is the number of files in the diff
+	FilesTotal int `json:"filesTotal"`
+
+	// Comments is the number of findings the LLM reported so far, before
+	// they are filtered and merged
+	Comments int `json:"comments"`
+
+	// LastUpdateTime is when the progress was recorded
+	// +optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// TokenUsage records the LLM tokens a review used
+type TokenUsage struct {
+	// PromptTokens is the number of tokens sent to the LLM
+	// +optional
+	PromptTokens int64 `json:"promptTokens,omitempty"`
+
+	// CompletionTokens is the number of tokens the LLM generated
+	// +optional
+	CompletionTokens int64 `json:"completionTokens,omitempty"`
+
+	// TotalTokens is the number of tokens used, including any the LLM
+	// backend did not break down
+	TotalTokens int64 `json:"totalTokens"`
+
+	// EstimatedCost is the estimated cost in US dollars, such as "0.0123"
+	// +optional
+	EstimatedCost string `json:"estimatedCost,omitempty"`
+}
+
+// ComplianceAttestation records the compliance settings a review ran under
+type ComplianceAttestation struct {
+	// MinTLSVersion is the lowest TLS version allowed for connections
+	MinTLSVersion string `json:"minTLSVersion"`
+
+	// CipherSuites are the TLS 1.2 cipher suites allowed
+	CipherSuites []string `json:"cipherSuites,omitempty"`
+
+	// FIPSModule reports whether the operator was built with a FIPS 140 validated crypto module
+	FIPSModule bool `json:"fipsModule"`
+
+	// LLMProvider is the LLM backend the review used
+	// +optional
+	LLMProvider string `json:"llmProvider,omitempty"`
+
+	// AllowedLLMProviders are the LLM backends reviews may use
+	// +optional
+	AllowedLLMProviders []string `json:"allowedLLMProviders,omitempty"`
+}
+
+// ReviewProvenance records the system and configuration that produced a review
+type ReviewProvenance struct {
+	// OperatorVersion is the version of the operator that ran the review
+	OperatorVersion string `json:"operatorVersion"`
+
+	// Models are the backends and models that reviewed the diff
+	// +optional
+	Models []string `json:"models,omitempty"`
+
+	// PromptHash is the SHA-256 hash of the prompt templates and the
+	// settings shaping the prompt
+	// +optional
+	PromptHash string `json:"promptHash,omitempty"`
+
+	// SummaryDigest is the SHA-256 digest of the posted summary
+	SummaryDigest string `json:"summaryDigest"`
+
+	// KeyID identifies the key the provenance was signed with; unset when
+	// reviews are not signed
+	// +optional
+	KeyID string `json:"keyID,omitempty"`
+
+	// Attestation is the signed DSSE envelope attached to the posted
+	// summary, base64 encoded
+	// +optional
+	Attestation string `json:"attestation,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Repository",type=string,JSONPath=`.spec.repository`
+// +kubebuilder:printcolumn:name="PR",type=integer,JSONPath=`.spec.pullRequest`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Files Reviewed",type=integer,JSONPath=`.status.progress.filesReviewed`,priority=1
+// +kubebuilder:printcolumn:name="Findings",type=integer,JSONPath=`.status.progress.comments`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// CodeReview is the Schema for the codereviews API
+type CodeReview struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CodeReviewSpec   `json:"spec,omitempty"`
+	Status CodeReviewStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CodeReviewList contains a list of CodeReview
+type CodeReviewList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CodeReview `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CodeReview{}, &CodeReviewList{})
+}
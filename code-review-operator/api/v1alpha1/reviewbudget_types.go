@@ -0,0 +1,91 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition types reported on a ReviewBudget
+const (
+	// ConditionExceeded is true when the budget has used its monthly tokens
+	ConditionExceeded = "Exceeded"
+)
+
+// ReviewBudgetSpec defines the monthly token limit of a ReviewBudget
+type ReviewBudgetSpec struct {
+	// Repository limits the budget to the reviews of one repository, as
+	// owner/name. The budget covers every review in its namespace when empty.
+	// +kubebuilder:validation:Pattern=`^[^/]+/[^/]+$`
+	// +optional
+	Repository string `json:"repository,omitempty"`
+
+	// MonthlyTokens is the number of LLM tokens reviews may use per calendar
+	// month (UTC). Reviews are paused once it is used up.
+	// +kubebuilder:validation:Minimum=1
+	MonthlyTokens int64 `json:"monthlyTokens"`
+}
+
+// ReviewBudgetStatus defines the observed state of ReviewBudget
+type ReviewBudgetStatus struct {
+	// Period is the month the usage counts, as YYYY-MM
+	// +optional
+	Period string `json:"period,omitempty"`
+
+	// TokensUsed is the number of tokens reviews used in the period
+	// +optional
+	TokensUsed int64 `json:"tokensUsed,omitempty"`
+
+	// EstimatedCost is the estimated cost of the period in US dollars
+	// +optional
+	EstimatedCost string `json:"estimatedCost,omitempty"`
+
+	// Conditions represent the latest available observations of the budget
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Repository",type=string,JSONPath=`.spec.repository`
+// +kubebuilder:printcolumn:name="Limit",type=integer,JSONPath=`.spec.monthlyTokens`
+// +kubebuilder:printcolumn:name="Used",type=integer,JSONPath=`.status.tokensUsed`
+// +kubebuilder:printcolumn:name="Exceeded",type=string,JSONPath=`.status.conditions[?(@.type=="Exceeded")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ReviewBudget is the Schema for the reviewbudgets API. It caps the LLM
+// tokens the reviews in its namespace, or of one repository, use per month.
+type ReviewBudget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ReviewBudgetSpec   `json:"spec,omitempty"`
+	Status ReviewBudgetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ReviewBudgetList contains a list of ReviewBudget
+type ReviewBudgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReviewBudget `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ReviewBudget{}, &ReviewBudgetList{})
+}
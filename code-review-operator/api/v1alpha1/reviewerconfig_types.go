@@ -0,0 +1,78 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultReviewerConfig is the name of the ReviewerConfig applying to the
+// reviews of its namespace that do not name one
+const DefaultReviewerConfig = "default"
+
+// ReviewerConfigSpec defines the credentials and backends a team's reviews use
+type ReviewerConfigSpec struct {
+	// Repositories lists the repositories the reviews may review, as
+	// owner/name globs such as example-org/payments-*. Reviews of other
+	// repositories fail. Every repository may be reviewed when empty.
+	// +optional
+	Repositories []string `json:"repositories,omitempty"`
+
+	// TokenSecretRef references the Secret key holding the provider
+	// credentials of reviews that do not reference any
+	// +optional
+	TokenSecretRef *TokenSecretReference `json:"tokenSecretRef,omitempty"`
+
+	// LLM selects the LLM backend of reviews that do not select one, in place
+	// of the operator's backends
+	// +optional
+	LLM *LLMSpec `json:"llm,omitempty"`
+
+	// Notifications lists sinks notified of the outcome of every review, in
+	// addition to the review's own
+	// +optional
+	Notifications []NotificationSpec `json:"notifications,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Repositories",type=string,JSONPath=`.spec.repositories`
+// +kubebuilder:printcolumn:name="LLM",type=string,JSONPath=`.spec.llm.provider`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ReviewerConfig is the Schema for the reviewerconfigs API. It configures
+// the provider token, LLM backend and notifications of a team's reviews. A
+// CodeReview uses the ReviewerConfig of its namespace it names, or else the
+// one named default; token budgets are set per namespace with ReviewBudgets.
+type ReviewerConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ReviewerConfigSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ReviewerConfigList contains a list of ReviewerConfig
+type ReviewerConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReviewerConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ReviewerConfig{}, &ReviewerConfigList{})
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition types reported on a ReviewPrompt
+const (
+	// ConditionValid is true when the prompt templates parse and render
+	ConditionValid = "Valid"
+)
+
+// PromptPreview requests a rendering of the templates against a sample diff
+type PromptPreview struct {
+	// Diff is a sample unified diff the templates are rendered against
+	// +kubebuilder:validation:MinLength=1
+	Diff string `json:"diff"`
+
+	// Rules are sample review rules
+	// +optional
+	Rules []string `json:"rules,omitempty"`
+
+	// Language is a sample language hint
+	// +optional
+	Language string `json:"language,omitempty"`
+}
+
+// ReviewPromptSpec defines the prompt templates of a ReviewPrompt
+// +kubebuilder:validation:XValidation:rule="has(self.system) || has(self.user)",message="at least one of system or user must be set"
+type ReviewPromptSpec struct {
+	// System is a Go text/template template of the system prompt. The
+	// instructions describing the response format are always appended.
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	System string `json:"system,omitempty"`
+
+	// User is a Go text/template template of the user message
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	User string `json:"user,omitempty"`
+
+	// Preview renders the templates against a sample diff into the status
+	// +optional
+	Preview *PromptPreview `json:"preview,omitempty"`
+}
+
+// RenderedPrompt holds prompts rendered from the templates
+type RenderedPrompt struct {
+	// System is the rendered system prompt
+	System string `json:"system"`
+
+	// User is the rendered user message
+	User string `json:"user"`
+}
+
+// ReviewPromptStatus defines the observed state of ReviewPrompt
+type ReviewPromptStatus struct {
+	// ObservedGeneration is the generation the status was computed for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Preview is the rendering requested in spec.preview
+	// +optional
+	Preview *RenderedPrompt `json:"preview,omitempty"`
+
+	// Conditions represent the latest available observations of the prompt
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Valid",type=string,JSONPath=`.status.conditions[?(@.type=="Valid")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ReviewPrompt is the Schema for the reviewprompts API. CodeReviews select
+// one by name to replace the built-in review prompts.
+type ReviewPrompt struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ReviewPromptSpec   `json:"spec,omitempty"`
+	Status ReviewPromptStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ReviewPromptList contains a list of ReviewPrompt
+type ReviewPromptList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReviewPrompt `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ReviewPrompt{}, &ReviewPromptList{})
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/history"
+)
+
+// listHistory implements the history command
+func listHistory(args []string) error {
+	var server, file, repository, output string
+	var number, limit int
+	var since time.Duration
+	flags := flag.NewFlagSet("history", flag.ContinueOnError)
+	flags.StringVar(&server, "server", "", "URL of the operator's review history API.")
+	flags.StringVar(&file, "file", "", "Read a review history file written with --history-file instead of the API.")
+	flags.StringVar(&repository, "repo", "", "Only list reviews of this repository, as owner/name.")
+	flags.IntVar(&number, "pr", 0, "Only list reviews of this pull request.")
+	flags.DurationVar(&since, "since", 0, "Only list reviews completed within this duration, such as 720h.")
+	flags.IntVar(&limit, "limit", history.DefaultLimit, "Maximum number of reviews listed.")
+	flags.StringVar(&output, "output", "text", "Output format: text or json.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if output != "text" && output != "json" {
+		return fmt.Errorf("unknown output format %q", output)
+	}
+	if (server == "") == (file == "") {
+		return fmt.Errorf("exactly one of --server and --file is required")
+	}
+
+	query := history.Query{PullRequest: number, Limit: limit}
+	if repository != "" {
+		owner, repo, ok := strings.Cut(repository, "/")
+		if !ok || owner == "" || repo == "" {
+			return fmt.Errorf("invalid --repo %q, expected owner/name", repository)
+		}
+		query.Owner, query.Repository = owner, repo
+	}
+	if since > 0 {
+		query.Since = time.Now().Add(-since)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var records []history.Record
+	var err error
+	if file != "" {
+		records, err = history.NewFileStore(file).List(ctx, query)
+	} else {
+		records, err = (&history.Client{URL: strings.TrimSuffix(server, "/")}).List(ctx, query)
+	}
+	if err != nil {
+		return err
+	}
+
+	if output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	}
+	printHistory(os.Stdout, records)
+
+	return nil
+}
+
+// printHistory prints review records as a table followed by their totals
+func printHistory(w io.Writer, records []history.Record) {
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "COMPLETED\tREPOSITORY\tPR\tCOMMIT\tFINDINGS\tTOKENS\tDURATION\tDECISION")
+	findings, tokens := 0, int64(0)
+	for _, record := range records {
+		sha := record.CommitSHA
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+		fmt.Fprintf(table, "%s\t%s/%s\t%d\t%s\t%d\t%d\t%s\t%s\n",
+			record.CompletedAt.Local().Format(time.DateTime), record.Owner, record.Repository, record.PullRequest,
+			sha, len(record.Comments), record.TotalTokens, record.Duration.Round(time.Second), record.Decision)
+		findings += len(record.Comments)
+		tokens += record.TotalTokens
+	}
+	table.Flush()
+
+	fmt.Fprintf(w, "\nReviews: %d  Findings: %d  Tokens: %d\n", len(records), findings, tokens)
+}
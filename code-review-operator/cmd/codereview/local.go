@@ -0,0 +1,201 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+)
+
+// localOwner is the owner of the repository of a local review
+const localOwner = "local"
+
+// errLocal is returned by the provider operations a local working tree does not support
+var errLocal = fmt.Errorf("%w: not supported when reviewing a local diff", git.ErrInvalidRequest)
+
+// localClient is a git.Client reading a local working tree. Its diff is the
+// working tree compared with a base commit, files are read from the working
+// tree, and nothing can be posted.
+type localClient struct {
+	// dir is the root of the working tree
+	dir string
+
+	// base is the commit the working tree is compared with
+	base string
+
+	// diff replaces the output of git diff when set
+	diff string
+}
+
+var _ git.Client = &localClient{}
+
+// git runs a Git command in the working tree and returns its output
+func (c *localClient) git(ctx context.Context, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = c.dir
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return out, nil
+}
+
+// GetDiff implements git.Client
+func (c *localClient) GetDiff(ctx context.Context, _, _ string, _ int, _ string) (string, error) {
+	if c.diff != "" {
+		return c.diff, nil
+	}
+	out, err := c.git(ctx, "diff", "--no-color", "--no-ext-diff", c.base, "--")
+	if err != nil {
+		return "", err
+	}
+
+	return string(out), nil
+}
+
+// GetCompareDiff implements git.Client
+func (c *localClient) GetCompareDiff(ctx context.Context, _, _, base, head string) (string, error) {
+	out, err := c.git(ctx, "diff", "--no-color", "--no-ext-diff", base, head, "--")
+	if err != nil {
+		return "", err
+	}
+
+	return string(out), nil
+}
+
+// GetFileContent implements git.Client. Files of other repositories, such as
+// the org-wide configuration, do not exist locally.
+func (c *localClient) GetFileContent(ctx context.Context, owner, repo, path, ref string) ([]byte, error) {
+	if owner != localOwner || repo != filepath.Base(c.dir) {
+		return nil, git.ErrResourceNotFound
+	}
+	if ref != "" {
+		data, err := c.git(ctx, "show", ref+":"+path)
+		if err != nil {
+			return nil, git.ErrResourceNotFound
+		}
+		return data, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(c.dir, filepath.FromSlash(path)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, git.ErrResourceNotFound
+	}
+
+	return data, err
+}
+
+// PostReview implements git.Client
+func (c *localClient) PostReview(context.Context, string, string, int, string, []git.ReviewComment, string, git.ReviewDecision) (string, error) {
+	return "", errLocal
+}
+
+// ListReviewComments implements git.Client
+func (c *localClient) ListReviewComments(context.Context, string, string, int) ([]git.PostedComment, error) {
+	return nil, nil
+}
+
+// ReplyToReviewComment implements git.Client
+func (c *localClient) ReplyToReviewComment(context.Context, string, string, int, string, string) error {
+	return errLocal
+}
+
+// ResolveReviewThread implements git.Client
+func (c *localClient) ResolveReviewThread(context.Context, string, string, int, string) error {
+	return errLocal
+}
+
+// PostComment implements git.Client
+func (c *localClient) PostComment(context.Context, string, string, int, string) (string, error) {
+	return "", errLocal
+}
+
+// GetComments implements git.Client
+func (c *localClient) GetComments(context.Context, string, string, int) ([]git.IssueComment, error) {
+	return nil, nil
+}
+
+// FindPullRequestsByLabel implements git.Client
+func (c *localClient) FindPullRequestsByLabel(context.Context, string, string) ([]git.PullRequest, error) {
+	return nil, fmt.Errorf("pull requests are not available for a local diff")
+}
+
+// GetIssue implements git.Client
+func (c *localClient) GetIssue(context.Context, string, string, int) (*git.Issue, error) {
+	return nil, fmt.Errorf("issues are not available for a local diff")
+}
+
+// DeleteReviewComment implements git.Client
+func (c *localClient) DeleteReviewComment(context.Context, string, string, int, string) error {
+	return errLocal
+}
+
+// GetRepositories implements git.Client
+func (c *localClient) GetRepositories(context.Context, string) ([]git.Repository, error) {
+	return []git.Repository{{Owner: localOwner, Name: filepath.Base(c.dir), FullName: localOwner + "/" + filepath.Base(c.dir)}}, nil
+}
+
+// GetPullRequests implements git.Client
+func (c *localClient) GetPullRequests(context.Context, string, string) ([]git.PullRequest, error) {
+	return nil, nil
+}
+
+// CreateCheckRun implements git.Client
+func (c *localClient) CreateCheckRun(context.Context, string, string, git.CheckRun) (string, error) {
+	return "", errLocal
+}
+
+// StartCheckRun implements git.Client
+func (c *localClient) StartCheckRun(context.Context, string, string, git.CheckRun) (string, error) {
+	return "", errLocal
+}
+
+// UpdateCheckRun implements git.Client
+func (c *localClient) UpdateCheckRun(context.Context, string, string, string, git.CheckRun) error {
+	return errLocal
+}
+
+// SetCommitStatus implements git.Client
+func (c *localClient) SetCommitStatus(context.Context, string, string, string, git.CommitStatus) error {
+	return errLocal
+}
+
+// AddLabels implements git.Client
+func (c *localClient) AddLabels(context.Context, string, string, int, []string) error {
+	return errLocal
+}
+
+// DiffLink implements git.Client; local diffs have no links
+func (c *localClient) DiffLink(string, string, int, git.DiffLine) string {
+	return ""
+}
+
+// GetProviderName implements git.Client
+func (c *localClient) GetProviderName() string {
+	return "local"
+}
@@ -0,0 +1,366 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command codereview runs a review outside Kubernetes, against a pull request
+// or the local working tree, with the same pipeline as the operator. Findings
+// are printed as text, JSON or SARIF, and pull request reviews can be posted.
+//
+// Usage:
+//
+//	codereview pr --provider github --repo owner/name --pr 42 [--post]
+//	codereview diff [--base main] [--dir .]
+//	codereview history --server http://localhost:8085 [--repo owner/name]
+//	codereview queue list --server http://localhost:8086
+//	codereview verify --public-key review.pub < summary.md
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/git/github"
+	"github.com/Shridhar2104/code-review-operator/pkg/git/gitlab"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+	"github.com/Shridhar2104/code-review-operator/pkg/policy"
+	"github.com/Shridhar2104/code-review-operator/pkg/provenance"
+	"github.com/Shridhar2104/code-review-operator/pkg/repoconfig"
+	"github.com/Shridhar2104/code-review-operator/pkg/review"
+)
+
+// exitBlocked is the exit code when findings reach the --fail-on severity
+const exitBlocked = 2
+
+// settings are the flags shared by every subcommand
+type settings struct {
+	llmProvider   string
+	llmEndpoint   string
+	llmModel      string
+	llmRegion     string
+	llmProject    string
+	contextWindow int
+	output        string
+	failOn        string
+	verbose       bool
+	stream        bool
+	lintOnly      bool
+	signingKey    string
+}
+
+// bind registers the shared flags on a subcommand's flag set
+func (s *settings) bind(flags *flag.FlagSet) {
+	flags.StringVar(&s.llmProvider, "llm-provider", "http",
+		"The LLM backend: http, openai, anthropic, vertex, bedrock, azure-openai, ollama, local, or none to run "+
+			"only the deterministic checks.")
+	flags.StringVar(&s.llmEndpoint, "llm-endpoint", "",
+		"The URL of the LLM backend (defaults to the backend's public API). The API key is read from LLM_API_KEY.")
+	flags.StringVar(&s.llmModel, "llm-model", "", "The model used by the LLM backend.")
+	flags.StringVar(&s.llmRegion, "llm-region", "", "The cloud region of the vertex and bedrock backends.")
+	flags.StringVar(&s.llmProject, "llm-project", "", "The Google Cloud project of the vertex backend.")
+	flags.IntVar(&s.contextWindow, "llm-context-window", llm.DefaultLocalContextWindow,
+		"The context window in tokens of the ollama and local backends.")
+	flags.StringVar(&s.output, "output", "text", "Output format: text, json or sarif.")
+	flags.StringVar(&s.failOn, "fail-on", "",
+		"Exit with code 2 if a finding has this severity or a higher one (critical, major, minor or suggestion).")
+	flags.BoolVar(&s.verbose, "verbose", false, "Log the progress of the review to stderr.")
+	flags.BoolVar(&s.stream, "stream", true,
+		"Print findings to stderr as the LLM reports them, before they are filtered into the final results.")
+	flags.BoolVar(&s.lintOnly, "lint-only-fallback", false,
+		"If the LLM backend is unreachable, report the findings of the deterministic checks instead of failing.")
+}
+
+// validate checks the shared flags
+func (s *settings) validate() error {
+	switch s.output {
+	case "text", "json", "sarif":
+	default:
+		return fmt.Errorf("unknown output format %q", s.output)
+	}
+	if s.failOn != "" && policy.SeverityRank(s.failOn) == 0 {
+		return fmt.Errorf("unknown severity %q", s.failOn)
+	}
+
+	return nil
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	var err error
+	blocked := false
+	switch os.Args[1] {
+	case "pr":
+		blocked, err = reviewPullRequest(os.Args[2:])
+	case "diff":
+		blocked, err = reviewLocalDiff(os.Args[2:])
+	case "history":
+		err = listHistory(os.Args[2:])
+	case "queue":
+		err = manageQueue(os.Args[2:])
+	case "verify":
+		err = verifyReview(os.Args[2:])
+	case "-h", "-help", "--help", "help":
+		usage()
+		return
+	default:
+		err = fmt.Errorf("unknown command %q", os.Args[1])
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	if blocked {
+		os.Exit(exitBlocked)
+	}
+}
+
+// usage prints the available commands
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage: codereview <command> [flags]
+
+Commands:
+  pr       review a pull request, optionally posting the review
+  diff     review the changes in a local Git working tree
+  history  list past reviews recorded by the operator
+  queue    list, cancel, prioritize or requeue the operator's reviews
+  verify   check the signed provenance of a posted review
+
+Run "codereview <command> -h" for the flags of a command.`)
+}
+
+// reviewPullRequest implements the pr command
+func reviewPullRequest(args []string) (bool, error) {
+	var s settings
+	var provider, repository, sha, tokenEnv string
+	var number int
+	var post bool
+	var githubOptions github.Options
+	flags := flag.NewFlagSet("pr", flag.ContinueOnError)
+	flags.StringVar(&provider, "provider", "github", "Git provider: github or gitlab.")
+	flags.StringVar(&repository, "repo", "", "Repository to review, as owner/name.")
+	flags.IntVar(&number, "pr", 0, "Number of the pull request to review.")
+	flags.StringVar(&sha, "sha", "", "Head commit of the pull request, used to read the repository configuration.")
+	flags.StringVar(&tokenEnv, "token-env", "GIT_TOKEN", "Environment variable holding the provider token.")
+	flags.BoolVar(&post, "post", false, "Post the review on the pull request instead of only printing it.")
+	flags.StringVar(&s.signingKey, "signing-key", "",
+		"PEM encoded Ed25519 private key the provenance of the posted review is signed with.")
+	flags.StringVar(&githubOptions.BaseURL, "github-api-url", github.DefaultAPIURL,
+		"The URL of the GitHub REST API, https://HOST/api/v3 for GitHub Enterprise Server.")
+	s.bind(flags)
+	if err := flags.Parse(args); err != nil {
+		return false, err
+	}
+	if err := s.validate(); err != nil {
+		return false, err
+	}
+	owner, repo, ok := strings.Cut(repository, "/")
+	if !ok || owner == "" || repo == "" {
+		return false, fmt.Errorf("--repo is required, as owner/name")
+	}
+	if number <= 0 {
+		return false, fmt.Errorf("--pr is required")
+	}
+	token := os.Getenv(tokenEnv)
+	if token == "" {
+		return false, fmt.Errorf("no provider token in %s", tokenEnv)
+	}
+
+	factory := git.NewFactory()
+	factory.Register("github", func(tokenSource git.TokenSource) (git.Client, error) {
+		return github.NewClientWithOptions(tokenSource, githubOptions)
+	})
+	factory.Register("gitlab", gitlab.NewClient)
+	client, err := factory.Create(provider, git.NewStaticTokenSource(token))
+	if err != nil {
+		return false, err
+	}
+
+	job := &review.Job{
+		Client:      client,
+		Owner:       owner,
+		Repository:  repo,
+		PullRequest: number,
+		CommitSHA:   sha,
+		DryRun:      !post,
+	}
+
+	return run(s, job)
+}
+
+// reviewLocalDiff implements the diff command
+func reviewLocalDiff(args []string) (bool, error) {
+	var s settings
+	var dir, base, diffFile string
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.StringVar(&dir, "dir", ".", "Directory of the Git working tree to review.")
+	flags.StringVar(&base, "base", "HEAD", "Commit, branch or tag the working tree is compared with.")
+	flags.StringVar(&diffFile, "diff-file", "",
+		"Review this unified diff, or - for stdin, instead of running git diff.")
+	s.bind(flags)
+	if err := flags.Parse(args); err != nil {
+		return false, err
+	}
+	if err := s.validate(); err != nil {
+		return false, err
+	}
+
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return false, err
+	}
+	client := &localClient{dir: root, base: base}
+	if diffFile != "" {
+		client.diff, err = readFile(diffFile)
+		if err != nil {
+			return false, fmt.Errorf("error reading diff: %w", err)
+		}
+	}
+
+	job := &review.Job{
+		Client:     client,
+		Owner:      localOwner,
+		Repository: filepath.Base(root),
+		Dir:        root,
+		DryRun:     true,
+	}
+
+	return run(s, job)
+}
+
+// run reviews a job with the operator's default pipeline, prints its findings
+// and reports whether they reach the --fail-on severity
+func run(s settings, job *review.Job) (bool, error) {
+	logOutput := io.Discard
+	if s.verbose {
+		logOutput = os.Stderr
+	}
+	logger := zap.New(zap.WriteTo(logOutput), zap.UseDevMode(true))
+	log.SetLogger(logger)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	ctx = log.IntoContext(ctx, logger)
+
+	llmClient, err := llm.NewDefaultFactory().Create(s.llmProvider, llm.Config{
+		Endpoint:        s.llmEndpoint,
+		APIKey:          os.Getenv("LLM_API_KEY"),
+		Model:           s.llmModel,
+		ContextWindow:   s.contextWindow,
+		Region:          s.llmRegion,
+		Project:         s.llmProject,
+		TrustedEndpoint: true,
+	})
+	if err != nil {
+		return false, fmt.Errorf("error creating LLM client: %w", err)
+	}
+
+	// Apply the repository's configuration, as the operator would
+	source := repoconfig.OrgSource{Owner: job.Owner}
+	orgConfig, repoConfig, err := repoconfig.NewLoader(0).LoadLayers(ctx, job.Client, source, job.Repository, job.CommitSHA)
+	var verr *repoconfig.ValidationError
+	var oerr *repoconfig.OrgConfigError
+	if errors.As(err, &oerr) {
+		fmt.Fprintf(os.Stderr, "warning: ignoring %v\n", oerr)
+	}
+	if errors.As(err, &verr) {
+		fmt.Fprintf(os.Stderr, "warning: ignoring %v\n", verr)
+	}
+	if err != nil && verr == nil && oerr == nil {
+		return false, err
+	}
+	config := repoconfig.Resolve(&repoconfig.Config{}, orgConfig, repoConfig, nil).Config
+	job.Config = config
+	job.Options = llm.ReviewOptions{
+		Rules:          config.Rules,
+		SeverityLevels: config.SeverityLevels,
+		MinSeverity:    config.MinSeverity,
+		DisabledRules:  config.DisabledRules,
+		Language:       config.Language,
+		Glossary:       config.Glossary,
+		Include:        config.Include,
+		Exclude:        config.ExcludePatterns(),
+		Repository:     job.Owner + "/" + job.Repository,
+		Analyzers:      config.Analyzers,
+	}
+	// Languages are routed with their rules; models and prompts are the operator's
+	job.Routes = review.LanguageRoutes(config)
+
+	if s.stream {
+		job.OnProgress = printProgress(os.Stderr)
+	}
+	pipeline := review.NewDefaultPipeline(llmClient, review.NewDefaultPublisherRegistry())
+	pipeline.Use(review.LintOnlyFallback(s.lintOnly))
+	if s.signingKey != "" {
+		signer, err := provenance.LoadSigner(s.signingKey)
+		if err != nil {
+			return false, err
+		}
+		pipeline.Replace(review.StagePublish, "provenance", review.RecordProvenance(signer))
+	}
+	if err := pipeline.Run(ctx, job); err != nil {
+		return false, err
+	}
+
+	if err := printReview(os.Stdout, job, s.output); err != nil {
+		return false, err
+	}
+	if job.ReviewURL != "" {
+		fmt.Fprintf(os.Stderr, "Review posted: %s\n", job.ReviewURL)
+	}
+
+	return blocks(job.Comments, s.failOn), nil
+}
+
+// blocks reports whether a comment has at least the given severity
+func blocks(comments []git.ReviewComment, failOn string) bool {
+	if failOn == "" {
+		return false
+	}
+	for _, comment := range comments {
+		if policy.SeverityRank(comment.Severity) >= policy.SeverityRank(failOn) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readFile reads a file, or stdin if the path is -
+func readFile(path string) (string, error) {
+	if path == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		return string(data), err
+	}
+	data, err := os.ReadFile(path)
+
+	return string(data), err
+}
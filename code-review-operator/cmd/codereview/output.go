@@ -0,0 +1,81 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/report"
+	"github.com/Shridhar2104/code-review-operator/pkg/review"
+)
+
+// printReview prints the outcome of a review in the given format
+func printReview(w io.Writer, job *review.Job, format string) error {
+	if format != "text" {
+		return review.Report(job).Write(w, report.Format(format))
+	}
+
+	for _, comment := range job.Comments {
+		rule := ""
+		if comment.Rule != "" {
+			rule = " (" + comment.Rule + ")"
+		}
+		side := ""
+		if comment.Side == "LEFT" {
+			side = " [removed]"
+		}
+		fmt.Fprintf(w, "%s%s: %s%s\n  %s\n", comment.Location(), side, comment.Severity, rule, comment.Content)
+		if comment.SuggestedCode != "" {
+			fmt.Fprintf(w, "  Suggested change:\n    %s\n", indent(comment.SuggestedCode))
+		}
+	}
+	if len(job.Comments) > 0 {
+		fmt.Fprintln(w)
+	}
+	if job.Summary != "" {
+		fmt.Fprintf(w, "%s\n\n", job.Summary)
+	}
+	fmt.Fprintf(w, "Findings: %d\n", len(job.Comments))
+	if job.Decision != "" {
+		fmt.Fprintf(w, "Decision: %s\n", job.Decision)
+	}
+
+	return nil
+}
+
+// printProgress returns a progress handler printing the findings the LLM
+// streams and the files reviewed as they arrive
+func printProgress(w io.Writer) func(review.ReviewEvent) {
+	reviewed := 0
+	return func(event review.ReviewEvent) {
+		if comment := event.Comment; comment != nil {
+			content, _, _ := strings.Cut(comment.Content, "\n")
+			fmt.Fprintf(w, "... %s:%d: %s: %s\n", comment.File, comment.Line, comment.Severity, content)
+		}
+		if event.FilesReviewed > reviewed {
+			reviewed = event.FilesReviewed
+			fmt.Fprintf(w, "... reviewed %d/%d files\n", event.FilesReviewed, event.FilesTotal)
+		}
+	}
+}
+
+// indent indents the lines after the first of a block of code
+func indent(code string) string {
+	return strings.ReplaceAll(strings.TrimSuffix(code, "\n"), "\n", "\n    ")
+}
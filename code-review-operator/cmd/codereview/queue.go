@@ -0,0 +1,193 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/queue"
+)
+
+// queueUsage describes the queue subcommands
+const queueUsage = `Usage: codereview queue <subcommand> --server URL [flags] [arguments]
+
+Subcommands:
+  list                              list running, queued, pending and failed reviews
+  cancel <namespace/name>           cancel a queued, pending or running review
+  priority <namespace/name> <n>     set the priority of a review; higher priorities run first
+  requeue <namespace/name>          run a failed or timed out review again
+  requeue --failed                  run every failed and timed out review again
+
+Changes are authorized with the bearer token in QUEUE_API_TOKEN.`
+
+// manageQueue implements the queue command
+func manageQueue(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, queueUsage)
+		return fmt.Errorf("missing queue subcommand")
+	}
+	subcommand := args[0]
+	if subcommand == "-h" || subcommand == "-help" || subcommand == "--help" || subcommand == "help" {
+		fmt.Fprintln(os.Stderr, queueUsage)
+		return flag.ErrHelp
+	}
+
+	var server, namespace, output string
+	var failed bool
+	flags := flag.NewFlagSet("queue "+subcommand, flag.ContinueOnError)
+	flags.StringVar(&server, "server", "", "URL of the operator's review queue API.")
+	flags.StringVar(&namespace, "namespace", "", "Only list or requeue reviews in this namespace.")
+	flags.StringVar(&output, "output", "text", "Output format of list: text or json.")
+	flags.BoolVar(&failed, "failed", false, "With requeue, requeue every failed and timed out review.")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if server == "" {
+		return fmt.Errorf("--server is required")
+	}
+	client := &queue.Client{URL: strings.TrimSuffix(server, "/"), Token: os.Getenv("QUEUE_API_TOKEN")}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	switch subcommand {
+	case "list":
+		if output != "text" && output != "json" {
+			return fmt.Errorf("unknown output format %q", output)
+		}
+		entries, err := client.List(ctx, namespace)
+		if err != nil {
+			return err
+		}
+		if output == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(entries)
+		}
+		printQueue(os.Stdout, entries)
+		return nil
+	case "cancel":
+		key, err := reviewArgument(flags.Args(), 1)
+		if err != nil {
+			return err
+		}
+		if err := client.Cancel(ctx, key[0], key[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Cancelled %s/%s\n", key[0], key[1])
+		return nil
+	case "priority":
+		key, err := reviewArgument(flags.Args(), 2)
+		if err != nil {
+			return err
+		}
+		priority, err := strconv.Atoi(flags.Arg(1))
+		if err != nil {
+			return fmt.Errorf("invalid priority %q, expected an integer", flags.Arg(1))
+		}
+		if err := client.Prioritize(ctx, key[0], key[1], priority); err != nil {
+			return err
+		}
+		fmt.Printf("Set the priority of %s/%s to %d\n", key[0], key[1], priority)
+		return nil
+	case "requeue":
+		if failed {
+			return requeueFailed(ctx, client, namespace, flags.Args())
+		}
+		key, err := reviewArgument(flags.Args(), 1)
+		if err != nil {
+			return err
+		}
+		if err := client.Requeue(ctx, key[0], key[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Requeued %s/%s\n", key[0], key[1])
+		return nil
+	default:
+		fmt.Fprintln(os.Stderr, queueUsage)
+		return fmt.Errorf("unknown queue subcommand %q", subcommand)
+	}
+}
+
+// requeueFailed requeues every failed review, in a namespace if one is given
+func requeueFailed(ctx context.Context, client *queue.Client, namespace string, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("requeue --failed takes no review")
+	}
+	entries, err := client.List(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	requeued := 0
+	for _, entry := range entries {
+		if entry.State != queue.StateFailed {
+			continue
+		}
+		if err := client.Requeue(ctx, entry.Namespace, entry.Name); err != nil {
+			return fmt.Errorf("error requeueing %s/%s after requeueing %d reviews: %w", entry.Namespace, entry.Name, requeued, err)
+		}
+		requeued++
+	}
+	fmt.Printf("Requeued %d reviews\n", requeued)
+
+	return nil
+}
+
+// reviewArgument parses the namespace/name of the review a subcommand acts
+// on, the first of its count arguments
+func reviewArgument(args []string, count int) ([2]string, error) {
+	if len(args) != count {
+		return [2]string{}, fmt.Errorf("expected %d arguments, got %d", count, len(args))
+	}
+	namespace, name, ok := strings.Cut(args[0], "/")
+	if !ok || namespace == "" || name == "" {
+		return [2]string{}, fmt.Errorf("invalid review %q, expected namespace/name", args[0])
+	}
+
+	return [2]string{namespace, name}, nil
+}
+
+// printQueue prints queue entries as a table
+func printQueue(w io.Writer, entries []queue.Entry) {
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "STATE\tPOSITION\tREVIEW\tREPOSITORY\tPR\tCOMMIT\tPRIORITY\tLINES\tSINCE")
+	for _, entry := range entries {
+		sha := entry.CommitSHA
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+		position := "-"
+		if entry.Position > 0 {
+			position = strconv.Itoa(entry.Position)
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s/%s\t%s\t%d\t%s\t%d\t%d\t%s\n",
+			entry.State, position, entry.Namespace, entry.Name, entry.Repository, entry.PullRequest, sha,
+			entry.Priority, entry.Size, entry.Since.Local().Format(time.DateTime))
+	}
+	table.Flush()
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/provenance"
+)
+
+// verifyReview implements the verify command
+func verifyReview(args []string) error {
+	var keyFile, file, attestation, output string
+	flags := flag.NewFlagSet("verify", flag.ContinueOnError)
+	flags.StringVar(&keyFile, "public-key", "", "PEM encoded Ed25519 public key the reviews were signed with.")
+	flags.StringVar(&file, "file", "-", "File holding the posted review summary, or - for stdin.")
+	flags.StringVar(&attestation, "attestation", "",
+		"Verify this attestation, from the status of a CodeReview, instead of the one attached to the summary. "+
+			"The summary is then only compared with it if --file is set.")
+	flags.StringVar(&output, "output", "text", "Output format: text or json.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if output != "text" && output != "json" {
+		return fmt.Errorf("unknown output format %q", output)
+	}
+	if keyFile == "" {
+		return fmt.Errorf("--public-key is required")
+	}
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return fmt.Errorf("error reading public key: %w", err)
+	}
+	key, err := provenance.ParsePublicKey(data)
+	if err != nil {
+		return err
+	}
+
+	var statement *provenance.Statement
+	if attestation != "" {
+		statement, err = verifyAttestation(attestation, key)
+		if err == nil && !flagSet(flags, "file") {
+			fmt.Fprintln(os.Stderr, "note: no --file given, the summary was not compared with the attestation")
+		} else if err == nil {
+			var body string
+			if body, err = readFile(file); err != nil {
+				return fmt.Errorf("error reading summary: %w", err)
+			}
+			summary, _, extractErr := provenance.Extract(body)
+			if extractErr != nil && !errors.Is(extractErr, provenance.ErrNoAttestation) {
+				return extractErr
+			}
+			if provenance.Digest(summary) != statement.SummaryDigest {
+				err = provenance.ErrDigestMismatch
+			}
+		}
+	} else {
+		body, readErr := readFile(file)
+		if readErr != nil {
+			return fmt.Errorf("error reading summary: %w", readErr)
+		}
+		statement, err = provenance.VerifySummary(body, key)
+	}
+	if err != nil {
+		return err
+	}
+
+	if output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(statement)
+	}
+	printStatement(os.Stdout, statement, provenance.KeyID(key))
+
+	return nil
+}
+
+// verifyAttestation verifies a base64 encoded DSSE envelope
+func verifyAttestation(attestation string, key ed25519.PublicKey) (*provenance.Statement, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(attestation))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding attestation: %w", err)
+	}
+	var envelope provenance.Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("error parsing attestation: %w", err)
+	}
+
+	return provenance.Verify(&envelope, key)
+}
+
+// flagSet reports whether a flag was given on the command line
+func flagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+
+	return set
+}
+
+// printStatement prints a verified provenance statement
+func printStatement(w io.Writer, statement *provenance.Statement, keyID string) {
+	fmt.Fprintf(w, "Verified: signed with key %s\n\n", keyID)
+	fmt.Fprintf(w, "Repository:        %s\n", statement.Repository)
+	if statement.PullRequest > 0 {
+		fmt.Fprintf(w, "Pull request:      %d\n", statement.PullRequest)
+	}
+	if statement.CommitSHA != "" {
+		fmt.Fprintf(w, "Commit:            %s\n", statement.CommitSHA)
+	}
+	if statement.Decision != "" {
+		fmt.Fprintf(w, "Decision:          %s\n", statement.Decision)
+	}
+	fmt.Fprintf(w, "Comments:          %d\n", statement.Comments)
+	fmt.Fprintf(w, "Operator version:  %s\n", statement.OperatorVersion)
+	models := strings.Join(statement.Models, ", ")
+	if statement.LintOnly {
+		models = "none (lint-only review)"
+	}
+	fmt.Fprintf(w, "Models:            %s\n", models)
+	if statement.PromptHash != "" {
+		fmt.Fprintf(w, "Prompt hash:       %s\n", statement.PromptHash)
+	}
+	fmt.Fprintf(w, "Summary digest:    %s\n", statement.SummaryDigest)
+	fmt.Fprintf(w, "Signed at:         %s\n", statement.CreatedAt.Local().Format(time.DateTime))
+}
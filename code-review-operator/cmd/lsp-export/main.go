@@ -0,0 +1,106 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command lsp-export writes the operator's findings on a pull request as
+// Language Server Protocol diagnostics, for editor extensions to show inline
+// in a local checkout of the pull request's branch.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/git/github"
+	"github.com/Shridhar2104/code-review-operator/pkg/git/gitlab"
+	"github.com/Shridhar2104/code-review-operator/pkg/lsp"
+)
+
+func main() {
+	var provider, owner, repo, tokenEnv, root, outputPath string
+	var prNumber int
+	flag.StringVar(&provider, "provider", "github", "Git provider: github or gitlab.")
+	flag.StringVar(&owner, "owner", "", "Owner of the repository.")
+	flag.StringVar(&repo, "repo", "", "Name of the repository.")
+	flag.IntVar(&prNumber, "pr", 0, "Number of the pull request.")
+	flag.StringVar(&tokenEnv, "token-env", "GIT_TOKEN", "Environment variable holding the provider token.")
+	flag.StringVar(&root, "root", ".", "Root of the local checkout the diagnostics' file URIs point into.")
+	flag.StringVar(&outputPath, "output", "-", "Path to write the diagnostics to, or - for stdout.")
+	flag.Parse()
+
+	if err := run(provider, owner, repo, prNumber, os.Getenv(tokenEnv), root, outputPath); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run fetches the pull request's findings and writes them as diagnostics
+func run(provider, owner, repo string, prNumber int, token, root, outputPath string) error {
+	if owner == "" || repo == "" || prNumber <= 0 {
+		return fmt.Errorf("--owner, --repo and --pr are required")
+	}
+	if token == "" {
+		return fmt.Errorf("no provider token in the environment")
+	}
+
+	factory := git.NewFactory()
+	factory.Register("github", github.NewClient)
+	factory.Register("gitlab", gitlab.NewClient)
+	client, err := factory.Create(provider, git.NewStaticTokenSource(token))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	posted, err := client.ListReviewComments(ctx, owner, repo, prNumber)
+	if err != nil {
+		return err
+	}
+	comments := lsp.FromPostedComments(posted)
+	for i, comment := range comments {
+		comments[i].Link = client.DiffLink(owner, repo, prNumber, git.DiffLine{
+			File:      comment.File,
+			Side:      comment.Side,
+			StartLine: comment.StartLine,
+			Line:      comment.Line,
+		})
+	}
+
+	var writer io.Writer = os.Stdout
+	if outputPath != "-" {
+		file, err := os.Create(outputPath)
+		if err != nil {
+			return fmt.Errorf("error creating output: %w", err)
+		}
+		defer file.Close()
+		writer = file
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(lsp.Export(root, comments)); err != nil {
+		return fmt.Errorf("error writing diagnostics: %w", err)
+	}
+
+	return nil
+}
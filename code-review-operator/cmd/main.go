@@ -17,19 +17,22 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
-	"github.com/Shridhar2104/code-review-operator/pkg/git"
-	"github.com/Shridhar2104/code-review-operator/pkg/git/github"
-	"github.com/Shridhar2104/code-review-operator/pkg/git/gitlab"
-	
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -38,6 +41,28 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/yaml"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/internal/controller"
+	"github.com/Shridhar2104/code-review-operator/pkg/cloudevents"
+	"github.com/Shridhar2104/code-review-operator/pkg/compliance"
+	"github.com/Shridhar2104/code-review-operator/pkg/egress"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/git/github"
+	"github.com/Shridhar2104/code-review-operator/pkg/git/gitlab"
+	"github.com/Shridhar2104/code-review-operator/pkg/history"
+	"github.com/Shridhar2104/code-review-operator/pkg/httpx"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+	"github.com/Shridhar2104/code-review-operator/pkg/memory"
+	"github.com/Shridhar2104/code-review-operator/pkg/metrics"
+	"github.com/Shridhar2104/code-review-operator/pkg/notify"
+	"github.com/Shridhar2104/code-review-operator/pkg/provenance"
+	"github.com/Shridhar2104/code-review-operator/pkg/queue"
+	"github.com/Shridhar2104/code-review-operator/pkg/repoconfig"
+	"github.com/Shridhar2104/code-review-operator/pkg/review"
+	"github.com/Shridhar2104/code-review-operator/pkg/scheduler"
+	"github.com/Shridhar2104/code-review-operator/pkg/telemetry"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -49,23 +74,86 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
+	utilruntime.Must(reviewv1alpha1.AddToScheme(scheme))
+
 	// +kubebuilder:scaffold:scheme
 }
 
 func main() {
 
-
 	// Create the Git client factory
 	gitFactory := git.NewFactory()
-	gitFactory.Register("github", github.NewClient)
 	gitFactory.Register("gitlab", gitlab.NewClient)
 
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
 	var secureMetrics bool
+	var metricsCardinality = metrics.DefaultCardinality
 	var enableHTTP2 bool
 	var tlsOpts []func(*tls.Config)
+	var llmProvider string
+	var llmEndpoint string
+	var llmModel string
+	var llmContextWindow int
+	var llmRegion string
+	var llmProject string
+	var orgConfigInterval time.Duration
+	var maxConcurrentReviews int
+	retryPolicy := llm.DefaultRetryPolicy
+	var llmCacheSize int
+	var llmCacheTTL time.Duration
+	var llmCacheRedis string
+	var llmCacheRedisDB int
+	var gitCacheSize int
+	var gitCacheTTL time.Duration
+	var maxReviewsPerProvider int
+	var maxReviewsPerRepository int
+	var memoryNamespace string
+	var markFixedComments bool
+	var dryRun bool
+	var lintOnlyFallback bool
+	var reviewSigningKey string
+	var tenantIsolation bool
+	var repositoryContext bool
+	var repositoryContextTokens int
+	var containerProfile bool
+	var kubernetesProfile bool
+	var consensusProvider string
+	var consensusEndpoint string
+	var consensusModel string
+	var consensusMode string
+	var egressPolicy bool
+	var egressReport bool
+	var egressAllow string
+	var httpMaxRequestsPerHost int
+	var httpHostLimits string
+	var complianceMode bool
+	var complianceLLMProviders string
+	var retention controller.RetentionPolicy
+	var retentionInterval time.Duration
+	var telemetryEndpoint string
+	var cloudEventsSink string
+	var tokenPrices string
+	var promptConfigMap string
+	var operatorConfigMap string
+	var crdSkewPolicy string
+	var costRegressionThreshold float64
+	var costRegressionReviews int
+	var gitWebhookAddr string
+	var gitWebhookNamespace string
+	var historyFile string
+	var historyDriver string
+	var historyAddr string
+	var queueAddr string
+	var githubAPI string
+	var githubOptions github.Options
+	var githubCAFile string
+	var selftestMode bool
+	var selftestRepository string
+	var selftestProvider string
+	var selftestTokenEnv string
+	var selftestTimeout time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -74,16 +162,293 @@ func main() {
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.BoolVar(&secureMetrics, "metrics-secure", true,
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
+	flag.BoolVar(&metricsCardinality.HashRepositories, "metrics-hash-repositories", false,
+		"If set, metrics label repositories with the first 12 hex digits of the SHA-256 of owner/name instead of their name.")
+	flag.IntVar(&metricsCardinality.TopRepositories, "metrics-top-repositories", 0,
+		"If set, only this many repositories, those with the most reviews, get their own label in the token and "+
+			"cost metrics; the others are labeled \"other\". 0 labels every repository. The review history keeps the full detail.")
+	flag.BoolVar(&metricsCardinality.RuleLabels, "metrics-rule-labels", true,
+		"If set, comments posted are also counted by rule in codereview_comments_by_rule_total. "+
+			"Disable it if findings are reported for many distinct rules.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&llmProvider, "llm-provider", "http",
+		"The LLM backend used for reviews: http (the bundled LLM service), openai, anthropic, "+
+			"ollama, local (a self-hosted OpenAI-compatible server such as vLLM or llama.cpp), "+
+			"or vertex, bedrock or azure-openai, which authenticate with the pod's workload identity. "+
+			"none runs without an LLM: reviews post only the findings of the deterministic checks.")
+	flag.StringVar(&llmEndpoint, "llm-endpoint", "",
+		"The URL of the LLM review service, or the API base URL for the other backends. Defaults to "+
+			llm.DefaultHTTPEndpoint+" for http and to the public API of openai and anthropic. "+
+			"The API key is read from the LLM_API_KEY environment variable.")
+	flag.StringVar(&llmModel, "llm-model", "",
+		"The model used by the openai, anthropic, ollama, local, vertex and bedrock backends, "+
+			"or the deployment name for azure-openai.")
+	flag.StringVar(&llmRegion, "llm-region", "",
+		"The cloud region of the vertex and bedrock backends. bedrock defaults to AWS_REGION.")
+	flag.StringVar(&llmProject, "llm-project", "", "The Google Cloud project of the vertex backend.")
+	flag.IntVar(&llmContextWindow, "llm-context-window", llm.DefaultLocalContextWindow,
+		"The context window in tokens of the model served by the ollama and local backends.")
+	flag.IntVar(&retryPolicy.MaxRetries, "llm-max-retries", retryPolicy.MaxRetries,
+		"The number of retries of LLM requests failing with timeouts, connection errors, rate limits or server errors.")
+	flag.DurationVar(&retryPolicy.RequestTimeout, "llm-request-timeout", retryPolicy.RequestTimeout,
+		"If set, each LLM request attempt is cancelled after this long and retried. "+
+			"Attempts are also bounded by the backends' HTTP timeouts of 5 minutes (10 for local backends).")
+	flag.IntVar(&retryPolicy.FailureThreshold, "llm-circuit-failures", retryPolicy.FailureThreshold,
+		"The number of consecutive failed LLM requests after which requests to the backend are paused. 0 disables pausing.")
+	flag.DurationVar(&retryPolicy.OpenDuration, "llm-circuit-open-duration", retryPolicy.OpenDuration,
+		"How long requests to a failing LLM backend are paused before a single request probes whether it has recovered.")
+	flag.IntVar(&llmCacheSize, "llm-cache-size", 500,
+		"The number of LLM reviews cached in memory, reused when the same diff is reviewed again with the same settings. "+
+			"0 disables the in-memory cache.")
+	flag.DurationVar(&llmCacheTTL, "llm-cache-ttl", llm.DefaultCacheTTL, "How long LLM reviews are cached.")
+	flag.StringVar(&llmCacheRedis, "llm-cache-redis-address", "",
+		"If set, LLM reviews are cached in the Redis server at this host:port instead of in memory, "+
+			"shared by all replicas. The password is read from the REDIS_PASSWORD environment variable.")
+	flag.IntVar(&llmCacheRedisDB, "llm-cache-redis-db", 0, "The Redis database LLM reviews are cached in.")
+	flag.IntVar(&gitCacheSize, "git-cache-size-mb", 64,
+		"The megabytes of diffs and file contents at a commit cached in memory, pre-fetched when the Git webhook "+
+			"reports an opened or updated pull request and reused by its review. 0 disables the cache.")
+	flag.DurationVar(&gitCacheTTL, "git-cache-ttl", git.DefaultContentCacheTTL,
+		"How long pre-fetched diffs and file contents are cached.")
+	flag.DurationVar(&orgConfigInterval, "org-config-refresh-interval", repoconfig.DefaultRefreshInterval,
+		"How often org-wide review configuration is re-read from the central config repository.")
+	flag.IntVar(&maxConcurrentReviews, "max-concurrent-reviews", 4,
+		"The number of reviews run in parallel. Reviews of older commits are cancelled when a newer commit is pushed.")
+	flag.IntVar(&maxReviewsPerProvider, "max-reviews-per-provider", 0,
+		"The number of reviews of a single Git provider run in parallel, to stay within its API rate limits. "+
+			"0 means only --max-concurrent-reviews applies. Waiting reviews run smallest first.")
+	flag.IntVar(&maxReviewsPerRepository, "max-reviews-per-repository", 0,
+		"The number of reviews of a single repository run in parallel. "+
+			"0 means only --max-concurrent-reviews applies. Waiting reviews run smallest first.")
+	flag.StringVar(&memoryNamespace, "reviewer-memory-namespace", "",
+		"If set, the reviewer remembers recurring issues and past review summaries per repository "+
+			"in ConfigMaps in this namespace and uses them in later reviews. Findings that persist across pushes "+
+			"are escalated as configured by each repository; Slack notifications go to SLACK_WEBHOOK_URL. "+
+			"The chunk size learned for each model is kept in the review-model-registry ConfigMap.")
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"If set, reviews are rendered into the status of their CodeReview instead of being posted, "+
+			"to evaluate review quality before the operator comments publicly.")
+	flag.BoolVar(&lintOnlyFallback, "lint-only-fallback", false,
+		"If set, reviews whose LLM backend is unreachable post the findings of the deterministic checks, "+
+			"labeled as a lint-only review not made by AI, instead of waiting for the backend to recover.")
+	flag.StringVar(&reviewSigningKey, "review-signing-key", "",
+		"Path of a PEM encoded Ed25519 private key, such as one mounted from a Secret. If set, the provenance "+
+			"of each review (operator version, models, prompt hash and summary digest) is signed and attached "+
+			"to the posted summary, to be checked with codereview verify.")
+	flag.BoolVar(&tenantIsolation, "tenant-isolation", false,
+		"If set, every review needs a ReviewerConfig in its namespace and runs only on the LLM backend of its "+
+			"spec or ReviewerConfig, never on the operator's, so teams sharing the operator use only their own "+
+			"credentials and quota.")
+	flag.BoolVar(&markFixedComments, "mark-fixed-comments", false,
+		"If set, the reviewer replies to its comments from earlier reviews whose lines have changed "+
+			"and whose finding was not reported again, noting that they appear to be addressed.")
+	flag.BoolVar(&repositoryContext, "repository-context", false,
+		"If set, reviews include context read from the repository: the code around each change, "+
+			"package manifests such as go.mod or package.json, and the definitions of functions the changes call.")
+	flag.IntVar(&repositoryContextTokens, "repository-context-tokens", review.DefaultContextTokens,
+		"The budget of repository context added to each review, in tokens.")
+	flag.BoolVar(&containerProfile, "container-profile", false,
+		"If set, changed Dockerfiles are checked for ADD used instead of COPY, secrets in build arguments, "+
+			"unpinned images and missing HEALTHCHECKs, and the LLM explains each finding in its review.")
+	flag.BoolVar(&kubernetesProfile, "kubernetes-profile", false,
+		"If set, changed Kubernetes manifests, Helm charts and Kustomize files are checked for privileged containers, "+
+			"missing resource limits, unpinned images, hostPath volumes and RBAC wildcards, and the LLM explains "+
+			"each finding in its review.")
+	flag.StringVar(&consensusProvider, "consensus-llm-provider", "",
+		"If set, reviews labeled "+reviewv1alpha1.CriticalLabel+"=true are also run with this LLM backend "+
+			"and only findings both models agree on are posted. The API key is read from CONSENSUS_LLM_API_KEY.")
+	flag.StringVar(&consensusEndpoint, "consensus-llm-endpoint", "", "The API base URL of the consensus LLM backend.")
+	flag.StringVar(&consensusModel, "consensus-llm-model", "", "The model used by the consensus LLM backend.")
+	flag.StringVar(&consensusMode, "consensus-mode", string(llm.ConsensusAgree),
+		"What consensus reviews do with findings only one model reported: agree (drop them) "+
+			"or annotate (post them marked as lower confidence).")
+	flag.BoolVar(&egressPolicy, "egress-network-policy", false,
+		"If set, the operator maintains a NetworkPolicy restricting its own egress to DNS, the Kubernetes API "+
+			"and the configured Git, LLM and notification endpoints.")
+	flag.StringVar(&gitWebhookAddr, "git-webhook-bind-address", "0",
+		"The address the GitHub webhook endpoint, which runs /review commands from pull request comments "+
+			"and pre-fetches opened pull requests, binds to. Use 0 to disable it. Payloads are verified with the secret in GITHUB_WEBHOOK_SECRET.")
+	flag.StringVar(&gitWebhookNamespace, "git-webhook-namespace", "",
+		"The namespace of the reviews /review commands apply to. Reviews in every namespace are considered when empty.")
+	flag.StringVar(&historyFile, "history-file", "",
+		"If set, record every posted review (repository, pull request, commit, findings, tokens, models and "+
+			"duration) in this file, one JSON record per line. Suits a single replica with a persistent volume.")
+	flag.StringVar(&historyDriver, "history-database-driver", "",
+		"If set, record every posted review in a database instead, with this database/sql driver (sqlite, "+
+			"sqlite3, postgres or pgx) linked into the binary. The data source name is read from HISTORY_DATABASE_DSN.")
+	flag.StringVar(&historyAddr, "history-bind-address", "0",
+		"The address the review history API binds to. Use 0 to disable it.")
+	flag.StringVar(&queueAddr, "queue-bind-address", "0",
+		"The address the review queue API, which lists queued, running and failed reviews and cancels, "+
+			"prioritizes or requeues them, binds to on the leader. Use 0 to disable it. Changes need the "+
+			"bearer token in QUEUE_API_TOKEN; without it the queue is read-only.")
+	flag.StringVar(&githubAPI, "github-api", "rest",
+		"The GitHub API reviews read pull requests with: rest, or graphql to read a pull request's metadata, "+
+			"changed files and review threads in one or two queries, saving requests and rate limit.")
+	flag.StringVar(&githubOptions.BaseURL, "github-api-url", github.DefaultAPIURL,
+		"The URL of the GitHub REST API, https://HOST/api/v3 for GitHub Enterprise Server.")
+	flag.StringVar(&githubOptions.UploadURL, "github-upload-url", "",
+		"The URL of the GitHub uploads API. Defaults to the one matching --github-api-url.")
+	flag.StringVar(&githubOptions.ProxyURL, "github-proxy-url", "",
+		"If set, the HTTP proxy GitHub requests go through, instead of the proxy in HTTPS_PROXY.")
+	flag.StringVar(&githubCAFile, "github-ca-file", "",
+		"If set, a file of PEM certificates trusted for GitHub and its proxy in addition to the system's, "+
+			"such as the corporate CA signing a GitHub Enterprise Server certificate.")
+	flag.DurationVar(&githubOptions.Timeout, "github-timeout", github.DefaultTimeout,
+		"How long each GitHub API request may take.")
+	flag.IntVar(&httpMaxRequestsPerHost, "http-max-requests-per-host", httpx.DefaultMaxRequestsPerHost,
+		"The most outbound requests in flight to a host, such as a Git provider or an LLM backend, so that a slow "+
+			"host cannot starve requests to the others. 0 is unlimited.")
+	flag.StringVar(&httpHostLimits, "http-host-limits", "",
+		"Limits of outbound requests in flight to individual hosts, as host=limit separated by commas "+
+			"(such as api.openai.com=8), overriding --http-max-requests-per-host.")
+	flag.Float64Var(&costRegressionThreshold, "cost-regression-threshold", 0,
+		"If set, warn with an event and a Slack notification when a repository's review cost per 1000 changed "+
+			"lines rises by more than this percentage after its configuration or model changed. Needs "+
+			"--reviewer-memory-namespace.")
+	flag.IntVar(&costRegressionReviews, "cost-regression-min-reviews", 3,
+		"The number of reviews averaged under each configuration before their costs are compared.")
+	flag.StringVar(&crdSkewPolicy, "crd-skew-policy", controller.CRDSkewRefuse,
+		"What the operator does when the installed CRDs do not match its types, such as after a partial "+
+			"upgrade: refuse (exit at startup), read-only (hold every review with the CRDVersionSkew condition) "+
+			"or ignore (log the differences).")
+	flag.BoolVar(&selftestMode, "selftest", false,
+		"If set, review a canned diff with the configured LLM backends, validate their responses and check "+
+			"the Git credentials against --selftest-repository, print a report and exit, nonzero if a check failed.")
+	flag.StringVar(&selftestRepository, "selftest-repository", "",
+		"The sandbox repository (owner/name) whose pull requests the self-test lists to check Git credentials.")
+	flag.StringVar(&selftestProvider, "selftest-git-provider", "github", "The Git provider of the self-test sandbox repository.")
+	flag.StringVar(&selftestTokenEnv, "selftest-token-env", "GIT_TOKEN",
+		"Environment variable holding the Git token the self-test uses.")
+	flag.DurationVar(&selftestTimeout, "selftest-timeout", 2*time.Minute, "How long each self-test check may take.")
+	flag.BoolVar(&egressReport, "egress-report", false,
+		"Print the destinations the operator needs to reach and the resulting NetworkPolicy, then exit.")
+	flag.StringVar(&egressAllow, "egress-allow", "",
+		"Comma-separated extra URLs allowed by the egress NetworkPolicy, such as LLM endpoints selected in CodeReview specs.")
+	flag.BoolVar(&complianceMode, "compliance-mode", false,
+		"If set, connections are restricted to TLS 1.2 or later with FIPS approved cipher suites, reviews may only use "+
+			"the LLM backends in --compliance-llm-providers over TLS, and each review attests these settings in its status.")
+	flag.StringVar(&complianceLLMProviders, "compliance-llm-providers", strings.Join(compliance.DefaultAllowedLLMProviders, ","),
+		"Comma-separated LLM backends approved in compliance mode.")
+	flag.DurationVar(&retention.Reviews, "retention-reviews", 0,
+		"How long finished CodeReview resources are kept before they are deleted. 0 keeps them forever.")
+	flag.DurationVar(&retention.Summaries, "retention-summaries", 0,
+		"How long review summaries in the reviewer memory are kept before they are deleted. 0 keeps them forever.")
+	flag.DurationVar(&retention.Findings, "retention-findings", 0,
+		"How long findings tracked in the reviewer memory are kept after their pull request was last reviewed. "+
+			"0 keeps them forever. Aggregate counts of recurring issues are always kept.")
+	flag.DurationVar(&retentionInterval, "retention-interval", controller.DefaultRetentionInterval,
+		"How often expired review data is purged. Each deletion is logged to the audit logger.")
+	flag.StringVar(&promptConfigMap, "prompt-templates-configmap", "",
+		"If set, the ConfigMap of this name in the operator's namespace overrides the built-in prompt templates: "+
+			"system.tmpl and user.tmpl replace the shared templates, and <language>.tmpl (such as go.tmpl) "+
+			"replaces or adds the review guidance for a language.")
+	flag.StringVar(&operatorConfigMap, "operator-config-configmap", "",
+		"If set, the operator.yaml key of the ConfigMap of this name in the operator's namespace holds "+
+			"default review settings and the models of each model tier. It and the prompt template ConfigMap "+
+			"are reloaded when they change, without restarting the operator.")
+	flag.StringVar(&telemetryEndpoint, "telemetry-endpoint", "",
+		"Telemetry is off unless this is set. If set, aggregate usage counts (reviews by outcome, error reasons, "+
+			"comment totals and LLM backends) are sent to this URL daily. No code, repository names or users are sent.")
+	flag.StringVar(&cloudEventsSink, "cloudevents-sink", "",
+		"If set, CloudEvents of review.started, review.completed, review.failed and finding.critical are sent to "+
+			"this URL: posted to http:// and https:// URLs, or published to the NATS server of nats:// and tls:// "+
+			"URLs under the subject in the URL's path (codereview by default) followed by the event type.")
+	flag.StringVar(&tokenPrices, "token-prices", "",
+		"Prices of LLM models used to estimate review costs, in US dollars per million tokens, as "+
+			"model=prompt:completion separated by commas (such as gpt-4o=2.5:10). They are added to built-in "+
+			"list prices of common models; a model is priced by the longest name it contains.")
+	// Log JSON at info level by default for log aggregators; --zap-encoder=console and
+	// --zap-log-level=debug (or a verbosity such as 2) change the format and level
 	opts := zap.Options{
-		Development: true,
+		Development: false,
 	}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	switch crdSkewPolicy {
+	case controller.CRDSkewRefuse, controller.CRDSkewReadOnly, controller.CRDSkewIgnore:
+	default:
+		setupLog.Error(nil, "unsupported CRD skew policy", "policy", crdSkewPolicy)
+		os.Exit(1)
+	}
+
+	if githubCAFile != "" {
+		rootCAs, err := os.ReadFile(githubCAFile)
+		if err != nil {
+			setupLog.Error(err, "unable to read the GitHub CA file")
+			os.Exit(1)
+		}
+		githubOptions.RootCAs = rootCAs
+	}
+	// Fail fast on options no client can be created with
+	if _, err := github.NewClientWithOptions(nil, githubOptions); err != nil {
+		setupLog.Error(err, "invalid GitHub client options")
+		os.Exit(1)
+	}
+	switch githubAPI {
+	case "rest":
+		gitFactory.Register("github", func(tokenSource git.TokenSource) (git.Client, error) {
+			return github.NewClientWithOptions(tokenSource, githubOptions)
+		})
+	case "graphql":
+		gitFactory.Register("github", func(tokenSource git.TokenSource) (git.Client, error) {
+			return github.NewGraphQLClientWithOptions(tokenSource, githubOptions)
+		})
+	default:
+		setupLog.Error(nil, "unsupported GitHub API", "api", githubAPI)
+		os.Exit(1)
+	}
+	gitFactory.RegisterTokenSource(reviewv1alpha1.AuthTypeGitHubApp,
+		func(provider string, secret *corev1.Secret, key string) (git.TokenSource, error) {
+			if provider != "github" {
+				return nil, fmt.Errorf("GitHub App credentials cannot authenticate to %s", provider)
+			}
+			return github.NewAppTokenSourceFromSecret(secret, key, githubOptions)
+		})
+
+	// Work out where the operator needs to connect to
+	if llmEndpoint == "" {
+		llmEndpoint = llm.DefaultEndpoint(llmProvider)
+	}
+	if consensusProvider != "" && consensusEndpoint == "" {
+		consensusEndpoint = llm.DefaultEndpoint(consensusProvider)
+	}
+	llmURL := llmEndpoint
+	if !llm.UsesEndpoint(llmProvider) {
+		llmURL = ""
+	}
+	consensusURL := consensusEndpoint
+	if !llm.UsesEndpoint(consensusProvider) {
+		consensusURL = ""
+	}
+	egressEndpoints := []egressEndpoint{
+		{"github", githubOptions.BaseURL},
+		{"github-proxy", githubOptions.ProxyURL},
+		{"gitlab", gitlab.DefaultAPIURL},
+		{"llm", llmURL},
+		{"consensus-llm", consensusURL},
+		{"slack", os.Getenv("SLACK_WEBHOOK_URL")},
+		{"telemetry", telemetryEndpoint},
+		{"cloudevents", cloudEventsSink},
+	}
+	for _, allowed := range strings.Split(egressAllow, ",") {
+		egressEndpoints = append(egressEndpoints, egressEndpoint{"allowed", strings.TrimSpace(allowed)})
+	}
+	egressNamespace := egress.CurrentNamespace()
+	destinations, err := egressDestinations(egressNamespace, egressEndpoints)
+	if err != nil {
+		setupLog.Error(err, "invalid endpoint")
+		os.Exit(1)
+	}
+	if egressReport {
+		printEgressReport(egressNamespace, destinations)
+		os.Exit(0)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -99,6 +464,106 @@ func main() {
 		tlsOpts = append(tlsOpts, disableHTTP2)
 	}
 
+	metrics.SetCardinality(metricsCardinality)
+
+	hostLimits, err := httpx.ParseHostLimits(httpHostLimits)
+	if err != nil {
+		setupLog.Error(err, "invalid HTTP host limits")
+		os.Exit(1)
+	}
+	httpx.SetLimits(httpx.Limits{PerHost: httpMaxRequestsPerHost, Hosts: hostLimits})
+
+	var complianceSettings *compliance.Mode
+	if complianceMode {
+		complianceSettings = &compliance.Mode{}
+		for _, provider := range strings.Split(complianceLLMProviders, ",") {
+			if provider = strings.TrimSpace(provider); provider != "" {
+				complianceSettings.AllowedLLMProviders = append(complianceSettings.AllowedLLMProviders, provider)
+			}
+		}
+		if err := complianceSettings.EnforceOutbound(); err != nil {
+			setupLog.Error(err, "unable to restrict outbound TLS for compliance mode")
+			os.Exit(1)
+		}
+		tlsOpts = append(tlsOpts, complianceSettings.ConfigureTLS)
+		setupLog.Info("compliance mode enabled", "fipsModule", complianceSettings.Attest().FIPSModule)
+	}
+
+	if complianceSettings != nil {
+		if err := complianceSettings.CheckLLM(llmProvider, llmEndpoint); err != nil {
+			setupLog.Error(err, "LLM backend not allowed", "provider", llmProvider)
+			os.Exit(1)
+		}
+		if consensusProvider != "" {
+			if err := complianceSettings.CheckLLM(consensusProvider, consensusEndpoint); err != nil {
+				setupLog.Error(err, "consensus LLM backend not allowed", "provider", consensusProvider)
+				os.Exit(1)
+			}
+		}
+	}
+
+	// Create the default LLM client; reviews may select another backend in their spec
+	llmFactory := llm.NewDefaultFactory()
+	llmFactory.SetRetryPolicy(retryPolicy)
+	switch {
+	case llmCacheRedis != "":
+		llmFactory.SetCache(llm.NewRedisCache(llm.RedisConfig{
+			Address:  llmCacheRedis,
+			Password: os.Getenv("REDIS_PASSWORD"),
+			DB:       llmCacheRedisDB,
+		}), llmCacheTTL)
+	case llmCacheSize > 0:
+		llmFactory.SetCache(llm.NewMemoryCache(llmCacheSize), llmCacheTTL)
+	}
+	llmClient, err := llmFactory.Create(llmProvider, llm.Config{
+		Endpoint:        llmEndpoint,
+		APIKey:          os.Getenv("LLM_API_KEY"),
+		Model:           llmModel,
+		ContextWindow:   llmContextWindow,
+		Region:          llmRegion,
+		Project:         llmProject,
+		TrustedEndpoint: true,
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to create LLM client", "provider", llmProvider)
+		os.Exit(1)
+	}
+
+	var consensusClient llm.Client
+	if consensusProvider != "" {
+		switch llm.ConsensusMode(consensusMode) {
+		case llm.ConsensusAgree, llm.ConsensusAnnotate:
+		default:
+			setupLog.Error(nil, "unsupported consensus mode", "mode", consensusMode)
+			os.Exit(1)
+		}
+		consensusClient, err = llmFactory.Create(consensusProvider, llm.Config{
+			Endpoint:        consensusEndpoint,
+			APIKey:          os.Getenv("CONSENSUS_LLM_API_KEY"),
+			Model:           consensusModel,
+			ContextWindow:   llmContextWindow,
+			Region:          llmRegion,
+			Project:         llmProject,
+			TrustedEndpoint: true,
+		})
+		if err != nil {
+			setupLog.Error(err, "unable to create consensus LLM client", "provider", consensusProvider)
+			os.Exit(1)
+		}
+	}
+
+	if selftestMode {
+		selftest(selftestOptions{
+			LLM:        llmClient,
+			Consensus:  consensusClient,
+			Git:        gitFactory,
+			Provider:   selftestProvider,
+			Repository: selftestRepository,
+			Token:      os.Getenv(selftestTokenEnv),
+			Timeout:    selftestTimeout,
+		})
+	}
+
 	webhookServer := webhook.NewServer(webhook.Options{
 		TLSOpts: tlsOpts,
 	})
@@ -150,7 +615,263 @@ func main() {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
+	// Refreshed OAuth2 tokens rotate the refresh token kept in the Secret
+	gitFactory.RegisterTokenSource(reviewv1alpha1.AuthTypeOAuth2,
+		func(provider string, secret *corev1.Secret, key string) (git.TokenSource, error) {
+			return git.NewRefreshingTokenSourceFromSecret(mgr.GetClient(), provider, secret, key)
+		})
+
+	publishers := review.NewDefaultPublisherRegistry()
+	pipeline := review.NewDefaultPipeline(llmClient, publishers)
+	pipeline.Use(review.LintOnlyFallback(lintOnlyFallback))
+	if reviewSigningKey != "" {
+		signer, err := provenance.LoadSigner(reviewSigningKey)
+		if err != nil {
+			setupLog.Error(err, "unable to load review signing key")
+			os.Exit(1)
+		}
+		pipeline.Replace(review.StagePublish, "provenance", review.RecordProvenance(signer))
+		setupLog.Info("signing review provenance", "keyID", signer.KeyID())
+	}
+	if markFixedComments {
+		pipeline.Register(review.StagePublish, "fixed", review.MarkFixedComments)
+	}
+	if containerProfile {
+		pipeline.Register(review.StageEnrich, "container", review.CheckContainerFiles)
+	}
+	if kubernetesProfile {
+		pipeline.Register(review.StageEnrich, "kubernetes", review.CheckKubernetesFiles)
+	}
+	if repositoryContext {
+		pipeline.Register(review.StageEnrich, "context", review.GatherContext(review.ContextOptions{
+			MaxTokens: repositoryContextTokens,
+		}))
+	}
+	var memoryStore *memory.ConfigMapStore
+	var costGuard *controller.CostGuard
+	if memoryNamespace != "" {
+		memoryStore = memory.NewConfigMapStore(mgr.GetClient(), memoryNamespace)
+		pipeline.Register(review.StageEnrich, "memory", review.RecallMemory(memoryStore))
+		pipeline.Register(review.StagePublish, "memory", review.RememberReview(memoryStore))
+
+		// Chunk sizes learned for each model outlive the operator's pod
+		pipeline.Replace(review.StageReview, "llm", review.ReviewCode(llmClient, review.ChunkOptions{
+			Limits: memory.NewModelRegistry(mgr.GetClient(), memoryNamespace),
+		}))
+
+		// Escalated findings may change the review decision, so escalate first
+		var slack *notify.SlackClient
+		if webhookURL := os.Getenv("SLACK_WEBHOOK_URL"); webhookURL != "" {
+			slack = notify.NewSlackClient(webhookURL)
+		}
+		pipeline.RegisterBefore(review.StagePostprocess, "decision", "escalation", review.EscalateFindings(memoryStore, slack))
 
+		// Rules in cooldown are held back before per-rule caps are counted
+		pipeline.RegisterBefore(review.StagePostprocess, "rule-limits", "cooldowns", review.CooldownRules(memoryStore))
+
+		if costRegressionThreshold > 0 {
+			costGuard = &controller.CostGuard{
+				Store:      memoryStore,
+				Threshold:  costRegressionThreshold,
+				MinReviews: costRegressionReviews,
+				Slack:      slack,
+			}
+		}
+	} else if costRegressionThreshold > 0 {
+		setupLog.Error(nil, "--cost-regression-threshold needs --reviewer-memory-namespace")
+		os.Exit(1)
+	}
+
+	if egressPolicy {
+		if egressNamespace == "" {
+			setupLog.Error(nil, "unable to determine the operator namespace for the egress network policy; set POD_NAMESPACE")
+			os.Exit(1)
+		}
+		if err := mgr.Add(&egress.PolicyManager{
+			Client:       mgr.GetClient(),
+			Name:         egress.DefaultPolicyName,
+			Namespace:    egressNamespace,
+			PodLabels:    map[string]string{"control-plane": "controller-manager"},
+			Destinations: destinations,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up egress network policy")
+			os.Exit(1)
+		}
+	}
+
+	if retention.Reviews > 0 || retention.Summaries > 0 || retention.Findings > 0 {
+		if err := mgr.Add(&controller.RetentionPurger{
+			Client:   mgr.GetClient(),
+			Memory:   memoryStore,
+			Policy:   retention,
+			Interval: retentionInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up retention purger")
+			os.Exit(1)
+		}
+	}
+
+	if (promptConfigMap != "" || operatorConfigMap != "") && egressNamespace == "" {
+		setupLog.Error(nil, "unable to determine the operator namespace for the operator and prompt template ConfigMaps; set POD_NAMESPACE")
+		os.Exit(1)
+	}
+	operatorConfig := &controller.OperatorConfigStore{
+		Reader:          mgr.GetClient(),
+		ConfigMap:       types.NamespacedName{Namespace: egressNamespace, Name: operatorConfigMap},
+		PromptConfigMap: types.NamespacedName{Namespace: egressNamespace, Name: promptConfigMap},
+	}
+
+	var telemetryReporter *telemetry.Reporter
+	if telemetryEndpoint != "" {
+		telemetryReporter = telemetry.NewReporter(telemetryEndpoint, telemetry.DefaultReportInterval)
+		if err := mgr.Add(telemetryReporter); err != nil {
+			setupLog.Error(err, "unable to set up telemetry")
+			os.Exit(1)
+		}
+	}
+
+	prices, err := llm.ParsePrices(tokenPrices)
+	if err != nil {
+		setupLog.Error(err, "invalid token prices")
+		os.Exit(1)
+	}
+
+	configLoader := repoconfig.NewLoader(orgConfigInterval)
+	if err := mgr.Add(configLoader); err != nil {
+		setupLog.Error(err, "unable to set up config loader")
+		os.Exit(1)
+	}
+
+	// Reconciling against outdated CRDs would silently drop the fields they lack
+	crdSkew, err := controller.CheckCRDs(context.Background(), mgr.GetAPIReader())
+	if err != nil {
+		setupLog.Error(err, "unable to check the installed CRDs")
+	} else if len(crdSkew) > 0 {
+		switch crdSkewPolicy {
+		case controller.CRDSkewRefuse:
+			setupLog.Error(nil, "installed CRDs do not match the operator; install its CRDs or set --crd-skew-policy",
+				"problems", crdSkew)
+			os.Exit(1)
+		case controller.CRDSkewReadOnly:
+			setupLog.Error(nil, "installed CRDs do not match the operator, holding reviews", "problems", crdSkew)
+		case controller.CRDSkewIgnore:
+			setupLog.Info("installed CRDs do not match the operator", "problems", crdSkew)
+			crdSkew = nil
+		}
+	}
+
+	var historyStore history.Store
+	switch {
+	case historyFile != "" && historyDriver != "":
+		setupLog.Error(nil, "--history-file and --history-database-driver are mutually exclusive")
+		os.Exit(1)
+	case historyFile != "":
+		historyStore = history.NewFileStore(historyFile)
+	case historyDriver != "":
+		sqlStore, err := history.OpenSQLStore(context.Background(), historyDriver, os.Getenv("HISTORY_DATABASE_DSN"))
+		if err != nil {
+			setupLog.Error(err, "unable to open the review history database")
+			os.Exit(1)
+		}
+		defer sqlStore.Close()
+		historyStore = sqlStore
+	}
+	if historyAddr != "0" {
+		if historyStore == nil {
+			setupLog.Error(nil, "--history-bind-address needs --history-file or --history-database-driver")
+			os.Exit(1)
+		}
+		if err := mgr.Add(&history.Server{Addr: historyAddr, Store: historyStore}); err != nil {
+			setupLog.Error(err, "unable to set up the review history API")
+			os.Exit(1)
+		}
+	}
+
+	reviewReconciler := &controller.CodeReviewReconciler{
+		Client:               mgr.GetClient(),
+		APIReader:            mgr.GetAPIReader(),
+		Scheme:               mgr.GetScheme(),
+		Recorder:             mgr.GetEventRecorderFor("codereview-controller"),
+		GitFactory:           gitFactory,
+		LLMFactory:           llmFactory,
+		DefaultLLM:           llmClient,
+		ConsensusLLM:         consensusClient,
+		ConsensusMode:        llm.ConsensusMode(consensusMode),
+		Pipeline:             pipeline,
+		ConfigLoader:         configLoader,
+		DefaultConfig:        &repoconfig.Config{},
+		MaxConcurrentReviews: maxConcurrentReviews,
+		Scheduler: scheduler.New(scheduler.Limits{
+			Global:        maxConcurrentReviews,
+			PerProvider:   maxReviewsPerProvider,
+			PerRepository: maxReviewsPerRepository,
+		}),
+		Compliance:         complianceSettings,
+		DefaultLLMProvider: llmProvider,
+		OperatorConfig:     operatorConfig,
+		Telemetry:          telemetryReporter,
+		Prices:             prices,
+		DryRun:             dryRun,
+		TenantIsolation:    tenantIsolation,
+		CRDSkew:            crdSkew,
+		CostGuard:          costGuard,
+		History:            historyStore,
+	}
+	if cloudEventsSink != "" {
+		reviewReconciler.CloudEvents, err = cloudevents.NewSink(cloudEventsSink)
+		if err != nil {
+			setupLog.Error(err, "invalid CloudEvents sink")
+			os.Exit(1)
+		}
+	}
+	if gitCacheSize > 0 {
+		reviewReconciler.ContentCache = git.NewContentCache(int64(gitCacheSize)<<20, gitCacheTTL)
+	}
+	if err = reviewReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CodeReview")
+		os.Exit(1)
+	}
+	if queueAddr != "0" {
+		if err := mgr.Add(&queue.Server{
+			Addr:    queueAddr,
+			Manager: &controller.QueueManager{Reconciler: reviewReconciler},
+			Token:   os.Getenv("QUEUE_API_TOKEN"),
+		}); err != nil {
+			setupLog.Error(err, "unable to set up the review queue API")
+			os.Exit(1)
+		}
+	}
+	if gitWebhookAddr != "0" {
+		secret := os.Getenv("GITHUB_WEBHOOK_SECRET")
+		if secret == "" {
+			setupLog.Error(nil, "the Git webhook endpoint needs a secret; set GITHUB_WEBHOOK_SECRET")
+			os.Exit(1)
+		}
+		if err := mgr.Add(&controller.GitWebhookServer{
+			Addr:      gitWebhookAddr,
+			Secret:    []byte(secret),
+			Namespace: gitWebhookNamespace,
+			Reviews:   reviewReconciler,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up the Git webhook endpoint")
+			os.Exit(1)
+		}
+	}
+	if operatorConfigMap != "" || promptConfigMap != "" {
+		if err = (&controller.OperatorConfigReconciler{
+			Client: mgr.GetClient(),
+			Store:  operatorConfig,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "OperatorConfig")
+			os.Exit(1)
+		}
+	}
+	if err = (&controller.ReviewPromptReconciler{
+		Client: mgr.GetClient(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ReviewPrompt")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -161,10 +882,61 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	// Not ready to review until the informer caches have synced
+	if err := mgr.AddReadyzCheck("informers", func(req *http.Request) error {
+		if !mgr.GetCache().WaitForCacheSync(req.Context()) {
+			return fmt.Errorf("informer caches have not synced")
+		}
+		return nil
+	}); err != nil {
+		setupLog.Error(err, "unable to set up ready check")
+		os.Exit(1)
+	}
 
-	setupLog.Info("starting manager")
+	setupLog.Info("starting manager", "version", provenance.OperatorVersion())
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
+
+// egressEndpoint is an endpoint URL the operator connects to
+type egressEndpoint struct {
+	purpose string
+	url     string
+}
+
+// egressDestinations converts the configured endpoints to egress destinations, skipping unset ones
+func egressDestinations(namespace string, endpoints []egressEndpoint) ([]egress.Destination, error) {
+	var destinations []egress.Destination
+	for _, endpoint := range endpoints {
+		if endpoint.url == "" {
+			continue
+		}
+		destination, err := egress.ParseURL(endpoint.purpose, endpoint.url, namespace)
+		if err != nil {
+			return nil, err
+		}
+		destinations = append(destinations, destination)
+	}
+
+	return destinations, nil
+}
+
+// printEgressReport prints the destinations and the NetworkPolicy allowing them
+func printEgressReport(namespace string, destinations []egress.Destination) {
+	resolved, err := egress.Resolve(context.Background(), destinations)
+	if err != nil {
+		setupLog.Error(err, "unable to resolve every destination, addresses are incomplete")
+	}
+	fmt.Print(egress.Report(resolved))
+	fmt.Println("kubernetes API server: the endpoints of the default/kubernetes service, resolved at runtime")
+
+	policy, err := yaml.Marshal(egress.NetworkPolicy(egress.DefaultPolicyName, namespace,
+		map[string]string{"control-plane": "controller-manager"}, resolved))
+	if err != nil {
+		setupLog.Error(err, "unable to render network policy")
+		os.Exit(1)
+	}
+	fmt.Printf("---\n%s", policy)
+}
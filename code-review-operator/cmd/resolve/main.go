@@ -0,0 +1,125 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command resolve bulk-resolves or dismisses the operator's findings matching
+// a rule, severity or path across a repository's open pull requests, such as
+// after a rule that produced noise everywhere is disabled.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/git/github"
+	"github.com/Shridhar2104/code-review-operator/pkg/git/gitlab"
+	"github.com/Shridhar2104/code-review-operator/pkg/review"
+)
+
+func main() {
+	var provider, owner, repo, tokenEnv string
+	var options review.ResolveOptions
+	var output string
+	flag.StringVar(&provider, "provider", "github", "Git provider: github or gitlab.")
+	flag.StringVar(&owner, "owner", "", "Owner of the repository.")
+	flag.StringVar(&repo, "repo", "", "Name of the repository.")
+	flag.StringVar(&tokenEnv, "token-env", "GIT_TOKEN", "Environment variable holding the provider token.")
+	flag.StringVar(&options.Filter.Rule, "rule", "", "Only act on findings of this rule.")
+	flag.StringVar(&options.Filter.Severity, "severity", "", "Only act on findings of this severity.")
+	flag.StringVar(&options.Filter.Path, "path", "", "Only act on findings on files matching this glob.")
+	flag.StringVar(&options.Action, "action", review.ActionResolve, "Action: resolve the findings' threads or dismiss (delete) the findings.")
+	flag.StringVar(&options.Reason, "reason", "", "Reply posted to findings before resolving them.")
+	flag.BoolVar(&options.DryRun, "dry-run", false, "List the matching findings without changing them.")
+	flag.StringVar(&output, "output", "text", "Output format: text or json.")
+	flag.Parse()
+
+	if err := run(provider, owner, repo, os.Getenv(tokenEnv), options, output); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run applies the bulk action and prints the findings it was applied to
+func run(provider, owner, repo, token string, options review.ResolveOptions, output string) error {
+	if owner == "" || repo == "" {
+		return fmt.Errorf("--owner and --repo are required")
+	}
+	if options.Filter.IsEmpty() {
+		return fmt.Errorf("at least one of --rule, --severity or --path is required")
+	}
+	if token == "" {
+		return fmt.Errorf("no provider token in the environment")
+	}
+	if output != "text" && output != "json" {
+		return fmt.Errorf("unknown output format %q", output)
+	}
+
+	factory := git.NewFactory()
+	factory.Register("github", github.NewClient)
+	factory.Register("gitlab", gitlab.NewClient)
+	client, err := factory.Create(provider, git.NewStaticTokenSource(token))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	findings, err := review.ResolveFindings(ctx, client, owner, repo, options)
+	printFindings(findings, options, output)
+	if err != nil {
+		return err
+	}
+	for _, finding := range findings {
+		if finding.Error != "" {
+			return fmt.Errorf("the action failed on some findings")
+		}
+	}
+
+	return nil
+}
+
+// printFindings prints the findings a bulk action was applied to
+func printFindings(findings []review.ResolvedFinding, options review.ResolveOptions, output string) {
+	if output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(findings)
+		return
+	}
+
+	verb := map[string]string{review.ActionResolve: "resolved", review.ActionDismiss: "dismissed"}[options.Action]
+	if options.DryRun {
+		verb = "would be " + verb
+	}
+	failed := 0
+	for _, finding := range findings {
+		status := verb
+		if finding.Error != "" {
+			status = "failed: " + finding.Error
+			failed++
+		}
+		fmt.Printf("#%d %s:%d (%s): %s\n", finding.PullRequest, finding.File, finding.Line, finding.Rule, status)
+	}
+	fmt.Printf("Findings matched: %d\n", len(findings))
+	if failed > 0 {
+		fmt.Printf("Failed:           %d\n", failed)
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+	"github.com/Shridhar2104/code-review-operator/pkg/policy"
+)
+
+// selftestDiff is the change reviewed by the self-test. It builds an SQL query
+// from user input, so a working backend has something to say about it.
+const selftestDiff = `diff --git a/store/users.go b/store/users.go
+index 3b18e51..a9c2f04 100644
+--- a/store/users.go
++++ b/store/users.go
+@@ -10,3 +10,11 @@ type Store struct {
+ func New(db *sql.DB) *Store {
+ 	return &Store{db: db}
+ }
++
++// FindUser looks a user up by name
++func (s *Store) FindUser(name string) (*User, error) {
++	row := s.db.QueryRow("SELECT id, name FROM users WHERE name = '" + name + "'")
++	var user User
++	row.Scan(&user.ID, &user.Name)
++	return &user, nil
++}
+`
+
+// selftestOptions configures the self-test
+type selftestOptions struct {
+	// LLM and Consensus are the configured LLM clients; Consensus may be nil
+	LLM       llm.Client
+	Consensus llm.Client
+
+	// Git creates the client checked against Repository, an owner/name
+	// sandbox repository. The Git check is skipped if Repository is empty.
+	Git        *git.Factory
+	Provider   string
+	Repository string
+	Token      string
+
+	// Timeout bounds each check
+	Timeout time.Duration
+}
+
+// selftestCheck is the outcome of one self-test check
+type selftestCheck struct {
+	Name    string
+	Err     error
+	Skipped bool
+	Detail  string
+}
+
+// runSelftest reviews the canned diff with each configured LLM backend,
+// validates what they answered and checks the Git credentials
+func runSelftest(ctx context.Context, opts selftestOptions) []selftestCheck {
+	checks := selftestLLM(ctx, "llm", opts.LLM, opts.Timeout)
+	if opts.Consensus != nil {
+		checks = append(checks, selftestLLM(ctx, "consensus-llm", opts.Consensus, opts.Timeout)...)
+	}
+	return append(checks, selftestGit(ctx, opts))
+}
+
+// selftestLLM reviews the canned diff and validates the parsed result
+func selftestLLM(ctx context.Context, name string, client llm.Client, timeout time.Duration) []selftestCheck {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	review := selftestCheck{Name: name + " review"}
+	schema := selftestCheck{Name: name + " response schema"}
+	start := time.Now()
+	result, err := client.ReviewCode(ctx, selftestDiff, llm.ReviewOptions{
+		Language: "go",
+		SeverityLevels: []string{
+			policy.SeverityCritical, policy.SeverityMajor, policy.SeverityMinor, policy.SeveritySuggestion,
+		},
+		NoCache: true,
+	})
+	if err != nil {
+		review.Err = err
+		schema.Skipped = true
+		schema.Detail = "no response to validate"
+		return []selftestCheck{review, schema}
+	}
+	review.Detail = fmt.Sprintf("%d comments, %d tokens in %s",
+		len(result.Comments), result.TokensUsed, time.Since(start).Round(time.Millisecond))
+	schema.Err = validateSelftestResult(result)
+	return []selftestCheck{review, schema}
+}
+
+// validateSelftestResult checks that a review of the canned diff has a
+// summary or comments, and that every comment points at a changed line with a
+// known severity
+func validateSelftestResult(result *llm.ReviewResult) error {
+	if strings.TrimSpace(result.Summary) == "" && len(result.Comments) == 0 {
+		return errors.New("the response has neither a summary nor comments")
+	}
+	files, err := diff.Parse(selftestDiff)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i, comment := range result.Comments {
+		file := diff.Find(files, comment.File)
+		switch {
+		case file == nil:
+			errs = append(errs, fmt.Errorf("comment %d: file %q is not in the diff", i, comment.File))
+		case file.Line(diff.SideRight, comment.Line) == nil:
+			errs = append(errs, fmt.Errorf("comment %d: line %d of %s is not in the diff", i, comment.Line, comment.File))
+		}
+		if policy.SeverityRank(comment.Severity) == 0 {
+			errs = append(errs, fmt.Errorf("comment %d: unknown severity %q", i, comment.Severity))
+		}
+		if strings.TrimSpace(comment.Content) == "" {
+			errs = append(errs, fmt.Errorf("comment %d: empty content", i))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// selftestGit lists the pull requests of the sandbox repository, which needs
+// working credentials with read access
+func selftestGit(ctx context.Context, opts selftestOptions) selftestCheck {
+	check := selftestCheck{Name: "git credentials"}
+	if opts.Repository == "" {
+		check.Skipped = true
+		check.Detail = "no sandbox repository, set --selftest-repository"
+		return check
+	}
+	check.Name = fmt.Sprintf("git credentials (%s %s)", opts.Provider, opts.Repository)
+	owner, repo, ok := strings.Cut(opts.Repository, "/")
+	if !ok || owner == "" || repo == "" {
+		check.Err = fmt.Errorf("repository %q is not owner/name", opts.Repository)
+		return check
+	}
+	if opts.Token == "" {
+		check.Err = errors.New("no Git token")
+		return check
+	}
+	client, err := opts.Git.Create(opts.Provider, git.NewStaticTokenSource(opts.Token))
+	if err != nil {
+		check.Err = err
+		return check
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	pullRequests, err := client.GetPullRequests(ctx, owner, repo)
+	if err != nil {
+		check.Err = err
+		return check
+	}
+	check.Detail = fmt.Sprintf("%d open pull requests", len(pullRequests))
+	return check
+}
+
+// printSelftestReport writes one line per check and reports whether they all
+// passed; skipped checks do not fail the self-test
+func printSelftestReport(w io.Writer, checks []selftestCheck) bool {
+	passed := true
+	for _, check := range checks {
+		status, detail := "PASS", check.Detail
+		switch {
+		case check.Err != nil:
+			status, detail = "FAIL", check.Err.Error()
+			passed = false
+		case check.Skipped:
+			status = "SKIP"
+		}
+		line := fmt.Sprintf("%s  %s", status, check.Name)
+		if detail != "" {
+			line += ": " + strings.ReplaceAll(detail, "\n", "; ")
+		}
+		fmt.Fprintln(w, line)
+	}
+	if passed {
+		fmt.Fprintln(w, "self-test passed")
+	} else {
+		fmt.Fprintln(w, "self-test failed")
+	}
+	return passed
+}
+
+// selftest runs the self-test and exits, nonzero if a check failed
+func selftest(opts selftestOptions) {
+	if !printSelftestReport(os.Stdout, runSelftest(context.Background(), opts)) {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
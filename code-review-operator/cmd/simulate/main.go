@@ -0,0 +1,120 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command simulate replays the stored findings of past pull requests through
+// a proposed gating policy and reports how many reviews would have been
+// blocked or approved compared to the current policy.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/policy"
+)
+
+func main() {
+	var findingsPath string
+	var current, proposed policy.Policy
+	var currentDisabled, proposedDisabled string
+	var output string
+	var verbose bool
+	flag.StringVar(&findingsPath, "findings", "-", "Path to a JSON array of stored review records, or - for stdin.")
+	flag.StringVar(&current.FailOn, "current-fail-on", policy.SeverityCritical, "Minimum blocking severity of the current policy.")
+	flag.IntVar(&current.MaxFindings, "current-max-findings", 0, "Findings tolerated by the current policy.")
+	flag.StringVar(&currentDisabled, "current-disable-rules", "", "Comma-separated rules ignored by the current policy.")
+	flag.StringVar(&proposed.FailOn, "fail-on", policy.SeverityCritical, "Minimum blocking severity of the proposed policy.")
+	flag.IntVar(&proposed.MaxFindings, "max-findings", 0, "Findings tolerated by the proposed policy.")
+	flag.StringVar(&proposedDisabled, "disable-rules", "", "Comma-separated rules ignored by the proposed policy.")
+	flag.StringVar(&output, "output", "text", "Output format: text or json.")
+	flag.BoolVar(&verbose, "verbose", false, "List every pull request whose decision changes.")
+	flag.Parse()
+
+	current.DisabledRules = splitList(currentDisabled)
+	proposed.DisabledRules = splitList(proposedDisabled)
+
+	records, err := readRecords(findingsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	report := policy.Simulate(records, current, proposed)
+
+	switch output {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	case "text":
+		fmt.Printf("Reviews replayed:   %d\n", report.Total)
+		fmt.Printf("Blocked (current):  %d\n", report.CurrentBlocked)
+		fmt.Printf("Blocked (proposed): %d\n", report.ProposedBlocked)
+		fmt.Printf("Newly blocked:      %d\n", report.NewlyBlocked)
+		fmt.Printf("Newly approved:     %d\n", report.NewlyApproved)
+		if verbose {
+			for _, outcome := range report.Outcomes {
+				if !outcome.Changed() {
+					continue
+				}
+				r := outcome.Record
+				fmt.Printf("  %s/%s#%d: %s\n", r.Owner, r.Repository, r.PullRequest, outcome.Proposed.Reason)
+			}
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "error: unknown output format %q\n", output)
+		os.Exit(1)
+	}
+}
+
+// readRecords reads stored review records from a file or stdin
+func readRecords(path string) ([]policy.Record, error) {
+	var reader io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("error opening findings: %w", err)
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	var records []policy.Record
+	if err := json.NewDecoder(reader).Decode(&records); err != nil {
+		return nil, fmt.Errorf("error parsing findings: %w", err)
+	}
+
+	return records, nil
+}
+
+// splitList splits a comma-separated flag value
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
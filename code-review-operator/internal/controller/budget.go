@@ -0,0 +1,201 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+	"github.com/Shridhar2104/code-review-operator/pkg/metrics"
+)
+
+// budgetRecheckInterval is how often a paused review checks whether its
+// budget has room again, after a new month starts or its limit is raised
+const budgetRecheckInterval = time.Hour
+
+// +kubebuilder:rbac:groups=review.code-review.io,resources=reviewbudgets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=review.code-review.io,resources=reviewbudgets/status,verbs=get;update;patch
+
+// checkBudget reports whether a review must wait because a ReviewBudget
+// covering it has used its tokens this month, and records the pause in the
+// review's BudgetExceeded condition
+func (r *CodeReviewReconciler) checkBudget(ctx context.Context, review *reviewv1alpha1.CodeReview) (bool, error) {
+	budgets, err := r.coveringBudgets(ctx, review)
+	if err != nil {
+		return false, err
+	}
+
+	period := budgetPeriod(time.Now())
+	condition := metav1.Condition{
+		Type:    reviewv1alpha1.ConditionBudgetExceeded,
+		Status:  metav1.ConditionFalse,
+		Reason:  "WithinBudget",
+		Message: "no review budget is exceeded",
+	}
+	for _, budget := range budgets {
+		if budget.Status.Period == period && budget.Status.TokensUsed >= budget.Spec.MonthlyTokens {
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = "TokenBudgetExceeded"
+			condition.Message = fmt.Sprintf("review budget %s used %d of its %d tokens for %s",
+				budget.Name, budget.Status.TokensUsed, budget.Spec.MonthlyTokens, period)
+			break
+		}
+	}
+
+	paused := condition.Status == metav1.ConditionTrue
+	if meta.FindStatusCondition(review.Status.Conditions, condition.Type) == nil && !paused {
+		return false, nil
+	}
+	if meta.SetStatusCondition(&review.Status.Conditions, condition) {
+		if paused {
+			r.Recorder.Event(review, corev1.EventTypeWarning, "BudgetExceeded", condition.Message)
+		}
+		if err := r.Status().Update(ctx, review); err != nil {
+			return false, err
+		}
+	}
+
+	return paused, nil
+}
+
+// tokenUsage returns the tokens an LLM result used and their estimated cost,
+// or nil if the review did not reach the LLM
+func (r *CodeReviewReconciler) tokenUsage(result *llm.ReviewResult) (*reviewv1alpha1.TokenUsage, float64) {
+	if result == nil || result.TokensUsed == 0 && len(result.Usage) == 0 {
+		return nil, 0
+	}
+
+	usage := &reviewv1alpha1.TokenUsage{TotalTokens: int64(result.TokensUsed)}
+	for _, modelUsage := range result.Usage {
+		usage.PromptTokens += int64(modelUsage.PromptTokens)
+		usage.CompletionTokens += int64(modelUsage.CompletionTokens)
+	}
+	usage.TotalTokens = max(usage.TotalTokens, usage.PromptTokens+usage.CompletionTokens)
+	cost := r.Prices.Cost(result.Usage)
+	if cost > 0 {
+		usage.EstimatedCost = formatCost(cost)
+	}
+
+	return usage, cost
+}
+
+// recordUsage exports the tokens a review used as metrics and charges them to
+// the budgets covering the review. Accounting is best effort: failures are
+// logged, as the review has already been posted.
+func (r *CodeReviewReconciler) recordUsage(ctx context.Context, review *reviewv1alpha1.CodeReview, usage *reviewv1alpha1.TokenUsage, cost float64) {
+	if usage == nil {
+		return
+	}
+	logger := log.FromContext(ctx)
+
+	repository := metrics.RepositoryLabel(review.Spec.Owner + "/" + review.Spec.Repository)
+	metrics.ReviewTokens.WithLabelValues(review.Namespace, repository, metrics.TokenTypePrompt).Add(float64(usage.PromptTokens))
+	metrics.ReviewTokens.WithLabelValues(review.Namespace, repository, metrics.TokenTypeCompletion).Add(float64(usage.CompletionTokens))
+	metrics.ReviewCost.WithLabelValues(review.Namespace, repository).Add(cost)
+
+	budgets, err := r.coveringBudgets(ctx, review)
+	if err != nil {
+		logger.Error(err, "unable to charge review to budgets")
+		return
+	}
+	for _, budget := range budgets {
+		if err := r.chargeBudget(ctx, client.ObjectKeyFromObject(&budget), usage.TotalTokens, cost); err != nil {
+			logger.Error(err, "unable to charge review to budget", "budget", budget.Name)
+		}
+	}
+}
+
+// chargeBudget adds tokens and their cost to a budget's usage this month,
+// starting the count over when a new month has begun
+func (r *CodeReviewReconciler) chargeBudget(ctx context.Context, key client.ObjectKey, tokens int64, cost float64) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var budget reviewv1alpha1.ReviewBudget
+		if err := r.Get(ctx, key, &budget); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+
+		if period := budgetPeriod(time.Now()); budget.Status.Period != period {
+			budget.Status.Period = period
+			budget.Status.TokensUsed = 0
+			budget.Status.EstimatedCost = ""
+		}
+		budget.Status.TokensUsed += tokens
+		spent, _ := strconv.ParseFloat(budget.Status.EstimatedCost, 64)
+		if spent+cost > 0 {
+			budget.Status.EstimatedCost = formatCost(spent + cost)
+		}
+
+		condition := metav1.Condition{
+			Type:    reviewv1alpha1.ConditionExceeded,
+			Status:  metav1.ConditionFalse,
+			Reason:  "WithinBudget",
+			Message: fmt.Sprintf("%d of %d tokens used in %s", budget.Status.TokensUsed, budget.Spec.MonthlyTokens, budget.Status.Period),
+		}
+		if budget.Status.TokensUsed >= budget.Spec.MonthlyTokens {
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = "TokenBudgetExceeded"
+		}
+		meta.SetStatusCondition(&budget.Status.Conditions, condition)
+		if err := r.Status().Update(ctx, &budget); err != nil {
+			return err
+		}
+
+		metrics.BudgetTokensUsed.WithLabelValues(budget.Namespace, budget.Name).Set(float64(budget.Status.TokensUsed))
+		metrics.BudgetTokensLimit.WithLabelValues(budget.Namespace, budget.Name).Set(float64(budget.Spec.MonthlyTokens))
+		return nil
+	})
+}
+
+// coveringBudgets returns the ReviewBudgets in a review's namespace that
+// cover the whole namespace or the review's repository
+func (r *CodeReviewReconciler) coveringBudgets(ctx context.Context, review *reviewv1alpha1.CodeReview) ([]reviewv1alpha1.ReviewBudget, error) {
+	var list reviewv1alpha1.ReviewBudgetList
+	if err := r.List(ctx, &list, client.InNamespace(review.Namespace)); err != nil {
+		return nil, fmt.Errorf("error listing review budgets: %w", err)
+	}
+
+	repository := review.Spec.Owner + "/" + review.Spec.Repository
+	var budgets []reviewv1alpha1.ReviewBudget
+	for _, budget := range list.Items {
+		if budget.Spec.Repository == "" || budget.Spec.Repository == repository {
+			budgets = append(budgets, budget)
+		}
+	}
+
+	return budgets, nil
+}
+
+// budgetPeriod returns the calendar month, in UTC, usage at a time counts towards
+func budgetPeriod(now time.Time) string {
+	return now.UTC().Format("2006-01")
+}
+
+// formatCost formats an estimated cost in US dollars
+func formatCost(cost float64) string {
+	return strconv.FormatFloat(cost, 'f', 4, 64)
+}
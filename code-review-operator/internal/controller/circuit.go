@@ -0,0 +1,68 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+	reviewpkg "github.com/Shridhar2104/code-review-operator/pkg/review"
+)
+
+// waitForLLM pauses a review whose LLM backend's circuit breaker is open,
+// records the pause in the LLMUnavailable condition, and retries the review
+// once the breaker lets requests through again
+func (r *CodeReviewReconciler) waitForLLM(ctx context.Context, review *reviewv1alpha1.CodeReview, job *reviewpkg.Job, circuitErr *llm.CircuitOpenError) (ctrl.Result, error) {
+	reviewpkg.AbortProgress(ctx, job, circuitErr)
+	log.FromContext(ctx).Info("review waiting for its LLM backend", "llmProvider", circuitErr.Provider, "retryAfter", circuitErr.RetryAfter)
+
+	condition := metav1.Condition{
+		Type:    reviewv1alpha1.ConditionLLMUnavailable,
+		Status:  metav1.ConditionTrue,
+		Reason:  "CircuitOpen",
+		Message: circuitErr.Error(),
+	}
+	if meta.SetStatusCondition(&review.Status.Conditions, condition) {
+		r.Recorder.Event(review, corev1.EventTypeWarning, "LLMUnavailable", circuitErr.Error())
+		if err := r.Status().Update(ctx, review); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{RequeueAfter: circuitErr.RetryAfter}, nil
+}
+
+// clearLLMUnavailable marks the review's LLM backend available again, if the
+// review waited for it
+func clearLLMUnavailable(review *reviewv1alpha1.CodeReview) {
+	if meta.FindStatusCondition(review.Status.Conditions, reviewv1alpha1.ConditionLLMUnavailable) == nil {
+		return
+	}
+	meta.SetStatusCondition(&review.Status.Conditions, metav1.Condition{
+		Type:    reviewv1alpha1.ConditionLLMUnavailable,
+		Status:  metav1.ConditionFalse,
+		Reason:  "LLMAvailable",
+		Message: "the LLM backend answered",
+	})
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/cloudevents"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/notify"
+	"github.com/Shridhar2104/code-review-operator/pkg/policy"
+)
+
+// emitReviewEvent emits a CloudEvent of a review's lifecycle to the
+// operator's sink, if any, with the outcome of finished reviews. Dry runs
+// emit nothing. Errors are logged; they do not fail the review.
+func (r *CodeReviewReconciler) emitReviewEvent(ctx context.Context, cr *reviewv1alpha1.CodeReview, eventType string, outcome *notify.Event) {
+	if r.CloudEvents == nil || r.dryRun(cr) {
+		return
+	}
+
+	data := cloudevents.ReviewData{
+		Namespace:   cr.Namespace,
+		Name:        cr.Name,
+		Provider:    cr.Spec.Provider,
+		Owner:       cr.Spec.Owner,
+		Repository:  cr.Spec.Repository,
+		PullRequest: cr.Spec.PullRequest,
+		CommitSHA:   cr.Spec.CommitSHA,
+	}
+	if outcome != nil {
+		data.URL, data.Decision, data.Severities = outcome.URL, outcome.Decision, outcome.Severities
+		data.Reason, data.Message = outcome.Reason, outcome.Message
+	}
+	r.emit(ctx, cr, fmt.Sprintf("%s/%s", cr.UID, eventType), eventType, data)
+}
+
+// emitCriticalFindings emits a finding.critical CloudEvent for every
+// critical comment of a posted review
+func (r *CodeReviewReconciler) emitCriticalFindings(ctx context.Context, cr *reviewv1alpha1.CodeReview, comments []git.ReviewComment) {
+	if r.CloudEvents == nil || r.dryRun(cr) {
+		return
+	}
+
+	for i, comment := range comments {
+		if comment.Severity != policy.SeverityCritical {
+			continue
+		}
+		r.emit(ctx, cr, fmt.Sprintf("%s/finding/%d", cr.UID, i), cloudevents.TypeFindingCritical, cloudevents.FindingData{
+			Namespace:   cr.Namespace,
+			Name:        cr.Name,
+			Owner:       cr.Spec.Owner,
+			Repository:  cr.Spec.Repository,
+			PullRequest: cr.Spec.PullRequest,
+			CommitSHA:   cr.Spec.CommitSHA,
+			File:        comment.File,
+			Line:        comment.Line,
+			Severity:    comment.Severity,
+			Rule:        comment.Rule,
+			Language:    comment.Language,
+			Message:     comment.Content,
+			Link:        comment.Link,
+		})
+	}
+}
+
+// emit sends a CloudEvent about a review to the sink
+func (r *CodeReviewReconciler) emit(ctx context.Context, cr *reviewv1alpha1.CodeReview, id, eventType string, data interface{}) {
+	source := fmt.Sprintf("/namespaces/%s/codereviews/%s", cr.Namespace, cr.Name)
+	subject := fmt.Sprintf("%s/%s", cr.Spec.Owner, cr.Spec.Repository)
+	if cr.Spec.PullRequest != 0 {
+		subject = fmt.Sprintf("%s#%d", subject, cr.Spec.PullRequest)
+	}
+
+	if err := r.CloudEvents.Send(ctx, cloudevents.New(id, source, eventType, subject, data)); err != nil {
+		log.FromContext(ctx).Error(err, "unable to emit CloudEvent", "type", eventType)
+	}
+}
@@ -0,0 +1,822 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/cloudevents"
+	"github.com/Shridhar2104/code-review-operator/pkg/compliance"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/history"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+	"github.com/Shridhar2104/code-review-operator/pkg/metrics"
+	"github.com/Shridhar2104/code-review-operator/pkg/notify"
+	"github.com/Shridhar2104/code-review-operator/pkg/policy"
+	"github.com/Shridhar2104/code-review-operator/pkg/repoconfig"
+	reviewpkg "github.com/Shridhar2104/code-review-operator/pkg/review"
+	"github.com/Shridhar2104/code-review-operator/pkg/scheduler"
+	"github.com/Shridhar2104/code-review-operator/pkg/telemetry"
+)
+
+// CodeReviewReconciler reconciles a CodeReview object
+type CodeReviewReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// APIReader reads the Secrets of credentials other than plain tokens
+	// straight from the API server, as the operator rotates some of them
+	// itself. They are read from the cache when nil.
+	APIReader client.Reader
+
+	// GitFactory creates Git clients for the provider named in the spec
+	GitFactory *git.Factory
+
+	// LLMFactory creates LLM clients for reviews that select a backend in their spec
+	LLMFactory *llm.Factory
+
+	// DefaultLLM is the LLM client used when the spec does not select a backend
+	DefaultLLM llm.Client
+
+	// ConsensusLLM is the second model used for reviews labeled critical. Consensus mode is disabled when nil.
+	ConsensusLLM llm.Client
+
+	// ConsensusMode controls what consensus reviews do with findings only one model reported
+	ConsensusMode llm.ConsensusMode
+
+	// Pipeline runs the review
+	Pipeline *reviewpkg.Pipeline
+
+	// ConfigLoader reads org-wide and in-repo review configuration
+	ConfigLoader *repoconfig.Loader
+
+	// DefaultConfig holds the operator's built-in review settings, under the
+	// review settings of the operator configuration
+	DefaultConfig *repoconfig.Config
+
+	// MaxConcurrentReviews is the number of reviews run in parallel
+	MaxConcurrentReviews int
+
+	// Scheduler admits reviews under per-provider and per-repository
+	// concurrency limits, smallest first. Reviews run as soon as a worker is
+	// free when nil.
+	Scheduler *scheduler.Scheduler
+
+	// Compliance restricts reviews to approved LLM backends and attests its
+	// settings in the status. Compliance mode is off when nil.
+	Compliance *compliance.Mode
+
+	// DefaultLLMProvider is the backend of DefaultLLM, recorded in compliance attestations and telemetry
+	DefaultLLMProvider string
+
+	// OperatorConfig holds the operator configuration and prompt templates,
+	// reloaded when their ConfigMaps change. The built-in defaults are used
+	// when nil.
+	OperatorConfig *OperatorConfigStore
+
+	// Telemetry collects aggregate usage when telemetry is enabled, nil otherwise
+	Telemetry *telemetry.Reporter
+
+	// Prices estimate the cost of the tokens reviews use
+	Prices llm.Prices
+
+	// CostGuard alerts when review costs jump after a configuration or model
+	// change. Costs are not tracked when nil.
+	CostGuard *CostGuard
+
+	// History records every posted review. Reviews are not recorded when nil.
+	History history.Store
+
+	// DryRun renders every review into its status instead of posting it,
+	// as if each review's spec asked for a dry run
+	DryRun bool
+
+	// CRDSkew lists how the installed CRDs differ from the operator's types.
+	// While it is set the operator runs read-only: reviews are held with the
+	// CRDVersionSkew condition instead of running.
+	CRDSkew []string
+
+	// inflight holds the cancel functions of running reviews
+	inflightMu sync.Mutex
+	inflight   map[types.NamespacedName]context.CancelCauseFunc
+
+	// tokenSources holds the token sources of credentials other than plain
+	// tokens, by credential scope
+	tokenSourcesMu sync.Mutex
+	tokenSources   map[string]git.TokenSource
+
+	// CloudEvents receives CloudEvents of the reviews' lifecycle and
+	// critical findings. No events are emitted when nil.
+	CloudEvents cloudevents.Sink
+
+	// TenantIsolation requires every review to have a ReviewerConfig and
+	// never uses the operator's own LLM backends, so each team's reviews run
+	// only on the credentials and quota the team configured
+	TenantIsolation bool
+
+	// ContentCache holds the diffs and file contents reviews read at their
+	// commit, warmed when pull requests are opened. Content is always read
+	// from the provider when nil.
+	ContentCache *git.ContentCache
+
+	// sizes caches the changed lines of queued reviews
+	sizesMu sync.Mutex
+	sizes   map[types.NamespacedName]reviewSize
+}
+
+// +kubebuilder:rbac:groups=review.code-review.io,resources=codereviews,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=review.code-review.io,resources=codereviews/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=review.code-review.io,resources=codereviews/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=review.code-review.io,resources=reviewprompts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=argoproj.io,resources=appprojects,verbs=get;update;patch
+
+// Reconcile runs the review described by a CodeReview and records the outcome in its status
+func (r *CodeReviewReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var review reviewv1alpha1.CodeReview
+	if err := r.Get(ctx, req.NamespacedName, &review); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.dequeue(req.NamespacedName)
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if isFinished(&review) {
+		r.dequeue(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	if len(r.CRDSkew) > 0 {
+		return ctrl.Result{}, r.holdForCRDSkew(ctx, &review)
+	}
+
+	// Tag every log line of the review, including those of the Git and LLM
+	// clients, so a single review can be traced end to end
+	logger = logger.WithValues(
+		"reviewID", string(review.UID),
+		"provider", review.Spec.Provider,
+		"repository", review.Spec.Owner+"/"+review.Spec.Repository,
+		"pullRequest", review.Spec.PullRequest,
+	)
+	ctx = log.IntoContext(ctx, logger)
+
+	// Cancel reviews of older commits of the pull request, or give way to a newer one
+	superseded, err := r.supersede(ctx, &review)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if superseded {
+		r.dequeue(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	// Hold reviews that have not started while a budget covering them is used up
+	if review.Status.Phase != reviewv1alpha1.CodeReviewPhaseInProgress {
+		paused, err := r.checkBudget(ctx, &review)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if paused {
+			logger.Info("review paused by its token budget")
+			return ctrl.Result{RequeueAfter: budgetRecheckInterval}, nil
+		}
+	}
+
+	// Wait for a slot under the concurrency limits
+	release, admitted := r.admit(ctx, &review)
+	if !admitted {
+		logger.V(1).Info("review queued for a free slot")
+		return ctrl.Result{RequeueAfter: queueRecheckInterval}, nil
+	}
+	defer release()
+
+	// Run the review under a context that a newer push can cancel
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	r.track(req.NamespacedName, cancel)
+	defer r.untrack(req.NamespacedName)
+
+	if review.Status.Phase != reviewv1alpha1.CodeReviewPhaseInProgress {
+		now := metav1.Now()
+		review.Status.Phase = reviewv1alpha1.CodeReviewPhaseInProgress
+		review.Status.StartTime = &now
+		if err := r.Status().Update(ctx, &review); err != nil {
+			return ctrl.Result{}, err
+		}
+		metrics.ReviewsStarted.Inc()
+		r.emitReviewEvent(ctx, &review, cloudevents.TypeReviewStarted, nil)
+	}
+
+	// Stop the review at its deadline, counted from when it started
+	if deadline, ok := deadline(&review); ok {
+		if time.Now().After(deadline) {
+			return r.timeOut(ctx, &review, nil)
+		}
+		var cancelDeadline context.CancelFunc
+		ctx, cancelDeadline = context.WithDeadlineCause(ctx, deadline, errDeadlineExceeded)
+		defer cancelDeadline()
+	}
+
+	gitClient, err := r.reviewClient(ctx, &review)
+	if err != nil {
+		return r.fail(ctx, &review, "GitClientError", err)
+	}
+
+	operatorConfig, err := r.OperatorConfig.Current(ctx)
+	if err != nil {
+		return r.fail(ctx, &review, "OperatorConfigError", err)
+	}
+	review.Status.ConfigGeneration = operatorConfig.Generation
+
+	prompt, err := r.reviewPrompt(ctx, &review)
+	if err != nil {
+		return r.fail(ctx, &review, "PromptError", err)
+	}
+
+	// Work out which settings apply to this review
+	defaults := repoconfig.Merge(r.DefaultConfig, operatorConfig.Config.Review)
+	resolution, err := r.resolveConfig(ctx, gitClient, &review, defaults)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("error resolving review config: %w", err)
+	}
+	config := resolution.Config
+
+	llmClient, err := r.llmClient(ctx, &review, operatorConfig.Config.Models[config.ModelTier])
+	if err != nil {
+		return r.fail(ctx, &review, "LLMClientError", err)
+	}
+	routes, err := r.languageRoutes(ctx, &review, config, operatorConfig.Config.Models)
+	if err != nil {
+		return r.fail(ctx, &review, "LanguageRouteError", err)
+	}
+	allowRepository, err := r.repositoryFilter(ctx, &review)
+	if err != nil {
+		return r.fail(ctx, &review, "GitClientError", err)
+	}
+
+	// Run the review pipeline
+	spec := review.Spec
+	job := &reviewpkg.Job{
+		Client:          gitClient,
+		Owner:           spec.Owner,
+		Repository:      spec.Repository,
+		PullRequest:     spec.PullRequest,
+		CommitSHA:       spec.CommitSHA,
+		Author:          spec.Author,
+		Config:          config,
+		LLM:             llmClient,
+		Routes:          routes,
+		AllowRepository: allowRepository,
+		ReportProgress:  spec.ReportProgress && !r.dryRun(&review),
+		DryRun:          r.dryRun(&review),
+		Options: llm.ReviewOptions{
+			Rules:          config.Rules,
+			SeverityLevels: config.SeverityLevels,
+			MinSeverity:    config.MinSeverity,
+			DisabledRules:  config.DisabledRules,
+			Language:       config.Language,
+			Glossary:       config.Glossary,
+			Include:        config.Include,
+			Exclude:        config.ExcludePatterns(),
+			Repository:     spec.Owner + "/" + spec.Repository,
+			Templates:      operatorConfig.Templates,
+			Prompt:         prompt,
+			Analyzers:      config.Analyzers,
+			NoCache:        spec.BypassCache,
+		},
+	}
+	if spec.Incremental && spec.Diff == nil {
+		job.BaseCommitSHA, err = r.lastReviewedSHA(ctx, &review)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if spec.Decision != nil {
+		job.DecisionPolicy = &policy.ReviewPolicy{
+			ApproveWhenClean: spec.Decision.ApproveWhenClean,
+			RequestChangesOn: spec.Decision.RequestChangesOn,
+			MaxFindings:      spec.Decision.MaxFindings,
+		}
+	}
+	for _, publisher := range spec.Publishers {
+		job.Sinks = append(job.Sinks, publisher.Type)
+	}
+	progress := r.trackProgress(ctx, &review, job)
+	err = r.Pipeline.Run(ctx, job)
+	progress.stop(&review)
+	if err != nil {
+		if errors.Is(context.Cause(ctx), errSuperseded) {
+			// The review that superseded this one has already updated the status
+			reviewpkg.AbortProgress(context.WithoutCancel(ctx), job, errSuperseded)
+			logger.Info("review superseded by a newer commit")
+			return ctrl.Result{}, nil
+		}
+		if errors.Is(context.Cause(ctx), errSkipped) {
+			// The slash command that skipped this review has already updated the status
+			reviewpkg.AbortProgress(context.WithoutCancel(ctx), job, errSkipped)
+			logger.Info("review skipped on request")
+			return ctrl.Result{}, nil
+		}
+		if errors.Is(context.Cause(ctx), errCancelled) {
+			// The queue API that cancelled this review has already updated the status
+			reviewpkg.AbortProgress(context.WithoutCancel(ctx), job, errCancelled)
+			logger.Info("review cancelled through the queue API")
+			return ctrl.Result{}, nil
+		}
+		if errors.Is(context.Cause(ctx), errDeadlineExceeded) {
+			return r.timeOut(ctx, &review, job)
+		}
+		var circuitErr *llm.CircuitOpenError
+		if errors.As(err, &circuitErr) {
+			return r.waitForLLM(ctx, &review, job, circuitErr)
+		}
+		reviewpkg.AbortProgress(ctx, job, err)
+		decision := classifyError(err)
+		if !decision.retry {
+			return r.fail(ctx, &review, "ReviewError", err)
+		}
+		r.Telemetry.RecordError("ReviewError")
+		if decision.after > 0 {
+			logger.Info("retrying review after transient error", "retryAfter", decision.after, "error", err.Error())
+			return ctrl.Result{RequeueAfter: decision.after}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("error running review: %w", err)
+	}
+
+	if len(job.Adjustments) > 0 {
+		adjusted := make([]string, 0, len(job.Adjustments))
+		for _, adjustment := range job.Adjustments {
+			adjusted = append(adjusted, fmt.Sprintf("%s:%d (%s)", adjustment.Comment.File, adjustment.Comment.Line, adjustment.Reason))
+		}
+		r.Recorder.Eventf(&review, corev1.EventTypeNormal, "CommentsAdjusted",
+			"moved or dropped comments outside the diff: %s", strings.Join(adjusted, ", "))
+	}
+
+	if len(job.Escalations) > 0 {
+		escalated := make([]string, 0, len(job.Escalations))
+		for _, escalation := range job.Escalations {
+			escalated = append(escalated, fmt.Sprintf("%s:%d (%d pushes)", escalation.Comment.File, escalation.Comment.Line, escalation.Pushes))
+		}
+		r.Recorder.Eventf(&review, corev1.EventTypeWarning, "FindingsEscalated",
+			"findings unresolved across pushes: %s", strings.Join(escalated, ", "))
+	}
+
+	if job.DryRun {
+		r.renderDryRun(ctx, &review, job)
+	}
+	r.exportReport(ctx, &review, job)
+
+	usage, cost := r.tokenUsage(job.Result)
+	now := metav1.Now()
+	review.Status.Phase = reviewv1alpha1.CodeReviewPhaseCompleted
+	review.Status.ReviewURL = job.ReviewURL
+	review.Status.ReviewedSHA = spec.CommitSHA
+	review.Status.BaseSHA = job.BaseCommitSHA
+	review.Status.CommentCount = len(job.Comments)
+	review.Status.Decision = string(job.Decision)
+	review.Status.LintOnly = job.LintOnly
+	review.Status.Usage = usage
+	review.Status.Compliance = r.attestCompliance(ctx, &review)
+	review.Status.Provenance = reviewProvenance(job)
+	review.Status.CompletionTime = &now
+	if job.LintOnly {
+		r.Recorder.Event(&review, corev1.EventTypeWarning, "LintOnlyReview",
+			"no LLM backend was available; posted the findings of the deterministic checks only")
+	} else {
+		clearLLMUnavailable(&review)
+	}
+	if err := r.Status().Update(ctx, &review); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.recordUsage(ctx, &review, usage, cost)
+	r.guardCost(ctx, &review, config, job.Result, job.Diff, cost)
+	r.recordHistory(ctx, &review, job)
+	r.holdSyncs(ctx, &review, config, job.DeploymentTargets)
+	outcome := notificationEvent(&review, notify.KindCompleted, job.Comments)
+	r.sendNotifications(ctx, &review, outcome)
+	r.emitReviewEvent(ctx, &review, cloudevents.TypeReviewCompleted, outcome)
+	r.emitCriticalFindings(ctx, &review, job.Comments)
+	recordFinished(&review)
+
+	r.Telemetry.RecordReview(string(review.Status.Phase), r.llmProvider(ctx, &review), len(job.Comments))
+
+	if job.DryRun {
+		logger.Info("review rendered in dry run", "comments", len(job.Comments))
+		return ctrl.Result{}, nil
+	}
+	logger.Info("review posted", "url", job.ReviewURL, "comments", len(job.Comments))
+	return ctrl.Result{}, nil
+}
+
+// gitClient creates a Git client for the review's provider. Plain tokens are
+// read from the referenced Secret on every request so rotations take effect
+// immediately. Diffs and files at a commit are read through the content
+// cache, if any.
+func (r *CodeReviewReconciler) gitClient(ctx context.Context, review *reviewv1alpha1.CodeReview) (git.Client, error) {
+	ref, err := r.tokenSecretRef(ctx, review)
+	if err != nil {
+		return nil, err
+	}
+	tokenSource, err := r.tokenSource(ctx, review, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fail fast if the Secret is missing or incomplete
+	if _, err := tokenSource.Token(); err != nil {
+		r.forgetTokenSource(credentialScope(review, ref))
+		return nil, err
+	}
+
+	gitClient, err := r.GitFactory.Create(review.Spec.Provider, tokenSource)
+	if err != nil || r.ContentCache == nil {
+		return gitClient, err
+	}
+
+	return r.ContentCache.Client(gitClient, credentialScope(review, ref)), nil
+}
+
+// tokenSource returns the token source of a review's credentials. Sources
+// of credentials other than plain tokens, such as GitHub App installations,
+// cache the tokens they exchange the credentials for, so they are kept per
+// credentials until a token cannot be had and the Secret is read again.
+func (r *CodeReviewReconciler) tokenSource(ctx context.Context, review *reviewv1alpha1.CodeReview, ref reviewv1alpha1.TokenSecretReference) (git.TokenSource, error) {
+	name := types.NamespacedName{Namespace: review.Namespace, Name: ref.Name}
+	if ref.AuthType() == reviewv1alpha1.AuthTypeToken {
+		return git.NewSecretTokenSource(r.Client, name, ref.Key), nil
+	}
+
+	scope := credentialScope(review, ref)
+	r.tokenSourcesMu.Lock()
+	defer r.tokenSourcesMu.Unlock()
+	if tokenSource, ok := r.tokenSources[scope]; ok {
+		return tokenSource, nil
+	}
+
+	// The cache may not have seen a refresh token the operator just rotated
+	var reader client.Reader = r.Client
+	if r.APIReader != nil {
+		reader = r.APIReader
+	}
+	var secret corev1.Secret
+	if err := reader.Get(ctx, name, &secret); err != nil {
+		return nil, fmt.Errorf("error getting secret %s: %w", name, err)
+	}
+	tokenSource, err := r.GitFactory.CreateTokenSource(ref.AuthType(), review.Spec.Provider, &secret, ref.Key)
+	if err != nil {
+		return nil, err
+	}
+	if r.tokenSources == nil {
+		r.tokenSources = make(map[string]git.TokenSource)
+	}
+	r.tokenSources[scope] = tokenSource
+
+	return tokenSource, nil
+}
+
+// forgetTokenSource drops the token source of credentials, so the next
+// review using them reads their Secret again
+func (r *CodeReviewReconciler) forgetTokenSource(scope string) {
+	r.tokenSourcesMu.Lock()
+	defer r.tokenSourcesMu.Unlock()
+
+	delete(r.tokenSources, scope)
+}
+
+// credentialScope identifies the provider credentials of a review, so that
+// what is read with one team's token is never served to another
+func credentialScope(review *reviewv1alpha1.CodeReview, ref reviewv1alpha1.TokenSecretReference) string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s", review.Spec.Provider, review.Namespace, ref.Name, ref.Key, ref.AuthType())
+}
+
+// orgConfigSource returns the org-wide configuration of a review's owner, as
+// read with the review's credentials
+func (r *CodeReviewReconciler) orgConfigSource(ctx context.Context, review *reviewv1alpha1.CodeReview) (repoconfig.OrgSource, error) {
+	ref, err := r.tokenSecretRef(ctx, review)
+	if err != nil {
+		return repoconfig.OrgSource{}, err
+	}
+
+	return repoconfig.OrgSource{
+		Provider: review.Spec.Provider,
+		Owner:    review.Spec.Owner,
+		Scope:    credentialScope(review, ref),
+	}, nil
+}
+
+// llmClient creates the LLM client selected in the review's spec, or else the
+// client of the model registered for the review's model tier, or returns the
+// operator's default client. Reviews labeled critical get a consensus client
+// combining the selected model with the consensus model.
+func (r *CodeReviewReconciler) llmClient(ctx context.Context, review *reviewv1alpha1.CodeReview, tierModel ModelSettings) (llm.Client, error) {
+	spec := review.Spec.LLM
+	if spec == nil {
+		tenantSpec, err := r.tenantLLM(ctx, review)
+		if err != nil {
+			return nil, err
+		}
+		spec = tenantSpec
+	}
+	operatorModel := spec == nil
+	if spec == nil {
+		if r.TenantIsolation {
+			return nil, fmt.Errorf("neither the review nor its reviewer config selects an LLM backend, " +
+				"and tenant isolation forbids the operator's backends")
+		}
+		if tierModel.Provider == "" {
+			return r.consensus(review, r.DefaultLLM), nil
+		}
+		spec = &reviewv1alpha1.LLMSpec{
+			Provider:      tierModel.Provider,
+			Endpoint:      tierModel.Endpoint,
+			Model:         tierModel.Model,
+			ContextWindow: tierModel.ContextWindow,
+			Region:        tierModel.Region,
+			Project:       tierModel.Project,
+		}
+	}
+
+	if r.Compliance != nil {
+		if err := r.Compliance.CheckLLM(spec.Provider, spec.Endpoint); err != nil {
+			return nil, err
+		}
+	}
+
+	// Endpoints of CodeReviews and ReviewerConfigs are not trusted with the
+	// operator's workload identity
+	config := llm.Config{
+		Endpoint:        spec.Endpoint,
+		Model:           spec.Model,
+		ContextWindow:   spec.ContextWindow,
+		Region:          spec.Region,
+		Project:         spec.Project,
+		TrustedEndpoint: operatorModel,
+	}
+	if operatorModel {
+		apiKeyEnv := tierModel.APIKeyEnv
+		if apiKeyEnv == "" {
+			apiKeyEnv = "LLM_API_KEY"
+		}
+		config.APIKey = os.Getenv(apiKeyEnv)
+	} else if ref := spec.APIKeySecretRef; ref != nil {
+		var secret corev1.Secret
+		if err := r.Get(ctx, types.NamespacedName{Namespace: review.Namespace, Name: ref.Name}, &secret); err != nil {
+			return nil, fmt.Errorf("error getting secret %s: %w", ref.Name, err)
+		}
+		apiKey, ok := secret.Data[ref.Key]
+		if !ok {
+			return nil, fmt.Errorf("secret %s is missing key %q", ref.Name, ref.Key)
+		}
+		config.APIKey = string(apiKey)
+	}
+	if r.TenantIsolation && llm.UsesWorkloadIdentity(spec.Provider, config) {
+		return nil, fmt.Errorf("LLM provider %s would authenticate with the operator's identity, "+
+			"which tenant isolation forbids; select a provider with an apiKeySecretRef", spec.Provider)
+	}
+
+	llmClient, err := r.LLMFactory.Create(spec.Provider, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.consensus(review, llmClient), nil
+}
+
+// attestCompliance returns the compliance settings a review ran under, or nil
+// if compliance mode is off
+func (r *CodeReviewReconciler) attestCompliance(ctx context.Context, review *reviewv1alpha1.CodeReview) *reviewv1alpha1.ComplianceAttestation {
+	if r.Compliance == nil {
+		return nil
+	}
+
+	attestation := r.Compliance.Attest()
+	return &reviewv1alpha1.ComplianceAttestation{
+		MinTLSVersion:       attestation.MinTLSVersion,
+		CipherSuites:        attestation.CipherSuites,
+		FIPSModule:          attestation.FIPSModule,
+		LLMProvider:         r.llmProvider(ctx, review),
+		AllowedLLMProviders: attestation.AllowedLLMProviders,
+	}
+}
+
+// llmProvider returns the LLM backend a review uses
+func (r *CodeReviewReconciler) llmProvider(ctx context.Context, review *reviewv1alpha1.CodeReview) string {
+	if review.Spec.LLM != nil {
+		return review.Spec.LLM.Provider
+	}
+	if spec, err := r.tenantLLM(ctx, review); err == nil && spec != nil {
+		return spec.Provider
+	}
+
+	return r.DefaultLLMProvider
+}
+
+// consensus wraps the review's LLM client in a consensus client if the review
+// is labeled critical and a consensus model is configured. The operator's
+// consensus model is not used with tenant isolation.
+func (r *CodeReviewReconciler) consensus(review *reviewv1alpha1.CodeReview, llmClient llm.Client) llm.Client {
+	if r.ConsensusLLM == nil || r.TenantIsolation || llmClient == nil || review.Labels[reviewv1alpha1.CriticalLabel] != "true" {
+		return llmClient
+	}
+
+	return llm.NewConsensusClient(llmClient, r.ConsensusLLM, r.ConsensusMode)
+}
+
+// resolveConfig computes the effective configuration for a review and records
+// it, along with any conflicts between the spec and the in-repo file, in the status
+func (r *CodeReviewReconciler) resolveConfig(ctx context.Context, gitClient git.Client, review *reviewv1alpha1.CodeReview, defaults *repoconfig.Config) (*repoconfig.Resolution, error) {
+	logger := log.FromContext(ctx)
+	spec := review.Spec
+
+	source, err := r.orgConfigSource(ctx, review)
+	if err != nil {
+		return nil, err
+	}
+	orgConfig, repoConfig, err := r.ConfigLoader.LoadLayers(ctx, gitClient, source, spec.Repository, spec.CommitSHA)
+	var verr *repoconfig.ValidationError
+	var oerr *repoconfig.OrgConfigError
+	if errors.As(err, &oerr) {
+		// Not a problem of the pull request, so no check fails on it
+		r.Recorder.Event(review, corev1.EventTypeWarning, "InvalidOrgConfig", oerr.Error())
+	}
+	if errors.As(err, &verr) {
+		r.Recorder.Event(review, corev1.EventTypeWarning, "InvalidConfig", verr.Error())
+		if spec.CommitSHA != "" {
+			if _, err := repoconfig.ReportValidationError(ctx, gitClient, spec.Owner, spec.Repository, spec.CommitSHA, verr); err != nil {
+				logger.Error(err, "unable to report config validation errors")
+			}
+		}
+	}
+	if err != nil && verr == nil && oerr == nil {
+		return nil, err
+	}
+
+	resolution := repoconfig.Resolve(defaults, orgConfig, repoConfig, specConfig(spec.Review))
+
+	review.Status.EffectiveConfig = effectiveConfig(resolution)
+	review.Status.ConfigConflicts = nil
+	fieldNames := make([]string, 0, len(resolution.Conflicts))
+	for _, conflict := range resolution.Conflicts {
+		review.Status.ConfigConflicts = append(review.Status.ConfigConflicts, reviewv1alpha1.ConfigConflict{
+			Field:     conflict.Field,
+			SpecValue: conflict.SpecValue,
+			RepoValue: conflict.RepoValue,
+		})
+		fieldNames = append(fieldNames, conflict.Field)
+	}
+
+	if len(fieldNames) > 0 {
+		message := fmt.Sprintf("spec overrides %s from %s", strings.Join(fieldNames, ", "), repoconfig.RepoConfigPath)
+		meta.SetStatusCondition(&review.Status.Conditions, metav1.Condition{
+			Type:    reviewv1alpha1.ConditionConfigDrift,
+			Status:  metav1.ConditionTrue,
+			Reason:  "SpecOverridesRepoConfig",
+			Message: message,
+		})
+		r.Recorder.Event(review, corev1.EventTypeWarning, "ConfigDrift", message)
+	} else {
+		meta.SetStatusCondition(&review.Status.Conditions, metav1.Condition{
+			Type:    reviewv1alpha1.ConditionConfigDrift,
+			Status:  metav1.ConditionFalse,
+			Reason:  "NoConflicts",
+			Message: "spec and in-repo configuration agree",
+		})
+	}
+
+	if err := r.Status().Update(ctx, review); err != nil {
+		return nil, err
+	}
+
+	return resolution, nil
+}
+
+// fail marks a review as failed
+func (r *CodeReviewReconciler) fail(ctx context.Context, review *reviewv1alpha1.CodeReview, reason string, err error) (ctrl.Result, error) {
+	r.Recorder.Event(review, corev1.EventTypeWarning, reason, err.Error())
+
+	now := metav1.Now()
+	review.Status.Phase = reviewv1alpha1.CodeReviewPhaseFailed
+	review.Status.CompletionTime = &now
+	if updateErr := r.Status().Update(ctx, review); updateErr != nil {
+		return ctrl.Result{}, updateErr
+	}
+	event := notificationEvent(review, notify.KindFailed, nil)
+	event.Reason, event.Message = reason, err.Error()
+	r.sendNotifications(ctx, review, event)
+	r.emitReviewEvent(ctx, review, cloudevents.TypeReviewFailed, event)
+	recordFinished(review)
+	r.Telemetry.RecordError(reason)
+	r.Telemetry.RecordReview(string(review.Status.Phase), r.llmProvider(ctx, review), 0)
+
+	return ctrl.Result{}, nil
+}
+
+// specConfig converts the spec's review settings to a configuration layer
+func specConfig(settings *reviewv1alpha1.ReviewSettings) *repoconfig.Config {
+	if settings == nil {
+		return nil
+	}
+
+	config := &repoconfig.Config{
+		Rules:           settings.Rules,
+		SeverityLevels:  settings.SeverityLevels,
+		Include:         settings.Include,
+		Exclude:         settings.Exclude,
+		DefaultExcludes: settings.DefaultExcludes,
+		Glossary:        settings.Glossary,
+		Tone:            settings.Tone,
+		ModelTier:       settings.ModelTier,
+		Language:        settings.Language,
+		MaxComments:     settings.MaxComments,
+		MinSeverity:     settings.MinSeverity,
+		DisabledRules:   settings.DisabledRules,
+	}
+	if settings.Gating != nil {
+		config.Gating = &repoconfig.GatingPolicy{
+			FailOn:      settings.Gating.FailOn,
+			MaxFindings: settings.Gating.MaxFindings,
+		}
+	}
+
+	return config
+}
+
+// effectiveConfig converts a resolution to its status representation
+func effectiveConfig(resolution *repoconfig.Resolution) *reviewv1alpha1.EffectiveConfig {
+	config := resolution.Config
+
+	effective := &reviewv1alpha1.EffectiveConfig{
+		ReviewSettings: reviewv1alpha1.ReviewSettings{
+			Rules:           config.Rules,
+			SeverityLevels:  config.SeverityLevels,
+			Include:         config.Include,
+			Exclude:         config.Exclude,
+			DefaultExcludes: config.DefaultExcludes,
+			Glossary:        config.Glossary,
+			Tone:            config.Tone,
+			ModelTier:       config.ModelTier,
+			Language:        config.Language,
+			MaxComments:     config.MaxComments,
+			MinSeverity:     config.MinSeverity,
+			DisabledRules:   config.DisabledRules,
+		},
+		Sources: make(map[string]string, len(resolution.Sources)),
+	}
+	if config.Gating != nil {
+		effective.Gating = &reviewv1alpha1.GatingPolicy{
+			FailOn:      config.Gating.FailOn,
+			MaxFindings: config.Gating.MaxFindings,
+		}
+	}
+	for field, source := range resolution.Sources {
+		effective.Sources[field] = string(source)
+	}
+
+	return effective
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CodeReviewReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&reviewv1alpha1.CodeReview{}).
+		WithOptions(crcontroller.Options{MaxConcurrentReconciles: r.MaxConcurrentReviews}).
+		Complete(r)
+}
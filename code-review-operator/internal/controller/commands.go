@@ -0,0 +1,360 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+)
+
+// errSkipped is the cancellation cause of reviews skipped by a slash command
+var errSkipped = errors.New("review skipped on request")
+
+// slashCommandUsage lists the slash commands, for answers to invalid ones
+const slashCommandUsage = "Supported commands: `/review` to review the latest changes again, " +
+	"`/review skip` to skip the pending reviews, `/review focus <area>` to review again focusing on an " +
+	"area such as security, and `/review explain <comment-id>` to explain a review comment in more depth."
+
+// Slash command actions
+const (
+	commandReview  = ""
+	commandSkip    = "skip"
+	commandFocus   = "focus"
+	commandExplain = "explain"
+)
+
+// slashCommand is a /review command from a pull request comment
+type slashCommand struct {
+	// Action is empty to review the pull request again, or skip, focus or explain
+	Action string
+
+	// Argument is the area to focus on or the ID of the review comment to explain
+	Argument string
+}
+
+// parseSlashCommand finds a /review command on a line of its own in a
+// comment. It returns false if the comment has no command, and an error if
+// the command is malformed.
+func parseSlashCommand(body string) (slashCommand, bool, error) {
+	for _, line := range strings.Split(body, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != "/review" {
+			continue
+		}
+
+		if len(fields) == 1 {
+			return slashCommand{Action: commandReview}, true, nil
+		}
+		command := slashCommand{Action: fields[1], Argument: strings.Join(fields[2:], " ")}
+		switch {
+		case command.Action == commandSkip && command.Argument == "":
+		case command.Action == commandFocus && command.Argument != "":
+		case command.Action == commandExplain && len(fields) == 3:
+		default:
+			return command, true, fmt.Errorf("invalid command `%s`", strings.Join(fields, " "))
+		}
+		return command, true, nil
+	}
+
+	return slashCommand{}, false, nil
+}
+
+// commentEvent is a comment posted on a pull request
+type commentEvent struct {
+	Provider    string
+	Owner       string
+	Repository  string
+	PullRequest int
+	CommentID   string
+	Author      string
+	Body        string
+
+	// Namespace is the namespace of the reviews the command applies to, or
+	// empty for every namespace
+	Namespace string
+}
+
+// runSlashCommand runs the /review command of a pull request comment. The
+// reviews it starts copy the spec of the pull request's latest CodeReview,
+// whose Git credentials are also used to answer; commands on pull requests
+// that were never reviewed are ignored. So are commands on pull requests
+// reviewed in several namespaces, as it is not known which team's
+// credentials and settings the command is meant to run with.
+func (r *CodeReviewReconciler) runSlashCommand(ctx context.Context, event commentEvent) error {
+	command, ok, parseErr := parseSlashCommand(event.Body)
+	if !ok {
+		return nil
+	}
+	logger := log.FromContext(ctx).WithValues(
+		"provider", event.Provider,
+		"repository", event.Owner+"/"+event.Repository,
+		"pullRequest", event.PullRequest,
+		"commentID", event.CommentID,
+	)
+	ctx = log.IntoContext(ctx, logger)
+
+	reviews, err := r.pullRequestReviews(ctx, event)
+	if err != nil {
+		return err
+	}
+	if len(reviews) == 0 {
+		logger.Info("ignoring slash command on a pull request without reviews")
+		return nil
+	}
+	if namespaces := reviewNamespaces(reviews); len(namespaces) > 1 {
+		logger.Info("ignoring slash command on a pull request reviewed in several namespaces", "namespaces", namespaces)
+		return nil
+	}
+	latest := reviews[len(reviews)-1]
+
+	gitClient, err := r.gitClient(ctx, latest)
+	if err != nil {
+		return err
+	}
+
+	// Webhooks may be delivered more than once; answered commands are not run again
+	comments, err := gitClient.GetComments(ctx, event.Owner, event.Repository, event.PullRequest)
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		if git.ParseCommandMarker(comment.Body) == event.CommentID {
+			logger.V(1).Info("slash command already answered")
+			return nil
+		}
+	}
+
+	var answer string
+	switch {
+	case parseErr != nil:
+		answer = fmt.Sprintf("%s. %s", parseErr, slashCommandUsage)
+	case command.Action == commandReview, command.Action == commandFocus:
+		review, err := r.requestReview(ctx, gitClient, latest, event, command.Argument)
+		if err != nil {
+			return err
+		}
+		answer = fmt.Sprintf("Started review `%s`.", review.Name)
+		if command.Argument != "" {
+			answer = fmt.Sprintf("Started review `%s`, focusing on %s.", review.Name, command.Argument)
+		}
+	case command.Action == commandSkip:
+		skipped, err := r.skipReviews(ctx, reviews, event.Author)
+		if err != nil {
+			return err
+		}
+		answer = fmt.Sprintf("Skipped %d pending review(s).", skipped)
+	case command.Action == commandExplain:
+		answer, err = r.explainComment(ctx, gitClient, latest, event, command.Argument)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Info("ran slash command", "action", command.Action, "author", event.Author)
+	_, err = gitClient.PostComment(ctx, event.Owner, event.Repository, event.PullRequest,
+		fmt.Sprintf("@%s %s\n\n%s", event.Author, answer, git.CommandMarker(event.CommentID)))
+	return err
+}
+
+// pullRequestReviews returns the CodeReviews of a pull request in the
+// event's namespace, oldest first
+func (r *CodeReviewReconciler) pullRequestReviews(ctx context.Context, event commentEvent) ([]*reviewv1alpha1.CodeReview, error) {
+	var list reviewv1alpha1.CodeReviewList
+	if err := r.List(ctx, &list, client.InNamespace(event.Namespace)); err != nil {
+		return nil, fmt.Errorf("error listing reviews: %w", err)
+	}
+
+	target := &reviewv1alpha1.CodeReview{Spec: reviewv1alpha1.CodeReviewSpec{
+		Provider:    event.Provider,
+		Owner:       event.Owner,
+		Repository:  event.Repository,
+		PullRequest: event.PullRequest,
+	}}
+	var reviews []*reviewv1alpha1.CodeReview
+	for i := range list.Items {
+		if samePullRequest(target, &list.Items[i]) {
+			reviews = append(reviews, &list.Items[i])
+		}
+	}
+	sort.Slice(reviews, func(i, j int) bool {
+		return newer(reviews[j], reviews[i])
+	})
+
+	return reviews, nil
+}
+
+// reviewNamespaces returns the sorted namespaces of reviews
+func reviewNamespaces(reviews []*reviewv1alpha1.CodeReview) []string {
+	var namespaces []string
+	for _, review := range reviews {
+		if !slices.Contains(namespaces, review.Namespace) {
+			namespaces = append(namespaces, review.Namespace)
+		}
+	}
+	sort.Strings(namespaces)
+
+	return namespaces
+}
+
+// requestReview creates a review of the pull request's head commit with the
+// spec of its latest review, bypassing the response cache. A focus adds a
+// rule asking the model to concentrate on an area.
+func (r *CodeReviewReconciler) requestReview(ctx context.Context, gitClient git.Client, latest *reviewv1alpha1.CodeReview,
+	event commentEvent, focus string) (*reviewv1alpha1.CodeReview, error) {
+	review := &reviewv1alpha1.CodeReview{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: latest.GenerateName,
+			Namespace:    latest.Namespace,
+			Labels:       latest.Labels,
+			Annotations:  map[string]string{reviewv1alpha1.RequestedByAnnotation: event.Author},
+		},
+		Spec: *latest.Spec.DeepCopy(),
+	}
+	if review.GenerateName == "" {
+		review.GenerateName = "codereview-"
+	}
+	review.Spec.BypassCache = true
+
+	pr, err := git.GetPullRequest(ctx, gitClient, event.Owner, event.Repository, event.PullRequest)
+	if err != nil && !errors.Is(err, git.ErrResourceNotFound) {
+		return nil, err
+	}
+	if pr != nil && pr.HeadSHA != "" && pr.HeadSHA != review.Spec.CommitSHA {
+		// A pre-computed diff is of the commit it was computed at
+		review.Spec.CommitSHA = pr.HeadSHA
+		review.Spec.Diff = nil
+	}
+
+	if focus != "" {
+		if review.Spec.Review == nil {
+			review.Spec.Review = &reviewv1alpha1.ReviewSettings{}
+		}
+		review.Spec.Review.Rules = append(review.Spec.Review.Rules,
+			fmt.Sprintf("Focus on %s; only report other findings if they are critical.", focus))
+	}
+
+	if err := r.Create(ctx, review); err != nil {
+		return nil, fmt.Errorf("error creating review: %w", err)
+	}
+	r.Recorder.Eventf(review, corev1.EventTypeNormal, "Requested", "requested by %s in comment %s", event.Author, event.CommentID)
+
+	return review, nil
+}
+
+// skipReviews cancels the unfinished reviews and marks them skipped,
+// returning how many were skipped
+func (r *CodeReviewReconciler) skipReviews(ctx context.Context, reviews []*reviewv1alpha1.CodeReview, by string) (int, error) {
+	skipped := 0
+	for _, review := range reviews {
+		if isFinished(review) {
+			continue
+		}
+
+		r.cancelInflight(client.ObjectKeyFromObject(review), errSkipped)
+		r.Recorder.Eventf(review, corev1.EventTypeNormal, "Skipped", "skipped on request of %s", by)
+
+		now := metav1.Now()
+		review.Status.Phase = reviewv1alpha1.CodeReviewPhaseSkipped
+		review.Status.CompletionTime = &now
+		if err := r.Status().Update(ctx, review); err != nil {
+			return skipped, err
+		}
+		recordFinished(review)
+		r.dequeue(client.ObjectKeyFromObject(review))
+		skipped++
+	}
+
+	return skipped, nil
+}
+
+// explainComment asks the LLM to explain a review comment in more depth and
+// replies in its thread. It returns the answer to the command.
+func (r *CodeReviewReconciler) explainComment(ctx context.Context, gitClient git.Client, latest *reviewv1alpha1.CodeReview,
+	event commentEvent, commentID string) (string, error) {
+	comments, err := gitClient.ListReviewComments(ctx, event.Owner, event.Repository, event.PullRequest)
+	if err != nil {
+		return "", err
+	}
+	var target *git.PostedComment
+	for i := range comments {
+		if comments[i].ID == commentID {
+			target = &comments[i]
+		}
+	}
+	if target == nil {
+		return fmt.Sprintf("There is no review comment %s on this pull request.", commentID), nil
+	}
+
+	// Show the model the hunk commented on, or the whole file if it is outdated
+	changes, err := gitClient.GetDiff(ctx, event.Owner, event.Repository, event.PullRequest, "")
+	if err != nil {
+		return "", err
+	}
+	files, err := diff.Parse(changes)
+	if err != nil {
+		return "", err
+	}
+	file := diff.Find(files, target.File)
+	if file == nil {
+		return fmt.Sprintf("%s is no longer changed in this pull request.", target.File), nil
+	}
+	side := diff.SideRight
+	if target.Side != "" {
+		side = diff.Side(target.Side)
+	}
+	excerpt := *file
+	if hunk := file.Hunk(side, target.Line); hunk != nil {
+		excerpt.Hunks = []*diff.Hunk{hunk}
+	}
+
+	llmClient, err := r.llmClient(ctx, latest, ModelSettings{})
+	if err != nil {
+		return "", err
+	}
+	result, err := llmClient.ReviewCode(ctx, excerpt.String(), llm.ReviewOptions{
+		Rules: []string{
+			"Do not report new findings. Explain the following earlier review comment on this change in " +
+				"more depth in the summary: why it matters and how to address it.\n\n" +
+				git.StripCommentMarker(target.Body),
+		},
+		Repository: event.Owner + "/" + event.Repository,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	reply := fmt.Sprintf("%s\n\n%s", strings.TrimSpace(result.Summary), git.CommandMarker(event.CommentID))
+	if err := gitClient.ReplyToReviewComment(ctx, event.Owner, event.Repository, event.PullRequest, commentID, reply); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Explained review comment %s in its thread.", commentID), nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+	"github.com/Shridhar2104/code-review-operator/pkg/memory"
+	"github.com/Shridhar2104/code-review-operator/pkg/notify"
+	"github.com/Shridhar2104/code-review-operator/pkg/repoconfig"
+)
+
+// defaultCostMinReviews is the number of reviews averaged under each
+// configuration before their costs are compared
+const defaultCostMinReviews = 3
+
+// CostGuard alerts when the cost of reviewing a repository per thousand
+// changed lines jumps after its configuration or model changes, catching
+// expensive prompt regressions
+type CostGuard struct {
+	// Store keeps the costs of each repository
+	Store memory.Store
+
+	// Threshold is the increase, in percent, over the cost under the previous
+	// configuration that is alerted on
+	Threshold float64
+
+	// MinReviews is the number of reviews averaged under each configuration
+	// before they are compared (defaults to 3)
+	MinReviews int
+
+	// Slack is notified of regressions when set
+	Slack *notify.SlackClient
+}
+
+// guardCost records the cost of a completed review and reports a regression
+// with a warning event and a notification. Errors are logged; they do not
+// fail the review.
+func (r *CodeReviewReconciler) guardCost(ctx context.Context, review *reviewv1alpha1.CodeReview, config *repoconfig.Config,
+	result *llm.ReviewResult, reviewed string, cost float64) {
+	guard := r.CostGuard
+	if guard == nil || cost <= 0 {
+		return
+	}
+	logger := log.FromContext(ctx)
+
+	files, err := diff.Parse(reviewed)
+	if err != nil {
+		logger.Error(err, "unable to parse the reviewed diff to track its cost")
+		return
+	}
+	lines := countChangedLines(files)
+	if lines == 0 {
+		return
+	}
+
+	minReviews := guard.MinReviews
+	if minReviews <= 0 {
+		minReviews = defaultCostMinReviews
+	}
+	configuration := costConfiguration(review, config, result)
+	var regression string
+	err = guard.Store.Update(ctx, review.Spec.Owner, review.Spec.Repository, func(mem *memory.Memory) {
+		costs := mem.RecordCost(configuration, cost, lines, minReviews)
+		if costs.Alerted || costs.Baseline == nil || costs.Current.Reviews < minReviews {
+			return
+		}
+		before, after := costs.Baseline.PerKLoC(), costs.Current.PerKLoC()
+		if before <= 0 || (after-before)/before*100 <= guard.Threshold {
+			return
+		}
+		costs.Alerted = true
+		regression = fmt.Sprintf("review cost of %s/%s rose %.0f%% to $%s per 1000 changed lines (from $%s) "+
+			"over %d reviews since its configuration or model changed", review.Spec.Owner, review.Spec.Repository,
+			(after-before)/before*100, formatCost(after), formatCost(before), costs.Current.Reviews)
+	})
+	if err != nil {
+		logger.Error(err, "unable to record review cost")
+		return
+	}
+	if regression == "" {
+		return
+	}
+
+	logger.Info("review cost regression", "configuration", configuration, "message", regression)
+	r.Recorder.Event(review, corev1.EventTypeWarning, "CostRegression", regression)
+	if guard.Slack != nil {
+		if err := guard.Slack.Post(ctx, fmt.Sprintf(":chart_with_upwards_trend: %s (CodeReview %s/%s)",
+			regression, review.Namespace, review.Name)); err != nil {
+			logger.Error(err, "unable to notify of review cost regression")
+		}
+	}
+}
+
+// costConfiguration identifies what review costs depend on: the operator
+// configuration, the resolved review settings, the prompt and the models used
+func costConfiguration(review *reviewv1alpha1.CodeReview, config *repoconfig.Config, result *llm.ReviewResult) string {
+	models := make([]string, 0, len(result.Usage))
+	for _, usage := range result.Usage {
+		models = append(models, usage.Model)
+	}
+	sort.Strings(models)
+	models = slices.Compact(models)
+
+	settings, _ := json.Marshal(config)
+	hash := sha256.New()
+	for _, part := range append([]string{review.Status.ConfigGeneration, string(settings), review.Spec.Prompt}, models...) {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:12]
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get
+
+// CRD skew policies, deciding what the operator does when the installed CRDs
+// do not match the ones it was built with
+const (
+	// CRDSkewRefuse stops the operator at startup
+	CRDSkewRefuse = "refuse"
+
+	// CRDSkewReadOnly holds every review with the CRDVersionSkew condition
+	CRDSkewReadOnly = "read-only"
+
+	// CRDSkewIgnore only logs the differences
+	CRDSkewIgnore = "ignore"
+)
+
+// expectedCRDs are the resources the operator reconciles, keyed by the name
+// of their CustomResourceDefinition
+var expectedCRDs = map[string]any{
+	"codereviews." + reviewv1alpha1.GroupVersion.Group:     reviewv1alpha1.CodeReview{},
+	"reviewbudgets." + reviewv1alpha1.GroupVersion.Group:   reviewv1alpha1.ReviewBudget{},
+	"reviewerconfigs." + reviewv1alpha1.GroupVersion.Group: reviewv1alpha1.ReviewerConfig{},
+	"reviewprompts." + reviewv1alpha1.GroupVersion.Group:   reviewv1alpha1.ReviewPrompt{},
+}
+
+// CheckCRDs compares the installed CustomResourceDefinitions with the types
+// the operator was built with. It returns one problem per missing CRD, API
+// version that is not served or not the storage version, and field missing
+// from the installed schema; the API server would silently drop such fields
+// from the objects the operator writes.
+func CheckCRDs(ctx context.Context, reader client.Reader) ([]string, error) {
+	names := make([]string, 0, len(expectedCRDs))
+	for name := range expectedCRDs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		crd := &unstructured.Unstructured{}
+		crd.SetGroupVersionKind(schema.GroupVersionKind{
+			Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition",
+		})
+		if err := reader.Get(ctx, types.NamespacedName{Name: name}, crd); err != nil {
+			if apierrors.IsNotFound(err) {
+				problems = append(problems, fmt.Sprintf("CRD %s is not installed", name))
+				continue
+			}
+			return nil, fmt.Errorf("failed to get CRD %s: %w", name, err)
+		}
+		problems = append(problems, checkCRD(name, crd.Object, reflect.TypeOf(expectedCRDs[name]))...)
+	}
+
+	return problems, nil
+}
+
+// checkCRD checks one installed CRD against the type of its resource
+func checkCRD(name string, crd map[string]any, typ reflect.Type) []string {
+	want := reviewv1alpha1.GroupVersion.Version
+	versions, _, _ := unstructured.NestedSlice(crd, "spec", "versions")
+
+	var problems []string
+	var found map[string]any
+	for _, v := range versions {
+		version, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		versionName, _, _ := unstructured.NestedString(version, "name")
+		storage, _, _ := unstructured.NestedBool(version, "storage")
+		if versionName == want {
+			found = version
+		} else if storage {
+			problems = append(problems, fmt.Sprintf("CRD %s stores version %s, the operator uses %s", name, versionName, want))
+		}
+	}
+	if found == nil {
+		return append(problems, fmt.Sprintf("CRD %s has no version %s", name, want))
+	}
+	if served, _, _ := unstructured.NestedBool(found, "served"); !served {
+		problems = append(problems, fmt.Sprintf("CRD %s does not serve version %s", name, want))
+	}
+
+	root, ok, _ := unstructured.NestedMap(found, "schema", "openAPIV3Schema")
+	if !ok {
+		return append(problems, fmt.Sprintf("CRD %s has no schema for version %s", name, want))
+	}
+	for _, missing := range missingFields(root, "", typ) {
+		problems = append(problems, fmt.Sprintf("CRD %s %s has no field %s", name, want, strings.TrimPrefix(missing, ".")))
+	}
+
+	return problems
+}
+
+// missingFields returns the JSON paths below path, whose value has type typ,
+// that its schema node does not describe. Types from other packages, such as
+// timestamps and conditions, are not descended into.
+func missingFields(node map[string]any, path string, typ reflect.Type) []string {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.PkgPath() != "" && typ.PkgPath() != reflect.TypeOf(reviewv1alpha1.CodeReview{}).PkgPath() {
+		return nil
+	}
+	if preserve, _, _ := unstructured.NestedBool(node, "x-kubernetes-preserve-unknown-fields"); preserve {
+		return nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Slice:
+		if items, ok, _ := unstructured.NestedMap(node, "items"); ok {
+			return missingFields(items, path+"[]", typ.Elem())
+		}
+		return nil
+	case reflect.Map:
+		if values, ok, _ := unstructured.NestedMap(node, "additionalProperties"); ok {
+			return missingFields(values, path+"{}", typ.Elem())
+		}
+		return nil
+	case reflect.Struct:
+	default:
+		return nil
+	}
+
+	properties, _, _ := unstructured.NestedMap(node, "properties")
+	var missing []string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch {
+		case name == "-" || !field.IsExported():
+		case name == "" && field.Anonymous:
+			missing = append(missing, missingFields(node, path, field.Type)...)
+		case name != "":
+			child, ok := properties[name].(map[string]any)
+			if !ok {
+				missing = append(missing, path+"."+name)
+				continue
+			}
+			missing = append(missing, missingFields(child, path+"."+name, field.Type)...)
+		}
+	}
+	return missing
+}
+
+// holdForCRDSkew marks a review as held because the installed CRDs do not
+// match the operator
+func (r *CodeReviewReconciler) holdForCRDSkew(ctx context.Context, review *reviewv1alpha1.CodeReview) error {
+	condition := metav1.Condition{
+		Type:   reviewv1alpha1.ConditionCRDVersionSkew,
+		Status: metav1.ConditionTrue,
+		Reason: "IncompatibleCRDs",
+		Message: fmt.Sprintf("the installed CRDs do not match this operator version (%s); "+
+			"install the operator's CRDs and restart it", strings.Join(r.CRDSkew, "; ")),
+	}
+	if !meta.SetStatusCondition(&review.Status.Conditions, condition) {
+		return nil
+	}
+	r.Recorder.Event(review, corev1.EventTypeWarning, "CRDVersionSkew", condition.Message)
+	return r.Status().Update(ctx, review)
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/cloudevents"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/notify"
+	reviewpkg "github.com/Shridhar2104/code-review-operator/pkg/review"
+)
+
+// errDeadlineExceeded is the cancellation cause of reviews that ran past their deadline
+var errDeadlineExceeded = errors.New("review deadline exceeded")
+
+// partialPublishTimeout bounds publishing the partial results of a timed out review
+const partialPublishTimeout = 2 * time.Minute
+
+// deadline returns when the review must finish, or false if it has no deadline
+func deadline(review *reviewv1alpha1.CodeReview) (time.Time, bool) {
+	if review.Spec.ActiveDeadlineSeconds == nil || review.Status.StartTime == nil {
+		return time.Time{}, false
+	}
+
+	return review.Status.StartTime.Add(time.Duration(*review.Spec.ActiveDeadlineSeconds) * time.Second), true
+}
+
+// timeOut publishes whatever the review gathered before its deadline, labeled
+// as partial, and marks the review TimedOut. job is nil if the deadline passed
+// before the review started.
+func (r *CodeReviewReconciler) timeOut(ctx context.Context, review *reviewv1alpha1.CodeReview, job *reviewpkg.Job) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// The review's own context has expired
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), partialPublishTimeout)
+	defer cancel()
+
+	message := "review exceeded its deadline before any findings were gathered"
+	if job != nil {
+		if job.Partial {
+			if err := r.Pipeline.RunFrom(ctx, job, reviewpkg.StagePostprocess); err != nil {
+				logger.Error(err, "unable to publish partial review")
+				reviewpkg.AbortProgress(ctx, job, errDeadlineExceeded)
+			} else if job.DryRun {
+				message = "review exceeded its deadline; rendered a partial review"
+				r.renderDryRun(ctx, review, job)
+			} else {
+				message = "review exceeded its deadline; posted a partial review"
+			}
+		} else {
+			reviewpkg.AbortProgress(ctx, job, errDeadlineExceeded)
+		}
+	}
+	r.Recorder.Event(review, corev1.EventTypeWarning, "TimedOut", message)
+
+	var usage *reviewv1alpha1.TokenUsage
+	var cost float64
+	if job != nil {
+		usage, cost = r.tokenUsage(job.Result)
+	}
+	now := metav1.Now()
+	review.Status.Phase = reviewv1alpha1.CodeReviewPhaseTimedOut
+	review.Status.Usage = usage
+	if job != nil {
+		review.Status.ReviewURL = job.ReviewURL
+		review.Status.CommentCount = len(job.Comments)
+		review.Status.Decision = string(job.Decision)
+		review.Status.Compliance = r.attestCompliance(ctx, review)
+	}
+	review.Status.CompletionTime = &now
+	if err := r.Status().Update(ctx, review); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.recordUsage(ctx, review, usage, cost)
+	var comments []git.ReviewComment
+	if job != nil {
+		comments = job.Comments
+	}
+	event := notificationEvent(review, notify.KindFailed, comments)
+	event.Reason, event.Message = "TimedOut", message
+	r.sendNotifications(ctx, review, event)
+	r.emitReviewEvent(ctx, review, cloudevents.TypeReviewFailed, event)
+	recordFinished(review)
+
+	r.Telemetry.RecordReview(string(review.Status.Phase), r.llmProvider(ctx, review), review.Status.CommentCount)
+
+	logger.Info(message, "url", review.Status.ReviewURL)
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/httpx"
+)
+
+const (
+	// maxDiffArtifact is the largest pre-computed diff read, compressed or not
+	maxDiffArtifact = 64 << 20
+
+	// diffDownloadTimeout bounds downloading a pre-computed diff
+	diffDownloadTimeout = time.Minute
+)
+
+// diffHTTPClient downloads pre-computed diffs
+var diffHTTPClient = httpx.NewClient(diffDownloadTimeout)
+
+// reviewClient returns the Git client a review reads its diff and posts its
+// findings with. The diff of reviews with a pre-computed diff is read from
+// its source rather than from the provider.
+func (r *CodeReviewReconciler) reviewClient(ctx context.Context, review *reviewv1alpha1.CodeReview) (git.Client, error) {
+	gitClient, err := r.gitClient(ctx, review)
+	if err != nil || review.Spec.Diff == nil {
+		return gitClient, err
+	}
+
+	source := review.Spec.Diff.DeepCopy()
+	namespace := review.Namespace
+	return git.WithDiff(gitClient, func(ctx context.Context) (string, error) {
+		return r.loadDiff(ctx, namespace, source)
+	}), nil
+}
+
+// loadDiff reads a pre-computed diff from its source, checks its digest and
+// decompresses it
+func (r *CodeReviewReconciler) loadDiff(ctx context.Context, namespace string, source *reviewv1alpha1.DiffSource) (string, error) {
+	var data []byte
+	var err error
+	switch {
+	case source.URL != "":
+		data, err = downloadDiff(ctx, source.URL)
+	case source.URLSecretRef != nil:
+		var location []byte
+		location, err = r.secretKey(ctx, namespace, *source.URLSecretRef)
+		if err == nil {
+			data, err = downloadDiff(ctx, string(location))
+		}
+	case source.ConfigMapKeyRef != nil:
+		data, err = r.configMapKey(ctx, namespace, *source.ConfigMapKeyRef)
+	case source.SecretKeyRef != nil:
+		data, err = r.secretKey(ctx, namespace, *source.SecretKeyRef)
+	default:
+		err = fmt.Errorf("diff source sets no location")
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading diff: %w", err)
+	}
+
+	if source.SHA256 != "" {
+		digest := sha256.Sum256(data)
+		if hex.EncodeToString(digest[:]) != source.SHA256 {
+			return "", fmt.Errorf("diff does not match its sha256 digest %s", source.SHA256)
+		}
+	}
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		data, err = gunzip(data)
+		if err != nil {
+			return "", fmt.Errorf("error decompressing diff: %w", err)
+		}
+	}
+	log.FromContext(ctx).Info("read pre-computed diff", "bytes", len(data))
+
+	return string(data), nil
+}
+
+// secretKey reads a key of a Secret
+func (r *CodeReviewReconciler) secretKey(ctx context.Context, namespace string, ref reviewv1alpha1.SecretKeyReference) ([]byte, error) {
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, &secret); err != nil {
+		return nil, fmt.Errorf("error getting secret %s: %w", ref.Name, err)
+	}
+	data, ok := secret.Data[ref.Key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s is missing key %q", ref.Name, ref.Key)
+	}
+
+	return data, nil
+}
+
+// configMapKey reads a key of a ConfigMap, from its data or binaryData
+func (r *CodeReviewReconciler) configMapKey(ctx context.Context, namespace string, ref reviewv1alpha1.ConfigMapKeyReference) ([]byte, error) {
+	var configMap corev1.ConfigMap
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, &configMap); err != nil {
+		return nil, fmt.Errorf("error getting config map %s: %w", ref.Name, err)
+	}
+	if data, ok := configMap.Data[ref.Key]; ok {
+		return []byte(data), nil
+	}
+	if data, ok := configMap.BinaryData[ref.Key]; ok {
+		return data, nil
+	}
+
+	return nil, fmt.Errorf("config map %s is missing key %q", ref.Name, ref.Key)
+}
+
+// downloadDiff downloads a diff, refusing diffs larger than maxDiffArtifact
+func downloadDiff(ctx context.Context, location string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, diffDownloadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid diff URL: %w", err)
+	}
+	req.Header.Set("Accept", "text/x-diff, text/plain, application/gzip, */*")
+	resp, err := diffHTTPClient.Do(req)
+	if err != nil {
+		// Only the host is reported, as URLs may carry credentials
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("error downloading diff from %s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading diff from %s returned %s", req.URL.Host, resp.Status)
+	}
+
+	return readLimited(resp.Body)
+}
+
+// gunzip decompresses a gzip-compressed diff
+func gunzip(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return readLimited(reader)
+}
+
+// readLimited reads at most maxDiffArtifact bytes, failing on more
+func readLimited(reader io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, maxDiffArtifact+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDiffArtifact {
+		return nil, fmt.Errorf("diff is larger than %d MiB", maxDiffArtifact>>20)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	reviewpkg "github.com/Shridhar2104/code-review-operator/pkg/review"
+)
+
+// maxRenderedComments is the number of comments a dry run records in the status
+const maxRenderedComments = 50
+
+// Keys of the ConfigMap a dry run writes the rendered review to
+const (
+	dryRunSummaryKey  = "summary.md"
+	dryRunCommentsKey = "comments.json"
+	dryRunDecisionKey = "decision"
+)
+
+// dryRun reports whether a review is rendered instead of posted
+func (r *CodeReviewReconciler) dryRun(review *reviewv1alpha1.CodeReview) bool {
+	return r.DryRun || review.Spec.DryRun != nil
+}
+
+// renderDryRun records the review a dry run would have posted in the status
+// and, if the spec names one, in a ConfigMap owned by the review. Failing to
+// write the ConfigMap is reported in an event; the status still holds the
+// first comments.
+func (r *CodeReviewReconciler) renderDryRun(ctx context.Context, review *reviewv1alpha1.CodeReview, job *reviewpkg.Job) {
+	posted := reviewpkg.CommentsToPost(job)
+	comments := make([]reviewv1alpha1.RenderedComment, 0, len(posted))
+	for _, comment := range posted {
+		comments = append(comments, renderedComment(comment))
+	}
+
+	result := &reviewv1alpha1.DryRunResult{Summary: job.Summary, Comments: comments}
+	if len(comments) > maxRenderedComments {
+		result.Comments = comments[:maxRenderedComments]
+		result.OmittedComments = len(comments) - maxRenderedComments
+	}
+	review.Status.DryRun = result
+
+	if review.Spec.DryRun == nil || review.Spec.DryRun.ConfigMap == "" {
+		return
+	}
+	if err := r.writeDryRunConfigMap(ctx, review, job, comments); err != nil {
+		log.FromContext(ctx).Error(err, "unable to write dry run configmap", "configMap", review.Spec.DryRun.ConfigMap)
+		r.Recorder.Eventf(review, corev1.EventTypeWarning, "DryRunConfigMapFailed",
+			"unable to write the rendered review to configmap %s: %v", review.Spec.DryRun.ConfigMap, err)
+		return
+	}
+	result.ConfigMap = review.Spec.DryRun.ConfigMap
+}
+
+// writeDryRunConfigMap writes the full rendered review to the ConfigMap named in the spec
+func (r *CodeReviewReconciler) writeDryRunConfigMap(ctx context.Context, review *reviewv1alpha1.CodeReview, job *reviewpkg.Job, comments []reviewv1alpha1.RenderedComment) error {
+	data, err := json.MarshalIndent(comments, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding comments: %w", err)
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: review.Spec.DryRun.ConfigMap, Namespace: review.Namespace},
+	}
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		configMap.Data = map[string]string{
+			dryRunSummaryKey:  job.Summary,
+			dryRunCommentsKey: string(data),
+			dryRunDecisionKey: string(job.Decision),
+		}
+		return controllerutil.SetControllerReference(review, configMap, r.Scheme)
+	})
+
+	return err
+}
+
+// renderedComment converts a comment to its status representation
+func renderedComment(comment git.ReviewComment) reviewv1alpha1.RenderedComment {
+	return reviewv1alpha1.RenderedComment{
+		File:          comment.File,
+		Line:          comment.Line,
+		StartLine:     comment.StartLine,
+		Side:          comment.Side,
+		Severity:      comment.Severity,
+		Rule:          comment.Rule,
+		Language:      comment.Language,
+		Content:       comment.Content,
+		SuggestedCode: comment.SuggestedCode,
+	}
+}
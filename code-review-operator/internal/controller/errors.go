@@ -0,0 +1,53 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+)
+
+// retryDecision is how a failed review is handled
+type retryDecision struct {
+	// retry is false for failures retrying cannot fix, such as rejected
+	// credentials or a deleted pull request
+	retry bool
+
+	// after is how long the Git provider or LLM backend asked to wait before
+	// retrying; 0 leaves the delay to the controller's backoff
+	after time.Duration
+}
+
+// classifyError decides whether a failed review is retried. Failed requests
+// to a Git provider or an LLM backend are retried if they may succeed later,
+// such as rate limits, server errors and timeouts; other error responses
+// fail the review. Errors of unknown origin are retried.
+func classifyError(err error) retryDecision {
+	var gitErr *git.Error
+	if errors.As(err, &gitErr) {
+		return retryDecision{retry: gitErr.Transient, after: gitErr.RetryAfter}
+	}
+	var apiErr *llm.APIError
+	if errors.As(err, &apiErr) {
+		return retryDecision{retry: apiErr.Transient(), after: apiErr.RetryAfter}
+	}
+
+	return retryDecision{retry: true}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/repoconfig"
+	reviewpkg "github.com/Shridhar2104/code-review-operator/pkg/review"
+)
+
+const (
+	// syncHoldsAnnotation records on an Argo CD AppProject the pull requests
+	// holding the sync of each of its Applications
+	syncHoldsAnnotation = "review.code-review.io/sync-holds"
+
+	// defaultArgoNamespace is where Argo CD AppProjects live unless a target
+	// names another namespace
+	defaultArgoNamespace = "argocd"
+)
+
+// appProjectGVK is the kind of Argo CD projects, whose sync windows hold
+// the sync of their Applications
+var appProjectGVK = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "AppProject"}
+
+// holdSyncs holds the Argo CD sync of the Applications a review found
+// critical issues in, if the repository asks for it, with a deny sync window
+// in their project. Holds are tracked per pull request: the window of an
+// Application is removed once no pull request holds it, when a later review
+// has no critical findings in it. Dry runs hold nothing. Errors are logged and
+// recorded as events; they do not fail the review.
+func (r *CodeReviewReconciler) holdSyncs(ctx context.Context, cr *reviewv1alpha1.CodeReview, config *repoconfig.Config, targets []reviewpkg.DeploymentTarget) {
+	if r.dryRun(cr) || config.GitOps == nil || config.GitOps.HoldSyncOnCritical == nil || !*config.GitOps.HoldSyncOnCritical {
+		return
+	}
+	holder := fmt.Sprintf("%s/%s#%d", cr.Spec.Owner, cr.Spec.Repository, cr.Spec.PullRequest)
+
+	projects := map[types.NamespacedName][]reviewpkg.DeploymentTarget{}
+	for _, target := range targets {
+		if target.Kind != repoconfig.GitOpsApplication {
+			continue
+		}
+		namespace := target.Namespace
+		if namespace == "" {
+			namespace = defaultArgoNamespace
+		}
+		key := types.NamespacedName{Namespace: namespace, Name: target.Project}
+		projects[key] = append(projects[key], target)
+	}
+
+	for key, targets := range projects {
+		held, released, err := r.holdProjectSyncs(ctx, key, holder, targets)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "unable to hold Argo CD syncs", "project", key.String())
+			r.Recorder.Eventf(cr, corev1.EventTypeWarning, "SyncHoldFailed", "unable to update Argo CD project %s: %v", key, err)
+			continue
+		}
+		if len(held) > 0 {
+			r.Recorder.Eventf(cr, corev1.EventTypeWarning, "SyncHeld",
+				"held the Argo CD sync of %s on critical findings", strings.Join(held, ", "))
+		}
+		if len(released) > 0 {
+			r.Recorder.Eventf(cr, corev1.EventTypeNormal, "SyncReleased",
+				"released the Argo CD sync of %s", strings.Join(released, ", "))
+		}
+	}
+}
+
+// holdProjectSyncs updates the holds of a pull request on the Applications of
+// one project and the project's deny sync windows to match, returning the
+// Applications whose sync was newly held or released
+func (r *CodeReviewReconciler) holdProjectSyncs(ctx context.Context, key types.NamespacedName, holder string,
+	targets []reviewpkg.DeploymentTarget) ([]string, []string, error) {
+	project := &unstructured.Unstructured{}
+	project.SetGroupVersionKind(appProjectGVK)
+	if err := r.Get(ctx, key, project); err != nil {
+		return nil, nil, err
+	}
+
+	holds := map[string][]string{}
+	if value := project.GetAnnotations()[syncHoldsAnnotation]; value != "" {
+		if err := json.Unmarshal([]byte(value), &holds); err != nil {
+			return nil, nil, fmt.Errorf("invalid %s annotation: %w", syncHoldsAnnotation, err)
+		}
+	}
+	windows, _, err := unstructured.NestedSlice(project.Object, "spec", "syncWindows")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var held, released []string
+	for _, target := range targets {
+		holders := slices.DeleteFunc(holds[target.Name], func(h string) bool { return h == holder })
+		if target.Critical > 0 {
+			holders = append(holders, holder)
+		}
+		sort.Strings(holders)
+		windowAt := slices.IndexFunc(windows, func(window interface{}) bool { return isHoldWindow(window, target.Name) })
+		switch {
+		case len(holders) > 0 && windowAt < 0:
+			windows = append(windows, holdWindow(target.Name))
+			held = append(held, target.Name)
+		case len(holders) == 0 && windowAt >= 0:
+			windows = slices.Delete(windows, windowAt, windowAt+1)
+			released = append(released, target.Name)
+		}
+		if len(holders) > 0 {
+			holds[target.Name] = holders
+		} else {
+			delete(holds, target.Name)
+		}
+	}
+
+	var value string
+	if len(holds) > 0 {
+		data, err := json.Marshal(holds)
+		if err != nil {
+			return nil, nil, err
+		}
+		value = string(data)
+	}
+	annotations := project.GetAnnotations()
+	if value == annotations[syncHoldsAnnotation] && len(held) == 0 && len(released) == 0 {
+		return nil, nil, nil
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if value == "" {
+		delete(annotations, syncHoldsAnnotation)
+	} else {
+		annotations[syncHoldsAnnotation] = value
+	}
+	project.SetAnnotations(annotations)
+	if err := unstructured.SetNestedSlice(project.Object, windows, "spec", "syncWindows"); err != nil {
+		return nil, nil, err
+	}
+	if err := r.Update(ctx, project); err != nil {
+		return nil, nil, err
+	}
+
+	return held, released, nil
+}
+
+// holdWindow returns the deny sync window holding an Application: always
+// active, while still allowing manual syncs for emergencies
+func holdWindow(application string) map[string]interface{} {
+	return map[string]interface{}{
+		"kind":         "deny",
+		"schedule":     "* * * * *",
+		"duration":     "1h",
+		"applications": []interface{}{application},
+		"manualSync":   true,
+		"description":  "Held by code-review-operator on critical review findings",
+	}
+}
+
+// isHoldWindow reports whether a sync window is the hold window of an Application
+func isHoldWindow(window interface{}, application string) bool {
+	fields, ok := window.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	applications, _, _ := unstructured.NestedStringSlice(fields, "applications")
+	kind, _, _ := unstructured.NestedString(fields, "kind")
+	schedule, _, _ := unstructured.NestedString(fields, "schedule")
+	duration, _, _ := unstructured.NestedString(fields, "duration")
+
+	return kind == "deny" && schedule == "* * * * *" && duration == "1h" &&
+		len(applications) == 1 && applications[0] == application
+}
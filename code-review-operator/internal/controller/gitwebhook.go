@@ -0,0 +1,274 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultCommandAssociations are the relationships to a repository, as
+// reported by GitHub, whose members may run slash commands
+var DefaultCommandAssociations = []string{"OWNER", "MEMBER", "COLLABORATOR"}
+
+const (
+	// maxWebhookPayload is the largest webhook payload GitHub sends
+	maxWebhookPayload = 25 << 20
+
+	// slashCommandTimeout bounds running one slash command
+	slashCommandTimeout = 5 * time.Minute
+)
+
+// prefetchActions are the pull_request webhook actions that pre-fetch the
+// pull request's content, as a review of its head commit is coming
+var prefetchActions = []string{"opened", "reopened", "synchronize", "ready_for_review"}
+
+// GitWebhookServer receives GitHub webhooks and runs the slash commands of
+// pull request comments. Commands are answered after the webhook is
+// acknowledged. Opened and updated pull requests are pre-fetched into the
+// reviews' content cache, if there is one. It runs on the leader, which runs
+// the reviews the commands adjust.
+type GitWebhookServer struct {
+	// Addr is the address the server listens on
+	Addr string
+
+	// Secret is the secret GitHub signs webhook payloads with
+	Secret []byte
+
+	// Associations are the relationships to the repository whose members
+	// may run slash commands (defaults to DefaultCommandAssociations)
+	Associations []string
+
+	// Namespace limits slash commands to the reviews of one namespace. They
+	// apply to reviews in every namespace when empty.
+	Namespace string
+
+	// Reviews runs the commands
+	Reviews *CodeReviewReconciler
+}
+
+// githubCommentPayload is the part of an issue_comment webhook payload the
+// server reads
+type githubCommentPayload struct {
+	Action string `json:"action"`
+	Issue  struct {
+		Number      int              `json:"number"`
+		PullRequest *json.RawMessage `json:"pull_request"`
+	} `json:"issue"`
+	Comment struct {
+		ID                int64  `json:"id"`
+		Body              string `json:"body"`
+		AuthorAssociation string `json:"author_association"`
+		User              struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"comment"`
+	Repository struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+}
+
+// githubPullRequestPayload is the part of a pull_request webhook payload the
+// server reads
+type githubPullRequestPayload struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Draft bool `json:"draft"`
+		Head  struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	} `json:"pull_request"`
+	Repository struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+}
+
+// Start implements manager.Runnable
+func (s *GitWebhookServer) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle("/github", s.handler(ctx))
+	server := &http.Server{
+		Addr:              s.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+	log.FromContext(ctx).Info("serving Git webhooks", "address", s.Addr)
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	case err := <-errs:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+func (s *GitWebhookServer) NeedLeaderElection() bool {
+	return true
+}
+
+// handler verifies GitHub webhooks and runs the slash commands of new pull
+// request comments, and pre-fetches opened pull requests, in the background,
+// under ctx
+func (s *GitWebhookServer) handler(ctx context.Context) http.HandlerFunc {
+	logger := log.FromContext(ctx).WithName("git-webhook")
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(req.Body, maxWebhookPayload))
+		if err != nil {
+			http.Error(w, "error reading payload", http.StatusBadRequest)
+			return
+		}
+		if !validSignature(s.Secret, body, req.Header.Get("X-Hub-Signature-256")) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		switch req.Header.Get("X-GitHub-Event") {
+		case "issue_comment":
+		case "pull_request":
+			s.pullRequest(log.IntoContext(ctx, logger), w, body)
+			return
+		default:
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		var payload githubCommentPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		if payload.Action != "created" || payload.Issue.PullRequest == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		associations := s.Associations
+		if associations == nil {
+			associations = DefaultCommandAssociations
+		}
+		if !slices.Contains(associations, payload.Comment.AuthorAssociation) {
+			if _, ok, _ := parseSlashCommand(payload.Comment.Body); ok {
+				logger.Info("ignoring slash command of an author without access", "author", payload.Comment.User.Login,
+					"association", payload.Comment.AuthorAssociation)
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		event := commentEvent{
+			Provider:    "github",
+			Owner:       payload.Repository.Owner.Login,
+			Repository:  payload.Repository.Name,
+			PullRequest: payload.Issue.Number,
+			CommentID:   strconv.FormatInt(payload.Comment.ID, 10),
+			Author:      payload.Comment.User.Login,
+			Body:        payload.Comment.Body,
+			Namespace:   s.Namespace,
+		}
+		go func() {
+			ctx, cancel := context.WithTimeout(log.IntoContext(ctx, logger), slashCommandTimeout)
+			defer cancel()
+			if err := s.Reviews.runSlashCommand(ctx, event); err != nil {
+				logger.Error(err, "slash command failed", "repository", event.Owner+"/"+event.Repository,
+					"pullRequest", event.PullRequest, "commentID", event.CommentID)
+			}
+		}()
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// pullRequest pre-fetches the content of an opened or updated pull request in
+// the background
+func (s *GitWebhookServer) pullRequest(ctx context.Context, w http.ResponseWriter, body []byte) {
+	var payload githubPullRequestPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if s.Reviews.ContentCache == nil || !slices.Contains(prefetchActions, payload.Action) ||
+		payload.PullRequest.Draft || payload.PullRequest.Head.SHA == "" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	event := pullRequestEvent{
+		Provider:    "github",
+		Owner:       payload.Repository.Owner.Login,
+		Repository:  payload.Repository.Name,
+		PullRequest: payload.Number,
+		HeadSHA:     payload.PullRequest.Head.SHA,
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, prefetchTimeout)
+		defer cancel()
+		if err := s.Reviews.prefetch(ctx, event); err != nil {
+			log.FromContext(ctx).Error(err, "unable to pre-fetch pull request", "repository", event.Owner+"/"+event.Repository,
+				"pullRequest", event.PullRequest)
+		}
+	}()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// validSignature checks the X-Hub-Signature-256 header GitHub computes over
+// the payload with the webhook secret
+func validSignature(secret, body []byte, signature string) bool {
+	hexDigest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	digest, err := hex.DecodeString(hexDigest)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hmac.Equal(digest, mac.Sum(nil))
+}
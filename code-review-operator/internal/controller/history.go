@@ -0,0 +1,81 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/history"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+	"github.com/Shridhar2104/code-review-operator/pkg/review"
+)
+
+// recordHistory saves a posted review to the review history. Errors are
+// logged; they do not fail the review.
+func (r *CodeReviewReconciler) recordHistory(ctx context.Context, cr *reviewv1alpha1.CodeReview, job *review.Job) {
+	if r.History == nil || job.DryRun {
+		return
+	}
+
+	record := &history.Record{
+		Namespace:   cr.Namespace,
+		Name:        cr.Name,
+		Provider:    cr.Spec.Provider,
+		Owner:       cr.Spec.Owner,
+		Repository:  cr.Spec.Repository,
+		PullRequest: cr.Spec.PullRequest,
+		CommitSHA:   cr.Spec.CommitSHA,
+		Decision:    string(job.Decision),
+		Comments:    make([]llm.ReviewComment, 0, len(job.Comments)),
+	}
+	for _, comment := range job.Comments {
+		record.Comments = append(record.Comments, llm.ReviewComment{
+			File:          comment.File,
+			Line:          comment.Line,
+			StartLine:     comment.StartLine,
+			Content:       comment.Content,
+			Severity:      comment.Severity,
+			Rule:          comment.Rule,
+			SuggestedCode: comment.SuggestedCode,
+		})
+	}
+	if usage := cr.Status.Usage; usage != nil {
+		record.PromptTokens = usage.PromptTokens
+		record.CompletionTokens = usage.CompletionTokens
+		record.TotalTokens = usage.TotalTokens
+	}
+	if job.Result != nil {
+		for _, usage := range job.Result.Usage {
+			if usage.Model != "" {
+				record.Models = append(record.Models, usage.Model)
+			}
+		}
+	}
+	if cr.Status.CompletionTime != nil {
+		record.CompletedAt = cr.Status.CompletionTime.UTC()
+		if cr.Status.StartTime != nil {
+			record.Duration = cr.Status.CompletionTime.Sub(cr.Status.StartTime.Time)
+		}
+	}
+
+	if err := r.History.Save(ctx, record); err != nil {
+		log.FromContext(ctx).Error(err, "unable to record review history")
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+)
+
+// lastReviewedSHA returns the head commit of the most recently completed
+// review of the same pull request, or "" if there is none. Dry runs posted
+// nothing, so they do not count.
+func (r *CodeReviewReconciler) lastReviewedSHA(ctx context.Context, review *reviewv1alpha1.CodeReview) (string, error) {
+	if review.Spec.PullRequest == 0 || review.Spec.CommitSHA == "" {
+		return "", nil
+	}
+
+	var reviews reviewv1alpha1.CodeReviewList
+	if err := r.List(ctx, &reviews, client.InNamespace(review.Namespace)); err != nil {
+		return "", fmt.Errorf("error listing reviews: %w", err)
+	}
+
+	var last *reviewv1alpha1.CodeReview
+	for i := range reviews.Items {
+		other := &reviews.Items[i]
+		if other.Name == review.Name || !samePullRequest(review, other) ||
+			other.Status.Phase != reviewv1alpha1.CodeReviewPhaseCompleted ||
+			other.Status.ReviewedSHA == "" || other.Status.ReviewedSHA == review.Spec.CommitSHA ||
+			other.Status.CompletionTime == nil || other.Status.DryRun != nil {
+			continue
+		}
+		if last == nil || last.Status.CompletionTime.Before(other.Status.CompletionTime) {
+			last = other
+		}
+	}
+	if last == nil {
+		return "", nil
+	}
+
+	return last.Status.ReviewedSHA, nil
+}
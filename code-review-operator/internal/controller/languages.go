@@ -0,0 +1,69 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm/prompt"
+	"github.com/Shridhar2104/code-review-operator/pkg/repoconfig"
+	reviewpkg "github.com/Shridhar2104/code-review-operator/pkg/review"
+)
+
+// languageRoutes builds the routes of the languages a repository's
+// configuration reviews apart. A language's model tier selects its client
+// unless the review or its ReviewerConfig pins its LLM, and its prompt names
+// a ReviewPrompt of the review's namespace.
+func (r *CodeReviewReconciler) languageRoutes(ctx context.Context, review *reviewv1alpha1.CodeReview,
+	config *repoconfig.Config, models map[string]ModelSettings) (map[string]reviewpkg.Route, error) {
+	routes := reviewpkg.LanguageRoutes(config)
+	tenantLLM, err := r.tenantLLM(ctx, review)
+	if err != nil {
+		return nil, err
+	}
+	pinned := review.Spec.LLM != nil || tenantLLM != nil
+	clients := map[string]llm.Client{}
+	for language, settings := range config.Languages {
+		language = prompt.Normalize(language)
+		route := routes[language]
+
+		if tier := settings.ModelTier; tier != "" && tier != config.ModelTier && !pinned {
+			client, ok := clients[tier]
+			if !ok {
+				var err error
+				client, err = r.llmClient(ctx, review, models[tier])
+				if err != nil {
+					return nil, err
+				}
+				clients[tier] = client
+			}
+			route.LLM = client
+		}
+		if settings.Prompt != "" {
+			template, err := r.namedPrompt(ctx, review.Namespace, settings.Prompt)
+			if err != nil {
+				return nil, err
+			}
+			route.Prompt = template
+		}
+		routes[language] = route
+	}
+
+	return routes, nil
+}
@@ -0,0 +1,31 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/metrics"
+)
+
+// recordFinished exports the phase a review ended in and how long it took
+func recordFinished(review *reviewv1alpha1.CodeReview) {
+	phase := string(review.Status.Phase)
+	metrics.ReviewsFinished.WithLabelValues(phase).Inc()
+	if start, end := review.Status.StartTime, review.Status.CompletionTime; start != nil && end != nil {
+		metrics.ReviewDuration.WithLabelValues(phase).Observe(end.Sub(start.Time).Seconds())
+	}
+}
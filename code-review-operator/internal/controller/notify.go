@@ -0,0 +1,102 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/notify"
+)
+
+// notificationEvent describes the outcome recorded in a review's status,
+// counting comments by severity
+func notificationEvent(cr *reviewv1alpha1.CodeReview, kind notify.Kind, comments []git.ReviewComment) *notify.Event {
+	event := &notify.Event{
+		Kind:        kind,
+		Namespace:   cr.Namespace,
+		Name:        cr.Name,
+		Owner:       cr.Spec.Owner,
+		Repository:  cr.Spec.Repository,
+		PullRequest: cr.Spec.PullRequest,
+		CommitSHA:   cr.Spec.CommitSHA,
+		URL:         cr.Status.ReviewURL,
+		Decision:    cr.Status.Decision,
+	}
+	if cr.Status.CompletionTime != nil {
+		event.Time = cr.Status.CompletionTime.UTC()
+	}
+	if len(comments) > 0 {
+		event.Severities = make(map[string]int)
+		for _, comment := range comments {
+			event.Severities[comment.Severity]++
+		}
+	}
+
+	return event
+}
+
+// sendNotifications announces the outcome of a review to the sinks of its
+// spec that notify it. Dry runs notify nothing. Errors are logged and
+// recorded as events; they do not fail the review.
+func (r *CodeReviewReconciler) sendNotifications(ctx context.Context, cr *reviewv1alpha1.CodeReview, event *notify.Event) {
+	if r.dryRun(cr) {
+		return
+	}
+	logger := log.FromContext(ctx)
+
+	sinks, err := r.notifications(ctx, cr)
+	if err != nil {
+		logger.Error(err, "unable to read the reviewer config's notifications")
+	}
+	for _, sink := range sinks {
+		if len(sink.On) > 0 && !slices.Contains(sink.On, reviewv1alpha1.NotificationEvent(event.Kind)) {
+			continue
+		}
+		if err := r.notify(ctx, cr, sink, event); err != nil {
+			logger.Error(err, "unable to send notification", "type", sink.Type)
+			r.Recorder.Eventf(cr, corev1.EventTypeWarning, "NotificationFailed", "unable to send %s notification: %v", sink.Type, err)
+		}
+	}
+}
+
+// notify sends event to a sink, reading its URL from the referenced Secret
+func (r *CodeReviewReconciler) notify(ctx context.Context, cr *reviewv1alpha1.CodeReview, sink reviewv1alpha1.NotificationSpec, event *notify.Event) error {
+	ref := sink.URLSecretRef
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cr.Namespace, Name: ref.Name}, &secret); err != nil {
+		return fmt.Errorf("error getting secret %s: %w", ref.Name, err)
+	}
+	url, ok := secret.Data[ref.Key]
+	if !ok {
+		return fmt.Errorf("secret %s is missing key %q", ref.Name, ref.Key)
+	}
+
+	notifier, err := notify.New(sink.Type, string(url))
+	if err != nil {
+		return err
+	}
+
+	return notifier.Notify(ctx, event)
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/yaml"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/llm/prompt"
+	"github.com/Shridhar2104/code-review-operator/pkg/repoconfig"
+)
+
+// OperatorConfigKey is the key of the operator configuration in its ConfigMap
+const OperatorConfigKey = "operator.yaml"
+
+// OperatorConfig is the operator-wide configuration, reloaded while the
+// operator runs when its ConfigMap changes
+type OperatorConfig struct {
+	// Review holds default review settings in the .ai-review.yaml format,
+	// layered over the built-in defaults and under the org-wide configuration
+	Review *repoconfig.Config `json:"review,omitempty"`
+
+	// Models maps model tiers to the LLM backend reviews of the tier use when
+	// their spec does not select one
+	Models map[string]ModelSettings `json:"models,omitempty"`
+}
+
+// ModelSettings selects the LLM backend of a model tier
+type ModelSettings struct {
+	// Provider is the LLM backend, as accepted by --llm-provider
+	Provider string `json:"provider"`
+
+	// Model is the model name
+	Model string `json:"model,omitempty"`
+
+	// Endpoint is the API base URL of the backend
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// ContextWindow is the model's context window in tokens
+	ContextWindow int `json:"contextWindow,omitempty"`
+
+	// Region and Project locate cloud backends
+	Region  string `json:"region,omitempty"`
+	Project string `json:"project,omitempty"`
+
+	// APIKeyEnv is the environment variable of the operator holding the
+	// backend's API key (defaults to LLM_API_KEY)
+	APIKeyEnv string `json:"apiKeyEnv,omitempty"`
+}
+
+// ParseOperatorConfig parses and validates an operator configuration
+func ParseOperatorConfig(data []byte) (*OperatorConfig, error) {
+	var config OperatorConfig
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing operator config: %w", err)
+	}
+
+	var problems []string
+	if config.Review != nil {
+		for _, problem := range config.Review.Validate() {
+			problems = append(problems, "review."+problem)
+		}
+	}
+	for tier, model := range config.Models {
+		if model.Provider == "" {
+			problems = append(problems, fmt.Sprintf("models[%s].provider: must not be empty", tier))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("invalid operator config: %s", strings.Join(problems, "; "))
+	}
+
+	return &config, nil
+}
+
+// OperatorConfigGeneration is one loaded version of the operator
+// configuration and prompt templates
+type OperatorConfigGeneration struct {
+	// Config is the operator configuration
+	Config OperatorConfig
+
+	// Templates are the prompt templates, nil for the built-in templates
+	Templates *prompt.Set
+
+	// Generation identifies the version: a hash of the ConfigMaps' data,
+	// empty when neither ConfigMap is configured
+	Generation string
+}
+
+// OperatorConfigStore holds the current operator configuration, read from the
+// operator config and prompt template ConfigMaps. It is loaded on first use
+// and reloaded by OperatorConfigReconciler when either ConfigMap changes.
+type OperatorConfigStore struct {
+	// Reader reads the ConfigMaps
+	Reader client.Reader
+
+	// ConfigMap names the ConfigMap holding OperatorConfigKey; the operator
+	// configuration is empty when the name is empty or the ConfigMap is missing
+	ConfigMap types.NamespacedName
+
+	// PromptConfigMap names a ConfigMap overriding the built-in prompt
+	// templates. The built-in templates are used when the name is empty.
+	PromptConfigMap types.NamespacedName
+
+	loadMu  sync.Mutex
+	current atomic.Pointer[OperatorConfigGeneration]
+}
+
+// Current returns the current operator configuration, loading it if it has
+// not been loaded yet
+func (s *OperatorConfigStore) Current(ctx context.Context) (*OperatorConfigGeneration, error) {
+	if s == nil {
+		return &OperatorConfigGeneration{}, nil
+	}
+	if current := s.current.Load(); current != nil {
+		return current, nil
+	}
+	if _, err := s.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.current.Load(), nil
+}
+
+// Reload reads the ConfigMaps and makes their contents current, reporting
+// whether the generation changed. An invalid configuration is not loaded,
+// so reviews keep using the previous one.
+func (s *OperatorConfigStore) Reload(ctx context.Context) (bool, error) {
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	loaded := &OperatorConfigGeneration{}
+	hash := sha256.New()
+
+	if s.ConfigMap.Name != "" {
+		var configMap corev1.ConfigMap
+		err := s.Reader.Get(ctx, s.ConfigMap, &configMap)
+		switch {
+		case apierrors.IsNotFound(err):
+		case err != nil:
+			return false, fmt.Errorf("error getting operator configmap %s: %w", s.ConfigMap.Name, err)
+		default:
+			data := configMap.Data[OperatorConfigKey]
+			config, err := ParseOperatorConfig([]byte(data))
+			if err != nil {
+				return false, fmt.Errorf("configmap %s: %w", s.ConfigMap.Name, err)
+			}
+			loaded.Config = *config
+			fmt.Fprintf(hash, "%s\x00%s\x00", OperatorConfigKey, data)
+		}
+	}
+
+	if s.PromptConfigMap.Name != "" {
+		var configMap corev1.ConfigMap
+		if err := s.Reader.Get(ctx, s.PromptConfigMap, &configMap); err != nil {
+			return false, fmt.Errorf("error getting prompt template configmap %s: %w", s.PromptConfigMap.Name, err)
+		}
+		templates, err := prompt.Default().WithOverrides(configMap.Data)
+		if err != nil {
+			return false, fmt.Errorf("invalid prompt templates in configmap %s: %w", s.PromptConfigMap.Name, err)
+		}
+		loaded.Templates = templates
+		keys := make([]string, 0, len(configMap.Data))
+		for key := range configMap.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(hash, "%s\x00%s\x00", key, configMap.Data[key])
+		}
+	}
+
+	if s.ConfigMap.Name != "" || s.PromptConfigMap.Name != "" {
+		loaded.Generation = hex.EncodeToString(hash.Sum(nil))[:12]
+	}
+	previous := s.current.Swap(loaded)
+
+	return previous == nil || previous.Generation != loaded.Generation, nil
+}
+
+// watches reports whether a ConfigMap is one of the store's
+func (s *OperatorConfigStore) watches(name types.NamespacedName) bool {
+	return (s.ConfigMap.Name != "" && name == s.ConfigMap) ||
+		(s.PromptConfigMap.Name != "" && name == s.PromptConfigMap)
+}
+
+// OperatorConfigReconciler reloads the operator configuration and prompt
+// templates when their ConfigMaps change, so changes apply to the next
+// reviews without restarting the operator
+type OperatorConfigReconciler struct {
+	client.Client
+
+	// Store holds the configuration reloaded
+	Store *OperatorConfigStore
+}
+
+// Reconcile reloads the operator configuration. Invalid changes are logged
+// and the previous configuration stays in use until the ConfigMap is fixed.
+func (r *OperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	changed, err := r.Store.Reload(ctx)
+	if err != nil {
+		logger.Error(err, "unable to reload operator configuration, keeping the previous configuration")
+		return ctrl.Result{}, nil
+	}
+	if changed {
+		current, _ := r.Store.Current(ctx)
+		logger.Info("reloaded operator configuration", "generation", current.Generation)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *OperatorConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("operatorconfig").
+		For(&corev1.ConfigMap{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
+			return r.Store.watches(client.ObjectKeyFromObject(object))
+		}))).
+		Complete(r)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+)
+
+const (
+	// prefetchTimeout bounds pre-fetching one pull request
+	prefetchTimeout = 5 * time.Minute
+
+	// maxPrefetchFiles is the most changed files pre-fetched for a pull request
+	maxPrefetchFiles = 200
+
+	// prefetchConcurrency is the number of files fetched at once
+	prefetchConcurrency = 4
+)
+
+// pullRequestEvent is a pull request opened or updated on a Git provider
+type pullRequestEvent struct {
+	Provider    string
+	Owner       string
+	Repository  string
+	PullRequest int
+	HeadSHA     string
+}
+
+// prefetch warms the content cache with the diff, the configuration and the
+// changed files of a pull request at its head commit, so that its review,
+// however late it is scheduled, starts without reading them. The token of the
+// latest review of the repository is used, as the pull request may not have
+// a review yet. Failures only leave the cache cold and are logged.
+func (r *CodeReviewReconciler) prefetch(ctx context.Context, event pullRequestEvent) error {
+	if r.ContentCache == nil {
+		return nil
+	}
+	logger := log.FromContext(ctx).WithValues(
+		"provider", event.Provider,
+		"repository", event.Owner+"/"+event.Repository,
+		"pullRequest", event.PullRequest,
+		"commitSHA", event.HeadSHA,
+	)
+	ctx = log.IntoContext(ctx, logger)
+
+	latest, err := r.repositoryReview(ctx, event)
+	if err != nil {
+		return err
+	}
+	if latest == nil {
+		logger.V(1).Info("not pre-fetching a pull request of a repository without reviews")
+		return nil
+	}
+	gitClient, err := r.gitClient(ctx, latest)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	text, err := gitClient.GetDiff(ctx, event.Owner, event.Repository, event.PullRequest, event.HeadSHA)
+	if err != nil {
+		return fmt.Errorf("error getting diff: %w", err)
+	}
+	source, err := r.orgConfigSource(ctx, latest)
+	if err != nil {
+		return err
+	}
+	if _, _, err := r.ConfigLoader.LoadLayers(ctx, gitClient, source, event.Repository, event.HeadSHA); err != nil {
+		logger.V(1).Info("unable to pre-fetch review config", "error", err.Error())
+	}
+	files, err := diff.Parse(text)
+	if err != nil {
+		return fmt.Errorf("error parsing diff: %w", err)
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(prefetchConcurrency)
+	fetched := 0
+	for _, file := range files {
+		if file.Binary || file.Status == diff.FileDeleted || file.NewPath == "" {
+			continue
+		}
+		if fetched == maxPrefetchFiles {
+			break
+		}
+		fetched++
+		path := file.NewPath
+		group.Go(func() error {
+			if _, err := gitClient.GetFileContent(groupCtx, event.Owner, event.Repository, path, event.HeadSHA); err != nil {
+				logger.V(1).Info("unable to pre-fetch file", "file", path, "error", err.Error())
+			}
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	logger.Info("pre-fetched pull request", "files", fetched, "duration", time.Since(start).Round(time.Millisecond).String())
+	return nil
+}
+
+// repositoryReview returns the latest review of the event's repository, or
+// nil if it has none
+func (r *CodeReviewReconciler) repositoryReview(ctx context.Context, event pullRequestEvent) (*reviewv1alpha1.CodeReview, error) {
+	var list reviewv1alpha1.CodeReviewList
+	if err := r.List(ctx, &list); err != nil {
+		return nil, fmt.Errorf("error listing reviews: %w", err)
+	}
+
+	var reviews []*reviewv1alpha1.CodeReview
+	for i := range list.Items {
+		spec := list.Items[i].Spec
+		if spec.Provider == event.Provider && spec.Owner == event.Owner && spec.Repository == event.Repository {
+			reviews = append(reviews, &list.Items[i])
+		}
+	}
+	if len(reviews) == 0 {
+		return nil, nil
+	}
+	sort.Slice(reviews, func(i, j int) bool {
+		return newer(reviews[i], reviews[j])
+	})
+
+	return reviews[0], nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	reviewpkg "github.com/Shridhar2104/code-review-operator/pkg/review"
+)
+
+// progressInterval is the shortest time between two status updates with the
+// progress of a review
+const progressInterval = 5 * time.Second
+
+// progressTracker records the progress of a running review in its status.
+// Updates are throttled to one per progressInterval and patch a copy of the
+// review, which stop reconciles with the review the reconciler holds.
+type progressTracker struct {
+	client client.StatusClient
+
+	mu       sync.Mutex
+	progress reviewv1alpha1.ReviewProgress
+	changed  bool
+
+	// latest is the review as last patched, owned by the tracker's goroutine until it stops
+	latest *reviewv1alpha1.CodeReview
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// trackProgress records the progress of a job in the review's status until
+// stop is called. Status updates are best effort: failures are logged.
+func (r *CodeReviewReconciler) trackProgress(ctx context.Context, review *reviewv1alpha1.CodeReview, job *reviewpkg.Job) *progressTracker {
+	t := &progressTracker{
+		client:  r.Client,
+		latest:  review.DeepCopy(),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	job.OnProgress = t.observe
+	go t.run(ctx)
+
+	return t
+}
+
+// observe records an event of the review
+func (t *progressTracker) observe(event reviewpkg.ReviewEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.progress.FilesReviewed = event.FilesReviewed
+	t.progress.FilesTotal = event.FilesTotal
+	if event.Comment != nil {
+		t.progress.Comments++
+	}
+	t.changed = true
+}
+
+// run patches the review's status with its progress until the tracker stops
+func (t *progressTracker) run(ctx context.Context) {
+	defer close(t.stopped)
+
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.flush(ctx)
+		}
+	}
+}
+
+// flush patches the review's status with its progress if it changed
+func (t *progressTracker) flush(ctx context.Context) {
+	t.mu.Lock()
+	if !t.changed {
+		t.mu.Unlock()
+		return
+	}
+	progress := t.progress
+	t.changed = false
+	t.mu.Unlock()
+
+	now := metav1.Now()
+	progress.LastUpdateTime = &now
+	base := t.latest.DeepCopy()
+	t.latest.Status.Progress = &progress
+	if err := t.client.Status().Patch(ctx, t.latest, client.MergeFrom(base)); err != nil {
+		log.FromContext(ctx).V(1).Info("unable to record review progress", "error", err.Error())
+		t.latest = base
+	}
+}
+
+// stop stops recording progress and brings the review up to date with the
+// last patch and the final progress, so later status updates keep them
+func (t *progressTracker) stop(review *reviewv1alpha1.CodeReview) {
+	close(t.done)
+	<-t.stopped
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := metav1.Now()
+	progress := t.progress
+	progress.LastUpdateTime = &now
+	review.ResourceVersion = t.latest.ResourceVersion
+	review.Status.Progress = &progress
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/review"
+)
+
+// reviewProvenance returns the provenance recorded for a job in the status,
+// with its signed attestation if reviews are signed
+func reviewProvenance(job *review.Job) *reviewv1alpha1.ReviewProvenance {
+	if job.Provenance == nil {
+		return nil
+	}
+
+	recorded := &reviewv1alpha1.ReviewProvenance{
+		OperatorVersion: job.Provenance.OperatorVersion,
+		Models:          job.Provenance.Models,
+		PromptHash:      job.Provenance.PromptHash,
+		SummaryDigest:   job.Provenance.SummaryDigest,
+	}
+	if job.Attestation != nil {
+		if len(job.Attestation.Signatures) > 0 {
+			recorded.KeyID = job.Attestation.Signatures[0].KeyID
+		}
+		if data, err := json.Marshal(job.Attestation); err == nil {
+			recorded.Attestation = base64.StdEncoding.EncodeToString(data)
+		}
+	}
+
+	return recorded
+}
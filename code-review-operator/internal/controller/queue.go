@@ -0,0 +1,160 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/scheduler"
+)
+
+// queueRecheckInterval is how often a queued review asks for a slot again
+const queueRecheckInterval = 5 * time.Second
+
+// reviewSize is the size of a review's changes at a commit
+type reviewSize struct {
+	commitSHA string
+	lines     int
+}
+
+// admit asks the scheduler for a slot to run a review in. It returns a
+// function releasing the slot, or false if the review must wait.
+func (r *CodeReviewReconciler) admit(ctx context.Context, review *reviewv1alpha1.CodeReview) (func(), bool) {
+	if r.Scheduler == nil {
+		return func() {}, true
+	}
+
+	name := types.NamespacedName{Namespace: review.Namespace, Name: review.Name}
+	release, ok := r.Scheduler.TryAcquire(scheduler.Ticket{
+		Key:        name.String(),
+		Provider:   review.Spec.Provider,
+		Repository: review.Spec.Owner + "/" + review.Spec.Repository,
+		Size:       r.changedLines(ctx, name, review),
+		Priority:   priority(review),
+	})
+	if ok {
+		r.forgetSize(name)
+	}
+
+	return release, ok
+}
+
+// priority returns the priority of a review from its annotation, 0 if it
+// has none or it is not an integer
+func priority(review *reviewv1alpha1.CodeReview) int {
+	value, err := strconv.Atoi(review.Annotations[reviewv1alpha1.PriorityAnnotation])
+	if err != nil {
+		return 0
+	}
+
+	return value
+}
+
+// dequeue removes a review that will not run from the scheduler's queue
+func (r *CodeReviewReconciler) dequeue(name types.NamespacedName) {
+	if r.Scheduler == nil {
+		return
+	}
+	r.Scheduler.Forget(name.String())
+	r.forgetSize(name)
+}
+
+// changedLines returns the number of lines a review's diff changes, which
+// the scheduler uses to run small reviews first. The size is fetched once per
+// commit while the review waits; reviews whose diff cannot be read count as
+// empty, so they run and report the error.
+func (r *CodeReviewReconciler) changedLines(ctx context.Context, name types.NamespacedName, review *reviewv1alpha1.CodeReview) int {
+	r.sizesMu.Lock()
+	size, ok := r.sizes[name]
+	r.sizesMu.Unlock()
+	if ok && size.commitSHA == review.Spec.CommitSHA {
+		return size.lines
+	}
+
+	logger := log.FromContext(ctx)
+	size = reviewSize{commitSHA: review.Spec.CommitSHA}
+	gitClient, err := r.reviewClient(ctx, review)
+	if err != nil {
+		return 0
+	}
+	if reader, ok := gitClient.(git.PullRequestReader); ok && review.Spec.PullRequest != 0 && review.Spec.Diff == nil {
+		// The changed files carry their line counts, so the diff need not be downloaded
+		if pr, err := reader.GetPullRequest(ctx, review.Spec.Owner, review.Spec.Repository, review.Spec.PullRequest); err == nil {
+			size.lines = 0
+			for _, file := range pr.ChangedFiles {
+				size.lines += file.Additions + file.Deletions
+			}
+			r.rememberSize(name, size)
+			return size.lines
+		}
+	}
+	patch, err := gitClient.GetDiff(ctx, review.Spec.Owner, review.Spec.Repository, review.Spec.PullRequest, review.Spec.CommitSHA)
+	if err != nil {
+		logger.Error(err, "unable to fetch diff to size the review, queueing it as empty")
+		return 0
+	}
+	files, err := diff.Parse(patch)
+	if err != nil {
+		logger.Error(err, "unable to parse diff to size the review, queueing it as empty")
+		return 0
+	}
+	size.lines = countChangedLines(files)
+	r.rememberSize(name, size)
+
+	return size.lines
+}
+
+// rememberSize caches the size of a review
+func (r *CodeReviewReconciler) rememberSize(name types.NamespacedName, size reviewSize) {
+	r.sizesMu.Lock()
+	defer r.sizesMu.Unlock()
+	if r.sizes == nil {
+		r.sizes = make(map[types.NamespacedName]reviewSize)
+	}
+	r.sizes[name] = size
+}
+
+// forgetSize drops the cached size of a review
+func (r *CodeReviewReconciler) forgetSize(name types.NamespacedName) {
+	r.sizesMu.Lock()
+	defer r.sizesMu.Unlock()
+
+	delete(r.sizes, name)
+}
+
+// countChangedLines returns the number of added and removed lines in a diff
+func countChangedLines(files []*diff.File) int {
+	lines := 0
+	for _, file := range files {
+		for _, hunk := range file.Hunks {
+			for _, line := range hunk.Lines {
+				if line.Kind != diff.LineContext {
+					lines++
+				}
+			}
+		}
+	}
+	return lines
+}
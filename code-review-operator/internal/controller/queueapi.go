@@ -0,0 +1,263 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/queue"
+	"github.com/Shridhar2104/code-review-operator/pkg/scheduler"
+)
+
+// errCancelled is the cancellation cause of reviews cancelled through the queue API
+var errCancelled = errors.New("review cancelled through the queue API")
+
+// QueueManager implements queue.Manager over the reconciler's reviews and
+// scheduler. Changes are made to the CodeReviews, so they outlast the
+// operator's pod; running reviews are cancelled at once.
+type QueueManager struct {
+	Reconciler *CodeReviewReconciler
+}
+
+var _ queue.Manager = &QueueManager{}
+
+// List implements queue.Manager
+func (m *QueueManager) List(ctx context.Context, namespace string) ([]queue.Entry, error) {
+	r := m.Reconciler
+	var reviews reviewv1alpha1.CodeReviewList
+	if err := r.List(ctx, &reviews, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("error listing reviews: %w", err)
+	}
+
+	scheduled := make(map[string]scheduler.Entry)
+	if r.Scheduler != nil {
+		for _, entry := range r.Scheduler.Entries() {
+			scheduled[entry.Key] = entry
+		}
+	}
+
+	entries := make([]queue.Entry, 0, len(reviews.Items))
+	for i := range reviews.Items {
+		review := &reviews.Items[i]
+		name := client.ObjectKeyFromObject(review)
+		entry := queue.Entry{
+			Namespace:   review.Namespace,
+			Name:        review.Name,
+			Provider:    review.Spec.Provider,
+			Repository:  review.Spec.Owner + "/" + review.Spec.Repository,
+			PullRequest: review.Spec.PullRequest,
+			CommitSHA:   review.Spec.CommitSHA,
+			Phase:       string(review.Status.Phase),
+			Priority:    priority(review),
+			Size:        m.size(name, review),
+			Since:       review.CreationTimestamp.Time,
+		}
+		scheduledEntry, isScheduled := scheduled[name.String()]
+		switch {
+		case review.Status.Phase == reviewv1alpha1.CodeReviewPhaseFailed ||
+			review.Status.Phase == reviewv1alpha1.CodeReviewPhaseTimedOut:
+			entry.State = queue.StateFailed
+			if review.Status.CompletionTime != nil {
+				entry.Since = review.Status.CompletionTime.Time
+			}
+		case isFinished(review):
+			continue
+		case m.running(name):
+			entry.State = queue.StateRunning
+			if review.Status.StartTime != nil {
+				entry.Since = review.Status.StartTime.Time
+			}
+		case isScheduled && !scheduledEntry.Running:
+			entry.State = queue.StateQueued
+			entry.Position = scheduledEntry.Position
+			entry.Size = scheduledEntry.Size
+			entry.Since = scheduledEntry.WaitingSince
+		default:
+			entry.State = queue.StatePending
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if stateOrder[a.State] != stateOrder[b.State] {
+			return stateOrder[a.State] < stateOrder[b.State]
+		}
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+
+		return a.Since.Before(b.Since)
+	})
+
+	return entries, nil
+}
+
+// stateOrder orders the states of queue entries in a listing
+var stateOrder = map[string]int{
+	queue.StateRunning: 0,
+	queue.StateQueued:  1,
+	queue.StatePending: 2,
+	queue.StateFailed:  3,
+}
+
+// Cancel implements queue.Manager. The review is marked cancelled, and
+// stopped if it is running.
+func (m *QueueManager) Cancel(ctx context.Context, namespace, name string) error {
+	r := m.Reconciler
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	var review reviewv1alpha1.CodeReview
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := m.get(ctx, key, &review); err != nil {
+			return err
+		}
+		if isFinished(&review) {
+			return fmt.Errorf("review %s is %s: %w", key, review.Status.Phase, queue.ErrInvalidState)
+		}
+
+		now := metav1.Now()
+		review.Status.Phase = reviewv1alpha1.CodeReviewPhaseCancelled
+		review.Status.CompletionTime = &now
+		return r.Status().Update(ctx, &review)
+	})
+	if err != nil {
+		return err
+	}
+
+	r.cancelInflight(key, errCancelled)
+	r.dequeue(key)
+	recordFinished(&review)
+	r.Recorder.Event(&review, corev1.EventTypeNormal, "Cancelled", "cancelled through the queue API")
+	log.FromContext(ctx).Info("review cancelled through the queue API", "review", key)
+
+	return nil
+}
+
+// Prioritize implements queue.Manager. The priority applies the next time
+// the review asks for a slot.
+func (m *QueueManager) Prioritize(ctx context.Context, namespace, name string, value int) error {
+	r := m.Reconciler
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	var review reviewv1alpha1.CodeReview
+	if err := m.get(ctx, key, &review); err != nil {
+		return err
+	}
+	if isFinished(&review) {
+		return fmt.Errorf("review %s is %s: %w", key, review.Status.Phase, queue.ErrInvalidState)
+	}
+
+	patch := client.MergeFrom(review.DeepCopy())
+	if review.Annotations == nil {
+		review.Annotations = make(map[string]string)
+	}
+	review.Annotations[reviewv1alpha1.PriorityAnnotation] = strconv.Itoa(value)
+	if err := r.Patch(ctx, &review, patch); err != nil {
+		return fmt.Errorf("error setting review priority: %w", err)
+	}
+	r.Recorder.Eventf(&review, corev1.EventTypeNormal, "Prioritized", "priority set to %d through the queue API", value)
+
+	return nil
+}
+
+// Requeue implements queue.Manager. The review starts over as if it had
+// just been created.
+func (m *QueueManager) Requeue(ctx context.Context, namespace, name string) error {
+	r := m.Reconciler
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	var review reviewv1alpha1.CodeReview
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := m.get(ctx, key, &review); err != nil {
+			return err
+		}
+		if review.Status.Phase != reviewv1alpha1.CodeReviewPhaseFailed &&
+			review.Status.Phase != reviewv1alpha1.CodeReviewPhaseTimedOut {
+			return fmt.Errorf("review %s is %s, only failed and timed out reviews can be requeued: %w",
+				key, phaseOrPending(&review), queue.ErrInvalidState)
+		}
+
+		review.Status.Phase = reviewv1alpha1.CodeReviewPhasePending
+		review.Status.StartTime = nil
+		review.Status.CompletionTime = nil
+		review.Status.Progress = nil
+		return r.Status().Update(ctx, &review)
+	})
+	if err != nil {
+		return err
+	}
+
+	r.Recorder.Event(&review, corev1.EventTypeNormal, "Requeued", "requeued through the queue API")
+	log.FromContext(ctx).Info("review requeued through the queue API", "review", key)
+
+	return nil
+}
+
+// get reads a review, returning queue.ErrNotFound if it does not exist
+func (m *QueueManager) get(ctx context.Context, key types.NamespacedName, review *reviewv1alpha1.CodeReview) error {
+	err := m.Reconciler.Get(ctx, key, review)
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("review %s: %w", key, queue.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("error getting review %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// running reports whether a review is being run
+func (m *QueueManager) running(name types.NamespacedName) bool {
+	r := m.Reconciler
+	r.inflightMu.Lock()
+	defer r.inflightMu.Unlock()
+
+	_, ok := r.inflight[name]
+	return ok
+}
+
+// size returns the changed lines of a review, if they were counted for its commit
+func (m *QueueManager) size(name types.NamespacedName, review *reviewv1alpha1.CodeReview) int {
+	r := m.Reconciler
+	r.sizesMu.Lock()
+	defer r.sizesMu.Unlock()
+
+	if size, ok := r.sizes[name]; ok && size.commitSHA == review.Spec.CommitSHA {
+		return size.lines
+	}
+
+	return 0
+}
+
+// phaseOrPending returns the phase of a review, Pending if it has none yet
+func phaseOrPending(review *reviewv1alpha1.CodeReview) reviewv1alpha1.CodeReviewPhase {
+	if review.Status.Phase == "" {
+		return reviewv1alpha1.CodeReviewPhasePending
+	}
+
+	return review.Status.Phase
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/report"
+	reviewpkg "github.com/Shridhar2104/code-review-operator/pkg/review"
+)
+
+// reportKeys are the ConfigMap keys of each report format
+var reportKeys = map[report.Format]string{
+	report.FormatJSON:  "report.json",
+	report.FormatSARIF: "report.sarif",
+}
+
+// exportReport writes the report of a finished review to the ConfigMap named
+// in the spec and records it in the status. Failing to write it is reported
+// in an event and does not fail the review.
+func (r *CodeReviewReconciler) exportReport(ctx context.Context, review *reviewv1alpha1.CodeReview, job *reviewpkg.Job) {
+	spec := review.Spec.Report
+	if spec == nil {
+		return
+	}
+
+	formats := report.Formats
+	if len(spec.Formats) > 0 {
+		formats = nil
+		for _, format := range spec.Formats {
+			formats = append(formats, report.Format(format))
+		}
+	}
+	if err := r.writeReportConfigMap(ctx, review, reviewpkg.Report(job), formats); err != nil {
+		log.FromContext(ctx).Error(err, "unable to write report configmap", "configMap", spec.ConfigMap)
+		r.Recorder.Eventf(review, corev1.EventTypeWarning, "ReportFailed",
+			"unable to write the review report to configmap %s: %v", spec.ConfigMap, err)
+		return
+	}
+	review.Status.Report = spec.ConfigMap
+}
+
+// writeReportConfigMap writes a report in the given formats to the ConfigMap named in the spec
+func (r *CodeReviewReconciler) writeReportConfigMap(ctx context.Context, review *reviewv1alpha1.CodeReview, rep *report.Report, formats []report.Format) error {
+	data := make(map[string]string, len(formats))
+	for _, format := range formats {
+		encoded, err := rep.Marshal(format)
+		if err != nil {
+			return fmt.Errorf("error encoding %s report: %w", format, err)
+		}
+		data[reportKeys[format]] = string(encoded)
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: review.Spec.Report.ConfigMap, Namespace: review.Namespace},
+	}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		configMap.Data = data
+		return controllerutil.SetControllerReference(review, configMap, r.Scheme)
+	})
+
+	return err
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/memory"
+)
+
+// DefaultRetentionInterval is how often expired review data is purged
+const DefaultRetentionInterval = time.Hour
+
+// RetentionPolicy sets how long review data is kept. A zero duration keeps
+// that kind of data forever. Aggregate counts of recurring issues are always kept.
+type RetentionPolicy struct {
+	// Reviews is how long finished CodeReview resources are kept after they finish
+	Reviews time.Duration
+
+	// Summaries is how long review summaries remembered for later prompts are kept
+	Summaries time.Duration
+
+	// Findings is how long the findings tracked per pull request are kept
+	// after the pull request was last reviewed
+	Findings time.Duration
+}
+
+// RetentionPurger deletes review data once it expires under the retention
+// policy, logging a receipt for every deletion to the audit log. It
+// implements manager.Runnable.
+type RetentionPurger struct {
+	// Client is used to list and delete CodeReview resources
+	Client client.Client
+
+	// Memory is the reviewer memory store, nil if memory is disabled
+	Memory *memory.ConfigMapStore
+
+	// Policy sets how long each kind of data is kept
+	Policy RetentionPolicy
+
+	// Interval is how often expired data is purged (defaults to DefaultRetentionInterval)
+	Interval time.Duration
+}
+
+// Start purges expired data until the context is done
+func (p *RetentionPurger) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("retention")
+
+	interval := p.Interval
+	if interval <= 0 {
+		interval = DefaultRetentionInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.Purge(ctx, time.Now()); err != nil {
+			logger.Error(err, "unable to purge expired review data")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge deletes the data that has expired at the given time
+func (p *RetentionPurger) Purge(ctx context.Context, now time.Time) error {
+	audit := log.FromContext(ctx).WithName("audit")
+
+	var errs []error
+	if p.Policy.Reviews > 0 {
+		if err := p.purgeReviews(ctx, now.Add(-p.Policy.Reviews)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if p.Memory != nil && (p.Policy.Summaries > 0 || p.Policy.Findings > 0) {
+		var summariesBefore, findingsBefore time.Time
+		if p.Policy.Summaries > 0 {
+			summariesBefore = now.Add(-p.Policy.Summaries)
+		}
+		if p.Policy.Findings > 0 {
+			findingsBefore = now.Add(-p.Policy.Findings)
+		}
+		err := p.Memory.UpdateAll(ctx, func(repository string, mem *memory.Memory) bool {
+			summaries, findings := mem.Purge(summariesBefore, findingsBefore)
+			if summaries == 0 && findings == 0 {
+				return false
+			}
+			audit.Info("deleted expired reviewer memory", "repository", repository,
+				"summaries", summaries, "findings", findings, "deletedAt", now.UTC())
+			return true
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// purgeReviews deletes the CodeReview resources that finished before the cutoff
+func (p *RetentionPurger) purgeReviews(ctx context.Context, cutoff time.Time) error {
+	audit := log.FromContext(ctx).WithName("audit")
+
+	var reviews reviewv1alpha1.CodeReviewList
+	if err := p.Client.List(ctx, &reviews); err != nil {
+		return fmt.Errorf("error listing reviews: %w", err)
+	}
+
+	var errs []error
+	for i := range reviews.Items {
+		review := &reviews.Items[i]
+		finishedAt := review.CreationTimestamp.Time
+		if review.Status.CompletionTime != nil {
+			finishedAt = review.Status.CompletionTime.Time
+		}
+		if !isFinished(review) || !finishedAt.Before(cutoff) {
+			continue
+		}
+
+		if err := p.Client.Delete(ctx, review); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, fmt.Errorf("error deleting review %s/%s: %w", review.Namespace, review.Name, err))
+			continue
+		}
+		audit.Info("deleted expired review", "namespace", review.Namespace, "name", review.Name,
+			"repository", review.Spec.Owner+"/"+review.Spec.Repository, "pullRequest", review.Spec.PullRequest,
+			"finishedAt", finishedAt.UTC(), "deletedAt", time.Now().UTC())
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm/prompt"
+)
+
+// ReviewPromptReconciler validates ReviewPrompt templates and renders their previews
+type ReviewPromptReconciler struct {
+	client.Client
+}
+
+// +kubebuilder:rbac:groups=review.code-review.io,resources=reviewprompts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=review.code-review.io,resources=reviewprompts/status,verbs=get;update;patch
+
+// Reconcile validates a ReviewPrompt and records the outcome and any
+// requested preview in its status
+func (r *ReviewPromptReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var reviewPrompt reviewv1alpha1.ReviewPrompt
+	if err := r.Get(ctx, req.NamespacedName, &reviewPrompt); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	template := promptTemplate(&reviewPrompt)
+	reviewPrompt.Status.ObservedGeneration = reviewPrompt.Generation
+	reviewPrompt.Status.Preview = nil
+	if err := template.Validate(); err != nil {
+		meta.SetStatusCondition(&reviewPrompt.Status.Conditions, metav1.Condition{
+			Type:    reviewv1alpha1.ConditionValid,
+			Status:  metav1.ConditionFalse,
+			Reason:  "InvalidTemplate",
+			Message: err.Error(),
+		})
+	} else {
+		meta.SetStatusCondition(&reviewPrompt.Status.Conditions, metav1.Condition{
+			Type:    reviewv1alpha1.ConditionValid,
+			Status:  metav1.ConditionTrue,
+			Reason:  "TemplatesValid",
+			Message: "prompt templates parse and render",
+		})
+
+		if preview := reviewPrompt.Spec.Preview; preview != nil {
+			system, user, err := llm.RenderPrompt(preview.Diff, llm.ReviewOptions{
+				Rules:    preview.Rules,
+				Language: preview.Language,
+				Prompt:   template,
+			})
+			if err != nil {
+				meta.SetStatusCondition(&reviewPrompt.Status.Conditions, metav1.Condition{
+					Type:    reviewv1alpha1.ConditionValid,
+					Status:  metav1.ConditionFalse,
+					Reason:  "PreviewFailed",
+					Message: err.Error(),
+				})
+			} else {
+				reviewPrompt.Status.Preview = &reviewv1alpha1.RenderedPrompt{System: system, User: user}
+			}
+		}
+	}
+
+	if err := r.Status().Update(ctx, &reviewPrompt); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	logger.Info("validated review prompt", "valid", meta.IsStatusConditionTrue(reviewPrompt.Status.Conditions, reviewv1alpha1.ConditionValid))
+	return ctrl.Result{}, nil
+}
+
+// reviewPrompt returns the templates of the ReviewPrompt a review selects,
+// or nil if it selects none
+func (r *CodeReviewReconciler) reviewPrompt(ctx context.Context, review *reviewv1alpha1.CodeReview) (*prompt.Template, error) {
+	if review.Spec.Prompt == "" {
+		return nil, nil
+	}
+
+	return r.namedPrompt(ctx, review.Namespace, review.Spec.Prompt)
+}
+
+// namedPrompt fetches and validates a ReviewPrompt of a namespace
+func (r *CodeReviewReconciler) namedPrompt(ctx context.Context, namespace, name string) (*prompt.Template, error) {
+	var reviewPrompt reviewv1alpha1.ReviewPrompt
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &reviewPrompt); err != nil {
+		return nil, fmt.Errorf("error getting review prompt %s: %w", name, err)
+	}
+	template := promptTemplate(&reviewPrompt)
+	if err := template.Validate(); err != nil {
+		return nil, fmt.Errorf("review prompt %s is invalid: %w", name, err)
+	}
+
+	return template, nil
+}
+
+// promptTemplate converts a ReviewPrompt to prompt templates
+func promptTemplate(reviewPrompt *reviewv1alpha1.ReviewPrompt) *prompt.Template {
+	return &prompt.Template{
+		System: reviewPrompt.Spec.System,
+		User:   reviewPrompt.Spec.User,
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ReviewPromptReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&reviewv1alpha1.ReviewPrompt{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
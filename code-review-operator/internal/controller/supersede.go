@@ -0,0 +1,149 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+)
+
+// errSuperseded is the cancellation cause of reviews superseded by a newer push
+var errSuperseded = errors.New("review superseded by a newer commit")
+
+// track registers the cancel function of a running review
+func (r *CodeReviewReconciler) track(name types.NamespacedName, cancel context.CancelCauseFunc) {
+	r.inflightMu.Lock()
+	defer r.inflightMu.Unlock()
+
+	if r.inflight == nil {
+		r.inflight = make(map[types.NamespacedName]context.CancelCauseFunc)
+	}
+	r.inflight[name] = cancel
+}
+
+// untrack removes a review from the running reviews
+func (r *CodeReviewReconciler) untrack(name types.NamespacedName) {
+	r.inflightMu.Lock()
+	defer r.inflightMu.Unlock()
+
+	delete(r.inflight, name)
+}
+
+// cancelInflight cancels a running review with a cause, if there is one
+func (r *CodeReviewReconciler) cancelInflight(name types.NamespacedName, cause error) {
+	r.inflightMu.Lock()
+	defer r.inflightMu.Unlock()
+
+	if cancel, ok := r.inflight[name]; ok {
+		cancel(cause)
+	}
+}
+
+// supersede resolves unfinished reviews of the same pull request at different
+// commits. Older reviews are cancelled and marked superseded by this one; if a
+// newer review exists, this review is marked superseded instead and true is returned.
+func (r *CodeReviewReconciler) supersede(ctx context.Context, review *reviewv1alpha1.CodeReview) (bool, error) {
+	if review.Spec.PullRequest == 0 {
+		return false, nil
+	}
+
+	var reviews reviewv1alpha1.CodeReviewList
+	if err := r.List(ctx, &reviews, client.InNamespace(review.Namespace)); err != nil {
+		return false, fmt.Errorf("error listing reviews: %w", err)
+	}
+
+	var older []*reviewv1alpha1.CodeReview
+	for i := range reviews.Items {
+		other := &reviews.Items[i]
+		if other.Name == review.Name || !samePullRequest(review, other) || isFinished(other) {
+			continue
+		}
+		if other.Spec.CommitSHA == review.Spec.CommitSHA {
+			continue
+		}
+
+		if newer(other, review) {
+			return true, r.markSuperseded(ctx, review, other.Name)
+		}
+		older = append(older, other)
+	}
+
+	for _, other := range older {
+		r.cancelInflight(client.ObjectKeyFromObject(other), errSuperseded)
+		if err := r.markSuperseded(ctx, other, review.Name); err != nil {
+			return false, err
+		}
+	}
+
+	return false, nil
+}
+
+// markSuperseded records that a review was superseded by another
+func (r *CodeReviewReconciler) markSuperseded(ctx context.Context, review *reviewv1alpha1.CodeReview, by string) error {
+	r.Recorder.Eventf(review, corev1.EventTypeNormal, "Superseded", "superseded by %s", by)
+
+	now := metav1.Now()
+	review.Status.Phase = reviewv1alpha1.CodeReviewPhaseSuperseded
+	review.Status.SupersededBy = by
+	review.Status.CompletionTime = &now
+	if err := r.Status().Update(ctx, review); err != nil {
+		return err
+	}
+	recordFinished(review)
+
+	return nil
+}
+
+// samePullRequest reports whether two reviews target the same pull request
+func samePullRequest(a, b *reviewv1alpha1.CodeReview) bool {
+	return a.Spec.Provider == b.Spec.Provider &&
+		a.Spec.Owner == b.Spec.Owner &&
+		a.Spec.Repository == b.Spec.Repository &&
+		a.Spec.PullRequest == b.Spec.PullRequest
+}
+
+// isFinished reports whether a review has reached a terminal phase
+func isFinished(review *reviewv1alpha1.CodeReview) bool {
+	switch review.Status.Phase {
+	case reviewv1alpha1.CodeReviewPhaseCompleted,
+		reviewv1alpha1.CodeReviewPhaseFailed,
+		reviewv1alpha1.CodeReviewPhaseSuperseded,
+		reviewv1alpha1.CodeReviewPhaseTimedOut,
+		reviewv1alpha1.CodeReviewPhaseSkipped,
+		reviewv1alpha1.CodeReviewPhaseCancelled:
+		return true
+	}
+
+	return false
+}
+
+// newer reports whether review a was created after review b
+func newer(a, b *reviewv1alpha1.CodeReview) bool {
+	if a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.Name > b.Name
+	}
+
+	return b.CreationTimestamp.Before(&a.CreationTimestamp)
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	reviewv1alpha1 "github.com/Shridhar2104/code-review-operator/api/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups=review.code-review.io,resources=reviewerconfigs,verbs=get;list;watch
+
+// reviewerConfig returns the ReviewerConfig of a review: the one its spec
+// names, which must exist, or else its namespace's default one, or nil if
+// there is none. With tenant isolation every review needs one.
+func (r *CodeReviewReconciler) reviewerConfig(ctx context.Context, review *reviewv1alpha1.CodeReview) (*reviewv1alpha1.ReviewerConfig, error) {
+	name := review.Spec.ReviewerConfig
+	if name == "" {
+		name = reviewv1alpha1.DefaultReviewerConfig
+	}
+
+	var config reviewv1alpha1.ReviewerConfig
+	err := r.Get(ctx, types.NamespacedName{Namespace: review.Namespace, Name: name}, &config)
+	switch {
+	case apierrors.IsNotFound(err) && review.Spec.ReviewerConfig == "" && !r.TenantIsolation:
+		return nil, nil
+	case apierrors.IsNotFound(err):
+		return nil, fmt.Errorf("reviewer config %s not found in namespace %s", name, review.Namespace)
+	case err != nil:
+		return nil, fmt.Errorf("error getting reviewer config %s: %w", name, err)
+	}
+
+	return &config, nil
+}
+
+// checkRepository returns an error if a ReviewerConfig does not allow its
+// reviews to review the review's repository
+func checkRepository(config *reviewv1alpha1.ReviewerConfig, review *reviewv1alpha1.CodeReview) error {
+	if allowsRepository(config, review.Spec.Owner, review.Spec.Repository) {
+		return nil
+	}
+
+	return fmt.Errorf("repository %s/%s is not among the repositories of reviewer config %s",
+		review.Spec.Owner, review.Spec.Repository, config.Name)
+}
+
+// allowsRepository reports whether a ReviewerConfig allows its reviews to
+// read owner/repository. Every repository is allowed without a
+// ReviewerConfig or when it lists no repositories.
+func allowsRepository(config *reviewv1alpha1.ReviewerConfig, owner, repository string) bool {
+	if config == nil || len(config.Spec.Repositories) == 0 {
+		return true
+	}
+
+	name := strings.ToLower(owner + "/" + repository)
+	for _, pattern := range config.Spec.Repositories {
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// repositoryFilter returns the repositories other than its own a review may
+// read, such as those of related pull requests: those its ReviewerConfig
+// allows, so one team's token never reads another team's pull requests
+func (r *CodeReviewReconciler) repositoryFilter(ctx context.Context, review *reviewv1alpha1.CodeReview) (func(owner, repository string) bool, error) {
+	config, err := r.reviewerConfig(ctx, review)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(owner, repository string) bool {
+		return allowsRepository(config, owner, repository)
+	}, nil
+}
+
+// tokenSecretRef returns the Secret key holding the provider credentials of
+// a review: its own, or else its ReviewerConfig's. The repository must be one
+// the ReviewerConfig allows, so a team's token is never used for another
+// team's repositories.
+func (r *CodeReviewReconciler) tokenSecretRef(ctx context.Context, review *reviewv1alpha1.CodeReview) (reviewv1alpha1.TokenSecretReference, error) {
+	config, err := r.reviewerConfig(ctx, review)
+	if err != nil {
+		return reviewv1alpha1.TokenSecretReference{}, err
+	}
+	if err := checkRepository(config, review); err != nil {
+		return reviewv1alpha1.TokenSecretReference{}, err
+	}
+
+	switch {
+	case review.Spec.TokenSecretRef != nil:
+		return *review.Spec.TokenSecretRef, nil
+	case config != nil && config.Spec.TokenSecretRef != nil:
+		return *config.Spec.TokenSecretRef, nil
+	}
+
+	return reviewv1alpha1.TokenSecretReference{}, fmt.Errorf("review has no tokenSecretRef and no reviewer config supplies one")
+}
+
+// tenantLLM returns the LLM backend a review's ReviewerConfig selects, or nil
+// if it selects none
+func (r *CodeReviewReconciler) tenantLLM(ctx context.Context, review *reviewv1alpha1.CodeReview) (*reviewv1alpha1.LLMSpec, error) {
+	config, err := r.reviewerConfig(ctx, review)
+	if err != nil || config == nil {
+		return nil, err
+	}
+
+	return config.Spec.LLM, nil
+}
+
+// notifications returns the notification sinks of a review: its own, then
+// those of its ReviewerConfig
+func (r *CodeReviewReconciler) notifications(ctx context.Context, review *reviewv1alpha1.CodeReview) ([]reviewv1alpha1.NotificationSpec, error) {
+	config, err := r.reviewerConfig(ctx, review)
+	if err != nil || config == nil {
+		return review.Spec.Notifications, err
+	}
+
+	return append(append([]reviewv1alpha1.NotificationSpec(nil), review.Spec.Notifications...), config.Spec.Notifications...), nil
+}
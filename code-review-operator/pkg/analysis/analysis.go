@@ -0,0 +1,103 @@
+// Package analysis runs deterministic analyzers on the changed lines of a
+// diff: formatting and vet checks of Go code and untracked TODO comments.
+// Analyzers are cheap and spend no tokens, so they run alongside the LLM
+// review and contribute their own review comments. The package also finds
+// leaked credentials, which the review pipeline scans for before anything
+// is sent to the LLM.
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+)
+
+// Analyzer is a deterministic checker of a diff
+type Analyzer interface {
+	// Name identifies the analyzer in configuration
+	Name() string
+
+	// Analyze returns findings on the added lines of the diff
+	Analyze(ctx context.Context, input *Input) ([]git.ReviewComment, error)
+}
+
+// Input is what analyzers inspect
+type Input struct {
+	// Files are the files of the diff
+	Files []*diff.File
+
+	// Content returns the content of a file at the reviewed commit. It is
+	// nil when file contents are not available.
+	Content func(ctx context.Context, path string) ([]byte, error)
+
+	// Dir is the root of a local checkout of the reviewed commit, empty when
+	// the review does not run on one. Analyzers running external tools need it.
+	Dir string
+}
+
+// Defaults returns the built-in analyzers
+func Defaults() []Analyzer {
+	return []Analyzer{Gofmt{}, Vet{}, Todos{}}
+}
+
+// Names returns the names of the built-in analyzers
+func Names() []string {
+	analyzers := Defaults()
+	names := make([]string, 0, len(analyzers))
+	for _, analyzer := range analyzers {
+		names = append(names, analyzer.Name())
+	}
+
+	return names
+}
+
+// Run runs the enabled analyzers concurrently and returns their findings in
+// the order of analyzers. Analyzers are enabled unless enabled maps their
+// name to false. The findings of analyzers that succeeded are returned even
+// when others fail, along with the failures.
+func Run(ctx context.Context, analyzers []Analyzer, enabled map[string]bool, input *Input) ([]git.ReviewComment, error) {
+	findings := make([][]git.ReviewComment, len(analyzers))
+	errs := make([]error, len(analyzers))
+
+	var wg sync.WaitGroup
+	for i, analyzer := range analyzers {
+		if on, ok := enabled[analyzer.Name()]; ok && !on {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			comments, err := analyzer.Analyze(ctx, input)
+			if err != nil {
+				errs[i] = fmt.Errorf("analyzer %s: %w", analyzer.Name(), err)
+			}
+			findings[i] = comments
+		}()
+	}
+	wg.Wait()
+
+	var comments []git.ReviewComment
+	for _, found := range findings {
+		comments = append(comments, found...)
+	}
+
+	return comments, errors.Join(errs...)
+}
+
+// addedLines returns the added lines of a file by line number in the new version
+func addedLines(file *diff.File) map[int]string {
+	added := make(map[int]string)
+	for _, hunk := range file.Hunks {
+		for _, line := range hunk.Lines {
+			if line.Kind == diff.LineAdded {
+				added[line.NewNumber] = line.Content
+			}
+		}
+	}
+
+	return added
+}
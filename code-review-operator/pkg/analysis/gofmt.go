@@ -0,0 +1,90 @@
+package analysis
+
+import (
+	"context"
+	"go/format"
+	"path"
+	"strings"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/policy"
+)
+
+// RuleGofmt flags changed Go files that are not formatted with gofmt
+const RuleGofmt = "gofmt/unformatted"
+
+// Gofmt reports changed Go files whose added lines are not formatted as
+// gofmt would format them. It needs file contents.
+type Gofmt struct{}
+
+// Name returns the analyzer's name
+func (Gofmt) Name() string { return "gofmt" }
+
+// Analyze formats each changed Go file and reports, once per file, the first
+// added line within the region gofmt would change. Files that do not parse
+// are skipped: the compiler reports those.
+func (Gofmt) Analyze(ctx context.Context, input *Input) ([]git.ReviewComment, error) {
+	if input.Content == nil {
+		return nil, nil
+	}
+
+	var comments []git.ReviewComment
+	for _, file := range input.Files {
+		if path.Ext(file.NewPath) != ".go" || file.Status == diff.FileDeleted || file.Binary {
+			continue
+		}
+		added := addedLines(file)
+		if len(added) == 0 {
+			continue
+		}
+		data, err := input.Content(ctx, file.NewPath)
+		if err != nil {
+			return comments, err
+		}
+		formatted, err := format.Source(data)
+		if err != nil {
+			continue
+		}
+		first, last, changed := changedRegion(string(data), string(formatted))
+		if !changed {
+			continue
+		}
+		for line := first; line <= last; line++ {
+			if _, ok := added[line]; ok {
+				comments = append(comments, git.ReviewComment{
+					File:     file.NewPath,
+					Line:     line,
+					Content:  "This file is not formatted with gofmt. Run `gofmt -w " + file.NewPath + "`.",
+					Severity: policy.SeverityMinor,
+					Rule:     RuleGofmt,
+				})
+				break
+			}
+		}
+	}
+
+	return comments, nil
+}
+
+// changedRegion returns the range of lines of original, counting from 1,
+// between the first and last lines that differ from formatted
+func changedRegion(original, formatted string) (int, int, bool) {
+	if original == formatted {
+		return 0, 0, false
+	}
+	a := strings.Split(original, "\n")
+	b := strings.Split(formatted, "\n")
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	// A region of only removed lines is attributed to the line after it
+	return prefix + 1, max(len(a)-suffix, prefix+1), true
+}
@@ -0,0 +1,213 @@
+package analysis
+
+import (
+	"math"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+)
+
+// RuleHighEntropySecret flags random-looking strings assigned like credentials
+const RuleHighEntropySecret = "secrets/high-entropy-string"
+
+// Secret is a credential found on the added lines of a diff
+type Secret struct {
+	// File is the path of the file
+	File string
+
+	// Line is the line the credential starts on
+	Line int
+
+	// Rule names the kind of credential, such as secrets/aws-access-key
+	Rule string
+
+	// Description names the credential in findings, which never quote it
+	Description string
+
+	// Values are the credential's text, one entry per line it spans, to be
+	// masked in anything sent outside the cluster
+	Values []string
+}
+
+// secretPattern is a kind of credential recognized by its format
+type secretPattern struct {
+	// rule is the rule findings are reported under
+	rule string
+
+	// description names the credential in findings
+	description string
+
+	// pattern matches the credential
+	pattern *regexp.Regexp
+}
+
+// secretPatterns are the credentials recognized by their format
+var secretPatterns = []secretPattern{
+	{"secrets/aws-access-key", "an AWS access key ID", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"secrets/aws-secret-key", "an AWS secret access key",
+		regexp.MustCompile(`(?i)aws_?secret_?(access_?)?key["']?\s*[:=]\s*["']?[A-Za-z0-9/+]{40}\b`)},
+	{"secrets/gcp-service-account", "a Google Cloud service account key",
+		regexp.MustCompile(`"private_key_id"\s*:\s*"[0-9a-f]{40}"`)},
+	{"secrets/github-token", "a GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{60,})\b`)},
+	{"secrets/gitlab-token", "a GitLab token", regexp.MustCompile(`\bglpat-[A-Za-z0-9_\-]{20,}\b`)},
+	{"secrets/slack-token", "a Slack token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`)},
+	{"secrets/google-api-key", "a Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{"secrets/stripe-key", "a Stripe secret key", regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{24,}\b`)},
+	{"secrets/jwt", "a JSON Web Token", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{"secrets/hardcoded-credential", "a hardcoded credential",
+		regexp.MustCompile(`(?i)\b(api_?key|secret|token|passw(or)?d|access_?key)["']?\s*[:=]\s*["'][^"'\s$]{8,}["']`)},
+}
+
+// privateKeyBegin and privateKeyEnd delimit PEM-encoded private keys
+var (
+	privateKeyBegin = regexp.MustCompile(`-----BEGIN ((RSA|EC|DSA|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY( BLOCK)?-----`)
+	privateKeyEnd   = regexp.MustCompile(`-----END ((RSA|EC|DSA|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY( BLOCK)?-----`)
+)
+
+// secretValue matches quoted strings and assigned values that may be random tokens
+var secretValue = regexp.MustCompile(`["'\x60]([A-Za-z0-9+/=_\-.]{20,})["'\x60]|[:=]\s*([A-Za-z0-9+/=_\-.]{20,})\b`)
+
+// secretName matches names of variables holding secrets
+var secretName = regexp.MustCompile(`(?i)(passw(or)?d|secret|token|api_?key|private_?key|access_?key|credential|auth)`)
+
+// hexString matches hexadecimal strings, such as hashes
+var hexString = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+
+// lockfiles hold checksums that look random but are not secrets
+var lockfiles = []string{"go.sum", "package-lock.json", "yarn.lock", "pnpm-lock.yaml", "Cargo.lock",
+	"Gemfile.lock", "poetry.lock", "composer.lock", "*.lock"}
+
+// FindSecrets returns the credentials on the added lines of files: known
+// formats such as cloud access keys, service account keys, private keys and
+// API tokens, and high-entropy strings assigned like credentials. Each line
+// is reported once.
+func FindSecrets(files []*diff.File) []Secret {
+	var secrets []Secret
+	for _, file := range files {
+		if file.Binary || isLockfile(file.NewPath) {
+			continue
+		}
+		for _, hunk := range file.Hunks {
+			var key *Secret
+			for _, line := range hunk.Lines {
+				if line.Kind != diff.LineAdded {
+					continue
+				}
+				content := line.Content
+
+				// Private keys span several lines, all of which are masked
+				if key != nil {
+					key.Values = append(key.Values, strings.TrimSpace(content))
+					if privateKeyEnd.MatchString(content) {
+						secrets = append(secrets, *key)
+						key = nil
+					}
+					continue
+				}
+				if match := privateKeyBegin.FindString(content); match != "" {
+					key = &Secret{File: file.NewPath, Line: line.NewNumber, Rule: "secrets/private-key",
+						Description: "a private key", Values: []string{strings.TrimSpace(content)}}
+					if privateKeyEnd.MatchString(content) {
+						secrets = append(secrets, *key)
+						key = nil
+					}
+					continue
+				}
+
+				if secret, ok := matchSecret(content); ok {
+					secret.File, secret.Line = file.NewPath, line.NewNumber
+					secrets = append(secrets, secret)
+				}
+			}
+			if key != nil {
+				secrets = append(secrets, *key)
+			}
+		}
+	}
+
+	return secrets
+}
+
+// matchSecret finds a credential on a line by its format or its entropy
+func matchSecret(content string) (Secret, bool) {
+	for _, pattern := range secretPatterns {
+		if match := pattern.pattern.FindString(content); match != "" {
+			return Secret{Rule: pattern.rule, Description: pattern.description, Values: []string{match}}, true
+		}
+	}
+
+	for _, match := range secretValue.FindAllStringSubmatch(content, -1) {
+		value := match[1] + match[2]
+		if highEntropy(value, secretName.MatchString(content)) {
+			return Secret{Rule: RuleHighEntropySecret, Description: "a random-looking credential",
+				Values: []string{value}}, true
+		}
+	}
+
+	return Secret{}, false
+}
+
+// highEntropy reports whether a string looks like a random token. Hex strings
+// are often hashes and commit IDs, so they count only when the line names a
+// secret.
+func highEntropy(value string, named bool) bool {
+	if strings.Count(value, "/") > 2 {
+		return false
+	}
+	if hexString.MatchString(value) {
+		return named && len(value) >= 32 && entropy(value) >= 3.5
+	}
+
+	var upper, lower, digit bool
+	for _, r := range value {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			digit = true
+		}
+	}
+	if !upper || !lower || !digit {
+		return false
+	}
+
+	threshold := 4.5
+	if named {
+		threshold = 4.0
+	}
+
+	return entropy(value) >= threshold
+}
+
+// entropy returns the Shannon entropy of a string in bits per character
+func entropy(value string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range value {
+		counts[r]++
+	}
+
+	var bits float64
+	n := float64(len(value))
+	for _, count := range counts {
+		p := float64(count) / n
+		bits -= p * math.Log2(p)
+	}
+
+	return bits
+}
+
+// isLockfile reports whether a path is a dependency lockfile
+func isLockfile(filePath string) bool {
+	name := path.Base(filePath)
+	for _, pattern := range lockfiles {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,54 @@
+package analysis
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/policy"
+)
+
+// RuleUntrackedTodo flags TODO and FIXME comments that reference no issue
+const RuleUntrackedTodo = "todo/untracked"
+
+// todoMarker matches a TODO-style marker in a comment
+var todoMarker = regexp.MustCompile(`(//|#|/\*|<!--|--|;)\s*.*\b(TODO|FIXME|XXX|HACK)\b`)
+
+// issueReference matches references to a tracked issue: #123, PROJ-123 or a URL
+var issueReference = regexp.MustCompile(`#\d+|\b[A-Z][A-Z0-9]+-\d+\b|https?://`)
+
+// Todos reports TODO, FIXME, XXX and HACK comments added without a
+// reference to an issue tracking the work
+type Todos struct{}
+
+// Name returns the analyzer's name
+func (Todos) Name() string { return "todo" }
+
+// Analyze checks the comments of added lines
+func (Todos) Analyze(ctx context.Context, input *Input) ([]git.ReviewComment, error) {
+	var comments []git.ReviewComment
+	for _, file := range input.Files {
+		for _, hunk := range file.Hunks {
+			for _, line := range hunk.Lines {
+				if line.Kind != diff.LineAdded {
+					continue
+				}
+				match := todoMarker.FindStringSubmatch(line.Content)
+				if match == nil || issueReference.MatchString(line.Content) {
+					continue
+				}
+				comments = append(comments, git.ReviewComment{
+					File: file.NewPath,
+					Line: line.NewNumber,
+					Content: "New " + match[2] + " without a linked issue. Open an issue for the follow-up work " +
+						"and reference it here so it is not forgotten.",
+					Severity: policy.SeveritySuggestion,
+					Rule:     RuleUntrackedTodo,
+				})
+			}
+		}
+	}
+
+	return comments, nil
+}
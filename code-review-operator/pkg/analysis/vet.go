@@ -0,0 +1,131 @@
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/policy"
+)
+
+// RuleVet flags problems reported by go vet
+const RuleVet = "go-vet"
+
+// vetDiagnostic matches a diagnostic line of go vet: file:line[:column]: message
+var vetDiagnostic = regexp.MustCompile(`^(?:vet: )?([^\s:][^:]*\.go):(\d+)(?::\d+)?: (.+)$`)
+
+// Diagnostic is a problem reported by a tool at a line of a file
+type Diagnostic struct {
+	// File is the path of the file, relative to the directory the tool ran in
+	File string
+
+	// Line is the line of the problem
+	Line int
+
+	// Message describes the problem
+	Message string
+}
+
+// ParseVetOutput parses the output of go vet, skipping package headers and
+// other lines that are not diagnostics. Paths are made relative to dir.
+func ParseVetOutput(output, dir string) []Diagnostic {
+	var diagnostics []Diagnostic
+	for _, line := range strings.Split(output, "\n") {
+		match := vetDiagnostic.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if match == nil {
+			continue
+		}
+		file := match[1]
+		if filepath.IsAbs(file) && dir != "" {
+			if rel, err := filepath.Rel(dir, file); err == nil {
+				file = rel
+			}
+		}
+		number, _ := strconv.Atoi(match[2])
+		diagnostics = append(diagnostics, Diagnostic{
+			File:    strings.TrimPrefix(filepath.ToSlash(file), "./"),
+			Line:    number,
+			Message: match[3],
+		})
+	}
+
+	return diagnostics
+}
+
+// Vet runs go vet on the packages of changed Go files and reports the
+// problems on added lines. It needs a local checkout and the go command, and
+// does nothing otherwise.
+type Vet struct{}
+
+// Name returns the analyzer's name
+func (Vet) Name() string { return "vet" }
+
+// Analyze runs go vet in the checkout
+func (Vet) Analyze(ctx context.Context, input *Input) ([]git.ReviewComment, error) {
+	if input.Dir == "" {
+		return nil, nil
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		return nil, nil
+	}
+
+	added := make(map[string]map[int]string)
+	packages := make(map[string]bool)
+	for _, file := range input.Files {
+		if path.Ext(file.NewPath) != ".go" || file.Status == diff.FileDeleted {
+			continue
+		}
+		added[file.NewPath] = addedLines(file)
+		pkg := path.Dir(file.NewPath)
+		if pkg != "." {
+			pkg = "./" + pkg
+		}
+		packages[pkg] = true
+	}
+	if len(packages) == 0 {
+		return nil, nil
+	}
+	args := []string{"vet"}
+	for pkg := range packages {
+		args = append(args, pkg)
+	}
+	sort.Strings(args[1:])
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = input.Dir
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// go vet exits with an error when it reports problems
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, err
+		}
+	}
+
+	var comments []git.ReviewComment
+	for _, diagnostic := range ParseVetOutput(output.String(), input.Dir) {
+		if _, ok := added[diagnostic.File][diagnostic.Line]; !ok {
+			continue
+		}
+		comments = append(comments, git.ReviewComment{
+			File:     diagnostic.File,
+			Line:     diagnostic.Line,
+			Content:  "go vet: " + diagnostic.Message,
+			Severity: policy.SeverityMajor,
+			Rule:     RuleVet,
+		})
+	}
+
+	return comments, nil
+}
@@ -0,0 +1,168 @@
+package cloudevents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	neturl "net/url"
+	"time"
+)
+
+// SpecVersion is the version of the CloudEvents specification events follow
+const SpecVersion = "1.0"
+
+// ContentType is the media type of events in structured mode
+const ContentType = "application/cloudevents+json"
+
+// Types of the events emitted over a review's lifecycle
+const (
+	// TypeReviewStarted is emitted when a review starts running
+	TypeReviewStarted = "review.started"
+
+	// TypeReviewCompleted is emitted when a review was posted
+	TypeReviewCompleted = "review.completed"
+
+	// TypeReviewFailed is emitted when a review failed or ran out of time
+	TypeReviewFailed = "review.failed"
+
+	// TypeFindingCritical is emitted for every critical finding of a posted review
+	TypeFindingCritical = "finding.critical"
+)
+
+// Event is a CloudEvent with JSON data
+type Event struct {
+	// ID identifies the event within its source. Deliveries of the same
+	// event share it, so consumers can drop duplicates.
+	ID string `json:"id"`
+
+	// Source identifies the CodeReview the event is about
+	Source string `json:"source"`
+
+	// SpecVersion is the CloudEvents version, SpecVersion
+	SpecVersion string `json:"specversion"`
+
+	// Type is one of the event types above
+	Type string `json:"type"`
+
+	// Subject names the pull request, as owner/repo#number
+	Subject string `json:"subject,omitempty"`
+
+	// Time is when the event happened
+	Time time.Time `json:"time"`
+
+	// DataContentType is the media type of Data, always application/json
+	DataContentType string `json:"datacontenttype"`
+
+	// Data is ReviewData or FindingData
+	Data interface{} `json:"data"`
+}
+
+// New creates an event of a type with data
+func New(id, source, eventType, subject string, data interface{}) *Event {
+	return &Event{
+		ID:              id,
+		Source:          source,
+		SpecVersion:     SpecVersion,
+		Type:            eventType,
+		Subject:         subject,
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		Data:            data,
+	}
+}
+
+// ReviewData is the data of review events
+type ReviewData struct {
+	// Namespace and Name identify the CodeReview
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+
+	// Provider, Owner, Repository and PullRequest identify the reviewed pull request
+	Provider    string `json:"provider"`
+	Owner       string `json:"owner"`
+	Repository  string `json:"repository"`
+	PullRequest int    `json:"pullRequest,omitempty"`
+
+	// CommitSHA is the reviewed head commit
+	CommitSHA string `json:"commitSHA,omitempty"`
+
+	// URL is the URL of the posted review
+	URL string `json:"url,omitempty"`
+
+	// Decision is the event the review was posted with
+	Decision string `json:"decision,omitempty"`
+
+	// Severities counts the findings by severity
+	Severities map[string]int `json:"severities,omitempty"`
+
+	// Reason and Message explain why a review failed
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// FindingData is the data of finding events
+type FindingData struct {
+	// Namespace and Name identify the CodeReview that reported the finding
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+
+	// Owner, Repository, PullRequest and CommitSHA identify the reviewed change
+	Owner       string `json:"owner"`
+	Repository  string `json:"repository"`
+	PullRequest int    `json:"pullRequest,omitempty"`
+	CommitSHA   string `json:"commitSHA,omitempty"`
+
+	// File and Line locate the finding
+	File string `json:"file"`
+	Line int    `json:"line"`
+
+	// Severity and Rule classify the finding
+	Severity string `json:"severity"`
+	Rule     string `json:"rule,omitempty"`
+
+	// Language is the language of the file, such as go or terraform
+	Language string `json:"language,omitempty"`
+
+	// Message is the text of the comment
+	Message string `json:"message"`
+
+	// Link is a permalink to the commented lines in the pull request's diff
+	Link string `json:"link,omitempty"`
+}
+
+// Sink delivers events to downstream consumers
+type Sink interface {
+	// Send delivers an event
+	Send(ctx context.Context, event *Event) error
+}
+
+// NewSink creates the sink for a URL: http:// and https:// URLs receive
+// events by HTTP, nats:// and tls:// URLs publish them to a NATS server
+func NewSink(rawURL string) (Sink, error) {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing CloudEvents sink URL: %w", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("CloudEvents sink URL %q has no host", rawURL)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return NewHTTPSink(rawURL), nil
+	case "nats", "tls":
+		return NewNATSSink(u), nil
+	default:
+		return nil, fmt.Errorf("unsupported CloudEvents sink scheme %q: use http, https, nats or tls", u.Scheme)
+	}
+}
+
+// encode renders an event in structured mode
+func encode(event *Event) ([]byte, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling event: %w", err)
+	}
+
+	return data, nil
+}
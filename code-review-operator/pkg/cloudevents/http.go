@@ -0,0 +1,54 @@
+package cloudevents
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/httpx"
+)
+
+// HTTPSink posts events in structured mode to an HTTP endpoint, such as a
+// Knative broker
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSink creates an HTTPSink posting to url
+func NewHTTPSink(url string) *HTTPSink {
+	return &HTTPSink{
+		url:    url,
+		client: httpx.NewClient(10 * time.Second),
+	}
+}
+
+// Send implements Sink. Responses other than 2xx are errors.
+func (s *HTTPSink) Send(ctx context.Context, event *Event) error {
+	body, err := encode(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", ContentType)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("error from CloudEvents sink: %s (status code: %d)", string(message), resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package cloudevents
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultNATSPort is the port NATS servers listen on
+	DefaultNATSPort = "4222"
+
+	// DefaultNATSSubject is the subject events are published under when the
+	// sink URL has no path
+	DefaultNATSSubject = "codereview"
+
+	// natsTimeout bounds publishing one event
+	natsTimeout = 10 * time.Second
+)
+
+// NATSSink publishes events in structured mode to a NATS server, under the
+// subject of the URL's path followed by the event type, such as
+// codereview.review.completed. It speaks the core NATS protocol over a
+// connection per event, as reviews emit few events. Credentials are read
+// from the URL: a user and password, or a token as the user.
+type NATSSink struct {
+	addr    string
+	tls     bool
+	host    string
+	subject string
+
+	user     string
+	password string
+	token    string
+}
+
+// NewNATSSink creates a NATSSink for a nats:// or tls:// URL
+func NewNATSSink(u *neturl.URL) *NATSSink {
+	sink := &NATSSink{
+		addr:    u.Host,
+		tls:     u.Scheme == "tls",
+		host:    u.Hostname(),
+		subject: strings.ReplaceAll(strings.Trim(u.Path, "/"), "/", "."),
+	}
+	if u.Port() == "" {
+		sink.addr = net.JoinHostPort(u.Hostname(), DefaultNATSPort)
+	}
+	if sink.subject == "" {
+		sink.subject = DefaultNATSSubject
+	}
+	if u.User != nil {
+		if password, ok := u.User.Password(); ok {
+			sink.user, sink.password = u.User.Username(), password
+		} else {
+			sink.token = u.User.Username()
+		}
+	}
+
+	return sink
+}
+
+// Subject returns the subject an event of a type is published under
+func (s *NATSSink) Subject(eventType string) string {
+	return s.subject + "." + eventType
+}
+
+// natsInfo is the part of a NATS server's INFO message the sink reads
+type natsInfo struct {
+	TLSRequired bool `json:"tls_required"`
+}
+
+// natsConnect is the CONNECT message the sink sends
+type natsConnect struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Name     string `json:"name"`
+	Lang     string `json:"lang"`
+	Version  string `json:"version"`
+	User     string `json:"user,omitempty"`
+	Password string `json:"pass,omitempty"`
+	Token    string `json:"auth_token,omitempty"`
+}
+
+// Send implements Sink. The event is published, then a PING is answered
+// once the server has processed it, reporting errors such as rejected
+// credentials or subjects.
+func (s *NATSSink) Send(ctx context.Context, event *Event) error {
+	payload, err := encode(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, natsTimeout)
+	defer cancel()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("error connecting to NATS: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("error reading NATS server info: %w", err)
+	}
+	infoJSON, ok := strings.CutPrefix(strings.TrimSpace(line), "INFO ")
+	if !ok {
+		return fmt.Errorf("unexpected NATS server greeting: %q", strings.TrimSpace(line))
+	}
+	var info natsInfo
+	if err := json.Unmarshal([]byte(infoJSON), &info); err != nil {
+		return fmt.Errorf("error parsing NATS server info: %w", err)
+	}
+	if s.tls || info.TLSRequired {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: s.host, MinVersion: tls.VersionTLS12})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return fmt.Errorf("error negotiating TLS with NATS: %w", err)
+		}
+		conn = tlsConn
+		reader = bufio.NewReader(conn)
+	}
+
+	connect, err := json.Marshal(natsConnect{
+		Name:     "code-review-operator",
+		Lang:     "go",
+		Version:  "1.0.0",
+		User:     s.user,
+		Password: s.password,
+		Token:    s.token,
+	})
+	if err != nil {
+		return fmt.Errorf("error marshaling NATS connect: %w", err)
+	}
+	message := fmt.Sprintf("CONNECT %s\r\nPUB %s %d\r\n%s\r\nPING\r\n", connect, s.Subject(event.Type), len(payload), payload)
+	if _, err := conn.Write([]byte(message)); err != nil {
+		return fmt.Errorf("error publishing to NATS: %w", err)
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("error reading NATS reply: %w", err)
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("error from NATS: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+		// +OK, INFO updates and PINGs of the server need no answer here
+	}
+}
@@ -0,0 +1,133 @@
+package compliance
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"slices"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+)
+
+// MinTLSVersion is the lowest TLS version allowed in compliance mode
+const MinTLSVersion = tls.VersionTLS12
+
+// CipherSuites are the FIPS 140 approved TLS 1.2 cipher suites allowed in
+// compliance mode. Go does not allow TLS 1.3 suites to be configured; builds
+// with a FIPS validated module restrict those as well.
+var CipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+}
+
+// CurvePreferences are the FIPS 140 approved key exchange curves
+var CurvePreferences = []tls.CurveID{tls.CurveP256, tls.CurveP384}
+
+// DefaultAllowedLLMProviders are the LLM backends allowed when none are
+// configured: cloud platforms offering FIPS validated endpoints
+var DefaultAllowedLLMProviders = []string{"vertex", "bedrock", "azure-openai"}
+
+// ErrLLMNotApproved is returned for LLM backends not allowed in compliance mode
+var ErrLLMNotApproved = errors.New("LLM backend is not approved in compliance mode")
+
+// Mode restricts the operator to approved cryptography and LLM backends for
+// regulated environments
+type Mode struct {
+	// AllowedLLMProviders are the LLM backends reviews may use
+	// (defaults to DefaultAllowedLLMProviders)
+	AllowedLLMProviders []string
+}
+
+// ConfigureTLS restricts a TLS configuration to TLS 1.2 or later with approved
+// cipher suites and curves. It can be used as a TLS option of the webhook and
+// metrics servers.
+func (m *Mode) ConfigureTLS(c *tls.Config) {
+	if c.MinVersion < MinTLSVersion {
+		c.MinVersion = MinTLSVersion
+	}
+	c.CipherSuites = CipherSuites
+	c.CurvePreferences = CurvePreferences
+}
+
+// EnforceOutbound applies the TLS restrictions to the default HTTP transport,
+// which the Git, LLM and notification clients use
+func (m *Mode) EnforceOutbound() error {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("default HTTP transport is a %T, not an *http.Transport", http.DefaultTransport)
+	}
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	m.ConfigureTLS(transport.TLSClientConfig)
+
+	return nil
+}
+
+// CheckLLM returns an error unless the LLM backend is approved and, if it
+// connects to the configured endpoint, the endpoint uses TLS. Running without
+// an LLM is always allowed, as no code leaves the cluster.
+func (m *Mode) CheckLLM(provider, endpoint string) error {
+	if provider == llm.ProviderNone {
+		return nil
+	}
+	if !slices.Contains(m.allowedLLMProviders(), provider) {
+		return fmt.Errorf("%w: %s", ErrLLMNotApproved, provider)
+	}
+	if !llm.UsesEndpoint(provider) || endpoint == "" {
+		return nil
+	}
+
+	u, err := neturl.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("error parsing LLM endpoint: %w", err)
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("%w: endpoint %s does not use TLS", ErrLLMNotApproved, endpoint)
+	}
+
+	return nil
+}
+
+// Attestation describes the active compliance settings
+type Attestation struct {
+	// MinTLSVersion is the lowest TLS version allowed
+	MinTLSVersion string
+
+	// CipherSuites are the TLS 1.2 cipher suites allowed
+	CipherSuites []string
+
+	// FIPSModule reports whether the operator was built with a FIPS 140 validated crypto module
+	FIPSModule bool
+
+	// AllowedLLMProviders are the LLM backends reviews may use
+	AllowedLLMProviders []string
+}
+
+// Attest returns the active compliance settings
+func (m *Mode) Attest() Attestation {
+	suites := make([]string, 0, len(CipherSuites))
+	for _, id := range CipherSuites {
+		suites = append(suites, tls.CipherSuiteName(id))
+	}
+
+	return Attestation{
+		MinTLSVersion:       tls.VersionName(MinTLSVersion),
+		CipherSuites:        suites,
+		FIPSModule:          fipsModule(),
+		AllowedLLMProviders: m.allowedLLMProviders(),
+	}
+}
+
+// allowedLLMProviders returns the configured or default allowed LLM backends
+func (m *Mode) allowedLLMProviders() []string {
+	if len(m.AllowedLLMProviders) == 0 {
+		return DefaultAllowedLLMProviders
+	}
+
+	return m.AllowedLLMProviders
+}
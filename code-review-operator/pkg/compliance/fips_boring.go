@@ -0,0 +1,10 @@
+//go:build boringcrypto
+
+package compliance
+
+import "crypto/boring"
+
+// fipsModule reports whether the BoringCrypto FIPS module is in use
+func fipsModule() bool {
+	return boring.Enabled()
+}
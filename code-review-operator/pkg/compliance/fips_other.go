@@ -0,0 +1,9 @@
+//go:build !boringcrypto
+
+package compliance
+
+// fipsModule reports whether a FIPS validated crypto module is in use. Builds
+// without GOEXPERIMENT=boringcrypto use the standard Go crypto packages.
+func fipsModule() bool {
+	return false
+}
@@ -0,0 +1,90 @@
+// Package contract compares two versions of an API definition, a Protocol
+// Buffers file or an OpenAPI document, and reports the changes that break
+// existing clients: removed elements, changed types and renumbered fields.
+package contract
+
+import (
+	"path"
+	"regexp"
+	"sort"
+)
+
+// Kinds of API definitions
+const (
+	KindProto   = "proto"
+	KindOpenAPI = "openapi"
+)
+
+// openAPIVersion matches the version key at the top of an OpenAPI or Swagger document
+var openAPIVersion = regexp.MustCompile(`(?m)^(?:"?(?:openapi|swagger)"?\s*:|\{\s*"(?:openapi|swagger)"\s*:)`)
+
+// Change is a breaking change between two versions of an API definition
+type Change struct {
+	// Line is the line of the change in the new version, or in the old
+	// version if Removed is set
+	Line int
+
+	// Removed is set for elements that no longer exist in the new version
+	Removed bool
+
+	// Message describes the change
+	Message string
+}
+
+// Candidate reports whether a file may hold an API definition, which for
+// YAML and JSON files is only known from their content
+func Candidate(filePath string) bool {
+	switch path.Ext(filePath) {
+	case ".proto", ".yaml", ".yml", ".json":
+		return true
+	}
+
+	return false
+}
+
+// Kind returns the kind of API definition a file is, or "" if it is none
+func Kind(filePath, content string) string {
+	switch path.Ext(filePath) {
+	case ".proto":
+		return KindProto
+	case ".yaml", ".yml", ".json":
+		if openAPIVersion.MatchString(content) {
+			return KindOpenAPI
+		}
+	}
+
+	return ""
+}
+
+// Compare returns the breaking changes between the old and new versions of
+// an API definition of a kind, ordered by line
+func Compare(kind, oldContent, newContent string) ([]Change, error) {
+	var changes []Change
+	var err error
+	switch kind {
+	case KindProto:
+		changes, err = compareProto(oldContent, newContent)
+	case KindOpenAPI:
+		changes, err = compareOpenAPI(oldContent, newContent)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Line < changes[j].Line
+	})
+
+	return changes, nil
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,315 @@
+package contract
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// httpMethods are the operations of an OpenAPI path item
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// maxSchemaDepth bounds the recursion into nested schemas
+const maxSchemaDepth = 10
+
+// compareOpenAPI returns the breaking changes between two versions of an
+// OpenAPI or Swagger document: removed paths, operations, responses and
+// schemas, new required parameters and properties, changed types and
+// removed properties and enum values. References are compared by name.
+func compareOpenAPI(oldContent, newContent string) ([]Change, error) {
+	oldDoc, err := parseYAML(oldContent)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing old version: %w", err)
+	}
+	newDoc, err := parseYAML(newContent)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing new version: %w", err)
+	}
+
+	c := &openAPIComparer{}
+	c.comparePaths(value(oldDoc, "paths"), value(newDoc, "paths"))
+
+	// OpenAPI 3 keeps schemas in components, Swagger 2 in definitions
+	c.compareSchemaMap("schema", value(value(oldDoc, "components"), "schemas"), value(value(newDoc, "components"), "schemas"))
+	c.compareSchemaMap("schema", value(oldDoc, "definitions"), value(newDoc, "definitions"))
+
+	return c.changes, nil
+}
+
+// openAPIComparer collects the changes between two OpenAPI documents
+type openAPIComparer struct {
+	changes []Change
+}
+
+// removed records an element missing from the new version
+func (c *openAPIComparer) removed(line int, format string, args ...interface{}) {
+	c.changes = append(c.changes, Change{Line: line, Removed: true, Message: fmt.Sprintf(format, args...)})
+}
+
+// changed records an element changed in the new version
+func (c *openAPIComparer) changed(line int, format string, args ...interface{}) {
+	c.changes = append(c.changes, Change{Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// comparePaths compares the paths and operations of two documents
+func (c *openAPIComparer) comparePaths(oldPaths, newPaths *yaml.Node) {
+	for _, path := range keys(oldPaths) {
+		oldKey, oldItem := entry(oldPaths, path)
+		_, newItem := entry(newPaths, path)
+		if newItem == nil {
+			c.removed(oldKey.Line, "Path `%s` was removed; clients calling it get 404s.", path)
+			continue
+		}
+		for _, method := range httpMethods {
+			methodKey, oldOperation := entry(oldItem, method)
+			if oldOperation == nil {
+				continue
+			}
+			name := strings.ToUpper(method) + " " + path
+			newOperation := value(newItem, method)
+			if newOperation == nil {
+				c.removed(methodKey.Line, "Operation `%s` was removed; clients calling it fail.", name)
+				continue
+			}
+			c.compareOperation(name, oldOperation, newOperation)
+		}
+	}
+}
+
+// compareOperation compares the parameters, request body and responses of an operation
+func (c *openAPIComparer) compareOperation(name string, oldOperation, newOperation *yaml.Node) {
+	oldParameters := parameters(value(oldOperation, "parameters"))
+	for key, newParameter := range parameters(value(newOperation, "parameters")) {
+		oldParameter, existed := oldParameters[key]
+		required := scalar(value(newParameter, "required")) == "true"
+		switch {
+		case !existed && required:
+			c.changed(newParameter.Line, "`%s` now requires the %s parameter `%s`, which existing clients do not send.",
+				name, scalar(value(newParameter, "in")), scalar(value(newParameter, "name")))
+		case existed && required && scalar(value(oldParameter, "required")) != "true":
+			c.changed(newParameter.Line, "The %s parameter `%s` of `%s` became required.",
+				scalar(value(newParameter, "in")), scalar(value(newParameter, "name")), name)
+		}
+		if existed {
+			label := fmt.Sprintf("parameter `%s` of `%s`", scalar(value(newParameter, "name")), name)
+			c.compareTypes(label, oldParameter, newParameter)
+			c.compareSchema(label, value(oldParameter, "schema"), value(newParameter, "schema"), 0)
+		}
+	}
+
+	oldBody, newBody := value(oldOperation, "requestBody"), value(newOperation, "requestBody")
+	if newBody != nil && scalar(value(newBody, "required")) == "true" && (oldBody == nil || scalar(value(oldBody, "required")) != "true") {
+		c.changed(newBody.Line, "The request body of `%s` became required.", name)
+	}
+	c.compareContent("request body of `"+name+"`", value(oldBody, "content"), value(newBody, "content"))
+
+	oldResponses, newResponses := value(oldOperation, "responses"), value(newOperation, "responses")
+	for _, code := range keys(oldResponses) {
+		codeKey, oldResponse := entry(oldResponses, code)
+		newResponse := value(newResponses, code)
+		if newResponse == nil {
+			if strings.HasPrefix(code, "2") {
+				c.removed(codeKey.Line, "The `%s` response of `%s` was removed.", code, name)
+			}
+			continue
+		}
+		label := fmt.Sprintf("`%s` response of `%s`", code, name)
+		c.compareContent(label, value(oldResponse, "content"), value(newResponse, "content"))
+		c.compareSchema(label, value(oldResponse, "schema"), value(newResponse, "schema"), 0)
+	}
+}
+
+// compareContent compares the schemas of the media types of a request or response
+func (c *openAPIComparer) compareContent(label string, oldContent, newContent *yaml.Node) {
+	for _, mediaType := range keys(oldContent) {
+		newMedia := value(newContent, mediaType)
+		if newMedia == nil {
+			continue
+		}
+		c.compareSchema(label, value(value(oldContent, mediaType), "schema"), value(newMedia, "schema"), 0)
+	}
+}
+
+// compareSchemaMap compares named schemas
+func (c *openAPIComparer) compareSchemaMap(kind string, oldSchemas, newSchemas *yaml.Node) {
+	for _, name := range keys(oldSchemas) {
+		nameKey, oldSchema := entry(oldSchemas, name)
+		newSchema := value(newSchemas, name)
+		if newSchema == nil {
+			c.removed(nameKey.Line, "The %s `%s` was removed; references to it no longer resolve.", kind, name)
+			continue
+		}
+		c.compareSchema(fmt.Sprintf("%s `%s`", kind, name), oldSchema, newSchema, 0)
+	}
+}
+
+// compareSchema compares two schemas and their properties and items
+func (c *openAPIComparer) compareSchema(label string, oldSchema, newSchema *yaml.Node, depth int) {
+	if oldSchema == nil || newSchema == nil || depth > maxSchemaDepth {
+		return
+	}
+
+	oldRef, newRef := scalar(value(oldSchema, "$ref")), scalar(value(newSchema, "$ref"))
+	if oldRef != "" || newRef != "" {
+		if oldRef != newRef {
+			c.changed(newSchema.Line, "The %s changed from `%s` to `%s`.", label, schemaName(oldSchema), schemaName(newSchema))
+		}
+		return
+	}
+	c.compareTypes(label, oldSchema, newSchema)
+
+	oldRequired := make(map[string]bool)
+	for _, item := range items(value(oldSchema, "required")) {
+		oldRequired[item.Value] = true
+	}
+	for _, item := range items(value(newSchema, "required")) {
+		if !oldRequired[item.Value] {
+			c.changed(item.Line, "Property `%s` of the %s became required, which existing clients may not send.", item.Value, label)
+		}
+	}
+
+	oldProperties, newProperties := value(oldSchema, "properties"), value(newSchema, "properties")
+	for _, property := range keys(oldProperties) {
+		propertyKey, oldProperty := entry(oldProperties, property)
+		newProperty := value(newProperties, property)
+		if newProperty == nil {
+			c.removed(propertyKey.Line, "Property `%s` was removed from the %s; clients reading it break.", property, label)
+			continue
+		}
+		c.compareSchema(fmt.Sprintf("property `%s` of the %s", property, label), oldProperty, newProperty, depth+1)
+	}
+	c.compareSchema("items of the "+label, value(oldSchema, "items"), value(newSchema, "items"), depth+1)
+
+	newValues := make(map[string]bool)
+	for _, item := range items(value(newSchema, "enum")) {
+		newValues[item.Value] = true
+	}
+	if enum := value(newSchema, "enum"); enum != nil {
+		for _, item := range items(value(oldSchema, "enum")) {
+			if !newValues[item.Value] {
+				c.removed(item.Line, "Value `%s` was removed from the %s; clients sending or expecting it break.", item.Value, label)
+			}
+		}
+	}
+}
+
+// compareTypes compares the type and format of two schemas or Swagger 2 parameters
+func (c *openAPIComparer) compareTypes(label string, oldSchema, newSchema *yaml.Node) {
+	oldType, newType := typeName(oldSchema), typeName(newSchema)
+	if oldType != "" && newType != "" && oldType != newType {
+		c.changed(value(newSchema, "type").Line, "The type of the %s changed from `%s` to `%s`.", label, oldType, newType)
+	}
+}
+
+// typeName returns the type and format of a schema
+func typeName(schema *yaml.Node) string {
+	typ := value(schema, "type")
+	if typ == nil {
+		return ""
+	}
+	name := scalar(typ)
+	if typ.Kind == yaml.SequenceNode {
+		var types []string
+		for _, item := range typ.Content {
+			types = append(types, item.Value)
+		}
+		name = strings.Join(types, "|")
+	}
+	if format := scalar(value(schema, "format")); format != "" {
+		name += "/" + format
+	}
+
+	return name
+}
+
+// schemaName describes a schema by its reference or type
+func schemaName(schema *yaml.Node) string {
+	if ref := scalar(value(schema, "$ref")); ref != "" {
+		return ref
+	}
+	if typ := typeName(schema); typ != "" {
+		return typ
+	}
+
+	return "inline schema"
+}
+
+// parameters returns the parameters of an operation by location and name.
+// Referenced parameters are keyed by their reference.
+func parameters(list *yaml.Node) map[string]*yaml.Node {
+	byKey := make(map[string]*yaml.Node)
+	for _, parameter := range items(list) {
+		key := scalar(value(parameter, "$ref"))
+		if key == "" {
+			key = scalar(value(parameter, "in")) + " " + scalar(value(parameter, "name"))
+		}
+		byKey[key] = parameter
+	}
+
+	return byKey
+}
+
+// parseYAML parses a YAML or JSON document
+func parseYAML(content string) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		return doc.Content[0], nil
+	}
+
+	return &doc, nil
+}
+
+// entry returns the key and value of a mapping's entry, or nils
+func entry(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+
+	return nil, nil
+}
+
+// value returns the value of a mapping's entry, or nil
+func value(mapping *yaml.Node, key string) *yaml.Node {
+	_, v := entry(mapping, key)
+	return v
+}
+
+// keys returns the keys of a mapping in document order
+func keys(mapping *yaml.Node) []string {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	var names []string
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		names = append(names, mapping.Content[i].Value)
+	}
+
+	return names
+}
+
+// items returns the items of a sequence, or nil
+func items(sequence *yaml.Node) []*yaml.Node {
+	if sequence == nil || sequence.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	return sequence.Content
+}
+
+// scalar returns the value of a scalar node, or ""
+func scalar(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+
+	return node.Value
+}
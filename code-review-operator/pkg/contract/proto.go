@@ -0,0 +1,555 @@
+package contract
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// token is a token of a .proto file
+type token struct {
+	text string
+	line int
+}
+
+// protoFile holds the definitions of a .proto file by their full name
+type protoFile struct {
+	messages map[string]*protoMessage
+	enums    map[string]*protoEnum
+	services map[string]*protoService
+}
+
+// protoMessage is a message definition
+type protoMessage struct {
+	line     int
+	fields   map[int]*protoField
+	reserved map[string]bool
+}
+
+// protoField is a field of a message
+type protoField struct {
+	name   string
+	typ    string
+	number int
+	line   int
+}
+
+// protoEnum is an enum definition
+type protoEnum struct {
+	line     int
+	values   map[string]*protoField
+	reserved map[string]bool
+}
+
+// protoService is a service definition
+type protoService struct {
+	line    int
+	methods map[string]*protoMethod
+}
+
+// protoMethod is an RPC of a service
+type protoMethod struct {
+	request  string
+	response string
+	line     int
+}
+
+// compareProto returns the breaking changes between two versions of a .proto file
+func compareProto(oldContent, newContent string) ([]Change, error) {
+	oldFile, err := parseProto(oldContent)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing old version: %w", err)
+	}
+	newFile, err := parseProto(newContent)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing new version: %w", err)
+	}
+
+	var changes []Change
+	for _, name := range sortedKeys(oldFile.messages) {
+		oldMessage := oldFile.messages[name]
+		newMessage, ok := newFile.messages[name]
+		if !ok {
+			changes = append(changes, Change{Line: oldMessage.line, Removed: true,
+				Message: fmt.Sprintf("Message `%s` was removed; clients using it no longer compile.", name)})
+			continue
+		}
+		changes = append(changes, compareFields(name, oldMessage, newMessage)...)
+	}
+
+	for _, name := range sortedKeys(oldFile.enums) {
+		oldEnum := oldFile.enums[name]
+		newEnum, ok := newFile.enums[name]
+		if !ok {
+			changes = append(changes, Change{Line: oldEnum.line, Removed: true,
+				Message: fmt.Sprintf("Enum `%s` was removed; clients using it no longer compile.", name)})
+			continue
+		}
+		for _, valueName := range sortedKeys(oldEnum.values) {
+			oldValue := oldEnum.values[valueName]
+			newValue, ok := newEnum.values[valueName]
+			switch {
+			case !ok && !newEnum.reserved[valueName] && !newEnum.reserved[strconv.Itoa(oldValue.number)]:
+				changes = append(changes, Change{Line: oldValue.line, Removed: true,
+					Message: fmt.Sprintf("Enum value `%s.%s = %d` was removed without reserving its name and number, "+
+						"so they can be reused with a different meaning.", name, valueName, oldValue.number)})
+			case ok && newValue.number != oldValue.number:
+				changes = append(changes, Change{Line: newValue.line,
+					Message: fmt.Sprintf("Enum value `%s.%s` was renumbered from %d to %d; stored and in-flight "+
+						"values now decode to a different value.", name, valueName, oldValue.number, newValue.number)})
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(oldFile.services) {
+		oldService := oldFile.services[name]
+		newService, ok := newFile.services[name]
+		if !ok {
+			changes = append(changes, Change{Line: oldService.line, Removed: true,
+				Message: fmt.Sprintf("Service `%s` was removed; its clients' calls will fail.", name)})
+			continue
+		}
+		for _, methodName := range sortedKeys(oldService.methods) {
+			oldMethod := oldService.methods[methodName]
+			newMethod, ok := newService.methods[methodName]
+			switch {
+			case !ok:
+				changes = append(changes, Change{Line: oldMethod.line, Removed: true,
+					Message: fmt.Sprintf("RPC `%s.%s` was removed; its clients' calls will fail.", name, methodName)})
+			case newMethod.request != oldMethod.request || newMethod.response != oldMethod.response:
+				changes = append(changes, Change{Line: newMethod.line,
+					Message: fmt.Sprintf("RPC `%s.%s` changed from `(%s) returns (%s)` to `(%s) returns (%s)`, "+
+						"which existing clients cannot call.", name, methodName, oldMethod.request, oldMethod.response,
+						newMethod.request, newMethod.response)})
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// compareFields returns the breaking changes of a message's fields
+func compareFields(message string, oldMessage, newMessage *protoMessage) []Change {
+	newByName := make(map[string]*protoField, len(newMessage.fields))
+	for _, field := range newMessage.fields {
+		newByName[field.name] = field
+	}
+
+	var changes []Change
+	for _, number := range sortedNumbers(oldMessage.fields) {
+		oldField := oldMessage.fields[number]
+		newField, ok := newMessage.fields[number]
+		switch {
+		case !ok && newByName[oldField.name] != nil:
+			renumbered := newByName[oldField.name]
+			changes = append(changes, Change{Line: renumbered.line,
+				Message: fmt.Sprintf("Field `%s.%s` was renumbered from %d to %d; existing data and clients "+
+					"still use %d.", message, oldField.name, oldField.number, renumbered.number, oldField.number)})
+		case !ok && !newMessage.reserved[strconv.Itoa(number)]:
+			changes = append(changes, Change{Line: oldField.line, Removed: true,
+				Message: fmt.Sprintf("Field `%s.%s = %d` was removed without reserving its number, so it can be "+
+					"reused with a different type. Add `reserved %d;` and `reserved \"%s\";`.", message, oldField.name,
+					number, number, oldField.name)})
+		case ok && newField.typ != oldField.typ:
+			changes = append(changes, Change{Line: newField.line,
+				Message: fmt.Sprintf("Field `%s.%s` changed type from `%s` to `%s`; existing data and clients "+
+					"decode it incorrectly.", message, newField.name, oldField.typ, newField.typ)})
+		case ok && newField.name != oldField.name:
+			changes = append(changes, Change{Line: newField.line,
+				Message: fmt.Sprintf("Field `%s.%s` was renamed to `%s`. The binary encoding is unchanged, but "+
+					"JSON payloads and field masks using the old name break.", message, oldField.name, newField.name)})
+		}
+	}
+
+	return changes
+}
+
+// parseProto parses the messages, enums and services of a .proto file
+func parseProto(content string) (*protoFile, error) {
+	p := &protoParser{tokens: tokenizeProto(content)}
+	file := &protoFile{
+		messages: make(map[string]*protoMessage),
+		enums:    make(map[string]*protoEnum),
+		services: make(map[string]*protoService),
+	}
+	if err := p.parseBody(file, ""); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// protoParser reads definitions from the tokens of a .proto file
+type protoParser struct {
+	tokens []token
+	pos    int
+}
+
+// next returns the next token, or an empty token at the end
+func (p *protoParser) next() token {
+	if p.pos >= len(p.tokens) {
+		return token{}
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+
+	return t
+}
+
+// peek returns the next token without consuming it
+func (p *protoParser) peek() token {
+	if p.pos >= len(p.tokens) {
+		return token{}
+	}
+
+	return p.tokens[p.pos]
+}
+
+// skipStatement skips to the end of a statement, including a block it opens
+func (p *protoParser) skipStatement() {
+	for t := p.next(); t.text != ""; t = p.next() {
+		switch t.text {
+		case ";":
+			return
+		case "{":
+			p.skipBlock()
+			return
+		}
+	}
+}
+
+// skipBlock skips to the brace closing a block whose opening brace was consumed
+func (p *protoParser) skipBlock() {
+	depth := 1
+	for t := p.next(); t.text != "" && depth > 0; t = p.next() {
+		switch t.text {
+		case "{":
+			depth++
+		case "}":
+			depth--
+		}
+		if depth == 0 {
+			return
+		}
+	}
+}
+
+// expect consumes a token, failing if it is not the expected one
+func (p *protoParser) expect(text string) error {
+	if t := p.next(); t.text != text {
+		return fmt.Errorf("line %d: expected %q, found %q", t.line, text, t.text)
+	}
+
+	return nil
+}
+
+// parseBody parses top-level definitions, or those nested in a message
+// named prefix, until the end of the file or the closing brace
+func (p *protoParser) parseBody(file *protoFile, prefix string) error {
+	var message *protoMessage
+	if prefix != "" {
+		message = file.messages[prefix]
+	}
+
+	for {
+		t := p.next()
+		switch t.text {
+		case "":
+			if prefix != "" {
+				return fmt.Errorf("message %s is not closed", prefix)
+			}
+			return nil
+		case "}":
+			if prefix == "" {
+				return fmt.Errorf("line %d: unexpected \"}\"", t.line)
+			}
+			return nil
+		case ";":
+		case "message":
+			name := qualified(prefix, p.next().text)
+			file.messages[name] = &protoMessage{line: t.line, fields: make(map[int]*protoField), reserved: make(map[string]bool)}
+			if err := p.expect("{"); err != nil {
+				return err
+			}
+			if err := p.parseBody(file, name); err != nil {
+				return err
+			}
+		case "enum":
+			if err := p.parseEnum(file, qualified(prefix, p.next().text), t.line); err != nil {
+				return err
+			}
+		case "service":
+			if err := p.parseService(file, p.next().text, t.line); err != nil {
+				return err
+			}
+		case "oneof":
+			if err := p.parseOneof(message); err != nil {
+				return err
+			}
+		case "reserved":
+			for r := p.next(); r.text != ";" && r.text != ""; r = p.next() {
+				if message != nil {
+					reserveRange(message.reserved, r.text, p)
+				}
+			}
+		case "syntax", "edition", "package", "import", "option", "extensions", "extend":
+			p.skipStatement()
+		default:
+			if message == nil {
+				p.skipStatement()
+				continue
+			}
+			p.pos--
+			if err := p.parseField(message); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// parseField parses a field of a message
+func (p *protoParser) parseField(message *protoMessage) error {
+	start := p.next()
+	typ := start.text
+	if typ == "repeated" || typ == "optional" || typ == "required" {
+		typ += " " + p.next().text
+	}
+	if typ == "map" {
+		// map<key, value>
+		var b strings.Builder
+		b.WriteString("map")
+		for t := p.next(); t.text != "" && t.text != ">"; t = p.next() {
+			b.WriteString(t.text)
+			if t.text == "," {
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString(">")
+		typ = b.String()
+	}
+	name := p.next().text
+	if err := p.expect("="); err != nil {
+		return err
+	}
+	numberToken := p.next()
+	number, err := strconv.ParseInt(numberToken.text, 0, 32)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid field number %q", numberToken.line, numberToken.text)
+	}
+	p.skipStatement()
+
+	// Proto3 optional does not change the wire type
+	message.fields[int(number)] = &protoField{name: name, typ: strings.TrimPrefix(typ, "optional "), number: int(number), line: start.line}
+
+	return nil
+}
+
+// parseOneof parses a oneof, whose fields belong to the enclosing message
+func (p *protoParser) parseOneof(message *protoMessage) error {
+	p.next()
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		switch p.peek().text {
+		case "":
+			return fmt.Errorf("oneof is not closed")
+		case "}":
+			p.next()
+			return nil
+		case ";":
+			p.next()
+		case "option":
+			p.skipStatement()
+		default:
+			if message == nil {
+				p.skipStatement()
+				continue
+			}
+			if err := p.parseField(message); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// parseEnum parses an enum definition
+func (p *protoParser) parseEnum(file *protoFile, name string, line int) error {
+	enum := &protoEnum{line: line, values: make(map[string]*protoField), reserved: make(map[string]bool)}
+	file.enums[name] = enum
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+
+	for {
+		t := p.next()
+		switch t.text {
+		case "":
+			return fmt.Errorf("enum %s is not closed", name)
+		case "}":
+			return nil
+		case ";":
+		case "option":
+			p.skipStatement()
+		case "reserved":
+			for r := p.next(); r.text != ";" && r.text != ""; r = p.next() {
+				reserveRange(enum.reserved, r.text, p)
+			}
+		default:
+			if err := p.expect("="); err != nil {
+				return err
+			}
+			numberToken := p.next()
+			if numberToken.text == "-" {
+				numberToken = p.next()
+				numberToken.text = "-" + numberToken.text
+			}
+			number, err := strconv.ParseInt(numberToken.text, 0, 32)
+			if err != nil {
+				return fmt.Errorf("line %d: invalid enum value %q", numberToken.line, numberToken.text)
+			}
+			enum.values[t.text] = &protoField{name: t.text, number: int(number), line: t.line}
+			p.skipStatement()
+		}
+	}
+}
+
+// parseService parses a service definition
+func (p *protoParser) parseService(file *protoFile, name string, line int) error {
+	service := &protoService{line: line, methods: make(map[string]*protoMethod)}
+	file.services[name] = service
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+
+	for {
+		t := p.next()
+		switch t.text {
+		case "":
+			return fmt.Errorf("service %s is not closed", name)
+		case "}":
+			return nil
+		case "rpc":
+			method := &protoMethod{line: t.line}
+			methodName := p.next().text
+			method.request = p.rpcType()
+			if err := p.expect("returns"); err != nil {
+				return err
+			}
+			method.response = p.rpcType()
+			service.methods[methodName] = method
+			p.skipStatement()
+		default:
+			p.pos--
+			p.skipStatement()
+		}
+	}
+}
+
+// rpcType parses the parenthesized request or response type of an RPC
+func (p *protoParser) rpcType() string {
+	var parts []string
+	if p.peek().text == "(" {
+		p.next()
+		for t := p.next(); t.text != "" && t.text != ")"; t = p.next() {
+			parts = append(parts, t.text)
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// reserveRange records a reserved name, number or "from to max" range.
+// Ranges are recorded by their bounds, as fields are looked up one by one.
+func reserveRange(reserved map[string]bool, text string, p *protoParser) {
+	if text == "," {
+		return
+	}
+	text = strings.Trim(text, `"'`)
+	from, err := strconv.Atoi(text)
+	if err != nil || p.peek().text != "to" {
+		reserved[text] = true
+		return
+	}
+	p.next()
+	to, err := strconv.Atoi(p.peek().text)
+	if p.peek().text == "max" {
+		to, err = 536870911, nil
+	}
+	if err != nil {
+		reserved[text] = true
+		return
+	}
+	p.next()
+	for n := from; n <= to && n-from < 10000; n++ {
+		reserved[strconv.Itoa(n)] = true
+	}
+}
+
+// qualified joins a nested definition's name to its parent's
+func qualified(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "." + name
+}
+
+// tokenizeProto splits a .proto file into tokens, dropping comments
+func tokenizeProto(content string) []token {
+	var tokens []token
+	runes := []rune(content)
+	line := 1
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\n':
+			line++
+		case unicode.IsSpace(r):
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			i--
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			for i += 2; i < len(runes) && !(runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/'); i++ {
+				if runes[i] == '\n' {
+					line++
+				}
+			}
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			for i++; i < len(runes) && runes[i] != r && runes[i] != '\n'; i++ {
+				if runes[i] == '\\' {
+					i++
+				}
+			}
+			tokens = append(tokens, token{text: string(runes[start:min(i+1, len(runes))]), line: line})
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{text: string(runes[start:i]), line: line})
+			i--
+		default:
+			tokens = append(tokens, token{text: string(r), line: line})
+		}
+	}
+
+	return tokens
+}
+
+// sortedNumbers returns the field numbers of a message in order
+func sortedNumbers(fields map[int]*protoField) []int {
+	numbers := make([]int, 0, len(fields))
+	for number := range fields {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	return numbers
+}
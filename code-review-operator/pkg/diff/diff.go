@@ -0,0 +1,249 @@
+package diff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LineKind is the kind of a line in a hunk
+type LineKind string
+
+const (
+	// LineContext is an unchanged line shown for context
+	LineContext LineKind = "context"
+
+	// LineAdded is a line added in the new version
+	LineAdded LineKind = "added"
+
+	// LineRemoved is a line removed from the old version
+	LineRemoved LineKind = "removed"
+)
+
+// Side is the version of a file a line number refers to
+type Side string
+
+const (
+	// SideLeft is the old version of the file
+	SideLeft Side = "LEFT"
+
+	// SideRight is the new version of the file
+	SideRight Side = "RIGHT"
+)
+
+// FileStatus describes how a file changed
+type FileStatus string
+
+const (
+	// FileModified is a file changed in place
+	FileModified FileStatus = "modified"
+
+	// FileAdded is a new file
+	FileAdded FileStatus = "added"
+
+	// FileDeleted is a removed file
+	FileDeleted FileStatus = "deleted"
+
+	// FileRenamed is a file moved to a new path, possibly with changes
+	FileRenamed FileStatus = "renamed"
+)
+
+// File is the diff of a single file
+type File struct {
+	// OldPath is the path in the old version, empty for added files
+	OldPath string
+
+	// NewPath is the path in the new version, empty for deleted files
+	NewPath string
+
+	// Status describes how the file changed
+	Status FileStatus
+
+	// Binary is true for binary files, which have no hunks
+	Binary bool
+
+	// Header holds the raw header lines preceding the first hunk
+	Header string
+
+	// Hunks are the changed regions of the file
+	Hunks []*Hunk
+}
+
+// Hunk is a changed region of a file
+type Hunk struct {
+	// OldStart and OldLines give the range of the hunk in the old version
+	OldStart int
+	OldLines int
+
+	// NewStart and NewLines give the range of the hunk in the new version
+	NewStart int
+	NewLines int
+
+	// Section is the text following the range, usually the enclosing function
+	Section string
+
+	// Lines are the lines of the hunk
+	Lines []*Line
+}
+
+// Line is a line of a hunk
+type Line struct {
+	// Kind is whether the line was added, removed or left unchanged
+	Kind LineKind
+
+	// OldNumber is the line number in the old version, 0 for added lines
+	OldNumber int
+
+	// NewNumber is the line number in the new version, 0 for removed lines
+	NewNumber int
+
+	// Position is the line's offset in the file's diff, counting from the
+	// line after the first hunk header, as used by GitHub review comments
+	Position int
+
+	// Content is the text of the line without its prefix
+	Content string
+
+	// NoNewline is true if the line has no trailing newline
+	NoNewline bool
+}
+
+// Path returns the path of the file in the new version, or the old path for deleted files
+func (f *File) Path() string {
+	if f.NewPath != "" {
+		return f.NewPath
+	}
+
+	return f.OldPath
+}
+
+// Line returns the line with the given number on a side, or nil if the line
+// is not part of the diff and cannot be commented on
+func (f *File) Line(side Side, number int) *Line {
+	_, line := f.find(side, number)
+	return line
+}
+
+// Hunk returns the hunk containing the line with the given number on a side, or nil
+func (f *File) Hunk(side Side, number int) *Hunk {
+	hunk, _ := f.find(side, number)
+	return hunk
+}
+
+// find returns the line with the given number on a side and its hunk
+func (f *File) find(side Side, number int) (*Hunk, *Line) {
+	for _, hunk := range f.Hunks {
+		for _, line := range hunk.Lines {
+			switch {
+			case side == SideRight && line.Kind != LineRemoved && line.NewNumber == number:
+				return hunk, line
+			case side == SideLeft && line.Kind != LineAdded && line.OldNumber == number:
+				return hunk, line
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// OldContent reconstructs the old version of a file from its new version by
+// reverting the file's hunks. It fails if the new version does not match the diff.
+func (f *File) OldContent(newContent string) (string, error) {
+	newLines := strings.Split(newContent, "\n")
+	var old []string
+	next := 1 // the next line of the new version to copy
+	copyTo := func(end int) error {
+		if end < next-1 || end > len(newLines) {
+			return fmt.Errorf("%s does not match its diff at line %d", f.Path(), end)
+		}
+		old = append(old, newLines[next-1:end]...)
+		next = end + 1
+		return nil
+	}
+
+	for _, hunk := range f.Hunks {
+		// A hunk that only removes lines starts after its NewStart line
+		end := hunk.NewStart - 1
+		if hunk.NewLines == 0 {
+			end = hunk.NewStart
+		}
+		if err := copyTo(end); err != nil {
+			return "", err
+		}
+		for _, line := range hunk.Lines {
+			if line.Kind == LineRemoved {
+				old = append(old, line.Content)
+				continue
+			}
+			if err := copyTo(line.NewNumber - 1); err != nil {
+				return "", err
+			}
+			if line.NewNumber > len(newLines) || newLines[line.NewNumber-1] != line.Content {
+				return "", fmt.Errorf("%s does not match its diff at line %d", f.Path(), line.NewNumber)
+			}
+			next = line.NewNumber + 1
+			if line.Kind == LineContext {
+				old = append(old, line.Content)
+			}
+		}
+	}
+	if err := copyTo(len(newLines)); err != nil {
+		return "", err
+	}
+
+	return strings.Join(old, "\n"), nil
+}
+
+// String renders the file as a unified diff
+func (f *File) String() string {
+	var b strings.Builder
+	b.WriteString(f.Header)
+	for _, hunk := range f.Hunks {
+		b.WriteString(hunk.String())
+	}
+
+	return b.String()
+}
+
+// String renders the hunk as a unified diff
+func (h *Hunk) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "@@ -%s +%s @@%s\n", hunkRange(h.OldStart, h.OldLines), hunkRange(h.NewStart, h.NewLines), h.Section)
+	for _, line := range h.Lines {
+		switch line.Kind {
+		case LineAdded:
+			b.WriteString("+")
+		case LineRemoved:
+			b.WriteString("-")
+		default:
+			b.WriteString(" ")
+		}
+		b.WriteString(line.Content)
+		b.WriteString("\n")
+		if line.NoNewline {
+			b.WriteString("\\ No newline at end of file\n")
+		}
+	}
+
+	return b.String()
+}
+
+// hunkRange formats a hunk range, omitting a line count of one as git does
+func hunkRange(start, lines int) string {
+	if lines == 1 {
+		return strconv.Itoa(start)
+	}
+
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+// Find returns the file with the given path in either version, or nil
+func Find(files []*File, path string) *File {
+	for _, file := range files {
+		if file.NewPath == path || file.OldPath == path {
+			return file
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,233 @@
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hunkHeader matches a hunk header such as "@@ -1,4 +1,5 @@ func main() {"
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@(.*)$`)
+
+// parser holds the state of a diff being parsed
+type parser struct {
+	files []*File
+	file  *File
+	hunk  *Hunk
+
+	// oldLeft and newLeft count the lines remaining in the current hunk
+	oldLeft, newLeft int
+
+	// position is the position of the last line in the current file
+	position int
+}
+
+// Parse parses a unified diff, as produced by git diff or returned by Git
+// provider APIs, into files, hunks and lines
+func Parse(diff string) ([]*File, error) {
+	p := &parser{}
+
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	for i, line := range lines {
+		if err := p.parseLine(line); err != nil {
+			return nil, fmt.Errorf("error parsing diff line %d: %w", i+1, err)
+		}
+	}
+	if p.inHunk() {
+		return nil, fmt.Errorf("error parsing diff: hunk at %s line %d is truncated", p.file.Path(), p.hunk.NewStart)
+	}
+
+	return p.files, nil
+}
+
+// inHunk reports whether the parser expects more lines of the current hunk
+func (p *parser) inHunk() bool {
+	return p.hunk != nil && (p.oldLeft > 0 || p.newLeft > 0)
+}
+
+// parseLine parses one line of the diff
+func (p *parser) parseLine(line string) error {
+	// Inside a hunk, the line counts decide what is content
+	if p.inHunk() {
+		return p.parseHunkLine(line)
+	}
+
+	switch {
+	case strings.HasPrefix(line, "diff --git "),
+		strings.HasPrefix(line, "--- ") && (p.file == nil || p.hunk != nil):
+		// A new file, with or without a git header
+		p.startFile()
+		p.parseHeaderLine(line)
+	case p.file == nil:
+		// Text before the first file, such as a commit message
+	case strings.HasPrefix(line, "@@"):
+		return p.startHunk(line)
+	case strings.HasPrefix(line, `\`) && p.hunk != nil:
+		if n := len(p.hunk.Lines); n > 0 {
+			p.hunk.Lines[n-1].NoNewline = true
+		}
+		p.position++
+	default:
+		p.parseHeaderLine(line)
+	}
+
+	return nil
+}
+
+// startFile begins a new file
+func (p *parser) startFile() {
+	p.file = &File{Status: FileModified}
+	p.files = append(p.files, p.file)
+	p.hunk = nil
+	p.position = 0
+}
+
+// parseHeaderLine parses an extended header line of the current file
+func (p *parser) parseHeaderLine(line string) {
+	p.file.Header += line + "\n"
+
+	switch {
+	case strings.HasPrefix(line, "diff --git "):
+		p.file.OldPath, p.file.NewPath = gitPaths(strings.TrimPrefix(line, "diff --git "))
+	case strings.HasPrefix(line, "--- "):
+		p.file.OldPath = headerPath(strings.TrimPrefix(line, "--- "))
+		if p.file.OldPath == "" {
+			p.file.Status = FileAdded
+		}
+	case strings.HasPrefix(line, "+++ "):
+		p.file.NewPath = headerPath(strings.TrimPrefix(line, "+++ "))
+		if p.file.NewPath == "" {
+			p.file.Status = FileDeleted
+		}
+	case strings.HasPrefix(line, "new file mode"):
+		p.file.Status = FileAdded
+		p.file.OldPath = ""
+	case strings.HasPrefix(line, "deleted file mode"):
+		p.file.Status = FileDeleted
+		p.file.NewPath = ""
+	case strings.HasPrefix(line, "rename from "):
+		p.file.Status = FileRenamed
+		p.file.OldPath = strings.TrimPrefix(line, "rename from ")
+	case strings.HasPrefix(line, "rename to "):
+		p.file.Status = FileRenamed
+		p.file.NewPath = strings.TrimPrefix(line, "rename to ")
+	case strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch":
+		p.file.Binary = true
+	}
+}
+
+// startHunk begins a new hunk from its header line
+func (p *parser) startHunk(line string) error {
+	match := hunkHeader.FindStringSubmatch(line)
+	if match == nil {
+		return fmt.Errorf("invalid hunk header %q", line)
+	}
+
+	p.hunk = &Hunk{
+		OldStart: atoi(match[1]),
+		OldLines: atoiDefault(match[2], 1),
+		NewStart: atoi(match[3]),
+		NewLines: atoiDefault(match[4], 1),
+		Section:  match[5],
+	}
+	p.file.Hunks = append(p.file.Hunks, p.hunk)
+	p.oldLeft, p.newLeft = p.hunk.OldLines, p.hunk.NewLines
+
+	// The first hunk header is position 0; later ones take a position
+	if len(p.file.Hunks) > 1 {
+		p.position++
+	}
+
+	return nil
+}
+
+// parseHunkLine parses a content line of the current hunk
+func (p *parser) parseHunkLine(line string) error {
+	if strings.HasPrefix(line, `\`) {
+		if n := len(p.hunk.Lines); n > 0 {
+			p.hunk.Lines[n-1].NoNewline = true
+		}
+		p.position++
+		return nil
+	}
+
+	oldNumber := p.hunk.OldStart + p.hunk.OldLines - p.oldLeft
+	newNumber := p.hunk.NewStart + p.hunk.NewLines - p.newLeft
+	p.position++
+
+	parsed := &Line{Position: p.position}
+	switch {
+	case strings.HasPrefix(line, "+"):
+		if p.newLeft == 0 {
+			return fmt.Errorf("hunk has more added lines than its header declares")
+		}
+		parsed.Kind, parsed.NewNumber, parsed.Content = LineAdded, newNumber, line[1:]
+		p.newLeft--
+	case strings.HasPrefix(line, "-"):
+		if p.oldLeft == 0 {
+			return fmt.Errorf("hunk has more removed lines than its header declares")
+		}
+		parsed.Kind, parsed.OldNumber, parsed.Content = LineRemoved, oldNumber, line[1:]
+		p.oldLeft--
+	default:
+		// Context lines start with a space, which some tools strip from empty lines
+		if p.oldLeft == 0 || p.newLeft == 0 {
+			return fmt.Errorf("hunk has more context lines than its header declares")
+		}
+		parsed.Kind, parsed.OldNumber, parsed.NewNumber = LineContext, oldNumber, newNumber
+		parsed.Content = strings.TrimPrefix(line, " ")
+		p.oldLeft--
+		p.newLeft--
+	}
+	p.hunk.Lines = append(p.hunk.Lines, parsed)
+
+	return nil
+}
+
+// gitPaths extracts the old and new paths from the arguments of a "diff --git" line
+func gitPaths(args string) (string, string) {
+	// Paths without spaces are split on the separating " b/"
+	if i := strings.Index(args, " b/"); strings.HasPrefix(args, "a/") && i > 0 {
+		return args[2:i], args[i+3:]
+	}
+
+	fields := strings.Fields(args)
+	if len(fields) != 2 {
+		return "", ""
+	}
+
+	return headerPath(fields[0]), headerPath(fields[1])
+}
+
+// headerPath extracts the path from a ---/+++ header, returning "" for /dev/null
+func headerPath(path string) string {
+	// Drop the timestamp some tools append after a tab
+	if i := strings.Index(path, "\t"); i >= 0 {
+		path = path[:i]
+	}
+	path = strings.Trim(path, `"`)
+	if path == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(path, "a/") || strings.HasPrefix(path, "b/") {
+		return path[2:]
+	}
+
+	return path
+}
+
+// atoi parses a number matched by hunkHeader
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// atoiDefault parses an optional number matched by hunkHeader
+func atoiDefault(s string, def int) int {
+	if s == "" {
+		return def
+	}
+
+	return atoi(s)
+}
@@ -0,0 +1,137 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	neturl "net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// namespaceFile holds the namespace of the pod's service account
+const namespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// Destination is an endpoint the operator connects to
+type Destination struct {
+	// Purpose describes what the operator uses the endpoint for
+	Purpose string
+
+	// Host is the host name or IP address
+	Host string
+
+	// Port is the TCP port
+	Port int
+
+	// Namespace is the namespace of an in-cluster service, empty for external hosts
+	Namespace string
+
+	// Addresses are the resolved IP addresses of an external host
+	Addresses []string
+}
+
+// InCluster reports whether the destination is a service in the cluster
+func (d Destination) InCluster() bool {
+	return d.Namespace != ""
+}
+
+// ParseURL returns the destination of an endpoint URL. Bare service names and
+// names of the form service.namespace.svc[.cluster.local] are in-cluster
+// services; bare service names are looked up in defaultNamespace.
+func ParseURL(purpose, rawURL, defaultNamespace string) (Destination, error) {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return Destination{}, fmt.Errorf("error parsing %s URL: %w", purpose, err)
+	}
+	if u.Hostname() == "" {
+		return Destination{}, fmt.Errorf("%s URL %q has no host", purpose, rawURL)
+	}
+
+	destination := Destination{Purpose: purpose, Host: u.Hostname()}
+	switch {
+	case u.Port() != "":
+		destination.Port, err = strconv.Atoi(u.Port())
+		if err != nil {
+			return Destination{}, fmt.Errorf("%s URL %q has an invalid port", purpose, rawURL)
+		}
+	case u.Scheme == "http":
+		destination.Port = 80
+	case u.Scheme == "nats" || u.Scheme == "tls":
+		destination.Port = 4222
+	default:
+		destination.Port = 443
+	}
+
+	labels := strings.Split(strings.TrimSuffix(destination.Host, ".cluster.local"), ".")
+	switch {
+	case net.ParseIP(destination.Host) != nil:
+		destination.Addresses = []string{destination.Host}
+	case len(labels) == 1:
+		destination.Namespace = defaultNamespace
+	case len(labels) == 3 && labels[2] == "svc":
+		destination.Namespace = labels[1]
+	}
+
+	return destination, nil
+}
+
+// Resolve looks up the IP addresses of external destinations. Addresses of
+// hosts behind CDNs change over time, so destinations should be re-resolved
+// periodically. Every destination is returned even if some fail to resolve,
+// in which case the errors are returned as well.
+func Resolve(ctx context.Context, destinations []Destination) ([]Destination, error) {
+	resolved := make([]Destination, 0, len(destinations))
+	var errs []error
+	for _, destination := range destinations {
+		if !destination.InCluster() && net.ParseIP(destination.Host) == nil {
+			addrs, err := net.DefaultResolver.LookupHost(ctx, destination.Host)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("error resolving %s host %s: %w", destination.Purpose, destination.Host, err))
+			}
+			sort.Strings(addrs)
+			destination.Addresses = addrs
+		}
+		resolved = append(resolved, destination)
+	}
+
+	return resolved, errors.Join(errs...)
+}
+
+// Report renders the destinations as a table for reviewing the operator's
+// network access
+func Report(destinations []Destination) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PURPOSE\tHOST\tPORT\tADDRESSES")
+	for _, destination := range destinations {
+		addresses := strings.Join(destination.Addresses, ",")
+		port := strconv.Itoa(destination.Port)
+		if destination.InCluster() {
+			// Policies match the pod's target port, which may differ from the service port
+			addresses = "pods in namespace " + destination.Namespace
+			port = "any"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", destination.Purpose, destination.Host, port, addresses)
+	}
+	fmt.Fprintln(w, "dns\t*\t53\tany")
+	w.Flush()
+
+	return b.String()
+}
+
+// CurrentNamespace returns the namespace the operator runs in, or "" outside a cluster
+func CurrentNamespace() string {
+	if namespace := os.Getenv("POD_NAMESPACE"); namespace != "" {
+		return namespace
+	}
+	data, err := os.ReadFile(namespaceFile)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(data))
+}
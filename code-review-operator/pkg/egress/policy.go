@@ -0,0 +1,194 @@
+package egress
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DefaultPolicyName is the default name of the egress NetworkPolicy
+	DefaultPolicyName = "code-review-operator-egress"
+
+	// DefaultRefreshInterval is how often destinations are re-resolved and the policy updated
+	DefaultRefreshInterval = 10 * time.Minute
+)
+
+// NetworkPolicy returns a policy allowing the pods matched by podLabels
+// egress only to DNS and the given destinations
+func NetworkPolicy(name, namespace string, podLabels map[string]string, destinations []Destination) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: networkingv1.SchemeGroupVersion.String(), Kind: "NetworkPolicy"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       policySpec(podLabels, destinations),
+	}
+}
+
+// policySpec builds the spec of the egress policy
+func policySpec(podLabels map[string]string, destinations []Destination) networkingv1.NetworkPolicySpec {
+	udp, tcp := corev1.ProtocolUDP, corev1.ProtocolTCP
+	dnsPort := intstr.FromInt32(53)
+
+	spec := networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{MatchLabels: podLabels},
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+		Egress: []networkingv1.NetworkPolicyEgressRule{{
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &udp, Port: &dnsPort},
+				{Protocol: &tcp, Port: &dnsPort},
+			},
+		}},
+	}
+
+	for _, destination := range destinations {
+		rule := networkingv1.NetworkPolicyEgressRule{}
+		if destination.InCluster() {
+			// Policies match the pod's target port, which may differ from the service port
+			rule.To = []networkingv1.NetworkPolicyPeer{{
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{corev1.LabelMetadataName: destination.Namespace},
+				},
+			}}
+		} else {
+			for _, address := range destination.Addresses {
+				rule.To = append(rule.To, networkingv1.NetworkPolicyPeer{
+					IPBlock: &networkingv1.IPBlock{CIDR: hostCIDR(address)},
+				})
+			}
+			port := intstr.FromInt32(int32(destination.Port))
+			rule.Ports = []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &port}}
+		}
+		if len(rule.To) > 0 {
+			spec.Egress = append(spec.Egress, rule)
+		}
+	}
+
+	return spec
+}
+
+// hostCIDR returns the single-address CIDR of an IP address
+func hostCIDR(address string) string {
+	if ip := net.ParseIP(address); ip != nil && ip.To4() == nil {
+		return address + "/128"
+	}
+
+	return address + "/32"
+}
+
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=endpoints,verbs=get;list;watch
+
+// PolicyManager keeps a NetworkPolicy restricting the operator's egress up to
+// date as the addresses of its destinations change. It implements
+// manager.Runnable.
+type PolicyManager struct {
+	// Client is used to read the API server endpoints and write the policy
+	Client client.Client
+
+	// Name and Namespace identify the NetworkPolicy
+	Name      string
+	Namespace string
+
+	// PodLabels select the operator's pods
+	PodLabels map[string]string
+
+	// Destinations are the endpoints the operator needs to reach
+	Destinations []Destination
+
+	// Interval is how often the policy is refreshed (defaults to DefaultRefreshInterval)
+	Interval time.Duration
+}
+
+// Start applies the policy and refreshes it until the context is done
+func (m *PolicyManager) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("egress")
+
+	interval := m.Interval
+	if interval <= 0 {
+		interval = DefaultRefreshInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.Apply(ctx); err != nil {
+			logger.Error(err, "unable to apply egress network policy")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Apply resolves the destinations and creates or updates the policy
+func (m *PolicyManager) Apply(ctx context.Context) error {
+	// Keep the current policy rather than cutting off a destination that failed to resolve
+	destinations, err := Resolve(ctx, m.Destinations)
+	if err != nil {
+		return err
+	}
+
+	apiServer, err := m.apiServer(ctx)
+	if err != nil {
+		return err
+	}
+	destinations = append(destinations, apiServer...)
+
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: m.Name, Namespace: m.Namespace},
+	}
+	_, err = controllerutil.CreateOrUpdate(ctx, m.Client, policy, func() error {
+		if policy.Labels == nil {
+			policy.Labels = make(map[string]string)
+		}
+		policy.Labels["app.kubernetes.io/managed-by"] = "code-review-operator"
+		policy.Spec = policySpec(m.PodLabels, destinations)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error applying network policy %s: %w", m.Name, err)
+	}
+
+	return nil
+}
+
+// apiServer returns the Kubernetes API server as destinations. The endpoints
+// of the kubernetes service are used because policies apply to the addresses
+// traffic is delivered to, not the service IP.
+func (m *PolicyManager) apiServer(ctx context.Context) ([]Destination, error) {
+	var endpoints corev1.Endpoints
+	if err := m.Client.Get(ctx, types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: "kubernetes"}, &endpoints); err != nil {
+		return nil, fmt.Errorf("error getting API server endpoints: %w", err)
+	}
+
+	var destinations []Destination
+	for _, subset := range endpoints.Subsets {
+		addresses := make([]string, 0, len(subset.Addresses))
+		for _, address := range subset.Addresses {
+			addresses = append(addresses, address.IP)
+		}
+		for _, port := range subset.Ports {
+			destinations = append(destinations, Destination{
+				Purpose:   "kubernetes",
+				Host:      "kubernetes.default.svc",
+				Port:      int(port.Port),
+				Addresses: addresses,
+			})
+		}
+	}
+
+	return destinations, nil
+}
@@ -0,0 +1,244 @@
+package git
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/metrics"
+)
+
+// DefaultContentCacheTTL is how long fetched content is cached unless
+// configured otherwise
+const DefaultContentCacheTTL = time.Hour
+
+// ContentCache holds diffs and file contents read at a commit, which never
+// change, so that a review started after they were pre-fetched does not read
+// them again. It evicts the least recently used entries beyond its size.
+type ContentCache struct {
+	maxBytes int64
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	size    int64
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// contentEntry is an entry of a ContentCache. Missing entries record that a
+// file does not exist at a commit.
+type contentEntry struct {
+	key     string
+	value   []byte
+	missing bool
+	expires time.Time
+}
+
+// NewContentCache creates a ContentCache holding up to maxBytes of content
+// for ttl
+func NewContentCache(maxBytes int64, ttl time.Duration) *ContentCache {
+	if ttl <= 0 {
+		ttl = DefaultContentCacheTTL
+	}
+
+	return &ContentCache{
+		maxBytes: maxBytes,
+		ttl:      ttl,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Client returns a client that reads diffs and file contents at a commit
+// through the cache. Scope identifies the provider and credentials of
+// client, so content read with one token is never served to another.
+func (c *ContentCache) Client(client Client, scope string) Client {
+	cached := &cachedClient{Client: client, cache: c, scope: scope}
+	if reader, ok := client.(PullRequestReader); ok {
+		return &cachedReaderClient{cachedClient: cached, reader: reader}
+	}
+
+	return cached
+}
+
+// Len returns the number of cached entries
+func (c *ContentCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// get returns the entry stored under a key, if it has not expired
+func (c *ContentCache) get(key string) (*contentEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*contentEntry)
+	if time.Now().After(entry.expires) {
+		c.remove(element)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+
+	return entry, true
+}
+
+// set stores content, or that it is missing, under a key. Content larger
+// than the whole cache is not stored.
+func (c *ContentCache) set(key string, value []byte, missing bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if int64(len(value)) > c.maxBytes {
+		return
+	}
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	c.entries[key] = c.order.PushFront(&contentEntry{key: key, value: value, missing: missing, expires: time.Now().Add(c.ttl)})
+	c.size += int64(len(value))
+	for c.size > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+}
+
+// remove drops an entry; the caller holds mu
+func (c *ContentCache) remove(element *list.Element) {
+	entry := element.Value.(*contentEntry)
+	c.order.Remove(element)
+	delete(c.entries, entry.key)
+	c.size -= int64(len(entry.value))
+}
+
+// cachedClient reads diffs and file contents at a commit through a
+// ContentCache. Everything else, including content at a branch, which may
+// move, is read from the provider.
+type cachedClient struct {
+	Client
+
+	cache *ContentCache
+	scope string
+}
+
+// GetDiff implements Client. Diffs of a pull request at a commit are cached.
+func (c *cachedClient) GetDiff(ctx context.Context, owner, repo string, prNumber int, commitSHA string) (string, error) {
+	if prNumber <= 0 || !IsCommitSHA(commitSHA) {
+		return c.Client.GetDiff(ctx, owner, repo, prNumber, commitSHA)
+	}
+
+	key := c.key("diff", owner, repo, fmt.Sprint(prNumber), commitSHA)
+	if entry, ok := c.lookup(key); ok {
+		return string(entry.value), nil
+	}
+	diff, err := c.Client.GetDiff(ctx, owner, repo, prNumber, commitSHA)
+	if err != nil {
+		return "", err
+	}
+	c.cache.set(key, []byte(diff), false)
+
+	return diff, nil
+}
+
+// GetCompareDiff implements Client. Diffs between two commits are cached.
+func (c *cachedClient) GetCompareDiff(ctx context.Context, owner, repo, base, head string) (string, error) {
+	if !IsCommitSHA(base) || !IsCommitSHA(head) {
+		return c.Client.GetCompareDiff(ctx, owner, repo, base, head)
+	}
+
+	key := c.key("compare", owner, repo, base, head)
+	if entry, ok := c.lookup(key); ok {
+		return string(entry.value), nil
+	}
+	diff, err := c.Client.GetCompareDiff(ctx, owner, repo, base, head)
+	if err != nil {
+		return "", err
+	}
+	c.cache.set(key, []byte(diff), false)
+
+	return diff, nil
+}
+
+// GetFileContent implements Client. Contents at a commit are cached, as is
+// the absence of a file.
+func (c *cachedClient) GetFileContent(ctx context.Context, owner, repo, path, ref string) ([]byte, error) {
+	if !IsCommitSHA(ref) {
+		return c.Client.GetFileContent(ctx, owner, repo, path, ref)
+	}
+
+	key := c.key("file", owner, repo, ref, path)
+	if entry, ok := c.lookup(key); ok {
+		if entry.missing {
+			return nil, ErrResourceNotFound
+		}
+		return entry.value, nil
+	}
+	data, err := c.Client.GetFileContent(ctx, owner, repo, path, ref)
+	switch {
+	case errors.Is(err, ErrResourceNotFound):
+		c.cache.set(key, nil, true)
+		return nil, err
+	case err != nil:
+		return nil, err
+	}
+	c.cache.set(key, data, false)
+
+	return data, nil
+}
+
+// lookup returns the entry under a key and counts the lookup
+func (c *cachedClient) lookup(key string) (*contentEntry, bool) {
+	entry, ok := c.cache.get(key)
+	if ok {
+		metrics.GitCacheLookups.WithLabelValues(c.GetProviderName(), metrics.CacheHit).Inc()
+	} else {
+		metrics.GitCacheLookups.WithLabelValues(c.GetProviderName(), metrics.CacheMiss).Inc()
+	}
+
+	return entry, ok
+}
+
+// key builds the cache key of a kind of content
+func (c *cachedClient) key(kind, owner, repo string, parts ...string) string {
+	key := fmt.Sprintf("%s\x00%s\x00%s/%s", c.scope, kind, owner, repo)
+	for _, part := range parts {
+		key += "\x00" + part
+	}
+
+	return key
+}
+
+// cachedReaderClient is a cachedClient of a client that implements
+// PullRequestReader. Pull requests are not cached, as they change.
+type cachedReaderClient struct {
+	*cachedClient
+
+	reader PullRequestReader
+}
+
+// GetPullRequest implements PullRequestReader
+func (c *cachedReaderClient) GetPullRequest(ctx context.Context, owner, repo string, number int) (*PullRequest, error) {
+	return c.reader.GetPullRequest(ctx, owner, repo, number)
+}
+
+// IsCommitSHA reports whether ref is a full commit SHA, as opposed to a
+// branch, a tag or an abbreviated SHA
+func IsCommitSHA(ref string) bool {
+	if len(ref) != 40 && len(ref) != 64 {
+		return false
+	}
+	for _, r := range ref {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
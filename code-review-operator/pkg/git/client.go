@@ -2,95 +2,556 @@ package git
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
 )
 
 // ReviewComment represents a comment to be posted to a Git provider
 type ReviewComment struct {
 	// File is the path to the file being commented on
 	File string
-	
-	// Line is the line number to comment on
+
+	// Line is the line number to comment on, or the last line of a range
 	Line int
-	
+
+	// StartLine is the first line of a comment spanning several lines; 0 for
+	// a single-line comment
+	StartLine int
+
+	// Side is the version of the file the lines refer to: LEFT for the old
+	// version, RIGHT (the default) for the new version
+	Side string
+
+	// StartSide is the version of the file StartLine refers to, when a range
+	// starts on a removed line and ends on the new version; empty if it is
+	// Side
+	StartSide string
+
+	// OldPath is the path to the file in the old version, if it was renamed
+	OldPath string
+
+	// OldLine and NewLine are the numbers of Line in the old and new versions
+	// of the file, 0 if it is not part of that version: unchanged lines have
+	// both. They are set once the comment is placed on the diff.
+	OldLine int
+	NewLine int
+
 	// Content is the text of the comment
 	Content string
-	
+
 	// Severity is the severity level (critical, major, minor, suggestion)
 	Severity string
-	
+
+	// ReportedSeverity is the severity the finding was reported with, when
+	// Severity was raised because of the branch the pull request targets
+	ReportedSeverity string
+
+	// Escalation explains why Severity was raised, if it was
+	Escalation string
+
 	// Rule is the rule that triggered this comment
 	Rule string
+
+	// Language is the language of the commented file, such as go or
+	// terraform; empty if it is not known
+	Language string
+
+	// SuggestedCode is optional replacement code for the commented lines.
+	// Providers with native suggestions let developers apply it in one click.
+	SuggestedCode string
+
+	// Link is a permalink to the commented lines in the pull request's diff,
+	// set once the comment's position is final
+	Link string
+}
+
+// IsRange reports whether the comment spans several lines
+func (c ReviewComment) IsRange() bool {
+	if c.StartLine <= 0 {
+		return false
+	}
+	if c.StartSide != "" && c.StartSide != c.Side {
+		return true
+	}
+
+	return c.StartLine < c.Line
+}
+
+// Location formats the file and line, or line range, of the comment
+func (c ReviewComment) Location() string {
+	if c.IsRange() {
+		return fmt.Sprintf("%s:%d-%d", c.File, c.StartLine, c.Line)
+	}
+
+	return fmt.Sprintf("%s:%d", c.File, c.Line)
+}
+
+// SuggestionBlock renders suggested code as a fenced code block, for
+// providers without native suggestions
+func SuggestionBlock(code string) string {
+	return "\n\nSuggested change:\n```\n" + strings.TrimSuffix(code, "\n") + "\n```"
+}
+
+// commentMarkerPrefix starts the hidden marker in comments posted by the operator
+const commentMarkerPrefix = "<!-- code-review-operator"
+
+// CommentMarker returns the hidden marker appended to posted comments. It
+// identifies the operator's comments and records the rule they were made for
+// and, when known, the finding's severity.
+func CommentMarker(rule, severity string) string {
+	marker := fmt.Sprintf("%s rule=%q", commentMarkerPrefix, rule)
+	if severity != "" {
+		marker += fmt.Sprintf(" severity=%q", severity)
+	}
+
+	return marker + " -->"
+}
+
+// ParseCommentMarker returns the rule recorded in a comment body, and whether
+// the comment was posted by the operator
+func ParseCommentMarker(body string) (string, bool) {
+	i := strings.LastIndex(body, commentMarkerPrefix)
+	if i < 0 {
+		return "", false
+	}
+
+	return markerAttribute(body[i:], "rule"), true
+}
+
+// StripCommentMarker returns a comment body without its hidden marker
+func StripCommentMarker(body string) string {
+	i := strings.LastIndex(body, commentMarkerPrefix)
+	if i < 0 {
+		return body
+	}
+
+	return strings.TrimSpace(body[:i])
+}
+
+// CommentSeverity returns the severity recorded in the marker of a comment
+// body, or an empty string for comments posted before severities were recorded
+func CommentSeverity(body string) string {
+	i := strings.LastIndex(body, commentMarkerPrefix)
+	if i < 0 {
+		return ""
+	}
+
+	return markerAttribute(body[i:], "severity")
+}
+
+// CommandMarker returns the hidden marker appended to the operator's answer to
+// a slash command, recording the ID of the comment holding the command
+func CommandMarker(commentID string) string {
+	return fmt.Sprintf("%s command=%q -->", commentMarkerPrefix, commentID)
+}
+
+// ParseCommandMarker returns the ID of the comment whose slash command a
+// comment body answers, or an empty string for other comments
+func ParseCommandMarker(body string) string {
+	i := strings.LastIndex(body, commentMarkerPrefix)
+	if i < 0 {
+		return ""
+	}
+
+	return markerAttribute(body[i:], "command")
+}
+
+// markerAttribute returns the value of a quoted attribute of a comment marker
+func markerAttribute(marker, name string) string {
+	if end := strings.Index(marker, "-->"); end >= 0 {
+		marker = marker[:end]
+	}
+	i := strings.Index(marker, " "+name+"=")
+	if i < 0 {
+		return ""
+	}
+	quoted, err := strconv.QuotedPrefix(marker[i+len(name)+2:])
+	if err != nil {
+		return ""
+	}
+	value, _ := strconv.Unquote(quoted)
+
+	return value
+}
+
+// DiffLine locates commented lines in a pull request's diff
+type DiffLine struct {
+	// File is the path to the file
+	File string
+
+	// Side is the version of the file the line numbers refer to (LEFT or RIGHT)
+	Side string
+
+	// StartLine is the first line of a range; 0 for a single line
+	StartLine int
+
+	// Line is the line, or the last line of a range
+	Line int
+
+	// OldLine and NewLine are the numbers of Line in the old and new versions
+	// of the file; 0 if it is not part of that version
+	OldLine int
+	NewLine int
+}
+
+// PostedComment is a review comment already on a pull request
+type PostedComment struct {
+	// ID identifies the comment
+	ID string
+
+	// InReplyTo is the ID of the comment this one replies to, empty for the
+	// first comment of a thread
+	InReplyTo string
+
+	// File is the path to the file commented on
+	File string
+
+	// Line is the line the comment is on in the current diff, or the last
+	// line of a range. It is 0 once the commented lines have changed and the
+	// comment is outdated.
+	Line int
+
+	// StartLine is the first line of a comment spanning several lines, 0 for
+	// a single-line comment
+	StartLine int
+
+	// Side is the version of the file the line refers to (LEFT or RIGHT)
+	Side string
+
+	// Body is the text of the comment
+	Body string
+
+	// Author is the login of the comment author
+	Author string
+}
+
+// Outdated reports whether the commented lines have changed since the comment was posted
+func (c PostedComment) Outdated() bool {
+	return c.Line == 0
+}
+
+// IssueComment is a comment on the conversation of a pull request, outside
+// its diff
+type IssueComment struct {
+	// ID identifies the comment
+	ID string
+
+	// Body is the text of the comment
+	Body string
+
+	// Author is the login of the comment author
+	Author string
 }
 
+// Issue is an issue of a repository, such as one a pull request closes
+type Issue struct {
+	// Number is the issue number
+	Number int
+
+	// Title is the title of the issue
+	Title string
+
+	// Body is the description of the issue
+	Body string
+
+	// State is open or closed
+	State string
+
+	// PullRequest is true when the issue is a pull request
+	PullRequest bool
+
+	// URL is the URL to the issue
+	URL string
+}
+
+// ReviewDecision is the event a review is posted with
+type ReviewDecision string
+
+// Review decisions
+const (
+	// ReviewDecisionComment posts the review without approving or blocking
+	ReviewDecisionComment ReviewDecision = "COMMENT"
+
+	// ReviewDecisionApprove approves the pull request
+	ReviewDecisionApprove ReviewDecision = "APPROVE"
+
+	// ReviewDecisionRequestChanges requests changes to the pull request
+	ReviewDecisionRequestChanges ReviewDecision = "REQUEST_CHANGES"
+)
+
 // Repository represents a Git repository
 type Repository struct {
 	// Owner is the owner/organization of the repository
 	Owner string
-	
+
 	// Name is the name of the repository
 	Name string
-	
+
 	// FullName is the full name (owner/name)
 	FullName string
-	
+
 	// URL is the URL to the repository
 	URL string
 }
 
 // PullRequest represents a Git pull request
 type PullRequest struct {
+	// Owner and Repository identify the repository of the PR
+	Owner      string
+	Repository string
+
 	// Number is the PR number
 	Number int
-	
+
 	// Title is the title of the PR
 	Title string
-	
+
 	// BaseBranch is the target branch of the PR
 	BaseBranch string
-	
+
 	// HeadBranch is the source branch of the PR
 	HeadBranch string
-	
+
+	// HeadSHA is the head commit of the PR
+	HeadSHA string
+
+	// Body is the description of the PR
+	Body string
+
+	// Labels are the names of the PR's labels
+	Labels []string
+
+	// ChangedFiles are the files the PR changes, set by clients implementing
+	// PullRequestReader
+	ChangedFiles []ChangedFile
+
 	// URL is the URL to the PR
 	URL string
 }
 
+// ChangedFile is a file changed by a pull request
+type ChangedFile struct {
+	// Path is the path to the file
+	Path string
+
+	// Additions and Deletions are the numbers of added and removed lines
+	Additions int
+	Deletions int
+}
+
+// PullRequestReader is implemented by clients that read a single pull
+// request, with its changed files, without listing every open pull request
+type PullRequestReader interface {
+	// GetPullRequest gets a pull request, open or not. It returns
+	// ErrResourceNotFound if the pull request does not exist.
+	GetPullRequest(ctx context.Context, owner, repo string, number int) (*PullRequest, error)
+}
+
+// GetPullRequest gets a pull request with client. Clients that do not
+// implement PullRequestReader list the open pull requests, so
+// ErrResourceNotFound is also returned for pull requests that are closed.
+func GetPullRequest(ctx context.Context, client Client, owner, repo string, number int) (*PullRequest, error) {
+	if reader, ok := client.(PullRequestReader); ok {
+		return reader.GetPullRequest(ctx, owner, repo, number)
+	}
+
+	pullRequests, err := client.GetPullRequests(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pullRequests {
+		if pullRequests[i].Number == number {
+			return &pullRequests[i], nil
+		}
+	}
+
+	return nil, ErrResourceNotFound
+}
+
+// CheckRun conclusions
+const (
+	CheckConclusionSuccess        = "success"
+	CheckConclusionFailure        = "failure"
+	CheckConclusionNeutral        = "neutral"
+	CheckConclusionActionRequired = "action_required"
+)
+
+// CheckRun represents a completed check reported against a commit
+type CheckRun struct {
+	// Name is the name of the check
+	Name string
+
+	// HeadSHA is the commit the check is reported against
+	HeadSHA string
+
+	// Conclusion is the final result of the check (success, failure, neutral, action_required)
+	Conclusion string
+
+	// Title is the title of the check output
+	Title string
+
+	// Summary is the markdown summary of the check output
+	Summary string
+
+	// Text is the optional markdown details of the check output
+	Text string
+
+	// DetailsURL is an optional link to more information
+	DetailsURL string
+}
+
+// Commit status states
+const (
+	CommitStatusPending = "pending"
+	CommitStatusSuccess = "success"
+	CommitStatusFailure = "failure"
+	CommitStatusError   = "error"
+)
+
+// CommitStatus represents a status reported against a commit, for providers
+// without check runs
+type CommitStatus struct {
+	// Context is the name of the status
+	Context string
+
+	// State is the state of the status (pending, success, failure, error)
+	State string
+
+	// Description is a short, single-line description
+	Description string
+
+	// TargetURL is an optional link to more information
+	TargetURL string
+}
+
+// RateLimit describes the API quota reported by a Git provider
+type RateLimit struct {
+	// Limit is the maximum number of requests allowed in the current window
+	Limit int
+
+	// Remaining is the number of requests left in the current window
+	Remaining int
+
+	// Reset is the time at which the current window resets
+	Reset time.Time
+}
+
+// RateLimitReporter is implemented by clients that track provider rate limits
+type RateLimitReporter interface {
+	// RateLimit returns the most recently observed rate limit
+	RateLimit() RateLimit
+}
+
 // Client defines the interface for Git provider clients
 type Client interface {
 	// GetDiff gets the code diff for a pull request or commit
 	GetDiff(ctx context.Context, owner, repo string, prNumber int, commitSHA string) (string, error)
-	
-	// PostReview posts review comments to a pull request
-	PostReview(ctx context.Context, owner, repo string, prNumber int, comments []ReviewComment, summary string) (string, error)
-	
+
+	// GetCompareDiff gets the diff between two commits. It returns
+	// ErrResourceNotFound if either commit no longer exists.
+	GetCompareDiff(ctx context.Context, owner, repo, base, head string) (string, error)
+
+	// PostReview posts review comments to a pull request with the given
+	// decision. The comments are placed on the diff at commitSHA, the commit
+	// that was reviewed, or at the pull request's head when it is empty.
+	PostReview(ctx context.Context, owner, repo string, prNumber int, commitSHA string, comments []ReviewComment, summary string, decision ReviewDecision) (string, error)
+
+	// ListReviewComments gets the review comments on a pull request, including replies
+	ListReviewComments(ctx context.Context, owner, repo string, prNumber int) ([]PostedComment, error)
+
+	// ReplyToReviewComment replies in the thread of a review comment
+	ReplyToReviewComment(ctx context.Context, owner, repo string, prNumber int, commentID, body string) error
+
+	// ResolveReviewThread marks the thread started by a review comment as resolved
+	ResolveReviewThread(ctx context.Context, owner, repo string, prNumber int, commentID string) error
+
+	// DeleteReviewComment deletes a review comment
+	DeleteReviewComment(ctx context.Context, owner, repo string, prNumber int, commentID string) error
+
+	// PostComment posts a comment on the conversation of a pull request and returns its ID
+	PostComment(ctx context.Context, owner, repo string, prNumber int, body string) (string, error)
+
+	// GetComments gets the comments on the conversation of a pull request, oldest first
+	GetComments(ctx context.Context, owner, repo string, prNumber int) ([]IssueComment, error)
+
 	// GetRepositories gets the list of repositories for an organization or user
 	GetRepositories(ctx context.Context, owner string) ([]Repository, error)
-	
+
 	// GetPullRequests gets the list of open pull requests for a repository
 	GetPullRequests(ctx context.Context, owner, repo string) ([]PullRequest, error)
-	
+
+	// FindPullRequestsByLabel gets the open pull requests carrying a label
+	// across the repositories of an organization or user
+	FindPullRequestsByLabel(ctx context.Context, owner, label string) ([]PullRequest, error)
+
+	// GetIssue gets an issue of a repository. It returns ErrResourceNotFound
+	// if the issue does not exist.
+	GetIssue(ctx context.Context, owner, repo string, number int) (*Issue, error)
+
+	// GetFileContent gets the raw content of a file at the given ref (default branch if empty)
+	GetFileContent(ctx context.Context, owner, repo, path, ref string) ([]byte, error)
+
+	// CreateCheckRun reports a completed check against a commit
+	CreateCheckRun(ctx context.Context, owner, repo string, checkRun CheckRun) (string, error)
+
+	// StartCheckRun creates an in-progress check against a commit and returns its ID
+	StartCheckRun(ctx context.Context, owner, repo string, checkRun CheckRun) (string, error)
+
+	// UpdateCheckRun updates the output of a check; a non-empty Conclusion completes it
+	UpdateCheckRun(ctx context.Context, owner, repo, id string, checkRun CheckRun) error
+
+	// SetCommitStatus sets a status on a commit, replacing any earlier status with the same context
+	SetCommitStatus(ctx context.Context, owner, repo, sha string, status CommitStatus) error
+
+	// AddLabels adds labels to a pull request, keeping its existing labels
+	AddLabels(ctx context.Context, owner, repo string, prNumber int, labels []string) error
+
+	// DiffLink returns a permalink to lines in a pull request's diff
+	DiffLink(owner, repo string, prNumber int, line DiffLine) string
+
 	// GetProviderName returns the name of the Git provider
 	GetProviderName() string
 }
 
-// Factory creates Git clients based on provider type
+// Factory creates Git clients based on provider type, and the token sources
+// of credentials other than plain tokens based on their authentication type
 type Factory struct {
-	clients map[string]ClientConstructor
+	clients      map[string]ClientConstructor
+	tokenSources map[string]TokenSourceConstructor
 }
 
 // ClientConstructor is a function that creates a Git client
 type ClientConstructor func(tokenSource TokenSource) (Client, error)
 
+// TokenSourceConstructor is a function that creates a token source for a
+// provider from the Secret holding the credentials, key being the key of
+// the Secret the credentials are referenced by
+type TokenSourceConstructor func(provider string, secret *corev1.Secret, key string) (TokenSource, error)
+
 // TokenSource provides authentication tokens for Git providers
 type TokenSource interface {
 	// Token returns the current token
 	Token() (string, error)
 }
 
+// BearerTokenSource is implemented by token sources of OAuth2 access tokens,
+// which providers that have tokens of their own expect as bearer tokens
+type BearerTokenSource interface {
+	TokenSource
+
+	// BearerToken reports whether the tokens must be sent as bearer tokens
+	BearerToken() bool
+}
+
 // NewFactory creates a new Git client factory
 func NewFactory() *Factory {
 	return &Factory{
-		clients: make(map[string]ClientConstructor),
+		clients:      make(map[string]ClientConstructor),
+		tokenSources: make(map[string]TokenSourceConstructor),
 	}
 }
 
@@ -105,10 +566,27 @@ func (f *Factory) Create(providerType string, tokenSource TokenSource) (Client,
 	if !ok {
 		return nil, ErrUnsupportedProvider
 	}
-	
+
 	return constructor(tokenSource)
 }
 
+// RegisterTokenSource registers a token source constructor for an
+// authentication type
+func (f *Factory) RegisterTokenSource(authType string, constructor TokenSourceConstructor) {
+	f.tokenSources[authType] = constructor
+}
+
+// CreateTokenSource creates a token source for a provider from the Secret
+// holding credentials of an authentication type
+func (f *Factory) CreateTokenSource(authType, provider string, secret *corev1.Secret, key string) (TokenSource, error) {
+	constructor, ok := f.tokenSources[authType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAuthType, authType)
+	}
+
+	return constructor(provider, secret, key)
+}
+
 // StaticTokenSource is a simple token source that returns a static token
 type StaticTokenSource struct {
 	token string
@@ -125,29 +603,3 @@ func NewStaticTokenSource(token string) *StaticTokenSource {
 func (s *StaticTokenSource) Token() (string, error) {
 	return s.token, nil
 }
-
-// Error definitions
-var (
-	ErrUnsupportedProvider = NewError("unsupported git provider")
-	ErrAuthenticationFailed = NewError("authentication failed")
-	ErrResourceNotFound = NewError("resource not found")
-	ErrPermissionDenied = NewError("permission denied")
-	ErrInvalidRequest = NewError("invalid request")
-)
-
-// Error represents a git client error
-type Error struct {
-	Message string
-}
-
-// NewError creates a new git error
-func NewError(message string) *Error {
-	return &Error{
-		Message: message,
-	}
-}
-
-// Error implements the error interface
-func (e *Error) Error() string {
-	return e.Message
-}
\ No newline at end of file
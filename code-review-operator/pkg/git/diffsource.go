@@ -0,0 +1,75 @@
+package git
+
+import (
+	"context"
+	"sync"
+)
+
+// DiffLoader loads a pre-computed unified diff, such as an artifact of a CI
+// build
+type DiffLoader func(ctx context.Context) (string, error)
+
+// WithDiff returns a client that serves the diff load returns instead of
+// fetching diffs from the provider. The diff is loaded once, on first use;
+// everything else is read from client.
+func WithDiff(client Client, load DiffLoader) Client {
+	static := &staticDiffClient{Client: client, load: load}
+	if reader, ok := client.(PullRequestReader); ok {
+		return &staticDiffReaderClient{staticDiffClient: static, reader: reader}
+	}
+
+	return static
+}
+
+// staticDiffClient serves a pre-computed diff
+type staticDiffClient struct {
+	Client
+
+	load DiffLoader
+
+	mu     sync.Mutex
+	loaded bool
+	diff   string
+}
+
+// GetDiff implements Client
+func (c *staticDiffClient) GetDiff(ctx context.Context, owner, repo string, prNumber int, commitSHA string) (string, error) {
+	return c.diffOnce(ctx)
+}
+
+// GetCompareDiff implements Client. The pre-computed diff is the only one
+// there is, so it is also served for incremental reviews.
+func (c *staticDiffClient) GetCompareDiff(ctx context.Context, owner, repo, base, head string) (string, error) {
+	return c.diffOnce(ctx)
+}
+
+// diffOnce loads the diff unless it was loaded already. Failed loads are
+// tried again on the next call.
+func (c *staticDiffClient) diffOnce(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.loaded {
+		return c.diff, nil
+	}
+	diff, err := c.load(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.diff, c.loaded = diff, true
+
+	return diff, nil
+}
+
+// staticDiffReaderClient is a staticDiffClient of a client that implements
+// PullRequestReader
+type staticDiffReaderClient struct {
+	*staticDiffClient
+
+	reader PullRequestReader
+}
+
+// GetPullRequest implements PullRequestReader
+func (c *staticDiffReaderClient) GetPullRequest(ctx context.Context, owner, repo string, number int) (*PullRequest, error) {
+	return c.reader.GetPullRequest(ctx, owner, repo, number)
+}
@@ -0,0 +1,142 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Error definitions. Errors from a provider's API match the kind of
+// failure they report with errors.Is.
+var (
+	ErrUnsupportedProvider  = NewError("unsupported git provider")
+	ErrUnsupportedAuthType  = NewError("unsupported authentication type")
+	ErrAuthenticationFailed = NewError("authentication failed")
+	ErrResourceNotFound     = NewError("resource not found")
+	ErrPermissionDenied     = NewError("permission denied")
+	ErrInvalidRequest       = NewError("invalid request")
+	ErrRateLimited          = NewError("rate limit exceeded")
+)
+
+// Error represents a git client error: one of the error definitions above,
+// or a failed request to a provider's API along with what is known about it
+type Error struct {
+	// Message describes the error
+	Message string
+
+	// Provider is the Git provider the request was sent to, empty for the
+	// error definitions
+	Provider string
+
+	// StatusCode is the HTTP status code of the response, 0 if the request
+	// got no response
+	StatusCode int
+
+	// Transient reports whether the request may succeed if retried later:
+	// connection errors, rate limits and server errors
+	Transient bool
+
+	// RetryAfter is how long the provider asked clients to wait before
+	// retrying, if it did
+	RetryAfter time.Duration
+
+	// RateLimit is the provider's rate limit when the request was rejected
+	// for exceeding it
+	RateLimit *RateLimit
+
+	// Kind is the error definition the error is an instance of, if any
+	Kind *Error
+
+	// Err is the underlying error, if any
+	Err error
+}
+
+// NewError creates a new git error
+func NewError(message string) *Error {
+	return &Error{
+		Message: message,
+	}
+}
+
+// NewResponseError creates the error of a provider's API response with a
+// failure status code. The kind of error is derived from the status code;
+// providers signalling rate limits otherwise set Kind to ErrRateLimited.
+func NewResponseError(provider string, resp *http.Response, body []byte) *Error {
+	err := &Error{
+		Message:    string(body),
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		Transient: resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests ||
+			resp.StatusCode >= http.StatusInternalServerError,
+	}
+	switch resp.StatusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		err.Kind = ErrInvalidRequest
+	case http.StatusUnauthorized:
+		err.Kind = ErrAuthenticationFailed
+	case http.StatusForbidden:
+		err.Kind = ErrPermissionDenied
+	case http.StatusNotFound:
+		err.Kind = ErrResourceNotFound
+	case http.StatusTooManyRequests:
+		err.Kind = ErrRateLimited
+	}
+	if seconds, parseErr := strconv.Atoi(resp.Header.Get("Retry-After")); parseErr == nil {
+		err.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	return err
+}
+
+// NewRequestError creates the error of a request to a provider's API that
+// got no response, such as a connection error, which may succeed if retried
+func NewRequestError(provider string, err error) *Error {
+	return &Error{
+		Message:   "error executing request",
+		Provider:  provider,
+		Transient: true,
+		Err:       err,
+	}
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	message := e.Message
+	if message == "" && e.Kind != nil {
+		message = e.Kind.Message
+	}
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+	if e.Err != nil {
+		message += ": " + e.Err.Error()
+	}
+	if e.StatusCode == 0 {
+		return message
+	}
+
+	return fmt.Sprintf("error from %s API: %s (status code: %d)", e.Provider, message, e.StatusCode)
+}
+
+// Unwrap returns the error's kind and underlying error, so errors.Is matches
+// both
+func (e *Error) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+
+	return errs
+}
+
+// IsTransient reports whether an error is a failed request to a provider's
+// API that may succeed if retried later
+func IsTransient(err error) bool {
+	var gitErr *Error
+	return errors.As(err, &gitErr) && gitErr.Transient
+}
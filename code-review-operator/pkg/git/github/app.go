@@ -0,0 +1,240 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+)
+
+const (
+	// SecretKeyAppID is the Secret key holding the GitHub App ID
+	SecretKeyAppID = "app-id"
+
+	// SecretKeyInstallationID is the Secret key holding the GitHub App installation ID
+	SecretKeyInstallationID = "installation-id"
+
+	// SecretKeyPrivateKey is the conventional Secret key holding the PEM
+	// encoded GitHub App private key
+	SecretKeyPrivateKey = "private-key"
+
+	// jwtLifetime is how long an app JWT is valid (GitHub allows at most 10 minutes)
+	jwtLifetime = 9 * time.Minute
+
+	// tokenRefreshSkew is how long before expiry an installation token is refreshed
+	tokenRefreshSkew = 5 * time.Minute
+)
+
+// AppTokenSource is a git.TokenSource that authenticates as a GitHub App
+// installation, exchanging a signed JWT for short-lived installation tokens
+type AppTokenSource struct {
+	appID          int64
+	installationID int64
+	privateKey     *rsa.PrivateKey
+	apiURL         string
+	client         *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewAppTokenSource creates a token source for a GitHub App installation,
+// requesting installation tokens from the REST API of options through the
+// same proxy, trusted authorities and shared HTTP layer as the API client
+func NewAppTokenSource(appID, installationID int64, privateKeyPEM []byte, options Options) (*AppTokenSource, error) {
+	key, err := parsePrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	httpClient, err := options.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	apiURL := DefaultAPIURL
+	if options.BaseURL != "" {
+		apiURL = strings.TrimSuffix(options.BaseURL, "/")
+	}
+
+	return &AppTokenSource{
+		appID:          appID,
+		installationID: installationID,
+		privateKey:     key,
+		apiURL:         apiURL,
+		client:         httpClient,
+	}, nil
+}
+
+// NewAppTokenSourceFromSecret creates a token source from a Kubernetes Secret
+// holding the app ID and installation ID, and the private key in key
+func NewAppTokenSourceFromSecret(secret *corev1.Secret, key string, options Options) (*AppTokenSource, error) {
+	appID, err := secretInt(secret, SecretKeyAppID)
+	if err != nil {
+		return nil, err
+	}
+
+	installationID, err := secretInt(secret, SecretKeyInstallationID)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s is missing key %q", secret.Namespace, secret.Name, key)
+	}
+
+	return NewAppTokenSource(appID, installationID, privateKey, options)
+}
+
+// Token implements git.TokenSource, returning a cached installation token
+// and refreshing it shortly before it expires
+func (s *AppTokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Until(s.expiresAt) > tokenRefreshSkew {
+		return s.token, nil
+	}
+
+	token, expiresAt, err := s.createInstallationToken()
+	if err != nil {
+		return "", err
+	}
+	s.token = token
+	s.expiresAt = expiresAt
+
+	return s.token, nil
+}
+
+// createInstallationToken exchanges an app JWT for an installation access token
+func (s *AppTokenSource) createInstallationToken() (string, time.Time, error) {
+	jwt, err := s.signJWT(time.Now())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.client.Timeout)
+	defer cancel()
+
+	// Create request
+	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", s.apiURL, s.installationID)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", jwt))
+	req.Header.Set("User-Agent", DefaultUserAgent)
+
+	// Execute request
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error requesting installation token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error reading response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", time.Time{}, git.NewResponseError("github", resp, body)
+	}
+
+	// Parse the response
+	var tokenResponse struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.Unmarshal(body, &tokenResponse); err != nil {
+		return "", time.Time{}, fmt.Errorf("error parsing response: %w", err)
+	}
+
+	return tokenResponse.Token, tokenResponse.ExpiresAt, nil
+}
+
+// signJWT creates an RS256 signed JWT identifying the app
+func (s *AppTokenSource) signJWT(now time.Time) (string, error) {
+	header := map[string]string{"alg": "RS256", "typ": "JWT"}
+	claims := map[string]interface{}{
+		// Backdate to allow for clock drift
+		"iat": now.Add(-60 * time.Second).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": strconv.FormatInt(s.appID, 10),
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling JWT header: %w", err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling JWT claims: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("error signing JWT: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey parses a PKCS#1 or PKCS#8 PEM encoded RSA private key
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("error decoding private key: no PEM data found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing private key: %w", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("error parsing private key: not an RSA key")
+	}
+
+	return rsaKey, nil
+}
+
+// secretInt reads an integer value from a Secret
+func secretInt(secret *corev1.Secret, key string) (int64, error) {
+	value, ok := secret.Data[key]
+	if !ok {
+		return 0, fmt.Errorf("secret %s/%s is missing key %q", secret.Namespace, secret.Name, key)
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSpace(string(value)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing %q from secret %s/%s: %w", key, secret.Namespace, secret.Name, err)
+	}
+
+	return n, nil
+}
@@ -3,20 +3,28 @@ package github
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	neturl "net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
 	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/metrics"
 )
 
 const (
 	// DefaultAPIURL is the default GitHub API URL
 	DefaultAPIURL = "https://api.github.com"
-	
+
 	// DefaultUserAgent is the default User-Agent for API requests
 	DefaultUserAgent = "CodeReviewOperator/1.0"
 )
@@ -25,26 +33,27 @@ const (
 type Client struct {
 	client    *http.Client
 	apiURL    string
+	uploadURL string
 	userAgent string
 	token     git.TokenSource
+
+	maxRetries  int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+
+	rateMu    sync.RWMutex
+	rateLimit git.RateLimit
 }
 
-// NewClient creates a new GitHub client
+// NewClient creates a new GitHub client for github.com
 func NewClient(token git.TokenSource) (git.Client, error) {
-	return &Client{
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		apiURL:    DefaultAPIURL,
-		userAgent: DefaultUserAgent,
-		token:     token,
-	}, nil
+	return NewClientWithOptions(token, Options{})
 }
 
 // GetDiff gets the code diff for a pull request or commit
 func (c *Client) GetDiff(ctx context.Context, owner, repo string, prNumber int, commitSHA string) (string, error) {
 	var url string
-	
+
 	if prNumber > 0 {
 		// Get diff for a pull request
 		url = fmt.Sprintf("%s/repos/%s/%s/pulls/%d", c.apiURL, owner, repo, prNumber)
@@ -54,92 +63,431 @@ func (c *Client) GetDiff(ctx context.Context, owner, repo string, prNumber int,
 	} else {
 		return "", fmt.Errorf("either prNumber or commitSHA must be provided")
 	}
-	
+
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return "", fmt.Errorf("error creating request: %w", err)
 	}
-	
+
 	// Set headers for diff format
 	req.Header.Set("Accept", "application/vnd.github.v3.diff")
-	
+
 	// Execute request
 	diff, err := c.doRequest(req)
 	if err != nil {
 		return "", fmt.Errorf("error getting diff: %w", err)
 	}
-	
+
 	return diff, nil
 }
 
-// PostReview posts review comments to a pull request
-func (c *Client) PostReview(ctx context.Context, owner, repo string, prNumber int, comments []git.ReviewComment, summary string) (string, error) {
+// GetCompareDiff gets the diff between two commits
+func (c *Client) GetCompareDiff(ctx context.Context, owner, repo, base, head string) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/compare/%s...%s", c.apiURL, owner, repo, base, head)
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+
+	// Set headers for diff format
+	req.Header.Set("Accept", "application/vnd.github.v3.diff")
+
+	// Execute request
+	diff, err := c.doRequest(req)
+	if err != nil {
+		return "", fmt.Errorf("error getting compare diff: %w", err)
+	}
+
+	return diff, nil
+}
+
+// PostReview posts review comments to a pull request. Pinning the review to
+// the reviewed commit keeps its comments on the lines they were written for
+// when the pull request has been pushed to since.
+func (c *Client) PostReview(ctx context.Context, owner, repo string, prNumber int, commitSHA string, comments []git.ReviewComment, summary string, decision git.ReviewDecision) (string, error) {
+	if decision == "" {
+		decision = git.ReviewDecisionComment
+	}
+
 	// GitHub API requires a different format for review comments
 	githubComments := make([]map[string]interface{}, 0, len(comments))
-	
+
 	for _, comment := range comments {
+		side := comment.Side
+		if side == "" {
+			side = "RIGHT"
+		}
 		githubComment := map[string]interface{}{
 			"path": comment.File,
 			"line": comment.Line,
+			"side": side,
 			"body": formatCommentBody(comment),
 		}
+		if comment.IsRange() {
+			startSide := comment.StartSide
+			if startSide == "" {
+				startSide = side
+			}
+			githubComment["start_line"] = comment.StartLine
+			githubComment["start_side"] = startSide
+		}
 		githubComments = append(githubComments, githubComment)
 	}
-	
+
 	// Create the review request body
 	requestBody := map[string]interface{}{
-		"commit_id": "", // Will be filled by API
-		"body":      summary,
-		"event":     "COMMENT", // Can be APPROVE, REQUEST_CHANGES, or COMMENT
-		"comments":  githubComments,
+		"body":     summary,
+		"event":    decision,
+		"comments": githubComments,
 	}
-	
+	if commitSHA != "" {
+		requestBody["commit_id"] = commitSHA
+	}
+
 	// Marshal the request body
 	jsonBody, err := json.Marshal(requestBody)
 	if err != nil {
 		return "", fmt.Errorf("error marshaling review: %w", err)
 	}
-	
+
 	// Create the request
 	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/reviews", c.apiURL, owner, repo, prNumber)
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return "", fmt.Errorf("error creating request: %w", err)
 	}
-	
+
 	// Execute the request
 	response, err := c.doRequest(req)
 	if err != nil {
 		return "", fmt.Errorf("error posting review: %w", err)
 	}
-	
+
 	// Parse the response to get the review URL
 	var reviewResponse map[string]interface{}
 	if err := json.Unmarshal([]byte(response), &reviewResponse); err != nil {
 		return "", fmt.Errorf("error parsing response: %w", err)
 	}
-	
+
 	// Return the HTML URL of the review
 	if htmlURL, ok := reviewResponse["html_url"].(string); ok {
 		return htmlURL, nil
 	}
-	
+
 	// Return a generic URL if html_url is not found
 	return fmt.Sprintf("https://github.com/%s/%s/pull/%d", owner, repo, prNumber), nil
 }
 
+// reviewCommentsPerPage is the page size used when listing review comments
+const reviewCommentsPerPage = 100
+
+// ListReviewComments gets the review comments on a pull request, including replies
+func (c *Client) ListReviewComments(ctx context.Context, owner, repo string, prNumber int) ([]git.PostedComment, error) {
+	var comments []git.PostedComment
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/comments?per_page=%d&page=%d",
+			c.apiURL, owner, repo, prNumber, reviewCommentsPerPage, page)
+
+		// Create request
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error creating request: %w", err)
+		}
+
+		// Execute request
+		response, err := c.doRequest(req)
+		if err != nil {
+			return nil, fmt.Errorf("error listing review comments: %w", err)
+		}
+
+		// Parse the response; line is null once the comment is outdated
+		var githubComments []struct {
+			ID          int64  `json:"id"`
+			InReplyToID int64  `json:"in_reply_to_id"`
+			Path        string `json:"path"`
+			Line        int    `json:"line"`
+			StartLine   int    `json:"start_line"`
+			Side        string `json:"side"`
+			Body        string `json:"body"`
+			User        struct {
+				Login string `json:"login"`
+			} `json:"user"`
+		}
+		if err := json.Unmarshal([]byte(response), &githubComments); err != nil {
+			return nil, fmt.Errorf("error parsing response: %w", err)
+		}
+
+		for _, comment := range githubComments {
+			posted := git.PostedComment{
+				ID:        strconv.FormatInt(comment.ID, 10),
+				File:      comment.Path,
+				Line:      comment.Line,
+				StartLine: comment.StartLine,
+				Side:      comment.Side,
+				Body:      comment.Body,
+				Author:    comment.User.Login,
+			}
+			if comment.InReplyToID != 0 {
+				posted.InReplyTo = strconv.FormatInt(comment.InReplyToID, 10)
+			}
+			comments = append(comments, posted)
+		}
+		if len(githubComments) < reviewCommentsPerPage {
+			return comments, nil
+		}
+	}
+}
+
+// ReplyToReviewComment replies in the thread of a review comment
+func (c *Client) ReplyToReviewComment(ctx context.Context, owner, repo string, prNumber int, commentID, body string) error {
+	jsonBody, err := json.Marshal(map[string]interface{}{"body": body})
+	if err != nil {
+		return fmt.Errorf("error marshaling reply: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/comments/%s/replies", c.apiURL, owner, repo, prNumber, commentID)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	if _, err := c.doRequest(req); err != nil {
+		return fmt.Errorf("error replying to review comment: %w", err)
+	}
+
+	return nil
+}
+
+// reviewThreadsQuery lists the review threads of a pull request with the
+// database ID of the comment starting each thread
+const reviewThreadsQuery = `query($owner: String!, $repo: String!, $number: Int!, $cursor: String) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      reviewThreads(first: 100, after: $cursor) {
+        nodes {
+          id
+          isResolved
+          comments(first: 1) { nodes { databaseId } }
+        }
+        pageInfo { hasNextPage endCursor }
+      }
+    }
+  }
+}`
+
+// resolveThreadMutation resolves a review thread
+const resolveThreadMutation = `mutation($thread: ID!) {
+  resolveReviewThread(input: {threadId: $thread}) { thread { id } }
+}`
+
+// ResolveReviewThread marks the thread started by a review comment as
+// resolved. Threads are only exposed by the GraphQL API, so the thread is
+// looked up among the pull request's threads by its first comment.
+func (c *Client) ResolveReviewThread(ctx context.Context, owner, repo string, prNumber int, commentID string) error {
+	id, err := strconv.ParseInt(commentID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid review comment ID %q: %w", commentID, err)
+	}
+
+	var cursor *string
+	for {
+		var result struct {
+			Repository struct {
+				PullRequest struct {
+					ReviewThreads struct {
+						Nodes []struct {
+							ID         string `json:"id"`
+							IsResolved bool   `json:"isResolved"`
+							Comments   struct {
+								Nodes []struct {
+									DatabaseID int64 `json:"databaseId"`
+								} `json:"nodes"`
+							} `json:"comments"`
+						} `json:"nodes"`
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+					} `json:"reviewThreads"`
+				} `json:"pullRequest"`
+			} `json:"repository"`
+		}
+		variables := map[string]interface{}{"owner": owner, "repo": repo, "number": prNumber, "cursor": cursor}
+		if err := c.graphQL(ctx, reviewThreadsQuery, variables, &result); err != nil {
+			return fmt.Errorf("error listing review threads: %w", err)
+		}
+
+		threads := result.Repository.PullRequest.ReviewThreads
+		for _, thread := range threads.Nodes {
+			if len(thread.Comments.Nodes) == 0 || thread.Comments.Nodes[0].DatabaseID != id {
+				continue
+			}
+			if thread.IsResolved {
+				return nil
+			}
+			if err := c.graphQL(ctx, resolveThreadMutation, map[string]interface{}{"thread": thread.ID}, nil); err != nil {
+				return fmt.Errorf("error resolving review thread: %w", err)
+			}
+			return nil
+		}
+		if !threads.PageInfo.HasNextPage {
+			return git.ErrResourceNotFound
+		}
+		cursor = &threads.PageInfo.EndCursor
+	}
+}
+
+// DeleteReviewComment deletes a review comment
+func (c *Client) DeleteReviewComment(ctx context.Context, owner, repo string, prNumber int, commentID string) error {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/comments/%s", c.apiURL, owner, repo, commentID)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	if _, err := c.doRequest(req); err != nil {
+		return fmt.Errorf("error deleting review comment: %w", err)
+	}
+
+	return nil
+}
+
+// PostComment posts a comment on the conversation of a pull request
+func (c *Client) PostComment(ctx context.Context, owner, repo string, prNumber int, body string) (string, error) {
+	jsonBody, err := json.Marshal(map[string]interface{}{"body": body})
+	if err != nil {
+		return "", fmt.Errorf("error marshaling comment: %w", err)
+	}
+
+	// Pull requests share the comments API of issues
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/comments", c.apiURL, owner, repo, prNumber)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+
+	response, err := c.doRequest(req)
+	if err != nil {
+		return "", fmt.Errorf("error posting comment: %w", err)
+	}
+
+	var comment struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(response), &comment); err != nil {
+		return "", fmt.Errorf("error parsing response: %w", err)
+	}
+
+	return strconv.FormatInt(comment.ID, 10), nil
+}
+
+// GetComments gets the comments on the conversation of a pull request
+func (c *Client) GetComments(ctx context.Context, owner, repo string, prNumber int) ([]git.IssueComment, error) {
+	var comments []git.IssueComment
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/comments?per_page=%d&page=%d",
+			c.apiURL, owner, repo, prNumber, reviewCommentsPerPage, page)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error creating request: %w", err)
+		}
+
+		response, err := c.doRequest(req)
+		if err != nil {
+			return nil, fmt.Errorf("error listing comments: %w", err)
+		}
+
+		var githubComments []struct {
+			ID   int64  `json:"id"`
+			Body string `json:"body"`
+			User struct {
+				Login string `json:"login"`
+			} `json:"user"`
+		}
+		if err := json.Unmarshal([]byte(response), &githubComments); err != nil {
+			return nil, fmt.Errorf("error parsing response: %w", err)
+		}
+
+		for _, comment := range githubComments {
+			comments = append(comments, git.IssueComment{
+				ID:     strconv.FormatInt(comment.ID, 10),
+				Body:   comment.Body,
+				Author: comment.User.Login,
+			})
+		}
+		if len(githubComments) < reviewCommentsPerPage {
+			return comments, nil
+		}
+	}
+}
+
+// graphQL sends a GraphQL query and decodes its data into result, if not nil
+func (c *Client) graphQL(ctx context.Context, query string, variables map[string]interface{}, result interface{}) error {
+	jsonBody, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return fmt.Errorf("error marshaling query: %w", err)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", c.graphQLURL(), bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send the request
+	response, err := c.doRequest(req)
+	if err != nil {
+		return err
+	}
+
+	// GraphQL reports errors in the body of successful responses
+	var graphQLResponse struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(response), &graphQLResponse); err != nil {
+		return fmt.Errorf("error parsing response: %w", err)
+	}
+	if len(graphQLResponse.Errors) > 0 {
+		return fmt.Errorf("GraphQL error: %s", graphQLResponse.Errors[0].Message)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(graphQLResponse.Data, result); err != nil {
+		return fmt.Errorf("error parsing response: %w", err)
+	}
+
+	return nil
+}
+
+// graphQLURL returns the URL of the GraphQL API for the API URL
+func (c *Client) graphQLURL() string {
+	if c.apiURL == DefaultAPIURL {
+		return DefaultAPIURL + "/graphql"
+	}
+
+	// GitHub Enterprise Server serves GraphQL under /api/graphql
+	return c.webURL() + "/api/graphql"
+}
+
 // GetRepositories gets the list of repositories for an organization or user
 func (c *Client) GetRepositories(ctx context.Context, owner string) ([]git.Repository, error) {
 	// Determine if owner is an organization or user
 	url := fmt.Sprintf("%s/users/%s/repos", c.apiURL, owner)
-	
+
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
-	
+
 	// Execute request
 	response, err := c.doRequest(req)
 	if err != nil {
@@ -149,33 +497,33 @@ func (c *Client) GetRepositories(ctx context.Context, owner string) ([]git.Repos
 		if err != nil {
 			return nil, fmt.Errorf("error creating request: %w", err)
 		}
-		
+
 		response, err = c.doRequest(req)
 		if err != nil {
 			return nil, fmt.Errorf("error getting repositories: %w", err)
 		}
 	}
-	
+
 	// Parse the response
 	var githubRepos []map[string]interface{}
 	if err := json.Unmarshal([]byte(response), &githubRepos); err != nil {
 		return nil, fmt.Errorf("error parsing response: %w", err)
 	}
-	
+
 	// Convert to our Repository type
 	repos := make([]git.Repository, 0, len(githubRepos))
 	for _, repo := range githubRepos {
 		name, _ := repo["name"].(string)
 		fullName, _ := repo["full_name"].(string)
 		url, _ := repo["html_url"].(string)
-		
+
 		// Extract owner from full_name
 		parts := strings.Split(fullName, "/")
 		repoOwner := ""
 		if len(parts) >= 2 {
 			repoOwner = parts[0]
 		}
-		
+
 		repos = append(repos, git.Repository{
 			Owner:    repoOwner,
 			Name:     name,
@@ -183,115 +531,495 @@ func (c *Client) GetRepositories(ctx context.Context, owner string) ([]git.Repos
 			URL:      url,
 		})
 	}
-	
+
 	return repos, nil
 }
 
 // GetPullRequests gets the list of open pull requests for a repository
 func (c *Client) GetPullRequests(ctx context.Context, owner, repo string) ([]git.PullRequest, error) {
 	url := fmt.Sprintf("%s/repos/%s/%s/pulls", c.apiURL, owner, repo)
-	
+
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
-	
+
 	// Execute request
 	response, err := c.doRequest(req)
 	if err != nil {
 		return nil, fmt.Errorf("error getting pull requests: %w", err)
 	}
-	
+
 	// Parse the response
 	var githubPRs []map[string]interface{}
 	if err := json.Unmarshal([]byte(response), &githubPRs); err != nil {
 		return nil, fmt.Errorf("error parsing response: %w", err)
 	}
-	
+
 	// Convert to our PullRequest type
 	prs := make([]git.PullRequest, 0, len(githubPRs))
 	for _, pr := range githubPRs {
 		number, _ := pr["number"].(float64)
 		title, _ := pr["title"].(string)
+		body, _ := pr["body"].(string)
 		url, _ := pr["html_url"].(string)
-		
+		labels := labelNames(pr["labels"])
+
 		// Get base and head branches
 		base, _ := pr["base"].(map[string]interface{})
 		head, _ := pr["head"].(map[string]interface{})
-		
-		var baseBranch, headBranch string
+
+		var baseBranch, headBranch, headSHA string
 		if base != nil {
 			baseBranch, _ = base["ref"].(string)
 		}
 		if head != nil {
 			headBranch, _ = head["ref"].(string)
+			headSHA, _ = head["sha"].(string)
 		}
-		
+
 		prs = append(prs, git.PullRequest{
+			Owner:      owner,
+			Repository: repo,
 			Number:     int(number),
 			Title:      title,
 			BaseBranch: baseBranch,
 			HeadBranch: headBranch,
+			HeadSHA:    headSHA,
+			Body:       body,
+			Labels:     labels,
 			URL:        url,
 		})
 	}
-	
+
 	return prs, nil
 }
 
+// labelNames returns the names of the labels of a pull request or issue
+func labelNames(value interface{}) []string {
+	labels, _ := value.([]interface{})
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		label, _ := label.(map[string]interface{})
+		if name, _ := label["name"].(string); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// FindPullRequestsByLabel gets the open pull requests carrying a label across
+// the repositories of an organization or user, with the search API. Search
+// results carry no branches or head commits.
+func (c *Client) FindPullRequestsByLabel(ctx context.Context, owner, label string) ([]git.PullRequest, error) {
+	query := fmt.Sprintf("is:pr is:open user:%s label:%q", owner, label)
+	url := fmt.Sprintf("%s/search/issues?per_page=100&q=%s", c.apiURL, neturl.QueryEscape(query))
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	response, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("error searching pull requests: %w", err)
+	}
+
+	var results struct {
+		Items []map[string]interface{} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(response), &results); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+
+	prs := make([]git.PullRequest, 0, len(results.Items))
+	for _, item := range results.Items {
+		number, _ := item["number"].(float64)
+		title, _ := item["title"].(string)
+		body, _ := item["body"].(string)
+		url, _ := item["html_url"].(string)
+
+		// The repository is only given by its API URL
+		repositoryURL, _ := item["repository_url"].(string)
+		_, fullName, _ := strings.Cut(repositoryURL, "/repos/")
+		repoOwner, repoName, ok := strings.Cut(fullName, "/")
+		if !ok {
+			continue
+		}
+
+		prs = append(prs, git.PullRequest{
+			Owner:      repoOwner,
+			Repository: repoName,
+			Number:     int(number),
+			Title:      title,
+			Body:       body,
+			Labels:     labelNames(item["labels"]),
+			URL:        url,
+		})
+	}
+
+	return prs, nil
+}
+
+// GetIssue gets an issue of a repository. Pull requests are issues too on
+// GitHub, so a pull request number returns the pull request.
+func (c *Client) GetIssue(ctx context.Context, owner, repo string, number int) (*git.Issue, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d", c.apiURL, owner, repo, number)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	response, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("error getting issue: %w", err)
+	}
+
+	var issue struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		Body    string `json:"body"`
+		State   string `json:"state"`
+		HTMLURL string `json:"html_url"`
+
+		PullRequest *json.RawMessage `json:"pull_request"`
+	}
+	if err := json.Unmarshal([]byte(response), &issue); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+
+	return &git.Issue{
+		Number:      issue.Number,
+		Title:       issue.Title,
+		Body:        issue.Body,
+		State:       issue.State,
+		PullRequest: issue.PullRequest != nil,
+		URL:         issue.HTMLURL,
+	}, nil
+}
+
+// GetFileContent gets the raw content of a file at the given ref
+func (c *Client) GetFileContent(ctx context.Context, owner, repo, path, ref string) ([]byte, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/contents/%s", c.apiURL, owner, repo, strings.TrimPrefix(path, "/"))
+	if ref != "" {
+		url += "?ref=" + neturl.QueryEscape(ref)
+	}
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	// Execute request
+	response, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("error getting file content: %w", err)
+	}
+
+	// Parse the response
+	var content struct {
+		Type     string `json:"type"`
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	if err := json.Unmarshal([]byte(response), &content); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+	if content.Type != "file" {
+		return nil, fmt.Errorf("%s is not a file: %w", path, git.ErrInvalidRequest)
+	}
+	if content.Encoding != "base64" {
+		return []byte(content.Content), nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(content.Content, "\n", ""))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding file content: %w", err)
+	}
+
+	return data, nil
+}
+
+// CreateCheckRun reports a completed check run against a commit
+func (c *Client) CreateCheckRun(ctx context.Context, owner, repo string, checkRun git.CheckRun) (string, error) {
+	// Create the check run request body
+	requestBody := checkRunBody(checkRun)
+	requestBody["name"] = checkRun.Name
+	requestBody["head_sha"] = checkRun.HeadSHA
+	requestBody["status"] = "completed"
+
+	// Execute the request
+	checkResponse, err := c.sendCheckRun(ctx, "POST", fmt.Sprintf("%s/repos/%s/%s/check-runs", c.apiURL, owner, repo), requestBody)
+	if err != nil {
+		return "", fmt.Errorf("error creating check run: %w", err)
+	}
+
+	return checkResponse.HTMLURL, nil
+}
+
+// StartCheckRun creates an in-progress check against a commit and returns its ID
+func (c *Client) StartCheckRun(ctx context.Context, owner, repo string, checkRun git.CheckRun) (string, error) {
+	// Create the check run request body
+	requestBody := checkRunBody(checkRun)
+	requestBody["name"] = checkRun.Name
+	requestBody["head_sha"] = checkRun.HeadSHA
+	requestBody["status"] = "in_progress"
+
+	// Execute the request
+	checkResponse, err := c.sendCheckRun(ctx, "POST", fmt.Sprintf("%s/repos/%s/%s/check-runs", c.apiURL, owner, repo), requestBody)
+	if err != nil {
+		return "", fmt.Errorf("error starting check run: %w", err)
+	}
+
+	return strconv.FormatInt(checkResponse.ID, 10), nil
+}
+
+// UpdateCheckRun updates the output of a check; a non-empty Conclusion completes it
+func (c *Client) UpdateCheckRun(ctx context.Context, owner, repo, id string, checkRun git.CheckRun) error {
+	requestBody := checkRunBody(checkRun)
+	if checkRun.Conclusion != "" {
+		requestBody["status"] = "completed"
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/check-runs/%s", c.apiURL, owner, repo, id)
+	if _, err := c.sendCheckRun(ctx, "PATCH", url, requestBody); err != nil {
+		return fmt.Errorf("error updating check run: %w", err)
+	}
+
+	return nil
+}
+
+// checkRunResponse is the part of a check run response the client uses
+type checkRunResponse struct {
+	ID      int64  `json:"id"`
+	HTMLURL string `json:"html_url"`
+}
+
+// checkRunBody builds the output, conclusion and details URL of a check run request
+func checkRunBody(checkRun git.CheckRun) map[string]interface{} {
+	output := map[string]interface{}{
+		"title":   checkRun.Title,
+		"summary": checkRun.Summary,
+	}
+	if checkRun.Text != "" {
+		output["text"] = checkRun.Text
+	}
+
+	requestBody := map[string]interface{}{
+		"output": output,
+	}
+	if checkRun.Conclusion != "" {
+		requestBody["conclusion"] = checkRun.Conclusion
+	}
+	if checkRun.DetailsURL != "" {
+		requestBody["details_url"] = checkRun.DetailsURL
+	}
+
+	return requestBody
+}
+
+// sendCheckRun sends a check run request and parses the response
+func (c *Client) sendCheckRun(ctx context.Context, method, url string, requestBody map[string]interface{}) (*checkRunResponse, error) {
+	// Marshal the request body
+	jsonBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling check run: %w", err)
+	}
+
+	// Create the request
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	// Execute the request
+	response, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the response
+	var checkResponse checkRunResponse
+	if err := json.Unmarshal([]byte(response), &checkResponse); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+
+	return &checkResponse, nil
+}
+
+// SetCommitStatus sets a status on a commit
+func (c *Client) SetCommitStatus(ctx context.Context, owner, repo, sha string, status git.CommitStatus) error {
+	requestBody := map[string]interface{}{
+		"state":       status.State,
+		"context":     status.Context,
+		"description": truncate(status.Description, maxStatusDescription),
+	}
+	if status.TargetURL != "" {
+		requestBody["target_url"] = status.TargetURL
+	}
+	jsonBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return fmt.Errorf("error marshaling commit status: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/statuses/%s", c.apiURL, owner, repo, sha)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	if _, err := c.doRequest(req); err != nil {
+		return fmt.Errorf("error setting commit status: %w", err)
+	}
+
+	return nil
+}
+
+// AddLabels adds labels to a pull request, keeping its existing labels
+func (c *Client) AddLabels(ctx context.Context, owner, repo string, prNumber int, labels []string) error {
+	jsonBody, err := json.Marshal(map[string]interface{}{"labels": labels})
+	if err != nil {
+		return fmt.Errorf("error marshaling labels: %w", err)
+	}
+
+	// Pull requests share the issues API for labels
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/labels", c.apiURL, owner, repo, prNumber)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	if _, err := c.doRequest(req); err != nil {
+		return fmt.Errorf("error adding labels: %w", err)
+	}
+
+	return nil
+}
+
 // GetProviderName returns the name of the Git provider
 func (c *Client) GetProviderName() string {
 	return "github"
 }
 
-// doRequest executes an HTTP request with proper authentication
+// DiffLink returns a permalink to lines in the "Files changed" tab of a pull
+// request, anchored by the SHA-256 hash of the file path
+func (c *Client) DiffLink(owner, repo string, prNumber int, line git.DiffLine) string {
+	side := "R"
+	if line.Side == "LEFT" {
+		side = "L"
+	}
+	anchor := fmt.Sprintf("diff-%x%s%d", sha256.Sum256([]byte(line.File)), side, line.Line)
+	if line.StartLine > 0 && line.StartLine < line.Line {
+		anchor = fmt.Sprintf("diff-%x%s%d-%s%d", sha256.Sum256([]byte(line.File)), side, line.StartLine, side, line.Line)
+	}
+
+	return fmt.Sprintf("%s/%s/%s/pull/%d/files#%s", c.webURL(), owner, repo, prNumber, anchor)
+}
+
+// webURL returns the URL of the GitHub web interface for the API URL
+func (c *Client) webURL() string {
+	if c.apiURL == DefaultAPIURL {
+		return "https://github.com"
+	}
+
+	// GitHub Enterprise Server serves the API under /api/v3
+	return strings.TrimSuffix(strings.TrimSuffix(c.apiURL, "/"), "/api/v3")
+}
+
+// doRequest executes an HTTP request with proper authentication, retrying
+// rate-limited and server error responses with backoff
 func (c *Client) doRequest(req *http.Request) (string, error) {
 	// Set common headers
 	req.Header.Set("User-Agent", c.userAgent)
 	req.Header.Set("Accept", "application/json")
-	
+
 	// Set authentication token
 	token, err := c.token.Token()
 	if err != nil {
 		return "", fmt.Errorf("error getting token: %w", err)
 	}
 	req.Header.Set("Authorization", fmt.Sprintf("token %s", token))
-	
-	// Execute request
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("error executing request: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	// Read response body
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("error reading response: %w", err)
-	}
-	
-	// Check for errors
-	if resp.StatusCode >= 400 {
-		switch resp.StatusCode {
-		case http.StatusUnauthorized:
-			return "", git.ErrAuthenticationFailed
-		case http.StatusForbidden:
-			return "", git.ErrPermissionDenied
-		case http.StatusNotFound:
-			return "", git.ErrResourceNotFound
-		default:
-			return "", fmt.Errorf("error from GitHub API: %s (status code: %d)", string(body), resp.StatusCode)
+
+	// The review's logger carries its correlation fields
+	logger := log.FromContext(req.Context())
+	for attempt := 0; ; attempt++ {
+		// Rewind the body for retries
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return "", fmt.Errorf("error rewinding request body: %w", err)
+			}
+			req.Body = body
+		}
+
+		// Execute request
+		start := time.Now()
+		resp, err := c.client.Do(req)
+		if err != nil {
+			metrics.GitRequestDuration.WithLabelValues("github", req.Method, "error").Observe(time.Since(start).Seconds())
+			return "", git.NewRequestError("github", err)
+		}
+		metrics.GitRequestDuration.WithLabelValues("github", req.Method, strconv.Itoa(resp.StatusCode)).Observe(time.Since(start).Seconds())
+		logger.V(1).Info("GitHub API request", "method", req.Method, "path", req.URL.Path,
+			"status", resp.StatusCode, "duration", time.Since(start))
+
+		// Read response body
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return "", fmt.Errorf("error reading response: %w", err)
+		}
+
+		c.updateRateLimit(resp.Header)
+
+		if resp.StatusCode < 400 {
+			return string(body), nil
+		}
+
+		// Back off and retry rate limits, and server errors of idempotent requests
+		if isRetryable(req, resp) && attempt < c.maxRetries {
+			if delay, ok := c.retryDelay(resp, attempt); ok {
+				logger.Info("retrying GitHub API request", "method", req.Method, "path", req.URL.Path,
+					"status", resp.StatusCode, "attempt", attempt+1, "delay", delay)
+				if err := sleep(req.Context(), delay); err != nil {
+					return "", err
+				}
+				continue
+			}
 		}
+
+		// Check for errors
+		apiErr := git.NewResponseError("github", resp, body)
+		if isRateLimited(resp) {
+			rateLimit := c.RateLimit()
+			apiErr.Kind, apiErr.Transient, apiErr.RateLimit = git.ErrRateLimited, true, &rateLimit
+			if apiErr.RetryAfter == 0 && !rateLimit.Reset.IsZero() {
+				apiErr.RetryAfter = time.Until(rateLimit.Reset)
+			}
+		}
+		return "", apiErr
+	}
+}
+
+// sleep waits for the given duration or until the context is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
-	
-	return string(body), nil
 }
 
 // formatCommentBody formats a comment with severity and rule information
 func formatCommentBody(comment git.ReviewComment) string {
 	var prefix string
-	
+
 	switch comment.Severity {
 	case "critical":
 		prefix = "🚨 **CRITICAL**"
@@ -304,6 +1032,25 @@ func formatCommentBody(comment git.ReviewComment) string {
 	default:
 		prefix = "**INFO**"
 	}
-	
-	return fmt.Sprintf("%s (%s): %s", prefix, comment.Rule, comment.Content)
-}
\ No newline at end of file
+
+	body := fmt.Sprintf("%s (%s): %s", prefix, comment.Rule, comment.Content)
+	if comment.SuggestedCode != "" {
+		// GitHub renders suggestion blocks as a change that can be applied in one click
+		body += "\n\n```suggestion\n" + strings.TrimSuffix(comment.SuggestedCode, "\n") + "\n```"
+	}
+
+	return body + "\n\n" + git.CommentMarker(comment.Rule, comment.Severity)
+}
+
+// maxStatusDescription is the longest commit status description GitHub accepts
+const maxStatusDescription = 140
+
+// truncate shortens s to at most n characters, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+
+	return string(runes[:n-1]) + "…"
+}
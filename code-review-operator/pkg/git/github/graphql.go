@@ -0,0 +1,448 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+)
+
+// snapshotTTL bounds how long a pull request read with GraphQL is reused;
+// writes to the pull request through the client drop it sooner
+const snapshotTTL = time.Minute
+
+// GraphQLClient is a GitHub client that reads pull requests, their changed
+// files and their review threads with the GraphQL API. A pull request is
+// read in one query, plus one per further page of 100 files or threads, and
+// reused until the client writes to it, where the REST API takes a request
+// per page and per kind of data. Diffs, which GraphQL does not serve, file
+// contents and writes go through the REST API.
+type GraphQLClient struct {
+	*Client
+
+	mu        sync.Mutex
+	snapshots map[string]*snapshot
+}
+
+// snapshot is a pull request and its review threads as read at a point in time
+type snapshot struct {
+	pullRequest git.PullRequest
+	threads     []graphQLThread
+	readAt      time.Time
+
+	// repliesMissing is set once a reply was posted: the threads and their
+	// first comments are still current, but not their replies
+	repliesMissing bool
+}
+
+// NewGraphQLClient creates a GitHub client for github.com reading pull
+// requests with the GraphQL API
+func NewGraphQLClient(token git.TokenSource) (git.Client, error) {
+	return NewGraphQLClientWithOptions(token, Options{})
+}
+
+// pullRequestFields are the fields read for every pull request
+const pullRequestFields = `fragment pullRequestFields on PullRequest {
+  number
+  title
+  body
+  url
+  baseRefName
+  headRefName
+  headRefOid
+  labels(first: 100) { nodes { name } }
+}`
+
+// threadFields are the fields read for every review thread. Threads with
+// more than 100 comments are cut short.
+const threadFields = `fragment threadFields on PullRequestReviewThread {
+  id
+  isResolved
+  comments(first: 100) {
+    nodes {
+      databaseId
+      body
+      path
+      line
+      startLine
+      diffSide
+      author { login }
+      replyTo { databaseId }
+    }
+  }
+}`
+
+// snapshotQuery reads a pull request with the first pages of its changed
+// files and review threads
+const snapshotQuery = `query($owner: String!, $repo: String!, $number: Int!) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      ...pullRequestFields
+      files(first: 100) {
+        nodes { path additions deletions }
+        pageInfo { hasNextPage endCursor }
+      }
+      reviewThreads(first: 100) {
+        nodes { ...threadFields }
+        pageInfo { hasNextPage endCursor }
+      }
+    }
+  }
+}
+` + pullRequestFields + "\n" + threadFields
+
+// filesQuery reads a further page of a pull request's changed files
+const filesQuery = `query($owner: String!, $repo: String!, $number: Int!, $cursor: String) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      files(first: 100, after: $cursor) {
+        nodes { path additions deletions }
+        pageInfo { hasNextPage endCursor }
+      }
+    }
+  }
+}`
+
+// threadsQuery reads a further page of a pull request's review threads
+const threadsQuery = `query($owner: String!, $repo: String!, $number: Int!, $cursor: String) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      reviewThreads(first: 100, after: $cursor) {
+        nodes { ...threadFields }
+        pageInfo { hasNextPage endCursor }
+      }
+    }
+  }
+}
+` + threadFields
+
+// openPullRequestsQuery reads a page of the open pull requests of a repository
+const openPullRequestsQuery = `query($owner: String!, $repo: String!, $cursor: String) {
+  repository(owner: $owner, name: $repo) {
+    pullRequests(states: OPEN, first: 100, after: $cursor) {
+      nodes { ...pullRequestFields }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}
+` + pullRequestFields
+
+type graphQLPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type graphQLPullRequest struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+	URL         string `json:"url"`
+	BaseRefName string `json:"baseRefName"`
+	HeadRefName string `json:"headRefName"`
+	HeadRefOid  string `json:"headRefOid"`
+	Labels      struct {
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	} `json:"labels"`
+	Files         graphQLFiles   `json:"files"`
+	ReviewThreads graphQLThreads `json:"reviewThreads"`
+}
+
+type graphQLFiles struct {
+	Nodes []struct {
+		Path      string `json:"path"`
+		Additions int    `json:"additions"`
+		Deletions int    `json:"deletions"`
+	} `json:"nodes"`
+	PageInfo graphQLPageInfo `json:"pageInfo"`
+}
+
+type graphQLThreads struct {
+	Nodes    []graphQLThread `json:"nodes"`
+	PageInfo graphQLPageInfo `json:"pageInfo"`
+}
+
+type graphQLThread struct {
+	ID         string `json:"id"`
+	IsResolved bool   `json:"isResolved"`
+	Comments   struct {
+		Nodes []graphQLComment `json:"nodes"`
+	} `json:"comments"`
+}
+
+// graphQLComment is a review comment; line is null once it is outdated
+type graphQLComment struct {
+	DatabaseID int64  `json:"databaseId"`
+	Body       string `json:"body"`
+	Path       string `json:"path"`
+	Line       int    `json:"line"`
+	StartLine  int    `json:"startLine"`
+	DiffSide   string `json:"diffSide"`
+	Author     struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	ReplyTo *struct {
+		DatabaseID int64 `json:"databaseId"`
+	} `json:"replyTo"`
+}
+
+// toPullRequest converts a pull request read with GraphQL
+func (pr *graphQLPullRequest) toPullRequest(owner, repo string) git.PullRequest {
+	pullRequest := git.PullRequest{
+		Owner:      owner,
+		Repository: repo,
+		Number:     pr.Number,
+		Title:      pr.Title,
+		BaseBranch: pr.BaseRefName,
+		HeadBranch: pr.HeadRefName,
+		HeadSHA:    pr.HeadRefOid,
+		Body:       pr.Body,
+		Labels:     make([]string, 0, len(pr.Labels.Nodes)),
+		URL:        pr.URL,
+	}
+	for _, label := range pr.Labels.Nodes {
+		pullRequest.Labels = append(pullRequest.Labels, label.Name)
+	}
+
+	return pullRequest
+}
+
+// snapshotKey identifies a pull request in the snapshot cache
+func snapshotKey(owner, repo string, number int) string {
+	return fmt.Sprintf("%s/%s#%d", owner, repo, number)
+}
+
+// snapshot returns the pull request and its review threads, reading them
+// unless they were read within snapshotTTL and not written to since.
+// withReplies asks for every reply, which replies posted since leave out.
+func (c *GraphQLClient) snapshot(ctx context.Context, owner, repo string, number int, withReplies bool) (*snapshot, error) {
+	key := snapshotKey(owner, repo, number)
+	c.mu.Lock()
+	cached, ok := c.snapshots[key]
+	c.mu.Unlock()
+	if ok && time.Since(cached.readAt) < snapshotTTL && !(withReplies && cached.repliesMissing) {
+		return cached, nil
+	}
+
+	var result struct {
+		Repository struct {
+			PullRequest *graphQLPullRequest `json:"pullRequest"`
+		} `json:"repository"`
+	}
+	variables := map[string]interface{}{"owner": owner, "repo": repo, "number": number}
+	if err := c.graphQL(ctx, snapshotQuery, variables, &result); err != nil {
+		return nil, fmt.Errorf("error reading pull request: %w", err)
+	}
+	pr := result.Repository.PullRequest
+	if pr == nil {
+		return nil, git.ErrResourceNotFound
+	}
+
+	snap := &snapshot{
+		pullRequest: pr.toPullRequest(owner, repo),
+		threads:     pr.ReviewThreads.Nodes,
+		readAt:      time.Now(),
+	}
+
+	files := pr.Files
+	for {
+		for _, file := range files.Nodes {
+			snap.pullRequest.ChangedFiles = append(snap.pullRequest.ChangedFiles, git.ChangedFile{
+				Path:      file.Path,
+				Additions: file.Additions,
+				Deletions: file.Deletions,
+			})
+		}
+		if !files.PageInfo.HasNextPage {
+			break
+		}
+		var page struct {
+			Repository struct {
+				PullRequest struct {
+					Files graphQLFiles `json:"files"`
+				} `json:"pullRequest"`
+			} `json:"repository"`
+		}
+		variables["cursor"] = files.PageInfo.EndCursor
+		if err := c.graphQL(ctx, filesQuery, variables, &page); err != nil {
+			return nil, fmt.Errorf("error listing changed files: %w", err)
+		}
+		files = page.Repository.PullRequest.Files
+	}
+
+	threads := pr.ReviewThreads
+	for threads.PageInfo.HasNextPage {
+		var page struct {
+			Repository struct {
+				PullRequest struct {
+					ReviewThreads graphQLThreads `json:"reviewThreads"`
+				} `json:"pullRequest"`
+			} `json:"repository"`
+		}
+		variables["cursor"] = threads.PageInfo.EndCursor
+		if err := c.graphQL(ctx, threadsQuery, variables, &page); err != nil {
+			return nil, fmt.Errorf("error listing review threads: %w", err)
+		}
+		threads = page.Repository.PullRequest.ReviewThreads
+		snap.threads = append(snap.threads, threads.Nodes...)
+	}
+
+	c.mu.Lock()
+	c.snapshots[key] = snap
+	c.mu.Unlock()
+
+	return snap, nil
+}
+
+// forget drops the snapshot of a pull request after a write to it
+func (c *GraphQLClient) forget(owner, repo string, number int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.snapshots, snapshotKey(owner, repo, number))
+}
+
+// GetPullRequest implements git.PullRequestReader
+func (c *GraphQLClient) GetPullRequest(ctx context.Context, owner, repo string, number int) (*git.PullRequest, error) {
+	snap, err := c.snapshot(ctx, owner, repo, number, false)
+	if err != nil {
+		return nil, err
+	}
+
+	pullRequest := snap.pullRequest
+	return &pullRequest, nil
+}
+
+// GetPullRequests gets the open pull requests of a repository, 100 per query
+func (c *GraphQLClient) GetPullRequests(ctx context.Context, owner, repo string) ([]git.PullRequest, error) {
+	var prs []git.PullRequest
+	variables := map[string]interface{}{"owner": owner, "repo": repo, "cursor": nil}
+	for {
+		var result struct {
+			Repository struct {
+				PullRequests struct {
+					Nodes    []graphQLPullRequest `json:"nodes"`
+					PageInfo graphQLPageInfo      `json:"pageInfo"`
+				} `json:"pullRequests"`
+			} `json:"repository"`
+		}
+		if err := c.graphQL(ctx, openPullRequestsQuery, variables, &result); err != nil {
+			return nil, fmt.Errorf("error getting pull requests: %w", err)
+		}
+
+		pullRequests := result.Repository.PullRequests
+		for i := range pullRequests.Nodes {
+			prs = append(prs, pullRequests.Nodes[i].toPullRequest(owner, repo))
+		}
+		if !pullRequests.PageInfo.HasNextPage {
+			return prs, nil
+		}
+		variables["cursor"] = pullRequests.PageInfo.EndCursor
+	}
+}
+
+// ListReviewComments gets the review comments on a pull request, including
+// replies, from its review threads
+func (c *GraphQLClient) ListReviewComments(ctx context.Context, owner, repo string, prNumber int) ([]git.PostedComment, error) {
+	snap, err := c.snapshot(ctx, owner, repo, prNumber, true)
+	if err != nil {
+		return nil, fmt.Errorf("error listing review comments: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var comments []git.PostedComment
+	for _, thread := range snap.threads {
+		for _, comment := range thread.Comments.Nodes {
+			posted := git.PostedComment{
+				ID:        strconv.FormatInt(comment.DatabaseID, 10),
+				File:      comment.Path,
+				Line:      comment.Line,
+				StartLine: comment.StartLine,
+				Side:      comment.DiffSide,
+				Body:      comment.Body,
+				Author:    comment.Author.Login,
+			}
+			if comment.ReplyTo != nil {
+				posted.InReplyTo = strconv.FormatInt(comment.ReplyTo.DatabaseID, 10)
+			}
+			comments = append(comments, posted)
+		}
+	}
+
+	return comments, nil
+}
+
+// ResolveReviewThread marks the thread started by a review comment as
+// resolved, finding the thread among the pull request's threads already read
+func (c *GraphQLClient) ResolveReviewThread(ctx context.Context, owner, repo string, prNumber int, commentID string) error {
+	id, err := strconv.ParseInt(commentID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid review comment ID %q: %w", commentID, err)
+	}
+
+	snap, err := c.snapshot(ctx, owner, repo, prNumber, false)
+	if err != nil {
+		return fmt.Errorf("error listing review threads: %w", err)
+	}
+	c.mu.Lock()
+	thread := -1
+	resolved := false
+	for i := range snap.threads {
+		comments := snap.threads[i].Comments.Nodes
+		if len(comments) > 0 && comments[0].DatabaseID == id {
+			thread, resolved = i, snap.threads[i].IsResolved
+			break
+		}
+	}
+	c.mu.Unlock()
+	if thread < 0 {
+		return git.ErrResourceNotFound
+	}
+	if resolved {
+		return nil
+	}
+
+	if err := c.graphQL(ctx, resolveThreadMutation, map[string]interface{}{"thread": snap.threads[thread].ID}, nil); err != nil {
+		return fmt.Errorf("error resolving review thread: %w", err)
+	}
+	c.mu.Lock()
+	snap.threads[thread].IsResolved = true
+	c.mu.Unlock()
+
+	return nil
+}
+
+// PostReview posts review comments to a pull request with the given decision
+func (c *GraphQLClient) PostReview(ctx context.Context, owner, repo string, prNumber int, commitSHA string, comments []git.ReviewComment, summary string, decision git.ReviewDecision) (string, error) {
+	defer c.forget(owner, repo, prNumber)
+	return c.Client.PostReview(ctx, owner, repo, prNumber, commitSHA, comments, summary, decision)
+}
+
+// ReplyToReviewComment replies in the thread of a review comment
+func (c *GraphQLClient) ReplyToReviewComment(ctx context.Context, owner, repo string, prNumber int, commentID, body string) error {
+	defer func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if snap, ok := c.snapshots[snapshotKey(owner, repo, prNumber)]; ok {
+			snap.repliesMissing = true
+		}
+	}()
+	return c.Client.ReplyToReviewComment(ctx, owner, repo, prNumber, commentID, body)
+}
+
+// DeleteReviewComment deletes a review comment
+func (c *GraphQLClient) DeleteReviewComment(ctx context.Context, owner, repo string, prNumber int, commentID string) error {
+	defer c.forget(owner, repo, prNumber)
+	return c.Client.DeleteReviewComment(ctx, owner, repo, prNumber, commentID)
+}
+
+// AddLabels adds labels to a pull request, keeping its existing labels
+func (c *GraphQLClient) AddLabels(ctx context.Context, owner, repo string, prNumber int, labels []string) error {
+	defer c.forget(owner, repo, prNumber)
+	return c.Client.AddLabels(ctx, owner, repo, prNumber, labels)
+}
@@ -0,0 +1,129 @@
+package github
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/httpx"
+)
+
+// DefaultTimeout bounds each request to the GitHub API
+const DefaultTimeout = 30 * time.Second
+
+// Options configures how a client reaches GitHub, such as a GitHub
+// Enterprise Server behind a proxy. The zero value reaches github.com
+// directly, or through the proxy of the HTTPS_PROXY environment variable.
+type Options struct {
+	// BaseURL is the URL of the REST API, https://HOST/api/v3 on GitHub
+	// Enterprise Server (defaults to DefaultAPIURL)
+	BaseURL string
+
+	// UploadURL is the URL of the uploads API, https://HOST/api/uploads on
+	// GitHub Enterprise Server (defaults to the one matching BaseURL)
+	UploadURL string
+
+	// ProxyURL is the HTTP proxy requests go through, replacing the proxy of
+	// the environment
+	ProxyURL string
+
+	// RootCAs are PEM-encoded certificates of the authorities trusted in
+	// addition to the system's, such as a corporate CA signing GitHub
+	// Enterprise Server's or the proxy's certificate
+	RootCAs []byte
+
+	// Timeout bounds each request (defaults to DefaultTimeout)
+	Timeout time.Duration
+}
+
+// NewClientWithOptions creates a GitHub client configured by options
+func NewClientWithOptions(token git.TokenSource, options Options) (git.Client, error) {
+	httpClient, err := options.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	apiURL := DefaultAPIURL
+	if options.BaseURL != "" {
+		apiURL = strings.TrimSuffix(options.BaseURL, "/")
+	}
+
+	return &Client{
+		client:      httpClient,
+		apiURL:      apiURL,
+		uploadURL:   strings.TrimSuffix(options.UploadURL, "/"),
+		userAgent:   DefaultUserAgent,
+		token:       token,
+		maxRetries:  DefaultMaxRetries,
+		baseBackoff: DefaultBaseBackoff,
+		maxBackoff:  DefaultMaxBackoff,
+	}, nil
+}
+
+// NewGraphQLClientWithOptions creates a GitHub client reading pull requests
+// with the GraphQL API, configured by options
+func NewGraphQLClientWithOptions(token git.TokenSource, options Options) (git.Client, error) {
+	client, err := NewClientWithOptions(token, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GraphQLClient{
+		Client:    client.(*Client),
+		snapshots: make(map[string]*snapshot),
+	}, nil
+}
+
+// httpClient creates the HTTP client of the options
+func (o Options) httpClient() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if o.ProxyURL != "" {
+		proxy, err := neturl.Parse(o.ProxyURL)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", o.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if len(o.RootCAs) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(o.RootCAs) {
+			return nil, fmt.Errorf("no PEM certificates found in the root CAs")
+		}
+		transport.TLSClientConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			RootCAs:    pool,
+		}
+	}
+
+	timeout := o.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &http.Client{
+		Transport: httpx.NewTransport(transport),
+		Timeout:   timeout,
+	}, nil
+}
+
+// UploadURL returns the URL of the uploads API
+func (c *Client) UploadURL() string {
+	if c.uploadURL != "" {
+		return c.uploadURL
+	}
+	if c.apiURL == DefaultAPIURL {
+		return "https://uploads.github.com"
+	}
+
+	// GitHub Enterprise Server serves uploads under /api/uploads
+	return c.webURL() + "/api/uploads"
+}
@@ -0,0 +1,120 @@
+package github
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/metrics"
+)
+
+const (
+	// DefaultMaxRetries is the default number of retries for rate-limited or failed requests
+	DefaultMaxRetries = 3
+
+	// DefaultBaseBackoff is the initial backoff used for exponential retries
+	DefaultBaseBackoff = 1 * time.Second
+
+	// DefaultMaxBackoff caps the time spent waiting before a single retry
+	DefaultMaxBackoff = 1 * time.Minute
+)
+
+// RateLimit returns the most recently observed GitHub API rate limit
+func (c *Client) RateLimit() git.RateLimit {
+	c.rateMu.RLock()
+	defer c.rateMu.RUnlock()
+
+	return c.rateLimit
+}
+
+// updateRateLimit records the rate limit headers from a GitHub response
+func (c *Client) updateRateLimit(header http.Header) {
+	remaining := header.Get("X-RateLimit-Remaining")
+	if remaining == "" {
+		return
+	}
+
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+
+	if n, err := strconv.Atoi(remaining); err == nil {
+		c.rateLimit.Remaining = n
+		metrics.GitRateLimitRemaining.WithLabelValues("github").Set(float64(n))
+	}
+	if n, err := strconv.Atoi(header.Get("X-RateLimit-Limit")); err == nil {
+		c.rateLimit.Limit = n
+	}
+	if n, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		c.rateLimit.Reset = time.Unix(n, 0)
+	}
+}
+
+// isRateLimited reports whether a response was rejected because of a primary or secondary rate limit
+func isRateLimited(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		return false
+	}
+
+	return resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != ""
+}
+
+// isRetryable reports whether a failed response should be retried. Rate
+// limited requests were not carried out, so any request is retried. Server
+// errors are retried only for idempotent methods, as GitHub may have created
+// a review or comment before failing, and retrying would post it twice.
+func isRetryable(req *http.Request, resp *http.Response) bool {
+	if isRateLimited(resp) {
+		return true
+	}
+	if resp.StatusCode < http.StatusInternalServerError {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+// retryDelay determines how long to wait before retrying a failed response.
+// It honors Retry-After, then the rate limit reset time, and otherwise falls
+// back to jittered exponential backoff. The second return value is false when
+// the required wait exceeds the maximum backoff and the request should not be retried.
+func (c *Client) retryDelay(resp *http.Response, attempt int) (time.Duration, bool) {
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			delay := time.Duration(seconds) * time.Second
+			return delay, delay <= c.maxBackoff
+		}
+		if at, err := http.ParseTime(retryAfter); err == nil {
+			delay := time.Until(at)
+			return delay, delay <= c.maxBackoff
+		}
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if n, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			delay := time.Until(time.Unix(n, 0))
+			return delay, delay <= c.maxBackoff
+		}
+	}
+
+	return c.backoff(attempt), true
+}
+
+// backoff returns a full-jitter exponential backoff for the given attempt
+func (c *Client) backoff(attempt int) time.Duration {
+	limit := c.baseBackoff << uint(attempt)
+	if limit <= 0 || limit > c.maxBackoff {
+		limit = c.maxBackoff
+	}
+
+	return time.Duration(rand.Int63n(int64(limit) + 1))
+}
@@ -1,21 +1,40 @@
 package gitlab
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/httpx"
 )
 
+// DefaultAPIURL is the default GitLab API URL
+const DefaultAPIURL = "https://gitlab.com/api/v4"
+
 // Client implements the git.Client interface for GitLab
 type Client struct {
-	// GitLab client configuration
+	client *http.Client
+	apiURL string
+	token  git.TokenSource
 }
 
 // NewClient creates a new GitLab client
 func NewClient(token git.TokenSource) (git.Client, error) {
-	// For now, return a stub client
-	return &Client{}, nil
+	return &Client{
+		client: httpx.NewClient(30 * time.Second),
+		apiURL: DefaultAPIURL,
+		token:  token,
+	}, nil
 }
 
 // GetDiff gets the code diff for a pull request or commit
@@ -23,11 +42,201 @@ func (c *Client) GetDiff(ctx context.Context, owner, repo string, prNumber int,
 	return "", fmt.Errorf("GitLab client not fully implemented yet")
 }
 
-// PostReview posts review comments to a merge request
-func (c *Client) PostReview(ctx context.Context, owner, repo string, prNumber int, comments []git.ReviewComment, summary string) (string, error) {
+// GetCompareDiff gets the diff between two commits
+func (c *Client) GetCompareDiff(ctx context.Context, owner, repo, base, head string) (string, error) {
 	return "", fmt.Errorf("GitLab client not fully implemented yet")
 }
 
+// diffRefs are the commits a merge request's diff is computed between, which
+// GitLab needs to place comments on it
+type diffRefs struct {
+	BaseSHA  string `json:"base_sha"`
+	StartSHA string `json:"start_sha"`
+	HeadSHA  string `json:"head_sha"`
+}
+
+// PostReview posts review comments to a merge request, each starting a
+// discussion on its line, and the summary as a note. GitLab has no review
+// decisions, so the decision is not posted. Comments GitLab cannot place on
+// the diff are posted as discussions of the merge request, with their
+// location, rather than lost. Comments are placed on the diff at commitSHA,
+// the reviewed commit, rather than at the merge request's current head.
+func (c *Client) PostReview(ctx context.Context, owner, repo string, prNumber int, commitSHA string, comments []git.ReviewComment, summary string, decision git.ReviewDecision) (string, error) {
+	mergeRequestURL := c.mergeRequestURL(owner, repo, prNumber)
+
+	var mergeRequest struct {
+		WebURL   string   `json:"web_url"`
+		DiffRefs diffRefs `json:"diff_refs"`
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", mergeRequestURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+	if err := c.doRequest(req, &mergeRequest); err != nil {
+		return "", fmt.Errorf("error getting merge request: %w", err)
+	}
+	if commitSHA != "" {
+		mergeRequest.DiffRefs.HeadSHA = commitSHA
+	}
+
+	for _, comment := range comments {
+		body := formatCommentBody(comment)
+		err := c.postDiscussion(ctx, mergeRequestURL, map[string]interface{}{
+			"body":     body,
+			"position": position(comment, mergeRequest.DiffRefs),
+		})
+		if errors.Is(err, git.ErrInvalidRequest) {
+			err = c.postDiscussion(ctx, mergeRequestURL, map[string]interface{}{
+				"body": fmt.Sprintf("`%s`: %s", comment.Location(), body),
+			})
+		}
+		if err != nil {
+			return "", fmt.Errorf("error posting comment on %s: %w", comment.Location(), err)
+		}
+	}
+
+	if summary != "" {
+		if _, err := c.PostComment(ctx, owner, repo, prNumber, summary); err != nil {
+			return "", err
+		}
+	}
+
+	return mergeRequest.WebURL, nil
+}
+
+// postDiscussion starts a discussion on a merge request
+func (c *Client) postDiscussion(ctx context.Context, mergeRequestURL string, discussion map[string]interface{}) error {
+	jsonBody, err := json.Marshal(discussion)
+	if err != nil {
+		return fmt.Errorf("error marshaling discussion: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", mergeRequestURL+"/discussions", bytes.NewReader(jsonBody))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return c.doRequest(req, nil)
+}
+
+// position places a comment on a merge request's diff. GitLab identifies a
+// line by its number in the old version for removed lines, in the new version
+// for added lines, and in both for unchanged lines. Ranges are commented on
+// their last line.
+func position(comment git.ReviewComment, refs diffRefs) map[string]interface{} {
+	oldPath := comment.OldPath
+	if oldPath == "" {
+		oldPath = comment.File
+	}
+	pos := map[string]interface{}{
+		"position_type": "text",
+		"base_sha":      refs.BaseSHA,
+		"start_sha":     refs.StartSHA,
+		"head_sha":      refs.HeadSHA,
+		"old_path":      oldPath,
+		"new_path":      comment.File,
+	}
+
+	oldLine, newLine := comment.OldLine, comment.NewLine
+	if oldLine == 0 && newLine == 0 {
+		// Not placed on the diff: the line is on the comment's side only
+		if comment.Side == "LEFT" {
+			oldLine = comment.Line
+		} else {
+			newLine = comment.Line
+		}
+	}
+	if oldLine > 0 {
+		pos["old_line"] = oldLine
+	}
+	if newLine > 0 {
+		pos["new_line"] = newLine
+	}
+
+	return pos
+}
+
+// formatCommentBody formats a review comment for GitLab. Suggestions use
+// GitLab's suggestion blocks, which replace the commented lines.
+func formatCommentBody(comment git.ReviewComment) string {
+	body := comment.Content
+	if comment.Severity != "" {
+		body = fmt.Sprintf("**%s** (%s): %s", strings.ToUpper(comment.Severity), comment.Rule, comment.Content)
+	}
+	if comment.SuggestedCode != "" {
+		above := 0
+		if comment.IsRange() && comment.StartSide == "" {
+			above = comment.Line - comment.StartLine
+		}
+		body += fmt.Sprintf("\n\n```suggestion:-%d+0\n%s\n```", above, strings.TrimSuffix(comment.SuggestedCode, "\n"))
+	}
+
+	return body + "\n\n" + git.CommentMarker(comment.Rule, comment.Severity)
+}
+
+// mergeRequestURL returns the API URL of a merge request. Projects are
+// addressed by their URL-encoded full path.
+func (c *Client) mergeRequestURL(owner, repo string, prNumber int) string {
+	return fmt.Sprintf("%s/projects/%s/merge_requests/%d", c.apiURL, neturl.PathEscape(owner+"/"+repo), prNumber)
+}
+
+// ListReviewComments gets the review comments on a merge request
+func (c *Client) ListReviewComments(ctx context.Context, owner, repo string, prNumber int) ([]git.PostedComment, error) {
+	return nil, fmt.Errorf("GitLab client not fully implemented yet")
+}
+
+// ReplyToReviewComment replies in the discussion of a review comment
+func (c *Client) ReplyToReviewComment(ctx context.Context, owner, repo string, prNumber int, commentID, body string) error {
+	return fmt.Errorf("GitLab client not fully implemented yet")
+}
+
+// ResolveReviewThread marks the discussion started by a review comment as resolved
+func (c *Client) ResolveReviewThread(ctx context.Context, owner, repo string, prNumber int, commentID string) error {
+	return fmt.Errorf("GitLab client not fully implemented yet")
+}
+
+// DeleteReviewComment deletes a review comment
+func (c *Client) DeleteReviewComment(ctx context.Context, owner, repo string, prNumber int, commentID string) error {
+	return fmt.Errorf("GitLab client not fully implemented yet")
+}
+
+// PostComment posts a note on a merge request
+func (c *Client) PostComment(ctx context.Context, owner, repo string, prNumber int, body string) (string, error) {
+	jsonBody, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return "", fmt.Errorf("error marshaling note: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.mergeRequestURL(owner, repo, prNumber)+"/notes", bytes.NewReader(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var note struct {
+		ID int64 `json:"id"`
+	}
+	if err := c.doRequest(req, &note); err != nil {
+		return "", fmt.Errorf("error posting note: %w", err)
+	}
+
+	return strconv.FormatInt(note.ID, 10), nil
+}
+
+// GetComments gets the notes on a merge request
+func (c *Client) GetComments(ctx context.Context, owner, repo string, prNumber int) ([]git.IssueComment, error) {
+	return nil, fmt.Errorf("GitLab client not fully implemented yet")
+}
+
+// FindPullRequestsByLabel gets the open merge requests carrying a label in a group
+func (c *Client) FindPullRequestsByLabel(ctx context.Context, owner, label string) ([]git.PullRequest, error) {
+	return nil, fmt.Errorf("GitLab client not fully implemented yet")
+}
+
+// GetIssue gets an issue of a project
+func (c *Client) GetIssue(ctx context.Context, owner, repo string, number int) (*git.Issue, error) {
+	return nil, fmt.Errorf("GitLab client not fully implemented yet")
+}
+
 // GetRepositories gets the list of repositories for an organization or user
 func (c *Client) GetRepositories(ctx context.Context, owner string) ([]git.Repository, error) {
 	return nil, fmt.Errorf("GitLab client not fully implemented yet")
@@ -38,7 +247,111 @@ func (c *Client) GetPullRequests(ctx context.Context, owner, repo string) ([]git
 	return nil, fmt.Errorf("GitLab client not fully implemented yet")
 }
 
+// GetFileContent gets the raw content of a file at the given ref
+func (c *Client) GetFileContent(ctx context.Context, owner, repo, path, ref string) ([]byte, error) {
+	return nil, fmt.Errorf("GitLab client not fully implemented yet")
+}
+
+// CreateCheckRun reports a completed check against a commit
+func (c *Client) CreateCheckRun(ctx context.Context, owner, repo string, checkRun git.CheckRun) (string, error) {
+	return "", fmt.Errorf("GitLab client not fully implemented yet")
+}
+
+// StartCheckRun creates an in-progress check against a commit
+func (c *Client) StartCheckRun(ctx context.Context, owner, repo string, checkRun git.CheckRun) (string, error) {
+	return "", fmt.Errorf("GitLab client not fully implemented yet")
+}
+
+// UpdateCheckRun updates the output of a check
+func (c *Client) UpdateCheckRun(ctx context.Context, owner, repo, id string, checkRun git.CheckRun) error {
+	return fmt.Errorf("GitLab client not fully implemented yet")
+}
+
+// SetCommitStatus sets a pipeline status on a commit
+func (c *Client) SetCommitStatus(ctx context.Context, owner, repo, sha string, status git.CommitStatus) error {
+	// GitLab names failed statuses differently
+	state := status.State
+	switch state {
+	case git.CommitStatusFailure, git.CommitStatusError:
+		state = "failed"
+	}
+
+	params := neturl.Values{}
+	params.Set("state", state)
+	params.Set("name", status.Context)
+	params.Set("description", status.Description)
+	if status.TargetURL != "" {
+		params.Set("target_url", status.TargetURL)
+	}
+
+	// Projects are addressed by their URL-encoded full path
+	project := neturl.PathEscape(owner + "/" + repo)
+	url := fmt.Sprintf("%s/projects/%s/statuses/%s?%s", c.apiURL, project, sha, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	if err := c.doRequest(req, nil); err != nil {
+		return fmt.Errorf("error setting commit status: %w", err)
+	}
+
+	return nil
+}
+
+// AddLabels adds labels to a merge request
+func (c *Client) AddLabels(ctx context.Context, owner, repo string, prNumber int, labels []string) error {
+	return fmt.Errorf("GitLab client not fully implemented yet")
+}
+
 // GetProviderName returns the name of the Git provider
 func (c *Client) GetProviderName() string {
 	return "gitlab"
-}
\ No newline at end of file
+}
+
+// DiffLink returns a permalink to a line in the changes of a merge request,
+// anchored by GitLab's line code: the SHA-1 hash of the file path followed by
+// the line's old and new numbers
+func (c *Client) DiffLink(owner, repo string, prNumber int, line git.DiffLine) string {
+	webURL := strings.TrimSuffix(strings.TrimSuffix(c.apiURL, "/"), "/api/v4")
+	anchor := fmt.Sprintf("%x_%d_%d", sha1.Sum([]byte(line.File)), line.OldLine, line.NewLine)
+
+	return fmt.Sprintf("%s/%s/%s/-/merge_requests/%d/diffs#%s", webURL, owner, repo, prNumber, anchor)
+}
+
+// doRequest executes an HTTP request with authentication, decoding the JSON
+// response into out unless it is nil. Personal, project and group access
+// tokens are sent in the PRIVATE-TOKEN header, and OAuth2 access tokens as
+// bearer tokens.
+func (c *Client) doRequest(req *http.Request, out interface{}) error {
+	token, err := c.token.Token()
+	if err != nil {
+		return fmt.Errorf("error getting token: %w", err)
+	}
+	if bearer, ok := c.token.(git.BearerTokenSource); ok && bearer.BearerToken() {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else {
+		req.Header.Set("PRIVATE-TOKEN", token)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return git.NewRequestError("gitlab", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 400 {
+		if out == nil {
+			return nil
+		}
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("error parsing response: %w", err)
+		}
+		return nil
+	}
+
+	// Check for errors
+	body, _ := ioutil.ReadAll(resp.Body)
+	return git.NewResponseError("gitlab", resp, body)
+}
@@ -0,0 +1,287 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/httpx"
+)
+
+const (
+	// GitLabTokenURL is the OAuth2 token endpoint of gitlab.com
+	GitLabTokenURL = "https://gitlab.com/oauth/token"
+
+	// SecretKeyClientID is the Secret key holding the OAuth2 application ID
+	SecretKeyClientID = "client-id"
+
+	// SecretKeyClientSecret is the Secret key holding the OAuth2 application secret
+	SecretKeyClientSecret = "client-secret"
+
+	// RefreshTokenLabel must be set to "true" on Secrets holding refresh
+	// tokens, which the operator then writes the rotated tokens to. Secrets
+	// without it are never written.
+	RefreshTokenLabel = "review.code-review.io/refresh-token"
+
+	// oauth2RefreshSkew is how long before expiry an access token is refreshed
+	oauth2RefreshSkew = 2 * time.Minute
+)
+
+// OAuth2Config holds the OAuth2 application credentials used to refresh tokens
+type OAuth2Config struct {
+	// TokenURL is the provider's token endpoint
+	TokenURL string
+
+	// ClientID is the OAuth2 application ID
+	ClientID string
+
+	// ClientSecret is the OAuth2 application secret
+	ClientSecret string
+}
+
+// GitLabOAuth2Config returns the OAuth2 configuration for a GitLab instance.
+// An empty baseURL selects gitlab.com.
+func GitLabOAuth2Config(baseURL, clientID, clientSecret string) OAuth2Config {
+	tokenURL := GitLabTokenURL
+	if baseURL != "" {
+		tokenURL = strings.TrimSuffix(baseURL, "/") + "/oauth/token"
+	}
+
+	return OAuth2Config{
+		TokenURL:     tokenURL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+	}
+}
+
+// OAuth2ConfigFor returns the OAuth2 configuration of a provider's hosted
+// service with the given application credentials
+func OAuth2ConfigFor(provider, clientID, clientSecret string) (OAuth2Config, error) {
+	switch provider {
+	case "gitlab":
+		return GitLabOAuth2Config("", clientID, clientSecret), nil
+	}
+
+	return OAuth2Config{}, fmt.Errorf("OAuth2 refresh tokens cannot authenticate to %s", provider)
+}
+
+// RefreshTokenStore persists rotated refresh tokens
+type RefreshTokenStore interface {
+	// SaveRefreshToken stores the latest refresh token
+	SaveRefreshToken(ctx context.Context, refreshToken string) error
+}
+
+// RefreshingTokenSource is a TokenSource that uses an OAuth2 refresh token to
+// obtain access tokens, refreshing them shortly before they expire. Providers
+// that rotate refresh tokens on use have the new token saved to the store.
+// It is safe for concurrent use.
+type RefreshingTokenSource struct {
+	config OAuth2Config
+	store  RefreshTokenStore
+	client *http.Client
+
+	mu           sync.Mutex
+	refreshToken string
+	accessToken  string
+	expiresAt    time.Time
+
+	// unsaved is set while a rotated refresh token has not been saved
+	unsaved bool
+}
+
+// NewRefreshingTokenSource creates a new refreshing token source. The store may be nil.
+func NewRefreshingTokenSource(config OAuth2Config, refreshToken string, store RefreshTokenStore) *RefreshingTokenSource {
+	return &RefreshingTokenSource{
+		config:       config,
+		store:        store,
+		refreshToken: refreshToken,
+		client:       httpx.NewClient(30 * time.Second),
+	}
+}
+
+// Token implements TokenSource. A rotated refresh token that could not be
+// saved is saved again on every call until it is.
+func (s *RefreshingTokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.client.Timeout)
+	defer cancel()
+
+	if s.unsaved {
+		s.save(ctx)
+	}
+	if s.accessToken != "" && time.Until(s.expiresAt) > oauth2RefreshSkew {
+		return s.accessToken, nil
+	}
+
+	if err := s.refresh(ctx); err != nil {
+		return "", err
+	}
+
+	return s.accessToken, nil
+}
+
+// BearerToken implements BearerTokenSource, as the tokens are OAuth2 access tokens
+func (s *RefreshingTokenSource) BearerToken() bool {
+	return true
+}
+
+// refresh exchanges the refresh token for a new access token. Callers must hold s.mu.
+func (s *RefreshingTokenSource) refresh(ctx context.Context) error {
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", s.refreshToken)
+	form.Set("client_id", s.config.ClientID)
+	form.Set("client_secret", s.config.ClientSecret)
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "POST", s.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	// Execute request
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error refreshing token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized:
+		// The refresh token was revoked or has already been used
+		return fmt.Errorf("error refreshing token: %s: %w", string(body), ErrAuthenticationFailed)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("error from token endpoint: %s (status code: %d)", string(body), resp.StatusCode)
+	}
+
+	// Parse the response
+	var tokenResponse struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &tokenResponse); err != nil {
+		return fmt.Errorf("error parsing response: %w", err)
+	}
+	if tokenResponse.AccessToken == "" {
+		return fmt.Errorf("error refreshing token: no access token in response")
+	}
+
+	s.accessToken = tokenResponse.AccessToken
+	s.expiresAt = time.Now().Add(time.Duration(tokenResponse.ExpiresIn) * time.Second)
+
+	// Persist the rotated refresh token so it survives restarts. The old
+	// token has been used up, so a failure to save the new one must not
+	// fail the access token it came with.
+	if tokenResponse.RefreshToken != "" && tokenResponse.RefreshToken != s.refreshToken {
+		s.refreshToken = tokenResponse.RefreshToken
+		s.unsaved = s.store != nil
+		if s.unsaved {
+			s.save(ctx)
+		}
+	}
+
+	return nil
+}
+
+// save stores a rotated refresh token, logging a failure so that the next
+// call to Token tries again. Callers must hold s.mu.
+func (s *RefreshingTokenSource) save(ctx context.Context) {
+	if err := s.store.SaveRefreshToken(ctx, s.refreshToken); err != nil {
+		log.FromContext(ctx).Error(err, "error saving rotated refresh token, will retry")
+		return
+	}
+	s.unsaved = false
+}
+
+// NewRefreshingTokenSourceFromSecret creates a refreshing token source for a
+// provider from a Kubernetes Secret holding the refresh token in key and the
+// OAuth2 application credentials. Rotated refresh tokens are written back to
+// key with c, so they survive restarts, which the Secret must allow with
+// RefreshTokenLabel.
+func NewRefreshingTokenSourceFromSecret(c client.Client, provider string, secret *corev1.Secret, key string) (*RefreshingTokenSource, error) {
+	if !rotatable(secret) {
+		return nil, fmt.Errorf("secret %s/%s must be labeled %s=true to hold a refresh token", secret.Namespace, secret.Name, RefreshTokenLabel)
+	}
+	var values [3]string
+	for i, name := range []string{key, SecretKeyClientID, SecretKeyClientSecret} {
+		value, ok := secret.Data[name]
+		if !ok {
+			return nil, fmt.Errorf("secret %s/%s is missing key %q", secret.Namespace, secret.Name, name)
+		}
+		values[i] = strings.TrimSpace(string(value))
+	}
+	config, err := OAuth2ConfigFor(provider, values[1], values[2])
+	if err != nil {
+		return nil, err
+	}
+	store := NewSecretRefreshTokenStore(c, types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, key)
+
+	return NewRefreshingTokenSource(config, values[0], store), nil
+}
+
+// rotatable reports whether a Secret allows the operator to write rotated
+// refresh tokens to it
+func rotatable(secret *corev1.Secret) bool {
+	return secret.Labels[RefreshTokenLabel] == "true"
+}
+
+// SecretRefreshTokenStore stores refresh tokens in a key of a Kubernetes
+// Secret labeled with RefreshTokenLabel
+type SecretRefreshTokenStore struct {
+	client client.Client
+	name   types.NamespacedName
+	key    string
+}
+
+// NewSecretRefreshTokenStore creates a store writing to the given Secret key
+func NewSecretRefreshTokenStore(c client.Client, name types.NamespacedName, key string) *SecretRefreshTokenStore {
+	return &SecretRefreshTokenStore{
+		client: c,
+		name:   name,
+		key:    key,
+	}
+}
+
+// SaveRefreshToken implements RefreshTokenStore
+func (s *SecretRefreshTokenStore) SaveRefreshToken(ctx context.Context, refreshToken string) error {
+	var secret corev1.Secret
+	if err := s.client.Get(ctx, s.name, &secret); err != nil {
+		return fmt.Errorf("error getting secret %s: %w", s.name, err)
+	}
+	if !rotatable(&secret) {
+		return fmt.Errorf("secret %s is not labeled %s=true", s.name, RefreshTokenLabel)
+	}
+
+	patch := client.MergeFrom(secret.DeepCopy())
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	secret.Data[s.key] = []byte(refreshToken)
+
+	if err := s.client.Patch(ctx, &secret, patch); err != nil {
+		return fmt.Errorf("error updating secret %s: %w", s.name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// secretReadTimeout bounds how long Token waits to read the Secret
+const secretReadTimeout = 10 * time.Second
+
+// SecretTokenSource is a TokenSource that reads the token from a key of a
+// Kubernetes Secret on every call. When backed by a manager's cached client
+// the Secret is watched, so rotated tokens are picked up without a restart.
+type SecretTokenSource struct {
+	reader client.Reader
+	name   types.NamespacedName
+	key    string
+}
+
+// NewSecretTokenSource creates a token source reading from the given Secret key
+func NewSecretTokenSource(reader client.Reader, name types.NamespacedName, key string) *SecretTokenSource {
+	return &SecretTokenSource{
+		reader: reader,
+		name:   name,
+		key:    key,
+	}
+}
+
+// Token implements TokenSource
+func (s *SecretTokenSource) Token() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretReadTimeout)
+	defer cancel()
+
+	var secret corev1.Secret
+	if err := s.reader.Get(ctx, s.name, &secret); err != nil {
+		return "", fmt.Errorf("error getting secret %s: %w", s.name, err)
+	}
+
+	token, ok := secret.Data[s.key]
+	if !ok {
+		return "", fmt.Errorf("secret %s is missing key %q", s.name, s.key)
+	}
+
+	return strings.TrimSpace(string(token)), nil
+}
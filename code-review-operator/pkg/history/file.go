@@ -0,0 +1,85 @@
+package history
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"sync"
+)
+
+// FileStore keeps records in a file, one JSON record per line. It suits a
+// single operator replica writing to a persistent volume.
+type FileStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileStore creates a store appending records to the file at path
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Save implements Store
+func (s *FileStore) Save(ctx context.Context, record *Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error encoding review record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("error opening review history: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("error writing review history: %w", err)
+	}
+
+	return file.Close()
+}
+
+// List implements Store
+func (s *FileStore) List(ctx context.Context, query Query) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []Record{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening review history: %w", err)
+	}
+	defer file.Close()
+
+	records := []Record{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64<<10), 16<<20)
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("error decoding review history: %w", err)
+		}
+		if query.Matches(&record) {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading review history: %w", err)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool { return records[i].CompletedAt.After(records[j].CompletedAt) })
+	if limit := query.limit(); len(records) > limit {
+		records = records[:limit]
+	}
+
+	return records, nil
+}
@@ -0,0 +1,148 @@
+// Package history keeps a record of every completed review, for auditing the
+// reviews posted and measuring their usefulness over time.
+package history
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+)
+
+const (
+	// DefaultLimit is the number of records a query returns by default
+	DefaultLimit = 50
+
+	// MaxLimit is the largest number of records a query returns
+	MaxLimit = 1000
+)
+
+// Record is a completed review
+type Record struct {
+	// Namespace and Name identify the CodeReview resource
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+
+	Provider    string `json:"provider"`
+	Owner       string `json:"owner"`
+	Repository  string `json:"repository"`
+	PullRequest int    `json:"pullRequest"`
+	CommitSHA   string `json:"commitSHA"`
+
+	// Decision is the review decision posted, if any
+	Decision string `json:"decision,omitempty"`
+
+	// Comments are the findings posted
+	Comments []llm.ReviewComment `json:"comments"`
+
+	PromptTokens     int64 `json:"promptTokens"`
+	CompletionTokens int64 `json:"completionTokens"`
+	TotalTokens      int64 `json:"totalTokens"`
+
+	// Models are the LLM models the review used
+	Models []string `json:"models,omitempty"`
+
+	// Duration is the time from the start of the review to its completion
+	Duration time.Duration `json:"duration"`
+
+	CompletedAt time.Time `json:"completedAt"`
+}
+
+// Query selects records. Empty fields match every record.
+type Query struct {
+	Owner       string
+	Repository  string
+	PullRequest int
+
+	// Since excludes records completed before it
+	Since time.Time
+
+	// Limit is the maximum number of records returned (defaults to
+	// DefaultLimit, at most MaxLimit)
+	Limit int
+}
+
+// Store persists review records
+type Store interface {
+	// Save adds a record
+	Save(ctx context.Context, record *Record) error
+
+	// List returns the records matching a query, most recent first
+	List(ctx context.Context, query Query) ([]Record, error)
+}
+
+// Matches reports whether the query selects a record
+func (q Query) Matches(record *Record) bool {
+	return (q.Owner == "" || record.Owner == q.Owner) &&
+		(q.Repository == "" || record.Repository == q.Repository) &&
+		(q.PullRequest == 0 || record.PullRequest == q.PullRequest) &&
+		!record.CompletedAt.Before(q.Since)
+}
+
+// limit returns the effective limit of the query
+func (q Query) limit() int {
+	if q.Limit <= 0 {
+		return DefaultLimit
+	}
+
+	return min(q.Limit, MaxLimit)
+}
+
+// Values encodes the query as URL parameters, the inverse of ParseQuery
+func (q Query) Values() url.Values {
+	values := url.Values{}
+	if q.Owner != "" || q.Repository != "" {
+		values.Set("repository", q.Owner+"/"+q.Repository)
+	}
+	if q.PullRequest != 0 {
+		values.Set("pr", strconv.Itoa(q.PullRequest))
+	}
+	if !q.Since.IsZero() {
+		values.Set("since", q.Since.UTC().Format(time.RFC3339))
+	}
+	if q.Limit != 0 {
+		values.Set("limit", strconv.Itoa(q.Limit))
+	}
+
+	return values
+}
+
+// ParseQuery parses a query from URL parameters: repository as owner/name
+// or owner, pr, since as an RFC 3339 time, and limit
+func ParseQuery(values url.Values) (Query, error) {
+	var query Query
+	if repository := values.Get("repository"); repository != "" {
+		owner, repo, _ := strings.Cut(repository, "/")
+		if owner == "" {
+			return query, fmt.Errorf("invalid repository %q, expected owner/name", repository)
+		}
+		query.Owner, query.Repository = owner, repo
+	}
+	if pr := values.Get("pr"); pr != "" {
+		number, err := strconv.Atoi(pr)
+		if err != nil || number <= 0 {
+			return query, fmt.Errorf("invalid pull request number %q", pr)
+		}
+		query.PullRequest = number
+	}
+	if since := values.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return query, fmt.Errorf("invalid since time %q: %w", since, err)
+		}
+		query.Since = t
+	}
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			return query, fmt.Errorf("invalid limit %q", limit)
+		}
+		query.Limit = n
+	}
+
+	return query, nil
+}
@@ -0,0 +1,120 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Server serves the review history over HTTP. GET /reviews returns the
+// records matching the query parameters read by ParseQuery, as a JSON array.
+type Server struct {
+	// Addr is the address the server listens on
+	Addr string
+
+	// Store holds the records
+	Store Store
+}
+
+// Start implements manager.Runnable
+func (s *Server) Start(ctx context.Context) error {
+	server := &http.Server{
+		Addr:              s.Addr,
+		Handler:           Handler(s.Store),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+	log.FromContext(ctx).Info("serving review history", "address", s.Addr)
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	case err := <-errs:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. The history
+// is read-only here, so every replica serves it.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Handler returns the HTTP handler of the history API
+func Handler(store Store) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/reviews", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		query, err := ParseQuery(req.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		records, err := store.List(req.Context(), query)
+		if err != nil {
+			log.FromContext(req.Context()).Error(err, "unable to list review history")
+			http.Error(w, "error reading review history", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(records); err != nil {
+			log.FromContext(req.Context()).Error(err, "unable to write review history")
+		}
+	})
+
+	return mux
+}
+
+// Client queries a history server
+type Client struct {
+	// URL is the base URL of the server
+	URL string
+
+	// HTTPClient sends the requests (defaults to http.DefaultClient)
+	HTTPClient *http.Client
+}
+
+// List returns the records matching a query, most recent first
+func (c *Client) List(ctx context.Context, query Query) ([]Record, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL+"/reviews?"+query.Values().Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("review history server returned %s", resp.Status)
+	}
+
+	var records []Record
+	if err := json.NewDecoder(resp.Body).Decode(&records); err != nil {
+		return nil, fmt.Errorf("error decoding review history: %w", err)
+	}
+
+	return records, nil
+}
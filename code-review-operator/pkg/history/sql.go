@@ -0,0 +1,186 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schemas create the history table of each supported database. Times are
+// stored in Unix milliseconds, which every driver scans alike.
+var schemas = map[string][]string{
+	"sqlite": {
+		`CREATE TABLE IF NOT EXISTS review_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			namespace TEXT NOT NULL, name TEXT NOT NULL,
+			provider TEXT NOT NULL, owner TEXT NOT NULL, repository TEXT NOT NULL,
+			pull_request INTEGER NOT NULL, commit_sha TEXT NOT NULL, decision TEXT NOT NULL,
+			comments TEXT NOT NULL, models TEXT NOT NULL,
+			prompt_tokens INTEGER NOT NULL, completion_tokens INTEGER NOT NULL, total_tokens INTEGER NOT NULL,
+			duration_ms INTEGER NOT NULL, completed_at INTEGER NOT NULL)`,
+		`CREATE INDEX IF NOT EXISTS review_history_repository ON review_history (owner, repository, completed_at)`,
+	},
+	"postgres": {
+		`CREATE TABLE IF NOT EXISTS review_history (
+			id BIGSERIAL PRIMARY KEY,
+			namespace TEXT NOT NULL, name TEXT NOT NULL,
+			provider TEXT NOT NULL, owner TEXT NOT NULL, repository TEXT NOT NULL,
+			pull_request INTEGER NOT NULL, commit_sha TEXT NOT NULL, decision TEXT NOT NULL,
+			comments TEXT NOT NULL, models TEXT NOT NULL,
+			prompt_tokens BIGINT NOT NULL, completion_tokens BIGINT NOT NULL, total_tokens BIGINT NOT NULL,
+			duration_ms BIGINT NOT NULL, completed_at BIGINT NOT NULL)`,
+		`CREATE INDEX IF NOT EXISTS review_history_repository ON review_history (owner, repository, completed_at)`,
+	},
+}
+
+// dialects maps database/sql driver names to the database they speak
+var dialects = map[string]string{
+	"sqlite":   "sqlite",
+	"sqlite3":  "sqlite",
+	"postgres": "postgres",
+	"pgx":      "postgres",
+}
+
+// SQLStore keeps records in a SQLite or PostgreSQL database. The database
+// driver must be linked into the binary.
+type SQLStore struct {
+	db      *sql.DB
+	dialect string
+}
+
+// OpenSQLStore opens the database with the named database/sql driver and
+// creates the history table if needed
+func OpenSQLStore(ctx context.Context, driver, dsn string) (*SQLStore, error) {
+	dialect, ok := dialects[driver]
+	if !ok {
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error opening review history database: %w", err)
+	}
+	for _, statement := range schemas[dialect] {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("error creating review history table: %w", err)
+		}
+	}
+
+	return &SQLStore{db: db, dialect: dialect}, nil
+}
+
+// Close closes the database
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
+
+// Save implements Store
+func (s *SQLStore) Save(ctx context.Context, record *Record) error {
+	comments, err := json.Marshal(record.Comments)
+	if err != nil {
+		return fmt.Errorf("error encoding review comments: %w", err)
+	}
+	models, err := json.Marshal(record.Models)
+	if err != nil {
+		return fmt.Errorf("error encoding review models: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, s.rebind(`INSERT INTO review_history (
+		namespace, name, provider, owner, repository, pull_request, commit_sha, decision, comments, models,
+		prompt_tokens, completion_tokens, total_tokens, duration_ms, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		record.Namespace, record.Name, record.Provider, record.Owner, record.Repository, record.PullRequest,
+		record.CommitSHA, record.Decision, string(comments), string(models),
+		record.PromptTokens, record.CompletionTokens, record.TotalTokens,
+		record.Duration.Milliseconds(), record.CompletedAt.UnixMilli())
+	if err != nil {
+		return fmt.Errorf("error saving review record: %w", err)
+	}
+
+	return nil
+}
+
+// List implements Store
+func (s *SQLStore) List(ctx context.Context, query Query) ([]Record, error) {
+	var conditions []string
+	var args []any
+	if query.Owner != "" {
+		conditions = append(conditions, "owner = ?")
+		args = append(args, query.Owner)
+	}
+	if query.Repository != "" {
+		conditions = append(conditions, "repository = ?")
+		args = append(args, query.Repository)
+	}
+	if query.PullRequest != 0 {
+		conditions = append(conditions, "pull_request = ?")
+		args = append(args, query.PullRequest)
+	}
+	if !query.Since.IsZero() {
+		conditions = append(conditions, "completed_at >= ?")
+		args = append(args, query.Since.UnixMilli())
+	}
+
+	statement := `SELECT namespace, name, provider, owner, repository, pull_request, commit_sha, decision, comments, models,
+		prompt_tokens, completion_tokens, total_tokens, duration_ms, completed_at FROM review_history`
+	if len(conditions) > 0 {
+		statement += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	statement += " ORDER BY completed_at DESC, id DESC LIMIT " + strconv.Itoa(query.limit())
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(statement), args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying review history: %w", err)
+	}
+	defer rows.Close()
+
+	records := []Record{}
+	for rows.Next() {
+		var record Record
+		var comments, models string
+		var duration, completed int64
+		if err := rows.Scan(&record.Namespace, &record.Name, &record.Provider, &record.Owner, &record.Repository,
+			&record.PullRequest, &record.CommitSHA, &record.Decision, &comments, &models,
+			&record.PromptTokens, &record.CompletionTokens, &record.TotalTokens, &duration, &completed); err != nil {
+			return nil, fmt.Errorf("error reading review history: %w", err)
+		}
+		if err := json.Unmarshal([]byte(comments), &record.Comments); err != nil {
+			return nil, fmt.Errorf("error decoding review comments: %w", err)
+		}
+		if err := json.Unmarshal([]byte(models), &record.Models); err != nil {
+			return nil, fmt.Errorf("error decoding review models: %w", err)
+		}
+		record.Duration = time.Duration(duration) * time.Millisecond
+		record.CompletedAt = time.UnixMilli(completed).UTC()
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading review history: %w", err)
+	}
+
+	return records, nil
+}
+
+// rebind rewrites the ? placeholders of a statement for the store's database
+func (s *SQLStore) rebind(statement string) string {
+	if s.dialect != "postgres" {
+		return statement
+	}
+
+	var rebound strings.Builder
+	n := 0
+	for _, r := range statement {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&rebound, "$%d", n)
+			continue
+		}
+		rebound.WriteRune(r)
+	}
+
+	return rebound.String()
+}
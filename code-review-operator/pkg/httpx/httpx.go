@@ -0,0 +1,194 @@
+// Package httpx is the HTTP layer shared by the operator's outbound clients:
+// the Git providers, the LLM backends, their credential sources and the
+// notification, telemetry and event sinks. Requests are observed by host, and
+// the requests in flight to each host are limited, so that a slow host holds
+// at most its own budget of connections and cannot starve the others.
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/metrics"
+)
+
+// DefaultMaxRequestsPerHost is the default limit of requests in flight to a host
+const DefaultMaxRequestsPerHost = 32
+
+// Limits are the budgets of requests in flight to each host
+type Limits struct {
+	// PerHost is the limit of requests in flight to a host without its own
+	// limit; 0 is unlimited
+	PerHost int
+
+	// Hosts are the limits of individual hosts, by host name
+	Hosts map[string]int
+}
+
+// ParseHostLimits parses limits of individual hosts in the form host=limit,
+// separated by commas, such as "api.openai.com=8,github.com=16"
+func ParseHostLimits(value string) (map[string]int, error) {
+	hosts := make(map[string]int)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, limit, ok := strings.Cut(entry, "=")
+		if !ok || host == "" {
+			return nil, fmt.Errorf("invalid host limit %q: must be host=limit", entry)
+		}
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid limit of host %s: %q", host, limit)
+		}
+		hosts[strings.ToLower(host)] = n
+	}
+
+	return hosts, nil
+}
+
+// limiter holds the slots of requests in flight to each host
+type limiter struct {
+	mu     sync.Mutex
+	limits Limits
+	slots  map[string]chan struct{}
+}
+
+// shared limits the requests of every client of the package
+var shared = &limiter{
+	limits: Limits{PerHost: DefaultMaxRequestsPerHost},
+	slots:  make(map[string]chan struct{}),
+}
+
+// SetLimits sets the budgets of requests in flight to each host. It is meant
+// to be called at startup; requests already in flight keep the slots they hold.
+func SetLimits(limits Limits) {
+	shared.mu.Lock()
+	defer shared.mu.Unlock()
+
+	shared.limits = limits
+	shared.slots = make(map[string]chan struct{})
+}
+
+// slotsOf returns the slots of a host, or nil if its requests are unlimited
+func (l *limiter) slotsOf(host string) chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if slots, ok := l.slots[host]; ok {
+		return slots
+	}
+	limit, ok := l.limits.Hosts[host]
+	if !ok {
+		limit = l.limits.PerHost
+	}
+	var slots chan struct{}
+	if limit > 0 {
+		slots = make(chan struct{}, limit)
+	}
+	l.slots[host] = slots
+
+	return slots
+}
+
+// acquire waits for a slot of a host, or for the context to be done. It
+// returns the function releasing the slot.
+func (l *limiter) acquire(ctx context.Context, host string) (func(), error) {
+	slots := l.slotsOf(host)
+	if slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case slots <- struct{}{}:
+	default:
+		start := time.Now()
+		select {
+		case slots <- struct{}{}:
+			metrics.HTTPRequestWait.WithLabelValues(host).Observe(time.Since(start).Seconds())
+		case <-ctx.Done():
+			metrics.HTTPRequestWait.WithLabelValues(host).Observe(time.Since(start).Seconds())
+			return nil, fmt.Errorf("waiting for a free connection to %s: %w", host, ctx.Err())
+		}
+	}
+
+	return func() { <-slots }, nil
+}
+
+// Transport is an http.RoundTripper observing requests and holding them to
+// the per-host budgets shared by every Transport
+type Transport struct {
+	// Base sends the requests (defaults to http.DefaultTransport)
+	Base http.RoundTripper
+}
+
+// NewTransport creates a Transport sending requests with base, which may be
+// nil for http.DefaultTransport
+func NewTransport(base http.RoundTripper) *Transport {
+	return &Transport{Base: base}
+}
+
+// NewClient creates an HTTP client with the given timeout sending requests
+// through the shared layer
+func NewClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: NewTransport(nil),
+		Timeout:   timeout,
+	}
+}
+
+// RoundTrip implements http.RoundTripper. A request holds its host's slot
+// until its response body is closed, so streamed responses count against
+// the budget while they are read.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := strings.ToLower(req.URL.Hostname())
+	release, err := shared.acquire(req.Context(), host)
+	if err != nil {
+		return nil, err
+	}
+
+	base := t.Base
+	if base == nil {
+		// Read at request time, as compliance mode hardens the default transport at startup
+		base = http.DefaultTransport
+	}
+	metrics.HTTPRequestsInFlight.WithLabelValues(host).Inc()
+	done := func() {
+		release()
+		metrics.HTTPRequestsInFlight.WithLabelValues(host).Dec()
+	}
+
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		metrics.HTTPRequestDuration.WithLabelValues(host, req.Method, "error").Observe(time.Since(start).Seconds())
+		done()
+		return nil, err
+	}
+	metrics.HTTPRequestDuration.WithLabelValues(host, req.Method, strconv.Itoa(resp.StatusCode)).Observe(time.Since(start).Seconds())
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: done}
+
+	return resp, nil
+}
+
+// releasingBody releases its request's slot when closed
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+// Close implements io.Closer
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+
+	return err
+}
@@ -0,0 +1,454 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/httpx"
+)
+
+const (
+	// DefaultAnthropicBaseURL is the default Anthropic API base URL
+	DefaultAnthropicBaseURL = "https://api.anthropic.com/v1"
+
+	// DefaultAnthropicModel is the default Anthropic model
+	DefaultAnthropicModel = "claude-3-5-sonnet-latest"
+
+	// AnthropicAPIVersion is the Anthropic API version sent with every request
+	AnthropicAPIVersion = "2023-06-01"
+
+	// defaultAnthropicMaxTokens is used when ReviewOptions does not set MaxTokens
+	defaultAnthropicMaxTokens = 4096
+
+	// anthropicStatusOverloaded is returned when the API is temporarily overloaded
+	anthropicStatusOverloaded = 529
+
+	// anthropicStopMaxTokens is the stop reason of answers cut off at max_tokens
+	anthropicStopMaxTokens = "max_tokens"
+
+	// reviewToolName is the name of the tool the model submits its review with
+	reviewToolName = "submit_review"
+)
+
+// AnthropicConfig configures an Anthropic client
+type AnthropicConfig struct {
+	// BaseURL is the API base URL (defaults to DefaultAnthropicBaseURL)
+	BaseURL string
+
+	// APIKey is the API key
+	APIKey string
+
+	// Model is the model name (defaults to DefaultAnthropicModel)
+	Model string
+
+	// MaxRetries is the number of retries on rate-limited or overloaded responses
+	MaxRetries int
+}
+
+// AnthropicClient implements the Client interface using the Anthropic Messages
+// API, served by Anthropic or by a cloud platform hosting the models
+type AnthropicClient struct {
+	config     AnthropicConfig
+	platform   anthropicPlatform
+	httpClient *http.Client
+}
+
+// anthropicPlatform creates authenticated Messages API requests for where the
+// models are hosted
+type anthropicPlatform interface {
+	// newRequest creates a request sending a Messages API body
+	newRequest(ctx context.Context, reqBytes []byte) (*http.Request, error)
+}
+
+// anthropicAPI is the Anthropic API, authenticated with an API key
+type anthropicAPI struct {
+	config AnthropicConfig
+}
+
+// anthropicTool describes a tool the model can call
+type anthropicTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+// anthropicRequest is the body of a Messages API request
+type anthropicRequest struct {
+	Model       string            `json:"model"`
+	System      string            `json:"system"`
+	Messages    []chatMessage     `json:"messages"`
+	MaxTokens   int               `json:"max_tokens"`
+	Temperature float32           `json:"temperature"`
+	Tools       []anthropicTool   `json:"tools,omitempty"`
+	ToolChoice  map[string]string `json:"tool_choice,omitempty"`
+	Stream      bool              `json:"stream,omitempty"`
+}
+
+// anthropicStreamEvent is an event of a streamed Messages API response
+type anthropicStreamEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		Usage struct {
+			InputTokens int `json:"input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+	ContentBlock struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+	} `json:"content_block"`
+	Delta struct {
+		Type        string `json:"type"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// anthropicResponse is the body of a Messages API response
+type anthropicResponse struct {
+	Content []struct {
+		Type  string          `json:"type"`
+		Text  string          `json:"text"`
+		Name  string          `json:"name"`
+		Input json.RawMessage `json:"input"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// NewAnthropicClient creates a new Anthropic client
+func NewAnthropicClient(config AnthropicConfig) *AnthropicClient {
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultAnthropicBaseURL
+	}
+	if config.Model == "" {
+		config.Model = DefaultAnthropicModel
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	}
+
+	return &AnthropicClient{
+		config:     config,
+		platform:   anthropicAPI{config: config},
+		httpClient: httpx.NewClient(5 * time.Minute), // Code review might take a while
+	}
+}
+
+// ReviewCode sends the diff to the Messages API, forcing the model to answer
+// through a tool whose input schema is the review format
+func (c *AnthropicClient) ReviewCode(ctx context.Context, diff string, options ReviewOptions) (*ReviewResult, error) {
+	reqBody, err := c.reviewRequest(diff, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Marshal the request to JSON
+	reqBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	body, err := c.send(ctx, reqBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the response
+	var message anthropicResponse
+	if err := json.Unmarshal(body, &message); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+	if message.StopReason == anthropicStopMaxTokens {
+		return nil, fmt.Errorf("error parsing response: %w", ErrOutputTruncated)
+	}
+
+	for _, block := range message.Content {
+		if block.Type != "tool_use" || block.Name != reviewToolName {
+			continue
+		}
+
+		return c.reviewResult(block.Input, message.Usage.InputTokens, message.Usage.OutputTokens)
+	}
+
+	return nil, fmt.Errorf("error parsing response: no %s tool call (stop reason: %s)", reviewToolName, message.StopReason)
+}
+
+// ReviewCodeStream implements Streamer, delivering the comments and summary
+// of the review tool call as its input is streamed. Cloud platforms stream
+// in their own formats; their reviews are delivered once complete.
+func (c *AnthropicClient) ReviewCodeStream(ctx context.Context, diff string, options ReviewOptions, onEvent StreamFunc) (*ReviewResult, error) {
+	if _, ok := c.platform.(anthropicAPI); !ok {
+		result, err := c.ReviewCode(ctx, diff, options)
+		if err != nil {
+			return nil, err
+		}
+		emitResult(result, onEvent)
+		return result, nil
+	}
+
+	reqBody, err := c.reviewRequest(diff, options)
+	if err != nil {
+		return nil, err
+	}
+	reqBody.Stream = true
+	reqBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	resp, err := c.do(ctx, reqBytes)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var input strings.Builder
+	var inputTokens, outputTokens int
+	var stopReason string
+	inReview := false
+	parser := newStreamParser(onEvent)
+	err = readSSE(resp.Body, func(_, data string) error {
+		var event anthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return fmt.Errorf("error parsing response stream: %w", err)
+		}
+		switch event.Type {
+		case "message_start":
+			inputTokens = event.Message.Usage.InputTokens
+		case "content_block_start":
+			inReview = event.ContentBlock.Type == "tool_use" && event.ContentBlock.Name == reviewToolName
+		case "content_block_delta":
+			if inReview && event.Delta.Type == "input_json_delta" {
+				input.WriteString(event.Delta.PartialJSON)
+				parser.Write(event.Delta.PartialJSON)
+			}
+		case "content_block_stop":
+			inReview = false
+		case "message_delta":
+			outputTokens = event.Usage.OutputTokens
+			stopReason = event.Delta.StopReason
+		case "error":
+			return fmt.Errorf("error in response stream: %s: %s", event.Error.Type, event.Error.Message)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if stopReason == anthropicStopMaxTokens {
+		return nil, fmt.Errorf("error parsing response: %w", ErrOutputTruncated)
+	}
+	if input.Len() == 0 {
+		return nil, fmt.Errorf("error parsing response: no %s tool call (stop reason: %s)", reviewToolName, stopReason)
+	}
+
+	return c.reviewResult(json.RawMessage(input.String()), inputTokens, outputTokens)
+}
+
+// reviewResult parses the input of the review tool call
+func (c *AnthropicClient) reviewResult(input json.RawMessage, inputTokens, outputTokens int) (*ReviewResult, error) {
+	var result ReviewResult
+	if err := json.Unmarshal(input, &result); err != nil {
+		return nil, fmt.Errorf("error parsing review: %w", err)
+	}
+	result.TokensUsed = inputTokens + outputTokens
+	result.Usage = []Usage{{
+		Model:            c.config.Model,
+		PromptTokens:     inputTokens,
+		CompletionTokens: outputTokens,
+	}}
+
+	return &result, nil
+}
+
+// reviewRequest builds the Messages API request reviewing a diff, forcing
+// the model to answer through the review tool
+func (c *AnthropicClient) reviewRequest(diff string, options ReviewOptions) (anthropicRequest, error) {
+	maxTokens := options.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = defaultAnthropicMaxTokens
+	}
+	system, user, err := buildPrompt(diff, options)
+	if err != nil {
+		return anthropicRequest{}, err
+	}
+
+	return anthropicRequest{
+		Model:       c.config.Model,
+		System:      system,
+		Messages:    []chatMessage{{Role: "user", Content: user}},
+		MaxTokens:   maxTokens,
+		Temperature: options.Temperature,
+		Tools: []anthropicTool{{
+			Name:        reviewToolName,
+			Description: "Submit the code review comments and summary",
+			InputSchema: reviewSchema,
+		}},
+		ToolChoice: map[string]string{"type": "tool", "name": reviewToolName},
+	}, nil
+}
+
+// Summarize consolidates the summaries of a chunked review into one
+func (c *AnthropicClient) Summarize(ctx context.Context, summaries []string, options ReviewOptions) (string, error) {
+	maxTokens := options.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = defaultAnthropicMaxTokens
+	}
+	system, user := buildSummaryPrompt(summaries)
+
+	// Marshal the request to JSON
+	reqBytes, err := json.Marshal(anthropicRequest{
+		Model:       c.config.Model,
+		System:      system,
+		Messages:    []chatMessage{{Role: "user", Content: user}},
+		MaxTokens:   maxTokens,
+		Temperature: options.Temperature,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	body, err := c.send(ctx, reqBytes)
+	if err != nil {
+		return "", err
+	}
+
+	// Parse the response
+	var message anthropicResponse
+	if err := json.Unmarshal(body, &message); err != nil {
+		return "", fmt.Errorf("error parsing response: %w", err)
+	}
+
+	var summary strings.Builder
+	for _, block := range message.Content {
+		if block.Type == "text" {
+			summary.WriteString(block.Text)
+		}
+	}
+
+	return strings.TrimSpace(summary.String()), nil
+}
+
+// send posts a request to the Messages API, retrying rate-limited and
+// overloaded responses, and returns the response body
+func (c *AnthropicClient) send(ctx context.Context, reqBytes []byte) ([]byte, error) {
+	resp, err := c.do(ctx, reqBytes)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Read the response body
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+
+	return body, nil
+}
+
+// do posts a request to the Messages API, retrying rate-limited and
+// overloaded responses, and returns the successful response, whose body the
+// caller must close
+func (c *AnthropicClient) do(ctx context.Context, reqBytes []byte) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		// Create the request; credentials may have been refreshed since the last attempt
+		req, err := c.platform.newRequest(ctx, reqBytes)
+		if err != nil {
+			return nil, err
+		}
+
+		// Send the request
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("error sending request: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			return resp, nil
+		}
+
+		// Read the error response body
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading response: %w", err)
+		}
+
+		retryable := resp.StatusCode == http.StatusTooManyRequests ||
+			resp.StatusCode == anthropicStatusOverloaded ||
+			resp.StatusCode >= http.StatusInternalServerError
+		if !retryable || attempt >= c.config.MaxRetries {
+			return nil, newAPIError("Anthropic API", resp, body)
+		}
+
+		// Honor retry-after, otherwise back off exponentially with jitter
+		delay := time.Duration(1<<uint(attempt)) * time.Second
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+		if seconds, err := strconv.Atoi(resp.Header.Get("retry-after")); err == nil {
+			delay = time.Duration(seconds) * time.Second
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// newRequest implements anthropicPlatform
+func (p anthropicAPI) newRequest(ctx context.Context, reqBytes []byte) (*http.Request, error) {
+	url := strings.TrimSuffix(p.config.BaseURL, "/") + "/messages"
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(reqBytes))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", p.config.APIKey)
+	req.Header.Set("anthropic-version", AnthropicAPIVersion)
+
+	return req, nil
+}
+
+// platformBody adapts a Messages API body for a cloud platform, which takes
+// the model from the URL and the API version from the body
+func platformBody(reqBytes []byte, version string) ([]byte, error) {
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(reqBytes, &body); err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+	delete(body, "model")
+	body["anthropic_version"], _ = json.Marshal(version)
+
+	reqBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	return reqBytes, nil
+}
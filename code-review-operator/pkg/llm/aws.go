@@ -0,0 +1,240 @@
+package llm
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/httpx"
+)
+
+// defaultAWSRoleSessionName is the session name used when assuming a role
+const defaultAWSRoleSessionName = "code-review-operator"
+
+// AWSCredentials are temporary AWS security credentials
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// AWSWebIdentityCredentials provides AWS credentials using IAM roles for
+// service accounts (IRSA): the Kubernetes service account token projected
+// into the pod is exchanged for temporary role credentials with STS. It is
+// safe for concurrent use.
+type AWSWebIdentityCredentials struct {
+	client *http.Client
+
+	mu          sync.Mutex
+	credentials AWSCredentials
+	expiresAt   time.Time
+}
+
+// assumeRoleWithWebIdentityResponse is the body of an STS AssumeRoleWithWebIdentity response
+type assumeRoleWithWebIdentityResponse struct {
+	Result struct {
+		Credentials struct {
+			AccessKeyID     string    `xml:"AccessKeyId"`
+			SecretAccessKey string    `xml:"SecretAccessKey"`
+			SessionToken    string    `xml:"SessionToken"`
+			Expiration      time.Time `xml:"Expiration"`
+		} `xml:"Credentials"`
+	} `xml:"AssumeRoleWithWebIdentityResult"`
+}
+
+// NewAWSWebIdentityCredentials creates a credentials provider. The role and
+// token file are read from the AWS_ROLE_ARN and AWS_WEB_IDENTITY_TOKEN_FILE
+// variables injected by the EKS pod identity webhook.
+func NewAWSWebIdentityCredentials() *AWSWebIdentityCredentials {
+	return &AWSWebIdentityCredentials{
+		client: httpx.NewClient(30 * time.Second),
+	}
+}
+
+// Credentials returns cached credentials, refreshing them shortly before they expire
+func (p *AWSWebIdentityCredentials) Credentials() (AWSCredentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.credentials.AccessKeyID != "" && time.Until(p.expiresAt) > credentialRefreshSkew {
+		return p.credentials, nil
+	}
+
+	roleARN, tokenFile := os.Getenv("AWS_ROLE_ARN"), os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	if roleARN == "" || tokenFile == "" {
+		return AWSCredentials{}, fmt.Errorf("AWS web identity is not configured: AWS_ROLE_ARN and AWS_WEB_IDENTITY_TOKEN_FILE must be set")
+	}
+	sessionName := os.Getenv("AWS_ROLE_SESSION_NAME")
+	if sessionName == "" {
+		sessionName = defaultAWSRoleSessionName
+	}
+
+	// The projected token is rotated by the kubelet, so read it on every refresh
+	webIdentityToken, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("error reading web identity token: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.client.Timeout)
+	defer cancel()
+
+	form := url.Values{}
+	form.Set("Action", "AssumeRoleWithWebIdentity")
+	form.Set("Version", "2011-06-15")
+	form.Set("RoleArn", roleARN)
+	form.Set("RoleSessionName", sessionName)
+	form.Set("WebIdentityToken", strings.TrimSpace(string(webIdentityToken)))
+
+	// Create request; the call is authenticated by the token, not signed
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, stsURL(os.Getenv("AWS_REGION")), strings.NewReader(form.Encode()))
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	// Send the request
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("error assuming role: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read the response body
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("error reading response: %w", err)
+	}
+
+	// Check for errors
+	if resp.StatusCode != http.StatusOK {
+		return AWSCredentials{}, fmt.Errorf("error assuming role: %s (status code: %d)", string(body), resp.StatusCode)
+	}
+
+	var result assumeRoleWithWebIdentityResponse
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return AWSCredentials{}, fmt.Errorf("error parsing response: %w", err)
+	}
+	credentials := result.Result.Credentials
+	if credentials.AccessKeyID == "" {
+		return AWSCredentials{}, fmt.Errorf("error parsing response: no credentials returned")
+	}
+
+	p.credentials = AWSCredentials{
+		AccessKeyID:     credentials.AccessKeyID,
+		SecretAccessKey: credentials.SecretAccessKey,
+		SessionToken:    credentials.SessionToken,
+	}
+	p.expiresAt = credentials.Expiration
+
+	return p.credentials, nil
+}
+
+// stsURL returns the regional STS endpoint, or the global one if no region is set
+func stsURL(region string) string {
+	if region == "" {
+		return "https://sts.amazonaws.com/"
+	}
+
+	return "https://sts." + region + ".amazonaws.com/"
+}
+
+// signAWSRequest signs a request with AWS Signature Version 4
+func signAWSRequest(req *http.Request, body []byte, credentials AWSCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	scope := strings.Join([]string{amzDate[:8], region, service, "aws4_request"}, "/")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+
+	// Build the canonical request from the headers being signed
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(req.Header.Get(name))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL),
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		sha256Hex(body),
+	}, "\n")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	// Derive the signing key for the date, region and service
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), amzDate[:8])
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		credentials.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalURI returns the path of a request as signed by AWS services other
+// than S3, which encode each segment of the already escaped path again
+func canonicalURI(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = awsEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// awsEscape percent-encodes every byte except the unreserved characters
+func awsEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
+
+// sha256Hex returns the hex encoded SHA-256 hash of data
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with the given key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// DefaultBedrockModel is the default Anthropic model on Amazon Bedrock
+	DefaultBedrockModel = "anthropic.claude-3-5-sonnet-20241022-v2:0"
+
+	// bedrockAnthropicVersion is the Messages API version sent to Bedrock
+	bedrockAnthropicVersion = "bedrock-2023-05-31"
+)
+
+// BedrockConfig configures a client for Anthropic models on Amazon Bedrock
+type BedrockConfig struct {
+	// Region is the AWS region, e.g. us-east-1
+	Region string
+
+	// Model is the model or inference profile ID (defaults to DefaultBedrockModel)
+	Model string
+
+	// Credentials provides AWS credentials, typically using IRSA
+	Credentials *AWSWebIdentityCredentials
+
+	// MaxRetries is the number of retries on throttled or overloaded responses
+	MaxRetries int
+}
+
+// bedrockPlatform serves the Messages API through the Bedrock runtime
+type bedrockPlatform struct {
+	config BedrockConfig
+}
+
+// NewBedrockClient creates a client for Anthropic models on Amazon Bedrock
+func NewBedrockClient(config BedrockConfig) *AnthropicClient {
+	if config.Model == "" {
+		config.Model = DefaultBedrockModel
+	}
+
+	c := NewAnthropicClient(AnthropicConfig{Model: config.Model, MaxRetries: config.MaxRetries})
+	c.platform = bedrockPlatform{config: config}
+
+	return c
+}
+
+// newRequest implements anthropicPlatform
+func (p bedrockPlatform) newRequest(ctx context.Context, reqBytes []byte) (*http.Request, error) {
+	reqBytes, err := platformBody(reqBytes, bedrockAnthropicVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := p.config.Credentials.Credentials()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create HTTP request; model IDs contain colons, which must be escaped
+	endpoint := &url.URL{
+		Scheme:  "https",
+		Host:    "bedrock-runtime." + p.config.Region + ".amazonaws.com",
+		Path:    "/model/" + p.config.Model + "/invoke",
+		RawPath: "/model/" + awsEscape(p.config.Model) + "/invoke",
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(reqBytes))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	// Set headers and sign the request
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	signAWSRequest(req, reqBytes, credentials, p.config.Region, "bedrock", time.Now())
+
+	return req, nil
+}
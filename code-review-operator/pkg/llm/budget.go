@@ -0,0 +1,160 @@
+package llm
+
+import (
+	"sort"
+	"strings"
+)
+
+// Prompt section names
+const (
+	SectionDiff        = "diff"
+	SectionFileContext = "fileContext"
+	SectionGuidelines  = "guidelines"
+	SectionRAG         = "rag"
+	SectionMemory      = "memory"
+	SectionIssues      = "issues"
+	SectionRelated     = "related"
+)
+
+// truncationMarker is appended to sections that were trimmed to fit the budget
+const truncationMarker = "\n... [truncated to fit the context window]"
+
+// Budget divides a model's context window among prompt sections
+type Budget struct {
+	// ContextTokens is the size of the model's context window in tokens
+	ContextTokens int
+
+	// ReservedTokens is kept free for instructions and the model's response
+	ReservedTokens int
+
+	// Ratios is the share of the remaining tokens guaranteed to each section
+	Ratios map[string]float64
+
+	// Priorities orders sections for trimming; lower priorities are trimmed first
+	Priorities map[string]int
+}
+
+// DefaultBudget returns a budget for the given context window using the default ratios
+func DefaultBudget(contextTokens int) *Budget {
+	return &Budget{
+		ContextTokens:  contextTokens,
+		ReservedTokens: contextTokens / 4,
+		Ratios: map[string]float64{
+			SectionDiff:        0.55,
+			SectionFileContext: 0.15,
+			SectionGuidelines:  0.1,
+			SectionIssues:      0.05,
+			SectionRelated:     0.05,
+			SectionMemory:      0.05,
+			SectionRAG:         0.05,
+		},
+		Priorities: map[string]int{
+			SectionDiff:        7,
+			SectionGuidelines:  6,
+			SectionIssues:      5,
+			SectionRelated:     4,
+			SectionMemory:      3,
+			SectionFileContext: 2,
+			SectionRAG:         1,
+		},
+	}
+}
+
+// Section is a named part of a prompt
+type Section struct {
+	// Name identifies the section
+	Name string
+
+	// Content is the text of the section
+	Content string
+}
+
+// EstimateTokens approximates the number of tokens in a text
+func EstimateTokens(text string) int {
+	// Roughly four characters per token for English text and code
+	return (len(text) + 3) / 4
+}
+
+// Allocate trims sections so that together they fit the budget. Sections
+// over their guaranteed share are trimmed lowest priority first, down to that
+// share; if that is not enough, the lowest priority sections are trimmed
+// further. Sections are returned in their original order.
+func (b *Budget) Allocate(sections []Section) []Section {
+	available := b.ContextTokens - b.ReservedTokens
+	if available < 0 {
+		available = 0
+	}
+
+	tokens := make([]int, len(sections))
+	total := 0
+	for i, section := range sections {
+		tokens[i] = EstimateTokens(section.Content)
+		total += tokens[i]
+	}
+	if total <= available {
+		return sections
+	}
+
+	// Trim in ascending priority order
+	order := make([]int, len(sections))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, c int) bool {
+		return b.Priorities[sections[order[a]].Name] < b.Priorities[sections[order[c]].Name]
+	})
+
+	over := total - available
+
+	// First pass: trim sections down to their guaranteed share
+	for _, i := range order {
+		if over <= 0 {
+			break
+		}
+		share := int(b.Ratios[sections[i].Name] * float64(available))
+		if excess := tokens[i] - share; excess > 0 {
+			cut := min(excess, over)
+			tokens[i] -= cut
+			over -= cut
+		}
+	}
+
+	// Second pass: trim lowest priority sections further
+	for _, i := range order {
+		if over <= 0 {
+			break
+		}
+		cut := min(tokens[i], over)
+		tokens[i] -= cut
+		over -= cut
+	}
+
+	trimmed := make([]Section, len(sections))
+	for i, section := range sections {
+		trimmed[i] = Section{Name: section.Name, Content: truncateTokens(section.Content, tokens[i])}
+	}
+
+	return trimmed
+}
+
+// truncateTokens cuts text to roughly the given number of tokens, preferring line boundaries
+func truncateTokens(text string, tokens int) string {
+	if EstimateTokens(text) <= tokens {
+		return text
+	}
+	if tokens <= 0 {
+		return ""
+	}
+
+	limit := tokens*4 - len(truncationMarker)
+	if limit <= 0 {
+		return ""
+	}
+
+	cut := text[:limit]
+	if newline := strings.LastIndex(cut, "\n"); newline > 0 {
+		cut = cut[:newline]
+	}
+
+	return cut + truncationMarker
+}
@@ -0,0 +1,200 @@
+package llm
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/metrics"
+)
+
+// DefaultCacheTTL is how long reviews are cached unless configured otherwise
+const DefaultCacheTTL = 24 * time.Hour
+
+// Cache stores the results of previous reviews by key
+type Cache interface {
+	// Get returns the value stored under a key, or false if there is none or
+	// it has expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores a value under a key for a time
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// MemoryCache is a Cache held in memory that evicts the least recently used
+// entries beyond its capacity
+type MemoryCache struct {
+	capacity int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// memoryEntry is an entry of a MemoryCache
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache creates a MemoryCache holding up to capacity entries
+func NewMemoryCache(capacity int) *MemoryCache {
+	return &MemoryCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get implements Cache
+func (c *MemoryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*memoryEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(element)
+
+	return entry.value, true, nil
+}
+
+// Set implements Cache
+func (c *MemoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*memoryEntry)
+		entry.value, entry.expires = value, time.Now().Add(ttl)
+		c.order.MoveToFront(element)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(&memoryEntry{key: key, value: value, expires: time.Now().Add(ttl)})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryEntry).key)
+	}
+
+	return nil
+}
+
+// cachedClient returns the previous result when a backend is asked to review
+// a diff it has already reviewed with the same options. Cache failures are
+// logged and the backend asked instead.
+type cachedClient struct {
+	client   Client
+	cache    Cache
+	ttl      time.Duration
+	provider string
+
+	// backend identifies the backend and model in cache keys
+	backend string
+}
+
+// ReviewCode implements Client. Cached results report no tokens used, as
+// none were spent on them.
+func (c *cachedClient) ReviewCode(ctx context.Context, diff string, options ReviewOptions) (*ReviewResult, error) {
+	return c.review(ctx, diff, options, nil)
+}
+
+// ReviewCodeStream implements Streamer. Cached results are delivered at once.
+func (c *cachedClient) ReviewCodeStream(ctx context.Context, diff string, options ReviewOptions, onEvent StreamFunc) (*ReviewResult, error) {
+	return c.review(ctx, diff, options, onEvent)
+}
+
+// review serves a review from the cache or the backend, streaming events to
+// onEvent unless it is nil
+func (c *cachedClient) review(ctx context.Context, diff string, options ReviewOptions, onEvent StreamFunc) (*ReviewResult, error) {
+	logger := log.FromContext(ctx)
+	key := c.key(diff, options)
+	if !options.NoCache {
+		if result, ok := c.lookup(ctx, key); ok {
+			metrics.LLMCacheLookups.WithLabelValues(c.provider, metrics.CacheHit).Inc()
+			logger.V(1).Info("LLM review served from cache", "llmProvider", c.provider, "comments", len(result.Comments))
+			if onEvent != nil {
+				emitResult(result, onEvent)
+			}
+			return result, nil
+		}
+		metrics.LLMCacheLookups.WithLabelValues(c.provider, metrics.CacheMiss).Inc()
+	}
+
+	result, err := ReviewStream(ctx, c.client, diff, options, onEvent)
+	if err != nil {
+		return nil, err
+	}
+	value, err := json.Marshal(result)
+	if err == nil {
+		err = c.cache.Set(ctx, key, value, c.ttl)
+	}
+	if err != nil {
+		logger.Error(err, "unable to cache LLM review", "llmProvider", c.provider)
+	}
+
+	return result, nil
+}
+
+// Summarize implements Summarizer. Summaries are not cached.
+func (c *cachedClient) Summarize(ctx context.Context, summaries []string, options ReviewOptions) (string, error) {
+	summarizer, ok := c.client.(Summarizer)
+	if !ok {
+		return strings.Join(summaries, "\n\n"), nil
+	}
+
+	return summarizer.Summarize(ctx, summaries, options)
+}
+
+// ModelID implements Identifier
+func (c *cachedClient) ModelID() string {
+	return ModelID(c.client)
+}
+
+// lookup returns the cached result under a key
+func (c *cachedClient) lookup(ctx context.Context, key string) (*ReviewResult, bool) {
+	value, ok, err := c.cache.Get(ctx, key)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to read LLM review cache", "llmProvider", c.provider)
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+	var result ReviewResult
+	if err := json.Unmarshal(value, &result); err != nil {
+		return nil, false
+	}
+	result.TokensUsed, result.Usage = 0, nil
+
+	return &result, true
+}
+
+// key hashes the backend, the diff and the options of a review, including
+// the prompt templates and budget
+func (c *cachedClient) key(diff string, options ReviewOptions) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00", c.backend, diff)
+	if data, err := json.Marshal(options); err == nil {
+		hash.Write(data)
+	}
+	fmt.Fprintf(hash, "\x00%v\x00%v\x00%v", options.Templates, options.Prompt, options.Budget)
+
+	return "codereview:llm:" + hex.EncodeToString(hash.Sum(nil))
+}
@@ -8,6 +8,9 @@ import (
 	"io/ioutil"
 	"net/http"
 	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/httpx"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm/prompt"
 )
 
 // ReviewComment represents a single code review comment
@@ -17,6 +20,14 @@ type ReviewComment struct {
 	Content  string `json:"content"`
 	Severity string `json:"severity"`
 	Rule     string `json:"rule"`
+
+	// StartLine is the first line of a finding spanning several lines, in
+	// which case Line is the last; 0 for a single-line finding
+	StartLine int `json:"start_line,omitempty"`
+
+	// SuggestedCode is replacement code for the commented lines, empty if the
+	// finding has no mechanical fix
+	SuggestedCode string `json:"suggested_code,omitempty"`
 }
 
 // ReviewResult contains the results of a code review
@@ -24,15 +35,100 @@ type ReviewResult struct {
 	Comments   []ReviewComment `json:"comments"`
 	Summary    string          `json:"summary"`
 	TokensUsed int             `json:"tokens_used"`
+
+	// Usage breaks the tokens used down by model, for cost accounting
+	Usage []Usage `json:"usage,omitempty"`
+
+	// Redactions counts the text masked before the review was sent
+	Redactions Redactions `json:"-"`
+}
+
+// Usage counts the tokens a model used
+type Usage struct {
+	Model            string `json:"model,omitempty"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+}
+
+// AddUsage adds usages to the result's breakdown, merging those of the same
+// model. TokensUsed is left to the caller.
+func (r *ReviewResult) AddUsage(usages ...Usage) {
+	for _, usage := range usages {
+		merged := false
+		for i := range r.Usage {
+			if r.Usage[i].Model == usage.Model {
+				r.Usage[i].PromptTokens += usage.PromptTokens
+				r.Usage[i].CompletionTokens += usage.CompletionTokens
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			r.Usage = append(r.Usage, usage)
+		}
+	}
 }
 
 // ReviewOptions contains options for generating a code review
 type ReviewOptions struct {
-	MaxTokens    int      `json:"max_tokens,omitempty"`
-	Temperature  float32  `json:"temperature,omitempty"`
-	Language     string   `json:"language,omitempty"`
+	MaxTokens      int      `json:"max_tokens,omitempty"`
+	Temperature    float32  `json:"temperature,omitempty"`
+	Language       string   `json:"language,omitempty"`
 	SeverityLevels []string `json:"severity_levels,omitempty"`
-	Rules        []string `json:"rules,omitempty"`
+	Rules          []string `json:"rules,omitempty"`
+	FileContext    string   `json:"file_context,omitempty"`
+	Snippets       []string `json:"snippets,omitempty"`
+	Memory         string   `json:"memory,omitempty"`
+
+	// Hints are the areas the pull request's author asked reviewers to
+	// focus on, prioritized over the other rules
+	Hints []string `json:"hints,omitempty"`
+
+	// Issues describes the issues the pull request says it resolves
+	Issues string `json:"issues,omitempty"`
+
+	// RelatedChanges holds the diffs of the pull requests of other
+	// repositories the change is made together with
+	RelatedChanges string `json:"related_changes,omitempty"`
+
+	// MinSeverity is the least severe finding wanted; clients that build
+	// prompts themselves do not ask for less severe ones
+	MinSeverity string `json:"min_severity,omitempty"`
+
+	// DisabledRules lists rules, or globs matching them, whose findings are
+	// not wanted
+	DisabledRules []string `json:"disabled_rules,omitempty"`
+
+	// Glossary maps the organization's terms to their meaning
+	Glossary map[string]string `json:"glossary,omitempty"`
+
+	// Include and Exclude are the path globs the diff is filtered with before
+	// review. When Include is set, only matching files are reviewed.
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+
+	// Repository is the full name of the repository under review
+	Repository string `json:"repository,omitempty"`
+
+	// Templates are the prompt templates of clients that build prompts
+	// themselves (defaults to the built-in templates)
+	Templates *prompt.Set `json:"-"`
+
+	// Prompt holds custom system and user templates replacing those in Templates
+	Prompt *prompt.Template `json:"-"`
+
+	// Budget divides the context window among prompt sections. Clients that
+	// build prompts themselves trim sections to fit it when set.
+	Budget *Budget `json:"-"`
+
+	// Analyzers enables or disables the deterministic analyzers run alongside
+	// the review by name; analyzers not listed run
+	Analyzers map[string]bool `json:"-"`
+
+	// NoCache bypasses the response cache: the backend is asked even if it
+	// reviewed the same diff with the same options before, and its answer
+	// replaces the cached one
+	NoCache bool `json:"-"`
 }
 
 // ReviewRequest represents a request to the LLM service
@@ -46,6 +142,32 @@ type Client interface {
 	ReviewCode(ctx context.Context, diff string, options ReviewOptions) (*ReviewResult, error)
 }
 
+// Summarizer is implemented by clients that can consolidate the summaries of
+// a review split into several chunks
+type Summarizer interface {
+	Summarize(ctx context.Context, summaries []string, options ReviewOptions) (string, error)
+}
+
+// Identifier is implemented by clients that know the backend and model they
+// send requests to
+type Identifier interface {
+	// ModelID identifies the backend and model, such as "openai/gpt-4o"
+	ModelID() string
+}
+
+// ModelID returns the backend and model a client sends requests to, or an
+// empty string if the client does not know them
+func ModelID(client Client) string {
+	if identifier, ok := client.(Identifier); ok {
+		return identifier.ModelID()
+	}
+
+	return ""
+}
+
+// DefaultHTTPEndpoint is the URL of the LLM review service deployed with the operator
+const DefaultHTTPEndpoint = "http://llm-service:8000/api/v1/review"
+
 // HTTPClient implements the Client interface using HTTP
 type HTTPClient struct {
 	endpoint   string
@@ -56,11 +178,9 @@ type HTTPClient struct {
 // NewHTTPClient creates a new HTTP client for the LLM service
 func NewHTTPClient(endpoint, apiKey string) *HTTPClient {
 	return &HTTPClient{
-		endpoint: endpoint,
-		apiKey:   apiKey,
-		httpClient: &http.Client{
-			Timeout: 5 * time.Minute, // Code review might take a while
-		},
+		endpoint:   endpoint,
+		apiKey:     apiKey,
+		httpClient: httpx.NewClient(5 * time.Minute), // Code review might take a while
 	}
 }
 
@@ -110,7 +230,7 @@ func (c *HTTPClient) ReviewCode(ctx context.Context, diff string, options Review
 
 	// Check for errors
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("error from LLM service: %s (status code: %d)", string(body), resp.StatusCode)
+		return nil, newAPIError("LLM service", resp, body)
 	}
 
 	// Parse the response
@@ -120,4 +240,4 @@ func (c *HTTPClient) ReviewCode(ctx context.Context, diff string, options Review
 	}
 
 	return &result, nil
-}
\ No newline at end of file
+}
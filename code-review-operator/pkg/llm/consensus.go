@@ -0,0 +1,140 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ConsensusMode controls what a consensus review does with findings only one model reported
+type ConsensusMode string
+
+const (
+	// ConsensusAgree keeps only findings both models reported
+	ConsensusAgree ConsensusMode = "agree"
+
+	// ConsensusAnnotate keeps every finding, marking those only one model
+	// reported as lower confidence
+	ConsensusAnnotate ConsensusMode = "annotate"
+)
+
+// ConsensusLineTolerance is how many lines apart two findings in the same file may be and still match
+const ConsensusLineTolerance = 3
+
+// lowConfidenceNote is prepended to findings only one model reported
+const lowConfidenceNote = "_Lower confidence: reported by only one of the review models._\n\n"
+
+// ConsensusClient reviews code with two models and combines their findings,
+// trading cost for precision
+type ConsensusClient struct {
+	primary   Client
+	secondary Client
+	mode      ConsensusMode
+}
+
+// NewConsensusClient creates a client that reviews with both primary and secondary
+func NewConsensusClient(primary, secondary Client, mode ConsensusMode) *ConsensusClient {
+	if mode == "" {
+		mode = ConsensusAgree
+	}
+
+	return &ConsensusClient{
+		primary:   primary,
+		secondary: secondary,
+		mode:      mode,
+	}
+}
+
+// ReviewCode reviews the diff with both models concurrently and combines the
+// findings. The summary and comment wording come from the primary model.
+func (c *ConsensusClient) ReviewCode(ctx context.Context, diff string, options ReviewOptions) (*ReviewResult, error) {
+	var wg sync.WaitGroup
+	var secondary *ReviewResult
+	var secondaryErr error
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		secondary, secondaryErr = c.secondary.ReviewCode(ctx, diff, options)
+	}()
+	primary, err := c.primary.ReviewCode(ctx, diff, options)
+	wg.Wait()
+
+	if err != nil {
+		return nil, fmt.Errorf("error from primary model: %w", err)
+	}
+	if secondaryErr != nil {
+		return nil, fmt.Errorf("error from secondary model: %w", secondaryErr)
+	}
+
+	result := &ReviewResult{
+		Summary:    primary.Summary,
+		TokensUsed: primary.TokensUsed + secondary.TokensUsed,
+	}
+	result.AddUsage(primary.Usage...)
+	result.AddUsage(secondary.Usage...)
+
+	matched := make([]bool, len(secondary.Comments))
+	for _, comment := range primary.Comments {
+		if i := findMatch(secondary.Comments, matched, comment); i >= 0 {
+			matched[i] = true
+			result.Comments = append(result.Comments, comment)
+		} else if c.mode == ConsensusAnnotate {
+			comment.Content = lowConfidenceNote + comment.Content
+			result.Comments = append(result.Comments, comment)
+		}
+	}
+
+	if c.mode == ConsensusAnnotate {
+		for i, comment := range secondary.Comments {
+			if !matched[i] {
+				comment.Content = lowConfidenceNote + comment.Content
+				result.Comments = append(result.Comments, comment)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Summarize implements Summarizer using the primary model. If the primary
+// model cannot summarize, the summaries are joined.
+func (c *ConsensusClient) Summarize(ctx context.Context, summaries []string, options ReviewOptions) (string, error) {
+	summarizer, ok := c.primary.(Summarizer)
+	if !ok {
+		return strings.Join(summaries, "\n\n"), nil
+	}
+
+	return summarizer.Summarize(ctx, summaries, options)
+}
+
+// ModelID implements Identifier. Both models review every diff, so the pair
+// is identified together; it is unknown if either model is.
+func (c *ConsensusClient) ModelID() string {
+	primary, secondary := ModelID(c.primary), ModelID(c.secondary)
+	if primary == "" || secondary == "" {
+		return ""
+	}
+
+	return primary + "+" + secondary
+}
+
+// findMatch returns the index of the first unmatched comment on the same file
+// and within ConsensusLineTolerance lines of comment, or -1
+func findMatch(comments []ReviewComment, matched []bool, comment ReviewComment) int {
+	for i, candidate := range comments {
+		if matched[i] || candidate.File != comment.File {
+			continue
+		}
+		distance := candidate.Line - comment.Line
+		if distance < 0 {
+			distance = -distance
+		}
+		if distance <= ConsensusLineTolerance {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,95 @@
+package llm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Price is what a model charges, in US dollars per million tokens
+type Price struct {
+	Prompt     float64
+	Completion float64
+}
+
+// Prices maps model names to their prices. A model is priced by the longest
+// name it contains, so "claude-3-5-sonnet" prices every snapshot of the
+// model, including its Bedrock and Vertex AI IDs.
+type Prices map[string]Price
+
+// DefaultPrices are the list prices of common hosted models. They are
+// estimates: override them with the prices you pay.
+var DefaultPrices = Prices{
+	"gpt-4o":            {Prompt: 2.50, Completion: 10.00},
+	"gpt-4o-mini":       {Prompt: 0.15, Completion: 0.60},
+	"gpt-4.1":           {Prompt: 2.00, Completion: 8.00},
+	"gpt-4.1-mini":      {Prompt: 0.40, Completion: 1.60},
+	"claude-3-5-sonnet": {Prompt: 3.00, Completion: 15.00},
+	"claude-3-5-haiku":  {Prompt: 0.80, Completion: 4.00},
+	"claude-3-7-sonnet": {Prompt: 3.00, Completion: 15.00},
+	"claude-sonnet-4":   {Prompt: 3.00, Completion: 15.00},
+	"claude-opus-4":     {Prompt: 15.00, Completion: 75.00},
+	"gemini-1.5-pro":    {Prompt: 1.25, Completion: 5.00},
+	"gemini-1.5-flash":  {Prompt: 0.075, Completion: 0.30},
+}
+
+// ParsePrices parses prices in the form model=prompt:completion, separated
+// by commas, such as "gpt-4o=2.5:10,llama3.1=0:0", on top of DefaultPrices
+func ParsePrices(value string) (Prices, error) {
+	prices := make(Prices, len(DefaultPrices))
+	for model, price := range DefaultPrices {
+		prices[model] = price
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		model, rates, ok := strings.Cut(entry, "=")
+		prompt, completion, ok2 := strings.Cut(rates, ":")
+		if !ok || !ok2 || model == "" {
+			return nil, fmt.Errorf("invalid price %q: must be model=prompt:completion", entry)
+		}
+		var price Price
+		var err error
+		if price.Prompt, err = strconv.ParseFloat(prompt, 64); err != nil {
+			return nil, fmt.Errorf("invalid prompt price of %s: %w", model, err)
+		}
+		if price.Completion, err = strconv.ParseFloat(completion, 64); err != nil {
+			return nil, fmt.Errorf("invalid completion price of %s: %w", model, err)
+		}
+		prices[model] = price
+	}
+
+	return prices, nil
+}
+
+// Cost estimates the cost of usages in US dollars. Models without a price
+// cost nothing.
+func (p Prices) Cost(usages []Usage) float64 {
+	cost := 0.0
+	for _, usage := range usages {
+		price, ok := p.lookup(usage.Model)
+		if !ok {
+			continue
+		}
+		cost += (float64(usage.PromptTokens)*price.Prompt + float64(usage.CompletionTokens)*price.Completion) / 1e6
+	}
+
+	return cost
+}
+
+// lookup returns the price of the longest model name a model contains
+func (p Prices) lookup(model string) (Price, bool) {
+	model = strings.ToLower(model)
+	var price Price
+	matched := ""
+	for name, candidate := range p {
+		if len(name) > len(matched) && strings.Contains(model, strings.ToLower(name)) {
+			price, matched = candidate, name
+		}
+	}
+
+	return price, matched != ""
+}
@@ -0,0 +1,204 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/httpx"
+)
+
+const (
+	// defaultGCPMetadataHost is the GCE metadata server, which serves tokens
+	// for the Google service account bound by GKE Workload Identity
+	defaultGCPMetadataHost = "metadata.google.internal"
+
+	// defaultAzureAuthorityHost is the Microsoft Entra ID authority
+	defaultAzureAuthorityHost = "https://login.microsoftonline.com/"
+
+	// AzureCognitiveServicesScope is the token scope for Azure OpenAI
+	AzureCognitiveServicesScope = "https://cognitiveservices.azure.com/.default"
+
+	// credentialRefreshSkew is how long before expiry a cached credential is refreshed
+	credentialRefreshSkew = 5 * time.Minute
+)
+
+// TokenSource provides bearer tokens for authenticating to a cloud-hosted LLM
+type TokenSource interface {
+	// Token returns a valid token
+	Token() (string, error)
+}
+
+// tokenResponse is the body of an OAuth2 token response
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// GCPTokenSource is a TokenSource returning access tokens for the Google
+// service account of the pod, as bound by GKE Workload Identity. It is safe
+// for concurrent use.
+type GCPTokenSource struct {
+	client *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewGCPTokenSource creates a token source using the GCE metadata server, or
+// the host in GCE_METADATA_HOST if set
+func NewGCPTokenSource() *GCPTokenSource {
+	return &GCPTokenSource{
+		client: httpx.NewClient(30 * time.Second),
+	}
+}
+
+// Token implements TokenSource, returning a cached token and refreshing it
+// shortly before it expires
+func (s *GCPTokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Until(s.expiresAt) > credentialRefreshSkew {
+		return s.token, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.client.Timeout)
+	defer cancel()
+
+	host := os.Getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = defaultGCPMetadataHost
+	}
+
+	// Create request
+	tokenURL := "http://" + host + "/computeMetadata/v1/instance/service-accounts/default/token"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	token, err := requestToken(s.client, req)
+	if err != nil {
+		return "", fmt.Errorf("error getting GCP workload identity token: %w", err)
+	}
+	s.token = token.AccessToken
+	s.expiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+
+	return s.token, nil
+}
+
+// AzureTokenSource is a TokenSource using Azure Workload Identity: the
+// Kubernetes service account token projected into the pod is exchanged for
+// a Microsoft Entra ID access token. It is safe for concurrent use.
+type AzureTokenSource struct {
+	scope  string
+	client *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewAzureTokenSource creates a token source for the given scope. The client,
+// tenant and token file are read from the AZURE_CLIENT_ID, AZURE_TENANT_ID and
+// AZURE_FEDERATED_TOKEN_FILE variables injected by the workload identity webhook.
+func NewAzureTokenSource(scope string) *AzureTokenSource {
+	return &AzureTokenSource{
+		scope:  scope,
+		client: httpx.NewClient(30 * time.Second),
+	}
+}
+
+// Token implements TokenSource, returning a cached token and refreshing it
+// shortly before it expires
+func (s *AzureTokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Until(s.expiresAt) > credentialRefreshSkew {
+		return s.token, nil
+	}
+
+	clientID, tenantID, tokenFile := os.Getenv("AZURE_CLIENT_ID"), os.Getenv("AZURE_TENANT_ID"), os.Getenv("AZURE_FEDERATED_TOKEN_FILE")
+	if clientID == "" || tenantID == "" || tokenFile == "" {
+		return "", fmt.Errorf("azure workload identity is not configured: AZURE_CLIENT_ID, AZURE_TENANT_ID and AZURE_FEDERATED_TOKEN_FILE must be set")
+	}
+	authorityHost := os.Getenv("AZURE_AUTHORITY_HOST")
+	if authorityHost == "" {
+		authorityHost = defaultAzureAuthorityHost
+	}
+
+	// The projected token is rotated by the kubelet, so read it on every refresh
+	assertion, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("error reading federated token: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.client.Timeout)
+	defer cancel()
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", clientID)
+	form.Set("scope", s.scope)
+	form.Set("client_assertion_type", "urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
+	form.Set("client_assertion", strings.TrimSpace(string(assertion)))
+
+	// Create request
+	tokenURL := strings.TrimSuffix(authorityHost, "/") + "/" + url.PathEscape(tenantID) + "/oauth2/v2.0/token"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	token, err := requestToken(s.client, req)
+	if err != nil {
+		return "", fmt.Errorf("error getting Azure workload identity token: %w", err)
+	}
+	s.token = token.AccessToken
+	s.expiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+
+	return s.token, nil
+}
+
+// requestToken sends a token request and parses the response
+func requestToken(client *http.Client, req *http.Request) (*tokenResponse, error) {
+	// Send the request
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read the response body
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+
+	// Check for errors
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s (status code: %d)", string(body), resp.StatusCode)
+	}
+
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("error parsing response: no access token returned")
+	}
+
+	return &token, nil
+}
@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrOutputTruncated is returned when a model stopped answering because it
+// reached its output limit, leaving the review incomplete
+var ErrOutputTruncated = errors.New("model output truncated at its token limit")
+
+// contextLengthMessages are the phrases backends use in errors about a
+// request exceeding the model's context window
+var contextLengthMessages = []string{
+	"context_length_exceeded",
+	"context length",
+	"context window",
+	"prompt is too long",
+	"input is too long",
+	"too many tokens",
+	"too many input tokens",
+	"maximum number of tokens",
+}
+
+// APIError is an error response from an LLM backend's API
+type APIError struct {
+	// API names the API, such as "OpenAI API"
+	API string
+
+	// Provider is the backend the request was sent to
+	Provider string
+
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+
+	// Body is the body of the response
+	Body string
+
+	// RetryAfter is how long the backend asked clients to wait, if it did
+	RetryAfter time.Duration
+}
+
+// newAPIError creates an APIError from a response and its body
+func newAPIError(api string, resp *http.Response, body []byte) *APIError {
+	err := &APIError{API: api, StatusCode: resp.StatusCode, Body: string(body)}
+	if seconds, parseErr := strconv.Atoi(resp.Header.Get("Retry-After")); parseErr == nil {
+		err.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	return err
+}
+
+// Error implements error
+func (e *APIError) Error() string {
+	return fmt.Sprintf("error from %s: %s (status code: %d)", e.API, e.Body, e.StatusCode)
+}
+
+// Transient reports whether the response may succeed if retried
+func (e *APIError) Transient() bool {
+	return e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode == anthropicStatusOverloaded || e.StatusCode >= http.StatusInternalServerError
+}
+
+// RateLimited reports whether the request was rejected for exceeding the
+// backend's rate limit
+func (e *APIError) RateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// ContextLengthExceeded reports whether the request was rejected for not
+// fitting in the model's context window, or as too large altogether
+func (e *APIError) ContextLengthExceeded() bool {
+	if e.StatusCode == http.StatusRequestEntityTooLarge {
+		return true
+	}
+	if e.StatusCode != http.StatusBadRequest {
+		return false
+	}
+	body := strings.ToLower(e.Body)
+	for _, message := range contextLengthMessages {
+		if strings.Contains(body, message) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsTooLarge reports whether a request failed because it was too large for
+// the model: it exceeded the model's context window, or the model's answer
+// was truncated. A smaller request may succeed.
+func IsTooLarge(err error) bool {
+	if errors.Is(err, ErrOutputTruncated) {
+		return true
+	}
+	var apiErr *APIError
+
+	return errors.As(err, &apiErr) && apiErr.ContextLengthExceeded()
+}
+
+// IsTransient reports whether a failed request may succeed if retried:
+// error responses that are transient, connection errors and timeouts
+func IsTransient(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Transient()
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// IsUnavailable reports whether a request failed because no LLM backend
+// could answer it: none is configured, its circuit breaker is open, or it
+// kept failing with transient errors
+func IsUnavailable(err error) bool {
+	return errors.Is(err, ErrNoBackend) || errors.Is(err, ErrCircuitOpen) || IsTransient(err)
+}
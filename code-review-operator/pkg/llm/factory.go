@@ -0,0 +1,323 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	neturl "net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config holds the backend-independent settings used to construct an LLM client
+type Config struct {
+	// Endpoint is the service URL or API base URL. Backends fall back to
+	// their default base URL when empty.
+	Endpoint string
+
+	// APIKey is the API key, if the backend needs one
+	APIKey string
+
+	// Model is the model name
+	Model string
+
+	// ContextWindow is the model's context window in tokens, used by local backends
+	ContextWindow int
+
+	// Region is the cloud region of the vertex and bedrock backends
+	Region string
+
+	// Project is the Google Cloud project of the vertex backend
+	Project string
+
+	// TrustedEndpoint is set when the operator configured Endpoint itself,
+	// rather than a CodeReview or ReviewerConfig. Without an API key, the
+	// azure-openai backend only sends the operator's workload identity token
+	// to trusted endpoints and to Azure OpenAI hosts.
+	TrustedEndpoint bool
+}
+
+// ClientConstructor is a function that creates an LLM client
+type ClientConstructor func(config Config) (Client, error)
+
+// Factory creates LLM clients based on provider name
+type Factory struct {
+	clients map[string]ClientConstructor
+
+	// policy is the retry policy of the clients created
+	policy RetryPolicy
+
+	// breakers are the circuit breakers of each backend, shared by the
+	// clients created for it
+	breakersMu sync.Mutex
+	breakers   map[string]*circuitBreaker
+
+	// cache holds previous reviews of the clients created, if set
+	cache    Cache
+	cacheTTL time.Duration
+}
+
+// Error definitions
+var (
+	ErrUnsupportedProvider = errors.New("unsupported LLM provider")
+	ErrMissingEndpoint     = errors.New("LLM endpoint is required")
+	ErrMissingModel        = errors.New("LLM model is required")
+	ErrMissingRegion       = errors.New("LLM region is required")
+	ErrMissingProject      = errors.New("LLM project is required")
+
+	// ErrNoBackend is returned by the clients of the none backend
+	ErrNoBackend = errors.New("no LLM backend is configured")
+
+	// ErrUntrustedEndpoint is returned for endpoints the operator's workload
+	// identity token would be sent to without the operator configuring them
+	ErrUntrustedEndpoint = errors.New("LLM endpoint is not trusted with the operator's workload identity")
+)
+
+// AzureOpenAIHostSuffixes are the host name suffixes of Azure OpenAI
+// endpoints, which the operator's workload identity token may be sent to
+var AzureOpenAIHostSuffixes = []string{".openai.azure.com", ".cognitiveservices.azure.com"}
+
+// IsAzureOpenAIEndpoint reports whether an endpoint is an Azure OpenAI
+// resource reached over TLS
+func IsAzureOpenAIEndpoint(endpoint string) bool {
+	u, err := neturl.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, suffix := range AzureOpenAIHostSuffixes {
+		if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ProviderNone is the backend of operators running without an LLM: its
+// clients fail every request with ErrNoBackend, so that reviews fall back to
+// the deterministic checks
+const ProviderNone = "none"
+
+// noBackend is the client of the none backend
+type noBackend struct{}
+
+// ReviewCode implements Client
+func (noBackend) ReviewCode(context.Context, string, ReviewOptions) (*ReviewResult, error) {
+	return nil, ErrNoBackend
+}
+
+// NewFactory creates a new LLM client factory
+func NewFactory() *Factory {
+	return &Factory{
+		clients:  make(map[string]ClientConstructor),
+		policy:   DefaultRetryPolicy,
+		breakers: make(map[string]*circuitBreaker),
+	}
+}
+
+// DefaultEndpoint returns the endpoint the operator uses for a built-in
+// backend when none is configured, or "" if the backend requires one: the
+// public API of hosted backends, and the bundled review service for http
+func DefaultEndpoint(provider string) string {
+	switch provider {
+	case "http":
+		return DefaultHTTPEndpoint
+	case "openai":
+		return DefaultOpenAIBaseURL
+	case "anthropic":
+		return DefaultAnthropicBaseURL
+	case "ollama":
+		return DefaultOllamaBaseURL
+	}
+
+	return ""
+}
+
+// UsesEndpoint reports whether a built-in backend connects to the configured
+// endpoint; vertex and bedrock derive theirs from the region
+func UsesEndpoint(provider string) bool {
+	return provider != "vertex" && provider != "bedrock" && provider != ProviderNone
+}
+
+// UsesWorkloadIdentity reports whether a built-in backend created with config
+// authenticates with the operator's workload identity: vertex and bedrock
+// always do, azure-openai when it has no API key
+func UsesWorkloadIdentity(provider string, config Config) bool {
+	switch provider {
+	case "vertex", "bedrock":
+		return true
+	case "azure-openai":
+		return config.APIKey == ""
+	}
+
+	return false
+}
+
+// NewDefaultFactory creates a factory with the built-in backends registered.
+// The vertex, bedrock and azure-openai backends authenticate with the pod's
+// workload identity (GKE Workload Identity, EKS IRSA or Azure Workload
+// Identity); credentials are cached and refreshed across the clients created.
+// azure-openai uses the API key instead if one is configured, and otherwise
+// refuses endpoints that are neither trusted nor Azure OpenAI hosts.
+func NewDefaultFactory() *Factory {
+	gcpTokens := NewGCPTokenSource()
+	awsCredentials := NewAWSWebIdentityCredentials()
+	azureTokens := NewAzureTokenSource(AzureCognitiveServicesScope)
+
+	f := NewFactory()
+	f.Register("http", func(config Config) (Client, error) {
+		if config.Endpoint == "" {
+			return nil, ErrMissingEndpoint
+		}
+		return NewHTTPClient(config.Endpoint, config.APIKey), nil
+	})
+	f.Register("openai", func(config Config) (Client, error) {
+		return NewOpenAIClient(OpenAIConfig{BaseURL: config.Endpoint, APIKey: config.APIKey, Model: config.Model}), nil
+	})
+	f.Register("anthropic", func(config Config) (Client, error) {
+		return NewAnthropicClient(AnthropicConfig{BaseURL: config.Endpoint, APIKey: config.APIKey, Model: config.Model}), nil
+	})
+	f.Register("vertex", func(config Config) (Client, error) {
+		if config.Region == "" {
+			return nil, ErrMissingRegion
+		}
+		if config.Project == "" {
+			return nil, ErrMissingProject
+		}
+		return NewVertexClient(VertexConfig{
+			Project: config.Project,
+			Region:  config.Region,
+			Model:   config.Model,
+			Tokens:  gcpTokens,
+		}), nil
+	})
+	f.Register("bedrock", func(config Config) (Client, error) {
+		region := config.Region
+		if region == "" {
+			region = os.Getenv("AWS_REGION")
+		}
+		if region == "" {
+			return nil, ErrMissingRegion
+		}
+		return NewBedrockClient(BedrockConfig{
+			Region:      region,
+			Model:       config.Model,
+			Credentials: awsCredentials,
+		}), nil
+	})
+	f.Register("azure-openai", func(config Config) (Client, error) {
+		if config.Endpoint == "" {
+			return nil, ErrMissingEndpoint
+		}
+		if config.Model == "" {
+			return nil, ErrMissingModel
+		}
+		openAIConfig := OpenAIConfig{BaseURL: config.Endpoint, APIKey: config.APIKey, AzureDeployment: config.Model}
+		if config.APIKey == "" {
+			if !config.TrustedEndpoint && !IsAzureOpenAIEndpoint(config.Endpoint) {
+				return nil, fmt.Errorf("%w: %s needs an API key", ErrUntrustedEndpoint, config.Endpoint)
+			}
+			openAIConfig.Tokens = azureTokens
+		}
+		return NewOpenAIClient(openAIConfig), nil
+	})
+	f.Register("ollama", func(config Config) (Client, error) {
+		return NewLocalClient(LocalConfig{
+			API:           LocalAPIOllama,
+			BaseURL:       config.Endpoint,
+			Model:         config.Model,
+			ContextWindow: config.ContextWindow,
+		}), nil
+	})
+	f.Register(ProviderNone, func(Config) (Client, error) {
+		return noBackend{}, nil
+	})
+	f.Register("local", func(config Config) (Client, error) {
+		if config.Endpoint == "" {
+			return nil, ErrMissingEndpoint
+		}
+		return NewLocalClient(LocalConfig{
+			API:           LocalAPIOpenAI,
+			BaseURL:       config.Endpoint,
+			Model:         config.Model,
+			ContextWindow: config.ContextWindow,
+		}), nil
+	})
+
+	return f
+}
+
+// Register registers a client constructor for a provider
+func (f *Factory) Register(provider string, constructor ClientConstructor) {
+	f.clients[provider] = constructor
+}
+
+// SetRetryPolicy sets the retry policy of the clients created afterwards
+func (f *Factory) SetRetryPolicy(policy RetryPolicy) {
+	f.breakersMu.Lock()
+	defer f.breakersMu.Unlock()
+
+	f.policy = policy
+	f.breakers = make(map[string]*circuitBreaker)
+}
+
+// SetCache makes the clients created afterwards return the previous result
+// when asked to review a diff they reviewed with the same options within ttl
+func (f *Factory) SetCache(cache Cache, ttl time.Duration) {
+	f.cache = cache
+	f.cacheTTL = ttl
+}
+
+// Create creates a new LLM client based on provider. Requests of clients
+// created for the same provider and endpoint share a circuit breaker, so a
+// failing backend is paused for every review using it.
+func (f *Factory) Create(provider string, config Config) (Client, error) {
+	constructor, ok := f.clients[provider]
+	if !ok {
+		return nil, ErrUnsupportedProvider
+	}
+
+	client, err := constructor(config)
+	if err != nil {
+		return nil, err
+	}
+
+	model := provider
+	if config.Model != "" {
+		model += "/" + config.Model
+	}
+	client = &resilientClient{
+		client:  &instrumentedClient{client: client, provider: provider},
+		breaker: f.breaker(provider, config.Endpoint),
+		model:   model,
+	}
+	if f.cache != nil {
+		client = &cachedClient{
+			client:   client,
+			cache:    f.cache,
+			ttl:      f.cacheTTL,
+			provider: provider,
+			backend:  provider + " " + config.Endpoint + " " + config.Model,
+		}
+	}
+
+	return client, nil
+}
+
+// breaker returns the circuit breaker of a backend
+func (f *Factory) breaker(provider, endpoint string) *circuitBreaker {
+	f.breakersMu.Lock()
+	defer f.breakersMu.Unlock()
+
+	key := provider + " " + endpoint
+	breaker, ok := f.breakers[key]
+	if !ok {
+		breaker = &circuitBreaker{provider: provider, policy: f.policy}
+		f.breakers[key] = breaker
+	}
+
+	return breaker
+}
@@ -0,0 +1,314 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/httpx"
+)
+
+const (
+	// DefaultOllamaBaseURL is the default base URL of an in-cluster Ollama server
+	DefaultOllamaBaseURL = "http://ollama:11434"
+
+	// DefaultLocalModel is the default model served by a local server
+	DefaultLocalModel = "llama3.1"
+
+	// DefaultLocalContextWindow is the default context window of a local model in tokens
+	DefaultLocalContextWindow = 8192
+)
+
+// LocalAPI selects the API spoken by a local model server
+type LocalAPI string
+
+const (
+	// LocalAPIOllama is the native Ollama chat API
+	LocalAPIOllama LocalAPI = "ollama"
+
+	// LocalAPIOpenAI is the OpenAI-compatible chat completions API served by
+	// vLLM, llama.cpp and similar servers
+	LocalAPIOpenAI LocalAPI = "openai"
+)
+
+// LocalConfig configures a client for a self-hosted model server
+type LocalConfig struct {
+	// API is the API spoken by the server (defaults to LocalAPIOllama)
+	API LocalAPI
+
+	// BaseURL is the server base URL (defaults to DefaultOllamaBaseURL). For
+	// OpenAI-compatible servers this includes the /v1 prefix.
+	BaseURL string
+
+	// Model is the model name (defaults to DefaultLocalModel)
+	Model string
+
+	// ContextWindow is the model's context window in tokens (defaults to
+	// DefaultLocalContextWindow). Prompts are budgeted to fit it.
+	ContextWindow int
+}
+
+// LocalClient implements the Client interface against a self-hosted model
+// server, so that diffs never leave the cluster. No API key is sent.
+type LocalClient struct {
+	config     LocalConfig
+	openai     *OpenAIClient
+	httpClient *http.Client
+}
+
+// ollamaChatRequest is the body of an Ollama chat request
+type ollamaChatRequest struct {
+	Model    string                 `json:"model"`
+	Messages []chatMessage          `json:"messages"`
+	Stream   bool                   `json:"stream"`
+	Format   json.RawMessage        `json:"format,omitempty"`
+	Options  map[string]interface{} `json:"options"`
+}
+
+// ollamaChatResponse is the body of an Ollama chat response
+type ollamaChatResponse struct {
+	Message         chatMessage `json:"message"`
+	Done            bool        `json:"done"`
+	DoneReason      string      `json:"done_reason"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+	EvalCount       int         `json:"eval_count"`
+}
+
+// NewLocalClient creates a new client for a self-hosted model server
+func NewLocalClient(config LocalConfig) *LocalClient {
+	if config.API == "" {
+		config.API = LocalAPIOllama
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultOllamaBaseURL
+	}
+	if config.Model == "" {
+		config.Model = DefaultLocalModel
+	}
+	if config.ContextWindow == 0 {
+		config.ContextWindow = DefaultLocalContextWindow
+	}
+
+	c := &LocalClient{
+		config:     config,
+		httpClient: httpx.NewClient(10 * time.Minute), // Local models are often slower than hosted ones
+	}
+	if config.API == LocalAPIOpenAI {
+		c.openai = NewOpenAIClient(OpenAIConfig{BaseURL: config.BaseURL, Model: config.Model})
+		c.openai.httpClient = c.httpClient
+	}
+
+	return c
+}
+
+// ReviewCode sends the diff to the local model server and parses the structured review
+func (c *LocalClient) ReviewCode(ctx context.Context, diff string, options ReviewOptions) (*ReviewResult, error) {
+	if options.Budget == nil {
+		options.Budget = DefaultBudget(c.config.ContextWindow)
+	}
+
+	if c.openai != nil {
+		return c.openai.ReviewCode(ctx, diff, options)
+	}
+
+	reqBody, err := c.reviewRequest(diff, options)
+	if err != nil {
+		return nil, err
+	}
+	chat, err := c.chat(ctx, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.reviewResult(chat.Message.Content, chat)
+}
+
+// ReviewCodeStream implements Streamer. Ollama streams its answer as one
+// JSON object per line.
+func (c *LocalClient) ReviewCodeStream(ctx context.Context, diff string, options ReviewOptions, onEvent StreamFunc) (*ReviewResult, error) {
+	if options.Budget == nil {
+		options.Budget = DefaultBudget(c.config.ContextWindow)
+	}
+
+	if c.openai != nil {
+		return c.openai.ReviewCodeStream(ctx, diff, options, onEvent)
+	}
+
+	reqBody, err := c.reviewRequest(diff, options)
+	if err != nil {
+		return nil, err
+	}
+	reqBody.Stream = true
+	resp, err := c.send(ctx, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var content strings.Builder
+	var last ollamaChatResponse
+	parser := newStreamParser(onEvent)
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64<<10), 4<<20)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var chunk ollamaChatResponse
+		if err := json.Unmarshal(scanner.Bytes(), &chunk); err != nil {
+			return nil, fmt.Errorf("error parsing response stream: %w", err)
+		}
+		content.WriteString(chunk.Message.Content)
+		parser.Write(chunk.Message.Content)
+		if chunk.Done {
+			last = chunk
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading response stream: %w", err)
+	}
+
+	return c.reviewResult(content.String(), &last)
+}
+
+// reviewRequest builds the Ollama chat request reviewing a diff
+func (c *LocalClient) reviewRequest(diff string, options ReviewOptions) (ollamaChatRequest, error) {
+	system, user, err := buildPrompt(diff, options)
+	if err != nil {
+		return ollamaChatRequest{}, err
+	}
+
+	return ollamaChatRequest{
+		Model: c.config.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: user},
+		},
+		Format:  reviewSchema,
+		Options: c.modelOptions(options),
+	}, nil
+}
+
+// reviewResult parses the review answered in a chat, whose final response
+// counts the tokens used, failing with ErrOutputTruncated if it was cut off
+// at the output limit
+func (c *LocalClient) reviewResult(content string, chat *ollamaChatResponse) (*ReviewResult, error) {
+	if chat.DoneReason == finishReasonLength {
+		return nil, fmt.Errorf("error parsing response: %w", ErrOutputTruncated)
+	}
+	result, err := parseReviewJSON(content)
+	if err != nil {
+		return nil, err
+	}
+	result.TokensUsed = chat.PromptEvalCount + chat.EvalCount
+	result.Usage = []Usage{{
+		Model:            c.config.Model,
+		PromptTokens:     chat.PromptEvalCount,
+		CompletionTokens: chat.EvalCount,
+	}}
+
+	return result, nil
+}
+
+// Summarize consolidates the summaries of a chunked review into one
+func (c *LocalClient) Summarize(ctx context.Context, summaries []string, options ReviewOptions) (string, error) {
+	if c.openai != nil {
+		return c.openai.Summarize(ctx, summaries, options)
+	}
+
+	system, user := buildSummaryPrompt(summaries)
+	chat, err := c.chat(ctx, ollamaChatRequest{
+		Model: c.config.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: user},
+		},
+		Options: c.modelOptions(options),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(chat.Message.Content), nil
+}
+
+// modelOptions returns the Ollama model options for a request
+func (c *LocalClient) modelOptions(options ReviewOptions) map[string]interface{} {
+	modelOptions := map[string]interface{}{
+		"num_ctx":     c.config.ContextWindow,
+		"temperature": options.Temperature,
+	}
+	if options.MaxTokens > 0 {
+		modelOptions["num_predict"] = options.MaxTokens
+	}
+
+	return modelOptions
+}
+
+// chat sends a request to the Ollama chat API and returns the parsed response
+func (c *LocalClient) chat(ctx context.Context, reqBody ollamaChatRequest) (*ollamaChatResponse, error) {
+	resp, err := c.send(ctx, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Read the response body
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+
+	// Parse the response
+	var chat ollamaChatResponse
+	if err := json.Unmarshal(body, &chat); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+
+	return &chat, nil
+}
+
+// send sends a request to the Ollama chat API and returns the successful
+// response, whose body the caller must close
+func (c *LocalClient) send(ctx context.Context, reqBody ollamaChatRequest) (*http.Response, error) {
+	// Marshal the request to JSON
+	reqBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	// Create HTTP request
+	url := strings.TrimSuffix(c.config.BaseURL, "/") + "/api/chat"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send the request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+
+	// Check for errors
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("error reading response: %w", err)
+		}
+		return nil, newAPIError("Ollama API", resp, body)
+	}
+
+	return resp, nil
+}
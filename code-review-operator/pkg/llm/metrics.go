@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/metrics"
+)
+
+// instrumentedClient records the latency and outcome of a backend's requests
+// in metrics and in the logger of the request's context
+type instrumentedClient struct {
+	client   Client
+	provider string
+}
+
+// ReviewCode implements Client
+func (c *instrumentedClient) ReviewCode(ctx context.Context, diff string, options ReviewOptions) (*ReviewResult, error) {
+	start := time.Now()
+	result, err := c.client.ReviewCode(ctx, diff, options)
+	c.observe(ctx, "review", start, err)
+	if result != nil {
+		log.FromContext(ctx).V(1).Info("LLM review", "llmProvider", c.provider, "comments", len(result.Comments),
+			"tokens", result.TokensUsed, "duration", time.Since(start))
+	}
+
+	return result, err
+}
+
+// ReviewCodeStream implements Streamer
+func (c *instrumentedClient) ReviewCodeStream(ctx context.Context, diff string, options ReviewOptions, onEvent StreamFunc) (*ReviewResult, error) {
+	start := time.Now()
+	result, err := ReviewStream(ctx, c.client, diff, options, onEvent)
+	c.observe(ctx, "review", start, err)
+	if result != nil {
+		log.FromContext(ctx).V(1).Info("LLM review", "llmProvider", c.provider, "comments", len(result.Comments),
+			"tokens", result.TokensUsed, "duration", time.Since(start), "streamed", true)
+	}
+
+	return result, err
+}
+
+// Summarize implements Summarizer. If the backend cannot summarize, the
+// summaries are joined.
+func (c *instrumentedClient) Summarize(ctx context.Context, summaries []string, options ReviewOptions) (string, error) {
+	summarizer, ok := c.client.(Summarizer)
+	if !ok {
+		return strings.Join(summaries, "\n\n"), nil
+	}
+
+	start := time.Now()
+	summary, err := summarizer.Summarize(ctx, summaries, options)
+	c.observe(ctx, "summarize", start, err)
+
+	return summary, err
+}
+
+// observe records the latency of a request and attributes error responses
+// to the backend
+func (c *instrumentedClient) observe(ctx context.Context, operation string, start time.Time, err error) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Provider == "" {
+		apiErr.Provider = c.provider
+	}
+
+	outcome := metrics.OutcomeSuccess
+	if err != nil {
+		outcome = metrics.OutcomeError
+		log.FromContext(ctx).V(1).Info("LLM request failed", "llmProvider", c.provider, "operation", operation,
+			"duration", time.Since(start), "error", err.Error())
+	}
+	metrics.LLMRequestDuration.WithLabelValues(c.provider, operation, outcome).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,358 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/httpx"
+)
+
+const (
+	// DefaultOpenAIBaseURL is the default OpenAI API base URL
+	DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+	// DefaultOpenAIModel is the default OpenAI model
+	DefaultOpenAIModel = "gpt-4o"
+
+	// DefaultAzureAPIVersion is the default Azure OpenAI API version
+	DefaultAzureAPIVersion = "2024-08-01-preview"
+
+	// finishReasonLength is the finish reason of answers cut off at the
+	// output limit, used by the chat completions API and Ollama
+	finishReasonLength = "length"
+)
+
+// OpenAIConfig configures an OpenAI-compatible client
+type OpenAIConfig struct {
+	// BaseURL is the API base URL (defaults to DefaultOpenAIBaseURL). For
+	// Azure OpenAI this is the resource endpoint, e.g. https://name.openai.azure.com
+	BaseURL string
+
+	// APIKey is the API key
+	APIKey string
+
+	// Model is the model name (defaults to DefaultOpenAIModel)
+	Model string
+
+	// Organization is the optional OpenAI organization ID
+	Organization string
+
+	// AzureDeployment selects Azure OpenAI mode using the named deployment
+	AzureDeployment string
+
+	// AzureAPIVersion is the Azure OpenAI API version (defaults to DefaultAzureAPIVersion)
+	AzureAPIVersion string
+
+	// Tokens provides Microsoft Entra ID tokens for Azure OpenAI, typically an
+	// AzureTokenSource using Azure Workload Identity. Used instead of APIKey if set.
+	Tokens TokenSource
+}
+
+// OpenAIClient implements the Client interface using the OpenAI chat completions API
+type OpenAIClient struct {
+	config     OpenAIConfig
+	httpClient *http.Client
+}
+
+// chatMessage is a message in a chat completions request or response
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatCompletionRequest is the body of a chat completions request
+type chatCompletionRequest struct {
+	Model          string                 `json:"model,omitempty"`
+	Messages       []chatMessage          `json:"messages"`
+	MaxTokens      int                    `json:"max_tokens,omitempty"`
+	Temperature    float32                `json:"temperature"`
+	ResponseFormat map[string]interface{} `json:"response_format,omitempty"`
+	Stream         bool                   `json:"stream,omitempty"`
+	StreamOptions  map[string]bool        `json:"stream_options,omitempty"`
+}
+
+// chatCompletionResponse is the body of a chat completions response
+type chatCompletionResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message      chatMessage `json:"message"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+	Usage chatUsage `json:"usage"`
+}
+
+// chatCompletionChunk is an event of a streamed chat completions response
+type chatCompletionChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta        chatMessage `json:"delta"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+
+	// Usage is set on the last chunk only
+	Usage *chatUsage `json:"usage"`
+}
+
+// chatUsage counts the tokens of a chat completion
+type chatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// NewOpenAIClient creates a new OpenAI-compatible client
+func NewOpenAIClient(config OpenAIConfig) *OpenAIClient {
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultOpenAIBaseURL
+	}
+	if config.Model == "" {
+		config.Model = DefaultOpenAIModel
+	}
+	if config.AzureDeployment != "" && config.AzureAPIVersion == "" {
+		config.AzureAPIVersion = DefaultAzureAPIVersion
+	}
+
+	return &OpenAIClient{
+		config:     config,
+		httpClient: httpx.NewClient(5 * time.Minute), // Code review might take a while
+	}
+}
+
+// ReviewCode sends the diff to the chat completions API and parses the structured review
+func (c *OpenAIClient) ReviewCode(ctx context.Context, diff string, options ReviewOptions) (*ReviewResult, error) {
+	reqBody, err := c.reviewRequest(diff, options)
+	if err != nil {
+		return nil, err
+	}
+
+	completion, err := c.complete(ctx, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.reviewResult(completion.Choices[0].Message.Content, completion.Choices[0].FinishReason,
+		completion.Model, completion.Usage)
+}
+
+// ReviewCodeStream implements Streamer, streaming the chat completion and
+// delivering its comments and summary as they are written
+func (c *OpenAIClient) ReviewCodeStream(ctx context.Context, diff string, options ReviewOptions, onEvent StreamFunc) (*ReviewResult, error) {
+	reqBody, err := c.reviewRequest(diff, options)
+	if err != nil {
+		return nil, err
+	}
+	reqBody.Stream = true
+	reqBody.StreamOptions = map[string]bool{"include_usage": true}
+
+	resp, err := c.send(ctx, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var content strings.Builder
+	var model, finishReason string
+	var usage chatUsage
+	parser := newStreamParser(onEvent)
+	err = readSSE(resp.Body, func(_, data string) error {
+		if data == "[DONE]" {
+			return nil
+		}
+		var chunk chatCompletionChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("error parsing response stream: %w", err)
+		}
+		if chunk.Model != "" {
+			model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			usage = *chunk.Usage
+		}
+		if len(chunk.Choices) > 0 && chunk.Choices[0].FinishReason != "" {
+			finishReason = chunk.Choices[0].FinishReason
+		}
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+			content.WriteString(chunk.Choices[0].Delta.Content)
+			parser.Write(chunk.Choices[0].Delta.Content)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.reviewResult(content.String(), finishReason, model, usage)
+}
+
+// reviewResult parses the review answered by a chat completion, failing
+// with ErrOutputTruncated if it was cut off at the output limit
+func (c *OpenAIClient) reviewResult(content, finishReason, model string, usage chatUsage) (*ReviewResult, error) {
+	if finishReason == finishReasonLength {
+		return nil, fmt.Errorf("error parsing response: %w", ErrOutputTruncated)
+	}
+	result, err := parseReviewJSON(content)
+	if err != nil {
+		return nil, err
+	}
+	if model == "" {
+		model = c.config.Model
+	}
+	result.TokensUsed = usage.TotalTokens
+	result.Usage = []Usage{{
+		Model:            model,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+	}}
+
+	return result, nil
+}
+
+// reviewRequest builds the chat completions request reviewing a diff
+func (c *OpenAIClient) reviewRequest(diff string, options ReviewOptions) (chatCompletionRequest, error) {
+	system, user, err := buildPrompt(diff, options)
+	if err != nil {
+		return chatCompletionRequest{}, err
+	}
+
+	// Create the request body
+	reqBody := chatCompletionRequest{
+		Messages: []chatMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: user},
+		},
+		MaxTokens:   options.MaxTokens,
+		Temperature: options.Temperature,
+		ResponseFormat: map[string]interface{}{
+			"type": "json_schema",
+			"json_schema": map[string]interface{}{
+				"name":   "code_review",
+				"strict": true,
+				"schema": reviewSchema,
+			},
+		},
+	}
+	if c.config.AzureDeployment == "" {
+		reqBody.Model = c.config.Model
+	}
+
+	return reqBody, nil
+}
+
+// Summarize consolidates the summaries of a chunked review into one
+func (c *OpenAIClient) Summarize(ctx context.Context, summaries []string, options ReviewOptions) (string, error) {
+	system, user := buildSummaryPrompt(summaries)
+
+	reqBody := chatCompletionRequest{
+		Messages: []chatMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: user},
+		},
+		MaxTokens:   options.MaxTokens,
+		Temperature: options.Temperature,
+	}
+	if c.config.AzureDeployment == "" {
+		reqBody.Model = c.config.Model
+	}
+
+	completion, err := c.complete(ctx, reqBody)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(completion.Choices[0].Message.Content), nil
+}
+
+// complete sends a chat completions request and returns the parsed response
+func (c *OpenAIClient) complete(ctx context.Context, reqBody chatCompletionRequest) (*chatCompletionResponse, error) {
+	resp, err := c.send(ctx, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Read the response body
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+
+	// Parse the response
+	var completion chatCompletionResponse
+	if err := json.Unmarshal(body, &completion); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return nil, fmt.Errorf("error parsing response: no choices returned")
+	}
+
+	return &completion, nil
+}
+
+// send sends a chat completions request and returns the successful response,
+// whose body the caller must close
+func (c *OpenAIClient) send(ctx context.Context, reqBody chatCompletionRequest) (*http.Response, error) {
+	// Marshal the request to JSON
+	reqBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.completionsURL(), bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+	if c.config.Tokens != nil {
+		token, err := c.config.Tokens.Token()
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	} else if c.config.AzureDeployment != "" {
+		req.Header.Set("api-key", c.config.APIKey)
+	} else if c.config.APIKey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.APIKey))
+	}
+	if c.config.Organization != "" {
+		req.Header.Set("OpenAI-Organization", c.config.Organization)
+	}
+
+	// Send the request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+
+	// Check for errors
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("error reading response: %w", err)
+		}
+		return nil, newAPIError("OpenAI API", resp, body)
+	}
+
+	return resp, nil
+}
+
+// completionsURL returns the chat completions endpoint for the configured API
+func (c *OpenAIClient) completionsURL() string {
+	baseURL := strings.TrimSuffix(c.config.BaseURL, "/")
+	if c.config.AzureDeployment == "" {
+		return baseURL + "/chat/completions"
+	}
+
+	return fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
+		baseURL, url.PathEscape(c.config.AzureDeployment), url.QueryEscape(c.config.AzureAPIVersion))
+}
@@ -0,0 +1,243 @@
+package prompt
+
+import (
+	"path"
+	"strings"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+)
+
+// Languages with their own prompt templates
+const (
+	LanguageGo         = "go"
+	LanguagePython     = "python"
+	LanguageJava       = "java"
+	LanguageTerraform  = "terraform"
+	LanguageDockerfile = "dockerfile"
+	LanguageKubernetes = "kubernetes"
+)
+
+// Languages lists the languages with their own prompt templates
+var Languages = []string{
+	LanguageGo,
+	LanguagePython,
+	LanguageJava,
+	LanguageTerraform,
+	LanguageDockerfile,
+	LanguageKubernetes,
+}
+
+// Languages detected in changed files that have no prompt templates of
+// their own
+const (
+	LanguageJavaScript = "javascript"
+	LanguageTypeScript = "typescript"
+	LanguageRust       = "rust"
+	LanguageRuby       = "ruby"
+	LanguageShell      = "shell"
+	LanguageSQL        = "sql"
+	LanguageYAML       = "yaml"
+	LanguageMarkdown   = "markdown"
+)
+
+// KnownLanguages lists every language FileLanguage detects
+var KnownLanguages = append(append([]string(nil), Languages...),
+	LanguageJavaScript,
+	LanguageTypeScript,
+	LanguageRust,
+	LanguageRuby,
+	LanguageShell,
+	LanguageSQL,
+	LanguageYAML,
+	LanguageMarkdown,
+)
+
+// displayNames are the names languages are given in prompts
+var displayNames = map[string]string{
+	LanguageGo:         "Go",
+	LanguagePython:     "Python",
+	LanguageJava:       "Java",
+	LanguageTerraform:  "Terraform",
+	LanguageDockerfile: "the Dockerfile language",
+	LanguageKubernetes: "Kubernetes YAML",
+	LanguageJavaScript: "JavaScript",
+	LanguageTypeScript: "TypeScript",
+	LanguageRust:       "Rust",
+	LanguageRuby:       "Ruby",
+	LanguageShell:      "shell script",
+	LanguageSQL:        "SQL",
+	LanguageYAML:       "YAML",
+	LanguageMarkdown:   "Markdown documentation",
+}
+
+// aliases map common spellings of language hints to languages
+var aliases = map[string]string{
+	"golang":          LanguageGo,
+	"py":              LanguagePython,
+	"python3":         LanguagePython,
+	"hcl":             LanguageTerraform,
+	"tf":              LanguageTerraform,
+	"opentofu":        LanguageTerraform,
+	"docker":          LanguageDockerfile,
+	"containerfile":   LanguageDockerfile,
+	"k8s":             LanguageKubernetes,
+	"kubernetes yaml": LanguageKubernetes,
+	"js":              LanguageJavaScript,
+	"node":            LanguageJavaScript,
+	"ts":              LanguageTypeScript,
+	"rs":              LanguageRust,
+	"rb":              LanguageRuby,
+	"bash":            LanguageShell,
+	"sh":              LanguageShell,
+	"zsh":             LanguageShell,
+	"yml":             LanguageYAML,
+	"md":              LanguageMarkdown,
+	"docs":            LanguageMarkdown,
+}
+
+// Normalize returns the language a hint such as "Golang" or "k8s" names, or
+// the lowercased hint if it is not a known language
+func Normalize(hint string) string {
+	language := strings.ToLower(strings.TrimSpace(hint))
+	if alias, ok := aliases[language]; ok {
+		return alias
+	}
+
+	return language
+}
+
+// DisplayName returns the name of a language as used in prompts
+func DisplayName(language string) string {
+	if name, ok := displayNames[language]; ok {
+		return name
+	}
+
+	return language
+}
+
+// Detect returns the language most of the files changed in a diff are
+// written in, or an empty string if none of them is a known language
+func Detect(unifiedDiff string) string {
+	files, err := diff.Parse(unifiedDiff)
+	if err != nil {
+		return ""
+	}
+
+	counts := make(map[string]int)
+	for _, file := range files {
+		if language := FileLanguage(file); language != "" {
+			counts[language]++
+		}
+	}
+
+	detected := ""
+	for _, language := range Languages {
+		if counts[language] > counts[detected] {
+			detected = language
+		}
+	}
+
+	return detected
+}
+
+// extensions map file extensions to languages
+var extensions = map[string]string{
+	".go":         LanguageGo,
+	".py":         LanguagePython,
+	".pyi":        LanguagePython,
+	".java":       LanguageJava,
+	".tf":         LanguageTerraform,
+	".tfvars":     LanguageTerraform,
+	".hcl":        LanguageTerraform,
+	".dockerfile": LanguageDockerfile,
+	".js":         LanguageJavaScript,
+	".jsx":        LanguageJavaScript,
+	".mjs":        LanguageJavaScript,
+	".cjs":        LanguageJavaScript,
+	".ts":         LanguageTypeScript,
+	".tsx":        LanguageTypeScript,
+	".rs":         LanguageRust,
+	".rb":         LanguageRuby,
+	".sh":         LanguageShell,
+	".bash":       LanguageShell,
+	".zsh":        LanguageShell,
+	".sql":        LanguageSQL,
+	".md":         LanguageMarkdown,
+	".markdown":   LanguageMarkdown,
+	".mdx":        LanguageMarkdown,
+}
+
+// interpreters map the interpreters of shebang lines to languages
+var interpreters = map[string]string{
+	"python":  LanguagePython,
+	"python3": LanguagePython,
+	"sh":      LanguageShell,
+	"bash":    LanguageShell,
+	"zsh":     LanguageShell,
+	"node":    LanguageJavaScript,
+	"ruby":    LanguageRuby,
+}
+
+// FileLanguage returns the language of a changed file, or an empty string if
+// it is not known. The language is given by the file's extension or name and,
+// where those are ambiguous, by its content: YAML files whose changes show an
+// apiVersion and a kind are Kubernetes manifests, and files without an
+// extension are scripts of the interpreter of their shebang line.
+func FileLanguage(file *diff.File) string {
+	name := path.Base(file.Path())
+	ext := strings.ToLower(path.Ext(name))
+	if language, ok := extensions[ext]; ok {
+		return language
+	}
+	switch {
+	case ext == ".yaml" || ext == ".yml":
+		if isManifest(file) {
+			return LanguageKubernetes
+		}
+		return LanguageYAML
+	case strings.HasPrefix(name, "Dockerfile") || strings.HasPrefix(name, "Containerfile"):
+		return LanguageDockerfile
+	case ext == "":
+		return interpreters[shebangInterpreter(file)]
+	}
+
+	return ""
+}
+
+// shebangInterpreter returns the interpreter named by the shebang line of a
+// file, when the diff shows its first line
+func shebangInterpreter(file *diff.File) string {
+	for _, hunk := range file.Hunks {
+		for _, line := range hunk.Lines {
+			if line.NewNumber != 1 || line.Kind == diff.LineRemoved {
+				continue
+			}
+			fields := strings.Fields(strings.TrimPrefix(line.Content, "#!"))
+			if !strings.HasPrefix(line.Content, "#!") || len(fields) == 0 {
+				return ""
+			}
+			interpreter := path.Base(fields[0])
+			if interpreter == "env" && len(fields) > 1 {
+				interpreter = fields[1]
+			}
+			return interpreter
+		}
+	}
+
+	return ""
+}
+
+// isManifest reports whether a YAML file's changes look like a Kubernetes
+// manifest: an apiVersion and a kind are visible in the diff
+func isManifest(file *diff.File) bool {
+	apiVersion, kind := false, false
+	for _, hunk := range file.Hunks {
+		for _, line := range hunk.Lines {
+			content := strings.TrimSpace(line.Content)
+			apiVersion = apiVersion || strings.HasPrefix(content, "apiVersion:")
+			kind = kind || strings.HasPrefix(content, "kind:")
+		}
+	}
+
+	return apiVersion && kind
+}
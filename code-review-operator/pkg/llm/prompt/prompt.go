@@ -0,0 +1,120 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Data is the data prompt templates are rendered with
+type Data struct {
+	// Diff is the unified diff under review
+	Diff string
+
+	// Rules are the review rules to apply
+	Rules []string
+
+	// SeverityLevels are the severities to report
+	SeverityLevels []string
+
+	// Language is the main language of the code, if known
+	Language string
+
+	// Focus is the review guidance for the language, rendered from its template
+	Focus string
+
+	// Guidelines is the rendering of the rules and the glossary terms used in the diff
+	Guidelines string
+
+	// Repository is the full name of the repository under review, if known
+	Repository string
+
+	// Memory is context from previous reviews of the repository
+	Memory string
+
+	// Issues describes the issues the change says it resolves
+	Issues string
+
+	// RelatedChanges holds the diffs of the pull requests of other
+	// repositories the change is made together with
+	RelatedChanges string
+
+	// FileContext is surrounding code from the changed files
+	FileContext string
+
+	// Snippets is related code from elsewhere in the repository
+	Snippets string
+}
+
+// Template holds the Go text/template templates of a review prompt.
+// Templates are rendered with Data and may use the join, lower, upper and
+// trim functions.
+type Template struct {
+	// System is the template of the system prompt
+	System string
+
+	// User is the template of the user message
+	User string
+
+	// Focus is the template of the language's review guidance, rendered
+	// first and available to the other templates as .Focus
+	Focus string
+}
+
+// funcs are the functions available to prompt templates in addition to the
+// text/template built-ins
+var funcs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+}
+
+// sampleData is the data templates are validated against
+var sampleData = Data{
+	Diff:           "--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-package a\n+package main\n",
+	Rules:          []string{"sample rule"},
+	SeverityLevels: []string{"critical", "major", "minor", "suggestion"},
+}
+
+// Render renders the system prompt and user message
+func (t *Template) Render(data Data) (string, string, error) {
+	focus, err := render("focus", t.Focus, data)
+	if err != nil {
+		return "", "", err
+	}
+	data.Focus = focus
+
+	system, err := render("system", t.System, data)
+	if err != nil {
+		return "", "", err
+	}
+	user, err := render("user", t.User, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	return strings.TrimSpace(system), strings.TrimSpace(user), nil
+}
+
+// Validate returns an error if a template cannot be parsed or refers to data
+// that does not exist
+func (t *Template) Validate() error {
+	_, _, err := t.Render(sampleData)
+	return err
+}
+
+// render parses and executes a template
+func render(name, text string, data Data) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(funcs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("error parsing %s prompt template: %w", name, err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("error rendering %s prompt template: %w", name, err)
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,118 @@
+package prompt
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Template file names. Language templates are named after the language,
+// such as go.tmpl.
+const (
+	SystemFile = "system.tmpl"
+	UserFile   = "user.tmpl"
+	fileSuffix = ".tmpl"
+)
+
+//go:embed templates/*.tmpl
+var builtin embed.FS
+
+// Set holds the prompt templates: a system and a user template shared by all
+// languages, and the review guidance of each language
+type Set struct {
+	system    string
+	user      string
+	languages map[string]string
+}
+
+// Default returns the built-in templates
+func Default() *Set {
+	set := &Set{languages: make(map[string]string)}
+	entries, err := builtin.ReadDir("templates")
+	if err != nil {
+		panic(fmt.Sprintf("error reading built-in prompt templates: %v", err))
+	}
+	for _, entry := range entries {
+		data, err := builtin.ReadFile("templates/" + entry.Name())
+		if err != nil {
+			panic(fmt.Sprintf("error reading built-in prompt template %s: %v", entry.Name(), err))
+		}
+		set.add(entry.Name(), string(data))
+	}
+
+	return set
+}
+
+// WithOverrides returns a copy of the set with templates replaced by files,
+// such as the data of a ConfigMap. system.tmpl and user.tmpl replace the
+// shared templates; <language>.tmpl replaces or adds a language's guidance.
+// Every template is validated.
+func (s *Set) WithOverrides(files map[string]string) (*Set, error) {
+	set := &Set{system: s.system, user: s.user, languages: make(map[string]string, len(s.languages))}
+	for language, focus := range s.languages {
+		set.languages[language] = focus
+	}
+
+	var errs []error
+	for name, text := range files {
+		if !strings.HasSuffix(name, fileSuffix) {
+			errs = append(errs, fmt.Errorf("%s: prompt template names must end in %s", name, fileSuffix))
+			continue
+		}
+		set.add(name, text)
+	}
+	for name := range files {
+		if err := set.For(strings.TrimSuffix(name, fileSuffix)).Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return set, nil
+}
+
+// For returns the templates for a language, which may be a hint such as
+// "Golang". Languages without their own guidance get none.
+func (s *Set) For(language string) *Template {
+	return &Template{
+		System: s.system,
+		User:   s.user,
+		Focus:  s.languages[Normalize(language)],
+	}
+}
+
+// Digest returns the SHA-256 hash of the templates, which changes whenever
+// one of them does
+func (s *Set) Digest() string {
+	languages := make([]string, 0, len(s.languages))
+	for language := range s.languages {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00", s.system, s.user)
+	for _, language := range languages {
+		fmt.Fprintf(hash, "%s\x00%s\x00", language, s.languages[language])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// add sets a template by file name
+func (s *Set) add(name, text string) {
+	switch name {
+	case SystemFile:
+		s.system = text
+	case UserFile:
+		s.user = text
+	default:
+		s.languages[Normalize(strings.TrimSuffix(name, fileSuffix))] = text
+	}
+}
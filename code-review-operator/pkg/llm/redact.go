@@ -0,0 +1,239 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+)
+
+// ConfidentialFile is the name under which files dropped from the diff
+// because their path is confidential are counted
+const ConfidentialFile = "confidential file"
+
+// RedactionRule masks text matching a pattern before it is sent to an LLM
+type RedactionRule struct {
+	// Name describes what the rule masks, such as "email address"
+	Name string
+
+	// Pattern matches the text to mask
+	Pattern *regexp.Regexp
+}
+
+// DefaultRedactionRules are the built-in redaction rules: email addresses,
+// hostnames under internal top-level domains, private IPv4 addresses and
+// credentials assigned in configuration
+var DefaultRedactionRules = []RedactionRule{
+	{"email address", regexp.MustCompile(`\b[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}\b`)},
+	{"internal hostname", regexp.MustCompile(
+		`(?i)\b[a-z0-9](?:[a-z0-9\-]*[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9\-]*[a-z0-9])?)*\.(?:internal|corp|intranet|lan|local|localdomain|home\.arpa)\b`)},
+	{"private IP address", regexp.MustCompile(
+		`\b(?:10\.\d{1,3}|172\.(?:1[6-9]|2\d|3[01])|192\.168)\.\d{1,3}\.\d{1,3}\b`)},
+	{"credential", regexp.MustCompile(
+		`(?i)\b(?:password|passwd|secret|api[_-]?key|access[_-]?token|auth[_-]?token|client[_-]?secret)["']?\s*[:=]\s*["']?[^\s"',;]{6,}`)},
+}
+
+// Redactions counts the text masked before a review, by rule name
+type Redactions map[string]int
+
+// Add adds the counts of other to r
+func (r Redactions) Add(other Redactions) Redactions {
+	if len(other) == 0 {
+		return r
+	}
+	if r == nil {
+		r = make(Redactions, len(other))
+	}
+	for name, count := range other {
+		r[name] += count
+	}
+
+	return r
+}
+
+// String lists the counts, such as "confidential file (1), email address (2)"
+func (r Redactions) String() string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s (%d)", name, r[name]))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// Redactor masks sensitive text in diffs and prompts before they leave the cluster
+type Redactor struct {
+	// Rules are the redaction rules applied, in order
+	Rules []RedactionRule
+
+	// Confidential are path globs of files dropped from diffs entirely. A
+	// glob without a slash matches file names in any directory, and a glob
+	// matching a directory matches everything below it.
+	Confidential []string
+}
+
+// NewRedactor creates a redactor applying the built-in rules, unless builtin
+// is false, followed by the named user patterns
+func NewRedactor(builtin bool, patterns map[string]string, confidential []string) (*Redactor, error) {
+	redactor := &Redactor{Confidential: confidential}
+	if builtin {
+		redactor.Rules = append(redactor.Rules, DefaultRedactionRules...)
+	}
+
+	names := make([]string, 0, len(patterns))
+	for name := range patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pattern, err := regexp.Compile(patterns[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %s: %w", name, err)
+		}
+		redactor.Rules = append(redactor.Rules, RedactionRule{Name: name, Pattern: pattern})
+	}
+
+	return redactor, nil
+}
+
+// Redact masks the text matching the redaction rules, replacing each match
+// with a placeholder naming the rule
+func (r *Redactor) Redact(text string, counts Redactions) string {
+	for _, rule := range r.Rules {
+		placeholder := "[REDACTED " + rule.Name + "]"
+		text = rule.Pattern.ReplaceAllStringFunc(text, func(string) string {
+			counts[rule.Name]++
+			return placeholder
+		})
+	}
+
+	return text
+}
+
+// RedactDiff drops the confidential files from a diff and masks the rest.
+// Masking keeps the lines of the diff, so review comments still refer to
+// the right lines.
+func (r *Redactor) RedactDiff(unified string, counts Redactions) string {
+	if len(r.Confidential) > 0 {
+		files, err := diff.Parse(unified)
+		if err == nil {
+			var kept strings.Builder
+			for _, file := range files {
+				if r.confidential(file.NewPath) || r.confidential(file.OldPath) {
+					counts[ConfidentialFile]++
+					continue
+				}
+				kept.WriteString(file.String())
+			}
+			unified = kept.String()
+		}
+	}
+
+	return r.Redact(unified, counts)
+}
+
+// IsConfidential reports whether a file's content must not be sent to the LLM
+func (r *Redactor) IsConfidential(filePath string) bool {
+	return r.confidential(filePath)
+}
+
+// confidential reports whether a path matches a confidential glob
+func (r *Redactor) confidential(filePath string) bool {
+	if filePath == "" {
+		return false
+	}
+	for _, pattern := range r.Confidential {
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(filePath)); ok {
+				return true
+			}
+		}
+		for p := filePath; p != "." && p != "/"; p = path.Dir(p) {
+			if ok, _ := path.Match(strings.TrimSuffix(pattern, "/"), p); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// redactingClient masks sensitive text in the diff and in the context of a
+// review before passing them to the backend
+type redactingClient struct {
+	client   Client
+	redactor *Redactor
+}
+
+// NewRedactingClient returns a client that redacts what it sends to client.
+// The counts of masked text are recorded in the results' Redactions.
+func NewRedactingClient(client Client, redactor *Redactor) Client {
+	return &redactingClient{client: client, redactor: redactor}
+}
+
+// ReviewCode implements Client
+func (c *redactingClient) ReviewCode(ctx context.Context, diff string, options ReviewOptions) (*ReviewResult, error) {
+	return c.review(ctx, diff, options, nil)
+}
+
+// ReviewCodeStream implements Streamer
+func (c *redactingClient) ReviewCodeStream(ctx context.Context, diff string, options ReviewOptions, onEvent StreamFunc) (*ReviewResult, error) {
+	return c.review(ctx, diff, options, onEvent)
+}
+
+// review redacts the review input and reviews it, streaming events to
+// onEvent unless it is nil
+func (c *redactingClient) review(ctx context.Context, diff string, options ReviewOptions, onEvent StreamFunc) (*ReviewResult, error) {
+	counts := make(Redactions)
+	diff = c.redactor.RedactDiff(diff, counts)
+	if strings.TrimSpace(diff) == "" {
+		// Every file of the diff is confidential
+		return &ReviewResult{Redactions: counts}, nil
+	}
+	options.FileContext = c.redactor.Redact(options.FileContext, counts)
+	options.Memory = c.redactor.Redact(options.Memory, counts)
+	options.Issues = c.redactor.Redact(options.Issues, counts)
+	options.RelatedChanges = c.redactor.Redact(options.RelatedChanges, counts)
+	if len(options.Snippets) > 0 {
+		snippets := make([]string, len(options.Snippets))
+		for i, snippet := range options.Snippets {
+			snippets[i] = c.redactor.Redact(snippet, counts)
+		}
+		options.Snippets = snippets
+	}
+
+	result, err := ReviewStream(ctx, c.client, diff, options, onEvent)
+	if result != nil && len(counts) > 0 {
+		annotated := *result
+		annotated.Redactions = Redactions(nil).Add(result.Redactions).Add(counts)
+		result = &annotated
+	}
+
+	return result, err
+}
+
+// ModelID implements Identifier
+func (c *redactingClient) ModelID() string {
+	return ModelID(c.client)
+}
+
+// Summarize implements Summarizer. Summaries come from the backend, so they
+// are not redacted. If the backend cannot summarize, the summaries are joined.
+func (c *redactingClient) Summarize(ctx context.Context, summaries []string, options ReviewOptions) (string, error) {
+	summarizer, ok := c.client.(Summarizer)
+	if !ok {
+		return strings.Join(summaries, "\n\n"), nil
+	}
+
+	return summarizer.Summarize(ctx, summaries, options)
+}
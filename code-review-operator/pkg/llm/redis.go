@@ -0,0 +1,194 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// defaultRedisTimeout bounds each Redis command
+const defaultRedisTimeout = 5 * time.Second
+
+// maxIdleRedisConnections is the number of connections kept open between commands
+const maxIdleRedisConnections = 4
+
+// errRedisNil is the reply to GET of a missing key
+var errRedisNil = errors.New("redis: nil")
+
+// RedisConfig holds the settings of a RedisCache
+type RedisConfig struct {
+	// Address is the host:port of the Redis server
+	Address string
+
+	// Password authenticates to the server, if set
+	Password string
+
+	// DB is the database number
+	DB int
+
+	// Timeout bounds each command (defaults to 5s)
+	Timeout time.Duration
+}
+
+// RedisCache is a Cache stored in Redis, so cached reviews are shared by
+// the operator's replicas and survive restarts
+type RedisCache struct {
+	config RedisConfig
+	idle   chan *redisConn
+}
+
+// redisConn is a connection to a Redis server
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewRedisCache creates a RedisCache. Connections are opened on first use.
+func NewRedisCache(config RedisConfig) *RedisCache {
+	if config.Timeout == 0 {
+		config.Timeout = defaultRedisTimeout
+	}
+
+	return &RedisCache{config: config, idle: make(chan *redisConn, maxIdleRedisConnections)}
+}
+
+// Get implements Cache
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.do(ctx, "GET", key)
+	if errors.Is(err, errRedisNil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+// Set implements Cache
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	_, err := c.do(ctx, "SET", key, string(value), "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	return err
+}
+
+// do sends a command and returns its reply
+func (c *RedisCache) do(ctx context.Context, args ...string) ([]byte, error) {
+	conn, err := c.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := conn.do(ctx, c.config.Timeout, args...)
+	var replyErr redisError
+	if err != nil && !errors.Is(err, errRedisNil) && !errors.As(err, &replyErr) {
+		// The connection's state is unknown after I/O errors
+		conn.conn.Close()
+		return nil, fmt.Errorf("error sending Redis %s: %w", args[0], err)
+	}
+
+	select {
+	case c.idle <- conn:
+	default:
+		conn.conn.Close()
+	}
+
+	return reply, err
+}
+
+// conn returns an idle connection or opens one
+func (c *RedisCache) conn(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn := <-c.idle:
+		return conn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: c.config.Timeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", c.config.Address)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to Redis: %w", err)
+	}
+	conn := &redisConn{conn: netConn, reader: bufio.NewReader(netConn)}
+
+	if c.config.Password != "" {
+		if _, err := conn.do(ctx, c.config.Timeout, "AUTH", c.config.Password); err != nil {
+			netConn.Close()
+			return nil, fmt.Errorf("error authenticating to Redis: %w", err)
+		}
+	}
+	if c.config.DB != 0 {
+		if _, err := conn.do(ctx, c.config.Timeout, "SELECT", strconv.Itoa(c.config.DB)); err != nil {
+			netConn.Close()
+			return nil, fmt.Errorf("error selecting Redis database: %w", err)
+		}
+	}
+
+	return conn, nil
+}
+
+// redisError is an error reply from the server
+type redisError string
+
+// Error implements error
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// do sends a command as a RESP array of bulk strings and reads the reply
+func (c *redisConn) do(ctx context.Context, timeout time.Duration, args ...string) ([]byte, error) {
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	command := fmt.Sprintf("*%d\r\n", len(args))
+	for _, arg := range args {
+		command += fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.conn, command); err != nil {
+		return nil, err
+	}
+
+	return c.reply()
+}
+
+// reply reads a simple string, error, integer or bulk string reply
+func (c *redisConn) reply() ([]byte, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 {
+		return nil, fmt.Errorf("malformed Redis reply %q", line)
+	}
+	line = line[:len(line)-2]
+
+	switch line[0] {
+	case '+', ':':
+		return []byte(line[1:]), nil
+	case '-':
+		return nil, redisError(line[1:])
+	case '$':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("malformed Redis reply %q", line)
+		}
+		if length < 0 {
+			return nil, errRedisNil
+		}
+		data := make([]byte, length+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, err
+		}
+		return data[:length], nil
+	}
+
+	return nil, fmt.Errorf("unexpected Redis reply %q", line)
+}
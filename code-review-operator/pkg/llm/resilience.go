@@ -0,0 +1,240 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/metrics"
+)
+
+// RetryPolicy controls how requests to an LLM backend are retried and when
+// the backend is considered down
+type RetryPolicy struct {
+	// MaxRetries is the number of retries of a request failing transiently:
+	// timeouts, connection errors, rate limits and server errors
+	MaxRetries int
+
+	// RequestTimeout bounds each attempt, separately from the deadline of
+	// the whole review. Zero leaves attempts bounded by the HTTP client only.
+	RequestTimeout time.Duration
+
+	// Backoff is the delay before the first retry, doubled on each retry
+	Backoff time.Duration
+
+	// FailureThreshold is the number of consecutive transient failures that
+	// open the circuit breaker. Zero disables the breaker.
+	FailureThreshold int
+
+	// OpenDuration is how long an open breaker rejects requests before
+	// letting a single request through to probe the backend
+	OpenDuration time.Duration
+}
+
+// DefaultRetryPolicy is the policy of clients created by a Factory unless
+// another is set
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:       2,
+	Backoff:          2 * time.Second,
+	FailureThreshold: 5,
+	OpenDuration:     time.Minute,
+}
+
+// ErrCircuitOpen is matched by errors returned while a backend's circuit breaker is open
+var ErrCircuitOpen = errors.New("LLM backend circuit breaker is open")
+
+// CircuitOpenError is returned without contacting the backend while its
+// circuit breaker is open
+type CircuitOpenError struct {
+	// Provider is the backend
+	Provider string
+
+	// RetryAfter is how long until the breaker lets a request through again
+	RetryAfter time.Duration
+}
+
+// Error implements error
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("LLM backend %s is failing, requests are paused for %s", e.Provider, e.RetryAfter.Round(time.Second))
+}
+
+// Is matches ErrCircuitOpen
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// circuitBreaker stops requests to a backend after consecutive transient
+// failures, then lets a single request through once in a while to probe
+// whether the backend has recovered
+type circuitBreaker struct {
+	provider string
+	policy   RetryPolicy
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow reports whether a request may be sent, or returns the error to fail it with
+func (b *circuitBreaker) allow() error {
+	if b.policy.FailureThreshold <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openUntil.IsZero() {
+		return nil
+	}
+	if wait := time.Until(b.openUntil); wait > 0 {
+		return &CircuitOpenError{Provider: b.provider, RetryAfter: wait}
+	}
+	if b.probing {
+		return &CircuitOpenError{Provider: b.provider, RetryAfter: b.policy.OpenDuration}
+	}
+	b.probing = true
+
+	return nil
+}
+
+// record updates the breaker with the outcome of a request. Failures that
+// are not transient say nothing about the backend's health.
+func (b *circuitBreaker) record(err error) {
+	if b.policy.FailureThreshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case err == nil:
+		b.failures, b.openUntil, b.probing = 0, time.Time{}, false
+		metrics.LLMCircuitOpen.WithLabelValues(b.provider).Set(0)
+	case IsTransient(err):
+		b.failures++
+		if b.probing || b.failures >= b.policy.FailureThreshold {
+			b.openUntil, b.probing = time.Now().Add(b.policy.OpenDuration), false
+			metrics.LLMCircuitOpen.WithLabelValues(b.provider).Set(1)
+		}
+	default:
+		b.probing = false
+	}
+}
+
+// abandon gives up a probe cut short by its caller, so another request can probe
+func (b *circuitBreaker) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// resilientClient retries transient failures of a backend's requests,
+// bounds each attempt by the request timeout, and fails fast while the
+// backend's circuit breaker is open
+type resilientClient struct {
+	client  Client
+	breaker *circuitBreaker
+
+	// model identifies the backend and model
+	model string
+}
+
+// ModelID implements Identifier
+func (c *resilientClient) ModelID() string {
+	return c.model
+}
+
+// ReviewCode implements Client
+func (c *resilientClient) ReviewCode(ctx context.Context, diff string, options ReviewOptions) (*ReviewResult, error) {
+	var result *ReviewResult
+	err := c.do(ctx, "review", func(ctx context.Context) error {
+		var err error
+		result, err = c.client.ReviewCode(ctx, diff, options)
+		return err
+	})
+
+	return result, err
+}
+
+// ReviewCodeStream implements Streamer
+func (c *resilientClient) ReviewCodeStream(ctx context.Context, diff string, options ReviewOptions, onEvent StreamFunc) (*ReviewResult, error) {
+	var result *ReviewResult
+	err := c.do(ctx, "review", func(ctx context.Context) error {
+		var err error
+		result, err = ReviewStream(ctx, c.client, diff, options, onEvent)
+		return err
+	})
+
+	return result, err
+}
+
+// Summarize implements Summarizer. If the backend cannot summarize, the
+// summaries are joined.
+func (c *resilientClient) Summarize(ctx context.Context, summaries []string, options ReviewOptions) (string, error) {
+	summarizer, ok := c.client.(Summarizer)
+	if !ok {
+		return strings.Join(summaries, "\n\n"), nil
+	}
+
+	var summary string
+	err := c.do(ctx, "summarize", func(ctx context.Context) error {
+		var err error
+		summary, err = summarizer.Summarize(ctx, summaries, options)
+		return err
+	})
+
+	return summary, err
+}
+
+// do runs a request under the retry policy
+func (c *resilientClient) do(ctx context.Context, operation string, request func(ctx context.Context) error) error {
+	policy := c.breaker.policy
+	for attempt := 0; ; attempt++ {
+		if err := c.breaker.allow(); err != nil {
+			return err
+		}
+
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if policy.RequestTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, policy.RequestTimeout)
+		}
+		err := request(attemptCtx)
+		cancel()
+
+		// The review itself was cancelled or ran out of time
+		if ctx.Err() != nil {
+			c.breaker.abandon()
+			return err
+		}
+		c.breaker.record(err)
+		if err == nil || !IsTransient(err) || attempt >= policy.MaxRetries {
+			return err
+		}
+
+		// Honor Retry-After, otherwise back off exponentially with jitter
+		delay := policy.Backoff << uint(attempt)
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			delay = apiErr.RetryAfter
+		}
+		log.FromContext(ctx).Info("retrying LLM request", "llmProvider", c.breaker.provider, "operation", operation,
+			"attempt", attempt+1, "delay", delay, "error", err.Error())
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// StreamEvent is part of a review delivered while the LLM is still answering
+type StreamEvent struct {
+	// Comment is a finding the answer just completed, nil for summary text
+	Comment *ReviewComment
+
+	// Summary is the summary text received since the previous event
+	Summary string
+}
+
+// StreamFunc receives the events of a streamed review. It is called from the
+// goroutine running the review and should return quickly.
+type StreamFunc func(event StreamEvent)
+
+// Streamer is implemented by clients that can deliver a review as the LLM
+// writes it. The returned result is complete and authoritative; the events
+// only preview it, and a retried request may deliver them again.
+type Streamer interface {
+	ReviewCodeStream(ctx context.Context, diff string, options ReviewOptions, onEvent StreamFunc) (*ReviewResult, error)
+}
+
+// ReviewStream reviews a diff, streaming its comments and summary to onEvent
+// if the client implements Streamer. The results of other clients are
+// delivered as events once complete. A nil onEvent reviews without streaming.
+func ReviewStream(ctx context.Context, client Client, diff string, options ReviewOptions, onEvent StreamFunc) (*ReviewResult, error) {
+	if onEvent == nil {
+		return client.ReviewCode(ctx, diff, options)
+	}
+	if streamer, ok := client.(Streamer); ok {
+		return streamer.ReviewCodeStream(ctx, diff, options, onEvent)
+	}
+
+	result, err := client.ReviewCode(ctx, diff, options)
+	if err != nil {
+		return nil, err
+	}
+	emitResult(result, onEvent)
+
+	return result, nil
+}
+
+// emitResult delivers a complete result as events
+func emitResult(result *ReviewResult, onEvent StreamFunc) {
+	for i := range result.Comments {
+		comment := result.Comments[i]
+		onEvent(StreamEvent{Comment: &comment})
+	}
+	if result.Summary != "" {
+		onEvent(StreamEvent{Summary: result.Summary})
+	}
+}
+
+// streamParser extracts the comments and summary of a JSON review from the
+// text of the answer as it arrives. It follows the JSON structure only as far
+// as needed: completed objects of the top-level "comments" array are emitted
+// as comments, and the top-level "summary" string as it grows.
+type streamParser struct {
+	onEvent StreamFunc
+
+	buf []byte
+	pos int
+
+	depth         int
+	inString      bool
+	escaped       bool
+	stringStart   int
+	lastString    string
+	key           string
+	expectValue   bool
+	commentsDepth int
+	objectStart   int
+
+	// summaryStart is the offset of the summary's text while it is being
+	// read, and summarySent the number of its bytes already emitted
+	summaryStart int
+	summarySent  int
+}
+
+// newStreamParser creates a parser delivering events to onEvent
+func newStreamParser(onEvent StreamFunc) *streamParser {
+	return &streamParser{onEvent: onEvent, objectStart: -1, summaryStart: -1}
+}
+
+// Write feeds the next part of the answer to the parser
+func (p *streamParser) Write(text string) {
+	p.buf = append(p.buf, text...)
+	for ; p.pos < len(p.buf); p.pos++ {
+		c := p.buf[p.pos]
+		if p.inString {
+			switch {
+			case p.escaped:
+				p.escaped = false
+			case c == '\\':
+				p.escaped = true
+			case c == '"':
+				p.inString = false
+				p.endString()
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			p.inString = true
+			p.stringStart = p.pos
+			if p.depth == 1 && p.expectValue && p.key == "summary" {
+				p.summaryStart, p.summarySent = p.pos+1, 0
+			}
+		case '{', '[':
+			p.depth++
+			if c == '[' && p.depth == 2 && p.expectValue && p.key == "comments" {
+				p.commentsDepth = p.depth
+			}
+			if c == '{' && p.commentsDepth > 0 && p.depth == p.commentsDepth+1 {
+				p.objectStart = p.pos
+			}
+		case '}', ']':
+			if c == '}' && p.objectStart >= 0 && p.depth == p.commentsDepth+1 {
+				p.emitComment(p.buf[p.objectStart : p.pos+1])
+				p.objectStart = -1
+			}
+			if c == ']' && p.depth == p.commentsDepth {
+				p.commentsDepth = 0
+			}
+			p.depth--
+		case ':':
+			if p.depth == 1 {
+				p.key, p.expectValue = p.lastString, true
+			}
+		case ',':
+			if p.depth == 1 {
+				p.expectValue = false
+			}
+		}
+	}
+	p.emitSummary(false)
+}
+
+// endString handles the end of the string that started at stringStart
+func (p *streamParser) endString() {
+	if p.summaryStart >= 0 {
+		p.emitSummary(true)
+		p.summaryStart = -1
+		return
+	}
+	if p.depth == 1 && !p.expectValue {
+		var key string
+		if err := json.Unmarshal(p.buf[p.stringStart:p.pos+1], &key); err == nil {
+			p.lastString = key
+		}
+	}
+}
+
+// emitComment delivers a completed comment object
+func (p *streamParser) emitComment(object []byte) {
+	var comment ReviewComment
+	if err := json.Unmarshal(object, &comment); err == nil {
+		p.onEvent(StreamEvent{Comment: &comment})
+	}
+}
+
+// emitSummary delivers the summary text decoded since the last call. Until
+// the string is complete, text is held back up to the last character that
+// cannot be part of an escape sequence.
+func (p *streamParser) emitSummary(complete bool) {
+	if p.summaryStart < 0 {
+		return
+	}
+	end := p.pos
+	if !complete {
+		if p.inString {
+			end = safeStringEnd(p.buf[p.summaryStart:p.pos]) + p.summaryStart
+		} else {
+			return
+		}
+	}
+	if end-p.summaryStart <= p.summarySent {
+		return
+	}
+
+	var text string
+	if err := json.Unmarshal(append(append([]byte{'"'}, p.buf[p.summaryStart:end]...), '"'), &text); err != nil {
+		return
+	}
+	var sent string
+	_ = json.Unmarshal(append(append([]byte{'"'}, p.buf[p.summaryStart:p.summaryStart+p.summarySent]...), '"'), &sent)
+	p.summarySent = end - p.summaryStart
+	if delta := strings.TrimPrefix(text, sent); delta != "" {
+		p.onEvent(StreamEvent{Summary: delta})
+	}
+}
+
+// safeStringEnd returns the length of the longest prefix of raw JSON string
+// content that does not end inside an escape sequence or a UTF-8 character
+func safeStringEnd(raw []byte) int {
+	end := len(raw)
+	if i := strings.LastIndexByte(string(raw), '\\'); i >= 0 {
+		// A backslash preceded by an odd number of backslashes is escaped
+		backslashes := 0
+		for j := i; j >= 0 && raw[j] == '\\'; j-- {
+			backslashes++
+		}
+		escapeLength := 2
+		if backslashes%2 == 1 && i+1 < len(raw) && raw[i+1] == 'u' {
+			escapeLength = 6
+		}
+		if backslashes%2 == 1 && len(raw)-i < escapeLength {
+			end = i
+		}
+	}
+	for end > 0 && !utf8.Valid(raw[:end]) {
+		end--
+	}
+
+	return end
+}
+
+// readSSE reads a server-sent events stream, calling onEvent with the type
+// and data of each event until the stream ends or onEvent returns an error
+func readSSE(body io.Reader, onEvent func(event, data string) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64<<10), 4<<20)
+
+	var event string
+	var data strings.Builder
+	dispatch := func() error {
+		defer func() {
+			event = ""
+			data.Reset()
+		}()
+		if data.Len() == 0 {
+			return nil
+		}
+		return onEvent(event, strings.TrimSuffix(data.String(), "\n"))
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+			// Comment, such as a keep-alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+			data.WriteByte('\n')
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading response stream: %w", err)
+	}
+
+	return dispatch()
+}
@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	// DefaultVertexModel is the default Anthropic model on Vertex AI
+	DefaultVertexModel = "claude-3-5-sonnet-v2@20241022"
+
+	// vertexAnthropicVersion is the Messages API version sent to Vertex AI
+	vertexAnthropicVersion = "vertex-2023-10-16"
+)
+
+// VertexConfig configures a client for Anthropic models on Google Cloud Vertex AI
+type VertexConfig struct {
+	// Project is the Google Cloud project ID
+	Project string
+
+	// Region is the Vertex AI region, e.g. us-east5
+	Region string
+
+	// Model is the model name (defaults to DefaultVertexModel)
+	Model string
+
+	// Tokens provides access tokens, typically a GCPTokenSource using GKE Workload Identity
+	Tokens TokenSource
+
+	// MaxRetries is the number of retries on rate-limited or overloaded responses
+	MaxRetries int
+}
+
+// vertexPlatform serves the Messages API through Vertex AI
+type vertexPlatform struct {
+	config VertexConfig
+}
+
+// NewVertexClient creates a client for Anthropic models on Vertex AI
+func NewVertexClient(config VertexConfig) *AnthropicClient {
+	if config.Model == "" {
+		config.Model = DefaultVertexModel
+	}
+
+	c := NewAnthropicClient(AnthropicConfig{Model: config.Model, MaxRetries: config.MaxRetries})
+	c.platform = vertexPlatform{config: config}
+
+	return c
+}
+
+// newRequest implements anthropicPlatform
+func (p vertexPlatform) newRequest(ctx context.Context, reqBytes []byte) (*http.Request, error) {
+	reqBytes, err := platformBody(reqBytes, vertexAnthropicVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := p.config.Tokens.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create HTTP request
+	endpoint := fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/anthropic/models/%s:rawPredict",
+		p.config.Region, url.PathEscape(p.config.Project), p.config.Region, url.PathEscape(p.config.Model))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(reqBytes))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	return req, nil
+}
@@ -0,0 +1,170 @@
+// Package lsp converts review findings to Language Server Protocol
+// diagnostics, so editor extensions can show a pull request's findings inline
+// in a local checkout of its branch.
+package lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+)
+
+// Source is the source of the exported diagnostics
+const Source = "code-review-operator"
+
+// Diagnostic severities defined by the protocol
+const (
+	SeverityError       = 1
+	SeverityWarning     = 2
+	SeverityInformation = 3
+	SeverityHint        = 4
+)
+
+// Position is a zero-based line and character offset in a document
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a range in a document, its end exclusive
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// CodeDescription links a diagnostic to more information
+type CodeDescription struct {
+	Href string `json:"href"`
+}
+
+// Diagnostic is a finding on a range of a document
+type Diagnostic struct {
+	Range           Range            `json:"range"`
+	Severity        int              `json:"severity"`
+	Code            string           `json:"code,omitempty"`
+	CodeDescription *CodeDescription `json:"codeDescription,omitempty"`
+	Source          string           `json:"source"`
+	Message         string           `json:"message"`
+}
+
+// PublishDiagnosticsParams holds the diagnostics of a document, in the form
+// of a textDocument/publishDiagnostics notification
+type PublishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// severities maps review severities to diagnostic severities
+var severities = map[string]int{
+	"critical":   SeverityError,
+	"major":      SeverityError,
+	"minor":      SeverityWarning,
+	"suggestion": SeverityHint,
+}
+
+// severityPrefix matches the severity and rule heading of a posted comment,
+// such as "❌ **MAJOR** (no-todo): "
+var severityPrefix = regexp.MustCompile(`^[^*\n]*\*\*([A-Z]+)\*\*(?: \([^)\n]*\))?: `)
+
+// Export converts findings to diagnostics grouped by document. Paths are
+// resolved against root, the directory of the local checkout. Findings on
+// removed lines have no place in the checkout and are skipped.
+func Export(root string, comments []git.ReviewComment) []PublishDiagnosticsParams {
+	byFile := make(map[string][]Diagnostic)
+	for _, comment := range comments {
+		if comment.Side == "LEFT" || comment.Line <= 0 {
+			continue
+		}
+		byFile[comment.File] = append(byFile[comment.File], diagnostic(comment))
+	}
+
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	params := make([]PublishDiagnosticsParams, 0, len(files))
+	for _, file := range files {
+		diagnostics := byFile[file]
+		sort.SliceStable(diagnostics, func(i, j int) bool {
+			return diagnostics[i].Range.Start.Line < diagnostics[j].Range.Start.Line
+		})
+		params = append(params, PublishDiagnosticsParams{URI: fileURI(root, file), Diagnostics: diagnostics})
+	}
+
+	return params
+}
+
+// FromPostedComments returns the findings among the comments on a pull
+// request: the operator's comments that start a thread and are not outdated
+func FromPostedComments(posted []git.PostedComment) []git.ReviewComment {
+	var comments []git.ReviewComment
+	for _, comment := range posted {
+		rule, ours := git.ParseCommentMarker(comment.Body)
+		if !ours || comment.InReplyTo != "" || comment.Outdated() {
+			continue
+		}
+		content := git.StripCommentMarker(comment.Body)
+		severity := git.CommentSeverity(comment.Body)
+		if match := severityPrefix.FindStringSubmatch(content); match != nil {
+			content = content[len(match[0]):]
+			if severity == "" {
+				// Comments posted before severities were recorded in the marker
+				severity = strings.ToLower(match[1])
+			}
+		}
+		comments = append(comments, git.ReviewComment{
+			File:      comment.File,
+			Line:      comment.Line,
+			StartLine: comment.StartLine,
+			Side:      comment.Side,
+			Content:   content,
+			Severity:  severity,
+			Rule:      rule,
+		})
+	}
+
+	return comments
+}
+
+// diagnostic converts a finding to a diagnostic spanning its whole lines
+func diagnostic(comment git.ReviewComment) Diagnostic {
+	start := comment.Line
+	if comment.IsRange() {
+		start = comment.StartLine
+	}
+	severity, ok := severities[strings.ToLower(comment.Severity)]
+	if !ok {
+		severity = SeverityInformation
+	}
+
+	d := Diagnostic{
+		Range: Range{
+			Start: Position{Line: start - 1},
+			End:   Position{Line: comment.Line},
+		},
+		Severity: severity,
+		Code:     comment.Rule,
+		Source:   Source,
+		Message:  comment.Content,
+	}
+	if comment.Link != "" {
+		d.CodeDescription = &CodeDescription{Href: comment.Link}
+	}
+
+	return d
+}
+
+// fileURI returns the file URI of a repository path in the checkout at root
+func fileURI(root, file string) string {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Join(root, filepath.FromSlash(file)))}).String()
+}
@@ -0,0 +1,180 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConfigMapKey is the ConfigMap key the memory is stored under
+	ConfigMapKey = "memory.json"
+
+	// MemoryLabel marks ConfigMaps holding reviewer memory
+	MemoryLabel = "review.code-review.io/memory"
+
+	// RepositoryAnnotation records the repository a memory ConfigMap belongs to
+	RepositoryAnnotation = "review.code-review.io/repository"
+)
+
+// ConfigMapStore stores reviewer memory in one ConfigMap per repository
+type ConfigMapStore struct {
+	client    client.Client
+	namespace string
+}
+
+// NewConfigMapStore creates a store keeping memory ConfigMaps in the given namespace
+func NewConfigMapStore(c client.Client, namespace string) *ConfigMapStore {
+	return &ConfigMapStore{
+		client:    c,
+		namespace: namespace,
+	}
+}
+
+// Load implements Store
+func (s *ConfigMapStore) Load(ctx context.Context, owner, repo string) (*Memory, error) {
+	var configMap corev1.ConfigMap
+	err := s.client.Get(ctx, s.key(owner, repo), &configMap)
+	if apierrors.IsNotFound(err) {
+		return &Memory{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting memory configmap: %w", err)
+	}
+
+	return decode(&configMap)
+}
+
+// Update implements Store
+func (s *ConfigMapStore) Update(ctx context.Context, owner, repo string, update func(*Memory)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var configMap corev1.ConfigMap
+		err := s.client.Get(ctx, s.key(owner, repo), &configMap)
+		create := apierrors.IsNotFound(err)
+		if err != nil && !create {
+			return fmt.Errorf("error getting memory configmap: %w", err)
+		}
+
+		memory := &Memory{}
+		if !create {
+			if memory, err = decode(&configMap); err != nil {
+				return err
+			}
+		}
+		update(memory)
+
+		data, err := json.MarshalIndent(memory, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling memory: %w", err)
+		}
+
+		if create {
+			configMap = corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      s.key(owner, repo).Name,
+					Namespace: s.namespace,
+					Labels:    map[string]string{MemoryLabel: "true"},
+					Annotations: map[string]string{
+						RepositoryAnnotation: owner + "/" + repo,
+					},
+				},
+				Data: map[string]string{ConfigMapKey: string(data)},
+			}
+			return s.client.Create(ctx, &configMap)
+		}
+
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		configMap.Data[ConfigMapKey] = string(data)
+		return s.client.Update(ctx, &configMap)
+	})
+}
+
+// UpdateAll applies update to the memory of every repository, saving the
+// memories for which it returns true
+func (s *ConfigMapStore) UpdateAll(ctx context.Context, update func(repository string, mem *Memory) bool) error {
+	var configMaps corev1.ConfigMapList
+	if err := s.client.List(ctx, &configMaps, client.InNamespace(s.namespace), client.MatchingLabels{MemoryLabel: "true"}); err != nil {
+		return fmt.Errorf("error listing memory configmaps: %w", err)
+	}
+
+	var errs []error
+	for i := range configMaps.Items {
+		configMap := &configMaps.Items[i]
+		memory, err := decode(configMap)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !update(configMap.Annotations[RepositoryAnnotation], memory) {
+			continue
+		}
+
+		data, err := json.MarshalIndent(memory, "", "  ")
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error marshaling memory: %w", err))
+			continue
+		}
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		configMap.Data[ConfigMapKey] = string(data)
+		if err := s.client.Update(ctx, configMap); err != nil {
+			errs = append(errs, fmt.Errorf("error updating memory configmap %s: %w", configMap.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// key returns the name of the ConfigMap holding a repository's memory
+func (s *ConfigMapStore) key(owner, repo string) types.NamespacedName {
+	fullName := owner + "/" + repo
+
+	// Sanitize to a DNS label and add a hash so distinct repositories never collide
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '-'
+		}
+	}, fullName)
+	if len(name) > 40 {
+		name = name[:40]
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(fullName))
+
+	return types.NamespacedName{
+		Namespace: s.namespace,
+		Name:      fmt.Sprintf("review-memory-%s-%08x", strings.Trim(name, "-"), hash.Sum32()),
+	}
+}
+
+// decode parses the memory stored in a ConfigMap
+func decode(configMap *corev1.ConfigMap) (*Memory, error) {
+	memory := &Memory{}
+	data, ok := configMap.Data[ConfigMapKey]
+	if !ok {
+		return memory, nil
+	}
+	if err := json.Unmarshal([]byte(data), memory); err != nil {
+		return nil, fmt.Errorf("error parsing memory configmap %s: %w", configMap.Name, err)
+	}
+
+	return memory, nil
+}
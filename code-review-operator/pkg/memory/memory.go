@@ -0,0 +1,371 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxSummaries is the number of recent review summaries remembered per repository
+	MaxSummaries = 5
+
+	// maxSummaryLength is the length recorded summaries are condensed to
+	maxSummaryLength = 500
+
+	// maxIssues is the number of recurring issues included in a prompt
+	maxIssues = 10
+
+	// MaxPullRequests is the number of pull requests whose open findings are
+	// tracked per repository; the least recently reviewed are forgotten first
+	MaxPullRequests = 50
+)
+
+// Memory holds what the reviewer remembers about a repository from previous reviews
+type Memory struct {
+	// Conventions lists conventions the team has agreed on. The reviewer does
+	// not flag code that follows them. Conventions are curated by hand.
+	Conventions []string `json:"conventions,omitempty"`
+
+	// RecurringIssues counts findings per rule across the repository
+	RecurringIssues map[string]int `json:"recurringIssues,omitempty"`
+
+	// Authors holds what the reviewer remembers about each pull request author
+	Authors map[string]*AuthorMemory `json:"authors,omitempty"`
+
+	// Summaries holds condensed summaries of the most recent reviews, newest last
+	Summaries []string `json:"summaries,omitempty"`
+
+	// SummaryTimes holds when each of the summaries was recorded
+	SummaryTimes []time.Time `json:"summaryTimes,omitempty"`
+
+	// PullRequests tracks the open findings of recently reviewed pull requests by number
+	PullRequests map[int]*PullRequestMemory `json:"pullRequests,omitempty"`
+
+	// Cost tracks what reviewing the repository costs
+	Cost *CostMemory `json:"cost,omitempty"`
+}
+
+// CostMemory tracks what reviewing a repository costs per thousand changed
+// lines under the current configuration and an earlier one
+type CostMemory struct {
+	// Configuration identifies the settings and models Current is measured under
+	Configuration string `json:"configuration"`
+
+	// Current accumulates the reviews under Configuration
+	Current CostStats `json:"current"`
+
+	// Baseline holds the reviews under the last earlier configuration with
+	// enough of them to compare against
+	Baseline *CostStats `json:"baseline,omitempty"`
+
+	// Alerted is set once a regression of Configuration over Baseline was reported
+	Alerted bool `json:"alerted,omitempty"`
+}
+
+// CostStats accumulates the estimated cost of reviews and the lines they changed
+type CostStats struct {
+	Reviews int     `json:"reviews"`
+	Cost    float64 `json:"cost"`
+	Lines   int     `json:"lines"`
+}
+
+// PerKLoC returns the average cost per thousand changed lines
+func (s CostStats) PerKLoC() float64 {
+	if s.Lines == 0 {
+		return 0
+	}
+	return s.Cost / float64(s.Lines) * 1000
+}
+
+// PullRequestMemory tracks the findings reported on a pull request across pushes
+type PullRequestMemory struct {
+	// CommitSHA is the most recently reviewed commit
+	CommitSHA string `json:"commitSHA"`
+
+	// ReviewedAt is when the pull request was last reviewed
+	ReviewedAt time.Time `json:"reviewedAt"`
+
+	// Findings counts, per finding fingerprint, the consecutive pushes the
+	// finding has been reported on
+	Findings map[string]int `json:"findings,omitempty"`
+}
+
+// AuthorMemory holds what the reviewer remembers about one author
+type AuthorMemory struct {
+	// Reviews is the number of reviews of the author's pull requests
+	Reviews int `json:"reviews"`
+
+	// RecurringIssues counts findings per rule on the author's pull requests
+	RecurringIssues map[string]int `json:"recurringIssues,omitempty"`
+
+	// LastCommented holds, per rule, when the author last received an inline
+	// comment for it
+	LastCommented map[string]time.Time `json:"lastCommented,omitempty"`
+}
+
+// Store persists reviewer memory per repository
+type Store interface {
+	// Load returns the memory of a repository, which is empty if nothing has been recorded
+	Load(ctx context.Context, owner, repo string) (*Memory, error)
+
+	// Update applies update to the memory of a repository and saves it
+	Update(ctx context.Context, owner, repo string, update func(*Memory)) error
+}
+
+// Record adds the outcome of a review to the memory
+func (m *Memory) Record(author string, rules []string, summary string, now time.Time) {
+	if m.RecurringIssues == nil {
+		m.RecurringIssues = make(map[string]int)
+	}
+	for _, rule := range rules {
+		if rule != "" {
+			m.RecurringIssues[rule]++
+		}
+	}
+
+	if author != "" {
+		if m.Authors == nil {
+			m.Authors = make(map[string]*AuthorMemory)
+		}
+		authorMemory, ok := m.Authors[author]
+		if !ok {
+			authorMemory = &AuthorMemory{RecurringIssues: make(map[string]int)}
+			m.Authors[author] = authorMemory
+		}
+		authorMemory.Reviews++
+		for _, rule := range rules {
+			if rule != "" {
+				authorMemory.RecurringIssues[rule]++
+			}
+		}
+	}
+
+	if summary = condense(summary); summary != "" {
+		m.alignSummaryTimes()
+		m.Summaries = append(m.Summaries, summary)
+		m.SummaryTimes = append(m.SummaryTimes, now)
+		if len(m.Summaries) > MaxSummaries {
+			m.Summaries = m.Summaries[len(m.Summaries)-MaxSummaries:]
+			m.SummaryTimes = m.SummaryTimes[len(m.SummaryTimes)-MaxSummaries:]
+		}
+	}
+}
+
+// RecordCost adds the cost of a review of lines changed lines under a
+// configuration. When the configuration changed, the costs under the previous
+// one become the baseline if they cover at least minReviews reviews.
+func (m *Memory) RecordCost(configuration string, cost float64, lines, minReviews int) *CostMemory {
+	if m.Cost == nil {
+		m.Cost = &CostMemory{Configuration: configuration}
+	}
+	if m.Cost.Configuration != configuration {
+		if m.Cost.Current.Reviews >= minReviews {
+			baseline := m.Cost.Current
+			m.Cost.Baseline = &baseline
+		}
+		m.Cost.Configuration = configuration
+		m.Cost.Current = CostStats{}
+		m.Cost.Alerted = false
+	}
+
+	m.Cost.Current.Reviews++
+	m.Cost.Current.Cost += cost
+	m.Cost.Current.Lines += lines
+
+	return m.Cost
+}
+
+// RecordComments records that author received inline comments for rules
+func (m *Memory) RecordComments(author string, rules []string, now time.Time) {
+	if author == "" || len(rules) == 0 {
+		return
+	}
+	if m.Authors == nil {
+		m.Authors = make(map[string]*AuthorMemory)
+	}
+	authorMemory, ok := m.Authors[author]
+	if !ok {
+		authorMemory = &AuthorMemory{RecurringIssues: make(map[string]int)}
+		m.Authors[author] = authorMemory
+	}
+	if authorMemory.LastCommented == nil {
+		authorMemory.LastCommented = make(map[string]time.Time)
+	}
+	for _, rule := range rules {
+		if rule != "" {
+			authorMemory.LastCommented[rule] = now
+		}
+	}
+}
+
+// LastCommented returns when author last received an inline comment for
+// rule, or the zero time if they never did
+func (m *Memory) LastCommented(author, rule string) time.Time {
+	if authorMemory, ok := m.Authors[author]; ok {
+		return authorMemory.LastCommented[rule]
+	}
+
+	return time.Time{}
+}
+
+// Purge forgets review summaries recorded before summariesBefore and the
+// findings of pull requests last reviewed before findingsBefore, returning
+// how many of each were removed. A zero time keeps that kind of data.
+// Summaries recorded before their times were tracked count as expired.
+// Aggregate counts of recurring issues are kept.
+func (m *Memory) Purge(summariesBefore, findingsBefore time.Time) (int, int) {
+	summaries, findings := 0, 0
+
+	if !summariesBefore.IsZero() && len(m.Summaries) > 0 {
+		m.alignSummaryTimes()
+		keptSummaries, keptTimes := m.Summaries[:0], m.SummaryTimes[:0]
+		for i, summary := range m.Summaries {
+			if m.SummaryTimes[i].Before(summariesBefore) {
+				summaries++
+				continue
+			}
+			keptSummaries = append(keptSummaries, summary)
+			keptTimes = append(keptTimes, m.SummaryTimes[i])
+		}
+		m.Summaries, m.SummaryTimes = keptSummaries, keptTimes
+	}
+
+	if !findingsBefore.IsZero() {
+		for number, pr := range m.PullRequests {
+			if pr.ReviewedAt.Before(findingsBefore) {
+				findings += len(pr.Findings)
+				delete(m.PullRequests, number)
+			}
+		}
+	}
+
+	return summaries, findings
+}
+
+// alignSummaryTimes gives summaries recorded before their times were tracked
+// the zero time, so SummaryTimes lines up with Summaries
+func (m *Memory) alignSummaryTimes() {
+	if missing := len(m.Summaries) - len(m.SummaryTimes); missing > 0 {
+		m.SummaryTimes = append(make([]time.Time, missing), m.SummaryTimes...)
+	}
+}
+
+// TrackFindings records the findings reported on a review of a pull request
+// and returns, per fingerprint, the number of consecutive pushes the finding
+// has been reported on. Findings that are no longer reported are forgotten, so
+// they start over if they reappear. Reviewing the same commit again does not
+// count as another push.
+func (m *Memory) TrackFindings(prNumber int, commitSHA string, fingerprints []string, now time.Time) map[string]int {
+	if m.PullRequests == nil {
+		m.PullRequests = make(map[int]*PullRequestMemory)
+	}
+	previous, ok := m.PullRequests[prNumber]
+	if !ok {
+		previous = &PullRequestMemory{}
+	}
+	newPush := previous.CommitSHA != commitSHA
+
+	findings := make(map[string]int, len(fingerprints))
+	for _, fingerprint := range fingerprints {
+		if _, seen := findings[fingerprint]; seen {
+			continue
+		}
+		count := previous.Findings[fingerprint]
+		if newPush || count == 0 {
+			count++
+		}
+		findings[fingerprint] = count
+	}
+	m.PullRequests[prNumber] = &PullRequestMemory{
+		CommitSHA:  commitSHA,
+		ReviewedAt: now,
+		Findings:   findings,
+	}
+
+	// Forget the least recently reviewed pull requests, which are most likely closed
+	for len(m.PullRequests) > MaxPullRequests {
+		oldest := prNumber
+		for number, pr := range m.PullRequests {
+			if pr.ReviewedAt.Before(m.PullRequests[oldest].ReviewedAt) {
+				oldest = number
+			}
+		}
+		delete(m.PullRequests, oldest)
+	}
+
+	return findings
+}
+
+// Prompt renders the memory as prompt guidance for a review of the given author's pull request
+func (m *Memory) Prompt(author string) string {
+	var b strings.Builder
+
+	if len(m.Conventions) > 0 {
+		b.WriteString("The team has agreed on the following conventions. Do not flag code that follows them or suggest alternatives:\n")
+		for _, convention := range m.Conventions {
+			fmt.Fprintf(&b, "- %s\n", convention)
+		}
+	}
+
+	if authorMemory, ok := m.Authors[author]; ok && author != "" {
+		if issues := topIssues(authorMemory.RecurringIssues); len(issues) > 0 {
+			fmt.Fprintf(&b, "Issues previously raised on pull requests by %s. Mention repeats briefly rather than explaining them again:\n", author)
+			for _, issue := range issues {
+				fmt.Fprintf(&b, "- %s\n", issue)
+			}
+		}
+	}
+
+	if issues := topIssues(m.RecurringIssues); len(issues) > 0 {
+		b.WriteString("Issues that recur in this repository:\n")
+		for _, issue := range issues {
+			fmt.Fprintf(&b, "- %s\n", issue)
+		}
+	}
+
+	if len(m.Summaries) > 0 {
+		b.WriteString("Summaries of recent reviews in this repository:\n")
+		for _, summary := range m.Summaries {
+			fmt.Fprintf(&b, "- %s\n", summary)
+		}
+	}
+
+	return b.String()
+}
+
+// topIssues returns the most frequent rules, formatted with their counts
+func topIssues(counts map[string]int) []string {
+	rules := make([]string, 0, len(counts))
+	for rule := range counts {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if counts[rules[i]] != counts[rules[j]] {
+			return counts[rules[i]] > counts[rules[j]]
+		}
+		return rules[i] < rules[j]
+	})
+	if len(rules) > maxIssues {
+		rules = rules[:maxIssues]
+	}
+
+	issues := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		issues = append(issues, fmt.Sprintf("%s (%d times)", rule, counts[rule]))
+	}
+
+	return issues
+}
+
+// condense shortens a summary to a single line of at most maxSummaryLength characters
+func condense(summary string) string {
+	summary = strings.Join(strings.Fields(summary), " ")
+	if len(summary) > maxSummaryLength {
+		summary = summary[:maxSummaryLength-3] + "..."
+	}
+
+	return summary
+}
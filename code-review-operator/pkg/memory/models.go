@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ModelRegistryName is the name of the ConfigMap holding what the
+	// reviewer learned about the models it reviews with
+	ModelRegistryName = "review-model-registry"
+
+	// ModelRegistryKey is the ConfigMap key the model registry is stored under
+	ModelRegistryKey = "models.json"
+)
+
+// ModelMemory holds what the reviewer learned about a model
+type ModelMemory struct {
+	// ChunkTokens is the size in tokens diffs are split into for the model,
+	// lowered when chunks exceeded its context window or output limit
+	ChunkTokens int `json:"chunkTokens,omitempty"`
+
+	// LearnedAt is when ChunkTokens was last lowered
+	LearnedAt time.Time `json:"learnedAt,omitempty"`
+}
+
+// ModelRegistry stores what the reviewer learned about each model, keyed
+// by backend and model, in a ConfigMap. Removing a model's entry makes the
+// reviewer start over from the configured chunk size.
+type ModelRegistry struct {
+	client client.Client
+	key    types.NamespacedName
+}
+
+// NewModelRegistry creates a registry kept in a ConfigMap in the given namespace
+func NewModelRegistry(c client.Client, namespace string) *ModelRegistry {
+	return &ModelRegistry{
+		client: c,
+		key:    types.NamespacedName{Namespace: namespace, Name: ModelRegistryName},
+	}
+}
+
+// ChunkTokens returns the chunk size learned for a model, or 0 if none was
+func (r *ModelRegistry) ChunkTokens(ctx context.Context, model string) (int, error) {
+	var configMap corev1.ConfigMap
+	err := r.client.Get(ctx, r.key, &configMap)
+	if apierrors.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error getting model registry configmap: %w", err)
+	}
+	models, err := decodeModels(&configMap)
+	if err != nil {
+		return 0, err
+	}
+	if models[model] == nil {
+		return 0, nil
+	}
+
+	return models[model].ChunkTokens, nil
+}
+
+// LowerChunkTokens records that a model handles chunks of at most tokens,
+// unless a smaller size was already recorded
+func (r *ModelRegistry) LowerChunkTokens(ctx context.Context, model string, tokens int) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var configMap corev1.ConfigMap
+		err := r.client.Get(ctx, r.key, &configMap)
+		create := apierrors.IsNotFound(err)
+		if err != nil && !create {
+			return fmt.Errorf("error getting model registry configmap: %w", err)
+		}
+
+		models := make(map[string]*ModelMemory)
+		if !create {
+			if models, err = decodeModels(&configMap); err != nil {
+				return err
+			}
+		}
+		if current := models[model]; current != nil && current.ChunkTokens > 0 && current.ChunkTokens <= tokens {
+			return nil
+		}
+		models[model] = &ModelMemory{ChunkTokens: tokens, LearnedAt: time.Now().UTC().Truncate(time.Second)}
+
+		data, err := json.MarshalIndent(models, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling model registry: %w", err)
+		}
+
+		if create {
+			configMap = corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      r.key.Name,
+					Namespace: r.key.Namespace,
+					Labels:    map[string]string{MemoryLabel: "models"},
+				},
+				Data: map[string]string{ModelRegistryKey: string(data)},
+			}
+			return r.client.Create(ctx, &configMap)
+		}
+
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		configMap.Data[ModelRegistryKey] = string(data)
+		return r.client.Update(ctx, &configMap)
+	})
+}
+
+// decodeModels parses the model registry stored in a ConfigMap
+func decodeModels(configMap *corev1.ConfigMap) (map[string]*ModelMemory, error) {
+	models := make(map[string]*ModelMemory)
+	data, ok := configMap.Data[ModelRegistryKey]
+	if !ok {
+		return models, nil
+	}
+	if err := json.Unmarshal([]byte(data), &models); err != nil {
+		return nil, fmt.Errorf("error parsing model registry configmap %s: %w", configMap.Name, err)
+	}
+	if models == nil {
+		models = make(map[string]*ModelMemory)
+	}
+
+	return models, nil
+}
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// OtherRepositories is the repository label of the repositories beyond the
+// top repositories
+const OtherRepositories = "other"
+
+// NoRule is the rule label of comments made for no rule
+const NoRule = "none"
+
+// Cardinality limits the label values of the metrics keyed by repository or
+// rule, which grow with the number of repositories reviewed and the rules
+// findings are reported for. The review history keeps the full detail.
+type Cardinality struct {
+	// HashRepositories replaces repository names with a hash of them, so that
+	// names are not exposed to everyone reading the metrics
+	HashRepositories bool
+
+	// TopRepositories is the number of repositories with their own label:
+	// those with the most reviews since the operator started. The others
+	// share the label OtherRepositories. 0 gives every repository its own.
+	TopRepositories int
+
+	// RuleLabels enables CommentsByRule
+	RuleLabels bool
+}
+
+// DefaultCardinality labels metrics with every repository and rule
+var DefaultCardinality = Cardinality{RuleLabels: true}
+
+// cardinality is the active Cardinality and the ranking of repositories
+var cardinality = struct {
+	sync.Mutex
+	Cardinality
+
+	// reviews counts the reviews of every repository seen
+	reviews map[string]int
+
+	// top are the repositories with their own label
+	top map[string]bool
+}{
+	Cardinality: DefaultCardinality,
+	reviews:     make(map[string]int),
+	top:         make(map[string]bool),
+}
+
+// SetCardinality sets the limits of the label values of the metrics. It is
+// meant to be called at startup.
+func SetCardinality(c Cardinality) {
+	cardinality.Lock()
+	defer cardinality.Unlock()
+
+	cardinality.Cardinality = c
+	cardinality.reviews = make(map[string]int)
+	cardinality.top = make(map[string]bool)
+}
+
+// RepositoryLabel returns the label value of a repository, given as
+// owner/name, and counts a review of it. Once TopRepositories repositories
+// have their own label, a repository with more reviews than the least
+// reviewed of them takes its place, and the series already recorded for it
+// under OtherRepositories stay there.
+func RepositoryLabel(repository string) string {
+	cardinality.Lock()
+	defer cardinality.Unlock()
+
+	label := repository
+	if cardinality.HashRepositories {
+		label = HashRepository(repository)
+	}
+	if cardinality.TopRepositories <= 0 {
+		return label
+	}
+
+	cardinality.reviews[repository]++
+	if cardinality.top[repository] {
+		return label
+	}
+	if len(cardinality.top) < cardinality.TopRepositories {
+		cardinality.top[repository] = true
+		return label
+	}
+
+	least, leastReviews := "", 0
+	for top := range cardinality.top {
+		if reviews := cardinality.reviews[top]; least == "" || reviews < leastReviews {
+			least, leastReviews = top, reviews
+		}
+	}
+	if cardinality.reviews[repository] <= leastReviews {
+		return OtherRepositories
+	}
+	delete(cardinality.top, least)
+	cardinality.top[repository] = true
+
+	return label
+}
+
+// HashRepository returns the label of a repository when repository names are
+// hashed: the first 12 hex digits of the SHA-256 of owner/name
+func HashRepository(repository string) string {
+	sum := sha256.Sum256([]byte(repository))
+
+	return hex.EncodeToString(sum[:6])
+}
+
+// CountRuleComment counts a comment posted for a rule in CommentsByRule,
+// unless rule labels are disabled
+func CountRuleComment(rule string) {
+	cardinality.Lock()
+	enabled := cardinality.RuleLabels
+	cardinality.Unlock()
+	if !enabled {
+		return
+	}
+
+	if rule == "" {
+		rule = NoRule
+	}
+	CommentsByRule.WithLabelValues(rule).Inc()
+}
@@ -0,0 +1,195 @@
+// Package metrics defines the operator's Prometheus metrics. They are
+// registered with the controller-runtime registry and served on the manager's
+// metrics endpoint.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Token types of ReviewTokens
+const (
+	TokenTypePrompt     = "prompt"
+	TokenTypeCompletion = "completion"
+)
+
+// Outcomes of LLM requests
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Results of LLM response and Git content cache lookups
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+// durationBuckets are the histogram buckets of reviews and LLM requests,
+// which take from seconds to several minutes
+var durationBuckets = prometheus.ExponentialBuckets(1, 2, 10)
+
+var (
+	// ReviewsStarted counts the reviews started
+	ReviewsStarted = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "codereview_reviews_started_total",
+		Help: "Reviews started.",
+	})
+
+	// ReviewsFinished counts the reviews finished, by the phase they ended in
+	ReviewsFinished = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "codereview_reviews_finished_total",
+		Help: "Reviews finished, by phase (Completed, Failed, TimedOut or Superseded).",
+	}, []string{"phase"})
+
+	// ReviewDuration observes how long reviews took from start to finish
+	ReviewDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "codereview_review_duration_seconds",
+		Help:    "Time from the start of a review to its end, by phase.",
+		Buckets: durationBuckets,
+	}, []string{"phase"})
+
+	// CommentsPosted counts the review comments posted, by severity
+	CommentsPosted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "codereview_comments_posted_total",
+		Help: "Review comments posted on pull requests, by severity.",
+	}, []string{"severity"})
+
+	// CommentsByRule counts the review comments posted, by rule, unless
+	// disabled by the metrics cardinality
+	CommentsByRule = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "codereview_comments_by_rule_total",
+		Help: "Review comments posted on pull requests, by rule.",
+	}, []string{"rule"})
+
+	// ReviewsRunning is the number of reviews holding a scheduler slot
+	ReviewsRunning = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "codereview_reviews_running",
+		Help: "Reviews currently running.",
+	})
+
+	// ReviewsQueued is the number of reviews waiting for a scheduler slot
+	ReviewsQueued = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "codereview_reviews_queued",
+		Help: "Reviews waiting for a free slot under the concurrency limits.",
+	})
+
+	// GitRequestDuration observes the latency of Git provider API requests
+	GitRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "codereview_git_request_duration_seconds",
+		Help:    "Latency of Git provider API requests, by provider, method and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"provider", "method", "code"})
+
+	// GitRateLimitRemaining is the most recently reported Git provider API quota left
+	GitRateLimitRemaining = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "codereview_git_rate_limit_remaining",
+		Help: "Requests left in the current Git provider rate limit window, as last reported.",
+	}, []string{"provider"})
+
+	// GitCacheLookups counts the lookups of the Git content cache
+	GitCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "codereview_git_cache_lookups_total",
+		Help: "Lookups of diffs and file contents pre-fetched or read by earlier reviews, by provider and result.",
+	}, []string{"provider", "result"})
+
+	// HTTPRequestDuration observes the latency of outbound HTTP requests
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "codereview_http_request_duration_seconds",
+		Help:    "Latency of outbound HTTP requests until their response headers, by host, method and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"host", "method", "code"})
+
+	// HTTPRequestsInFlight is the number of outbound HTTP requests in flight to each host
+	HTTPRequestsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "codereview_http_requests_in_flight",
+		Help: "Outbound HTTP requests in flight, including responses being read, by host.",
+	}, []string{"host"})
+
+	// HTTPRequestWait observes how long outbound HTTP requests waited for
+	// their host's budget
+	HTTPRequestWait = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "codereview_http_request_wait_seconds",
+		Help:    "Time outbound HTTP requests waited for a free slot under the per-host limit, by host.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"host"})
+
+	// LLMRequestDuration observes the latency of LLM requests
+	LLMRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "codereview_llm_request_duration_seconds",
+		Help:    "Latency of LLM requests, by backend, operation and outcome.",
+		Buckets: durationBuckets,
+	}, []string{"provider", "operation", "outcome"})
+
+	// LLMCircuitOpen is 1 while an LLM backend's circuit breaker is open
+	LLMCircuitOpen = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "codereview_llm_circuit_open",
+		Help: "Whether requests to an LLM backend are paused after repeated failures, by backend.",
+	}, []string{"provider"})
+
+	// LLMCacheLookups counts the lookups of the LLM response cache
+	LLMCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "codereview_llm_cache_lookups_total",
+		Help: "Lookups of previous LLM reviews of the same diff, by backend and result.",
+	}, []string{"provider", "result"})
+
+	// LLMChunkReductions counts the diff chunks split because they were too
+	// large for the model reviewing them
+	LLMChunkReductions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "codereview_llm_chunk_reductions_total",
+		Help: "Diff chunks split in half because they exceeded the context window or output limit of a model, by model.",
+	}, []string{"model"})
+
+	// ReviewTokens counts the LLM tokens used by reviews. Its repository
+	// label is limited by the Cardinality.
+	ReviewTokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "codereview_llm_tokens_total",
+		Help: "LLM tokens used by reviews, by namespace, repository and token type.",
+	}, []string{"namespace", "repository", "type"})
+
+	// ReviewCost counts the estimated cost of reviews in US dollars. Its
+	// repository label is limited by the Cardinality.
+	ReviewCost = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "codereview_llm_cost_dollars_total",
+		Help: "Estimated LLM cost of reviews in US dollars, by namespace and repository.",
+	}, []string{"namespace", "repository"})
+
+	// BudgetTokensUsed is the tokens used this month against each review budget
+	BudgetTokensUsed = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "codereview_budget_tokens_used",
+		Help: "Tokens used this month against a ReviewBudget.",
+	}, []string{"namespace", "budget"})
+
+	// BudgetTokensLimit is the monthly token limit of each review budget
+	BudgetTokensLimit = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "codereview_budget_tokens_limit",
+		Help: "Monthly token limit of a ReviewBudget.",
+	}, []string{"namespace", "budget"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		ReviewsStarted,
+		ReviewsFinished,
+		ReviewDuration,
+		CommentsPosted,
+		CommentsByRule,
+		ReviewsRunning,
+		ReviewsQueued,
+		GitRequestDuration,
+		GitRateLimitRemaining,
+		GitCacheLookups,
+		HTTPRequestDuration,
+		HTTPRequestsInFlight,
+		HTTPRequestWait,
+		LLMRequestDuration,
+		LLMCircuitOpen,
+		LLMCacheLookups,
+		LLMChunkReductions,
+		ReviewTokens,
+		ReviewCost,
+		BudgetTokensUsed,
+		BudgetTokensLimit,
+	)
+}
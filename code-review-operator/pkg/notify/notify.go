@@ -0,0 +1,174 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/httpx"
+	"github.com/Shridhar2104/code-review-operator/pkg/policy"
+)
+
+// Kind is the outcome of a review a notification reports
+type Kind string
+
+const (
+	// KindCompleted reports a review that was posted
+	KindCompleted Kind = "completed"
+
+	// KindFailed reports a review that failed or ran out of time
+	KindFailed Kind = "failed"
+)
+
+// Event describes the outcome of a review
+type Event struct {
+	// Kind is the outcome of the review
+	Kind Kind `json:"kind"`
+
+	// Namespace and Name identify the CodeReview
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+
+	// Owner, Repository and PullRequest identify the reviewed pull request
+	Owner       string `json:"owner"`
+	Repository  string `json:"repository"`
+	PullRequest int    `json:"pullRequest,omitempty"`
+
+	// CommitSHA is the reviewed head commit
+	CommitSHA string `json:"commitSHA,omitempty"`
+
+	// URL is the URL of the posted review
+	URL string `json:"url,omitempty"`
+
+	// Decision is the event the review was posted with
+	Decision string `json:"decision,omitempty"`
+
+	// Severities counts the findings by severity
+	Severities map[string]int `json:"severities,omitempty"`
+
+	// Reason and Message explain why a review failed
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+
+	// Time is when the review finished
+	Time time.Time `json:"time"`
+}
+
+// Target names the pull request of the event, as owner/repo#number
+func (e *Event) Target() string {
+	if e.PullRequest == 0 {
+		return e.Owner + "/" + e.Repository
+	}
+
+	return fmt.Sprintf("%s/%s#%d", e.Owner, e.Repository, e.PullRequest)
+}
+
+// Findings is the number of findings of the review
+func (e *Event) Findings() int {
+	total := 0
+	for _, count := range e.Severities {
+		total += count
+	}
+
+	return total
+}
+
+// Breakdown formats the findings by severity, most severe first, such as
+// "1 critical, 3 minor"
+func (e *Event) Breakdown() string {
+	if e.Findings() == 0 {
+		return "no findings"
+	}
+
+	severities := make([]string, 0, len(e.Severities))
+	for severity, count := range e.Severities {
+		if count > 0 {
+			severities = append(severities, severity)
+		}
+	}
+	sort.Slice(severities, func(i, j int) bool {
+		if ri, rj := policy.SeverityRank(severities[i]), policy.SeverityRank(severities[j]); ri != rj {
+			return ri > rj
+		}
+		return severities[i] < severities[j]
+	})
+	parts := make([]string, 0, len(severities))
+	for _, severity := range severities {
+		parts = append(parts, fmt.Sprintf("%d %s", e.Severities[severity], severity))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// Notifier sends notifications of review outcomes
+type Notifier interface {
+	// Notify sends a notification of the event
+	Notify(ctx context.Context, event *Event) error
+}
+
+// Sink types accepted by New
+const (
+	TypeSlack   = "slack"
+	TypeTeams   = "teams"
+	TypeWebhook = "webhook"
+)
+
+// New creates the notifier of the given type posting to url
+func New(sinkType, url string) (Notifier, error) {
+	if url == "" {
+		return nil, fmt.Errorf("no URL for %s notifications", sinkType)
+	}
+
+	switch sinkType {
+	case TypeSlack:
+		return NewSlackClient(url), nil
+	case TypeTeams:
+		return NewTeamsClient(url), nil
+	case TypeWebhook:
+		return NewWebhookClient(url), nil
+	default:
+		return nil, fmt.Errorf("unsupported notification type: %s", sinkType)
+	}
+}
+
+// newHTTPClient returns the HTTP client notifiers post with
+func newHTTPClient() *http.Client {
+	return httpx.NewClient(10 * time.Second)
+}
+
+// postJSON posts payload as JSON to url, reporting responses other than 2xx
+// as errors from service
+func postJSON(ctx context.Context, client *http.Client, url, service string, payload interface{}) error {
+	reqBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error marshaling message: %w", err)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send the request
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check for errors
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("error from %s: %s (status code: %d)", service, string(body), resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SlackClient posts messages to a Slack incoming webhook
+type SlackClient struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+// NewSlackClient creates a client posting to the given incoming webhook URL
+func NewSlackClient(webhookURL string) *SlackClient {
+	return &SlackClient{
+		webhookURL: webhookURL,
+		httpClient: newHTTPClient(),
+	}
+}
+
+// Post sends a message formatted with Slack mrkdwn
+func (c *SlackClient) Post(ctx context.Context, text string) error {
+	return postJSON(ctx, c.httpClient, c.webhookURL, "Slack", map[string]string{"text": text})
+}
+
+// Notify implements Notifier
+func (c *SlackClient) Notify(ctx context.Context, event *Event) error {
+	return c.Post(ctx, slackText(event))
+}
+
+// slackText formats an event with Slack mrkdwn
+func slackText(event *Event) string {
+	target := event.Target()
+	if event.URL != "" {
+		target = fmt.Sprintf("<%s|%s>", event.URL, target)
+	}
+
+	var b strings.Builder
+	switch event.Kind {
+	case KindFailed:
+		fmt.Fprintf(&b, ":x: Review of %s failed", target)
+		if event.Reason != "" {
+			fmt.Fprintf(&b, " (%s)", event.Reason)
+		}
+		if event.Message != "" {
+			fmt.Fprintf(&b, ": %s", event.Message)
+		}
+	default:
+		icon := ":white_check_mark:"
+		if event.Findings() > 0 {
+			icon = ":mag:"
+		}
+		fmt.Fprintf(&b, "%s Review of %s posted: %s", icon, target, event.Breakdown())
+		if event.Decision != "" {
+			fmt.Fprintf(&b, ", decision `%s`", event.Decision)
+		}
+	}
+	fmt.Fprintf(&b, " (CodeReview %s/%s)", event.Namespace, event.Name)
+
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// TeamsClient posts message cards to a Microsoft Teams incoming webhook
+type TeamsClient struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+// NewTeamsClient creates a client posting to the given incoming webhook URL
+func NewTeamsClient(webhookURL string) *TeamsClient {
+	return &TeamsClient{
+		webhookURL: webhookURL,
+		httpClient: newHTTPClient(),
+	}
+}
+
+// teamsCard is a legacy actionable message card, which Teams incoming
+// webhooks accept
+type teamsCard struct {
+	Type            string        `json:"@type"`
+	Context         string        `json:"@context"`
+	Summary         string        `json:"summary"`
+	ThemeColor      string        `json:"themeColor,omitempty"`
+	Title           string        `json:"title"`
+	Text            string        `json:"text,omitempty"`
+	Sections        []teamsFacts  `json:"sections,omitempty"`
+	PotentialAction []teamsAction `json:"potentialAction,omitempty"`
+}
+
+type teamsFacts struct {
+	Facts []teamsFact `json:"facts"`
+}
+
+type teamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type teamsAction struct {
+	Type    string        `json:"@type"`
+	Name    string        `json:"name"`
+	Targets []teamsTarget `json:"targets"`
+}
+
+type teamsTarget struct {
+	OS  string `json:"os"`
+	URI string `json:"uri"`
+}
+
+// Notify implements Notifier
+func (c *TeamsClient) Notify(ctx context.Context, event *Event) error {
+	return postJSON(ctx, c.httpClient, c.webhookURL, "Teams", teamsMessage(event))
+}
+
+// teamsMessage formats an event as a message card
+func teamsMessage(event *Event) *teamsCard {
+	card := &teamsCard{
+		Type:    "MessageCard",
+		Context: "https://schema.org/extensions",
+		Sections: []teamsFacts{{Facts: []teamsFact{
+			{Name: "CodeReview", Value: event.Namespace + "/" + event.Name},
+		}}},
+	}
+	facts := &card.Sections[0].Facts
+
+	switch event.Kind {
+	case KindFailed:
+		card.Title = fmt.Sprintf("Review of %s failed", event.Target())
+		card.ThemeColor = "D70000"
+		card.Text = event.Message
+		if event.Reason != "" {
+			*facts = append(*facts, teamsFact{Name: "Reason", Value: event.Reason})
+		}
+	default:
+		card.Title = fmt.Sprintf("Review of %s posted", event.Target())
+		card.ThemeColor = "2EB886"
+		if event.Findings() > 0 {
+			card.ThemeColor = "DAA038"
+		}
+		*facts = append(*facts, teamsFact{Name: "Findings", Value: event.Breakdown()})
+		if event.Decision != "" {
+			*facts = append(*facts, teamsFact{Name: "Decision", Value: event.Decision})
+		}
+	}
+	if event.CommitSHA != "" {
+		*facts = append(*facts, teamsFact{Name: "Commit", Value: event.CommitSHA})
+	}
+	card.Summary = card.Title
+
+	if event.URL != "" {
+		card.PotentialAction = []teamsAction{{
+			Type:    "OpenUri",
+			Name:    "Open review",
+			Targets: []teamsTarget{{OS: "default", URI: event.URL}},
+		}}
+	}
+
+	return card
+}
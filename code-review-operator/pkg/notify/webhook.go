@@ -0,0 +1,25 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+)
+
+// WebhookClient posts events as JSON to a generic webhook
+type WebhookClient struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewWebhookClient creates a client posting to the given URL
+func NewWebhookClient(url string) *WebhookClient {
+	return &WebhookClient{
+		url:        url,
+		httpClient: newHTTPClient(),
+	}
+}
+
+// Notify implements Notifier, posting the event as its JSON encoding
+func (c *WebhookClient) Notify(ctx context.Context, event *Event) error {
+	return postJSON(ctx, c.httpClient, c.url, "webhook", event)
+}
@@ -0,0 +1,36 @@
+package policy
+
+import (
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+)
+
+// ReviewPolicy decides whether a posted review approves the pull request,
+// requests changes, or only comments
+type ReviewPolicy struct {
+	// ApproveWhenClean approves the pull request when the review has no findings
+	ApproveWhenClean bool `json:"approveWhenClean,omitempty"`
+
+	// RequestChangesOn is the minimum severity that counts towards requesting
+	// changes. Changes are never requested when empty.
+	RequestChangesOn string `json:"requestChangesOn,omitempty"`
+
+	// MaxFindings is the number of counted findings tolerated before requesting changes
+	MaxFindings int `json:"maxFindings,omitempty"`
+}
+
+// Decide returns the review decision for a set of review comments, along
+// with a reason suitable for logging
+func (p ReviewPolicy) Decide(comments []git.ReviewComment) (git.ReviewDecision, string) {
+	if p.RequestChangesOn != "" {
+		decision := Policy{FailOn: p.RequestChangesOn, MaxFindings: p.MaxFindings}.Evaluate(comments)
+		if decision.Blocked {
+			return git.ReviewDecisionRequestChanges, decision.Reason
+		}
+	}
+
+	if p.ApproveWhenClean && len(comments) == 0 {
+		return git.ReviewDecisionApprove, "no findings"
+	}
+
+	return git.ReviewDecisionComment, "findings do not require changes"
+}
@@ -0,0 +1,106 @@
+package policy
+
+import (
+	"fmt"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+)
+
+// Severity levels, from most to least severe
+const (
+	SeverityCritical   = "critical"
+	SeverityMajor      = "major"
+	SeverityMinor      = "minor"
+	SeveritySuggestion = "suggestion"
+)
+
+// severityRanks orders severities, higher is more severe
+var severityRanks = map[string]int{
+	SeverityCritical:   4,
+	SeverityMajor:      3,
+	SeverityMinor:      2,
+	SeveritySuggestion: 1,
+}
+
+// SeverityRank returns the rank of a severity, higher is more severe.
+// Unknown severities rank lowest.
+func SeverityRank(severity string) int {
+	return severityRanks[severity]
+}
+
+// RaiseSeverity returns the severity levels above a severity, up to
+// critical. Unknown severities are returned unchanged.
+func RaiseSeverity(severity string, levels int) string {
+	rank := SeverityRank(severity)
+	if rank == 0 {
+		return severity
+	}
+	rank = min(rank+levels, severityRanks[SeverityCritical])
+	for name, r := range severityRanks {
+		if r == rank {
+			return name
+		}
+	}
+
+	return severity
+}
+
+// Policy decides whether the findings of a review block a pull request
+type Policy struct {
+	// FailOn is the minimum severity that counts towards blocking (defaults to critical)
+	FailOn string `json:"failOn,omitempty"`
+
+	// MaxFindings is the number of counted findings tolerated before blocking
+	MaxFindings int `json:"maxFindings,omitempty"`
+
+	// DisabledRules lists rules whose findings are ignored
+	DisabledRules []string `json:"disabledRules,omitempty"`
+}
+
+// Decision is the outcome of evaluating a policy
+type Decision struct {
+	// Blocked is true when the findings exceed the policy threshold
+	Blocked bool
+
+	// Counted is the number of findings at or above the FailOn severity
+	Counted int
+
+	// Reason explains the decision
+	Reason string
+}
+
+// Evaluate applies the policy to a set of review comments
+func (p Policy) Evaluate(comments []git.ReviewComment) Decision {
+	failOn := p.FailOn
+	if failOn == "" {
+		failOn = SeverityCritical
+	}
+	threshold := SeverityRank(failOn)
+
+	disabled := make(map[string]bool, len(p.DisabledRules))
+	for _, rule := range p.DisabledRules {
+		disabled[rule] = true
+	}
+
+	counted := 0
+	for _, comment := range comments {
+		if disabled[comment.Rule] {
+			continue
+		}
+		if SeverityRank(comment.Severity) >= threshold {
+			counted++
+		}
+	}
+
+	decision := Decision{
+		Blocked: counted > p.MaxFindings,
+		Counted: counted,
+	}
+	if decision.Blocked {
+		decision.Reason = fmt.Sprintf("%d finding(s) at or above %s (maximum %d)", counted, failOn, p.MaxFindings)
+	} else {
+		decision.Reason = fmt.Sprintf("%d finding(s) at or above %s within the maximum of %d", counted, failOn, p.MaxFindings)
+	}
+
+	return decision
+}
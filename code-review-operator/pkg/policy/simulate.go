@@ -0,0 +1,87 @@
+package policy
+
+import (
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+)
+
+// Record holds the stored findings of a past review
+type Record struct {
+	// Owner is the owner/organization of the repository
+	Owner string `json:"owner"`
+
+	// Repository is the name of the repository
+	Repository string `json:"repository"`
+
+	// PullRequest is the pull request number
+	PullRequest int `json:"pullRequest"`
+
+	// CommitSHA is the reviewed commit
+	CommitSHA string `json:"commitSHA,omitempty"`
+
+	// Comments are the findings of the review
+	Comments []git.ReviewComment `json:"comments"`
+}
+
+// Outcome is the result of replaying one review through the current and proposed policies
+type Outcome struct {
+	Record   Record
+	Current  Decision
+	Proposed Decision
+}
+
+// Changed reports whether the proposed policy reaches a different decision
+func (o Outcome) Changed() bool {
+	return o.Current.Blocked != o.Proposed.Blocked
+}
+
+// Report summarizes a simulation
+type Report struct {
+	// Total is the number of reviews replayed
+	Total int `json:"total"`
+
+	// CurrentBlocked is the number of reviews blocked by the current policy
+	CurrentBlocked int `json:"currentBlocked"`
+
+	// ProposedBlocked is the number of reviews blocked by the proposed policy
+	ProposedBlocked int `json:"proposedBlocked"`
+
+	// NewlyBlocked is the number of reviews the proposed policy blocks but the current one approves
+	NewlyBlocked int `json:"newlyBlocked"`
+
+	// NewlyApproved is the number of reviews the proposed policy approves but the current one blocks
+	NewlyApproved int `json:"newlyApproved"`
+
+	// Outcomes holds the per-review results
+	Outcomes []Outcome `json:"-"`
+}
+
+// Simulate replays stored findings through the current and proposed policies
+// to show how a policy change would have affected past pull requests
+func Simulate(records []Record, current, proposed Policy) Report {
+	report := Report{Total: len(records)}
+
+	for _, record := range records {
+		outcome := Outcome{
+			Record:   record,
+			Current:  current.Evaluate(record.Comments),
+			Proposed: proposed.Evaluate(record.Comments),
+		}
+
+		if outcome.Current.Blocked {
+			report.CurrentBlocked++
+		}
+		if outcome.Proposed.Blocked {
+			report.ProposedBlocked++
+		}
+		switch {
+		case outcome.Proposed.Blocked && !outcome.Current.Blocked:
+			report.NewlyBlocked++
+		case !outcome.Proposed.Blocked && outcome.Current.Blocked:
+			report.NewlyApproved++
+		}
+
+		report.Outcomes = append(report.Outcomes, outcome)
+	}
+
+	return report
+}
@@ -0,0 +1,105 @@
+// Package provenance records which system and configuration produced a
+// review: the operator version, the models and the prompt configuration the
+// review ran with, and a digest of the summary it posted. Statements are
+// signed with an Ed25519 key into DSSE envelopes, the envelope format of
+// in-toto and sigstore, and attached to the posted summary, so that the
+// decision of a review can be verified later with the public key alone.
+package provenance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// Version is the version of the operator, set at build time with
+// -ldflags "-X github.com/Shridhar2104/code-review-operator/pkg/provenance.Version=v1.2.3"
+var Version string
+
+// OperatorVersion returns the version of the operator: Version if set,
+// otherwise the module version or VCS revision recorded in the binary, or
+// "devel" if neither is known
+func OperatorVersion() string {
+	if Version != "" {
+		return Version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+
+	var revision string
+	modified := false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision == "" {
+		return "devel"
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if modified {
+		revision += "-dirty"
+	}
+
+	return revision
+}
+
+// Statement records what produced a review
+type Statement struct {
+	// Repository is the full name (owner/name) of the reviewed repository
+	Repository string `json:"repository"`
+
+	// PullRequest is the reviewed pull request number
+	PullRequest int `json:"pullRequest,omitempty"`
+
+	// CommitSHA is the reviewed commit
+	CommitSHA string `json:"commitSHA,omitempty"`
+
+	// Decision is the event the review was posted with
+	Decision string `json:"decision,omitempty"`
+
+	// Comments is the number of comments posted with the review
+	Comments int `json:"comments"`
+
+	// SummaryDigest is the SHA-256 digest of the summary, as computed by Digest
+	SummaryDigest string `json:"summaryDigest"`
+
+	// OperatorVersion is the version of the operator that ran the review
+	OperatorVersion string `json:"operatorVersion"`
+
+	// Models are the backends and models that reviewed the diff; empty for
+	// reviews made by the deterministic checks only
+	Models []string `json:"models,omitempty"`
+
+	// PromptHash is the SHA-256 hash of the prompt templates and the
+	// settings shaping the prompt
+	PromptHash string `json:"promptHash,omitempty"`
+
+	// LintOnly is set when no LLM reviewed the diff
+	LintOnly bool `json:"lintOnly,omitempty"`
+
+	// CreatedAt is when the statement was made
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Digest returns the SHA-256 digest of a summary, as "sha256:<hex>". Line
+// endings and surrounding white space are normalized first, as Git providers
+// may change them when storing the summary.
+func Digest(summary string) string {
+	normalized := strings.TrimSpace(strings.ReplaceAll(summary, "\r\n", "\n"))
+	sum := sha256.Sum256([]byte(normalized))
+
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
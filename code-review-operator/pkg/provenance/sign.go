@@ -0,0 +1,232 @@
+package provenance
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PayloadType is the DSSE payload type of provenance statements
+const PayloadType = "application/vnd.codereview.provenance+json"
+
+// marker starts the HTML comment a signed statement is attached to a summary in
+const marker = "<!-- codereview-provenance:"
+
+var (
+	// ErrNoAttestation is returned for summaries without a signed statement
+	ErrNoAttestation = errors.New("no provenance attestation found")
+
+	// ErrInvalidSignature is returned for envelopes no signature of which
+	// verifies with the public key
+	ErrInvalidSignature = errors.New("provenance signature does not verify")
+
+	// ErrDigestMismatch is returned for summaries changed since they were signed
+	ErrDigestMismatch = errors.New("summary does not match its signed digest")
+)
+
+// Envelope is a DSSE envelope holding a signed statement
+type Envelope struct {
+	// PayloadType is the type of the payload, PayloadType for statements
+	PayloadType string `json:"payloadType"`
+
+	// Payload is the JSON encoded statement
+	Payload []byte `json:"payload"`
+
+	// Signatures are the signatures of the payload
+	Signatures []Signature `json:"signatures"`
+}
+
+// Signature is a signature of a DSSE envelope
+type Signature struct {
+	// KeyID identifies the key that made the signature, as computed by KeyID
+	KeyID string `json:"keyid,omitempty"`
+
+	// Sig is the Ed25519 signature of the envelope's pre-authentication encoding
+	Sig []byte `json:"sig"`
+}
+
+// Signer signs statements with an Ed25519 key
+type Signer struct {
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+// NewSigner creates a signer signing with key
+func NewSigner(key ed25519.PrivateKey) *Signer {
+	return &Signer{key: key, keyID: KeyID(key.Public().(ed25519.PublicKey))}
+}
+
+// LoadSigner creates a signer with the PEM encoded PKCS #8 Ed25519 private
+// key in a file, such as one generated with openssl genpkey -algorithm ed25519
+func LoadSigner(path string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s is not PEM encoded", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing signing key %s: %w", path, err)
+	}
+	private, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is a %T, not an Ed25519 key", path, key)
+	}
+
+	return NewSigner(private), nil
+}
+
+// KeyID returns the ID of the signer's key
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// Sign signs a statement into an envelope
+func (s *Signer) Sign(statement *Statement) (*Envelope, error) {
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling provenance statement: %w", err)
+	}
+
+	return &Envelope{
+		PayloadType: PayloadType,
+		Payload:     payload,
+		Signatures: []Signature{{
+			KeyID: s.keyID,
+			Sig:   ed25519.Sign(s.key, pae(PayloadType, payload)),
+		}},
+	}, nil
+}
+
+// KeyID returns the ID of the signatures made with a public key's private
+// key: the first 16 hex digits of the SHA-256 hash of the public key
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// ParsePublicKey parses a PEM encoded PKIX Ed25519 public key, or the
+// public key of a PEM encoded PKCS #8 Ed25519 private key
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("public key is not PEM encoded")
+	}
+
+	var key any
+	var err error
+	if block.Type == "PRIVATE KEY" {
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		if private, ok := key.(ed25519.PrivateKey); ok {
+			key = private.Public()
+		}
+	} else {
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing public key: %w", err)
+	}
+	public, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is a %T, not an Ed25519 key", key)
+	}
+
+	return public, nil
+}
+
+// Verify checks that an envelope was signed with the private key of key and
+// returns its statement
+func Verify(envelope *Envelope, key ed25519.PublicKey) (*Statement, error) {
+	if envelope.PayloadType != PayloadType {
+		return nil, fmt.Errorf("unexpected payload type %q", envelope.PayloadType)
+	}
+	message := pae(envelope.PayloadType, envelope.Payload)
+	verified := false
+	for _, signature := range envelope.Signatures {
+		if ed25519.Verify(key, message, signature.Sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrInvalidSignature
+	}
+
+	var statement Statement
+	if err := json.Unmarshal(envelope.Payload, &statement); err != nil {
+		return nil, fmt.Errorf("error parsing provenance statement: %w", err)
+	}
+
+	return &statement, nil
+}
+
+// Attach appends a signed statement to a summary, in an HTML comment that
+// Git providers do not render
+func Attach(summary string, envelope *Envelope) (string, error) {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling provenance envelope: %w", err)
+	}
+
+	return fmt.Sprintf("%s\n\n%s%s -->", strings.TrimRight(summary, "\n"), marker, base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// Extract splits a posted summary into the summary that was signed and the
+// envelope attached to it
+func Extract(body string) (string, *Envelope, error) {
+	start := strings.LastIndex(body, marker)
+	if start < 0 {
+		return body, nil, ErrNoAttestation
+	}
+	encoded, _, ok := strings.Cut(body[start+len(marker):], "-->")
+	if !ok {
+		return body, nil, fmt.Errorf("unterminated provenance attestation")
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return body, nil, fmt.Errorf("error decoding provenance attestation: %w", err)
+	}
+	var envelope Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return body, nil, fmt.Errorf("error parsing provenance envelope: %w", err)
+	}
+
+	return body[:start], &envelope, nil
+}
+
+// VerifySummary checks a posted summary against the statement attached to
+// it: the statement must be signed with the private key of key, and the
+// summary must not have changed since. It returns the statement.
+func VerifySummary(body string, key ed25519.PublicKey) (*Statement, error) {
+	summary, envelope, err := Extract(body)
+	if err != nil {
+		return nil, err
+	}
+	statement, err := Verify(envelope, key)
+	if err != nil {
+		return nil, err
+	}
+	if Digest(summary) != statement.SummaryDigest {
+		return statement, ErrDigestMismatch
+	}
+
+	return statement, nil
+}
+
+// pae returns the DSSE pre-authentication encoding of a payload, which is
+// what envelopes sign
+func pae(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
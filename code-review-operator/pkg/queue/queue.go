@@ -0,0 +1,80 @@
+// Package queue serves the operator's review queue over HTTP, so operators
+// can see which reviews are waiting, running or failed, and cancel, reorder
+// or retry them during incidents without deleting CodeReviews by hand.
+package queue
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Review states
+const (
+	// StateQueued is a review waiting for a slot under the concurrency limits
+	StateQueued = "queued"
+
+	// StateRunning is a review being run
+	StateRunning = "running"
+
+	// StatePending is a review that has not asked for a slot yet, or is
+	// held, such as by a token budget
+	StatePending = "pending"
+
+	// StateFailed is a review that failed or timed out, and can be requeued
+	StateFailed = "failed"
+)
+
+var (
+	// ErrNotFound is returned for reviews that do not exist
+	ErrNotFound = errors.New("review not found")
+
+	// ErrInvalidState is returned for operations the review's state does not
+	// allow, such as cancelling a finished review
+	ErrInvalidState = errors.New("operation not allowed in the review's state")
+)
+
+// Entry is a review in the queue
+type Entry struct {
+	Namespace   string `json:"namespace"`
+	Name        string `json:"name"`
+	Provider    string `json:"provider"`
+	Repository  string `json:"repository"`
+	PullRequest int    `json:"pullRequest,omitempty"`
+	CommitSHA   string `json:"commitSHA"`
+
+	// State is queued, running, pending or failed
+	State string `json:"state"`
+
+	// Phase is the phase of the CodeReview
+	Phase string `json:"phase,omitempty"`
+
+	// Position is the place of a queued review in the queue, starting at 1
+	Position int `json:"position,omitempty"`
+
+	// Priority is the review's priority; higher priorities are admitted first
+	Priority int `json:"priority,omitempty"`
+
+	// Size is the number of changed lines, if known
+	Size int `json:"size,omitempty"`
+
+	// Since is when the review was queued, started or finished, depending on its state
+	Since time.Time `json:"since,omitempty"`
+}
+
+// Manager inspects and changes the review queue
+type Manager interface {
+	// List returns the unfinished and failed reviews, in a namespace or in
+	// every namespace if namespace is empty: the running reviews, the queued
+	// ones in queue order, then the pending and failed ones
+	List(ctx context.Context, namespace string) ([]Entry, error)
+
+	// Cancel stops a queued, pending or running review
+	Cancel(ctx context.Context, namespace, name string) error
+
+	// Prioritize sets the priority of an unfinished review
+	Prioritize(ctx context.Context, namespace, name string, priority int) error
+
+	// Requeue runs a failed or timed out review again
+	Requeue(ctx context.Context, namespace, name string) error
+}
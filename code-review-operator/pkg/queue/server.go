@@ -0,0 +1,264 @@
+package queue
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// maxBody is the most of a request body, or of an error response, read
+const maxBody = 4 << 10
+
+// Server serves the review queue over HTTP:
+//
+//	GET  /reviews[?namespace=ns]                  lists the queue as a JSON array of Entry
+//	POST /reviews/{namespace}/{name}/cancel       cancels a review
+//	POST /reviews/{namespace}/{name}/priority     sets a review's priority, from {"priority": n}
+//	POST /reviews/{namespace}/{name}/requeue      runs a failed review again
+//
+// Changes need the token as a bearer token; without a token the queue is
+// read-only.
+type Server struct {
+	// Addr is the address the server listens on
+	Addr string
+
+	// Manager inspects and changes the queue
+	Manager Manager
+
+	// Token authorizes changes to the queue
+	Token string
+}
+
+// Start implements manager.Runnable
+func (s *Server) Start(ctx context.Context) error {
+	server := &http.Server{
+		Addr:              s.Addr,
+		Handler:           Handler(s.Manager, s.Token),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+	log.FromContext(ctx).Info("serving review queue", "address", s.Addr, "readOnly", s.Token == "")
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	case err := <-errs:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. The queue
+// lives in the leader, so only the leader serves it.
+func (s *Server) NeedLeaderElection() bool {
+	return true
+}
+
+// Handler returns the HTTP handler of the queue API. Changes need token as a
+// bearer token, and are refused if token is empty.
+func Handler(manager Manager, token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /reviews", func(w http.ResponseWriter, req *http.Request) {
+		entries, err := manager.List(req.Context(), req.URL.Query().Get("namespace"))
+		if err != nil {
+			writeError(w, req, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(entries); err != nil {
+			log.FromContext(req.Context()).Error(err, "unable to write review queue")
+		}
+	})
+	mux.HandleFunc("POST /reviews/{namespace}/{name}/cancel", authorized(token, func(w http.ResponseWriter, req *http.Request) {
+		respond(w, req, manager.Cancel(req.Context(), req.PathValue("namespace"), req.PathValue("name")))
+	}))
+	mux.HandleFunc("POST /reviews/{namespace}/{name}/priority", authorized(token, func(w http.ResponseWriter, req *http.Request) {
+		var body struct {
+			Priority *int `json:"priority"`
+		}
+		if err := json.NewDecoder(io.LimitReader(req.Body, maxBody)).Decode(&body); err != nil || body.Priority == nil {
+			http.Error(w, `expected a body of the form {"priority": <integer>}`, http.StatusBadRequest)
+			return
+		}
+		respond(w, req, manager.Prioritize(req.Context(), req.PathValue("namespace"), req.PathValue("name"), *body.Priority))
+	}))
+	mux.HandleFunc("POST /reviews/{namespace}/{name}/requeue", authorized(token, func(w http.ResponseWriter, req *http.Request) {
+		respond(w, req, manager.Requeue(req.Context(), req.PathValue("namespace"), req.PathValue("name")))
+	}))
+
+	return mux
+}
+
+// authorized wraps a handler changing the queue so that it only runs for
+// requests bearing the token
+func authorized(token string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if token == "" {
+			http.Error(w, "changes to the review queue are disabled", http.StatusForbidden)
+			return
+		}
+		bearer, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler(w, req)
+	}
+}
+
+// respond answers a change to the queue
+func respond(w http.ResponseWriter, req *http.Request, err error) {
+	if err != nil {
+		writeError(w, req, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeError answers a request that failed with the status of its error
+func writeError(w http.ResponseWriter, req *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrInvalidState):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.FromContext(req.Context()).Error(err, "unable to serve review queue request", "path", req.URL.Path)
+		http.Error(w, "error serving review queue request", http.StatusInternalServerError)
+	}
+}
+
+// Client is a Manager of the queue of a remote queue server
+type Client struct {
+	// URL is the base URL of the server
+	URL string
+
+	// Token authorizes changes to the queue
+	Token string
+
+	// HTTPClient sends the requests (defaults to http.DefaultClient)
+	HTTPClient *http.Client
+}
+
+// List implements Manager
+func (c *Client) List(ctx context.Context, namespace string) ([]Entry, error) {
+	target := c.URL + "/reviews"
+	if namespace != "" {
+		target += "?" + url.Values{"namespace": {namespace}}.Encode()
+	}
+	resp, err := c.do(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var entries []Entry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("error decoding review queue: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Cancel implements Manager
+func (c *Client) Cancel(ctx context.Context, namespace, name string) error {
+	return c.change(ctx, namespace, name, "cancel", nil)
+}
+
+// Prioritize implements Manager
+func (c *Client) Prioritize(ctx context.Context, namespace, name string, priority int) error {
+	return c.change(ctx, namespace, name, "priority", map[string]int{"priority": priority})
+}
+
+// Requeue implements Manager
+func (c *Client) Requeue(ctx context.Context, namespace, name string) error {
+	return c.change(ctx, namespace, name, "requeue", nil)
+}
+
+// change sends a change to a review
+func (c *Client) change(ctx context.Context, namespace, name, operation string, body any) error {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error marshaling request: %w", err)
+		}
+		payload = bytes.NewReader(data)
+	}
+	target := fmt.Sprintf("%s/reviews/%s/%s/%s", c.URL, url.PathEscape(namespace), url.PathEscape(name), operation)
+	resp, err := c.do(ctx, http.MethodPost, target, payload)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// do sends a request, turning error responses into ResponseErrors
+func (c *Client) do(ctx context.Context, method, target string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+
+	return nil, &ResponseError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(message))}
+}
+
+// ResponseError is an error response of a queue server
+type ResponseError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+
+	// Message is the body of the response
+	Message string
+}
+
+// Error implements error
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("review queue server returned %d: %s", e.StatusCode, e.Message)
+}
+
+// Is matches ErrNotFound and ErrInvalidState to the responses they are served as
+func (e *ResponseError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound ||
+		target == ErrInvalidState && e.StatusCode == http.StatusConflict
+}
@@ -0,0 +1,760 @@
+package repoconfig
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// RepoConfigPath is the path of the per-repository configuration file
+	RepoConfigPath = ".ai-review.yaml"
+
+	// AltRepoConfigPath is an alternative path of the per-repository
+	// configuration file, read if RepoConfigPath does not exist
+	AltRepoConfigPath = ".codereview.yaml"
+
+	// OrgConfigRepo is the name of the central repository holding org-wide defaults
+	OrgConfigRepo = ".ai-review"
+
+	// OrgConfigPath is the path of the org-wide configuration file in the central repository
+	OrgConfigPath = "config.yaml"
+)
+
+// Config is the review configuration read from a repository
+type Config struct {
+	// Rules is the list of review rules to apply
+	Rules []string `json:"rules,omitempty"`
+
+	// SeverityLevels is the list of severity levels to report
+	SeverityLevels []string `json:"severityLevels,omitempty"`
+
+	// Include is a list of path globs to review; when set, other files are not reviewed
+	Include []string `json:"include,omitempty"`
+
+	// Exclude is a list of path globs that should not be reviewed
+	Exclude []string `json:"exclude,omitempty"`
+
+	// DefaultExcludes controls whether the paths in DefaultExclude are skipped (defaults to true)
+	DefaultExcludes *bool `json:"defaultExcludes,omitempty"`
+
+	// Tone controls the writing style of review comments
+	Tone string `json:"tone,omitempty"`
+
+	// ModelTier selects the class of model used for the review
+	ModelTier string `json:"modelTier,omitempty"`
+
+	// Gating controls whether findings block the pull request
+	Gating *GatingPolicy `json:"gating,omitempty"`
+
+	// Glossary maps internal terms, service names and acronyms to their meaning
+	Glossary map[string]string `json:"glossary,omitempty"`
+
+	// Escalation escalates findings left unresolved across several pushes
+	Escalation *EscalationPolicy `json:"escalation,omitempty"`
+
+	// BranchSensitivity raises the severity of findings on pull requests
+	// targeting sensitive branches, such as release branches, or main during
+	// a release window. A pull request is matched against the first entry
+	// whose pattern matches its target branch.
+	BranchSensitivity []SensitiveBranch `json:"branchSensitivity,omitempty"`
+
+	// Language is a hint naming the main language of the code
+	Language string `json:"language,omitempty"`
+
+	// MaxComments is the maximum number of inline comments posted per review;
+	// the remaining findings are listed in the summary
+	MaxComments *int `json:"maxComments,omitempty"`
+
+	// MinSeverity is the least severe finding posted; less severe findings
+	// are dropped
+	MinSeverity string `json:"minSeverity,omitempty"`
+
+	// DisabledRules lists rules, or globs matching them, whose findings are
+	// dropped
+	DisabledRules []string `json:"disabledRules,omitempty"`
+
+	// FeatureFlags enables the feature flag hygiene checks
+	FeatureFlags *FeatureFlagPolicy `json:"featureFlags,omitempty"`
+
+	// RulePacks enables optional sets of deterministic checks by name
+	RulePacks []string `json:"rulePacks,omitempty"`
+
+	// Spelling enables the spelling and terminology checks of changed
+	// string literals and documentation
+	Spelling *SpellingPolicy `json:"spelling,omitempty"`
+
+	// RuleLimits caps the comments of individual rules, keyed by rule name
+	// or by a glob matching rule names such as "style/*"
+	RuleLimits map[string]RuleLimit `json:"ruleLimits,omitempty"`
+
+	// Analyzers enables or disables the deterministic analyzers by name;
+	// analyzers not listed run
+	Analyzers map[string]bool `json:"analyzers,omitempty"`
+
+	// Secrets configures the scan of the diff for leaked credentials
+	Secrets *SecretsPolicy `json:"secrets,omitempty"`
+
+	// Redaction configures what is masked in the diff and the review's
+	// context before they are sent to the LLM
+	Redaction *RedactionPolicy `json:"redaction,omitempty"`
+
+	// Correlation reviews the pull request with awareness of the related
+	// pull requests of other repositories
+	Correlation *CorrelationPolicy `json:"correlation,omitempty"`
+
+	// GitOps maps the paths of a GitOps repository to the Argo CD
+	// Applications and Flux Kustomizations that deploy them
+	GitOps *GitOpsPolicy `json:"gitops,omitempty"`
+
+	// Languages routes the changed files of a language, such as terraform
+	// or markdown, to their own model, prompt and rules
+	Languages map[string]LanguageRoute `json:"languages,omitempty"`
+}
+
+// Rule packs
+const (
+	// RulePackObservability checks logging and metrics in changed code
+	RulePackObservability = "observability"
+
+	// RulePackAccessibility checks the accessibility of changed frontend markup
+	RulePackAccessibility = "accessibility"
+
+	// RulePackSetup checks changed Dockerfiles, dev containers and Makefiles
+	RulePackSetup = "setup"
+
+	// RulePackMigrations checks changed database migrations
+	RulePackMigrations = "migrations"
+
+	// RulePackContracts checks changed API definitions for breaking changes
+	RulePackContracts = "contracts"
+)
+
+// GatingPolicy controls how review findings gate a pull request
+type GatingPolicy struct {
+	// FailOn is the minimum severity that fails the review
+	FailOn string `json:"failOn,omitempty"`
+
+	// MaxFindings is the number of findings at or above FailOn tolerated before failing
+	MaxFindings *int `json:"maxFindings,omitempty"`
+}
+
+// DefaultEscalationPushes is the number of pushes a finding must persist
+// before it is escalated when the escalation policy does not set one
+const DefaultEscalationPushes = 3
+
+// EscalationPolicy controls how findings that persist across pushes are escalated
+type EscalationPolicy struct {
+	// Pushes is the number of consecutive pushes a finding must be reported on
+	// before it is escalated (defaults to DefaultEscalationPushes)
+	Pushes *int `json:"pushes,omitempty"`
+
+	// Severity is the severity escalated findings are raised to
+	Severity string `json:"severity,omitempty"`
+
+	// Label is added to the pull request when a finding is escalated
+	Label string `json:"label,omitempty"`
+
+	// Notify sends escalated findings to the operator's Slack webhook
+	Notify *bool `json:"notify,omitempty"`
+}
+
+// SensitiveBranch is a target branch whose pull requests get findings of a
+// higher severity
+type SensitiveBranch struct {
+	// Pattern is a glob matching target branches, such as release/* or main
+	Pattern string `json:"pattern"`
+
+	// Levels is the number of severity levels findings are raised by
+	// (defaults to 1)
+	Levels *int `json:"levels,omitempty"`
+
+	// Windows are the release windows the branch is sensitive in. Branches
+	// without windows are always sensitive.
+	Windows []ReleaseWindow `json:"windows,omitempty"`
+}
+
+// ReleaseWindow is a period in which a branch is sensitive, such as the week
+// before a release
+type ReleaseWindow struct {
+	// Name describes the window, such as "v2.4 release"
+	Name string `json:"name,omitempty"`
+
+	// Start is when the window opens, as an RFC 3339 time or a date
+	Start string `json:"start"`
+
+	// End is when the window closes, as an RFC 3339 time or a date; windows
+	// ending on a date include that day
+	End string `json:"end"`
+}
+
+// Contains reports whether t is within the window. Windows with an invalid
+// start or end contain no time.
+func (w ReleaseWindow) Contains(t time.Time) bool {
+	start, err := parseWindowTime(w.Start, false)
+	if err != nil {
+		return false
+	}
+	end, err := parseWindowTime(w.End, true)
+	if err != nil {
+		return false
+	}
+
+	return !t.Before(start) && t.Before(end)
+}
+
+// parseWindowTime parses the start or end of a release window. Dates are
+// in UTC; the end of a window ending on a date is the end of that day.
+func parseWindowTime(value string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: must be an RFC 3339 time or a date", value)
+	}
+	if end {
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return day, nil
+}
+
+// Sensitive returns the entry of BranchSensitivity matching a target
+// branch and, if the entry has windows, the window t is in. It returns false
+// if no entry matches the branch, or if t is outside the windows of the
+// entry that does.
+func (c *Config) Sensitive(branch string, t time.Time) (SensitiveBranch, *ReleaseWindow, bool) {
+	for _, sensitive := range c.BranchSensitivity {
+		if matched, _ := path.Match(sensitive.Pattern, branch); !matched {
+			continue
+		}
+		if len(sensitive.Windows) == 0 {
+			return sensitive, nil, true
+		}
+		for i := range sensitive.Windows {
+			if sensitive.Windows[i].Contains(t) {
+				return sensitive, &sensitive.Windows[i], true
+			}
+		}
+
+		return SensitiveBranch{}, nil, false
+	}
+
+	return SensitiveBranch{}, nil, false
+}
+
+// FeatureFlagPolicy configures the feature flag hygiene checks
+type FeatureFlagPolicy struct {
+	// Patterns are regular expressions matching evaluations of a flag with
+	// the organization's flag SDK, capturing the flag's key in their first
+	// group. Defaults to common LaunchDarkly, Unleash and OpenFeature calls.
+	Patterns []string `json:"patterns,omitempty"`
+
+	// Severity is the severity of the findings (defaults to minor)
+	Severity string `json:"severity,omitempty"`
+}
+
+// SpellingPolicy configures the spelling and terminology checks
+type SpellingPolicy struct {
+	// Terms maps banned or deprecated terms to the term to use instead, in
+	// addition to the built-in ones; an empty replacement allows a built-in term
+	Terms map[string]string `json:"terms,omitempty"`
+
+	// Typos maps misspellings to their correction, in addition to the
+	// built-in list of common misspellings
+	Typos map[string]string `json:"typos,omitempty"`
+
+	// Ignore lists words that are never flagged
+	Ignore []string `json:"ignore,omitempty"`
+
+	// Inline posts the findings as inline comments instead of listing them
+	// in the summary
+	Inline *bool `json:"inline,omitempty"`
+}
+
+// SecretsPolicy configures the secret scan
+type SecretsPolicy struct {
+	// FailCheck fails the review's check run and commit status when a
+	// secret is found, whatever the gating policy
+	FailCheck *bool `json:"failCheck,omitempty"`
+
+	// Ignore lists path globs that are not scanned, such as test fixtures
+	Ignore []string `json:"ignore,omitempty"`
+}
+
+// RedactionPolicy configures the redaction of text sent to the LLM
+type RedactionPolicy struct {
+	// Builtin applies the built-in rules masking email addresses, internal
+	// hostnames, private IP addresses and credentials (defaults to true)
+	Builtin *bool `json:"builtin,omitempty"`
+
+	// Patterns maps names to regular expressions of further text to mask
+	Patterns map[string]string `json:"patterns,omitempty"`
+
+	// Confidential lists path globs of files never sent to the LLM
+	Confidential []string `json:"confidential,omitempty"`
+}
+
+// DefaultCorrelatedPullRequests is the number of related pull requests read
+// when the correlation policy does not set one
+const DefaultCorrelatedPullRequests = 3
+
+// CorrelationPolicy configures reviewing a pull request together with the
+// pull requests of other repositories that make up the same change
+type CorrelationPolicy struct {
+	// Enabled turns correlation on. Pull requests of other repositories the
+	// description references are related.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Labels are tracking labels: open pull requests of the owner's other
+	// repositories that share one of them with the pull request are related
+	Labels []string `json:"labels,omitempty"`
+
+	// MaxPullRequests is the number of related pull requests read (defaults
+	// to DefaultCorrelatedPullRequests)
+	MaxPullRequests *int `json:"maxPullRequests,omitempty"`
+}
+
+// Kinds of GitOps targets
+const (
+	// GitOpsApplication is an Argo CD Application
+	GitOpsApplication = "Application"
+
+	// GitOpsKustomization is a Flux Kustomization
+	GitOpsKustomization = "Kustomization"
+)
+
+// GitOpsPolicy connects the findings of a GitOps repository to what deploys
+// the changed files
+type GitOpsPolicy struct {
+	// Targets map paths to the Applications and Kustomizations deploying
+	// them. A file belongs to the first target whose path matches.
+	Targets []GitOpsTarget `json:"targets,omitempty"`
+
+	// HoldSyncOnCritical adds a deny sync window to the Argo CD project of
+	// every Application with critical findings, until a later review of the
+	// pull request has none for it
+	HoldSyncOnCritical *bool `json:"holdSyncOnCritical,omitempty"`
+}
+
+// GitOpsTarget is an Argo CD Application or Flux Kustomization deploying
+// the files under a path
+type GitOpsTarget struct {
+	// Path is the directory of the target, by segments: {placeholder}
+	// matches any one segment and records it for Name, * matches any one
+	// segment
+	Path string `json:"path"`
+
+	// Kind is Application or Kustomization
+	Kind string `json:"kind"`
+
+	// Name is the name of the Application or Kustomization, which may use
+	// the placeholders of Path
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the Application or Kustomization
+	Namespace string `json:"namespace,omitempty"`
+
+	// Project is the Argo CD project of an Application, whose sync windows
+	// hold it (defaults to "default")
+	Project string `json:"project,omitempty"`
+}
+
+// Match reports whether a file is under the target's path and, if so,
+// returns the target's name with the placeholders of the path filled in
+func (t GitOpsTarget) Match(file string) (string, bool) {
+	pattern := strings.Split(strings.Trim(t.Path, "/"), "/")
+	segments := strings.Split(strings.TrimPrefix(file, "/"), "/")
+	if len(segments) <= len(pattern) {
+		return "", false
+	}
+
+	name := t.Name
+	for i, part := range pattern {
+		switch {
+		case part == "*":
+		case strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}"):
+			name = strings.ReplaceAll(name, part, segments[i])
+		case part != segments[i]:
+			return "", false
+		}
+	}
+
+	return name, true
+}
+
+// RuleLimit limits how often a rule is commented on so recurring stylistic
+// feedback does not drown out substantive findings
+type RuleLimit struct {
+	// MaxComments is the maximum number of inline comments of the rule per
+	// review; the remaining findings are listed in the summary
+	MaxComments *int `json:"maxComments,omitempty"`
+
+	// Cooldown is how long after commenting on the rule it is not commented
+	// on again for the same author, such as "72h" or "7d"
+	Cooldown string `json:"cooldown,omitempty"`
+}
+
+// LanguageRoute reviews the files of a language apart from the others, with
+// their own model, prompt or rules
+type LanguageRoute struct {
+	// ModelTier selects the class of model reviewing the files (defaults to
+	// the review's model tier)
+	ModelTier string `json:"modelTier,omitempty"`
+
+	// Prompt names the ReviewPrompt reviewing the files (defaults to the
+	// review's prompt)
+	Prompt string `json:"prompt,omitempty"`
+
+	// Rules are review rules applied to the files in addition to the
+	// review's rules
+	Rules []string `json:"rules,omitempty"`
+}
+
+// RuleLimit returns the limit applying to a rule and the key it is configured
+// under. A limit keyed by the rule's name takes precedence over globs, which
+// are tried in lexical order.
+func (c *Config) RuleLimit(rule string) (string, RuleLimit, bool) {
+	if limit, ok := c.RuleLimits[rule]; ok {
+		return rule, limit, true
+	}
+
+	keys := make([]string, 0, len(c.RuleLimits))
+	for key := range c.RuleLimits {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if matched, _ := path.Match(key, rule); matched {
+			return key, c.RuleLimits[key], true
+		}
+	}
+
+	return "", RuleLimit{}, false
+}
+
+// RuleDisabled reports whether a rule is disabled by name or by glob
+func (c *Config) RuleDisabled(rule string) bool {
+	for _, disabled := range c.DisabledRules {
+		if matched, _ := path.Match(disabled, rule); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseCooldown parses a rule cooldown, which is a Go duration or a number
+// of days such as "7d"
+func ParseCooldown(cooldown string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(cooldown, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid cooldown %q", cooldown)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	return time.ParseDuration(cooldown)
+}
+
+// DefaultExclude lists the paths skipped unless DefaultExcludes is false:
+// vendored dependencies, lockfiles and generated code
+var DefaultExclude = []string{
+	"vendor/",
+	"node_modules/",
+	"third_party/",
+	"go.sum",
+	"package-lock.json",
+	"yarn.lock",
+	"pnpm-lock.yaml",
+	"Cargo.lock",
+	"Gemfile.lock",
+	"poetry.lock",
+	"composer.lock",
+	"*.pb.go",
+	"*.pb.gw.go",
+	"*_pb2.py",
+	"*_pb2_grpc.py",
+	"zz_generated.*",
+	"*.min.js",
+	"*.min.css",
+}
+
+// ExcludePatterns returns the path globs that should not be reviewed,
+// including DefaultExclude unless DefaultExcludes is false
+func (c *Config) ExcludePatterns() []string {
+	if c.DefaultExcludes != nil && !*c.DefaultExcludes {
+		return c.Exclude
+	}
+
+	return appendUnique(append([]string(nil), DefaultExclude...), c.Exclude...)
+}
+
+// Parse parses a configuration file
+func Parse(data []byte) (*Config, error) {
+	var config Config
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing config: %w", err)
+	}
+
+	return &config, nil
+}
+
+// Merge returns the result of layering override on top of base.
+// Fields set in override replace the corresponding fields in base, except
+// Exclude, which is combined so org-wide exclusions always apply, and
+// Glossary, whose terms are combined with override definitions winning.
+// Rule limits are combined per rule, with the fields set in override winning,
+// and analyzer settings are combined per analyzer.
+// Either argument may be nil.
+func Merge(base, override *Config) *Config {
+	merged := &Config{}
+	if base != nil {
+		*merged = *base
+	}
+	if override == nil {
+		return merged
+	}
+
+	if override.Rules != nil {
+		merged.Rules = override.Rules
+	}
+	if override.SeverityLevels != nil {
+		merged.SeverityLevels = override.SeverityLevels
+	}
+	if override.Include != nil {
+		merged.Include = override.Include
+	}
+	if override.Exclude != nil {
+		merged.Exclude = appendUnique(append([]string(nil), merged.Exclude...), override.Exclude...)
+	}
+	if override.DefaultExcludes != nil {
+		merged.DefaultExcludes = override.DefaultExcludes
+	}
+	if override.Tone != "" {
+		merged.Tone = override.Tone
+	}
+	if override.ModelTier != "" {
+		merged.ModelTier = override.ModelTier
+	}
+	if override.Language != "" {
+		merged.Language = override.Language
+	}
+	if override.MaxComments != nil {
+		merged.MaxComments = override.MaxComments
+	}
+	if override.MinSeverity != "" {
+		merged.MinSeverity = override.MinSeverity
+	}
+	if override.DisabledRules != nil {
+		merged.DisabledRules = appendUnique(append([]string(nil), merged.DisabledRules...), override.DisabledRules...)
+	}
+	if override.RulePacks != nil {
+		merged.RulePacks = override.RulePacks
+	}
+	if override.Glossary != nil {
+		glossary := make(map[string]string, len(merged.Glossary)+len(override.Glossary))
+		for term, definition := range merged.Glossary {
+			glossary[term] = definition
+		}
+		for term, definition := range override.Glossary {
+			glossary[term] = definition
+		}
+		merged.Glossary = glossary
+	}
+	if override.Gating != nil {
+		gating := GatingPolicy{}
+		if merged.Gating != nil {
+			gating = *merged.Gating
+		}
+		if override.Gating.FailOn != "" {
+			gating.FailOn = override.Gating.FailOn
+		}
+		if override.Gating.MaxFindings != nil {
+			gating.MaxFindings = override.Gating.MaxFindings
+		}
+		merged.Gating = &gating
+	}
+	if override.Escalation != nil {
+		escalation := EscalationPolicy{}
+		if merged.Escalation != nil {
+			escalation = *merged.Escalation
+		}
+		if override.Escalation.Pushes != nil {
+			escalation.Pushes = override.Escalation.Pushes
+		}
+		if override.Escalation.Severity != "" {
+			escalation.Severity = override.Escalation.Severity
+		}
+		if override.Escalation.Label != "" {
+			escalation.Label = override.Escalation.Label
+		}
+		if override.Escalation.Notify != nil {
+			escalation.Notify = override.Escalation.Notify
+		}
+		merged.Escalation = &escalation
+	}
+	if override.BranchSensitivity != nil {
+		merged.BranchSensitivity = override.BranchSensitivity
+	}
+	if override.FeatureFlags != nil {
+		featureFlags := FeatureFlagPolicy{}
+		if merged.FeatureFlags != nil {
+			featureFlags = *merged.FeatureFlags
+		}
+		if override.FeatureFlags.Patterns != nil {
+			featureFlags.Patterns = override.FeatureFlags.Patterns
+		}
+		if override.FeatureFlags.Severity != "" {
+			featureFlags.Severity = override.FeatureFlags.Severity
+		}
+		merged.FeatureFlags = &featureFlags
+	}
+	if override.Spelling != nil {
+		spelling := SpellingPolicy{}
+		if merged.Spelling != nil {
+			spelling = *merged.Spelling
+		}
+		spelling.Terms = mergeMaps(spelling.Terms, override.Spelling.Terms)
+		spelling.Typos = mergeMaps(spelling.Typos, override.Spelling.Typos)
+		spelling.Ignore = appendUnique(append([]string(nil), spelling.Ignore...), override.Spelling.Ignore...)
+		if override.Spelling.Inline != nil {
+			spelling.Inline = override.Spelling.Inline
+		}
+		merged.Spelling = &spelling
+	}
+
+	merged.Analyzers = mergeMaps(merged.Analyzers, override.Analyzers)
+
+	if override.Redaction != nil {
+		redaction := RedactionPolicy{}
+		if merged.Redaction != nil {
+			redaction = *merged.Redaction
+		}
+		if override.Redaction.Builtin != nil {
+			redaction.Builtin = override.Redaction.Builtin
+		}
+		redaction.Patterns = mergeMaps(redaction.Patterns, override.Redaction.Patterns)
+		redaction.Confidential = appendUnique(append([]string(nil), redaction.Confidential...),
+			override.Redaction.Confidential...)
+		merged.Redaction = &redaction
+	}
+
+	if override.Secrets != nil {
+		secrets := SecretsPolicy{}
+		if merged.Secrets != nil {
+			secrets = *merged.Secrets
+		}
+		if override.Secrets.FailCheck != nil {
+			secrets.FailCheck = override.Secrets.FailCheck
+		}
+		secrets.Ignore = appendUnique(append([]string(nil), secrets.Ignore...), override.Secrets.Ignore...)
+		merged.Secrets = &secrets
+	}
+
+	if override.Correlation != nil {
+		correlation := CorrelationPolicy{}
+		if merged.Correlation != nil {
+			correlation = *merged.Correlation
+		}
+		if override.Correlation.Enabled != nil {
+			correlation.Enabled = override.Correlation.Enabled
+		}
+		correlation.Labels = appendUnique(append([]string(nil), correlation.Labels...), override.Correlation.Labels...)
+		if override.Correlation.MaxPullRequests != nil {
+			correlation.MaxPullRequests = override.Correlation.MaxPullRequests
+		}
+		merged.Correlation = &correlation
+	}
+
+	if override.GitOps != nil {
+		gitops := GitOpsPolicy{}
+		if merged.GitOps != nil {
+			gitops = *merged.GitOps
+		}
+		// The overriding targets are matched first
+		gitops.Targets = append(append([]GitOpsTarget(nil), override.GitOps.Targets...), gitops.Targets...)
+		if override.GitOps.HoldSyncOnCritical != nil {
+			gitops.HoldSyncOnCritical = override.GitOps.HoldSyncOnCritical
+		}
+		merged.GitOps = &gitops
+	}
+
+	if override.RuleLimits != nil {
+		limits := make(map[string]RuleLimit, len(merged.RuleLimits)+len(override.RuleLimits))
+		for rule, limit := range merged.RuleLimits {
+			limits[rule] = limit
+		}
+		for rule, limit := range override.RuleLimits {
+			if limit.MaxComments == nil {
+				limit.MaxComments = limits[rule].MaxComments
+			}
+			if limit.Cooldown == "" {
+				limit.Cooldown = limits[rule].Cooldown
+			}
+			limits[rule] = limit
+		}
+		merged.RuleLimits = limits
+	}
+
+	if override.Languages != nil {
+		languages := make(map[string]LanguageRoute, len(merged.Languages)+len(override.Languages))
+		for language, route := range merged.Languages {
+			languages[language] = route
+		}
+		for language, route := range override.Languages {
+			if route.ModelTier == "" {
+				route.ModelTier = languages[language].ModelTier
+			}
+			if route.Prompt == "" {
+				route.Prompt = languages[language].Prompt
+			}
+			if route.Rules == nil {
+				route.Rules = languages[language].Rules
+			}
+			languages[language] = route
+		}
+		merged.Languages = languages
+	}
+
+	return merged
+}
+
+// mergeMaps returns the entries of base and override, with override's
+// values winning
+func mergeMaps[V any](base, override map[string]V) map[string]V {
+	if override == nil {
+		return base
+	}
+	merged := make(map[string]V, len(base)+len(override))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range override {
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// appendUnique appends values that are not already present in list
+func appendUnique(list []string, values ...string) []string {
+	seen := make(map[string]bool, len(list))
+	for _, v := range list {
+		seen[v] = true
+	}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			list = append(list, v)
+		}
+	}
+
+	return list
+}
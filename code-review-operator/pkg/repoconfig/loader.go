@@ -0,0 +1,207 @@
+package repoconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+)
+
+// DefaultRefreshInterval is the default interval at which org-wide configuration is re-read
+const DefaultRefreshInterval = 10 * time.Minute
+
+// OrgSource identifies an org-wide configuration and the credentials it is read
+// with. Configuration read with the credentials of one scope is never served
+// to callers of another, and owners of different providers never share it.
+type OrgSource struct {
+	// Provider is the Git provider, such as github or gitlab
+	Provider string
+
+	// Owner is the owner whose central config repository holds the configuration
+	Owner string
+
+	// Scope identifies the credentials the configuration is read with, such
+	// as the Secret key of a provider token
+	Scope string
+}
+
+// Loader reads review configuration from Git, layering the per-repository
+// file on top of the org-wide defaults kept in the central config repository
+type Loader struct {
+	interval time.Duration
+
+	mu         sync.RWMutex
+	orgConfigs map[OrgSource]orgEntry
+}
+
+// orgEntry is a cached org-wide configuration. No client is kept: expired
+// entries are read again with the client of the next caller of their source.
+type orgEntry struct {
+	config *Config
+
+	// err is set while the configuration file is invalid, in which case
+	// config is the last valid configuration read, if any
+	err *OrgConfigError
+
+	// read is when the configuration was read
+	read time.Time
+}
+
+// NewLoader creates a new configuration loader
+func NewLoader(interval time.Duration) *Loader {
+	if interval <= 0 {
+		interval = DefaultRefreshInterval
+	}
+
+	return &Loader{
+		interval:   interval,
+		orgConfigs: make(map[OrgSource]orgEntry),
+	}
+}
+
+// Load returns the effective configuration for a repository at the given ref.
+// If the repository or org-wide file is invalid, Load returns the
+// configuration without it together with a *ValidationError or an
+// *OrgConfigError so the review can proceed on defaults.
+func (l *Loader) Load(ctx context.Context, client git.Client, source OrgSource, repo, ref string) (*Config, error) {
+	orgConfig, repoConfig, err := l.LoadLayers(ctx, client, source, repo, ref)
+	var verr *ValidationError
+	var oerr *OrgConfigError
+	if err != nil && !errors.As(err, &verr) && !errors.As(err, &oerr) {
+		return nil, err
+	}
+
+	return Merge(orgConfig, repoConfig), err
+}
+
+// LoadLayers returns the org-wide and per-repository configuration separately,
+// either of which may be nil, for a repository of the source's owner. The
+// per-repository configuration is read from RepoConfigPath, or
+// AltRepoConfigPath if the repository has no such file. If the repository file
+// is invalid, the org-wide configuration is returned together with a
+// *ValidationError, and if the org-wide file is invalid, the per-repository
+// configuration together with an *OrgConfigError; both are joined when both
+// files are invalid.
+func (l *Loader) LoadLayers(ctx context.Context, client git.Client, source OrgSource, repo, ref string) (*Config, *Config, error) {
+	orgConfig, orgErr := l.OrgConfig(ctx, client, source)
+	var oerr *OrgConfigError
+	if orgErr != nil && !errors.As(orgErr, &oerr) {
+		return nil, nil, orgErr
+	}
+
+	repoConfig, err := l.fetch(ctx, client, source.Owner, repo, RepoConfigPath, ref)
+	if repoConfig == nil && err == nil {
+		repoConfig, err = l.fetch(ctx, client, source.Owner, repo, AltRepoConfigPath, ref)
+	}
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return orgConfig, nil, errors.Join(orgErr, err)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("error loading repository config: %w", err)
+	}
+
+	return orgConfig, repoConfig, orgErr
+}
+
+// OrgConfig returns the org-wide configuration of a source, reading it from
+// the central config repository with client when it was not read within the
+// refresh interval. If it cannot be read again, the last one read is served.
+// While the file is invalid, the last valid configuration read, if any, is
+// served together with an *OrgConfigError, which is cached like the
+// configuration so the file is not read again for every review.
+func (l *Loader) OrgConfig(ctx context.Context, client git.Client, source OrgSource) (*Config, error) {
+	l.mu.RLock()
+	entry, ok := l.orgConfigs[source]
+	l.mu.RUnlock()
+	if ok && time.Since(entry.read) < l.interval {
+		return entry.config, entry.orgError()
+	}
+
+	logger := log.FromContext(ctx)
+	config, err := l.fetch(ctx, client, source.Owner, OrgConfigRepo, OrgConfigPath, "")
+	var verr *ValidationError
+	switch {
+	case errors.As(err, &verr):
+		entry = orgEntry{config: entry.config, err: &OrgConfigError{Owner: source.Owner, Problems: verr.Problems}}
+		logger.Error(entry.err, "ignoring invalid org config", "provider", source.Provider, "owner", source.Owner)
+	case err != nil && !ok:
+		return nil, fmt.Errorf("error loading org config: %w", err)
+	case err != nil:
+		// Keep serving the last known configuration until the next interval
+		logger.Error(err, "unable to refresh org config", "provider", source.Provider, "owner", source.Owner)
+	default:
+		entry = orgEntry{config: config}
+	}
+	entry.read = time.Now()
+
+	l.mu.Lock()
+	l.orgConfigs[source] = entry
+	l.mu.Unlock()
+
+	return entry.config, entry.orgError()
+}
+
+// orgError returns the error of an entry as an error, nil if it has none
+func (e orgEntry) orgError() error {
+	if e.err == nil {
+		return nil
+	}
+
+	return e.err
+}
+
+// evict forgets the org-wide configuration not read again within an interval
+func (l *Loader) evict() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for source, entry := range l.orgConfigs {
+		if time.Since(entry.read) >= l.interval {
+			delete(l.orgConfigs, source)
+		}
+	}
+}
+
+// Start forgets expired org-wide configuration on a schedule until the
+// context is cancelled, so that owners no longer reviewed are not kept. It
+// implements manager.Runnable.
+func (l *Loader) Start(ctx context.Context) error {
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			l.evict()
+		}
+	}
+}
+
+// fetch reads, parses and validates a configuration file, returning nil if it does not exist
+func (l *Loader) fetch(ctx context.Context, client git.Client, owner, repo, path, ref string) (*Config, error) {
+	data, err := client.GetFileContent(ctx, owner, repo, path, ref)
+	if errors.Is(err, git.ErrResourceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := Parse(data)
+	if err != nil {
+		return nil, &ValidationError{Path: path, Problems: []string{err.Error()}}
+	}
+	if problems := config.Validate(); len(problems) > 0 {
+		return nil, &ValidationError{Path: path, Problems: problems}
+	}
+
+	return config, nil
+}
@@ -0,0 +1,187 @@
+package repoconfig
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Source identifies where an effective setting came from
+type Source string
+
+// Configuration sources, from lowest to highest precedence
+const (
+	SourceDefault Source = "default"
+	SourceOrg     Source = "org"
+	SourceRepo    Source = "repo"
+	SourceSpec    Source = "spec"
+)
+
+// Conflict describes a setting where the spec overrode a different in-repo value
+type Conflict struct {
+	// Field is the name of the conflicting setting
+	Field string
+
+	// SpecValue is the value from the spec, which was applied
+	SpecValue string
+
+	// RepoValue is the value from the in-repo configuration, which was ignored
+	RepoValue string
+}
+
+// Resolution is the effective configuration along with its provenance
+type Resolution struct {
+	// Config is the effective configuration
+	Config *Config
+
+	// Sources maps each setting to the source that supplied its value
+	Sources map[string]Source
+
+	// Conflicts lists the settings where the spec overrode the in-repo configuration
+	Conflicts []Conflict
+}
+
+// field describes how to read a single setting from a Config
+type field struct {
+	name  string
+	value func(*Config) (string, bool)
+}
+
+// fields lists the settings tracked by Resolve. Exclude, DisabledRules and
+// Glossary are omitted from conflict detection because every layer's values are combined.
+var fields = []field{
+	{"rules", func(c *Config) (string, bool) { return strings.Join(c.Rules, ","), c.Rules != nil }},
+	{"severityLevels", func(c *Config) (string, bool) {
+		return strings.Join(c.SeverityLevels, ","), c.SeverityLevels != nil
+	}},
+	{"include", func(c *Config) (string, bool) { return strings.Join(c.Include, ","), c.Include != nil }},
+	{"defaultExcludes", func(c *Config) (string, bool) {
+		if c.DefaultExcludes == nil {
+			return "", false
+		}
+		return strconv.FormatBool(*c.DefaultExcludes), true
+	}},
+	{"tone", func(c *Config) (string, bool) { return c.Tone, c.Tone != "" }},
+	{"modelTier", func(c *Config) (string, bool) { return c.ModelTier, c.ModelTier != "" }},
+	{"language", func(c *Config) (string, bool) { return c.Language, c.Language != "" }},
+	{"maxComments", func(c *Config) (string, bool) {
+		if c.MaxComments == nil {
+			return "", false
+		}
+		return strconv.Itoa(*c.MaxComments), true
+	}},
+	{"minSeverity", func(c *Config) (string, bool) { return c.MinSeverity, c.MinSeverity != "" }},
+	{"rulePacks", func(c *Config) (string, bool) { return strings.Join(c.RulePacks, ","), c.RulePacks != nil }},
+	{"gating.failOn", func(c *Config) (string, bool) {
+		if c.Gating == nil {
+			return "", false
+		}
+		return c.Gating.FailOn, c.Gating.FailOn != ""
+	}},
+	{"gating.maxFindings", func(c *Config) (string, bool) {
+		if c.Gating == nil || c.Gating.MaxFindings == nil {
+			return "", false
+		}
+		return strconv.Itoa(*c.Gating.MaxFindings), true
+	}},
+	{"escalation.pushes", func(c *Config) (string, bool) {
+		if c.Escalation == nil || c.Escalation.Pushes == nil {
+			return "", false
+		}
+		return strconv.Itoa(*c.Escalation.Pushes), true
+	}},
+	{"escalation.severity", func(c *Config) (string, bool) {
+		if c.Escalation == nil {
+			return "", false
+		}
+		return c.Escalation.Severity, c.Escalation.Severity != ""
+	}},
+	{"escalation.label", func(c *Config) (string, bool) {
+		if c.Escalation == nil {
+			return "", false
+		}
+		return c.Escalation.Label, c.Escalation.Label != ""
+	}},
+	{"escalation.notify", func(c *Config) (string, bool) {
+		if c.Escalation == nil || c.Escalation.Notify == nil {
+			return "", false
+		}
+		return strconv.FormatBool(*c.Escalation.Notify), true
+	}},
+	{"featureFlags.patterns", func(c *Config) (string, bool) {
+		if c.FeatureFlags == nil {
+			return "", false
+		}
+		return strings.Join(c.FeatureFlags.Patterns, ","), c.FeatureFlags.Patterns != nil
+	}},
+	{"featureFlags.severity", func(c *Config) (string, bool) {
+		if c.FeatureFlags == nil {
+			return "", false
+		}
+		return c.FeatureFlags.Severity, c.FeatureFlags.Severity != ""
+	}},
+	{"spelling.inline", func(c *Config) (string, bool) {
+		if c.Spelling == nil || c.Spelling.Inline == nil {
+			return "", false
+		}
+		return strconv.FormatBool(*c.Spelling.Inline), true
+	}},
+}
+
+// Resolve computes the effective configuration from every layer.
+//
+// Precedence, from lowest to highest, is: built-in defaults, the org-wide
+// config.yaml in the central repository, the repository's .ai-review.yaml
+// (or .codereview.yaml),
+// and finally the settings in the CodeReview spec. A setting from a higher
+// layer replaces the same setting from every lower layer, except exclusions
+// and glossary terms, which are combined. Any layer may be nil.
+func Resolve(defaults, org, repo, spec *Config) *Resolution {
+	layers := []struct {
+		source Source
+		config *Config
+	}{
+		{SourceDefault, defaults},
+		{SourceOrg, org},
+		{SourceRepo, repo},
+		{SourceSpec, spec},
+	}
+
+	resolution := &Resolution{
+		Config:  &Config{},
+		Sources: make(map[string]Source),
+	}
+	for _, layer := range layers {
+		if layer.config == nil {
+			continue
+		}
+		resolution.Config = Merge(resolution.Config, layer.config)
+
+		for _, f := range fields {
+			if _, ok := f.value(layer.config); ok {
+				resolution.Sources[f.name] = layer.source
+			}
+		}
+		if len(layer.config.Exclude) > 0 {
+			resolution.Sources["exclude"] = layer.source
+		}
+		if len(layer.config.Glossary) > 0 {
+			resolution.Sources["glossary"] = layer.source
+		}
+	}
+
+	if repo != nil && spec != nil {
+		for _, f := range fields {
+			specValue, specSet := f.value(spec)
+			repoValue, repoSet := f.value(repo)
+			if specSet && repoSet && specValue != repoValue {
+				resolution.Conflicts = append(resolution.Conflicts, Conflict{
+					Field:     f.name,
+					SpecValue: specValue,
+					RepoValue: repoValue,
+				})
+			}
+		}
+	}
+
+	return resolution
+}
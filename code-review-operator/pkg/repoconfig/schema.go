@@ -0,0 +1,267 @@
+package repoconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// schemaNode is a node of the configuration's JSON schema. Only the keywords
+// the schema uses are supported: type, enum, $ref to definitions, properties,
+// required, additionalProperties, propertyNames, items, minimum, maximum,
+// minLength and pattern.
+type schemaNode struct {
+	Ref                  string                 `json:"$ref"`
+	Type                 string                 `json:"type"`
+	Enum                 []any                  `json:"enum"`
+	Properties           map[string]*schemaNode `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+	PropertyNames        *schemaNode            `json:"propertyNames"`
+	Items                *schemaNode            `json:"items"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	MinLength            *int                   `json:"minLength"`
+	Pattern              string                 `json:"pattern"`
+	Definitions          map[string]*schemaNode `json:"definitions"`
+
+	// additional is AdditionalProperties when it is a schema
+	additional *schemaNode
+
+	// closed is set when AdditionalProperties is false
+	closed bool
+
+	// pattern is Pattern compiled
+	pattern *regexp.Regexp
+}
+
+// configSchema is the parsed schema the configuration is validated against
+var configSchema = mustParseSchema(schema)
+
+// mustParseSchema parses a JSON schema, panicking if it is invalid, as the
+// schema is embedded at build time
+func mustParseSchema(data []byte) *schemaNode {
+	var root schemaNode
+	if err := json.Unmarshal(data, &root); err != nil {
+		panic(fmt.Sprintf("invalid config schema: %v", err))
+	}
+	if err := root.compile(); err != nil {
+		panic(fmt.Sprintf("invalid config schema: %v", err))
+	}
+
+	return &root
+}
+
+// compile prepares a node and its children for validation
+func (n *schemaNode) compile() error {
+	switch raw := strings.TrimSpace(string(n.AdditionalProperties)); raw {
+	case "", "true":
+	case "false":
+		n.closed = true
+	default:
+		n.additional = &schemaNode{}
+		if err := json.Unmarshal(n.AdditionalProperties, n.additional); err != nil {
+			return err
+		}
+	}
+	if n.Pattern != "" {
+		pattern, err := regexp.Compile(n.Pattern)
+		if err != nil {
+			return err
+		}
+		n.pattern = pattern
+	}
+
+	children := []*schemaNode{n.additional, n.PropertyNames, n.Items}
+	for _, child := range n.Properties {
+		children = append(children, child)
+	}
+	for _, child := range n.Definitions {
+		children = append(children, child)
+	}
+	for _, child := range children {
+		if child == nil {
+			continue
+		}
+		if err := child.compile(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateSchema checks the configuration against the schema, reporting
+// problems as "field: message"
+func (c *Config) validateSchema() []string {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return []string{fmt.Sprintf("config: %v", err)}
+	}
+	var document any
+	if err := json.Unmarshal(data, &document); err != nil {
+		return []string{fmt.Sprintf("config: %v", err)}
+	}
+
+	return configSchema.validate(configSchema, document, "")
+}
+
+// validate checks value against the node, reporting problems under field
+func (n *schemaNode) validate(root *schemaNode, value any, field string) []string {
+	if name, ok := strings.CutPrefix(n.Ref, "#/definitions/"); ok {
+		definition, ok := root.Definitions[name]
+		if !ok {
+			return []string{fmt.Sprintf("%s: unknown schema definition %q", fieldName(field), name)}
+		}
+		return definition.validate(root, value, field)
+	}
+
+	if n.Type != "" && !hasType(value, n.Type) {
+		return []string{fmt.Sprintf("%s: must be %s", fieldName(field), article(n.Type))}
+	}
+	if len(n.Enum) > 0 && !inEnum(n.Enum, value) {
+		allowed := make([]string, 0, len(n.Enum))
+		for _, v := range n.Enum {
+			allowed = append(allowed, fmt.Sprint(v))
+		}
+		return []string{fmt.Sprintf("%s: unknown value %s (allowed: %s)", fieldName(field), display(value), strings.Join(allowed, ", "))}
+	}
+
+	var problems []string
+	switch value := value.(type) {
+	case string:
+		if n.MinLength != nil && utf8.RuneCountInString(value) < *n.MinLength {
+			if *n.MinLength == 1 {
+				problems = append(problems, fieldName(field)+": must not be empty")
+			} else {
+				problems = append(problems, fmt.Sprintf("%s: must be at least %d characters", fieldName(field), *n.MinLength))
+			}
+		}
+		if n.pattern != nil && !n.pattern.MatchString(value) {
+			problems = append(problems, fmt.Sprintf("%s: invalid value %q", fieldName(field), value))
+		}
+	case float64:
+		switch {
+		case n.Minimum != nil && n.Maximum != nil && (value < *n.Minimum || value > *n.Maximum):
+			problems = append(problems, fmt.Sprintf("%s: must be between %v and %v", fieldName(field), *n.Minimum, *n.Maximum))
+		case n.Minimum != nil && value < *n.Minimum:
+			problems = append(problems, fmt.Sprintf("%s: must be at least %v", fieldName(field), *n.Minimum))
+		case n.Maximum != nil && value > *n.Maximum:
+			problems = append(problems, fmt.Sprintf("%s: must be at most %v", fieldName(field), *n.Maximum))
+		}
+	case []any:
+		if n.Items != nil {
+			for i, item := range value {
+				problems = append(problems, n.Items.validate(root, item, fmt.Sprintf("%s[%d]", field, i))...)
+			}
+		}
+	case map[string]any:
+		for _, name := range n.Required {
+			if _, ok := value[name]; !ok {
+				problems = append(problems, joinField(field, name)+": is required")
+			}
+		}
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if n.PropertyNames != nil {
+				problems = append(problems, n.PropertyNames.validate(root, key, fmt.Sprintf("%s[%s]", field, key))...)
+			}
+			if property, ok := n.Properties[key]; ok {
+				problems = append(problems, property.validate(root, value[key], joinField(field, key))...)
+				continue
+			}
+			switch {
+			case n.closed:
+				problems = append(problems, fmt.Sprintf("%s: unknown field", joinField(field, key)))
+			case n.additional != nil:
+				problems = append(problems, n.additional.validate(root, value[key], fmt.Sprintf("%s[%s]", field, key))...)
+			}
+		}
+	}
+
+	return problems
+}
+
+// hasType reports whether a decoded JSON value is of a JSON schema type
+func hasType(value any, schemaType string) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	case "null":
+		return value == nil
+	}
+
+	return false
+}
+
+// inEnum reports whether a decoded JSON value is one of the values of an enum
+func inEnum(enum []any, value any) bool {
+	for _, v := range enum {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// display formats a decoded JSON value for a problem
+func display(value any) string {
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// article returns a JSON schema type with its indefinite article
+func article(schemaType string) string {
+	switch schemaType {
+	case "object", "array", "integer":
+		return "an " + schemaType
+	}
+
+	return "a " + schemaType
+}
+
+// joinField returns the field of a property of an object field
+func joinField(field, name string) string {
+	if field == "" {
+		return name
+	}
+
+	return field + "." + name
+}
+
+// fieldName returns the name a field is reported under
+func fieldName(field string) string {
+	if field == "" {
+		return "config"
+	}
+
+	return field
+}
@@ -0,0 +1,226 @@
+package repoconfig
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm/prompt"
+)
+
+// ValidationCheckName is the name of the check run used to report configuration errors
+const ValidationCheckName = "ai-review/config"
+
+// schema is the JSON schema published for the configuration file
+//
+//go:embed schema.json
+var schema []byte
+
+// Schema returns the JSON schema for the configuration file
+func Schema() []byte {
+	return schema
+}
+
+// ValidationError lists the problems found in a configuration file
+type ValidationError struct {
+	// Path is the path of the invalid file
+	Path string
+
+	// Problems is the list of problems found
+	Problems []string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid config %s: %s", e.Path, strings.Join(e.Problems, "; "))
+}
+
+// OrgConfigError reports an invalid org-wide configuration file. It is not
+// the fault of the repositories reviewed, so reviews proceed without it, or
+// with the last valid org-wide configuration read.
+type OrgConfigError struct {
+	// Owner is the owner of the central config repository
+	Owner string
+
+	// Problems is the list of problems found
+	Problems []string
+}
+
+// Error implements the error interface
+func (e *OrgConfigError) Error() string {
+	return fmt.Sprintf("invalid org config %s/%s/%s: %s", e.Owner, OrgConfigRepo, OrgConfigPath, strings.Join(e.Problems, "; "))
+}
+
+// Validate checks the configuration against the embedded JSON schema, then
+// makes the checks the schema cannot express, such as that globs and regular
+// expressions compile
+func (c *Config) Validate() []string {
+	problems := c.validateSchema()
+
+	for i, pattern := range c.Include {
+		if _, err := path.Match(pattern, ""); err != nil {
+			problems = append(problems, fmt.Sprintf("include[%d]: invalid glob %q", i, pattern))
+		}
+	}
+
+	for i, pattern := range c.Exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			problems = append(problems, fmt.Sprintf("exclude[%d]: invalid glob %q", i, pattern))
+		}
+	}
+
+	for i, rule := range c.DisabledRules {
+		if _, err := path.Match(rule, ""); err != nil {
+			problems = append(problems, fmt.Sprintf("disabledRules[%d]: invalid glob %q", i, rule))
+		}
+	}
+
+	for i, sensitive := range c.BranchSensitivity {
+		problems = append(problems, validateSensitiveBranch(fmt.Sprintf("branchSensitivity[%d]", i), sensitive)...)
+	}
+
+	if c.FeatureFlags != nil {
+		for i, pattern := range c.FeatureFlags.Patterns {
+			re, err := regexp.Compile(pattern)
+			switch {
+			case err != nil:
+				problems = append(problems, fmt.Sprintf("featureFlags.patterns[%d]: invalid regular expression: %v", i, err))
+			case re.NumSubexp() < 1:
+				problems = append(problems, fmt.Sprintf("featureFlags.patterns[%d]: must capture the flag key in a group", i))
+			}
+		}
+	}
+
+	if c.Redaction != nil {
+		for name, pattern := range c.Redaction.Patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				problems = append(problems, fmt.Sprintf("redaction.patterns[%s]: invalid regular expression: %v", name, err))
+			}
+		}
+		for i, pattern := range c.Redaction.Confidential {
+			if _, err := path.Match(pattern, ""); err != nil {
+				problems = append(problems, fmt.Sprintf("redaction.confidential[%d]: invalid glob %q", i, pattern))
+			}
+		}
+	}
+
+	if c.GitOps != nil {
+		for i, target := range c.GitOps.Targets {
+			problems = append(problems, validateGitOpsTarget(fmt.Sprintf("gitops.targets[%d]", i), target)...)
+		}
+	}
+
+	if c.Secrets != nil {
+		for i, pattern := range c.Secrets.Ignore {
+			if _, err := path.Match(pattern, ""); err != nil {
+				problems = append(problems, fmt.Sprintf("secrets.ignore[%d]: invalid glob %q", i, pattern))
+			}
+		}
+	}
+
+	for rule, limit := range c.RuleLimits {
+		if _, err := path.Match(rule, ""); err != nil {
+			problems = append(problems, fmt.Sprintf("ruleLimits[%s]: invalid glob", rule))
+		}
+		if limit.Cooldown != "" {
+			// The schema checks the format
+			if cooldown, err := ParseCooldown(limit.Cooldown); err == nil && cooldown <= 0 {
+				problems = append(problems, fmt.Sprintf("ruleLimits[%s].cooldown: must be positive", rule))
+			}
+		}
+	}
+
+	for language := range c.Languages {
+		if !contains(prompt.KnownLanguages, prompt.Normalize(language)) {
+			problems = append(problems, fmt.Sprintf("languages: unknown language %q (allowed: %s)",
+				language, strings.Join(prompt.KnownLanguages, ", ")))
+		}
+	}
+
+	return problems
+}
+
+// ReportValidationError creates a failing check run describing an invalid
+// configuration file so the problems are visible on the pull request
+func ReportValidationError(ctx context.Context, client git.Client, owner, repo, headSHA string, verr *ValidationError) (string, error) {
+	var text strings.Builder
+	for _, problem := range verr.Problems {
+		fmt.Fprintf(&text, "- %s\n", problem)
+	}
+
+	return client.CreateCheckRun(ctx, owner, repo, git.CheckRun{
+		Name:       ValidationCheckName,
+		HeadSHA:    headSHA,
+		Conclusion: git.CheckConclusionFailure,
+		Title:      fmt.Sprintf("Invalid %s", verr.Path),
+		Summary: fmt.Sprintf("Found %d problem(s) in `%s`. The review ran with the org-wide defaults instead.",
+			len(verr.Problems), verr.Path),
+		Text: text.String(),
+	})
+}
+
+// validateSensitiveBranch checks a sensitive branch, reporting problems under field
+func validateSensitiveBranch(field string, sensitive SensitiveBranch) []string {
+	var problems []string
+	if _, err := path.Match(sensitive.Pattern, ""); err != nil {
+		problems = append(problems, fmt.Sprintf("%s.pattern: invalid glob %q", field, sensitive.Pattern))
+	}
+	for i, window := range sensitive.Windows {
+		windowField := fmt.Sprintf("%s.windows[%d]", field, i)
+		start, err := parseWindowTime(window.Start, false)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s.start: %v", windowField, err))
+		}
+		end, endErr := parseWindowTime(window.End, true)
+		if endErr != nil {
+			problems = append(problems, fmt.Sprintf("%s.end: %v", windowField, endErr))
+		}
+		if err == nil && endErr == nil && !end.After(start) {
+			problems = append(problems, windowField+": must end after it starts")
+		}
+	}
+
+	return problems
+}
+
+// validateGitOpsTarget checks a GitOps target, reporting problems under field
+func validateGitOpsTarget(field string, target GitOpsTarget) []string {
+	var problems []string
+	if target.Path != "" && strings.Trim(target.Path, "/") == "" {
+		problems = append(problems, field+".path: must name a directory")
+	}
+	if target.Project != "" && target.Kind == GitOpsKustomization {
+		problems = append(problems, field+".project: only Applications have a project")
+	}
+
+	placeholders := map[string]bool{}
+	for _, part := range strings.Split(strings.Trim(target.Path, "/"), "/") {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			placeholders[part] = true
+		}
+	}
+	name := target.Name
+	for placeholder := range placeholders {
+		name = strings.ReplaceAll(name, placeholder, "x")
+	}
+	if strings.ContainsAny(name, "{}") {
+		problems = append(problems, fmt.Sprintf("%s.name: uses a placeholder %q does not define", field, target.Path))
+	}
+
+	return problems
+}
+
+// contains reports whether list contains value
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
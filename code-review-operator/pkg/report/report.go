@@ -0,0 +1,212 @@
+// Package report exports the findings of a review in formats other tools
+// consume: a versioned JSON document and SARIF 2.1.0 logs, which GitHub Code
+// Scanning and most static analysis dashboards accept.
+package report
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+	"github.com/Shridhar2104/code-review-operator/pkg/provenance"
+)
+
+// SchemaVersion is the version of the JSON report schema. It changes only
+// when fields are removed or change meaning; new fields may be added.
+const SchemaVersion = "1"
+
+// ToolName is the name reports give the tool that made the findings
+const ToolName = "code-review-operator"
+
+// Format is a report format
+type Format string
+
+// Report formats
+const (
+	// FormatJSON is the versioned JSON report described by Schema
+	FormatJSON Format = "json"
+
+	// FormatSARIF is a SARIF 2.1.0 log
+	FormatSARIF Format = "sarif"
+)
+
+// Formats lists the supported report formats
+var Formats = []Format{FormatJSON, FormatSARIF}
+
+// schema is the JSON schema of JSON reports
+//
+//go:embed schema.json
+var schema []byte
+
+// Schema returns the JSON schema of JSON reports
+func Schema() []byte {
+	return schema
+}
+
+// Report holds the findings of a review
+type Report struct {
+	// SchemaVersion is the version of the report schema
+	SchemaVersion string `json:"schemaVersion"`
+
+	// Tool is the name of the tool that made the findings
+	Tool string `json:"tool"`
+
+	// Repository is the full name (owner/name) of the reviewed repository
+	Repository string `json:"repository"`
+
+	// PullRequest is the reviewed pull request number, 0 for other diffs
+	PullRequest int `json:"pullRequest,omitempty"`
+
+	// CommitSHA is the reviewed commit, if known
+	CommitSHA string `json:"commitSHA,omitempty"`
+
+	// Summary is the review summary
+	Summary string `json:"summary"`
+
+	// Decision is the event the review was posted with
+	Decision string `json:"decision,omitempty"`
+
+	// TokensUsed is the number of LLM tokens the review used
+	TokensUsed int `json:"tokensUsed"`
+
+	// Provenance records what produced the posted review
+	Provenance *provenance.Statement `json:"provenance,omitempty"`
+
+	// Findings are the findings of the review
+	Findings []Finding `json:"findings"`
+}
+
+// Finding is a single finding of a review
+type Finding struct {
+	// File is the path of the file the finding is on
+	File string `json:"file"`
+
+	// Line is the line of the finding, or the last line of a range
+	Line int `json:"line"`
+
+	// StartLine is the first line of a finding spanning several lines
+	StartLine int `json:"startLine,omitempty"`
+
+	// Side is LEFT for findings on removed lines, whose line numbers refer to
+	// the old version of the file, and RIGHT otherwise
+	Side string `json:"side"`
+
+	// Severity is critical, major, minor or suggestion
+	Severity string `json:"severity"`
+
+	// ReportedSeverity is the severity the finding was reported with, when
+	// Severity was raised because of the branch the pull request targets
+	ReportedSeverity string `json:"reportedSeverity,omitempty"`
+
+	// Escalation explains why the severity was raised
+	Escalation string `json:"escalation,omitempty"`
+
+	// Rule is the rule the finding was made for
+	Rule string `json:"rule,omitempty"`
+
+	// Language is the language of the file, such as go or terraform
+	Language string `json:"language,omitempty"`
+
+	// Message explains the finding
+	Message string `json:"message"`
+
+	// SuggestedCode is replacement code for the lines of the finding
+	SuggestedCode string `json:"suggestedCode,omitempty"`
+
+	// Link is a permalink to the lines in the pull request's diff
+	Link string `json:"link,omitempty"`
+}
+
+// New creates an empty report of a review
+func New(repository string, pullRequest int, commitSHA string) *Report {
+	return &Report{
+		SchemaVersion: SchemaVersion,
+		Tool:          ToolName,
+		Repository:    repository,
+		PullRequest:   pullRequest,
+		CommitSHA:     commitSHA,
+		Findings:      []Finding{},
+	}
+}
+
+// FromResult creates a report of the raw result of an LLM review
+func FromResult(repository string, pullRequest int, commitSHA string, result *llm.ReviewResult) *Report {
+	r := New(repository, pullRequest, commitSHA)
+	if result == nil {
+		return r
+	}
+	r.Summary = result.Summary
+	r.TokensUsed = result.TokensUsed
+	for _, comment := range result.Comments {
+		r.AddComments(git.ReviewComment{
+			File:          comment.File,
+			Line:          comment.Line,
+			StartLine:     comment.StartLine,
+			Content:       comment.Content,
+			Severity:      comment.Severity,
+			Rule:          comment.Rule,
+			SuggestedCode: comment.SuggestedCode,
+		})
+	}
+
+	return r
+}
+
+// AddComments adds review comments to the findings of a report
+func (r *Report) AddComments(comments ...git.ReviewComment) {
+	for _, comment := range comments {
+		side := comment.Side
+		if side == "" {
+			side = "RIGHT"
+		}
+		r.Findings = append(r.Findings, Finding{
+			File:          comment.File,
+			Line:          comment.Line,
+			StartLine:     comment.StartLine,
+			Side:          side,
+			Severity:      comment.Severity,
+			Rule:          comment.Rule,
+			Language:      comment.Language,
+			Message:       comment.Content,
+			SuggestedCode: comment.SuggestedCode,
+			Link:          comment.Link,
+
+			ReportedSeverity: comment.ReportedSeverity,
+			Escalation:       comment.Escalation,
+		})
+	}
+}
+
+// Marshal encodes a report in the given format
+func (r *Report) Marshal(format Format) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := r.Write(&buf, format); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Write writes a report in the given format
+func (r *Report) Write(w io.Writer, format Format) error {
+	var v interface{}
+	switch format {
+	case FormatJSON:
+		v = r
+	case FormatSARIF:
+		v = r.sarif()
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+
+	// Messages are Markdown, which HTML escaping would make unreadable
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
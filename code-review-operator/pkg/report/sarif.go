@@ -0,0 +1,217 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/policy"
+)
+
+// sarifSchema is the schema of SARIF 2.1.0 logs
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// informationURI is the home page reports link the tool to
+const informationURI = "https://github.com/Shridhar2104/code-review-operator"
+
+// sarifLevels maps severities to SARIF result levels
+var sarifLevels = map[string]string{
+	policy.SeverityCritical:   "error",
+	policy.SeverityMajor:      "error",
+	policy.SeverityMinor:      "warning",
+	policy.SeveritySuggestion: "note",
+}
+
+// sarifLog is a SARIF 2.1.0 log, with the subset of properties reports use
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool              sarifTool               `json:"tool"`
+	Results           []sarifResult           `json:"results"`
+	AutomationDetails *sarifAutomationDetails `json:"automationDetails,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	DefaultConfiguration *sarifConfiguration `json:"defaultConfiguration,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+	Fixes     []sarifFix      `json:"fixes,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine,omitempty"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion           `json:"deletedRegion"`
+	InsertedContent *sarifArtifactContent `json:"insertedContent,omitempty"`
+}
+
+type sarifArtifactContent struct {
+	Text string `json:"text"`
+}
+
+type sarifAutomationDetails struct {
+	ID string `json:"id"`
+}
+
+// srcRoot is the base ID file locations are relative to
+const srcRoot = "SRCROOT"
+
+// sarif converts a report to a SARIF log. Findings on removed lines are
+// located on their file only, since their lines are not in the reviewed
+// version; rules without a level of their own default to their most severe
+// finding's.
+func (r *Report) sarif() *sarifLog {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           r.Tool,
+			InformationURI: informationURI,
+			Rules:          []sarifRule{},
+		}},
+		Results: make([]sarifResult, 0, len(r.Findings)),
+	}
+	if r.PullRequest > 0 {
+		// Lets code scanning tell the results of pull requests apart
+		run.AutomationDetails = &sarifAutomationDetails{ID: fmt.Sprintf("%s/%s/pr/%d/", r.Tool, r.Repository, r.PullRequest)}
+	}
+
+	rules := make(map[string]string)
+	for _, finding := range r.Findings {
+		level, ok := sarifLevels[finding.Severity]
+		if !ok {
+			level = "note"
+		}
+		rule := ruleID(finding)
+		if current, ok := rules[rule]; !ok || levelRank(level) > levelRank(current) {
+			rules[rule] = level
+		}
+
+		location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: finding.File, URIBaseID: srcRoot}}
+		if finding.Side != "LEFT" && finding.Line > 0 {
+			location.Region = region(finding)
+		}
+		result := sarifResult{
+			RuleID:    rule,
+			Level:     level,
+			Message:   sarifMessage{Text: finding.Message},
+			Locations: []sarifLocation{{PhysicalLocation: location}},
+		}
+		if finding.SuggestedCode != "" && location.Region != nil {
+			result.Fixes = []sarifFix{{
+				Description: sarifMessage{Text: "Suggested change"},
+				ArtifactChanges: []sarifArtifactChange{{
+					ArtifactLocation: location.ArtifactLocation,
+					Replacements: []sarifReplacement{{
+						DeletedRegion:   *location.Region,
+						InsertedContent: &sarifArtifactContent{Text: finding.SuggestedCode},
+					}},
+				}},
+			}}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	ids := make([]string, 0, len(rules))
+	for id := range rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	index := make(map[string]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:                   id,
+			DefaultConfiguration: &sarifConfiguration{Level: rules[id]},
+		})
+	}
+	for i := range run.Results {
+		run.Results[i].RuleIndex = index[run.Results[i].RuleID]
+	}
+
+	return &sarifLog{Schema: sarifSchema, Version: "2.1.0", Runs: []sarifRun{run}}
+}
+
+// ruleID returns the SARIF rule ID of a finding; findings without a rule are
+// grouped under their severity
+func ruleID(finding Finding) string {
+	if finding.Rule != "" {
+		return finding.Rule
+	}
+
+	return "review/" + finding.Severity
+}
+
+// levelRank orders SARIF levels, higher is more severe
+func levelRank(level string) int {
+	switch level {
+	case "error":
+		return 3
+	case "warning":
+		return 2
+	}
+
+	return 1
+}
+
+// region returns the SARIF region of the lines of a finding
+func region(finding Finding) *sarifRegion {
+	if finding.StartLine > 0 && finding.StartLine < finding.Line {
+		return &sarifRegion{StartLine: finding.StartLine, EndLine: finding.Line}
+	}
+
+	return &sarifRegion{StartLine: finding.Line}
+}
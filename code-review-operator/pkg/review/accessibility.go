@@ -0,0 +1,306 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/repoconfig"
+)
+
+// Rules of the accessibility rule pack
+const (
+	// RuleMissingAlt flags images without a text alternative
+	RuleMissingAlt = "a11y/missing-alt"
+
+	// RuleUnlabeledInput flags form controls without an accessible name
+	RuleUnlabeledInput = "a11y/unlabeled-input"
+
+	// RuleInvalidARIA flags unknown ARIA attributes and roles
+	RuleInvalidARIA = "a11y/invalid-aria"
+)
+
+// accessibilityRules are the rules of the accessibility rule pack, as sent to the LLM
+var accessibilityRules = []string{
+	RuleMissingAlt + ": every img, area and image input needs an alt text; use alt=\"\" for decorative images",
+	RuleUnlabeledInput + ": every input, select and textarea needs a label, aria-label or aria-labelledby",
+	RuleInvalidARIA + ": use only valid ARIA attributes and roles, never aria-hidden on focusable elements, " +
+		"and prefer native elements to roles",
+	"a11y: interactive elements must be reachable and operable with the keyboard, and click handlers on " +
+		"non-interactive elements need a role, tabindex and key handler",
+}
+
+// markupExtensions are the extensions of files holding HTML or JSX markup
+var markupExtensions = []string{".html", ".htm", ".jsx", ".tsx", ".vue", ".svelte"}
+
+// startTag matches the start of an HTML start tag
+var startTag = regexp.MustCompile(`<([a-z][a-z0-9-]*)\b`)
+
+// ariaAttributes are the WAI-ARIA 1.2 states and properties, without the aria- prefix
+var ariaAttributes = map[string]bool{
+	"activedescendant": true, "atomic": true, "autocomplete": true, "braillelabel": true,
+	"brailleroledescription": true, "busy": true, "checked": true, "colcount": true, "colindex": true,
+	"colindextext": true, "colspan": true, "controls": true, "current": true, "describedby": true,
+	"description": true, "details": true, "disabled": true, "dropeffect": true, "errormessage": true,
+	"expanded": true, "flowto": true, "grabbed": true, "haspopup": true, "hidden": true, "invalid": true,
+	"keyshortcuts": true, "label": true, "labelledby": true, "level": true, "live": true, "modal": true,
+	"multiline": true, "multiselectable": true, "orientation": true, "owns": true, "placeholder": true,
+	"posinset": true, "pressed": true, "readonly": true, "relevant": true, "required": true,
+	"roledescription": true, "rowcount": true, "rowindex": true, "rowindextext": true, "rowspan": true,
+	"selected": true, "setsize": true, "sort": true, "valuemax": true, "valuemin": true, "valuenow": true,
+	"valuetext": true,
+}
+
+// ariaRoles are the WAI-ARIA 1.2 roles
+var ariaRoles = map[string]bool{}
+
+func init() {
+	for _, role := range strings.Fields(`alert alertdialog application article banner blockquote button caption
+		cell checkbox code columnheader combobox complementary contentinfo definition deletion dialog directory
+		document emphasis feed figure form generic grid gridcell group heading img insertion link list listbox
+		listitem log main mark marquee math menu menubar menuitem menuitemcheckbox menuitemradio meter
+		navigation none note option paragraph presentation progressbar radio radiogroup region row rowgroup
+		rowheader scrollbar search searchbox separator slider spinbutton status strong subscript superscript
+		switch tab table tablist tabpanel term textbox time timer toolbar tooltip tree treegrid treeitem`) {
+		ariaRoles[role] = true
+	}
+}
+
+// unlabeledInputTypes are the input types that need no label
+var unlabeledInputTypes = []string{"hidden", "submit", "reset", "button", "image"}
+
+// tag is an HTML or JSX start tag in the new version of a file
+type tag struct {
+	// Line is the line the tag starts on
+	Line int
+
+	// Name is the tag name
+	Name string
+
+	// Attributes maps attribute names to their literal values; values set
+	// by expressions are empty
+	Attributes map[string]string
+}
+
+// attribute matches an attribute and its literal value, if any
+var attribute = regexp.MustCompile(`([A-Za-z_:][\w:.-]*)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|\{))?`)
+
+// CheckAccessibility is an enrich handler running the accessibility rule pack
+// when the repository's configuration enables it. For changed HTML, JSX, Vue
+// and Svelte files, it adds accessibility rules to the review and checks the
+// tags starting on added lines for images without alt text, form controls
+// without a label and unknown ARIA attributes and roles. The LLM is asked to
+// explain each finding; MergeChecks posts them.
+func CheckAccessibility(ctx context.Context, job *Job) error {
+	if job.Config == nil || !slices.Contains(job.Config.RulePacks, repoconfig.RulePackAccessibility) {
+		return nil
+	}
+	files, ok := job.parsedFiles(ctx)
+	if !ok {
+		return nil
+	}
+
+	reviewed := false
+	var checks []git.ReviewComment
+	for _, file := range files {
+		if file.Binary || file.Status == diff.FileDeleted || !slices.Contains(markupExtensions, path.Ext(file.NewPath)) {
+			continue
+		}
+		added := addedLines(file)
+		if len(added) == 0 {
+			continue
+		}
+		reviewed = true
+		for _, hunk := range file.Hunks {
+			for _, t := range parseTags(hunk) {
+				if _, ok := added[t.Line]; ok {
+					checks = append(checks, checkTag(file.NewPath, t, hunk)...)
+				}
+			}
+		}
+	}
+	if reviewed {
+		addChecks(job, accessibilityRules, checks)
+	}
+
+	return nil
+}
+
+// parseTags returns the start tags in the new version of a hunk's lines.
+// Tags may span lines; quoted values and JSX expressions may contain '>'.
+func parseTags(hunk *diff.Hunk) []tag {
+	var text strings.Builder
+	var starts, numbers []int
+	for _, line := range hunk.Lines {
+		if line.Kind == diff.LineRemoved {
+			continue
+		}
+		starts = append(starts, text.Len())
+		numbers = append(numbers, line.NewNumber)
+		text.WriteString(line.Content)
+		text.WriteString("\n")
+	}
+	content := text.String()
+
+	var tags []tag
+	for _, match := range startTag.FindAllStringSubmatchIndex(content, -1) {
+		end := tagEnd(content, match[1])
+		if end < 0 {
+			continue
+		}
+		line := numbers[lineIndex(starts, match[0])]
+		tags = append(tags, tag{
+			Line:       line,
+			Name:       content[match[2]:match[3]],
+			Attributes: parseAttributes(content[match[1]:end]),
+		})
+	}
+
+	return tags
+}
+
+// lineIndex returns the index of the line holding an offset, given the
+// offsets lines start at
+func lineIndex(starts []int, offset int) int {
+	i := 0
+	for i+1 < len(starts) && starts[i+1] <= offset {
+		i++
+	}
+
+	return i
+}
+
+// tagEnd returns the offset of the '>' closing a tag, or -1 if the tag does
+// not end in the text
+func tagEnd(content string, from int) int {
+	depth := 0
+	var quote byte
+	for i := from; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+		case c == '>' && depth == 0:
+			return i
+		case c == '<' && depth == 0:
+			return -1
+		}
+	}
+
+	return -1
+}
+
+// parseAttributes returns the attributes of a tag, skipping JSX expressions
+func parseAttributes(text string) map[string]string {
+	attributes := make(map[string]string)
+	for i := 0; i < len(text); {
+		match := attribute.FindStringSubmatchIndex(text[i:])
+		if match == nil {
+			break
+		}
+		name := strings.ToLower(text[i+match[2] : i+match[3]])
+		value := ""
+		switch {
+		case match[4] >= 0:
+			value = text[i+match[4] : i+match[5]]
+		case match[6] >= 0:
+			value = text[i+match[6] : i+match[7]]
+		}
+		attributes[name] = value
+		i += match[1]
+		if strings.HasSuffix(text[:i], "{") {
+			// Skip the expression
+			depth := 1
+			for ; i < len(text) && depth > 0; i++ {
+				switch text[i] {
+				case '{':
+					depth++
+				case '}':
+					depth--
+				}
+			}
+		}
+	}
+
+	return attributes
+}
+
+// checkTag checks a tag starting on an added line
+func checkTag(filePath string, t tag, hunk *diff.Hunk) []git.ReviewComment {
+	var comments []git.ReviewComment
+	add := func(severity, rule, format string, args ...interface{}) {
+		comments = append(comments, git.ReviewComment{
+			File:     filePath,
+			Line:     t.Line,
+			Severity: severity,
+			Rule:     rule,
+			Content:  fmt.Sprintf(format, args...),
+		})
+	}
+	_, hasAlt := t.Attributes["alt"]
+	inputType := strings.ToLower(t.Attributes["type"])
+
+	switch {
+	case (t.Name == "img" || t.Name == "area" || t.Name == "input" && inputType == "image") && !hasAlt:
+		add("major", RuleMissingAlt, "`<%s>` has no `alt` text, so screen readers cannot describe it. "+
+			"Describe what it shows, or use `alt=\"\"` if it is decorative.", t.Name)
+	case (t.Name == "input" && !slices.Contains(unlabeledInputTypes, inputType) || t.Name == "select" || t.Name == "textarea") &&
+		!labeled(t, hunk):
+		add("major", RuleUnlabeledInput, "`<%s>` has no label, so screen readers announce it without a name. "+
+			"Associate a `<label>` with it or set `aria-label` or `aria-labelledby`.", t.Name)
+	}
+
+	names := make([]string, 0, len(t.Attributes))
+	for name := range t.Attributes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if strings.HasPrefix(name, "aria-") && !ariaAttributes[strings.TrimPrefix(name, "aria-")] {
+			add("minor", RuleInvalidARIA, "`%s` is not an ARIA attribute, so assistive technology ignores it.", name)
+		}
+	}
+	if role, ok := t.Attributes["role"]; ok && role != "" {
+		for _, value := range strings.Fields(role) {
+			if !ariaRoles[value] {
+				add("minor", RuleInvalidARIA, "`%s` is not an ARIA role, so assistive technology ignores it.", value)
+			}
+		}
+	}
+
+	return comments
+}
+
+// labeled reports whether a form control may have an accessible name: an ARIA
+// label, a title, an id a label may refer to, or a label element opened on its
+// line or the two lines above.
+func labeled(t tag, hunk *diff.Hunk) bool {
+	for _, name := range []string{"aria-label", "aria-labelledby", "title", "id"} {
+		if _, ok := t.Attributes[name]; ok {
+			return true
+		}
+	}
+
+	// A label element opened on the control's line or just above it
+	for _, line := range hunk.Lines {
+		if line.Kind != diff.LineRemoved && line.NewNumber >= t.Line-2 && line.NewNumber <= t.Line &&
+			strings.Contains(line.Content, "<label") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,56 @@
+package review
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/analysis"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+)
+
+// ReviewWithAnalyzers wraps a review stage handler so the deterministic
+// analyzers run on the diff while it reviews. Analyzers disabled in
+// job.Options.Analyzers are skipped. Their findings are added to job.Checks
+// once both are done, and MergeChecks posts them unless the LLM commented on
+// the same line. Analyzer failures are logged and do not fail the review.
+func ReviewWithAnalyzers(analyzers []analysis.Analyzer, next Handler) Handler {
+	return func(ctx context.Context, job *Job) error {
+		files, ok := job.parsedFiles(ctx)
+		if !ok {
+			return next(ctx, job)
+		}
+
+		input := &analysis.Input{Files: files, Dir: job.Dir}
+		if job.Client != nil {
+			client, owner, repo, sha := job.Client, job.Owner, job.Repository, job.CommitSHA
+			input.Content = func(ctx context.Context, path string) ([]byte, error) {
+				return client.GetFileContent(ctx, owner, repo, path, sha)
+			}
+		}
+
+		type outcome struct {
+			findings []git.ReviewComment
+			err      error
+		}
+		enabled := job.Options.Analyzers
+		done := make(chan outcome, 1)
+		go func() {
+			findings, err := analysis.Run(ctx, analyzers, enabled, input)
+			done <- outcome{findings, err}
+		}()
+
+		reviewErr := next(ctx, job)
+
+		result := <-done
+		if result.err != nil {
+			log.FromContext(ctx).Error(result.err, "analyzer failed")
+		}
+		job.Checks = append(job.Checks, result.findings...)
+		if len(result.findings) > 0 {
+			log.FromContext(ctx).Info("analyzers reported findings", "findings", len(result.findings))
+		}
+
+		return reviewErr
+	}
+}
@@ -0,0 +1,193 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+)
+
+// MaxAnchorDistance is how far, in lines, a comment is moved to reach a changed line
+const MaxAnchorDistance = 10
+
+// Adjustment records a comment that was moved or dropped because its line
+// was not part of the diff
+type Adjustment struct {
+	// Comment is the comment as returned by the LLM
+	Comment git.ReviewComment
+
+	// Line is the line the comment was moved to, or 0 if it was dropped
+	Line int
+
+	// Reason explains the adjustment
+	Reason string
+}
+
+// AnchorComments is a postprocess handler that checks every comment against
+// the diff. Providers reject a whole review if a single comment targets a line
+// outside the diff, so comments on such lines are moved to the nearest changed
+// line, or dropped and listed in the summary if there is none close by. Ranges
+// must lie within a single hunk; other ranges are reduced to their last line.
+// Anchored comments are then placed on the version of the file providers
+// expect, with their line numbers in both versions.
+func AnchorComments(ctx context.Context, job *Job) error {
+	files, ok := job.parsedFiles(ctx)
+	if !ok {
+		return nil
+	}
+
+	anchored := make([]git.ReviewComment, 0, len(job.Comments))
+	var dropped []git.ReviewComment
+	for _, comment := range job.Comments {
+		line, reason := anchor(files, comment)
+		if reason != "" {
+			job.Adjustments = append(job.Adjustments, Adjustment{Comment: comment, Line: line, Reason: reason})
+		}
+		if line == 0 {
+			dropped = append(dropped, comment)
+			continue
+		}
+		moved := line != comment.Line
+		if !moved && comment.IsRange() && !inOneHunk(files, comment) {
+			job.Adjustments = append(job.Adjustments, Adjustment{Comment: comment, Line: line,
+				Reason: fmt.Sprintf("lines %d-%d are not in one hunk", comment.StartLine, comment.Line)})
+			moved = true
+		}
+		if moved {
+			// A moved comment covers a single line, and the suggestion was written
+			// for the original lines; show it without making it applicable
+			comment.StartLine = 0
+			if comment.SuggestedCode != "" {
+				comment.Content += git.SuggestionBlock(comment.SuggestedCode)
+				comment.SuggestedCode = ""
+			}
+		}
+		comment.Line = line
+		place(files, &comment)
+		anchored = append(anchored, comment)
+	}
+	job.Comments = anchored
+
+	if len(dropped) > 0 {
+		var summary strings.Builder
+		summary.WriteString(job.Summary)
+		summary.WriteString("\n\n**Comments on lines outside the diff:**\n")
+		for _, comment := range dropped {
+			fmt.Fprintf(&summary, "- `%s`: %s\n", comment.Location(), comment.Content)
+		}
+		job.Summary = strings.TrimLeft(summary.String(), "\n")
+	}
+	if len(job.Adjustments) > 0 {
+		log.FromContext(ctx).Info("adjusted comments outside the diff",
+			"adjusted", len(job.Adjustments)-len(dropped), "dropped", len(dropped))
+	}
+
+	return nil
+}
+
+// anchor returns the line a comment should be posted on and, if it had to be
+// changed, the reason. A line of 0 means the comment cannot be placed.
+func anchor(files []*diff.File, comment git.ReviewComment) (int, string) {
+	file := diff.Find(files, comment.File)
+	if file == nil {
+		return 0, "file is not part of the diff"
+	}
+	side := commentSide(comment)
+	if file.Line(side, comment.Line) != nil {
+		return comment.Line, ""
+	}
+
+	// Move to the nearest line changed on the same side
+	nearest, distance := 0, MaxAnchorDistance+1
+	for _, hunk := range file.Hunks {
+		for _, line := range hunk.Lines {
+			number := line.NewNumber
+			if side == diff.SideLeft {
+				number = line.OldNumber
+			}
+			if line.Kind == diff.LineContext || number == 0 {
+				continue
+			}
+			d := number - comment.Line
+			if d < 0 {
+				d = -d
+			}
+			if d < distance {
+				nearest, distance = number, d
+			}
+		}
+	}
+	if nearest == 0 {
+		return 0, fmt.Sprintf("no changed line within %d lines", MaxAnchorDistance)
+	}
+
+	return nearest, fmt.Sprintf("line %d is not part of the diff", comment.Line)
+}
+
+// place records the paths and line numbers of an anchored comment in both
+// versions of the file. Unchanged lines are commented on in the new version,
+// as providers only accept removed lines in the old one; a range ending on an
+// unchanged line keeps its start in the old version if that is a removed line.
+// Suggestions cannot be applied to the old version, so they are shown as code
+// on comments that touch it.
+func place(files []*diff.File, comment *git.ReviewComment) {
+	file := diff.Find(files, comment.File)
+	if file == nil {
+		return
+	}
+	comment.File = file.Path()
+	if file.OldPath != "" && file.OldPath != comment.File {
+		comment.OldPath = file.OldPath
+	}
+	line := file.Line(commentSide(*comment), comment.Line)
+	if line == nil {
+		return
+	}
+	comment.OldLine, comment.NewLine = line.OldNumber, line.NewNumber
+
+	if commentSide(*comment) == diff.SideLeft && line.Kind == diff.LineContext {
+		if comment.IsRange() {
+			start := file.Line(diff.SideLeft, comment.StartLine)
+			switch {
+			case start == nil:
+				comment.StartLine = 0
+			case start.Kind == diff.LineRemoved:
+				comment.StartSide = string(diff.SideLeft)
+			default:
+				comment.StartLine = start.NewNumber
+			}
+		}
+		comment.Side, comment.Line = string(diff.SideRight), line.NewNumber
+	}
+
+	onOldVersion := commentSide(*comment) == diff.SideLeft || comment.StartSide == string(diff.SideLeft)
+	if onOldVersion && comment.SuggestedCode != "" {
+		comment.Content += git.SuggestionBlock(comment.SuggestedCode)
+		comment.SuggestedCode = ""
+	}
+}
+
+// inOneHunk reports whether both ends of a range comment are in the same hunk
+func inOneHunk(files []*diff.File, comment git.ReviewComment) bool {
+	file := diff.Find(files, comment.File)
+	if file == nil {
+		return false
+	}
+	side := commentSide(comment)
+	hunk := file.Hunk(side, comment.StartLine)
+
+	return hunk != nil && hunk == file.Hunk(side, comment.Line)
+}
+
+// commentSide returns the side of the diff a comment's lines refer to
+func commentSide(comment git.ReviewComment) diff.Side {
+	if comment.Side == string(diff.SideLeft) {
+		return diff.SideLeft
+	}
+
+	return diff.SideRight
+}
@@ -0,0 +1,62 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/policy"
+)
+
+// EscalateSensitiveBranches is a postprocess handler raising the severity of
+// the findings of pull requests targeting a sensitive branch of the
+// repository's branch sensitivity, such as a release branch, or main during a
+// release window. Each raised finding keeps the severity it was reported with
+// and explains the escalation in its comment, so that authors know why the
+// same finding is more severe here than elsewhere. It runs before findings
+// below the minimum severity are dropped, so that a finding raised to the
+// minimum severity is kept.
+func EscalateSensitiveBranches(ctx context.Context, job *Job) error {
+	if job.Config == nil || len(job.Config.BranchSensitivity) == 0 || job.BaseBranch == "" || len(job.Comments) == 0 {
+		return nil
+	}
+	sensitive, window, ok := job.Config.Sensitive(job.BaseBranch, time.Now())
+	if !ok {
+		return nil
+	}
+	levels := 1
+	if sensitive.Levels != nil {
+		levels = *sensitive.Levels
+	}
+
+	reason := fmt.Sprintf("this pull request targets `%s`", job.BaseBranch)
+	if window != nil {
+		name := window.Name
+		if name == "" {
+			name = "a release"
+		}
+		reason += fmt.Sprintf(" during the %s window (%s to %s)", name, window.Start, window.End)
+	}
+
+	raised := 0
+	for i := range job.Comments {
+		comment := &job.Comments[i]
+		severity := policy.RaiseSeverity(comment.Severity, levels)
+		if severity == comment.Severity {
+			continue
+		}
+		comment.ReportedSeverity = comment.Severity
+		comment.Escalation = fmt.Sprintf("Severity raised from %s to %s because %s.", comment.Severity, severity, reason)
+		comment.Content += "\n\n_" + comment.Escalation + "_"
+		comment.Severity = severity
+		raised++
+	}
+	if raised > 0 {
+		log.FromContext(ctx).Info("raised the severity of findings on a sensitive branch", "branch", job.BaseBranch,
+			"pattern", sensitive.Pattern, "levels", levels, "raised", raised)
+	}
+
+	return nil
+}
@@ -0,0 +1,256 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+)
+
+const (
+	// DefaultChunkTokens is the default size of a diff chunk in tokens
+	DefaultChunkTokens = 6000
+
+	// DefaultChunkConcurrency is the default number of chunks reviewed at once
+	DefaultChunkConcurrency = 4
+)
+
+// ChunkOptions configures how large diffs are split and reviewed
+type ChunkOptions struct {
+	// MaxTokens is the maximum size of a chunk in tokens (defaults to DefaultChunkTokens)
+	MaxTokens int
+
+	// Concurrency is the number of chunks reviewed at once (defaults to DefaultChunkConcurrency)
+	Concurrency int
+
+	// Summarizer consolidates the chunk summaries (defaults to the reviewing
+	// client if it implements llm.Summarizer)
+	Summarizer llm.Summarizer
+
+	// SummaryTokens is the maximum size of the summaries consolidated in one
+	// call; larger ones are map-reduced (defaults to DefaultSummaryTokens)
+	SummaryTokens int
+
+	// Limits records the chunk size learned for each model when chunks turn
+	// out too large for it (defaults to limits held in memory)
+	Limits ChunkLimits
+}
+
+// Chunk is a part of a diff small enough to review in one LLM call
+type Chunk struct {
+	// Diff is the unified diff of the chunk
+	Diff string
+
+	// Files is the number of files the chunk completes
+	Files int
+}
+
+// SplitDiff splits a unified diff into chunks of at most maxTokens. Files are
+// kept whole where possible; files larger than a chunk are split between
+// hunks, repeating the file header in every chunk. A diff that cannot be
+// parsed is returned as a single chunk.
+func SplitDiff(unified string, maxTokens int) []Chunk {
+	parsed, err := diff.Parse(unified)
+	if err != nil {
+		return []Chunk{{Diff: unified, Files: countFiles(unified)}}
+	}
+
+	var chunks []Chunk
+	var current strings.Builder
+	files := 0
+
+	flush := func() {
+		if current.Len() > 0 {
+			chunks = append(chunks, Chunk{Diff: current.String(), Files: files})
+			current.Reset()
+			files = 0
+		}
+	}
+
+	for _, file := range parsed {
+		whole := file.String()
+		if llm.EstimateTokens(current.String()+whole) <= maxTokens {
+			current.WriteString(whole)
+			files++
+			continue
+		}
+		flush()
+
+		if llm.EstimateTokens(whole) <= maxTokens {
+			current.WriteString(whole)
+			files++
+			continue
+		}
+
+		// Split the file between hunks; a single oversized hunk becomes its own chunk
+		for _, hunk := range file.Hunks {
+			text := hunk.String()
+			if current.Len() > 0 && llm.EstimateTokens(current.String()+text) > maxTokens {
+				flush()
+			}
+			if current.Len() == 0 {
+				current.WriteString(file.Header)
+			}
+			current.WriteString(text)
+		}
+		files++
+	}
+	flush()
+
+	return chunks
+}
+
+// ReviewChunks returns a handler that splits the diff into chunks, reviews
+// them concurrently with the given LLM client (or the job's own client), and
+// merges the results. When the diff is split, the chunk summaries are
+// consolidated by the summarizer, map-reducing them if they exceed
+// options.SummaryTokens.
+//
+// Chunks the model rejects as exceeding its context window, or answers
+// truncated, are split in half and reviewed again. The smaller size is
+// recorded in options.Limits, and later reviews with the model split diffs
+// into chunks of that size.
+func ReviewChunks(client llm.Client, options ChunkOptions) Handler {
+	if options.MaxTokens == 0 {
+		options.MaxTokens = DefaultChunkTokens
+	}
+	if options.Concurrency == 0 {
+		options.Concurrency = DefaultChunkConcurrency
+	}
+	if options.SummaryTokens == 0 {
+		options.SummaryTokens = DefaultSummaryTokens
+	}
+	if options.Limits == nil {
+		options.Limits = NewMemoryChunkLimits()
+	}
+
+	return func(ctx context.Context, job *Job) error {
+		reviewer := client
+		if job.LLM != nil {
+			reviewer = job.LLM
+		}
+
+		var mu sync.Mutex
+		tuner := &chunkTuner{
+			reviewer: reviewer,
+			model:    llm.ModelID(reviewer),
+			options:  job.Options,
+			stream:   streamProgress(job, &mu),
+		}
+		defer tuner.recordChunkTokens(ctx, options.Limits)
+
+		maxTokens := chunkTokens(ctx, options.Limits, tuner.model, options.MaxTokens)
+		chunks := SplitDiff(job.Diff, maxTokens)
+		if len(chunks) <= 1 {
+			chunkResults, err := tuner.review(ctx, job.Diff, maxTokens)
+			if err != nil {
+				return fmt.Errorf("error reviewing code: %w", err)
+			}
+			UpdateProgress(ctx, job, job.progressTotal)
+			if len(chunkResults) == 1 {
+				job.Result = chunkResults[0]
+				return nil
+			}
+
+			// The diff turned out too large for the model and was split
+			return summarizeChunks(ctx, job, reviewer, chunkResults, options)
+		}
+
+		// Map: review each chunk
+		results := make([][]*llm.ReviewResult, len(chunks))
+		filesDone := 0
+
+		group, groupCtx := errgroup.WithContext(ctx)
+		group.SetLimit(options.Concurrency)
+		for i, chunk := range chunks {
+			group.Go(func() error {
+				chunkResults, err := tuner.review(groupCtx, chunk.Diff, maxTokens)
+				if err != nil {
+					return fmt.Errorf("error reviewing chunk %d/%d: %w", i+1, len(chunks), err)
+				}
+				results[i] = chunkResults
+
+				mu.Lock()
+				defer mu.Unlock()
+				filesDone += chunk.Files
+				UpdateProgress(ctx, job, filesDone)
+
+				return nil
+			})
+		}
+		if err := group.Wait(); err != nil {
+			// Keep the chunks reviewed before the review was cancelled so they can still be published
+			if ctx.Err() != nil {
+				reviewed := 0
+				for _, chunkResults := range results {
+					if chunkResults != nil {
+						reviewed++
+					}
+				}
+				_, merged, _ := mergeResults(slices.Concat(results...))
+				if reviewed > 0 {
+					merged.Summary = fmt.Sprintf("**Partial review:** the review was stopped after %d of %d parts of the diff. "+
+						"Findings cover only those parts.\n\n%s", reviewed, len(chunks), merged.Summary)
+					job.Result = merged
+					job.Partial = true
+				}
+			}
+			return err
+		}
+
+		return summarizeChunks(ctx, job, reviewer, slices.Concat(results...), options)
+	}
+}
+
+// summarizeChunks merges the results of the reviewed chunks into the job's
+// result, consolidating their summaries with the summarizer
+func summarizeChunks(ctx context.Context, job *Job, reviewer llm.Client, results []*llm.ReviewResult, options ChunkOptions) error {
+	// Reduce: merge comments and consolidate summaries
+	_, merged, summaries := mergeResults(results)
+
+	summarizer := options.Summarizer
+	if summarizer == nil {
+		summarizer, _ = reviewer.(llm.Summarizer)
+	}
+	if summarizer != nil && len(summaries) > 1 {
+		summary, err := summarizeReview(ctx, summarizer, summaries, merged.Comments, job.Options,
+			options.SummaryTokens, options.Concurrency)
+		if err != nil {
+			return fmt.Errorf("error summarizing review: %w", err)
+		}
+		merged.Summary = summary
+	}
+	job.Result = merged
+
+	return nil
+}
+
+// mergeResults merges the results of the reviewed chunks, skipping chunks
+// without a result, and returns the number of results merged and their summaries
+func mergeResults(results []*llm.ReviewResult) (int, *llm.ReviewResult, []string) {
+	merged := &llm.ReviewResult{}
+	summaries := make([]string, 0, len(results))
+	reviewed := 0
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		reviewed++
+		merged.Comments = append(merged.Comments, result.Comments...)
+		merged.TokensUsed += result.TokensUsed
+		merged.AddUsage(result.Usage...)
+		merged.Redactions = merged.Redactions.Add(result.Redactions)
+		if result.Summary != "" {
+			summaries = append(summaries, result.Summary)
+		}
+	}
+	merged.Summary = strings.Join(summaries, "\n\n")
+
+	return reviewed, merged, summaries
+}
@@ -0,0 +1,154 @@
+package review
+
+import (
+	"context"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+	"github.com/Shridhar2104/code-review-operator/pkg/metrics"
+)
+
+// MinChunkTokens is the smallest chunk size chunks too large for a model
+// are split down to
+const MinChunkTokens = 500
+
+// ChunkLimits records the chunk size each model was found to handle, so that
+// later reviews split diffs small enough for it from the start
+type ChunkLimits interface {
+	// ChunkTokens returns the chunk size learned for a model, or 0 if none was
+	ChunkTokens(ctx context.Context, model string) (int, error)
+
+	// LowerChunkTokens records that a model handles chunks of at most
+	// tokens, unless a smaller size was already recorded
+	LowerChunkTokens(ctx context.Context, model string, tokens int) error
+}
+
+// MemoryChunkLimits is a ChunkLimits held in memory, forgotten when the
+// process exits
+type MemoryChunkLimits struct {
+	mu     sync.Mutex
+	limits map[string]int
+}
+
+// NewMemoryChunkLimits creates an empty MemoryChunkLimits
+func NewMemoryChunkLimits() *MemoryChunkLimits {
+	return &MemoryChunkLimits{limits: make(map[string]int)}
+}
+
+// ChunkTokens implements ChunkLimits
+func (l *MemoryChunkLimits) ChunkTokens(_ context.Context, model string) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.limits[model], nil
+}
+
+// LowerChunkTokens implements ChunkLimits
+func (l *MemoryChunkLimits) LowerChunkTokens(_ context.Context, model string, tokens int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if current, ok := l.limits[model]; !ok || tokens < current {
+		l.limits[model] = tokens
+	}
+
+	return nil
+}
+
+// chunkTuner reviews chunks with a model, splitting those too large for it,
+// and learns the chunk size the model handles
+type chunkTuner struct {
+	reviewer llm.Client
+	model    string
+	options  llm.ReviewOptions
+	stream   llm.StreamFunc
+
+	mu      sync.Mutex
+	learned int
+}
+
+// review reviews a part of the diff of at most tokens. If the model rejects
+// it as exceeding its context window, or truncates its answer, the part is
+// split into chunks of half its size, down to MinChunkTokens, which are
+// reviewed in turn. It returns the results of every chunk reviewed.
+func (t *chunkTuner) review(ctx context.Context, unified string, tokens int) ([]*llm.ReviewResult, error) {
+	result, err := llm.ReviewStream(ctx, t.reviewer, unified, t.options, t.stream)
+	if err == nil {
+		return []*llm.ReviewResult{result}, nil
+	}
+	if !llm.IsTooLarge(err) || ctx.Err() != nil {
+		return nil, err
+	}
+
+	smaller := min(tokens, llm.EstimateTokens(unified)) / 2
+	if smaller < MinChunkTokens {
+		return nil, err
+	}
+	chunks := SplitDiff(unified, smaller)
+	if len(chunks) <= 1 {
+		// A single hunk larger than the chunk cannot be split further
+		return nil, err
+	}
+	log.FromContext(ctx).Info("diff chunk too large for the model, splitting it", "model", t.model,
+		"chunkTokens", smaller, "error", err.Error())
+	metrics.LLMChunkReductions.WithLabelValues(t.model).Inc()
+	t.lower(smaller)
+
+	var results []*llm.ReviewResult
+	for _, chunk := range chunks {
+		reviewed, err := t.review(ctx, chunk.Diff, smaller)
+		results = append(results, reviewed...)
+		if err != nil {
+			return results, err
+		}
+	}
+
+	return results, nil
+}
+
+// lower records that chunks of tokens had to be split to
+func (t *chunkTuner) lower(tokens int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.learned == 0 || tokens < t.learned {
+		t.learned = tokens
+	}
+}
+
+// chunkTokens returns the chunk size to split a diff reviewed by model into:
+// the configured size, or the size learned for the model if smaller
+func chunkTokens(ctx context.Context, limits ChunkLimits, model string, maxTokens int) int {
+	if model == "" {
+		return maxTokens
+	}
+	learned, err := limits.ChunkTokens(ctx, model)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to read the chunk size learned for the model", "model", model)
+		return maxTokens
+	}
+	if learned > 0 && learned < maxTokens {
+		return learned
+	}
+
+	return maxTokens
+}
+
+// recordChunkTokens saves the chunk size learned while reviewing with a
+// model, if chunks had to be split
+func (t *chunkTuner) recordChunkTokens(ctx context.Context, limits ChunkLimits) {
+	t.mu.Lock()
+	learned := t.learned
+	t.mu.Unlock()
+	if learned == 0 || t.model == "" {
+		return
+	}
+
+	if err := limits.LowerChunkTokens(ctx, t.model, learned); err != nil {
+		log.FromContext(ctx).Error(err, "unable to record the chunk size learned for the model", "model", t.model)
+		return
+	}
+	log.FromContext(ctx).Info("learned the chunk size of the model", "model", t.model, "chunkTokens", learned)
+}
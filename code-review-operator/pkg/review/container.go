@@ -0,0 +1,294 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/policy"
+)
+
+// Rules of the container profile
+const (
+	// RuleAddInsteadOfCopy flags ADD used where COPY is enough
+	RuleAddInsteadOfCopy = "container/add-instead-of-copy"
+
+	// RuleSecretBuildArg flags secrets passed as build arguments or baked into the environment
+	RuleSecretBuildArg = "container/secret-in-build-arg"
+
+	// RuleLatestTag flags images pulled by the latest tag outside FROM
+	RuleLatestTag = "container/latest-tag"
+
+	// RuleMissingHealthcheck flags final images without a HEALTHCHECK
+	RuleMissingHealthcheck = "container/missing-healthcheck"
+)
+
+// containerRules are the rules of the container profile, as sent to the LLM
+var containerRules = []string{
+	RuleAddInsteadOfCopy + ": use COPY for local files; ADD fetches URLs and unpacks archives implicitly",
+	RuleSecretBuildArg + ": never pass secrets through ARG or ENV, they persist in the image history; use build secrets",
+	RuleLatestTag + ": pin every image a build pulls, including COPY --from images and image build arguments",
+	RuleMissingHealthcheck + ": final images of long-running services should declare a HEALTHCHECK",
+}
+
+// secretName matches the names of variables holding secrets
+var secretName = regexp.MustCompile(`(?i)(passw(or)?d|secret|token|api_?key|private_?key|access_?key|credentials?)`)
+
+// archiveSource matches ADD sources that ADD unpacks, which COPY cannot replace
+var archiveSource = regexp.MustCompile(`\.(tar|tar\.gz|tgz|tar\.bz2|tbz2?|tar\.xz|txz)$`)
+
+// CheckContainerFiles is an enrich handler running the container profile:
+// hadolint-style checks of changed Dockerfiles for ADD used instead of COPY,
+// secrets in build arguments, images pulled by the latest tag and final
+// images without a HEALTHCHECK. Findings on added lines are kept in
+// job.Checks and listed in the prompt's rules, so the LLM explains each one
+// in the context of the change; MergeChecks then posts them with the
+// explanations.
+func CheckContainerFiles(ctx context.Context, job *Job) error {
+	files, ok := job.parsedFiles(ctx)
+	if !ok {
+		return nil
+	}
+
+	reviewed := false
+	var checks []git.ReviewComment
+	for _, file := range files {
+		if setupFileKind(file.NewPath) != setupDockerfile || file.Binary {
+			continue
+		}
+		added := addedLines(file)
+		if len(added) == 0 {
+			continue
+		}
+		data, err := job.Client.GetFileContent(ctx, job.Owner, job.Repository, file.NewPath, job.CommitSHA)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "unable to fetch Dockerfile, skipping container checks", "file", file.NewPath)
+			continue
+		}
+		reviewed = true
+		checks = append(checks, checkContainer(file.NewPath, string(data), added)...)
+	}
+	if reviewed {
+		addChecks(job, containerRules, checks)
+	}
+
+	return nil
+}
+
+// addChecks adds the rules of a profile to the LLM's rules and the findings
+// of its checks to a job, asking the LLM to explain each finding
+func addChecks(job *Job, rules []string, checks []git.ReviewComment) {
+	job.Options.Rules = appendRules(job.Options.Rules, rules...)
+	job.Checks = append(job.Checks, checks...)
+	if len(checks) == 0 {
+		return
+	}
+
+	flagged := make([]string, 0, len(checks))
+	for _, check := range checks {
+		flagged = append(flagged, fmt.Sprintf("%s (%s)", check.Location(), check.Rule))
+	}
+	job.Options.Rules = append(job.Options.Rules, "static checks flagged "+strings.Join(flagged, ", ")+
+		": comment on each of these lines, tagged with its rule, explaining the risk in this change and how to fix it")
+}
+
+// MergeChecks is a postprocess handler merging the findings of deterministic
+// checks into the LLM's comments. An LLM comment on a flagged line, for the
+// same rule or no rule, becomes the finding's explanation and is raised to at
+// least the finding's severity; findings the LLM did not explain are posted
+// with the check's own text. It runs after the LLM's comments are converted.
+func MergeChecks(ctx context.Context, job *Job) error {
+	for _, check := range job.Checks {
+		explained := false
+		for i, comment := range job.Comments {
+			if !explains(comment, check) {
+				continue
+			}
+			job.Comments[i].Rule = check.Rule
+			if policy.SeverityRank(check.Severity) > policy.SeverityRank(comment.Severity) {
+				job.Comments[i].Severity = check.Severity
+			}
+			explained = true
+			break
+		}
+		if !explained {
+			job.Comments = append(job.Comments, check)
+		}
+	}
+
+	return nil
+}
+
+// explains reports whether a comment covers the line and rule of a check's finding
+func explains(comment, check git.ReviewComment) bool {
+	if comment.File != check.File || comment.Side == string(diff.SideLeft) {
+		return false
+	}
+	if comment.Rule != "" && comment.Rule != check.Rule {
+		return false
+	}
+	start := comment.StartLine
+	if start == 0 {
+		start = comment.Line
+	}
+
+	return check.Line >= start && check.Line <= comment.Line
+}
+
+// appendRules appends rules that are not already present
+func appendRules(rules []string, values ...string) []string {
+	seen := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		seen[rule] = true
+	}
+	for _, rule := range values {
+		if !seen[rule] {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
+// checkContainer checks a Dockerfile's instructions against the container
+// profile, reporting findings on added lines
+func checkContainer(file, content string, added map[int]string) []git.ReviewComment {
+	var comments []git.ReviewComment
+	report := func(line int, severity, rule, content string) {
+		if _, ok := added[line]; ok {
+			comments = append(comments, git.ReviewComment{File: file, Line: line, Severity: severity, Rule: rule, Content: content})
+		}
+	}
+
+	stages := make(map[string]bool)
+	var finalFrom instruction
+	var finalImage string
+	healthcheck := false
+	for _, inst := range parseDockerfile(content) {
+		switch inst.Keyword {
+		case "from":
+			image, name := fromImage(inst.Args)
+			if name != "" {
+				stages[strings.ToLower(name)] = true
+			}
+			finalFrom, finalImage, healthcheck = inst, image, false
+		case "healthcheck":
+			healthcheck = true
+		case "add":
+			if source, ok := localAddSource(inst.Args); ok {
+				report(inst.Line, "minor", RuleAddInsteadOfCopy, fmt.Sprintf("`ADD %s` copies a local file; use `COPY`, "+
+					"which does not fetch URLs or unpack archives as a side effect.", source))
+			} else if remote := remoteAddSource(inst.Args); remote != "" && !strings.Contains(inst.Args, "--checksum") {
+				report(inst.Line, "major", RuleAddInsteadOfCopy, fmt.Sprintf("`ADD` downloads `%s` without verifying it. "+
+					"Add `--checksum=sha256:...`, or download it in a `RUN` step that checks its digest.", remote))
+			}
+		case "copy":
+			if image := copyFromImage(inst.Args); !stages[strings.ToLower(image)] && unpinnedImage(image) {
+				report(inst.Line, "major", RuleLatestTag, fmt.Sprintf("`COPY --from=%s` copies from an unpinned image, "+
+					"so the build can change without notice. Pin a specific tag or digest.", image))
+			}
+		case "arg", "env":
+			name, value := variable(inst.Args)
+			switch {
+			case name == "":
+			case secretName.MatchString(name) && inst.Keyword == "arg":
+				report(inst.Line, "major", RuleSecretBuildArg, fmt.Sprintf("Build argument `%s` looks like a secret. "+
+					"Build arguments are recorded in the image history; mount it with `RUN --mount=type=secret` instead.", name))
+			case secretName.MatchString(name) && value != "" && !strings.HasPrefix(value, "$"):
+				report(inst.Line, "critical", RuleSecretBuildArg, fmt.Sprintf("`%s` bakes a secret into the image's "+
+					"environment. Remove it and provide it at runtime or through a build secret.", name))
+			case inst.Keyword == "arg" && latestImage.MatchString(value):
+				report(inst.Line, "minor", RuleLatestTag, fmt.Sprintf("Build argument `%s` defaults to a `latest` image. "+
+					"Pin a specific tag so builds are reproducible.", name))
+			}
+		}
+	}
+	if finalFrom.Keyword != "" && !healthcheck && !strings.EqualFold(finalImage, "scratch") && !stages[strings.ToLower(finalImage)] {
+		report(finalFrom.Line, "suggestion", RuleMissingHealthcheck, "The final image declares no `HEALTHCHECK`. "+
+			"If it runs a long-lived service, add one so the container runtime can detect when it stops responding.")
+	}
+
+	return comments
+}
+
+// addSources returns the sources of ADD arguments, without flags and the destination
+func addSources(args string) []string {
+	var fields []string
+	for _, field := range strings.Fields(args) {
+		if !strings.HasPrefix(field, "--") {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) < 2 {
+		return nil
+	}
+
+	return fields[:len(fields)-1]
+}
+
+// localAddSource returns the first source of an ADD that only copies local
+// files, and whether all sources are such files
+func localAddSource(args string) (string, bool) {
+	sources := addSources(args)
+	if len(sources) == 0 {
+		return "", false
+	}
+	for _, source := range sources {
+		if strings.Contains(source, "://") || strings.HasPrefix(source, "git@") || archiveSource.MatchString(source) {
+			return "", false
+		}
+	}
+
+	return sources[0], true
+}
+
+// remoteAddSource returns the first URL an ADD downloads, if any
+func remoteAddSource(args string) string {
+	for _, source := range addSources(args) {
+		if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+			return source
+		}
+	}
+
+	return ""
+}
+
+// copyFromImage returns the image of a COPY --from flag
+func copyFromImage(args string) string {
+	for _, field := range strings.Fields(args) {
+		from, ok := strings.CutPrefix(field, "--from=")
+		if !ok {
+			continue
+		}
+		// Stages can also be referenced by their index
+		if _, err := strconv.Atoi(from); err == nil {
+			return ""
+		}
+		return from
+	}
+
+	return ""
+}
+
+// variable returns the first name and value of ARG or ENV arguments
+func variable(args string) (string, string) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	if name, value, ok := strings.Cut(fields[0], "="); ok {
+		return name, strings.Trim(value, `"'`)
+	}
+	if len(fields) > 1 {
+		// Legacy ENV name value form
+		return fields[0], strings.Trim(strings.Join(fields[1:], " "), `"'`)
+	}
+
+	return fields[0], ""
+}
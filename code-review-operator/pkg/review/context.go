@@ -0,0 +1,277 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+)
+
+const (
+	// DefaultContextTokens is the default budget of repository context in tokens
+	DefaultContextTokens = 4000
+
+	// DefaultContextLines is the default number of lines shown around each hunk
+	DefaultContextLines = 20
+
+	// DefaultContextFiles is the default number of changed files fetched for context
+	DefaultContextFiles = 20
+
+	// maxDefinitionLines is the most lines shown of a referenced definition
+	maxDefinitionLines = 30
+)
+
+// ContextOptions configures the repository context added to reviews
+type ContextOptions struct {
+	// MaxTokens is the budget of the context in tokens (defaults to DefaultContextTokens)
+	MaxTokens int
+
+	// Lines is the number of lines shown above and below each hunk (defaults to DefaultContextLines)
+	Lines int
+
+	// MaxFiles is the number of changed files fetched (defaults to DefaultContextFiles)
+	MaxFiles int
+}
+
+// manifests maps file extensions to the package manifests describing their dependencies
+var manifests = map[string][]string{
+	".go":   {"go.mod"},
+	".py":   {"pyproject.toml", "requirements.txt"},
+	".java": {"pom.xml", "build.gradle"},
+	".kt":   {"build.gradle.kts", "pom.xml"},
+	".js":   {"package.json"},
+	".jsx":  {"package.json"},
+	".ts":   {"package.json"},
+	".tsx":  {"package.json"},
+	".rs":   {"Cargo.toml"},
+	".rb":   {"Gemfile"},
+}
+
+// callPattern matches the names of functions called on a line
+var callPattern = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*)\s*\(`)
+
+// definitionPattern matches lines defining a function, in the common syntaxes
+var definitionPattern = regexp.MustCompile(`^\s*(?:func(?:\s*\([^)]*\))?|def|function|fn|(?:(?:public|private|protected|static|final|async|pub)\s+)+[\w<>\[\],.]+)\s+([A-Za-z_][A-Za-z0-9_]*)\s*\(`)
+
+// keywords are words followed by a parenthesis that are not function calls
+var keywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "return": true, "func": true,
+	"function": true, "def": true, "fn": true, "catch": true, "select": true, "make": true,
+	"len": true, "append": true, "new": true, "print": true,
+}
+
+// GatherContext returns an enrich stage handler that adds repository context
+// to the review request: the code around each changed region, the package
+// manifests of the changed files' languages, and the definitions of functions
+// that added lines call and that are defined elsewhere in the changed files.
+// Files are read through the provider's contents API at the reviewed commit.
+// The context is cut to fit its token budget, in that order of importance.
+// Context is optional: failures are logged and the review continues without it.
+func GatherContext(options ContextOptions) Handler {
+	if options.MaxTokens <= 0 {
+		options.MaxTokens = DefaultContextTokens
+	}
+	if options.Lines <= 0 {
+		options.Lines = DefaultContextLines
+	}
+	if options.MaxFiles <= 0 {
+		options.MaxFiles = DefaultContextFiles
+	}
+
+	return func(ctx context.Context, job *Job) error {
+		logger := log.FromContext(ctx)
+
+		files, ok := job.parsedFiles(ctx)
+		if !ok {
+			return nil
+		}
+
+		// Fetch the new version of the changed files
+		contents := make(map[string][]string)
+		var paths []string
+		for _, file := range files {
+			if file.Binary || file.NewPath == "" || len(paths) == options.MaxFiles || job.confidential(file.NewPath) {
+				continue
+			}
+			data, err := job.Client.GetFileContent(ctx, job.Owner, job.Repository, file.NewPath, job.CommitSHA)
+			if err != nil {
+				logger.Error(err, "unable to fetch file for context", "file", file.NewPath)
+				continue
+			}
+			contents[file.NewPath] = strings.Split(string(data), "\n")
+			paths = append(paths, file.NewPath)
+		}
+
+		budget := options.MaxTokens
+		var fileContext strings.Builder
+		for _, file := range files {
+			lines, ok := contents[file.NewPath]
+			if !ok {
+				continue
+			}
+			section := surroundingCode(file, lines, options.Lines)
+			if !fitBudget(&budget, section) {
+				break
+			}
+			fileContext.WriteString(section)
+		}
+
+		var snippets []string
+		for _, name := range manifestPaths(paths) {
+			if job.confidential(name) {
+				continue
+			}
+			data, err := job.Client.GetFileContent(ctx, job.Owner, job.Repository, name, job.CommitSHA)
+			if err != nil {
+				if !errors.Is(err, git.ErrResourceNotFound) {
+					logger.Error(err, "unable to fetch manifest for context", "file", name)
+				}
+				continue
+			}
+			snippet := fmt.Sprintf("%s:\n```\n%s\n```", name, strings.TrimSpace(string(data)))
+			if fitBudget(&budget, snippet) {
+				snippets = append(snippets, snippet)
+			}
+		}
+		for _, snippet := range definitions(files, contents, options.Lines) {
+			if !fitBudget(&budget, snippet) {
+				break
+			}
+			snippets = append(snippets, snippet)
+		}
+
+		job.Options.FileContext = job.maskSecrets(strings.TrimSpace(fileContext.String()))
+		for _, snippet := range snippets {
+			job.Options.Snippets = append(job.Options.Snippets, job.maskSecrets(snippet))
+		}
+		logger.Info("gathered repository context", "files", len(paths), "snippets", len(snippets),
+			"tokens", options.MaxTokens-budget)
+
+		return nil
+	}
+}
+
+// fitBudget subtracts the tokens of a section from the budget and reports
+// whether it fits
+func fitBudget(budget *int, section string) bool {
+	tokens := llm.EstimateTokens(section)
+	if tokens > *budget {
+		return false
+	}
+	*budget -= tokens
+
+	return true
+}
+
+// surroundingCode renders the lines around each hunk of a file with their line numbers
+func surroundingCode(file *diff.File, lines []string, margin int) string {
+	var b strings.Builder
+	end := 0
+	for _, hunk := range file.Hunks {
+		from := max(hunk.NewStart-margin, end+1, 1)
+		to := min(hunk.NewStart+hunk.NewLines-1+margin, len(lines))
+		if from > to {
+			continue
+		}
+		if b.Len() == 0 {
+			fmt.Fprintf(&b, "%s:\n```\n", file.NewPath)
+		} else if from > end+1 {
+			b.WriteString("...\n")
+		}
+		for n := from; n <= to; n++ {
+			fmt.Fprintf(&b, "%d: %s\n", n, lines[n-1])
+		}
+		end = to
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	b.WriteString("```\n\n")
+
+	return b.String()
+}
+
+// manifestPaths returns the manifests of the languages of the changed files,
+// looked up in the repository root
+func manifestPaths(paths []string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, p := range paths {
+		for _, name := range manifests[path.Ext(p)] {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// definitions returns the definitions of the functions called on added lines
+// that are defined in a changed file outside the code already shown around
+// its hunks
+func definitions(files []*diff.File, contents map[string][]string, margin int) []string {
+	called := make(map[string]bool)
+	for _, file := range files {
+		for _, hunk := range file.Hunks {
+			for _, line := range hunk.Lines {
+				if line.Kind != diff.LineAdded {
+					continue
+				}
+				for _, match := range callPattern.FindAllStringSubmatch(line.Content, -1) {
+					if !keywords[match[1]] {
+						called[match[1]] = true
+					}
+				}
+			}
+		}
+	}
+
+	var snippets []string
+	for _, file := range files {
+		lines, ok := contents[file.NewPath]
+		if !ok {
+			continue
+		}
+		for i, line := range lines {
+			match := definitionPattern.FindStringSubmatch(line)
+			if match == nil || !called[match[1]] || nearHunk(file, i+1, margin) {
+				continue
+			}
+			delete(called, match[1])
+
+			end := min(i+maxDefinitionLines, len(lines))
+			for j := i + 1; j < end; j++ {
+				if lines[j] == "}" {
+					end = j + 1
+					break
+				}
+			}
+			snippets = append(snippets, fmt.Sprintf("%s:%d:\n```\n%s\n```", file.NewPath, i+1, strings.Join(lines[i:end], "\n")))
+		}
+	}
+
+	return snippets
+}
+
+// nearHunk reports whether a line of the new version is shown in the code around a hunk
+func nearHunk(file *diff.File, number, margin int) bool {
+	for _, hunk := range file.Hunks {
+		if number >= hunk.NewStart-margin && number <= hunk.NewStart+hunk.NewLines-1+margin {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,83 @@
+package review
+
+import (
+	"context"
+	"slices"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/contract"
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/repoconfig"
+)
+
+// RuleBreakingChange flags API definition changes that break existing clients
+const RuleBreakingChange = "api/breaking-change"
+
+// CheckAPIContracts is a postprocess handler that compares the old and new
+// versions of changed Protocol Buffers files and OpenAPI documents and
+// reports the changes that break existing clients, such as removed fields,
+// changed types and renumbered tags, next to the LLM's comments. The old
+// version is reconstructed from the new one and the diff. Removed elements
+// are commented on the old side of the diff. It runs when the repository
+// enables the contracts rule pack, and is skipped for diffs without a commit
+// to read the definitions at, such as pre-computed diffs.
+func CheckAPIContracts(ctx context.Context, job *Job) error {
+	if job.Config == nil || !slices.Contains(job.Config.RulePacks, repoconfig.RulePackContracts) {
+		return nil
+	}
+	if job.Client == nil || job.CommitSHA == "" {
+		return nil
+	}
+	files, ok := job.parsedFiles(ctx)
+	if !ok {
+		return nil
+	}
+
+	for _, file := range files {
+		if file.Status == diff.FileAdded || file.Status == diff.FileDeleted || file.Binary || len(file.Hunks) == 0 {
+			continue
+		}
+		if !contract.Candidate(file.NewPath) {
+			continue
+		}
+
+		logger := log.FromContext(ctx).WithValues("file", file.NewPath)
+		data, err := job.Client.GetFileContent(ctx, job.Owner, job.Repository, file.NewPath, job.CommitSHA)
+		if err != nil {
+			logger.Error(err, "unable to fetch API definition, skipping API contract checks")
+			continue
+		}
+		kind := contract.Kind(file.NewPath, string(data))
+		if kind == "" {
+			continue
+		}
+		oldContent, err := file.OldContent(string(data))
+		if err != nil {
+			logger.Error(err, "unable to reconstruct old API definition, skipping API contract checks")
+			continue
+		}
+		changes, err := contract.Compare(kind, oldContent, string(data))
+		if err != nil {
+			logger.Error(err, "unable to compare API definitions, skipping API contract checks")
+			continue
+		}
+
+		for _, change := range changes {
+			comment := git.ReviewComment{
+				File:     file.NewPath,
+				Line:     change.Line,
+				Severity: "major",
+				Rule:     RuleBreakingChange,
+				Content:  "Breaking API change: " + change.Message,
+			}
+			if change.Removed {
+				comment.Side = string(diff.SideLeft)
+			}
+			job.Comments = append(job.Comments, comment)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/repoconfig"
+)
+
+// maxRelatedDiffLength is the length in bytes the diffs of related pull
+// requests are cut to
+const maxRelatedDiffLength = 8000
+
+// relatedPullRequestPattern matches references to pull requests of other
+// repositories: owner/repo#123 or the URL of a pull request
+var relatedPullRequestPattern = regexp.MustCompile(`(?:^|[^\w/.-])([\w.-]+)/([\w.-]+)#(\d+)\b|` +
+	`https?://[^/\s]+/([\w.-]+)/([\w.-]+)/pull/(\d+)`)
+
+// ParseRelatedPullRequests returns the references of a pull request
+// description to issues or pull requests of repositories other than
+// owner/repo, such as "depends on acme/api#12" or the URL of a pull request.
+// HTML comments are ignored.
+func ParseRelatedPullRequests(description, owner, repo string) []IssueRef {
+	description = htmlComment.ReplaceAllString(description, "")
+
+	var refs []IssueRef
+	for _, match := range relatedPullRequestPattern.FindAllStringSubmatch(description, -1) {
+		ref := IssueRef{Owner: match[1], Repository: match[2]}
+		number := match[3]
+		if match[6] != "" {
+			ref.Owner, ref.Repository, number = match[4], match[5], match[6]
+		}
+		ref.Number, _ = strconv.Atoi(number)
+		if ref.Number <= 0 || sameRepository(ref, owner, repo) || containsRef(refs, ref) {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs
+}
+
+// sameRepository reports whether ref is in owner/repo
+func sameRepository(ref IssueRef, owner, repo string) bool {
+	return strings.EqualFold(ref.Owner, owner) && strings.EqualFold(ref.Repository, repo)
+}
+
+// CorrelatePullRequests is an enrich handler that reads the diffs of the
+// pull requests of other repositories made together with this one into the
+// options sent to the LLM, so the review can flag ordering and compatibility
+// risks between them. Pull requests are related when the description
+// references them or, for the repository's tracking labels, when they share
+// a label with this one. Only repositories job.AllowRepository allows are
+// read, and credentials in their diffs are masked before the LLM sees them.
+// It runs only when the repository's correlation policy enables it; pull
+// requests that cannot be read are logged and left out.
+func CorrelatePullRequests(ctx context.Context, job *Job) error {
+	if job.Client == nil || job.PullRequest == 0 || job.Config == nil || job.Config.Correlation == nil {
+		return nil
+	}
+	policy := job.Config.Correlation
+	if policy.Enabled == nil || !*policy.Enabled {
+		return nil
+	}
+	logger := log.FromContext(ctx)
+
+	limit := repoconfig.DefaultCorrelatedPullRequests
+	if policy.MaxPullRequests != nil {
+		limit = *policy.MaxPullRequests
+	}
+
+	candidates := ParseRelatedPullRequests(job.Description, job.Owner, job.Repository)
+	for _, label := range policy.Labels {
+		if !slices.Contains(job.Labels, label) {
+			continue
+		}
+		pullRequests, err := job.Client.FindPullRequestsByLabel(ctx, job.Owner, label)
+		if err != nil {
+			logger.Error(err, "unable to find pull requests sharing a tracking label", "label", label)
+			continue
+		}
+		for _, pr := range pullRequests {
+			ref := IssueRef{Owner: pr.Owner, Repository: pr.Repository, Number: pr.Number}
+			if !sameRepository(ref, job.Owner, job.Repository) && !containsRef(candidates, ref) {
+				candidates = append(candidates, ref)
+			}
+		}
+	}
+
+	var related strings.Builder
+	for _, ref := range candidates {
+		if len(job.Related) == limit {
+			break
+		}
+		if job.AllowRepository != nil && !job.AllowRepository(ref.Owner, ref.Repository) {
+			logger.Info("skipping related pull request of a repository the review may not read", "pullRequest", ref.String())
+			continue
+		}
+		issue, err := job.Client.GetIssue(ctx, ref.Owner, ref.Repository, ref.Number)
+		if err != nil {
+			logger.Error(err, "unable to read related pull request", "pullRequest", ref.String())
+			continue
+		}
+		if !issue.PullRequest {
+			// A referenced issue, not a change
+			continue
+		}
+		diff, err := job.Client.GetDiff(ctx, ref.Owner, ref.Repository, ref.Number, "")
+		if err != nil {
+			logger.Error(err, "unable to read the diff of related pull request", "pullRequest", ref.String())
+			continue
+		}
+		diff, err = job.maskDiffSecrets(diff)
+		if err != nil {
+			logger.Error(err, "unable to parse the diff of related pull request", "pullRequest", ref.String())
+			continue
+		}
+		if len(diff) > maxRelatedDiffLength {
+			diff = truncateDiff(diff, maxRelatedDiffLength) + "\n... [truncated]"
+		}
+
+		fmt.Fprintf(&related, "Pull request %s (%s): %s\n```diff\n%s\n```\n\n", ref, issue.State, issue.Title, strings.TrimRight(diff, "\n"))
+		job.Related = append(job.Related, ref)
+	}
+	job.Options.RelatedChanges = strings.TrimSpace(related.String())
+	if len(job.Related) > 0 {
+		logger.Info("correlated related pull requests", "related", len(job.Related))
+	}
+
+	return nil
+}
+
+// truncateDiff cuts a diff to at most limit bytes, at the end of a line if
+// there is one within the limit, and otherwise at the start of a character
+func truncateDiff(diff string, limit int) string {
+	if len(diff) <= limit {
+		return diff
+	}
+	if end := strings.LastIndexByte(diff[:limit], '\n'); end > 0 {
+		return diff[:end]
+	}
+	for limit > 0 && !utf8.RuneStart(diff[limit]) {
+		limit--
+	}
+
+	return diff[:limit]
+}
+
+// NoteRelatedChanges is a postprocess handler listing in the summary the
+// pull requests the review was correlated with
+func NoteRelatedChanges(ctx context.Context, job *Job) error {
+	if len(job.Related) == 0 {
+		return nil
+	}
+
+	refs := make([]string, 0, len(job.Related))
+	for _, ref := range job.Related {
+		refs = append(refs, ref.String())
+	}
+
+	var summary strings.Builder
+	summary.WriteString(strings.TrimRight(job.Summary, "\n"))
+	fmt.Fprintf(&summary, "\n\n**Reviewed together with:** %s.\n", strings.Join(refs, ", "))
+	job.Summary = strings.TrimLeft(summary.String(), "\n")
+
+	return nil
+}
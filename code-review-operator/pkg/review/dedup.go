@@ -0,0 +1,135 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+)
+
+// DeduplicateComments is a postprocess handler that finds comments an earlier
+// review already posted on the pull request: the operator's comments on the
+// same line made for the same rule or with the same text. They are listed in
+// job.Duplicates and not posted again, but still count towards check runs and
+// gating. Deduplication is optional: if the existing comments cannot be
+// listed, every comment is posted.
+func DeduplicateComments(ctx context.Context, job *Job) error {
+	if job.PullRequest == 0 {
+		return nil
+	}
+
+	posted, err := job.Client.ListReviewComments(ctx, job.Owner, job.Repository, job.PullRequest)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to list existing review comments, posting all comments")
+		return nil
+	}
+	job.PostedComments = posted
+
+	for _, comment := range job.Comments {
+		for _, existing := range posted {
+			if isDuplicate(existing, comment) {
+				job.Duplicates = append(job.Duplicates, comment)
+				break
+			}
+		}
+	}
+	if len(job.Duplicates) > 0 {
+		log.FromContext(ctx).Info("skipping comments already on the pull request", "duplicates", len(job.Duplicates))
+	}
+
+	return nil
+}
+
+// MarkFixedComments is a publish handler that replies to the operator's
+// comments from earlier reviews whose lines have since changed and whose
+// finding was not reported again, noting that it appears to be addressed.
+// Each thread is replied to once. It relies on DeduplicateComments having
+// listed the existing comments; failures are logged and the review continues.
+func MarkFixedComments(ctx context.Context, job *Job) error {
+	logger := log.FromContext(ctx)
+
+	// Threads the operator has already replied to
+	replied := make(map[string]bool)
+	for _, existing := range job.PostedComments {
+		if _, ours := git.ParseCommentMarker(existing.Body); ours && existing.InReplyTo != "" {
+			replied[existing.InReplyTo] = true
+		}
+	}
+
+	marked := 0
+	for _, existing := range job.PostedComments {
+		rule, ours := git.ParseCommentMarker(existing.Body)
+		if !ours || existing.InReplyTo != "" || !existing.Outdated() || replied[existing.ID] || reported(job.Comments, existing.File, rule) {
+			continue
+		}
+
+		body := fmt.Sprintf("This appears to be addressed in %s.\n\n%s", shortSHA(job.CommitSHA), git.CommentMarker(rule, ""))
+		if err := job.Client.ReplyToReviewComment(ctx, job.Owner, job.Repository, job.PullRequest, existing.ID, body); err != nil {
+			logger.Error(err, "unable to mark review comment as fixed", "comment", existing.ID)
+			continue
+		}
+		marked++
+	}
+	if marked > 0 {
+		logger.Info("marked fixed review comments", "fixed", marked)
+	}
+
+	return nil
+}
+
+// CommentsToPost returns the comments of a job to post inline: those not
+// already on the pull request and not over the comment limit
+func CommentsToPost(job *Job) []git.ReviewComment {
+	if len(job.Duplicates) == 0 && len(job.Overflow) == 0 {
+		return job.Comments
+	}
+
+	skipped := make(map[git.ReviewComment]bool, len(job.Duplicates)+len(job.Overflow))
+	for _, comment := range job.Duplicates {
+		skipped[comment] = true
+	}
+	for _, comment := range job.Overflow {
+		skipped[comment] = true
+	}
+	comments := make([]git.ReviewComment, 0, len(job.Comments))
+	for _, comment := range job.Comments {
+		if !skipped[comment] {
+			comments = append(comments, comment)
+		}
+	}
+
+	return comments
+}
+
+// isDuplicate reports whether an existing comment is the operator's comment on
+// the same line, made for the same rule or with the same text
+func isDuplicate(existing git.PostedComment, comment git.ReviewComment) bool {
+	rule, ours := git.ParseCommentMarker(existing.Body)
+	if !ours || existing.InReplyTo != "" || existing.Outdated() {
+		return false
+	}
+	if existing.File != comment.File || existing.Line != comment.Line || existing.Side != string(commentSide(comment)) {
+		return false
+	}
+
+	return rule == comment.Rule || comment.Content != "" && strings.Contains(normalizeText(existing.Body), normalizeText(comment.Content))
+}
+
+// reported reports whether a comment for a rule is made on a file in this review
+func reported(comments []git.ReviewComment, file, rule string) bool {
+	for _, comment := range comments {
+		if comment.File == file && comment.Rule == rule {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeText lowercases text and collapses whitespace for comparison
+func normalizeText(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
@@ -0,0 +1,152 @@
+package review
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/memory"
+	"github.com/Shridhar2104/code-review-operator/pkg/notify"
+	"github.com/Shridhar2104/code-review-operator/pkg/policy"
+	"github.com/Shridhar2104/code-review-operator/pkg/repoconfig"
+)
+
+// Escalation is a finding reported on several consecutive pushes without being fixed
+type Escalation struct {
+	// Comment is the escalated comment, with its raised severity
+	Comment git.ReviewComment
+
+	// Pushes is the number of consecutive pushes the finding was reported on
+	Pushes int
+}
+
+// EscalateFindings returns a postprocess handler that escalates findings
+// reported on several consecutive pushes, as configured by the repository's
+// escalation policy. Escalated findings are raised to the policy severity and
+// listed at the top of the summary; the pull request is labeled and Slack
+// notified if configured. Escalation is optional: failures are logged and the
+// review continues. slack may be nil.
+func EscalateFindings(store memory.Store, slack *notify.SlackClient) Handler {
+	return func(ctx context.Context, job *Job) error {
+		if job.Config == nil || job.Config.Escalation == nil {
+			return nil
+		}
+		escalation := job.Config.Escalation
+		logger := log.FromContext(ctx)
+
+		// Line content identifies a finding even when earlier edits shift its
+		// line number; without a parsed diff, line numbers are used instead
+		files, _ := job.parsedFiles(ctx)
+		fingerprints := make([]string, 0, len(job.Comments))
+		for _, comment := range job.Comments {
+			fingerprints = append(fingerprints, fingerprint(files, comment))
+		}
+
+		var pushes map[string]int
+		err := store.Update(ctx, job.Owner, job.Repository, func(mem *memory.Memory) {
+			pushes = mem.TrackFindings(job.PullRequest, job.CommitSHA, fingerprints, time.Now())
+		})
+		if err != nil {
+			logger.Error(err, "unable to track findings for escalation")
+			return nil
+		}
+
+		threshold := repoconfig.DefaultEscalationPushes
+		if escalation.Pushes != nil {
+			threshold = *escalation.Pushes
+		}
+		for i := range job.Comments {
+			count := pushes[fingerprints[i]]
+			if count < threshold {
+				continue
+			}
+			comment := &job.Comments[i]
+			if policy.SeverityRank(escalation.Severity) > policy.SeverityRank(comment.Severity) {
+				comment.Severity = escalation.Severity
+			}
+			job.Escalations = append(job.Escalations, Escalation{Comment: *comment, Pushes: count})
+		}
+		if len(job.Escalations) == 0 {
+			return nil
+		}
+
+		var summary strings.Builder
+		summary.WriteString("**Unresolved findings from earlier pushes:**\n")
+		summary.WriteString(formatEscalations(job.Escalations, linkedLocation))
+		if job.Summary != "" {
+			summary.WriteString("\n")
+			summary.WriteString(job.Summary)
+		}
+		job.Summary = summary.String()
+
+		if job.DryRun {
+			logger.Info("escalated unresolved findings in dry run", "escalated", len(job.Escalations))
+			return nil
+		}
+
+		if escalation.Label != "" {
+			if err := job.Client.AddLabels(ctx, job.Owner, job.Repository, job.PullRequest, []string{escalation.Label}); err != nil {
+				logger.Error(err, "unable to label pull request with escalated findings", "label", escalation.Label)
+			}
+		}
+
+		if slack != nil && escalation.Notify != nil && *escalation.Notify {
+			text := fmt.Sprintf("%d finding(s) on %s/%s#%d are still unresolved after %d or more pushes:\n%s",
+				len(job.Escalations), job.Owner, job.Repository, job.PullRequest, threshold, formatEscalations(job.Escalations, slackLocation))
+			if err := slack.Post(ctx, text); err != nil {
+				logger.Error(err, "unable to notify Slack of escalated findings")
+			}
+		}
+
+		logger.Info("escalated unresolved findings", "escalated", len(job.Escalations))
+
+		return nil
+	}
+}
+
+// formatEscalations renders escalated findings as a list, formatting their
+// locations with location
+func formatEscalations(escalations []Escalation, location func(git.ReviewComment) string) string {
+	var b strings.Builder
+	for _, escalation := range escalations {
+		comment := escalation.Comment
+		title, _, _ := strings.Cut(comment.Content, "\n")
+		fmt.Fprintf(&b, "- %s **%s** (%s), reported on %d pushes: %s\n",
+			location(comment), comment.Severity, comment.Rule, escalation.Pushes, title)
+	}
+
+	return b.String()
+}
+
+// slackLocation formats the location of a comment with Slack mrkdwn, linking
+// it to the diff when the comment has a permalink
+func slackLocation(comment git.ReviewComment) string {
+	if comment.Link == "" {
+		return fmt.Sprintf("`%s`", comment.Location())
+	}
+
+	return fmt.Sprintf("<%s|%s>", comment.Link, comment.Location())
+}
+
+// fingerprint identifies a finding across pushes by its file, rule and the
+// content of the commented line, falling back to the line number when the
+// line is not part of the diff
+func fingerprint(files []*diff.File, comment git.ReviewComment) string {
+	location := strconv.Itoa(comment.Line)
+	if file := diff.Find(files, comment.File); file != nil {
+		if line := file.Line(commentSide(comment), comment.Line); line != nil {
+			location = strings.Join(strings.Fields(line.Content), " ")
+		}
+	}
+
+	hash := sha256.Sum256([]byte(comment.File + "\x00" + comment.Rule + "\x00" + location))
+	return hex.EncodeToString(hash[:8])
+}
@@ -0,0 +1,109 @@
+package review
+
+import (
+	"context"
+	"path"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+)
+
+// ParseDiff is a filter handler parsing the diff into job.Files once the
+// other filter handlers have removed the files not reviewed. A diff that
+// cannot be parsed is still reviewed by the LLM, but the checks of its files
+// are skipped.
+func ParseDiff(ctx context.Context, job *Job) error {
+	job.parsedFiles(ctx)
+
+	return nil
+}
+
+// parsedFiles returns the files of job.Diff, parsing the diff only if it
+// changed since it was parsed. If it cannot be parsed, the error is logged
+// once and ok is false.
+func (job *Job) parsedFiles(ctx context.Context) (files []*diff.File, ok bool) {
+	if !job.filesParsed || job.filesDiff != job.Diff {
+		files, err := diff.Parse(job.Diff)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "unable to parse diff, skipping the checks of its files")
+			files = nil
+		}
+		job.Files, job.filesDiff, job.filesParsed, job.filesInvalid = files, job.Diff, true, err != nil
+	}
+	if job.filesInvalid {
+		return nil, false
+	}
+
+	return job.Files, true
+}
+
+// setFiles replaces the diff with the given files of it
+func (job *Job) setFiles(files []*diff.File) {
+	var unified strings.Builder
+	for _, file := range files {
+		unified.WriteString(file.String())
+	}
+	job.Diff = unified.String()
+	job.Files, job.filesDiff, job.filesParsed, job.filesInvalid = files, job.Diff, true, false
+}
+
+// FilterPaths is a filter handler that removes binary files and the files
+// excluded by the review options from the diff. When include globs are set,
+// only files matching one are kept; exclude globs win over include globs. A
+// glob without a slash matches file names in any directory, and a glob
+// matching a directory covers everything below it. Hand-edited generated
+// files are always kept.
+func FilterPaths(ctx context.Context, job *Job) error {
+	files, ok := job.parsedFiles(ctx)
+	if !ok {
+		return nil
+	}
+
+	include, exclude := job.Options.Include, job.Options.Exclude
+	var kept []*diff.File
+	excluded := 0
+	for _, file := range files {
+		if slices.Contains(job.HandEdited, file.NewPath) {
+			kept = append(kept, file)
+			continue
+		}
+		included := len(include) == 0 || matchesAny(include, file.NewPath) || matchesAny(include, file.OldPath)
+		if file.Binary || !included || matchesAny(exclude, file.NewPath) || matchesAny(exclude, file.OldPath) {
+			excluded++
+			continue
+		}
+		kept = append(kept, file)
+	}
+	if excluded == 0 {
+		return nil
+	}
+	job.setFiles(kept)
+	log.FromContext(ctx).Info("excluded files from review", "excluded", excluded, "kept", len(files)-excluded)
+
+	return nil
+}
+
+// matchesAny reports whether a path or one of its directories matches any of the globs
+func matchesAny(patterns []string, filePath string) bool {
+	if filePath == "" {
+		return false
+	}
+
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(filePath)); ok {
+				return true
+			}
+		}
+		for p := filePath; p != "." && p != "/"; p = path.Dir(p) {
+			if ok, _ := path.Match(strings.TrimSuffix(pattern, "/"), p); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,229 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+)
+
+// Rules of the feature flag checks
+const (
+	// RuleFlagMissingExpiry flags new feature flags with no plan for their removal
+	RuleFlagMissingExpiry = "feature-flag/missing-expiry"
+
+	// RuleFlagExpired flags changes next to feature flags past their expiry date
+	RuleFlagExpired = "feature-flag/expired"
+
+	// RuleFlagIncompleteCleanup flags feature flags removed from some but not all of their evaluations
+	RuleFlagIncompleteCleanup = "feature-flag/incomplete-cleanup"
+)
+
+// DefaultFlagSeverity is the severity of feature flag findings unless configured otherwise
+const DefaultFlagSeverity = "minor"
+
+// flagMarkerDistance is how many lines above a flag evaluation a removal
+// marker may be; markers on the evaluation's line also count
+const flagMarkerDistance = 2
+
+// DefaultFlagPatterns match flag evaluations with the LaunchDarkly, Unleash
+// and OpenFeature SDKs, capturing the flag key
+var DefaultFlagPatterns = []string{
+	`\.(?:[vV]ariation|[bB]oolVariation|[sS]tringVariation|[iI]ntVariation|[fF]loat64Variation|JSONVariation|jsonVariation)(?:Detail)?(?:Ctx)?\(\s*(?:ctx,\s*)?["']([\w.:-]+)["']`,
+	`\.(?:isEnabled|IsEnabled|is_enabled|getVariant|GetVariant|get_variant)\(\s*["']([\w.:-]+)["']`,
+	`\.(?:getBooleanValue|getStringValue|getNumberValue|getObjectValue|get_boolean_value|get_string_value|BooleanValue|StringValue|IntValue|FloatValue|ObjectValue)\(\s*(?:ctx,\s*)?["']([\w.:-]+)["']`,
+}
+
+var (
+	// flagRemovalTODO matches comments planning a flag's removal
+	flagRemovalTODO = regexp.MustCompile(`(?i)\b(?:TODO|FIXME)\b.*\b(?:remove|removal|delete|clean ?up|retire)\b`)
+
+	// flagExpiry matches expiry metadata and captures its date
+	flagExpiry = regexp.MustCompile(`(?i)\b(?:expires?|expiry|expiration|remove[ _-]?(?:by|after)|sunset)\b\W{0,3}(\d{4}-\d{2}-\d{2})`)
+)
+
+// flagUse is an evaluation of a feature flag in the diff
+type flagUse struct {
+	file string
+	line *diff.Line
+}
+
+// CheckFeatureFlags is a postprocess handler that checks the hygiene of
+// feature flags when the repository's configuration enables it. Flag
+// evaluations are found with the configured patterns of the organization's
+// flag SDK. Flags first evaluated in the change need a removal TODO or an
+// expiry date on or just above one of its evaluations; changes next to flags past their expiry
+// date are flagged as work on a stale flag; and flags whose evaluations the
+// change removes in some places but keeps in others are flagged as an
+// incomplete cleanup.
+func CheckFeatureFlags(ctx context.Context, job *Job) error {
+	if job.Config == nil || job.Config.FeatureFlags == nil {
+		return nil
+	}
+	settings := job.Config.FeatureFlags
+	severity := settings.Severity
+	if severity == "" {
+		severity = DefaultFlagSeverity
+	}
+	patterns, err := compileFlagPatterns(settings.Patterns)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "invalid feature flag pattern, skipping feature flag checks")
+		return nil
+	}
+	files, ok := job.parsedFiles(ctx)
+	if !ok {
+		return nil
+	}
+
+	// Evaluations of each flag in the diff, by kind of line
+	uses := make(map[string]map[diff.LineKind][]flagUse)
+	var keys []string
+	for _, file := range files {
+		if file.Binary {
+			continue
+		}
+		for _, hunk := range file.Hunks {
+			for _, line := range hunk.Lines {
+				for _, key := range flagKeys(patterns, line.Content) {
+					if uses[key] == nil {
+						uses[key] = make(map[diff.LineKind][]flagUse)
+						keys = append(keys, key)
+					}
+					uses[key][line.Kind] = append(uses[key][line.Kind], flagUse{file: file.Path(), line: line})
+				}
+			}
+		}
+	}
+
+	today := time.Now().UTC().Format(time.DateOnly)
+	for _, key := range keys {
+		byKind := uses[key]
+		added, removed, kept := byKind[diff.LineAdded], byKind[diff.LineRemoved], byKind[diff.LineContext]
+
+		switch {
+		case len(added) > 0 && len(removed) == 0 && len(kept) == 0:
+			// A flag new to the diff; it may still be evaluated elsewhere in
+			// the repository, so only flags without any marker are reported
+			if hasFlagMarker(files, added) {
+				continue
+			}
+			use := added[0]
+			job.Comments = append(job.Comments, git.ReviewComment{
+				File:     use.file,
+				Line:     use.line.NewNumber,
+				Severity: severity,
+				Rule:     RuleFlagMissingExpiry,
+				Content: fmt.Sprintf("Feature flag `%s` is added without a plan for its removal. Add a `TODO` "+
+					"to remove it or an expiry date (`expires: YYYY-MM-DD`) on or just above it, so it does not linger "+
+					"after the rollout.", key),
+			})
+		case len(removed) > 0 && len(added) == 0 && len(kept) > 0:
+			use := kept[0]
+			job.Comments = append(job.Comments, git.ReviewComment{
+				File:     use.file,
+				Line:     use.line.NewNumber,
+				Severity: severity,
+				Rule:     RuleFlagIncompleteCleanup,
+				Content: fmt.Sprintf("Feature flag `%s` is removed elsewhere in this change but is still evaluated "+
+					"here. Finish the cleanup, or keep the flag until every evaluation is gone.", key),
+			})
+		}
+
+		for _, use := range append(added, kept...) {
+			if expiry := flagExpiryDate(files, use); expiry != "" && expiry < today {
+				job.Comments = append(job.Comments, git.ReviewComment{
+					File:     use.file,
+					Line:     use.line.NewNumber,
+					Severity: severity,
+					Rule:     RuleFlagExpired,
+					Content: fmt.Sprintf("Feature flag `%s` expired on %s. Remove the flag rather than changing "+
+						"code behind it.", key, expiry),
+				})
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// compileFlagPatterns compiles the flag patterns, or the defaults if there are none
+func compileFlagPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	if len(patterns) == 0 {
+		patterns = DefaultFlagPatterns
+	}
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if re.NumSubexp() < 1 {
+			return nil, fmt.Errorf("pattern %q does not capture the flag key", pattern)
+		}
+		compiled = append(compiled, re)
+	}
+
+	return compiled, nil
+}
+
+// flagKeys returns the keys of the flags evaluated on a line
+func flagKeys(patterns []*regexp.Regexp, content string) []string {
+	var keys []string
+	for _, pattern := range patterns {
+		for _, match := range pattern.FindAllStringSubmatch(content, -1) {
+			if match[1] != "" {
+				keys = append(keys, match[1])
+			}
+		}
+	}
+
+	return keys
+}
+
+// hasFlagMarker reports whether any evaluation has a removal TODO or an
+// expiry date near it
+func hasFlagMarker(files []*diff.File, uses []flagUse) bool {
+	for _, use := range uses {
+		for _, content := range nearbyLines(files, use) {
+			if flagRemovalTODO.MatchString(content) || flagExpiry.MatchString(content) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// flagExpiryDate returns the expiry date near an evaluation, or ""
+func flagExpiryDate(files []*diff.File, use flagUse) string {
+	for _, content := range nearbyLines(files, use) {
+		if match := flagExpiry.FindStringSubmatch(content); match != nil {
+			return match[1]
+		}
+	}
+
+	return ""
+}
+
+// nearbyLines returns an evaluation's line and the lines of the new version
+// shown in the diff up to flagMarkerDistance above it
+func nearbyLines(files []*diff.File, use flagUse) []string {
+	file := diff.Find(files, use.file)
+	if file == nil {
+		return nil
+	}
+	var lines []string
+	for number := use.line.NewNumber - flagMarkerDistance; number <= use.line.NewNumber; number++ {
+		if line := file.Line(diff.SideRight, number); line != nil {
+			lines = append(lines, line.Content)
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,258 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+)
+
+// RuleGeneratedHandEdit flags edits to generated files made without changing their source
+const RuleGeneratedHandEdit = "generated/hand-edit"
+
+// generatedHeaderLines is how many lines at the top of a file are searched for a generated-code marker
+const generatedHeaderLines = 10
+
+// maxGeneratedLookups is how many files a review reads to find their header,
+// for changes that do not show the top of the file
+const maxGeneratedLookups = 100
+
+var (
+	// generatedMarker matches the header comments of generated files:
+	// "Code generated ... DO NOT EDIT." written by protoc-gen-go, mockgen,
+	// controller-gen and other Go generators, and the markers of other
+	// ecosystems' generators
+	generatedMarker = regexp.MustCompile(`(?i)code generated\b.*\bdo not edit|@generated\b|<auto-generated|` +
+		`\bauto-?generated\b.*\bdo not (?:edit|modify)|this file (?:is|was) (?:auto(?:matically)?[ -]?)?generated`)
+
+	// generatorName captures the generator named in a Go generated-code marker
+	generatorName = regexp.MustCompile(`(?i)code generated by ([\w./-]+)`)
+
+	// generatedSource captures the source file recorded in the header of
+	// protoc and mockgen output
+	generatedSource = regexp.MustCompile(`^\s*(?://|#|--|\*)\s*[sS]ource:\s*(\S+)`)
+)
+
+// generatorConfigFiles are files whose change regenerates generated code
+// without a change to its sources, such as a generator version bump
+var generatorConfigFiles = []string{
+	"go.mod", "Makefile", "buf.gen.yaml", "buf.yaml", "buf.work.yaml", ".mockery.yaml", "tools.go",
+	"package.json", "Cargo.toml", "pyproject.toml",
+}
+
+// generatedFile is a changed file carrying a generated-code marker
+type generatedFile struct {
+	file      *diff.File
+	generator string
+	source    string
+}
+
+// FilterGeneratedFiles is a filter handler that finds changed files with a
+// generated-code marker in their header. Files regenerated along with their
+// source, or with a change to the generator's configuration, are removed from
+// the diff. Generated files edited without such a change are flagged as hand
+// edits, which the next regeneration would lose, and kept for review even if
+// excluded by path. It runs before FilterPaths.
+func FilterGeneratedFiles(ctx context.Context, job *Job) error {
+	logger := log.FromContext(ctx)
+	files, ok := job.parsedFiles(ctx)
+	if !ok {
+		return nil
+	}
+
+	lookups := 0
+	var generated []generatedFile
+	for _, file := range files {
+		if file.Binary {
+			continue
+		}
+		header, ok := fileHeader(file)
+		if !ok {
+			if lookups >= maxGeneratedLookups || slices.Contains(documentationExtensions, strings.ToLower(path.Ext(file.NewPath))) {
+				continue
+			}
+			lookups++
+			data, err := job.Client.GetFileContent(ctx, job.Owner, job.Repository, file.NewPath, job.CommitSHA)
+			if err != nil {
+				if !errors.Is(err, git.ErrResourceNotFound) {
+					logger.Error(err, "unable to read file, assuming it is not generated", "file", file.NewPath)
+				}
+				continue
+			}
+			header = firstLines(string(data), generatedHeaderLines)
+		}
+		if g, ok := parseGeneratedHeader(header); ok {
+			g.file = file
+			generated = append(generated, g)
+		}
+	}
+	if len(generated) == 0 {
+		return nil
+	}
+
+	// Files whose change regenerates generated code
+	generatedPaths := make(map[*diff.File]bool, len(generated))
+	for _, g := range generated {
+		generatedPaths[g.file] = true
+	}
+	var sources []string
+	configChanged := false
+	for _, file := range files {
+		if generatedPaths[file] {
+			continue
+		}
+		sources = append(sources, file.Path())
+		if slices.Contains(generatorConfigFiles, path.Base(file.Path())) {
+			configChanged = true
+		}
+	}
+
+	regenerated := make(map[*diff.File]bool, len(generated))
+	var checks []git.ReviewComment
+	for _, g := range generated {
+		if g.file.Status != diff.FileModified || configChanged || sourceChanged(g, sources) {
+			regenerated[g.file] = true
+			continue
+		}
+		job.HandEdited = append(job.HandEdited, g.file.NewPath)
+		if check, ok := handEditFinding(g); ok {
+			checks = append(checks, check)
+		}
+	}
+	job.Checks = append(job.Checks, checks...)
+
+	if len(regenerated) > 0 {
+		var kept []*diff.File
+		for _, file := range files {
+			if !regenerated[file] {
+				kept = append(kept, file)
+			}
+		}
+		job.setFiles(kept)
+	}
+	logger.Info("found changed generated files", "regenerated", len(regenerated), "handEdited", len(job.HandEdited))
+
+	return nil
+}
+
+// fileHeader returns the first lines of a file's new version, or of its old
+// version if it was deleted, when the diff shows them
+func fileHeader(file *diff.File) (string, bool) {
+	if len(file.Hunks) == 0 {
+		return "", false
+	}
+	hunk := file.Hunks[0]
+	deleted := file.Status == diff.FileDeleted
+	if (deleted && hunk.OldStart > 1) || (!deleted && hunk.NewStart > 1) {
+		return "", false
+	}
+
+	var lines []string
+	for _, line := range hunk.Lines {
+		if (deleted && line.Kind == diff.LineAdded) || (!deleted && line.Kind == diff.LineRemoved) {
+			continue
+		}
+		lines = append(lines, line.Content)
+		if len(lines) == generatedHeaderLines {
+			break
+		}
+	}
+
+	return strings.Join(lines, "\n"), true
+}
+
+// firstLines returns the first n lines of a text
+func firstLines(text string, n int) string {
+	lines := strings.SplitN(text, "\n", n+1)
+	if len(lines) > n {
+		lines = lines[:n]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// parseGeneratedHeader reports whether a file header carries a
+// generated-code marker, and returns the generator and source it names
+func parseGeneratedHeader(header string) (generatedFile, bool) {
+	if !generatedMarker.MatchString(header) {
+		return generatedFile{}, false
+	}
+
+	var g generatedFile
+	if match := generatorName.FindStringSubmatch(header); match != nil {
+		g.generator = strings.TrimSuffix(match[1], ".")
+	}
+	for _, line := range strings.Split(header, "\n") {
+		if match := generatedSource.FindStringSubmatch(line); match != nil {
+			g.source = match[1]
+			break
+		}
+	}
+
+	return g, true
+}
+
+// sourceChanged reports whether the change also touches the source of a
+// generated file: the source its header names, or else a file that is not
+// generated in the same directory
+func sourceChanged(g generatedFile, changed []string) bool {
+	for _, p := range changed {
+		if g.source != "" {
+			if p == g.source || strings.HasSuffix(p, "/"+g.source) || strings.HasSuffix(g.source, "/"+p) {
+				return true
+			}
+			continue
+		}
+		if path.Dir(p) == path.Dir(g.file.NewPath) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// handEditFinding returns the finding for a hand-edited generated file, on
+// its first changed line
+func handEditFinding(g generatedFile) (git.ReviewComment, bool) {
+	generator := "a generator"
+	if g.generator != "" {
+		generator = g.generator
+	}
+	source := "its source"
+	if g.source != "" {
+		source = "`" + g.source + "`"
+	}
+	comment := git.ReviewComment{
+		File:     g.file.NewPath,
+		Severity: "major",
+		Rule:     RuleGeneratedHandEdit,
+		Content: fmt.Sprintf("`%s` is generated by %s, but this change edits it without changing %s. "+
+			"Hand edits are lost the next time it is regenerated; change the source or the generator's "+
+			"configuration and regenerate it instead.", g.file.NewPath, generator, source),
+	}
+
+	for _, hunk := range g.file.Hunks {
+		for _, line := range hunk.Lines {
+			switch line.Kind {
+			case diff.LineAdded:
+				comment.Line = line.NewNumber
+				return comment, true
+			case diff.LineRemoved:
+				if comment.Line == 0 {
+					comment.Line = line.OldNumber
+					comment.Side = string(diff.SideLeft)
+				}
+			}
+		}
+	}
+
+	return comment, comment.Line > 0
+}
@@ -0,0 +1,127 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/policy"
+	"github.com/Shridhar2104/code-review-operator/pkg/repoconfig"
+)
+
+// DeploymentTarget is an Argo CD Application or Flux Kustomization that
+// deploys files the pull request changes
+type DeploymentTarget struct {
+	// Kind is Application or Kustomization
+	Kind string
+
+	// Name and Namespace identify the Application or Kustomization
+	Name      string
+	Namespace string
+
+	// Project is the Argo CD project of an Application
+	Project string
+
+	// Critical is the number of critical findings in the files it deploys
+	Critical int
+}
+
+// String names the target, such as Argo CD Application `argocd/payments`
+func (t DeploymentTarget) String() string {
+	kind := "Flux " + t.Kind
+	if t.Kind == repoconfig.GitOpsApplication {
+		kind = "Argo CD " + t.Kind
+	}
+	name := t.Name
+	if t.Namespace != "" {
+		name = t.Namespace + "/" + t.Name
+	}
+
+	return fmt.Sprintf("%s `%s`", kind, name)
+}
+
+// AnnotateDeploymentTargets is a postprocess handler for GitOps
+// repositories. It notes on every comment the Application or Kustomization
+// deploying the commented file, as mapped by the repository's gitops
+// targets, and lists the targets the pull request changes in the summary with
+// their critical findings. It runs after findings are escalated, so that the
+// critical findings are final.
+func AnnotateDeploymentTargets(ctx context.Context, job *Job) error {
+	if job.Config == nil || job.Config.GitOps == nil || len(job.Config.GitOps.Targets) == 0 {
+		return nil
+	}
+	targets := job.Config.GitOps.Targets
+
+	files, ok := job.parsedFiles(ctx)
+	if !ok {
+		return nil
+	}
+	index := map[string]int{}
+	add := func(target DeploymentTarget) int {
+		if i, ok := index[target.String()]; ok {
+			return i
+		}
+		index[target.String()] = len(job.DeploymentTargets)
+		job.DeploymentTargets = append(job.DeploymentTargets, target)
+		return len(job.DeploymentTargets) - 1
+	}
+	for _, file := range files {
+		if target, ok := deploymentTarget(targets, file.Path()); ok {
+			add(target)
+		}
+	}
+
+	for i, comment := range job.Comments {
+		target, ok := deploymentTarget(targets, comment.File)
+		if !ok {
+			continue
+		}
+		t := add(target)
+		if comment.Severity == policy.SeverityCritical {
+			job.DeploymentTargets[t].Critical++
+		}
+		job.Comments[i].Content += fmt.Sprintf("\n\n_Deployed by %s._", target)
+	}
+	if len(job.DeploymentTargets) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(job.DeploymentTargets))
+	for _, target := range job.DeploymentTargets {
+		switch target.Critical {
+		case 0:
+			names = append(names, target.String())
+		case 1:
+			names = append(names, target.String()+" (1 critical finding)")
+		default:
+			names = append(names, fmt.Sprintf("%s (%d critical findings)", target, target.Critical))
+		}
+	}
+	var summary strings.Builder
+	summary.WriteString(strings.TrimRight(job.Summary, "\n"))
+	fmt.Fprintf(&summary, "\n\n**Deployment targets:** %s.\n", strings.Join(names, ", "))
+	job.Summary = strings.TrimLeft(summary.String(), "\n")
+
+	return nil
+}
+
+// deploymentTarget returns the target deploying a file: the first target
+// whose path matches it
+func deploymentTarget(targets []repoconfig.GitOpsTarget, file string) (DeploymentTarget, bool) {
+	for _, target := range targets {
+		name, ok := target.Match(file)
+		if !ok {
+			continue
+		}
+		deployment := DeploymentTarget{Kind: target.Kind, Name: name, Namespace: target.Namespace}
+		if target.Kind == repoconfig.GitOpsApplication {
+			deployment.Project = target.Project
+			if deployment.Project == "" {
+				deployment.Project = "default"
+			}
+		}
+		return deployment, true
+	}
+
+	return DeploymentTarget{}, false
+}
@@ -0,0 +1,114 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+)
+
+// FetchDiff fetches the diff of the pull request or commit. For incremental
+// reviews, only the changes since the base commit are fetched.
+func FetchDiff(ctx context.Context, job *Job) error {
+	// Review only what changed since the last review, if there was one
+	if job.BaseCommitSHA != "" {
+		diff, err := job.Client.GetCompareDiff(ctx, job.Owner, job.Repository, job.BaseCommitSHA, job.CommitSHA)
+		if err == nil {
+			job.Diff = diff
+			return nil
+		}
+		if !errors.Is(err, git.ErrResourceNotFound) {
+			return fmt.Errorf("error getting diff since %s: %w", job.BaseCommitSHA, err)
+		}
+
+		// The base is gone after a force push, so review the whole pull request
+		log.FromContext(ctx).Info("last reviewed commit no longer exists, reviewing the full diff", "base", job.BaseCommitSHA)
+		job.BaseCommitSHA = ""
+	}
+
+	diff, err := job.Client.GetDiff(ctx, job.Owner, job.Repository, job.PullRequest, job.CommitSHA)
+	if err != nil {
+		return fmt.Errorf("error getting diff: %w", err)
+	}
+	job.Diff = diff
+
+	return nil
+}
+
+// ReviewWithLLM returns a handler that reviews the diff with the given LLM
+// client, or with the job's own client if it has one
+func ReviewWithLLM(client llm.Client) Handler {
+	return func(ctx context.Context, job *Job) error {
+		reviewer := client
+		if job.LLM != nil {
+			reviewer = job.LLM
+		}
+
+		result, err := llm.ReviewStream(ctx, reviewer, job.Diff, job.Options, streamProgress(job, &sync.Mutex{}))
+		if err != nil {
+			return fmt.Errorf("error reviewing code: %w", err)
+		}
+		job.Result = result
+		UpdateProgress(ctx, job, job.progressTotal)
+
+		return nil
+	}
+}
+
+// DecideReview sets the review event from the job's decision policy
+func DecideReview(ctx context.Context, job *Job) error {
+	job.Decision = git.ReviewDecisionComment
+	if job.DecisionPolicy == nil {
+		return nil
+	}
+
+	decision, reason := job.DecisionPolicy.Decide(job.Comments)
+	if job.LintOnly && decision == git.ReviewDecisionApprove {
+		// Passing the deterministic checks alone does not make a change safe to merge
+		decision, reason = git.ReviewDecisionComment, "lint-only reviews do not approve"
+	}
+	job.Decision = decision
+	log.FromContext(ctx).Info("review decision", "decision", decision, "reason", reason)
+
+	return nil
+}
+
+// ConvertComments converts the LLM result into the comments and summary to publish
+func ConvertComments(ctx context.Context, job *Job) error {
+	if job.Result == nil {
+		return nil
+	}
+
+	for _, comment := range job.Result.Comments {
+		job.Comments = append(job.Comments, git.ReviewComment{
+			File:      comment.File,
+			Line:      comment.Line,
+			StartLine: comment.StartLine,
+			Content:   comment.Content,
+			Severity:  comment.Severity,
+			Rule:      comment.Rule,
+
+			SuggestedCode: comment.SuggestedCode,
+		})
+	}
+	job.Summary = job.Result.Summary
+	if job.BaseCommitSHA != "" {
+		job.Summary = fmt.Sprintf("_Reviewed the changes since %s._\n\n%s", shortSHA(job.BaseCommitSHA), job.Summary)
+	}
+
+	return nil
+}
+
+// shortSHA abbreviates a commit SHA for display
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+
+	return sha
+}
@@ -0,0 +1,168 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+)
+
+const (
+	// maxHints is the number of focus hints taken from a description
+	maxHints = 5
+
+	// maxHintLength is the length in characters hints are cut to
+	maxHintLength = 160
+)
+
+var (
+	// hintPatterns match the phrases authors ask for a focused review with;
+	// the first group is the area to focus on
+	hintPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\b(?:focus|concentrate)\s+(?:your\s+review\s+|mostly\s+|mainly\s+|especially\s+)?on\s+(.+)`),
+		regexp.MustCompile(`(?i)\bpay\s+(?:special\s+|extra\s+|close\s+|particular\s+)?attention\s+to\s+(.+)`),
+		regexp.MustCompile(`(?i)\b(?:close|careful|hard|closer)\s+look\s+at\s+(.+)`),
+		regexp.MustCompile(`(?i)\b(?:look|check)\s+(?:closely|carefully)\s+at\s+(.+)`),
+		regexp.MustCompile(`(?i)\b(?:main|biggest|primary)\s+concerns?\s+(?:is|are)\s+(.+)`),
+		regexp.MustCompile(`(?i)^(?:review\s+)?focus\s*:\s*(.+)`),
+	}
+
+	// negatedHint matches the end of text negating a hint phrase after it,
+	// as in "no need to focus on"
+	negatedHint = regexp.MustCompile(`(?i)(?:\bnot|n't|\bno\s+need\s+to|\bnever)\s+(?:\w+\s+)?$`)
+
+	// hintHeading matches a description heading introducing a list of areas
+	// to focus on, such as "## Review focus" or "### Notes for reviewers"
+	hintHeading = regexp.MustCompile(`(?i)^#+\s*(?:review(?:er)?s?\s+)?(?:focus|notes\s+for\s+reviewers?|where\s+to\s+look)\b`)
+
+	// htmlComment matches HTML comments, which hold the instructions of pull request templates
+	htmlComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+	// listItem matches the marker of a markdown list item
+	listItem = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+`)
+)
+
+// ParseHints returns the areas a pull request description asks reviewers to
+// focus on, such as "the retry logic" for "please focus on the retry logic",
+// and the items listed under a "Review focus" heading. Template comments,
+// quotes and code blocks are ignored.
+func ParseHints(description string) []string {
+	description = htmlComment.ReplaceAllString(description, "")
+
+	var hints []string
+	add := func(hint string) {
+		hint = cleanHint(hint)
+		if hint == "" || len(hints) >= maxHints {
+			return
+		}
+		for _, existing := range hints {
+			if strings.EqualFold(existing, hint) {
+				return
+			}
+		}
+		hints = append(hints, hint)
+	}
+
+	inCode, underHeading := false, false
+	for _, line := range strings.Split(description, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			inCode = !inCode
+			continue
+		}
+		if inCode || strings.HasPrefix(line, ">") {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			underHeading = hintHeading.MatchString(line)
+			continue
+		}
+		if underHeading && listItem.MatchString(line) {
+			add(listItem.ReplaceAllString(line, ""))
+			continue
+		}
+
+		text := listItem.ReplaceAllString(line, "")
+		for _, pattern := range hintPatterns {
+			match := pattern.FindStringSubmatchIndex(text)
+			if match == nil || negatedHint.MatchString(text[:match[0]]) {
+				continue
+			}
+			add(text[match[2]:match[3]])
+			break
+		}
+	}
+
+	return hints
+}
+
+// cleanHint cuts a hint at the end of its sentence and trims it
+func cleanHint(hint string) string {
+	if i := strings.IndexAny(hint, "!?;"); i >= 0 {
+		hint = hint[:i]
+	}
+	if i := strings.Index(hint, ". "); i >= 0 {
+		hint = hint[:i]
+	}
+	hint = strings.Trim(strings.TrimSpace(hint), ".,:*_\"'")
+	if len(hint) > maxHintLength {
+		hint = hint[:maxHintLength]
+		if i := strings.LastIndex(hint, " "); i > 0 {
+			hint = hint[:i]
+		}
+		hint += "…"
+	}
+
+	return strings.TrimSpace(hint)
+}
+
+// FetchDescription is a fetch handler reading the description, labels and
+// target branch of the pull request under review. Reviews without a pull request are skipped, and
+// failures are logged: the review goes on without the description.
+func FetchDescription(ctx context.Context, job *Job) error {
+	if job.Client == nil || job.PullRequest == 0 {
+		return nil
+	}
+
+	pr, err := git.GetPullRequest(ctx, job.Client, job.Owner, job.Repository, job.PullRequest)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to read the pull request description")
+		return nil
+	}
+	job.Description = pr.Body
+	job.Labels = pr.Labels
+	job.BaseBranch = pr.BaseBranch
+
+	return nil
+}
+
+// FocusHints is an enrich handler that reads the focus hints of the pull
+// request description into the options sent to the LLM, which prioritizes them
+func FocusHints(ctx context.Context, job *Job) error {
+	job.Options.Hints = ParseHints(job.Description)
+	if len(job.Options.Hints) > 0 {
+		log.FromContext(ctx).Info("found focus hints in the pull request description", "hints", job.Options.Hints)
+	}
+
+	return nil
+}
+
+// AcknowledgeHints is a postprocess handler noting in the summary the focus
+// hints the review prioritized
+func AcknowledgeHints(ctx context.Context, job *Job) error {
+	if job.Result == nil || len(job.Options.Hints) == 0 {
+		return nil
+	}
+
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "**Author focus:** as asked in the pull request description, this review prioritized %s.\n\n",
+		strings.Join(job.Options.Hints, "; "))
+	summary.WriteString(job.Summary)
+	job.Summary = strings.TrimRight(summary.String(), "\n")
+
+	return nil
+}
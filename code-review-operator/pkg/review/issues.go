@@ -0,0 +1,119 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// maxLinkedIssues is the number of linked issues read into the prompt
+	maxLinkedIssues = 3
+
+	// maxIssueLength is the length in characters issue descriptions are cut to
+	maxIssueLength = 2000
+)
+
+// linkedIssuePattern matches a closing keyword followed by an issue
+// reference: #123, owner/repo#123 or the URL of an issue
+var linkedIssuePattern = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?)\s*:?\s+` +
+	`(?:([\w.-]+)/([\w.-]+))?#(\d+)|` +
+	`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?)\s*:?\s+https?://[^/\s]+/([\w.-]+)/([\w.-]+)/issues/(\d+)`)
+
+// IssueRef identifies an issue
+type IssueRef struct {
+	Owner      string
+	Repository string
+	Number     int
+}
+
+// String formats the reference as owner/repo#number
+func (r IssueRef) String() string {
+	return fmt.Sprintf("%s/%s#%d", r.Owner, r.Repository, r.Number)
+}
+
+// ParseLinkedIssues returns the issues a pull request description says the
+// change resolves, with GitHub's closing keywords such as "closes #123",
+// "fixes owner/repo#45" or "resolves" followed by the URL of an issue.
+// References without a repository are in owner/repo. HTML comments, which
+// hold the instructions of pull request templates, are ignored.
+func ParseLinkedIssues(description, owner, repo string) []IssueRef {
+	description = htmlComment.ReplaceAllString(description, "")
+
+	var refs []IssueRef
+	for _, match := range linkedIssuePattern.FindAllStringSubmatch(description, -1) {
+		ref := IssueRef{Owner: owner, Repository: repo}
+		number := match[3]
+		switch {
+		case match[6] != "":
+			ref.Owner, ref.Repository, number = match[4], match[5], match[6]
+		case match[1] != "":
+			ref.Owner, ref.Repository = match[1], match[2]
+		}
+		ref.Number, _ = strconv.Atoi(number)
+		if ref.Number <= 0 || containsRef(refs, ref) {
+			continue
+		}
+		refs = append(refs, ref)
+		if len(refs) == maxLinkedIssues {
+			break
+		}
+	}
+
+	return refs
+}
+
+// containsRef reports whether refs holds ref, comparing names case-insensitively
+func containsRef(refs []IssueRef, ref IssueRef) bool {
+	for _, r := range refs {
+		if r.Number == ref.Number && strings.EqualFold(r.Owner, ref.Owner) && strings.EqualFold(r.Repository, ref.Repository) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LinkedIssues is an enrich handler that reads the issues the pull request
+// says it resolves into the options sent to the LLM, so the review can
+// judge whether the change addresses them. Issues that cannot be read are
+// logged and left out.
+func LinkedIssues(ctx context.Context, job *Job) error {
+	refs := ParseLinkedIssues(job.Description, job.Owner, job.Repository)
+	if len(refs) == 0 {
+		return nil
+	}
+	logger := log.FromContext(ctx)
+
+	var issues strings.Builder
+	for _, ref := range refs {
+		if ref.Number == job.PullRequest && strings.EqualFold(ref.Owner, job.Owner) && strings.EqualFold(ref.Repository, job.Repository) {
+			continue
+		}
+		issue, err := job.Client.GetIssue(ctx, ref.Owner, ref.Repository, ref.Number)
+		if err != nil {
+			logger.Error(err, "unable to read linked issue", "issue", ref.String())
+			continue
+		}
+
+		body := strings.TrimSpace(htmlComment.ReplaceAllString(issue.Body, ""))
+		if len(body) > maxIssueLength {
+			body = body[:maxIssueLength] + "\n... [truncated]"
+		}
+		fmt.Fprintf(&issues, "Issue %s (%s): %s\n", ref, issue.State, issue.Title)
+		if body != "" {
+			fmt.Fprintf(&issues, "%s\n", body)
+		}
+		issues.WriteString("\n")
+	}
+	job.Options.Issues = strings.TrimSpace(issues.String())
+	if job.Options.Issues != "" {
+		logger.Info("read linked issues", "issues", len(refs))
+	}
+
+	return nil
+}
@@ -0,0 +1,304 @@
+package review
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+)
+
+// Rules of the Kubernetes profile
+const (
+	// RulePrivilegedContainer flags privileged containers, privilege escalation and host namespaces
+	RulePrivilegedContainer = "k8s-policy/privileged-container"
+
+	// RuleMissingResourceLimits flags containers without resource limits
+	RuleMissingResourceLimits = "k8s-policy/missing-resource-limits"
+
+	// RuleK8sLatestTag flags images referenced by the latest tag or no tag
+	RuleK8sLatestTag = "k8s-policy/latest-tag"
+
+	// RuleHostPath flags hostPath volumes
+	RuleHostPath = "k8s-policy/host-path"
+
+	// RuleRBACWildcard flags RBAC rules granting every verb, resource or API group
+	RuleRBACWildcard = "k8s-policy/rbac-wildcard"
+)
+
+// kubernetesRules are the rules of the Kubernetes profile, as sent to the LLM
+var kubernetesRules = []string{
+	RulePrivilegedContainer + ": containers must not be privileged, allow privilege escalation or share the host's network, PID or IPC namespace",
+	RuleMissingResourceLimits + ": every container needs CPU and memory requests and limits",
+	RuleK8sLatestTag + ": pin every image to a version tag or digest, in manifests, Helm values and Kustomize image overrides",
+	RuleHostPath + ": avoid hostPath volumes, which expose the node's file system to the pod",
+	RuleRBACWildcard + ": RBAC rules must list the verbs, resources and API groups they need instead of *",
+}
+
+// kubernetesFileKind is the kind of a Kubernetes configuration file
+type kubernetesFileKind string
+
+const (
+	kubernetesManifest      kubernetesFileKind = "manifest"
+	kubernetesHelmTemplate  kubernetesFileKind = "helm-template"
+	kubernetesHelmValues    kubernetesFileKind = "helm-values"
+	kubernetesKustomization kubernetesFileKind = "kustomization"
+)
+
+var (
+	// privilegedSetting matches settings granting a container the host's privileges
+	privilegedSetting = regexp.MustCompile(`^\s*-?\s*(privileged|allowPrivilegeEscalation|hostNetwork|hostPID|hostIPC):\s*["']?true["']?\s*(?:#.*)?$`)
+
+	// hostPathVolume matches hostPath volume sources
+	hostPathVolume = regexp.MustCompile(`^\s*-?\s*hostPath:`)
+
+	// imageReference matches image references and captures the image
+	imageReference = regexp.MustCompile(`^\s*-?\s*image:\s*["']?([^"'\s#]+)`)
+
+	// latestTagSetting matches Helm values and Kustomize image overrides selecting the latest tag
+	latestTagSetting = regexp.MustCompile(`^\s*-?\s*(tag|newTag):\s*["']?latest["']?\s*(?:#.*)?$`)
+
+	// rbacWildcardFlow matches RBAC rule lists in flow style holding a wildcard
+	rbacWildcardFlow = regexp.MustCompile(`^\s*-?\s*(verbs|resources|apiGroups):\s*\[[^\]]*["']?\*["']?`)
+
+	// wildcardItem matches a block-style list item holding a wildcard
+	wildcardItem = regexp.MustCompile(`^\s*-\s*["']?\*["']?\s*(?:#.*)?$`)
+
+	// yamlKey matches a YAML mapping key and captures its indentation and name
+	yamlKey = regexp.MustCompile(`^(\s*)(?:-\s+)?([\w.-]+):`)
+)
+
+// CheckKubernetesFiles is an enrich handler running the Kubernetes profile on
+// changed manifests, Helm templates and values, and Kustomize files: added
+// lines are checked for privileged containers, containers without resource
+// limits, images pulled by the latest tag, hostPath volumes and RBAC
+// wildcards. As with the container profile, the findings are listed in the
+// prompt for the LLM to explain, and MergeChecks posts them.
+func CheckKubernetesFiles(ctx context.Context, job *Job) error {
+	files, ok := job.parsedFiles(ctx)
+	if !ok {
+		return nil
+	}
+
+	reviewed := false
+	var checks []git.ReviewComment
+	for _, file := range files {
+		if file.Binary || !isYAML(file.NewPath) {
+			continue
+		}
+		added := addedLines(file)
+		if len(added) == 0 {
+			continue
+		}
+		data, err := job.Client.GetFileContent(ctx, job.Owner, job.Repository, file.NewPath, job.CommitSHA)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "unable to fetch file, skipping Kubernetes checks", "file", file.NewPath)
+			continue
+		}
+		kind, ok := kubernetesFileKindOf(file.NewPath, string(data))
+		if !ok {
+			continue
+		}
+		reviewed = true
+		checks = append(checks, checkKubernetes(file.NewPath, kind, string(data), added)...)
+	}
+	if reviewed {
+		addChecks(job, kubernetesRules, checks)
+	}
+
+	return nil
+}
+
+// isYAML reports whether a file is a YAML file
+func isYAML(filePath string) bool {
+	ext := path.Ext(filePath)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// kubernetesFileKindOf returns the kind of a Kubernetes configuration file,
+// or false for other YAML files
+func kubernetesFileKindOf(filePath, content string) (kubernetesFileKind, bool) {
+	name := path.Base(filePath)
+	switch {
+	case strings.TrimSuffix(strings.TrimSuffix(name, ".yaml"), ".yml") == "kustomization":
+		return kubernetesKustomization, true
+	case strings.HasPrefix(name, "values") && strings.Contains(content, "image"):
+		return kubernetesHelmValues, true
+	case strings.Contains("/"+filePath, "/templates/") && strings.Contains(content, "{{"):
+		return kubernetesHelmTemplate, true
+	}
+
+	apiVersion, kind := false, false
+	for _, line := range strings.Split(content, "\n") {
+		apiVersion = apiVersion || strings.HasPrefix(line, "apiVersion:")
+		kind = kind || strings.HasPrefix(line, "kind:")
+	}
+
+	return kubernetesManifest, apiVersion && kind
+}
+
+// checkKubernetes runs the Kubernetes checks on a file, reporting findings on added lines
+func checkKubernetes(file string, kind kubernetesFileKind, content string, added map[int]string) []git.ReviewComment {
+	var comments []git.ReviewComment
+	report := func(line int, severity, rule, content string) {
+		if _, ok := added[line]; ok {
+			comments = append(comments, git.ReviewComment{File: file, Line: line, Severity: severity, Rule: rule, Content: content})
+		}
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		number := i + 1
+		if _, ok := added[number]; !ok {
+			continue
+		}
+		if match := privilegedSetting.FindStringSubmatch(line); match != nil {
+			report(number, "major", RulePrivilegedContainer, fmt.Sprintf("`%s: true` gives the pod the host's privileges, "+
+				"so a compromised container can take over the node. Remove it or set it to `false`.", match[1]))
+		}
+		if hostPathVolume.MatchString(line) {
+			report(number, "major", RuleHostPath, "`hostPath` mounts the node's file system into the pod. "+
+				"Use a `persistentVolumeClaim`, `configMap`, `secret` or `emptyDir` volume instead.")
+		}
+		if match := imageReference.FindStringSubmatch(line); match != nil && !strings.Contains(match[1], "{{") && unpinnedImage(match[1]) {
+			report(number, "minor", RuleK8sLatestTag, fmt.Sprintf("Image `%s` is not pinned, so the workload can change "+
+				"on any restart. Pin a version tag or digest.", match[1]))
+		}
+		if match := latestTagSetting.FindStringSubmatch(line); match != nil && kind != kubernetesManifest {
+			report(number, "minor", RuleK8sLatestTag, fmt.Sprintf("`%s: latest` deploys whatever image was pushed last. "+
+				"Pin a version tag or digest.", match[1]))
+		}
+		if match := rbacWildcardFlow.FindStringSubmatch(line); match != nil {
+			report(number, "major", RuleRBACWildcard, fmt.Sprintf("`%s` grants `*`. List the %s the role needs instead.", match[1], match[1]))
+		} else if wildcardItem.MatchString(line) {
+			if key := parentKey(lines, i); key == "verbs" || key == "resources" || key == "apiGroups" {
+				report(number, "major", RuleRBACWildcard, fmt.Sprintf("`%s` grants `*`. List the %s the role needs instead.", key, key))
+			}
+		}
+	}
+
+	if kind == kubernetesManifest {
+		for _, container := range containersWithoutLimits(content) {
+			for line := container.start; line <= container.end; line++ {
+				if _, ok := added[line]; ok {
+					report(line, "minor", RuleMissingResourceLimits, fmt.Sprintf("Container `%s` has no CPU and memory limits, "+
+						"so it can starve the other pods on its node. Set `resources.requests` and `resources.limits`.", container.name))
+					break
+				}
+			}
+		}
+	}
+
+	return comments
+}
+
+// parentKey returns the key of the mapping entry holding the list item on a line
+func parentKey(lines []string, item int) string {
+	indent := len(lines[item]) - len(strings.TrimLeft(lines[item], " "))
+	for i := item - 1; i >= 0; i-- {
+		match := yamlKey.FindStringSubmatch(lines[i])
+		if match == nil {
+			continue
+		}
+		// A key indented like the item holds it, unless it starts a sibling item
+		keyIndent, listItem := len(match[1]), strings.HasPrefix(strings.TrimSpace(lines[i]), "-")
+		if keyIndent < indent || (keyIndent == indent && !listItem) {
+			return match[2]
+		}
+	}
+
+	return ""
+}
+
+// containerSpan is a container of a manifest and the lines it spans
+type containerSpan struct {
+	name       string
+	start, end int
+}
+
+// containersWithoutLimits returns the containers of a manifest's workloads
+// that set neither a CPU nor a memory limit. Manifests that do not parse are
+// skipped.
+func containersWithoutLimits(content string) []containerSpan {
+	var spans []containerSpan
+	decoder := yaml.NewDecoder(bytes.NewReader([]byte(content)))
+	for {
+		var document yaml.Node
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return spans
+		}
+		walkYAML(&document, func(key string, value *yaml.Node) {
+			if (key != "containers" && key != "initContainers") || value.Kind != yaml.SequenceNode {
+				return
+			}
+			for _, container := range value.Content {
+				if container.Kind != yaml.MappingNode || hasLimits(container) {
+					continue
+				}
+				name := "unnamed"
+				if n := mappingValue(container, "name"); n != nil {
+					name = n.Value
+				}
+				start, end := nodeSpan(container)
+				spans = append(spans, containerSpan{name: name, start: start, end: end})
+			}
+		})
+	}
+
+	return spans
+}
+
+// walkYAML calls fn with every key and value of the mappings in a YAML tree
+func walkYAML(node *yaml.Node, fn func(key string, value *yaml.Node)) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			fn(node.Content[i].Value, node.Content[i+1])
+		}
+	}
+	for _, child := range node.Content {
+		walkYAML(child, fn)
+	}
+}
+
+// mappingValue returns the value of a key in a YAML mapping, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// hasLimits reports whether a container sets a CPU or memory limit
+func hasLimits(container *yaml.Node) bool {
+	limits := mappingValue(mappingValue(container, "resources"), "limits")
+	return mappingValue(limits, "cpu") != nil || mappingValue(limits, "memory") != nil
+}
+
+// nodeSpan returns the first and last lines of a YAML node and its children
+func nodeSpan(node *yaml.Node) (int, int) {
+	start, end := node.Line, node.Line
+	for _, child := range node.Content {
+		childStart, childEnd := nodeSpan(child)
+		start, end = min(start, childStart), max(end, childEnd)
+	}
+
+	return start, end
+}
@@ -0,0 +1,214 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm/prompt"
+	"github.com/Shridhar2104/code-review-operator/pkg/repoconfig"
+)
+
+// Route is how the changed files of a language are reviewed when they are
+// reviewed apart from the rest of the diff
+type Route struct {
+	// LLM reviews the files (defaults to the job's client)
+	LLM llm.Client
+
+	// Prompt replaces the job's custom prompt for the files when set
+	Prompt *prompt.Template
+
+	// Rules are applied to the files in addition to the job's rules
+	Rules []string
+}
+
+// LanguageRoutes returns the routes of the languages configured in a
+// repository's languages, keyed by language, with their rules. Callers that
+// can create clients and read prompts fill in the route's LLM and Prompt.
+func LanguageRoutes(config *repoconfig.Config) map[string]Route {
+	if config == nil || len(config.Languages) == 0 {
+		return nil
+	}
+
+	routes := make(map[string]Route, len(config.Languages))
+	for language, route := range config.Languages {
+		routes[prompt.Normalize(language)] = Route{Rules: route.Rules}
+	}
+
+	return routes
+}
+
+// RouteLanguages returns a review handler that splits the diff by the
+// language of its files and reviews the files of every language with a
+// route in the job's Routes apart, with the route's client, prompt and
+// rules, by calling next with a job holding only those files. The other files
+// are reviewed as usual. The results are merged, and their summaries
+// consolidated by the reviewing client if it implements llm.Summarizer.
+func RouteLanguages(client llm.Client, next Handler) Handler {
+	return func(ctx context.Context, job *Job) error {
+		if len(job.Routes) == 0 {
+			return next(ctx, job)
+		}
+		files, ok := job.parsedFiles(ctx)
+		if !ok {
+			return next(ctx, job)
+		}
+
+		parts := partitionLanguages(files, job.Routes)
+		if len(parts) == 1 && parts[0].language == "" {
+			return next(ctx, job)
+		}
+		log.FromContext(ctx).Info("routing languages", "languages", routedLanguages(parts))
+
+		results := make([]*llm.ReviewResult, len(parts))
+		done := 0
+		for i, part := range parts {
+			routed := routeJob(job, part, done)
+			err := next(ctx, routed)
+			results[i] = routed.Result
+			if err != nil {
+				// Keep the languages reviewed before the review was cancelled so they can still be published
+				if ctx.Err() != nil {
+					reviewed, merged, _ := mergeResults(results)
+					if reviewed > 0 {
+						merged.Summary = fmt.Sprintf("**Partial review:** the review was stopped after %d of %d languages of the diff. "+
+							"Findings cover only those languages.\n\n%s", reviewed, len(parts), merged.Summary)
+						job.Result = merged
+						job.Partial = true
+					}
+				}
+				return err
+			}
+			done += len(part.files)
+			UpdateProgress(ctx, job, done)
+		}
+
+		_, merged, summaries := mergeResults(results)
+		reviewer := client
+		if job.LLM != nil {
+			reviewer = job.LLM
+		}
+		if summarizer, ok := reviewer.(llm.Summarizer); ok && len(summaries) > 1 {
+			summary, err := summarizeReview(ctx, summarizer, summaries, merged.Comments, job.Options,
+				DefaultSummaryTokens, DefaultChunkConcurrency)
+			if err != nil {
+				return fmt.Errorf("error summarizing review: %w", err)
+			}
+			merged.Summary = summary
+		}
+		job.Result = merged
+
+		return nil
+	}
+}
+
+// languagePart is the part of a diff reviewed with the route of a language;
+// language is empty for the files without a route
+type languagePart struct {
+	language string
+	files    []*diff.File
+}
+
+// partitionLanguages groups the files of a diff by routed language, the
+// files without a route first, then the routed languages in lexical order
+func partitionLanguages(files []*diff.File, routes map[string]Route) []languagePart {
+	byLanguage := make(map[string][]*diff.File)
+	for _, file := range files {
+		language := prompt.FileLanguage(file)
+		if _, ok := routes[language]; !ok {
+			language = ""
+		}
+		byLanguage[language] = append(byLanguage[language], file)
+	}
+
+	languages := make([]string, 0, len(byLanguage))
+	for language := range byLanguage {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	parts := make([]languagePart, 0, len(languages))
+	for _, language := range languages {
+		parts = append(parts, languagePart{language: language, files: byLanguage[language]})
+	}
+
+	return parts
+}
+
+// routedLanguages lists the routed languages of a partitioned diff
+func routedLanguages(parts []languagePart) []string {
+	var languages []string
+	for _, part := range parts {
+		if part.language != "" {
+			languages = append(languages, part.language)
+		}
+	}
+
+	return languages
+}
+
+// routeJob creates the job reviewing a part of the diff. It reports its
+// progress to the job's OnProgress, offset by the files already reviewed;
+// the job's check run is updated once the part is reviewed.
+func routeJob(job *Job, part languagePart, offset int) *Job {
+	routed := *job
+	routed.setFiles(part.files)
+	routed.Result = nil
+	routed.Partial = false
+	routed.ReportProgress = false
+	routed.ProgressCheckRunID = ""
+	routed.progressTotal = len(part.files)
+	routed.progressDone = 0
+	if job.OnProgress != nil {
+		routed.OnProgress = func(event ReviewEvent) {
+			event.FilesReviewed += offset
+			event.FilesTotal = job.progressTotal
+			job.OnProgress(event)
+		}
+	}
+	if part.language == "" {
+		return &routed
+	}
+
+	route := job.Routes[part.language]
+	if route.LLM != nil {
+		routed.LLM = route.LLM
+	}
+	if route.Prompt != nil {
+		routed.Options.Prompt = route.Prompt
+	}
+	routed.Options.Rules = append(slices.Clip(job.Options.Rules), route.Rules...)
+	routed.Options.Language = part.language
+
+	return &routed
+}
+
+// DetectLanguages is a postprocess handler recording on every comment the
+// language of the commented file. It runs once comments are anchored to the
+// diff, so that they name the file's path in the new version.
+func DetectLanguages(ctx context.Context, job *Job) error {
+	if len(job.Comments) == 0 {
+		return nil
+	}
+	files, ok := job.parsedFiles(ctx)
+	if !ok {
+		return nil
+	}
+
+	languages := make(map[string]string, len(files))
+	for _, file := range files {
+		languages[file.Path()] = prompt.FileLanguage(file)
+	}
+	for i := range job.Comments {
+		if language, ok := languages[job.Comments[i].File]; ok {
+			job.Comments[i].Language = language
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/policy"
+)
+
+// LimitComments is a postprocess handler that caps the number of inline
+// comments at the configured maximum, keeping the most severe. The remaining
+// comments are listed in the summary instead and recorded in job.Overflow;
+// they still count towards check runs and gating. Comments that are not
+// posted again because they are duplicates do not count towards the cap.
+func LimitComments(ctx context.Context, job *Job) error {
+	if job.Config == nil || job.Config.MaxComments == nil {
+		return nil
+	}
+
+	comments := CommentsToPost(job)
+	max := *job.Config.MaxComments
+	if len(comments) <= max {
+		return nil
+	}
+
+	ranked := append([]git.ReviewComment(nil), comments...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return policy.SeverityRank(ranked[i].Severity) > policy.SeverityRank(ranked[j].Severity)
+	})
+	overflow := ranked[max:]
+	listInSummary(job, fmt.Sprintf("%d more finding(s) not posted inline", len(overflow)), overflow)
+
+	log.FromContext(ctx).Info("limited inline comments", "max", max, "overflow", len(overflow))
+
+	return nil
+}
+
+// listInSummary moves comments from inline to a list under heading in the
+// summary, recording them in job.Overflow
+func listInSummary(job *Job, heading string, comments []git.ReviewComment) {
+	job.Overflow = append(job.Overflow, comments...)
+
+	var summary strings.Builder
+	summary.WriteString(strings.TrimRight(job.Summary, "\n"))
+	fmt.Fprintf(&summary, "\n\n**%s:**\n", heading)
+	for _, comment := range comments {
+		title, _, _ := strings.Cut(comment.Content, "\n")
+		fmt.Fprintf(&summary, "- %s **%s** (%s): %s\n", linkedLocation(comment), comment.Severity, comment.Rule, title)
+	}
+	job.Summary = strings.TrimLeft(summary.String(), "\n")
+}
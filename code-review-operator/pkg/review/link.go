@@ -0,0 +1,49 @@
+package review
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+)
+
+// LinkComments is a postprocess handler that sets the permalink of every
+// comment to its lines in the pull request's diff, so findings listed in
+// summaries, check runs and notifications can be navigated to. It runs after
+// the comments are anchored to their final lines.
+func LinkComments(ctx context.Context, job *Job) error {
+	if job.PullRequest == 0 {
+		return nil
+	}
+
+	// Without a parsed diff, comments are linked without the line numbers of both sides
+	files, _ := job.parsedFiles(ctx)
+
+	for i, comment := range job.Comments {
+		line := git.DiffLine{
+			File:      comment.File,
+			Side:      string(commentSide(comment)),
+			StartLine: comment.StartLine,
+			Line:      comment.Line,
+		}
+		if file := diff.Find(files, comment.File); file != nil {
+			if diffLine := file.Line(commentSide(comment), comment.Line); diffLine != nil {
+				line.OldLine, line.NewLine = diffLine.OldNumber, diffLine.NewNumber
+			}
+		}
+		job.Comments[i].Link = job.Client.DiffLink(job.Owner, job.Repository, job.PullRequest, line)
+	}
+
+	return nil
+}
+
+// linkedLocation formats the location of a comment as markdown, linking it
+// to the diff when the comment has a permalink
+func linkedLocation(comment git.ReviewComment) string {
+	if comment.Link == "" {
+		return fmt.Sprintf("`%s`", comment.Location())
+	}
+
+	return fmt.Sprintf("[`%s`](%s)", comment.Location(), comment.Link)
+}
@@ -0,0 +1,56 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+)
+
+// LintOnlySummary heads the summary of reviews made without an LLM
+const LintOnlySummary = "**Lint-only review, not reviewed by AI.** %s, so this review lists only the findings of " +
+	"the deterministic checks: static analysis, the secret scan and the manifest rules enabled for the repository. " +
+	"It does not approve the change. Re-run the review once the backend is available for a full review."
+
+// LintOnlyFallback returns pipeline middleware letting reviews go on without
+// an LLM. When the LLM review handler fails because no backend is configured,
+// or if unreachable is set because the backend is unreachable, the review
+// keeps the findings of the deterministic checks, which run alongside it, and
+// is published as a lint-only review with a summary saying it was not
+// reviewed by AI. Reviews the LLM reviewed in part, and reviews that were
+// cancelled, fail as usual.
+func LintOnlyFallback(unreachable bool) Middleware {
+	return func(stage Stage, name string, next Handler) Handler {
+		if stage != StageReview || name != "llm" {
+			return next
+		}
+		return lintOnlyFallback(unreachable, next)
+	}
+}
+
+// lintOnlyFallback wraps the LLM review handler with LintOnlyFallback
+func lintOnlyFallback(unreachable bool, next Handler) Handler {
+	return func(ctx context.Context, job *Job) error {
+		err := next(ctx, job)
+		if err == nil || ctx.Err() != nil || job.Partial {
+			return err
+		}
+		if !errors.Is(err, llm.ErrNoBackend) && !(unreachable && llm.IsUnavailable(err)) {
+			return err
+		}
+
+		reason := "The LLM backend was unavailable"
+		if errors.Is(err, llm.ErrNoBackend) {
+			reason = "No LLM backend is configured"
+		}
+		log.FromContext(ctx).Info("LLM backend unavailable, falling back to a lint-only review",
+			"error", err.Error(), "checks", len(job.Checks))
+		job.LintOnly = true
+		job.Result = &llm.ReviewResult{Summary: fmt.Sprintf(LintOnlySummary, reason)}
+
+		return nil
+	}
+}
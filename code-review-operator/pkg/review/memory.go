@@ -0,0 +1,53 @@
+package review
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/memory"
+)
+
+// RecallMemory returns an enrich stage handler that adds what the reviewer
+// remembers about the repository and author to the prompt. Memory is optional:
+// failures are logged and the review continues without it.
+func RecallMemory(store memory.Store) Handler {
+	return func(ctx context.Context, job *Job) error {
+		mem, err := store.Load(ctx, job.Owner, job.Repository)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "unable to load reviewer memory")
+			return nil
+		}
+		job.Options.Memory = mem.Prompt(job.Author)
+
+		return nil
+	}
+}
+
+// RememberReview returns a publish stage handler that records the published
+// review in the reviewer memory, including the rules commented on inline so
+// rule cooldowns apply to later reviews
+func RememberReview(store memory.Store) Handler {
+	return func(ctx context.Context, job *Job) error {
+		rules := make([]string, 0, len(job.Comments))
+		for _, comment := range job.Comments {
+			rules = append(rules, comment.Rule)
+		}
+		var commented []string
+		for _, comment := range CommentsToPost(job) {
+			commented = append(commented, comment.Rule)
+		}
+
+		err := store.Update(ctx, job.Owner, job.Repository, func(mem *memory.Memory) {
+			now := time.Now()
+			mem.Record(job.Author, rules, job.Summary, now)
+			mem.RecordComments(job.Author, commented, now)
+		})
+		if err != nil {
+			log.FromContext(ctx).Error(err, "unable to update reviewer memory")
+		}
+
+		return nil
+	}
+}
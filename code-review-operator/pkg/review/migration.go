@@ -0,0 +1,359 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/repoconfig"
+)
+
+// Rules of the migration checks
+const (
+	// RuleLockingDDL flags schema changes that lock or rewrite existing tables
+	RuleLockingDDL = "migration/locking-ddl"
+
+	// RuleDestructiveOperation flags statements deleting data without a guard
+	RuleDestructiveOperation = "migration/destructive-operation"
+
+	// RuleMissingDown flags new migrations that cannot be rolled back
+	RuleMissingDown = "migration/missing-down"
+)
+
+// DefaultMigrationSeverity is the severity of migration findings unless configured otherwise
+const DefaultMigrationSeverity = "critical"
+
+// MigrationOptions configures the migration checks
+type MigrationOptions struct {
+	// Severity is the severity of the findings (defaults to DefaultMigrationSeverity)
+	Severity string
+}
+
+// Patterns of migration files and statements. Statements are matched
+// uppercased, with whitespace collapsed and comments removed.
+var (
+	migrateFile    = regexp.MustCompile(`^(\d+_[^/]+)\.(up|down)\.sql$`)
+	flywayFile     = regexp.MustCompile(`^[VR]\d[\w.]*__`)
+	gooseNoDown    = regexp.MustCompile(`goose\.AddMigration\w*\(\s*\w+\s*,\s*nil\s*\)`)
+	createTable    = regexp.MustCompile(`^CREATE\s+(?:UNLOGGED\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([\w."]+)`)
+	createIndex    = regexp.MustCompile(`^CREATE\s+(?:UNIQUE\s+)?INDEX\b.*?\bON\s+(?:ONLY\s+)?([\w."]+)`)
+	alterTable     = regexp.MustCompile(`^ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?([\w."]+)\s+(.*)$`)
+	addValidated   = regexp.MustCompile(`\bADD\s+(?:CONSTRAINT\s+\S+\s+)?(?:FOREIGN\s+KEY|CHECK)\b`)
+	addIndexed     = regexp.MustCompile(`\bADD\s+(?:CONSTRAINT\s+\S+\s+)?(?:PRIMARY\s+KEY|UNIQUE)\b`)
+	changeType     = regexp.MustCompile(`\bALTER\s+(?:COLUMN\s+)?\S+\s+(?:SET\s+DATA\s+)?TYPE\b`)
+	setNotNull     = regexp.MustCompile(`\bSET\s+NOT\s+NULL\b`)
+	addNotNull     = regexp.MustCompile(`\bADD\s+(?:COLUMN\s+)?(?:IF\s+NOT\s+EXISTS\s+)?\S+\s+[^,]*\bNOT\s+NULL\b`)
+	dropColumn     = regexp.MustCompile(`\bDROP\s+COLUMN\s+(IF\s+EXISTS\s+)?`)
+	dropObject     = regexp.MustCompile(`^DROP\s+(TABLE|SCHEMA|DATABASE)\b`)
+	unguardedWrite = regexp.MustCompile(`^(DELETE\s+FROM|UPDATE)\s+([\w."]+)`)
+	truncateTable  = regexp.MustCompile(`^TRUNCATE\b`)
+	whitespace     = regexp.MustCompile(`\s+`)
+)
+
+// Annotations of goose SQL migrations
+const (
+	gooseUpMarker    = "-- +goose Up"
+	gooseDownMarker  = "-- +goose Down"
+	gooseBeginMarker = "-- +goose StatementBegin"
+	gooseEndMarker   = "-- +goose StatementEnd"
+)
+
+// CheckMigrations returns a postprocess handler that checks changed database
+// migrations (plain SQL, goose and golang-migrate) for DDL that locks or
+// rewrites existing tables, destructive statements without a guard, and new
+// migrations without a down migration. Tables created in the same migration
+// are assumed empty and not checked for locking. Down migrations may drop
+// what their up migration created, so only the missing-down check applies
+// to them. It runs after the LLM's comments are converted, when the
+// repository enables the migrations rule pack, and is skipped for diffs
+// without a commit to read the migrations at, such as pre-computed diffs.
+func CheckMigrations(options MigrationOptions) Handler {
+	if options.Severity == "" {
+		options.Severity = DefaultMigrationSeverity
+	}
+
+	return func(ctx context.Context, job *Job) error {
+		if job.Config == nil || !slices.Contains(job.Config.RulePacks, repoconfig.RulePackMigrations) {
+			return nil
+		}
+		if job.Client == nil || job.CommitSHA == "" {
+			return nil
+		}
+		files, ok := job.parsedFiles(ctx)
+		if !ok {
+			return nil
+		}
+
+		for _, file := range files {
+			if !isMigration(file.NewPath) || file.Binary {
+				continue
+			}
+			added := addedLines(file)
+			if len(added) == 0 {
+				continue
+			}
+			data, err := job.Client.GetFileContent(ctx, job.Owner, job.Repository, file.NewPath, job.CommitSHA)
+			if err != nil {
+				log.FromContext(ctx).Error(err, "unable to fetch migration, skipping migration checks", "file", file.NewPath)
+				continue
+			}
+			content := string(data)
+
+			var comments []git.ReviewComment
+			if path.Ext(file.NewPath) == ".sql" {
+				comments = checkMigrationSQL(file.NewPath, content, added)
+			}
+			if file.Status == diff.FileAdded {
+				if comment, ok := missingDown(ctx, job, files, file, content); ok {
+					comments = append(comments, comment)
+				}
+			}
+			for _, comment := range comments {
+				comment.Severity = options.Severity
+				job.Comments = append(job.Comments, comment)
+			}
+		}
+
+		return nil
+	}
+}
+
+// isMigration reports whether a path is a database migration
+func isMigration(filePath string) bool {
+	name := path.Base(filePath)
+	dir := strings.ToLower(path.Dir(filePath))
+	inMigrations := strings.Contains(dir, "migration") || strings.Contains(dir, "migrate")
+	switch path.Ext(name) {
+	case ".sql":
+		return inMigrations || migrateFile.MatchString(name) || flywayFile.MatchString(name)
+	case ".go":
+		return inMigrations && !strings.HasSuffix(name, "_test.go")
+	}
+
+	return false
+}
+
+// sqlStatement is a statement of a migration
+type sqlStatement struct {
+	// Lines are the numbers of the lines holding the statement's code
+	Lines []int
+
+	// Text is the statement, uppercased, without comments and with whitespace collapsed
+	Text string
+
+	// Down is set for statements of a goose Down section
+	Down bool
+}
+
+// parseMigration splits a SQL migration into statements
+func parseMigration(content string) []sqlStatement {
+	var statements []sqlStatement
+	var current sqlStatement
+	var text strings.Builder
+	down, block := false, false
+	flush := func() {
+		if trimmed := strings.TrimSpace(text.String()); trimmed != "" {
+			current.Text = strings.ToUpper(whitespace.ReplaceAllString(trimmed, " "))
+			current.Down = down
+			statements = append(statements, current)
+		}
+		current, block = sqlStatement{}, false
+		text.Reset()
+	}
+
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, gooseUpMarker):
+			flush()
+			down = false
+			continue
+		case strings.HasPrefix(trimmed, gooseDownMarker):
+			flush()
+			down = true
+			continue
+		case strings.HasPrefix(trimmed, gooseBeginMarker):
+			block = true
+			continue
+		case strings.HasPrefix(trimmed, gooseEndMarker):
+			flush()
+			continue
+		}
+
+		code, _, _ := strings.Cut(line, "--")
+		if strings.TrimSpace(code) == "" {
+			continue
+		}
+		if block {
+			current.Lines = append(current.Lines, i+1)
+			text.WriteString(code + " ")
+			continue
+		}
+		parts := strings.Split(code, ";")
+		for j, part := range parts {
+			if strings.TrimSpace(part) != "" {
+				current.Lines = append(current.Lines, i+1)
+				text.WriteString(part + " ")
+			}
+			if j < len(parts)-1 {
+				flush()
+			}
+		}
+	}
+	flush()
+
+	return statements
+}
+
+// checkMigrationSQL checks the statements of a SQL migration, reporting
+// findings on the first added line of each statement
+func checkMigrationSQL(file, content string, added map[int]string) []git.ReviewComment {
+	isDown := strings.HasSuffix(file, ".down.sql")
+	statements := parseMigration(content)
+
+	// Tables created by the migration are empty, so changing them is safe
+	created := make(map[string]bool)
+	for _, statement := range statements {
+		if match := createTable.FindStringSubmatch(statement.Text); match != nil {
+			created[tableName(match[1])] = true
+		}
+	}
+
+	var comments []git.ReviewComment
+	for _, statement := range statements {
+		if isDown || statement.Down {
+			continue
+		}
+		line := 0
+		for _, number := range statement.Lines {
+			if _, ok := added[number]; ok {
+				line = number
+				break
+			}
+		}
+		if line == 0 {
+			continue
+		}
+		for _, finding := range checkStatement(statement.Text, created) {
+			finding.File, finding.Line = file, line
+			comments = append(comments, finding)
+		}
+	}
+
+	return comments
+}
+
+// checkStatement checks a single statement of an up migration
+func checkStatement(text string, created map[string]bool) []git.ReviewComment {
+	var findings []git.ReviewComment
+	report := func(rule, content string) {
+		findings = append(findings, git.ReviewComment{Rule: rule, Content: content})
+	}
+
+	if match := createIndex.FindStringSubmatch(text); match != nil && !created[tableName(match[1])] &&
+		!strings.Contains(text, " CONCURRENTLY ") {
+		report(RuleLockingDDL, fmt.Sprintf("Creating an index blocks writes to `%s` until the index is built. "+
+			"Use `CREATE INDEX CONCURRENTLY` (outside a transaction) on tables with data.", tableName(match[1])))
+	}
+
+	if match := alterTable.FindStringSubmatch(text); match != nil {
+		table, change := tableName(match[1]), match[2]
+		if !created[table] {
+			switch {
+			case addValidated.MatchString(change) && !strings.Contains(change, "NOT VALID"):
+				report(RuleLockingDDL, fmt.Sprintf("Adding the constraint scans every row of `%s` while holding a lock. "+
+					"Add it `NOT VALID` first, then run `VALIDATE CONSTRAINT` in a separate migration.", table))
+			case addIndexed.MatchString(change) && !strings.Contains(change, "USING INDEX"):
+				report(RuleLockingDDL, fmt.Sprintf("Adding the key builds an index on `%s` while blocking writes. "+
+					"Build a unique index `CONCURRENTLY` first, then add the constraint `USING INDEX`.", table))
+			case changeType.MatchString(change):
+				report(RuleLockingDDL, fmt.Sprintf("Changing a column type may rewrite `%s` under an exclusive lock. "+
+					"Add a new column, backfill it in batches and switch over instead.", table))
+			case setNotNull.MatchString(change):
+				report(RuleLockingDDL, fmt.Sprintf("`SET NOT NULL` scans every row of `%s` under an exclusive lock. "+
+					"Add a `CHECK (column IS NOT NULL) NOT VALID` constraint and validate it first.", table))
+			case addNotNull.MatchString(change) && !strings.Contains(change, "DEFAULT"):
+				report(RuleLockingDDL, fmt.Sprintf("Adding a `NOT NULL` column without a default fails on `%s` "+
+					"if it has rows. Add a default, or add the column nullable and backfill it.", table))
+			}
+		}
+		if match := dropColumn.FindStringSubmatch(change); match != nil && match[1] == "" {
+			report(RuleDestructiveOperation, fmt.Sprintf("Dropping a column of `%s` deletes its data and breaks code "+
+				"still reading it. Stop using the column in a release first, and guard the drop with `IF EXISTS`.", table))
+		}
+	}
+
+	switch match := dropObject.FindStringSubmatch(text); {
+	case match != nil && !strings.Contains(text, " IF EXISTS "):
+		report(RuleDestructiveOperation, fmt.Sprintf("`DROP %s` deletes its data irreversibly. Make sure nothing "+
+			"uses it any more, back it up, and guard the drop with `IF EXISTS`.", match[1]))
+	case truncateTable.MatchString(text):
+		report(RuleDestructiveOperation, "`TRUNCATE` deletes every row irreversibly. Migrations should not "+
+			"delete data wholesale; if this is intended, move it to a reviewed, one-off job.")
+	}
+	if match := unguardedWrite.FindStringSubmatch(text); match != nil && !strings.Contains(text, " WHERE ") {
+		report(RuleDestructiveOperation, fmt.Sprintf("This statement changes every row of `%s`. Add a `WHERE` "+
+			"clause, and batch large backfills so they do not lock the table.", tableName(match[2])))
+	}
+
+	return findings
+}
+
+// tableName returns a table name without quotes, in lower case
+func tableName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, `"`, ""))
+}
+
+// missingDown reports a new migration that has no down migration: a
+// golang-migrate up file without its down file, a goose SQL migration
+// without a Down section, or a goose Go migration registered without a down
+// function
+func missingDown(ctx context.Context, job *Job, files []*diff.File, file *diff.File, content string) (git.ReviewComment, bool) {
+	name := path.Base(file.NewPath)
+	comment := git.ReviewComment{File: file.NewPath, Line: 1, Rule: RuleMissingDown}
+
+	if match := migrateFile.FindStringSubmatch(name); match != nil {
+		if match[2] != "up" {
+			return comment, false
+		}
+		downPath := path.Join(path.Dir(file.NewPath), match[1]+".down.sql")
+		if diff.Find(files, downPath) != nil {
+			return comment, false
+		}
+		if _, err := job.Client.GetFileContent(ctx, job.Owner, job.Repository, downPath, job.CommitSHA); !errors.Is(err, git.ErrResourceNotFound) {
+			if err != nil {
+				log.FromContext(ctx).Error(err, "unable to look up down migration", "file", downPath)
+			}
+			return comment, false
+		}
+		comment.Content = fmt.Sprintf("This migration has no down migration, so it cannot be rolled back. "+
+			"Add `%s`, even if it only documents why the change is irreversible.", path.Base(downPath))
+		return comment, true
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if path.Ext(name) == ".go" && gooseNoDown.MatchString(line) {
+			comment.Line = i + 1
+			comment.Content = "The migration is registered without a down function, so it cannot be rolled back. " +
+				"Pass a function reverting it."
+			return comment, true
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), gooseUpMarker) && !strings.Contains(content, gooseDownMarker) {
+			comment.Line = i + 1
+			comment.Content = "This migration has no `-- +goose Down` section, so it cannot be rolled back. " +
+				"Add one reverting the change."
+			return comment, true
+		}
+	}
+
+	return comment, false
+}
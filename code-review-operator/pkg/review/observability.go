@@ -0,0 +1,273 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/repoconfig"
+)
+
+// Rules of the observability rule pack
+const (
+	// RuleSensitiveLogField flags log statements writing secrets or personal data
+	RuleSensitiveLogField = "observability/sensitive-log-field"
+
+	// RuleMissingLogLevel flags output written without a log level
+	RuleMissingLogLevel = "observability/missing-log-level"
+
+	// RuleLogInLoop flags log statements repeated for every iteration of a loop
+	RuleLogInLoop = "observability/log-in-loop"
+
+	// RuleUnregisteredMetric flags Prometheus metrics that are never registered
+	RuleUnregisteredMetric = "observability/unregistered-metric"
+)
+
+// Patterns of the observability checks
+var (
+	// logCall matches calls of common loggers and captures the method
+	logCall = regexp.MustCompile(`\b(?:log|logger|logging|slog|zap|klog|logrus|sugar|console|LOG|LOGGER)\s*\.\s*([A-Za-z]+)\s*\(`)
+
+	// levelessLog matches output without a level: Go's standard logger,
+	// console.log and printing to standard streams in Java
+	levelessLog = regexp.MustCompile(`\blog\.Print(?:f|ln)?\s*\(|\bconsole\.log\s*\(|\bSystem\.(?:out|err)\.print(?:ln|f)?\s*\(`)
+
+	// sensitiveIdentifier matches identifiers naming secrets or personal data
+	sensitiveIdentifier = regexp.MustCompile(`(?i)\b\w*(?:passw(?:or)?d|passwd|secret|token|api_?key|private_?key|credential|authorization|ssn|social_?security|credit_?card|card_?number|cvv)\w*\b`)
+
+	// sensitiveFormat matches format strings labeling a value as a secret
+	sensitiveFormat = regexp.MustCompile(`(?i)(?:passw(?:or)?d|secret|token|api_?key|authorization|ssn|credit_?card|cvv)\w*\s*[:=]\s*(?:%|\{|\$\{)`)
+
+	// stringLiteral matches string literals
+	stringLiteral = regexp.MustCompile("\"(?:[^\"\\\\]|\\\\.)*\"|'(?:[^'\\\\]|\\\\.)*'|`[^`]*`")
+
+	// loopStatement matches the first line of a loop
+	loopStatement = regexp.MustCompile(`^\s*(?:for\b|while\b|do\s*\{|foreach\b)|\.(?:forEach|each|map)\s*\(`)
+
+	// functionStatement matches the first line of a function, which ends the
+	// search for an enclosing loop
+	functionStatement = regexp.MustCompile(`^\s*(?:func\b|def\b|function\b|(?:public|private|protected|static|async)\s)|=>\s*\{?\s*$`)
+
+	// metricConstructor matches Prometheus metrics created with the Go
+	// client and captures the variable they are assigned to
+	metricConstructor = regexp.MustCompile(`\b(\w+)\s*:?=\s*prometheus\.New(?:Counter|Gauge|Histogram|Summary)(?:Vec|Func)?\s*\(`)
+
+	// registerCall matches the start of a registration with a Prometheus registry
+	registerCall = regexp.MustCompile(`\b(?:MustRegister|Register)\s*\(`)
+
+	// identifier matches identifiers
+	identifier = regexp.MustCompile(`[A-Za-z_]\w*`)
+)
+
+// quietLogMethods are log methods whose volume does not matter in loops
+var quietLogMethods = map[string]bool{
+	"debug": true, "debugf": true, "debugw": true, "trace": true, "tracef": true, "v": true,
+}
+
+// CheckObservability is a postprocess handler running the observability
+// rule pack when the repository's configuration enables it. Added lines are
+// checked for log statements writing secrets, output without a log level,
+// log statements at info level or above inside loops, and Prometheus metrics
+// created with the Go client but never registered in their file or the
+// rest of the change. Test files are skipped.
+func CheckObservability(ctx context.Context, job *Job) error {
+	if job.Config == nil || !slices.Contains(job.Config.RulePacks, repoconfig.RulePackObservability) {
+		return nil
+	}
+	files, ok := job.parsedFiles(ctx)
+	if !ok {
+		return nil
+	}
+
+	// Registrations anywhere in the change count for metrics of every file
+	var changed strings.Builder
+	for _, file := range files {
+		for _, content := range addedLines(file) {
+			changed.WriteString(content)
+			changed.WriteString("\n")
+		}
+	}
+	registered := registeredNames(changed.String())
+
+	for _, file := range files {
+		if file.Binary || file.Status == diff.FileDeleted || isTestFile(file.NewPath) {
+			continue
+		}
+		job.Comments = append(job.Comments, checkLogging(file)...)
+
+		if path.Ext(file.NewPath) == ".go" {
+			job.Comments = append(job.Comments, checkMetrics(ctx, job, file, registered)...)
+		}
+	}
+
+	return nil
+}
+
+// checkLogging checks the added log statements of a file
+func checkLogging(file *diff.File) []git.ReviewComment {
+	var comments []git.ReviewComment
+	for _, hunk := range file.Hunks {
+		for i, line := range hunk.Lines {
+			if line.Kind != diff.LineAdded {
+				continue
+			}
+			content := line.Content
+			comment := git.ReviewComment{File: file.NewPath, Line: line.NewNumber}
+
+			call := logCall.FindStringSubmatchIndex(content)
+			leveless := levelessLog.FindStringIndex(content)
+			if call == nil && leveless == nil {
+				continue
+			}
+			start := leveless
+			if call != nil {
+				start = call
+			}
+			arguments := content[start[0]:]
+
+			if sensitiveFormat.MatchString(arguments) || sensitiveIdentifier.MatchString(stringLiteral.ReplaceAllString(arguments, `""`)) {
+				comment.Severity, comment.Rule = "major", RuleSensitiveLogField
+				comment.Content = "This log statement appears to write a secret or personal data. Logs are widely " +
+					"readable and long-lived; drop the value or log a redacted form."
+				comments = append(comments, comment)
+				continue
+			}
+			if leveless != nil {
+				comment.Severity, comment.Rule = "minor", RuleMissingLogLevel
+				comment.Content = "This output has no log level, so it cannot be filtered or routed by severity. " +
+					"Use the project's leveled logger."
+				comments = append(comments, comment)
+				continue
+			}
+			if method := strings.ToLower(content[call[2]:call[3]]); !quietLogMethods[method] && inLoop(hunk.Lines[:i+1]) {
+				comment.Severity, comment.Rule = "minor", RuleLogInLoop
+				comment.Content = "This log statement runs on every iteration of the loop, so its volume grows " +
+					"with the input. Log once after the loop, aggregate, sample, or lower it to debug."
+				comments = append(comments, comment)
+			}
+		}
+	}
+
+	return comments
+}
+
+// inLoop reports whether the last of the new version's lines in a hunk is
+// inside a loop, judged by the indentation of the lines above it
+func inLoop(lines []*diff.Line) bool {
+	last := lines[len(lines)-1]
+	indent := indentation(last.Content)
+	for i := len(lines) - 2; i >= 0 && indent > 0; i-- {
+		line := lines[i]
+		if line.Kind == diff.LineRemoved || strings.TrimSpace(line.Content) == "" {
+			continue
+		}
+		lineIndent := indentation(line.Content)
+		if lineIndent >= indent {
+			continue
+		}
+		if loopStatement.MatchString(line.Content) {
+			return true
+		}
+		if functionStatement.MatchString(line.Content) {
+			return false
+		}
+		indent = lineIndent
+	}
+
+	return false
+}
+
+// indentation returns the width of a line's leading whitespace, counting tabs as four spaces
+func indentation(content string) int {
+	width := 0
+	for _, r := range content {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+
+	return width
+}
+
+// checkMetrics reports Prometheus metrics added to a Go file that are not
+// registered in the file or the rest of the change
+func checkMetrics(ctx context.Context, job *Job, file *diff.File, registered map[string]bool) []git.ReviewComment {
+	var added []*diff.Line
+	for _, hunk := range file.Hunks {
+		for _, line := range hunk.Lines {
+			if line.Kind == diff.LineAdded && metricConstructor.MatchString(line.Content) {
+				added = append(added, line)
+			}
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	data, err := job.Client.GetFileContent(ctx, job.Owner, job.Repository, file.NewPath, job.CommitSHA)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to fetch file, skipping metric registration checks", "file", file.NewPath)
+		return nil
+	}
+	inFile := registeredNames(string(data))
+
+	var comments []git.ReviewComment
+	for _, line := range added {
+		name := metricConstructor.FindStringSubmatch(line.Content)[1]
+		if registered[name] || inFile[name] {
+			continue
+		}
+		comments = append(comments, git.ReviewComment{
+			File:     file.NewPath,
+			Line:     line.NewNumber,
+			Severity: "minor",
+			Rule:     RuleUnregisteredMetric,
+			Content: fmt.Sprintf("Metric `%s` is not registered with a Prometheus registry in this change, so it "+
+				"will never be exported. Register it with `MustRegister` or create it with `promauto`.", name),
+		})
+	}
+
+	return comments
+}
+
+// registeredNames returns the identifiers passed to Register or MustRegister calls in code
+func registeredNames(code string) map[string]bool {
+	names := make(map[string]bool)
+	for _, match := range registerCall.FindAllStringIndex(code, -1) {
+		depth := 1
+		end := match[1]
+		for ; end < len(code) && depth > 0; end++ {
+			switch code[end] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+		}
+		for _, name := range identifier.FindAllString(code[match[1]:end], -1) {
+			names[name] = true
+		}
+	}
+
+	return names
+}
+
+// isTestFile reports whether a path is a test file
+func isTestFile(filePath string) bool {
+	name := path.Base(filePath)
+	return strings.HasSuffix(name, "_test.go") || strings.Contains(name, ".test.") || strings.Contains(name, ".spec.") ||
+		strings.HasPrefix(name, "test_") || strings.HasSuffix(name, "_test.py") ||
+		strings.Contains("/"+filePath, "/test/") || strings.Contains("/"+filePath, "/tests/")
+}
@@ -0,0 +1,357 @@
+package review
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/analysis"
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+	"github.com/Shridhar2104/code-review-operator/pkg/policy"
+	"github.com/Shridhar2104/code-review-operator/pkg/provenance"
+	"github.com/Shridhar2104/code-review-operator/pkg/repoconfig"
+)
+
+// Stage is a step of the review pipeline
+type Stage string
+
+// Pipeline stages, in the order they run
+const (
+	// StageFetch retrieves the diff to review
+	StageFetch Stage = "fetch"
+
+	// StageFilter removes parts of the diff that should not be reviewed
+	StageFilter Stage = "filter"
+
+	// StageEnrich adds context to the review request
+	StageEnrich Stage = "enrich"
+
+	// StageReview sends the diff to the LLM
+	StageReview Stage = "review"
+
+	// StagePostprocess turns the LLM result into the comments to publish
+	StagePostprocess Stage = "postprocess"
+
+	// StagePublish publishes the review
+	StagePublish Stage = "publish"
+)
+
+// Stages lists the pipeline stages in execution order
+var Stages = []Stage{StageFetch, StageFilter, StageEnrich, StageReview, StagePostprocess, StagePublish}
+
+// Job carries the state of a single review through the pipeline
+type Job struct {
+	// Client is the Git provider client for the repository
+	Client git.Client
+
+	// Owner is the owner/organization of the repository
+	Owner string
+
+	// Repository is the name of the repository
+	Repository string
+
+	// PullRequest is the pull request number
+	PullRequest int
+
+	// CommitSHA is the head commit being reviewed
+	CommitSHA string
+
+	// BaseCommitSHA is the commit reviewed last; when set, only the changes
+	// since then are reviewed
+	BaseCommitSHA string
+
+	// Author is the login of the pull request author
+	Author string
+
+	// Description is the description of the pull request, read in the fetch stage
+	Description string
+
+	// BaseBranch is the branch the pull request targets, read in the fetch stage
+	BaseBranch string
+
+	// Labels are the labels of the pull request, read in the fetch stage
+	Labels []string
+
+	// Related lists the pull requests of other repositories the review was
+	// correlated with
+	Related []IssueRef
+
+	// AllowRepository reports whether the review may read other pull
+	// requests of a repository, such as to correlate them; every repository
+	// the Git client can read is allowed when nil
+	AllowRepository func(owner, repository string) bool
+
+	// Dir is the root of a local checkout of the reviewed commit, set when
+	// the review runs outside the cluster on a working tree
+	Dir string
+
+	// Config is the effective review configuration
+	Config *repoconfig.Config
+
+	// LLM overrides the pipeline's LLM client for this job when set
+	LLM llm.Client
+
+	// Routes maps languages to how their files are reviewed apart from the
+	// rest of the diff, such as with a model tuned for the language
+	Routes map[string]Route
+
+	// Options are the options sent to the LLM
+	Options llm.ReviewOptions
+
+	// Diff is the unified diff under review
+	Diff string
+
+	// Files are the files of Diff, parsed once in the filter stage so the
+	// handlers of later stages do not parse the diff again
+	Files []*diff.File
+
+	// filesDiff is the diff Files were parsed from; filesParsed is set once
+	// it was parsed and filesInvalid if it could not be
+	filesDiff    string
+	filesParsed  bool
+	filesInvalid bool
+
+	// Result is the raw result returned by the LLM
+	Result *llm.ReviewResult
+
+	// Partial is set when the review stage was interrupted and Result covers
+	// only the chunks reviewed before then
+	Partial bool
+
+	// LintOnly is set when no LLM backend could review the diff and the
+	// review holds only the findings of the deterministic checks
+	LintOnly bool
+
+	// HandEdited lists generated files edited without their source. They
+	// are reviewed even if excluded by path.
+	HandEdited []string
+
+	// Checks are findings of deterministic checks made before or alongside
+	// the LLM review, merged into Comments once the LLM has explained them
+	Checks []git.ReviewComment
+
+	// Secrets are the findings of the secret scan, also in Checks
+	Secrets []git.ReviewComment
+
+	// secretValues are the secrets found, masked in text sent to the LLM
+	secretValues []string
+
+	// Comments are the comments to publish
+	Comments []git.ReviewComment
+
+	// Summary is the review summary to publish
+	Summary string
+
+	// DecisionPolicy decides the review event posted with the review; reviews only comment when nil
+	DecisionPolicy *policy.ReviewPolicy
+
+	// Decision is the review event the review is posted with
+	Decision git.ReviewDecision
+
+	// Adjustments lists comments moved or dropped because their line was outside the diff
+	Adjustments []Adjustment
+
+	// Escalations lists findings escalated because they persisted across pushes
+	Escalations []Escalation
+
+	// DeploymentTargets lists the Argo CD Applications and Flux
+	// Kustomizations deploying the changed files of a GitOps repository
+	DeploymentTargets []DeploymentTarget
+
+	// PostedComments are the review comments already on the pull request
+	PostedComments []git.PostedComment
+
+	// Duplicates lists comments not posted again because an earlier review
+	// already made them
+	Duplicates []git.ReviewComment
+
+	// Overflow lists comments listed in the summary instead of posted inline
+	// because the review reached its maximum number of comments
+	Overflow []git.ReviewComment
+
+	// Sinks names the publishers the review is published to (defaults to DefaultSinks)
+	Sinks []string
+
+	// ReviewURL is the URL of the published review
+	ReviewURL string
+
+	// Provenance records what produced the review, set in the publish stage
+	Provenance *provenance.Statement
+
+	// Attestation is Provenance signed, attached to the summary posted on
+	// the pull request; unset when reviews are not signed
+	Attestation *provenance.Envelope
+
+	// ReportProgress enables a check run showing progress while the review runs
+	ReportProgress bool
+
+	// DryRun runs the review without publishing it: the publish stage is
+	// skipped and handlers make no changes on the Git provider
+	DryRun bool
+
+	// ProgressCheckRunID is the ID of the in-progress check run, cleared once
+	// the check run is completed
+	ProgressCheckRunID string
+
+	// OnProgress is called as files are reviewed and as the LLM streams its
+	// findings and summary. Calls are serialized but come from the
+	// goroutines reviewing the diff, so it should return quickly.
+	OnProgress func(ReviewEvent)
+
+	// progressTotal is the number of files progress is reported against
+	progressTotal int
+
+	// progressDone is the number of files reviewed so far
+	progressDone int
+}
+
+// Handler processes a review job
+type Handler func(ctx context.Context, job *Job) error
+
+// Middleware wraps every handler in the pipeline
+type Middleware func(stage Stage, name string, next Handler) Handler
+
+// namedHandler is a handler registered under a name
+type namedHandler struct {
+	name    string
+	handler Handler
+}
+
+// Pipeline runs review jobs through the fetch, filter, enrich, review,
+// postprocess and publish stages. Each stage runs its registered handlers in
+// registration order, and every handler is wrapped by the pipeline middleware.
+type Pipeline struct {
+	handlers   map[Stage][]namedHandler
+	middleware []Middleware
+}
+
+// NewPipeline creates an empty pipeline
+func NewPipeline() *Pipeline {
+	return &Pipeline{
+		handlers: make(map[Stage][]namedHandler),
+	}
+}
+
+// NewDefaultPipeline creates a pipeline with the standard fetch, review,
+// postprocess and publish handlers registered. The built-in analyzers run
+// alongside the LLM review.
+func NewDefaultPipeline(llmClient llm.Client, publishers *PublisherRegistry) *Pipeline {
+	p := NewPipeline()
+	p.Register(StageFetch, "diff", FetchDiff)
+	p.Register(StageFetch, "progress", StartProgress)
+	p.Register(StageFetch, "description", FetchDescription)
+	p.Register(StageFilter, "secrets", ScanSecrets)
+	p.Register(StageFilter, "generated", FilterGeneratedFiles)
+	p.Register(StageFilter, "paths", FilterPaths)
+	p.Register(StageFilter, "parse", ParseDiff)
+	p.Register(StageEnrich, "accessibility", CheckAccessibility)
+	p.Register(StageEnrich, "hints", FocusHints)
+	p.Register(StageEnrich, "issues", LinkedIssues)
+	p.Register(StageEnrich, "correlation", CorrelatePullRequests)
+	p.Register(StageReview, "redact", RedactLLMInput(llmClient))
+	p.Register(StageReview, "llm", ReviewCode(llmClient, ChunkOptions{}))
+	p.Register(StagePostprocess, "comments", ConvertComments)
+	p.Register(StagePostprocess, "redactions", NoteRedactions)
+	p.Register(StagePostprocess, "hints", AcknowledgeHints)
+	p.Register(StagePostprocess, "related", NoteRelatedChanges)
+	p.Register(StagePostprocess, "checks", MergeChecks)
+	p.Register(StagePostprocess, "setup", CheckSetupFiles)
+	p.Register(StagePostprocess, "migrations", CheckMigrations(MigrationOptions{}))
+	p.Register(StagePostprocess, "contracts", CheckAPIContracts)
+	p.Register(StagePostprocess, "flags", CheckFeatureFlags)
+	p.Register(StagePostprocess, "observability", CheckObservability)
+	p.Register(StagePostprocess, "spelling", CheckSpelling)
+	p.Register(StagePostprocess, "branches", EscalateSensitiveBranches)
+	p.Register(StagePostprocess, "suppress", SuppressComments)
+	p.Register(StagePostprocess, "anchors", AnchorComments)
+	p.Register(StagePostprocess, "languages", DetectLanguages)
+	p.Register(StagePostprocess, "links", LinkComments)
+	p.Register(StagePostprocess, "decision", DecideReview)
+	p.Register(StagePostprocess, "gitops", AnnotateDeploymentTargets)
+	p.Register(StagePostprocess, "dedup", DeduplicateComments)
+	p.Register(StagePostprocess, "rule-limits", LimitRuleComments)
+	p.Register(StagePostprocess, "limit", LimitComments)
+	p.Register(StagePublish, "provenance", RecordProvenance(nil))
+	p.Register(StagePublish, "publishers", Publish(publishers))
+	p.Register(StagePublish, "progress", FinishProgress)
+
+	return p
+}
+
+// ReviewCode returns the review handler of the default pipeline: the LLM
+// review of the diff, routed by language and split into chunks, alongside the
+// built-in analyzers
+func ReviewCode(llmClient llm.Client, options ChunkOptions) Handler {
+	return ReviewWithAnalyzers(analysis.Defaults(), RouteLanguages(llmClient, ReviewChunks(llmClient, options)))
+}
+
+// Register appends a handler to a stage
+func (p *Pipeline) Register(stage Stage, name string, handler Handler) {
+	p.handlers[stage] = append(p.handlers[stage], namedHandler{name: name, handler: handler})
+}
+
+// Replace replaces the handler registered under name in a stage, or appends
+// it if there is no such handler
+func (p *Pipeline) Replace(stage Stage, name string, handler Handler) {
+	for i, h := range p.handlers[stage] {
+		if h.name == name {
+			p.handlers[stage][i].handler = handler
+			return
+		}
+	}
+
+	p.Register(stage, name, handler)
+}
+
+// RegisterBefore inserts a handler into a stage ahead of the handler
+// registered under before, or appends it if there is no such handler
+func (p *Pipeline) RegisterBefore(stage Stage, before, name string, handler Handler) {
+	handlers := p.handlers[stage]
+	for i, h := range handlers {
+		if h.name == before {
+			inserted := append([]namedHandler{}, handlers[:i]...)
+			inserted = append(inserted, namedHandler{name: name, handler: handler})
+			p.handlers[stage] = append(inserted, handlers[i:]...)
+			return
+		}
+	}
+
+	p.Register(stage, name, handler)
+}
+
+// Use adds middleware wrapping every handler. Middleware added first is outermost.
+func (p *Pipeline) Use(middleware ...Middleware) {
+	p.middleware = append(p.middleware, middleware...)
+}
+
+// Run processes a job through every stage, stopping at the first error
+func (p *Pipeline) Run(ctx context.Context, job *Job) error {
+	return p.RunFrom(ctx, job, Stages[0])
+}
+
+// RunFrom processes a job through the stages from the given stage on,
+// stopping at the first error
+func (p *Pipeline) RunFrom(ctx context.Context, job *Job, from Stage) error {
+	started := false
+	for _, stage := range Stages {
+		if started = started || stage == from; !started {
+			continue
+		}
+		if stage == StagePublish && job.DryRun {
+			continue
+		}
+		for _, h := range p.handlers[stage] {
+			handler := h.handler
+			for i := len(p.middleware) - 1; i >= 0; i-- {
+				handler = p.middleware[i](stage, h.name, handler)
+			}
+
+			if err := handler(ctx, job); err != nil {
+				return fmt.Errorf("%s/%s: %w", stage, h.name, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+)
+
+// ReviewEvent reports the progress of a running review to Job.OnProgress
+type ReviewEvent struct {
+	// FilesReviewed is the number of files of the diff reviewed so far, out
+	// of FilesTotal
+	FilesReviewed int
+	FilesTotal    int
+
+	// Comment is a finding the LLM just reported, before it is filtered or
+	// merged with the other findings; nil if none
+	Comment *llm.ReviewComment
+
+	// Summary is summary text the LLM just wrote
+	Summary string
+}
+
+// StartProgress counts the files to review and opens an in-progress check
+// run for jobs with ReportProgress set. It runs in the fetch stage, once the
+// diff is known. The check run publisher completes the same check run with
+// the final review. Progress reporting is best effort: failures are logged
+// and never fail the review.
+func StartProgress(ctx context.Context, job *Job) error {
+	job.progressTotal = countFiles(job.Diff)
+	if job.OnProgress != nil {
+		job.OnProgress(ReviewEvent{FilesTotal: job.progressTotal})
+	}
+	if !job.ReportProgress || job.CommitSHA == "" {
+		return nil
+	}
+	id, err := job.Client.StartCheckRun(ctx, job.Owner, job.Repository, git.CheckRun{
+		Name:    CheckRunName,
+		HeadSHA: job.CommitSHA,
+		Title:   progressTitle(0, job.progressTotal),
+		Summary: "The review is in progress. This check is updated as files are reviewed.",
+	})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to start progress check run")
+		return nil
+	}
+	job.ProgressCheckRunID = id
+
+	return nil
+}
+
+// UpdateProgress reports that done of the job's files have been reviewed.
+// Callers reviewing concurrently must serialize their calls.
+func UpdateProgress(ctx context.Context, job *Job, done int) {
+	job.progressDone = done
+	if job.OnProgress != nil {
+		job.OnProgress(ReviewEvent{FilesReviewed: done, FilesTotal: job.progressTotal})
+	}
+	if job.ProgressCheckRunID == "" {
+		return
+	}
+
+	err := job.Client.UpdateCheckRun(ctx, job.Owner, job.Repository, job.ProgressCheckRunID, git.CheckRun{
+		Title:   progressTitle(done, job.progressTotal),
+		Summary: "The review is in progress. This check is updated as files are reviewed.",
+	})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to update progress check run")
+	}
+}
+
+// FinishProgress completes the progress check run if no publisher replaced it
+// with the final review. It runs at the end of the publish stage.
+func FinishProgress(ctx context.Context, job *Job) error {
+	if job.ProgressCheckRunID == "" {
+		return nil
+	}
+
+	summary := "The review has been published."
+	if job.ReviewURL != "" {
+		summary = fmt.Sprintf("The review has been published: %s", job.ReviewURL)
+	}
+	completeProgress(ctx, job, git.CheckRun{
+		Conclusion: git.CheckConclusionNeutral,
+		Title:      fmt.Sprintf("%d review comment(s)", len(job.Comments)),
+		Summary:    summary,
+		DetailsURL: job.ReviewURL,
+	})
+
+	return nil
+}
+
+// streamProgress returns a function reporting what the LLM streams to the
+// job's OnProgress, holding lock to serialize the reports with those of
+// UpdateProgress. It returns nil, so that reviews are not streamed, when the
+// job has no OnProgress.
+func streamProgress(job *Job, lock sync.Locker) llm.StreamFunc {
+	if job.OnProgress == nil {
+		return nil
+	}
+
+	return func(event llm.StreamEvent) {
+		lock.Lock()
+		defer lock.Unlock()
+		job.OnProgress(ReviewEvent{
+			FilesReviewed: job.progressDone,
+			FilesTotal:    job.progressTotal,
+			Comment:       event.Comment,
+			Summary:       event.Summary,
+		})
+	}
+}
+
+// AbortProgress completes the progress check run of a job whose review failed
+func AbortProgress(ctx context.Context, job *Job, reviewErr error) {
+	if job.ProgressCheckRunID == "" {
+		return
+	}
+
+	completeProgress(ctx, job, git.CheckRun{
+		Conclusion: git.CheckConclusionNeutral,
+		Title:      "Review failed",
+		Summary:    fmt.Sprintf("The review could not be completed: %v", reviewErr),
+	})
+}
+
+// completeProgress completes the progress check run with the given output
+func completeProgress(ctx context.Context, job *Job, checkRun git.CheckRun) {
+	if err := job.Client.UpdateCheckRun(ctx, job.Owner, job.Repository, job.ProgressCheckRunID, checkRun); err != nil {
+		log.FromContext(ctx).Error(err, "unable to complete progress check run")
+		return
+	}
+	job.ProgressCheckRunID = ""
+}
+
+// progressTitle formats the title of the progress check run
+func progressTitle(done, total int) string {
+	return fmt.Sprintf("Reviewed %d/%d files", done, total)
+}
+
+// countFiles counts the files changed in a unified diff
+func countFiles(unified string) int {
+	files, err := diff.Parse(unified)
+	if err != nil {
+		// Fall back to counting file headers
+		return strings.Count(unified, "diff --git ")
+	}
+
+	return len(files)
+}
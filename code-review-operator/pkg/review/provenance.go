@@ -0,0 +1,84 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+	"github.com/Shridhar2104/code-review-operator/pkg/provenance"
+)
+
+// RecordProvenance returns a handler recording what produced the review: the
+// operator version, the models that reviewed the diff, the hash of the prompt
+// configuration and the digest of the summary. With a signer, the statement
+// is signed and attached to the summary posted on the pull request, so the
+// review can be verified later. It runs in the publish stage, once the
+// summary and the decision are final.
+func RecordProvenance(signer *provenance.Signer) Handler {
+	return func(ctx context.Context, job *Job) error {
+		statement := &provenance.Statement{
+			Repository:      job.Owner + "/" + job.Repository,
+			PullRequest:     job.PullRequest,
+			CommitSHA:       job.CommitSHA,
+			Decision:        string(job.Decision),
+			Comments:        len(CommentsToPost(job)),
+			SummaryDigest:   provenance.Digest(job.Summary),
+			OperatorVersion: provenance.OperatorVersion(),
+			Models:          reviewModels(job),
+			LintOnly:        job.LintOnly,
+			CreatedAt:       time.Now().UTC().Truncate(time.Second),
+		}
+		if !job.LintOnly {
+			statement.PromptHash = llm.PromptHash(job.Options)
+		}
+		job.Provenance = statement
+		if signer == nil {
+			return nil
+		}
+
+		envelope, err := signer.Sign(statement)
+		if err != nil {
+			return fmt.Errorf("error signing review provenance: %w", err)
+		}
+		job.Attestation = envelope
+
+		return nil
+	}
+}
+
+// reviewModels returns the models that reviewed a job's diff: those the
+// tokens were used by, or the model of the job's LLM client if the result
+// does not say, such as for cached results
+func reviewModels(job *Job) []string {
+	if job.LintOnly {
+		return nil
+	}
+
+	var models []string
+	if job.Result != nil {
+		for _, usage := range job.Result.Usage {
+			if usage.Model != "" && !slices.Contains(models, usage.Model) {
+				models = append(models, usage.Model)
+			}
+		}
+	}
+	if len(models) == 0 && job.LLM != nil {
+		if model := llm.ModelID(job.LLM); model != "" {
+			models = append(models, model)
+		}
+	}
+
+	return models
+}
+
+// postedSummary returns the summary to post on the pull request, with the
+// signed provenance statement attached if there is one
+func postedSummary(job *Job) (string, error) {
+	if job.Attestation == nil {
+		return job.Summary, nil
+	}
+
+	return provenance.Attach(job.Summary, job.Attestation)
+}
@@ -0,0 +1,284 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/metrics"
+	"github.com/Shridhar2104/code-review-operator/pkg/policy"
+)
+
+// Built-in publisher names
+const (
+	// PublisherPullRequest posts the review on the pull request
+	PublisherPullRequest = "pullRequest"
+
+	// PublisherCheckRun reports the review as a check run on the head commit
+	PublisherCheckRun = "checkRun"
+
+	// PublisherCommitStatus reports the review as a commit status on the head commit
+	PublisherCommitStatus = "commitStatus"
+)
+
+// DefaultSinks are the publishers used when a job does not name any
+var DefaultSinks = []string{PublisherPullRequest}
+
+// Publisher publishes the outcome of a review to a destination
+type Publisher interface {
+	// Name returns the name the publisher is selected by
+	Name() string
+
+	// Publish publishes the review
+	Publish(ctx context.Context, job *Job) error
+}
+
+// PublisherRegistry holds the publishers available to reviews
+type PublisherRegistry struct {
+	publishers map[string]Publisher
+}
+
+// NewPublisherRegistry creates an empty publisher registry
+func NewPublisherRegistry() *PublisherRegistry {
+	return &PublisherRegistry{
+		publishers: make(map[string]Publisher),
+	}
+}
+
+// NewDefaultPublisherRegistry creates a registry with the built-in publishers registered
+func NewDefaultPublisherRegistry() *PublisherRegistry {
+	registry := NewPublisherRegistry()
+	registry.Register(&PullRequestPublisher{})
+	registry.Register(&CheckRunPublisher{})
+	registry.Register(&CommitStatusPublisher{})
+
+	return registry
+}
+
+// Register registers a publisher under its name
+func (r *PublisherRegistry) Register(publisher Publisher) {
+	r.publishers[publisher.Name()] = publisher
+}
+
+// Get returns the publisher registered under a name
+func (r *PublisherRegistry) Get(name string) (Publisher, bool) {
+	publisher, ok := r.publishers[name]
+	return publisher, ok
+}
+
+// Publish returns a handler that publishes a job to each of its sinks. Every
+// sink is attempted even if an earlier one fails. The review is posted on the
+// pull request first so the other sinks can link to it.
+func Publish(registry *PublisherRegistry) Handler {
+	return func(ctx context.Context, job *Job) error {
+		sinks := append([]string(nil), job.Sinks...)
+		if len(sinks) == 0 {
+			sinks = DefaultSinks
+		}
+		sort.SliceStable(sinks, func(i, j int) bool {
+			return sinks[i] == PublisherPullRequest && sinks[j] != PublisherPullRequest
+		})
+
+		var errs []error
+		for _, sink := range sinks {
+			publisher, ok := registry.Get(sink)
+			if !ok {
+				errs = append(errs, fmt.Errorf("unknown publisher %q", sink))
+				continue
+			}
+			if err := publisher.Publish(ctx, job); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", sink, err))
+			}
+		}
+
+		return errors.Join(errs...)
+	}
+}
+
+// PullRequestPublisher posts the review comments and summary on the pull request
+type PullRequestPublisher struct{}
+
+// Name implements Publisher
+func (p *PullRequestPublisher) Name() string {
+	return PublisherPullRequest
+}
+
+// Publish implements Publisher
+func (p *PullRequestPublisher) Publish(ctx context.Context, job *Job) error {
+	comments := CommentsToPost(job)
+	summary, err := postedSummary(job)
+	if err != nil {
+		return err
+	}
+	reviewURL, err := job.Client.PostReview(ctx, job.Owner, job.Repository, job.PullRequest, job.CommitSHA, comments, summary, job.Decision)
+	if err != nil {
+		return fmt.Errorf("error posting review: %w", err)
+	}
+	job.ReviewURL = reviewURL
+
+	for _, comment := range comments {
+		metrics.CommentsPosted.WithLabelValues(comment.Severity).Inc()
+		metrics.CountRuleComment(comment.Rule)
+	}
+
+	return nil
+}
+
+// CheckRunPublisher reports the review as a check run on the head commit
+type CheckRunPublisher struct{}
+
+// CheckRunName is the name of the check run created for reviews
+const CheckRunName = "ai-review"
+
+// Name implements Publisher
+func (p *CheckRunPublisher) Name() string {
+	return PublisherCheckRun
+}
+
+// Publish implements Publisher
+func (p *CheckRunPublisher) Publish(ctx context.Context, job *Job) error {
+	if job.CommitSHA == "" {
+		return fmt.Errorf("a commit SHA is required to create a check run")
+	}
+
+	conclusion := git.CheckConclusionNeutral
+	summary := fmt.Sprintf("**Findings:** %s\n", countBySeverity(job.Comments))
+	if decision, ok := gate(job); ok {
+		conclusion = git.CheckConclusionSuccess
+		if decision.Blocked {
+			conclusion = git.CheckConclusionFailure
+		}
+		summary += fmt.Sprintf("**Gate:** %s\n", decision.Reason)
+	}
+	if job.ReviewURL != "" {
+		summary += fmt.Sprintf("\n[View the review](%s)\n", job.ReviewURL)
+	}
+	if job.Summary != "" {
+		summary += "\n" + job.Summary
+	}
+
+	var text strings.Builder
+	for _, comment := range job.Comments {
+		content := comment.Content
+		if comment.SuggestedCode != "" {
+			content += strings.ReplaceAll(git.SuggestionBlock(comment.SuggestedCode), "\n", "\n  ")
+		}
+		fmt.Fprintf(&text, "- %s **%s** (%s): %s\n", linkedLocation(comment), comment.Severity, comment.Rule, content)
+	}
+
+	checkRun := git.CheckRun{
+		Name:       CheckRunName,
+		HeadSHA:    job.CommitSHA,
+		Conclusion: conclusion,
+		Title:      fmt.Sprintf("%d review comment(s)", len(job.Comments)),
+		Summary:    summary,
+		Text:       text.String(),
+		DetailsURL: job.ReviewURL,
+	}
+
+	// Replace the progress check run with the final review, if there is one
+	if job.ProgressCheckRunID != "" {
+		if err := job.Client.UpdateCheckRun(ctx, job.Owner, job.Repository, job.ProgressCheckRunID, checkRun); err != nil {
+			return fmt.Errorf("error completing check run: %w", err)
+		}
+		job.ProgressCheckRunID = ""
+
+		return nil
+	}
+
+	if _, err := job.Client.CreateCheckRun(ctx, job.Owner, job.Repository, checkRun); err != nil {
+		return fmt.Errorf("error creating check run: %w", err)
+	}
+
+	return nil
+}
+
+// CommitStatusPublisher reports the review as a commit status on the head
+// commit, for providers without check runs
+type CommitStatusPublisher struct{}
+
+// Name implements Publisher
+func (p *CommitStatusPublisher) Name() string {
+	return PublisherCommitStatus
+}
+
+// Publish implements Publisher
+func (p *CommitStatusPublisher) Publish(ctx context.Context, job *Job) error {
+	if job.CommitSHA == "" {
+		return fmt.Errorf("a commit SHA is required to set a commit status")
+	}
+
+	status := git.CommitStatus{
+		Context:     CheckRunName,
+		State:       git.CommitStatusSuccess,
+		Description: countBySeverity(job.Comments),
+		TargetURL:   job.ReviewURL,
+	}
+	if decision, ok := gate(job); ok && decision.Blocked {
+		status.State = git.CommitStatusFailure
+		status.Description = decision.Reason
+	}
+
+	if err := job.Client.SetCommitStatus(ctx, job.Owner, job.Repository, job.CommitSHA, status); err != nil {
+		return fmt.Errorf("error setting commit status: %w", err)
+	}
+
+	return nil
+}
+
+// gate evaluates the findings against the configured gating policy, returning
+// false if the review is not gated. Secrets fail the gate when the secrets
+// policy asks for it, whatever the gating policy.
+func gate(job *Job) (policy.Decision, bool) {
+	if job.Config != nil && job.Config.Secrets != nil && job.Config.Secrets.FailCheck != nil &&
+		*job.Config.Secrets.FailCheck && len(job.Secrets) > 0 {
+		return policy.Decision{
+			Blocked: true,
+			Counted: len(job.Secrets),
+			Reason:  fmt.Sprintf("%d possible secret(s) found in the diff", len(job.Secrets)),
+		}, true
+	}
+	if job.Config == nil || job.Config.Gating == nil {
+		return policy.Decision{}, false
+	}
+
+	gating := policy.Policy{FailOn: job.Config.Gating.FailOn}
+	if job.Config.Gating.MaxFindings != nil {
+		gating.MaxFindings = *job.Config.Gating.MaxFindings
+	}
+
+	return gating.Evaluate(job.Comments), true
+}
+
+// countBySeverity summarizes the number of comments of each severity, most severe first
+func countBySeverity(comments []git.ReviewComment) string {
+	if len(comments) == 0 {
+		return "no findings"
+	}
+
+	counts := make(map[string]int)
+	for _, comment := range comments {
+		counts[comment.Severity]++
+	}
+	severities := make([]string, 0, len(counts))
+	for severity := range counts {
+		severities = append(severities, severity)
+	}
+	sort.Slice(severities, func(i, j int) bool {
+		ri, rj := policy.SeverityRank(severities[i]), policy.SeverityRank(severities[j])
+		if ri != rj {
+			return ri > rj
+		}
+		return severities[i] < severities[j]
+	})
+
+	parts := make([]string, 0, len(severities))
+	for _, severity := range severities {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[severity], severity))
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,92 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+)
+
+// RedactLLMInput returns a review stage handler, registered before the LLM
+// review, that wraps the job's LLM client (or client when the job has none)
+// in a redacting client. It drops confidential files from the diff and masks
+// email addresses, internal hostnames, private IP addresses, credentials and
+// the repository's own patterns in the diff and the review's context before
+// they leave the cluster.
+func RedactLLMInput(client llm.Client) Handler {
+	return func(ctx context.Context, job *Job) error {
+		redactor, err := newRedactor(job)
+		if err != nil {
+			// Sending unredacted code is worse than not reviewing it
+			return err
+		}
+		if redactor == nil {
+			return nil
+		}
+
+		reviewer := client
+		if job.LLM != nil {
+			reviewer = job.LLM
+		}
+		job.LLM = llm.NewRedactingClient(reviewer, redactor)
+		for language, route := range job.Routes {
+			if route.LLM != nil {
+				route.LLM = llm.NewRedactingClient(route.LLM, redactor)
+				job.Routes[language] = route
+			}
+		}
+
+		return nil
+	}
+}
+
+// NoteRedactions is a postprocess handler recording in the summary what was
+// masked before the review was sent to the LLM
+func NoteRedactions(ctx context.Context, job *Job) error {
+	if job.Result == nil || len(job.Result.Redactions) == 0 {
+		return nil
+	}
+
+	var summary strings.Builder
+	summary.WriteString(strings.TrimRight(job.Summary, "\n"))
+	fmt.Fprintf(&summary, "\n\n**Redacted before review:** %s. The reviewer did not see this content.\n",
+		job.Result.Redactions)
+	job.Summary = strings.TrimLeft(summary.String(), "\n")
+
+	log.FromContext(ctx).Info("redacted review input", "redactions", job.Result.Redactions.String())
+
+	return nil
+}
+
+// newRedactor creates the redactor of a job's configuration, or returns nil
+// when nothing is redacted
+func newRedactor(job *Job) (*llm.Redactor, error) {
+	builtin := true
+	var patterns map[string]string
+	var confidential []string
+	if job.Config != nil && job.Config.Redaction != nil {
+		redaction := job.Config.Redaction
+		if redaction.Builtin != nil {
+			builtin = *redaction.Builtin
+		}
+		patterns = redaction.Patterns
+		confidential = redaction.Confidential
+	}
+	if !builtin && len(patterns) == 0 && len(confidential) == 0 {
+		return nil, nil
+	}
+
+	return llm.NewRedactor(builtin, patterns, confidential)
+}
+
+// confidential reports whether a file must not be sent to the LLM
+func (job *Job) confidential(filePath string) bool {
+	if job.Config == nil || job.Config.Redaction == nil {
+		return false
+	}
+
+	return matchesAny(job.Config.Redaction.Confidential, filePath)
+}
@@ -0,0 +1,21 @@
+package review
+
+import (
+	"github.com/Shridhar2104/code-review-operator/pkg/report"
+)
+
+// Report returns the report of a finished job. It holds every finding of the
+// review, including those not posted inline because they were already on the
+// pull request or over the comment limit.
+func Report(job *Job) *report.Report {
+	r := report.New(job.Owner+"/"+job.Repository, job.PullRequest, job.CommitSHA)
+	r.Summary = job.Summary
+	r.Decision = string(job.Decision)
+	r.Provenance = job.Provenance
+	if job.Result != nil {
+		r.TokensUsed = job.Result.TokensUsed
+	}
+	r.AddComments(job.Comments...)
+
+	return r
+}
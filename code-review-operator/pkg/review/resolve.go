@@ -0,0 +1,146 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+)
+
+// Bulk actions on posted findings
+const (
+	// ActionResolve resolves the findings' threads, keeping them in the history
+	ActionResolve = "resolve"
+
+	// ActionDismiss deletes the findings' comments
+	ActionDismiss = "dismiss"
+)
+
+// FindingFilter selects findings the operator has posted. Empty fields match
+// every finding; a finding must match all the others.
+type FindingFilter struct {
+	// Rule is the rule the finding was made for
+	Rule string
+
+	// Severity is the severity of the finding. Comments posted before
+	// severities were recorded in the comment marker never match.
+	Severity string
+
+	// Path is a glob matching the commented file, as in exclude patterns
+	Path string
+}
+
+// IsEmpty reports whether the filter matches every finding
+func (f FindingFilter) IsEmpty() bool {
+	return f.Rule == "" && f.Severity == "" && f.Path == ""
+}
+
+// Matches reports whether a posted comment is one of the operator's findings
+// selected by the filter. Replies are never findings.
+func (f FindingFilter) Matches(comment git.PostedComment) bool {
+	rule, ours := git.ParseCommentMarker(comment.Body)
+	if !ours || comment.InReplyTo != "" {
+		return false
+	}
+	if f.Rule != "" && rule != f.Rule {
+		return false
+	}
+	if f.Severity != "" && !strings.EqualFold(git.CommentSeverity(comment.Body), f.Severity) {
+		return false
+	}
+
+	return f.Path == "" || matchesAny([]string{f.Path}, comment.File)
+}
+
+// ResolveOptions configures a bulk action on posted findings
+type ResolveOptions struct {
+	// Filter selects the findings
+	Filter FindingFilter
+
+	// Action is ActionResolve or ActionDismiss
+	Action string
+
+	// Reason is replied to resolved findings, if set, so developers know why
+	// the thread was closed
+	Reason string
+
+	// DryRun lists the matching findings without changing them
+	DryRun bool
+}
+
+// ResolvedFinding is a finding a bulk action was applied to
+type ResolvedFinding struct {
+	PullRequest int    `json:"pullRequest"`
+	CommentID   string `json:"commentId"`
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	Rule        string `json:"rule"`
+	Severity    string `json:"severity,omitempty"`
+
+	// Error is why the action failed on this finding, if it did
+	Error string `json:"error,omitempty"`
+}
+
+// ResolveFindings resolves or dismisses the operator's findings matching a
+// filter on every open pull request of a repository, such as after a noisy
+// rule is disabled. Failures on single findings are recorded in the result
+// and the action continues; listing failures stop it.
+func ResolveFindings(ctx context.Context, client git.Client, owner, repo string, options ResolveOptions) ([]ResolvedFinding, error) {
+	if options.Action != ActionResolve && options.Action != ActionDismiss {
+		return nil, fmt.Errorf("unknown action %q: must be %s or %s", options.Action, ActionResolve, ActionDismiss)
+	}
+
+	pullRequests, err := client.GetPullRequests(ctx, owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("error listing pull requests: %w", err)
+	}
+
+	var findings []ResolvedFinding
+	for _, pr := range pullRequests {
+		comments, err := client.ListReviewComments(ctx, owner, repo, pr.Number)
+		if err != nil {
+			return findings, fmt.Errorf("error listing review comments of #%d: %w", pr.Number, err)
+		}
+
+		for _, comment := range comments {
+			if !options.Filter.Matches(comment) {
+				continue
+			}
+			rule, _ := git.ParseCommentMarker(comment.Body)
+			finding := ResolvedFinding{
+				PullRequest: pr.Number,
+				CommentID:   comment.ID,
+				File:        comment.File,
+				Line:        comment.Line,
+				Rule:        rule,
+				Severity:    git.CommentSeverity(comment.Body),
+			}
+			if !options.DryRun {
+				if err := applyAction(ctx, client, owner, repo, pr.Number, comment, options); err != nil {
+					finding.Error = err.Error()
+				}
+			}
+			findings = append(findings, finding)
+		}
+	}
+
+	return findings, nil
+}
+
+// applyAction resolves or dismisses a single finding
+func applyAction(ctx context.Context, client git.Client, owner, repo string, prNumber int, comment git.PostedComment, options ResolveOptions) error {
+	if options.Action == ActionDismiss {
+		return client.DeleteReviewComment(ctx, owner, repo, prNumber, comment.ID)
+	}
+
+	if options.Reason != "" {
+		rule, _ := git.ParseCommentMarker(comment.Body)
+		body := options.Reason + "\n\n" + git.CommentMarker(rule, "")
+		if err := client.ReplyToReviewComment(ctx, owner, repo, prNumber, comment.ID, body); err != nil {
+			return err
+		}
+	}
+
+	return client.ResolveReviewThread(ctx, owner, repo, prNumber, comment.ID)
+}
@@ -0,0 +1,104 @@
+package review
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/memory"
+	"github.com/Shridhar2104/code-review-operator/pkg/policy"
+	"github.com/Shridhar2104/code-review-operator/pkg/repoconfig"
+)
+
+// LimitRuleComments is a postprocess handler that caps the inline comments of
+// each rule with a configured maximum, keeping the most severe. A limit keyed
+// by a glob caps the comments of all the rules it matches together. The
+// remaining comments are listed in the summary like those over the overall
+// maximum.
+func LimitRuleComments(ctx context.Context, job *Job) error {
+	if job.Config == nil || len(job.Config.RuleLimits) == 0 {
+		return nil
+	}
+
+	groups := make(map[string][]git.ReviewComment)
+	var keys []string
+	for _, comment := range CommentsToPost(job) {
+		key, limit, ok := job.Config.RuleLimit(comment.Rule)
+		if !ok || limit.MaxComments == nil {
+			continue
+		}
+		if _, seen := groups[key]; !seen {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], comment)
+	}
+
+	var overflow []git.ReviewComment
+	for _, key := range keys {
+		comments := groups[key]
+		max := *job.Config.RuleLimits[key].MaxComments
+		if len(comments) <= max {
+			continue
+		}
+		sort.SliceStable(comments, func(i, j int) bool {
+			return policy.SeverityRank(comments[i].Severity) > policy.SeverityRank(comments[j].Severity)
+		})
+		overflow = append(overflow, comments[max:]...)
+	}
+	if len(overflow) == 0 {
+		return nil
+	}
+
+	listInSummary(job, "Findings over their rule's comment limit, not posted inline", overflow)
+	log.FromContext(ctx).Info("limited inline comments per rule", "overflow", len(overflow))
+
+	return nil
+}
+
+// CooldownRules returns a postprocess handler that holds back inline comments
+// of rules with a configured cooldown when the pull request author received a
+// comment for the same rule within the cooldown, so recurring feedback is not
+// repeated on every pull request. Held back comments are listed in the
+// summary. RememberReview records the rules commented on.
+func CooldownRules(store memory.Store) Handler {
+	return func(ctx context.Context, job *Job) error {
+		if job.Config == nil || len(job.Config.RuleLimits) == 0 || job.Author == "" {
+			return nil
+		}
+		logger := log.FromContext(ctx)
+
+		mem, err := store.Load(ctx, job.Owner, job.Repository)
+		if err != nil {
+			logger.Error(err, "unable to load reviewer memory for rule cooldowns")
+			return nil
+		}
+
+		now := time.Now()
+		var held []git.ReviewComment
+		for _, comment := range CommentsToPost(job) {
+			_, limit, ok := job.Config.RuleLimit(comment.Rule)
+			if !ok || limit.Cooldown == "" {
+				continue
+			}
+			cooldown, err := repoconfig.ParseCooldown(limit.Cooldown)
+			if err != nil {
+				continue
+			}
+			last := mem.LastCommented(job.Author, comment.Rule)
+			if !last.IsZero() && now.Sub(last) < cooldown {
+				held = append(held, comment)
+			}
+		}
+		if len(held) == 0 {
+			return nil
+		}
+
+		listInSummary(job, "Findings of rules recently raised with the author, not posted inline", held)
+		logger.Info("held back inline comments of rules in cooldown", "author", job.Author, "comments", len(held))
+
+		return nil
+	}
+}
@@ -0,0 +1,92 @@
+package review
+
+import (
+	"context"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/analysis"
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/policy"
+)
+
+// redactedSecret replaces secrets in text sent to the LLM
+const redactedSecret = "[REDACTED]"
+
+// ScanSecrets is a filter handler scanning the added lines of the whole diff,
+// including files later excluded from review, for leaked credentials: known
+// formats such as AWS keys, Google Cloud service account keys, private keys
+// and API tokens, and high-entropy strings assigned like credentials. It
+// runs first so the secrets are masked in the diff before any other handler
+// sees it, and they never reach the LLM. Findings are critical, recorded in
+// job.Secrets and posted through job.Checks.
+func ScanSecrets(ctx context.Context, job *Job) error {
+	files, ok := job.parsedFiles(ctx)
+	if !ok {
+		return nil
+	}
+
+	var ignore []string
+	if job.Config != nil && job.Config.Secrets != nil {
+		ignore = job.Config.Secrets.Ignore
+	}
+	for _, secret := range analysis.FindSecrets(files) {
+		if matchesAny(ignore, secret.File) {
+			continue
+		}
+		finding := git.ReviewComment{
+			File: secret.File,
+			Line: secret.Line,
+			Content: "This line appears to contain " + secret.Description + ". Remove it and rotate the " +
+				"credential, since it is in the branch history, then load it from a secret store or the " +
+				"environment. The value was masked before the diff was sent for review.",
+			Severity: policy.SeverityCritical,
+			Rule:     secret.Rule,
+		}
+		job.Secrets = append(job.Secrets, finding)
+		job.Checks = append(job.Checks, finding)
+		for _, value := range secret.Values {
+			if value != "" {
+				job.secretValues = append(job.secretValues, value)
+			}
+		}
+	}
+	if len(job.Secrets) == 0 {
+		return nil
+	}
+
+	job.Diff = job.maskSecrets(job.Diff)
+	log.FromContext(ctx).Info("found possible secrets in the diff", "secrets", len(job.Secrets))
+
+	return nil
+}
+
+// maskDiffSecrets masks the credentials on the added lines of a diff other
+// than the one under review, such as that of a related pull request, along
+// with the secrets ScanSecrets found
+func (job *Job) maskDiffSecrets(unified string) (string, error) {
+	files, err := diff.Parse(unified)
+	if err != nil {
+		return "", err
+	}
+	for _, secret := range analysis.FindSecrets(files) {
+		for _, value := range secret.Values {
+			if value != "" {
+				unified = strings.ReplaceAll(unified, value, redactedSecret)
+			}
+		}
+	}
+
+	return job.maskSecrets(unified), nil
+}
+
+// maskSecrets replaces the secrets found by ScanSecrets in text
+func (job *Job) maskSecrets(text string) string {
+	for _, value := range job.secretValues {
+		text = strings.ReplaceAll(text, value, redactedSecret)
+	}
+
+	return text
+}
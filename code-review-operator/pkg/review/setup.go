@@ -0,0 +1,326 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/repoconfig"
+)
+
+// Rules of the setup file checks
+const (
+	// RuleUnpinnedImage flags base images not pinned to a version or digest
+	RuleUnpinnedImage = "setup/unpinned-base-image"
+
+	// RuleRootUser flags containers left running as root
+	RuleRootUser = "setup/root-user"
+
+	// RuleCacheBusting flags build steps that defeat the layer cache
+	RuleCacheBusting = "setup/cache-busting-layer"
+)
+
+// Kinds of setup files
+const (
+	setupDockerfile   = "dockerfile"
+	setupDevcontainer = "devcontainer"
+	setupMakefile     = "makefile"
+)
+
+// dependencyInstall matches commands installing dependencies from manifests
+var dependencyInstall = regexp.MustCompile(`\b(npm (ci|install)|yarn( install)?\b|pnpm install|pip3? install|poetry install|go mod download|bundle install|composer install|cargo (fetch|build)|mvn [\w:-]*dependency|gradle\w* dependencies)`)
+
+// devcontainerImage matches the image of a devcontainer.json
+var devcontainerImage = regexp.MustCompile(`"image"\s*:\s*"([^"]+)"`)
+
+// devcontainerRootUser matches a devcontainer.json running as root
+var devcontainerRootUser = regexp.MustCompile(`"(remoteUser|containerUser)"\s*:\s*"(root|0)"`)
+
+// latestImage matches image references tagged latest in commands
+var latestImage = regexp.MustCompile(`[\w.-]+(?:/[\w.-]+)*:latest\b`)
+
+// CheckSetupFiles is a postprocess handler that checks changed development
+// and build setup files (Dockerfiles, devcontainer.json and Makefiles) for
+// base images not pinned to a version or digest, containers running as root
+// and instruction order that rebuilds dependency layers on every change.
+// Findings are reported under dedicated rules, on added lines only. It runs
+// after the LLM's comments are converted so both are anchored together, when
+// the repository enables the setup rule pack.
+func CheckSetupFiles(ctx context.Context, job *Job) error {
+	if job.Config == nil || !slices.Contains(job.Config.RulePacks, repoconfig.RulePackSetup) {
+		return nil
+	}
+	files, ok := job.parsedFiles(ctx)
+	if !ok {
+		return nil
+	}
+
+	for _, file := range files {
+		kind := setupFileKind(file.NewPath)
+		if kind == "" || file.Binary {
+			continue
+		}
+		added := addedLines(file)
+		if len(added) == 0 {
+			continue
+		}
+
+		switch kind {
+		case setupDockerfile:
+			data, err := job.Client.GetFileContent(ctx, job.Owner, job.Repository, file.NewPath, job.CommitSHA)
+			if err != nil {
+				log.FromContext(ctx).Error(err, "unable to fetch Dockerfile, skipping setup checks", "file", file.NewPath)
+				continue
+			}
+			job.Comments = append(job.Comments, checkDockerfile(file.NewPath, string(data), added)...)
+		case setupDevcontainer:
+			job.Comments = append(job.Comments, checkDevcontainer(file.NewPath, added)...)
+		case setupMakefile:
+			job.Comments = append(job.Comments, checkMakefile(file.NewPath, added)...)
+		}
+	}
+
+	return nil
+}
+
+// setupFileKind returns the kind of setup file a path is, if any
+func setupFileKind(filePath string) string {
+	name := path.Base(filePath)
+	switch {
+	case strings.HasPrefix(name, "Dockerfile"), strings.HasPrefix(name, "Containerfile"), path.Ext(name) == ".dockerfile":
+		return setupDockerfile
+	case name == "devcontainer.json" || name == ".devcontainer.json":
+		return setupDevcontainer
+	case name == "Makefile" || name == "GNUmakefile" || name == "makefile" || path.Ext(name) == ".mk":
+		return setupMakefile
+	}
+
+	return ""
+}
+
+// addedLines returns the added lines of a file by their number in the new version
+func addedLines(file *diff.File) map[int]string {
+	added := make(map[int]string)
+	for _, hunk := range file.Hunks {
+		for _, line := range hunk.Lines {
+			if line.Kind == diff.LineAdded {
+				added[line.NewNumber] = line.Content
+			}
+		}
+	}
+
+	return added
+}
+
+// instruction is a Dockerfile instruction, joined across continuation lines
+type instruction struct {
+	// Line is the line the instruction starts on
+	Line int
+
+	// Keyword is the lowercased instruction keyword
+	Keyword string
+
+	// Args is the rest of the instruction
+	Args string
+}
+
+// parseDockerfile splits a Dockerfile into instructions
+func parseDockerfile(content string) []instruction {
+	var instructions []instruction
+	var current *instruction
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if current == nil {
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			keyword, args, _ := strings.Cut(trimmed, " ")
+			current = &instruction{Line: i + 1, Keyword: strings.ToLower(keyword), Args: strings.TrimSpace(args)}
+		} else if !strings.HasPrefix(trimmed, "#") {
+			current.Args += " " + trimmed
+		}
+		if strings.HasSuffix(current.Args, "\\") {
+			current.Args = strings.TrimSpace(strings.TrimSuffix(current.Args, "\\"))
+			continue
+		}
+		instructions = append(instructions, *current)
+		current = nil
+	}
+	if current != nil {
+		instructions = append(instructions, *current)
+	}
+
+	return instructions
+}
+
+// checkDockerfile checks a Dockerfile's instructions, reporting findings on added lines
+func checkDockerfile(file, content string, added map[int]string) []git.ReviewComment {
+	var comments []git.ReviewComment
+	report := func(line int, severity, rule, content string) {
+		if _, ok := added[line]; ok {
+			comments = append(comments, git.ReviewComment{File: file, Line: line, Severity: severity, Rule: rule, Content: content})
+		}
+	}
+
+	stages := make(map[string]string) // stage name to the user it ends as
+	var finalFrom, userInst instruction
+	var finalImage, stage, user string
+	copiedContext := 0
+	for _, inst := range parseDockerfile(content) {
+		switch inst.Keyword {
+		case "from":
+			if stage != "" {
+				stages[stage] = user
+			}
+			image, name := fromImage(inst.Args)
+			parent, isStage := stages[strings.ToLower(image)]
+			if !isStage && unpinnedImage(image) {
+				report(inst.Line, "major", RuleUnpinnedImage, fmt.Sprintf("The base image `%s` is not pinned to a version or digest, "+
+					"so builds can change without notice. Pin a specific tag, ideally with its `@sha256:` digest.", image))
+			}
+			finalFrom, finalImage, userInst = inst, image, instruction{}
+			stage, user, copiedContext = strings.ToLower(name), parent, 0
+		case "user":
+			user, userInst = strings.TrimSpace(inst.Args), inst
+		case "copy", "add":
+			if copiesContext(inst.Args) && copiedContext == 0 {
+				copiedContext = inst.Line
+			}
+		case "run":
+			if copiedContext > 0 && dependencyInstall.MatchString(inst.Args) {
+				report(copiedContext, "minor", RuleCacheBusting, "The whole build context is copied before dependencies are installed, "+
+					"so any source change invalidates the dependency layer. Copy the dependency manifests and install "+
+					"dependencies first, then copy the rest of the source.")
+				copiedContext = -1
+			}
+		}
+	}
+	// Switching to root for some steps is fine, as long as the image ends as another user
+	switch {
+	case finalFrom.Keyword == "" || strings.EqualFold(finalImage, "scratch"):
+	case userInst.Keyword != "" && isRootUser(user):
+		report(userInst.Line, "major", RuleRootUser, "The final image runs as root. Switch back to an unprivileged "+
+			"user after the steps that need root.")
+	case user == "":
+		report(finalFrom.Line, "major", RuleRootUser, "The final image runs as root because it sets no `USER`. "+
+			"Create an unprivileged user and switch to it with `USER` before the entrypoint.")
+	}
+
+	return comments
+}
+
+// fromImage returns the image and stage name of a FROM instruction's arguments
+func fromImage(args string) (string, string) {
+	var fields []string
+	for _, field := range strings.Fields(args) {
+		if !strings.HasPrefix(field, "--") {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return "", ""
+	}
+	if len(fields) >= 3 && strings.EqualFold(fields[1], "as") {
+		return fields[0], fields[2]
+	}
+
+	return fields[0], ""
+}
+
+// unpinnedImage reports whether an image reference has neither a digest nor
+// a tag other than latest. Images set by build arguments cannot be checked.
+func unpinnedImage(image string) bool {
+	if image == "" || strings.EqualFold(image, "scratch") || strings.Contains(image, "$") || strings.Contains(image, "@sha256:") {
+		return false
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	_, tag, tagged := strings.Cut(name, ":")
+
+	return !tagged || tag == "latest"
+}
+
+// isRootUser reports whether a USER value is the root user
+func isRootUser(user string) bool {
+	name, _, _ := strings.Cut(user, ":")
+	return name == "root" || name == "0"
+}
+
+// copiesContext reports whether COPY or ADD arguments copy the whole build context
+func copiesContext(args string) bool {
+	var sources []string
+	for _, field := range strings.Fields(args) {
+		if !strings.HasPrefix(field, "--") {
+			sources = append(sources, field)
+		}
+	}
+	if len(sources) < 2 {
+		return false
+	}
+	for _, source := range sources[:len(sources)-1] {
+		if source == "." || source == "./" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkDevcontainer checks the added lines of a devcontainer.json
+func checkDevcontainer(file string, added map[int]string) []git.ReviewComment {
+	var comments []git.ReviewComment
+	for _, number := range sortedLines(added) {
+		line := added[number]
+		if match := devcontainerImage.FindStringSubmatch(line); match != nil && unpinnedImage(match[1]) {
+			comments = append(comments, git.ReviewComment{File: file, Line: number, Severity: "major", Rule: RuleUnpinnedImage,
+				Content: fmt.Sprintf("The dev container image `%s` is not pinned to a version or digest, so every rebuild "+
+					"of the environment may differ. Pin a specific tag.", match[1])})
+		}
+		if devcontainerRootUser.MatchString(line) {
+			comments = append(comments, git.ReviewComment{File: file, Line: number, Severity: "major", Rule: RuleRootUser,
+				Content: "The dev container runs as root, so files it creates in the workspace are owned by root. " +
+					"Use the image's non-root user (often `vscode`) instead."})
+		}
+	}
+
+	return comments
+}
+
+// checkMakefile checks the added lines of a Makefile for container commands
+// using latest images or disabling the build cache
+func checkMakefile(file string, added map[int]string) []git.ReviewComment {
+	var comments []git.ReviewComment
+	for _, number := range sortedLines(added) {
+		line := added[number]
+		if image := latestImage.FindString(line); image != "" {
+			comments = append(comments, git.ReviewComment{File: file, Line: number, Severity: "minor", Rule: RuleUnpinnedImage,
+				Content: fmt.Sprintf("`%s` is not pinned, so the target's results can change without notice. "+
+					"Pin a specific tag.", image)})
+		}
+		if strings.Contains(line, "--no-cache") && strings.Contains(line, "build") {
+			comments = append(comments, git.ReviewComment{File: file, Line: number, Severity: "minor", Rule: RuleCacheBusting,
+				Content: "`--no-cache` rebuilds every layer on each run. Order the Dockerfile so dependency layers " +
+					"are reused instead, and keep uncached builds for release targets."})
+		}
+	}
+
+	return comments
+}
+
+// sortedLines returns the line numbers of added lines in order
+func sortedLines(added map[int]string) []int {
+	numbers := make([]int, 0, len(added))
+	for number := range added {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	return numbers
+}
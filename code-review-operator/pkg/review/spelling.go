@@ -0,0 +1,235 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/diff"
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+)
+
+// Rules of the spelling and terminology checks
+const (
+	// RuleTypo flags common misspellings
+	RuleTypo = "spelling/typo"
+
+	// RuleBannedTerm flags banned or deprecated terminology
+	RuleBannedTerm = "terminology/banned-term"
+)
+
+// DefaultBannedTerms maps terms replaced by more inclusive ones to their replacement
+var DefaultBannedTerms = map[string]string{
+	"whitelist":     "allowlist",
+	"whitelisted":   "allowlisted",
+	"whitelisting":  "allowlisting",
+	"blacklist":     "denylist",
+	"blacklisted":   "denylisted",
+	"blacklisting":  "denylisting",
+	"slave":         "replica",
+	"grandfathered": "legacy",
+}
+
+// DefaultTypos maps common misspellings to their correction
+var DefaultTypos = map[string]string{
+	"accomodate": "accommodate", "acheive": "achieve", "acknowledgement": "acknowledgment",
+	"adress": "address", "agressive": "aggressive", "aquire": "acquire", "arguement": "argument",
+	"assosiated": "associated", "asynchonous": "asynchronous", "authenication": "authentication",
+	"availible": "available", "begining": "beginning", "beleive": "believe", "calender": "calendar",
+	"commited": "committed", "comparision": "comparison", "compatability": "compatibility",
+	"concatinate": "concatenate", "configuraton": "configuration", "connnection": "connection",
+	"definately": "definitely", "dependancy": "dependency", "dependancies": "dependencies",
+	"desciption": "description", "destory": "destroy", "enviroment": "environment",
+	"existant": "existent", "explicitely": "explicitly", "familar": "familiar", "funtion": "function",
+	"guarentee": "guarantee", "immediatly": "immediately", "independant": "independent",
+	"initalize": "initialize", "intial": "initial", "lenght": "length", "maintainance": "maintenance",
+	"managment": "management", "mesage": "message", "neccessary": "necessary", "noticable": "noticeable",
+	"occured": "occurred", "occurence": "occurrence", "occurrance": "occurrence", "paramater": "parameter",
+	"paramter": "parameter", "permision": "permission", "persistant": "persistent", "posible": "possible",
+	"preceeding": "preceding", "priviledge": "privilege", "proccess": "process", "publically": "publicly",
+	"recieve": "receive", "recieved": "received", "recomend": "recommend", "refered": "referred",
+	"relevent": "relevant", "reponse": "response", "repositry": "repository", "requried": "required",
+	"retreive": "retrieve", "seperate": "separate", "seperator": "separator", "succesful": "successful",
+	"successfull": "successful", "sucess": "success", "supercede": "supersede", "suport": "support",
+	"teh": "the", "therefor": "therefore", "thier": "their", "threshhold": "threshold",
+	"tommorow": "tomorrow", "truely": "truly", "unecessary": "unnecessary", "untill": "until",
+	"usefull": "useful", "varaible": "variable", "visable": "visible", "wich": "which", "writting": "writing",
+}
+
+// documentationExtensions are the extensions of prose files checked in full
+var documentationExtensions = []string{".md", ".mdx", ".markdown", ".rst", ".txt", ".adoc"}
+
+var (
+	// inlineCode matches inline code spans and URLs in prose, which are not checked
+	inlineCode = regexp.MustCompile("`[^`]*`|https?://\\S+")
+
+	// word matches a word
+	word = regexp.MustCompile(`[A-Za-z]+`)
+)
+
+// CheckSpelling is a postprocess handler that checks changed string literals
+// and documentation for common misspellings and banned or deprecated terms
+// when the repository's configuration enables it. Findings are listed in the
+// summary unless the configuration asks for inline comments; summary findings
+// do not count towards the review decision.
+func CheckSpelling(ctx context.Context, job *Job) error {
+	if job.Config == nil || job.Config.Spelling == nil {
+		return nil
+	}
+	files, ok := job.parsedFiles(ctx)
+	if !ok {
+		return nil
+	}
+
+	checker := newSpellChecker(job.Config.Spelling.Terms, job.Config.Spelling.Typos, job.Config.Spelling.Ignore)
+	var findings []git.ReviewComment
+	for _, file := range files {
+		if file.Binary || file.Status == diff.FileDeleted {
+			continue
+		}
+		prose := slices.Contains(documentationExtensions, strings.ToLower(path.Ext(file.NewPath)))
+		for _, hunk := range file.Hunks {
+			fenced := false
+			for _, line := range hunk.Lines {
+				if line.Kind == diff.LineRemoved {
+					continue
+				}
+				if prose && strings.HasPrefix(strings.TrimSpace(line.Content), "```") {
+					fenced = !fenced
+					continue
+				}
+				if line.Kind != diff.LineAdded || fenced {
+					continue
+				}
+
+				var texts []string
+				if prose {
+					texts = []string{inlineCode.ReplaceAllString(line.Content, " ")}
+				} else {
+					for _, literal := range stringLiteral.FindAllString(line.Content, -1) {
+						texts = append(texts, inlineCode.ReplaceAllString(literal[1:len(literal)-1], " "))
+					}
+				}
+				for _, text := range texts {
+					for _, finding := range checker.check(text) {
+						finding.File = file.NewPath
+						finding.Line = line.NewNumber
+						findings = append(findings, finding)
+					}
+				}
+			}
+		}
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+
+	if inline := job.Config.Spelling.Inline; inline != nil && *inline {
+		job.Comments = append(job.Comments, findings...)
+		return nil
+	}
+
+	var summary strings.Builder
+	summary.WriteString(job.Summary)
+	fmt.Fprintf(&summary, "\n\n**Spelling and terminology (%d):**\n", len(findings))
+	for _, finding := range findings {
+		fmt.Fprintf(&summary, "- `%s`: %s\n", finding.Location(), finding.Content)
+	}
+	job.Summary = strings.TrimLeft(summary.String(), "\n")
+
+	return nil
+}
+
+// spellChecker finds misspellings and banned terms in text
+type spellChecker struct {
+	typos  map[string]string
+	terms  []bannedTerm
+	ignore map[string]bool
+}
+
+// bannedTerm is a banned term and the term to use instead
+type bannedTerm struct {
+	term        string
+	replacement string
+	pattern     *regexp.Regexp
+}
+
+// newSpellChecker creates a checker for the built-in misspellings and banned
+// terms extended with the configured ones
+func newSpellChecker(terms, typos map[string]string, ignore []string) *spellChecker {
+	c := &spellChecker{
+		typos:  make(map[string]string, len(DefaultTypos)+len(typos)),
+		ignore: make(map[string]bool, len(ignore)),
+	}
+	for _, w := range ignore {
+		c.ignore[strings.ToLower(w)] = true
+	}
+	for typo, correction := range DefaultTypos {
+		c.typos[typo] = correction
+	}
+	for typo, correction := range typos {
+		c.typos[strings.ToLower(typo)] = correction
+	}
+
+	banned := make(map[string]string, len(DefaultBannedTerms)+len(terms))
+	for term, replacement := range DefaultBannedTerms {
+		banned[term] = replacement
+	}
+	for term, replacement := range terms {
+		term = strings.ToLower(strings.TrimSpace(term))
+		if _, builtin := DefaultBannedTerms[term]; builtin && replacement == "" {
+			delete(banned, term)
+			continue
+		}
+		banned[term] = replacement
+	}
+	for term, replacement := range banned {
+		if c.ignore[term] {
+			continue
+		}
+		c.terms = append(c.terms, bannedTerm{
+			term:        term,
+			replacement: replacement,
+			pattern:     regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(term) + `\b`),
+		})
+	}
+	sort.Slice(c.terms, func(i, j int) bool { return c.terms[i].term < c.terms[j].term })
+
+	return c
+}
+
+// check returns the findings in a text, without their location
+func (c *spellChecker) check(text string) []git.ReviewComment {
+	var findings []git.ReviewComment
+	for _, term := range c.terms {
+		if !term.pattern.MatchString(text) {
+			continue
+		}
+		content := fmt.Sprintf("`%s` is banned terminology.", term.term)
+		if term.replacement != "" {
+			content = fmt.Sprintf("`%s` is banned terminology; use `%s` instead.", term.term, term.replacement)
+		}
+		findings = append(findings, git.ReviewComment{Severity: "minor", Rule: RuleBannedTerm, Content: content})
+	}
+
+	seen := make(map[string]bool)
+	for _, w := range word.FindAllString(text, -1) {
+		lower := strings.ToLower(w)
+		correction, ok := c.typos[lower]
+		if !ok || c.ignore[lower] || seen[lower] {
+			continue
+		}
+		seen[lower] = true
+		findings = append(findings, git.ReviewComment{
+			Severity: "suggestion",
+			Rule:     RuleTypo,
+			Content:  fmt.Sprintf("`%s` looks like a misspelling of `%s`.", w, correction),
+		})
+	}
+
+	return findings
+}
@@ -0,0 +1,129 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/llm"
+)
+
+// DefaultSummaryTokens is the default size in tokens of the summaries consolidated in one LLM call
+const DefaultSummaryTokens = 4000
+
+// summarizeReview consolidates the summaries of a chunked review into one.
+// Summaries that fit in maxTokens are consolidated in a single call. Larger
+// ones are map-reduced: the findings of each file are summarized first, then
+// the chunk and file summaries are summarized in batches of at most maxTokens,
+// and the batch summaries again, until a single summary remains.
+func summarizeReview(ctx context.Context, summarizer llm.Summarizer, summaries []string, comments []llm.ReviewComment,
+	options llm.ReviewOptions, maxTokens, concurrency int) (string, error) {
+	if llm.EstimateTokens(strings.Join(summaries, "\n\n")) <= maxTokens {
+		return summarizer.Summarize(ctx, summaries, options)
+	}
+
+	// Map: summarize the findings of each file
+	files := findingsByFile(comments)
+	fileSummaries := make([]string, len(files))
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(concurrency)
+	for i, file := range files {
+		group.Go(func() error {
+			summary, err := reduceSummaries(groupCtx, summarizer, file.findings, options, maxTokens, concurrency)
+			if err != nil {
+				return fmt.Errorf("error summarizing findings in %s: %w", file.path, err)
+			}
+			fileSummaries[i] = fmt.Sprintf("Findings in %s: %s", file.path, summary)
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return "", err
+	}
+
+	// Reduce: summarize the summaries until one remains
+	return reduceSummaries(ctx, summarizer, append(summaries, fileSummaries...), options, maxTokens, concurrency)
+}
+
+// fileFindings lists the findings of a file, one per line
+type fileFindings struct {
+	path     string
+	findings []string
+}
+
+// findingsByFile groups comments by file, sorted by path
+func findingsByFile(comments []llm.ReviewComment) []fileFindings {
+	byPath := map[string][]string{}
+	for _, comment := range comments {
+		byPath[comment.File] = append(byPath[comment.File],
+			fmt.Sprintf("[%s] line %d: %s", comment.Severity, comment.Line, comment.Content))
+	}
+
+	files := make([]fileFindings, 0, len(byPath))
+	for path, findings := range byPath {
+		files = append(files, fileFindings{path: path, findings: findings})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+
+	return files
+}
+
+// reduceSummaries summarizes texts in batches of at most maxTokens, then the
+// batch summaries, until a single summary remains
+func reduceSummaries(ctx context.Context, summarizer llm.Summarizer, texts []string,
+	options llm.ReviewOptions, maxTokens, concurrency int) (string, error) {
+	if len(texts) == 0 {
+		return "", nil
+	}
+	for {
+		batches := batchSummaries(texts, maxTokens)
+		reduced := make([]string, len(batches))
+
+		group, groupCtx := errgroup.WithContext(ctx)
+		group.SetLimit(concurrency)
+		for i, batch := range batches {
+			group.Go(func() error {
+				summary, err := summarizer.Summarize(groupCtx, batch, options)
+				if err != nil {
+					return err
+				}
+				reduced[i] = summary
+				return nil
+			})
+		}
+		if err := group.Wait(); err != nil {
+			return "", err
+		}
+
+		if len(reduced) == 1 {
+			return reduced[0], nil
+		}
+		texts = reduced
+	}
+}
+
+// batchSummaries splits texts into batches of at most maxTokens. Every batch
+// but a lone last one holds at least two texts, even if they exceed
+// maxTokens, so that each round of reduction at least halves their number.
+func batchSummaries(texts []string, maxTokens int) [][]string {
+	var batches [][]string
+	var batch []string
+	tokens := 0
+	for _, text := range texts {
+		size := llm.EstimateTokens(text)
+		if len(batch) >= 2 && tokens+size > maxTokens {
+			batches = append(batches, batch)
+			batch, tokens = nil, 0
+		}
+		batch = append(batch, text)
+		tokens += size
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
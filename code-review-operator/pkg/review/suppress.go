@@ -0,0 +1,36 @@
+package review
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/git"
+	"github.com/Shridhar2104/code-review-operator/pkg/policy"
+)
+
+// SuppressComments is a postprocess handler that drops the comments less
+// severe than the configured minimum and those of disabled rules. Unlike
+// comments over the limits, dropped comments are not listed in the summary
+// and do not count towards the decision.
+func SuppressComments(ctx context.Context, job *Job) error {
+	if job.Config == nil || (job.Config.MinSeverity == "" && len(job.Config.DisabledRules) == 0) {
+		return nil
+	}
+
+	min := policy.SeverityRank(job.Config.MinSeverity)
+	kept := make([]git.ReviewComment, 0, len(job.Comments))
+	for _, comment := range job.Comments {
+		if policy.SeverityRank(comment.Severity) < min || job.Config.RuleDisabled(comment.Rule) {
+			continue
+		}
+		kept = append(kept, comment)
+	}
+	if suppressed := len(job.Comments) - len(kept); suppressed > 0 {
+		log.FromContext(ctx).Info("suppressed comments", "minSeverity", job.Config.MinSeverity,
+			"disabledRules", job.Config.DisabledRules, "count", suppressed)
+	}
+	job.Comments = kept
+
+	return nil
+}
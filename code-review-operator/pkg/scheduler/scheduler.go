@@ -0,0 +1,237 @@
+// Package scheduler admits reviews to run under global, per-provider and
+// per-repository concurrency limits, so many reviews can run in parallel
+// without tripping the providers' API rate limits. Reviews waiting for a slot
+// are admitted highest priority first, then smallest first.
+package scheduler
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/metrics"
+)
+
+// DefaultWaitTimeout is how long a waiting review keeps its place in the
+// queue without asking again, before it is assumed gone
+const DefaultWaitTimeout = 2 * time.Minute
+
+// Limits are the most reviews run at once. Zero means no limit.
+type Limits struct {
+	// Global limits all reviews
+	Global int
+
+	// PerProvider limits the reviews of each Git provider
+	PerProvider int
+
+	// PerRepository limits the reviews of each repository
+	PerRepository int
+}
+
+// Ticket describes a review asking for a slot
+type Ticket struct {
+	// Key identifies the review across attempts
+	Key string
+
+	// Provider is the review's Git provider
+	Provider string
+
+	// Repository is the reviewed repository, as owner/name
+	Repository string
+
+	// Size is the number of changed lines. Smaller reviews are admitted first.
+	Size int
+
+	// Priority admits a review ahead of waiting reviews of lower priority,
+	// whatever their size
+	Priority int
+}
+
+// Entry is a review known to the scheduler
+type Entry struct {
+	Ticket
+
+	// Running is true once the review was admitted
+	Running bool
+
+	// Position is the place of a waiting review in the queue, starting at 1,
+	// or 0 if it is running
+	Position int
+
+	// WaitingSince is when a waiting review first asked for a slot
+	WaitingSince time.Time
+}
+
+// waiter is a ticket waiting for a slot
+type waiter struct {
+	ticket Ticket
+	since  time.Time
+	seen   time.Time
+}
+
+// Scheduler admits reviews within its limits. Admission does not block: a
+// review that is refused keeps its place in the queue and asks again later,
+// and is admitted once it is the smallest waiting review that fits the limits.
+type Scheduler struct {
+	limits      Limits
+	waitTimeout time.Duration
+
+	mu           sync.Mutex
+	running      map[string]Ticket
+	byProvider   map[string]int
+	byRepository map[string]int
+	waiting      map[string]*waiter
+}
+
+// New returns a Scheduler enforcing limits
+func New(limits Limits) *Scheduler {
+	return &Scheduler{
+		limits:       limits,
+		waitTimeout:  DefaultWaitTimeout,
+		running:      make(map[string]Ticket),
+		byProvider:   make(map[string]int),
+		byRepository: make(map[string]int),
+		waiting:      make(map[string]*waiter),
+	}
+}
+
+// TryAcquire admits a review if a slot is free for it and no smaller waiting
+// review could take the slot instead. It returns a function releasing the
+// slot once the review is done, and false if the review must ask again later.
+// A review that is already running is refused until it releases its slot.
+func (s *Scheduler) TryAcquire(ticket Ticket) (func(), bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.running[ticket.Key]; ok {
+		return nil, false
+	}
+
+	now := time.Now()
+	s.expire(now)
+	w, ok := s.waiting[ticket.Key]
+	if !ok {
+		w = &waiter{since: now}
+		s.waiting[ticket.Key] = w
+	}
+	w.ticket, w.seen = ticket, now
+
+	if !s.fits(ticket) || s.preferred(w) {
+		metrics.ReviewsQueued.Set(float64(len(s.waiting)))
+		return nil, false
+	}
+
+	delete(s.waiting, ticket.Key)
+	s.running[ticket.Key] = ticket
+	s.byProvider[ticket.Provider]++
+	s.byRepository[ticket.Repository]++
+	metrics.ReviewsQueued.Set(float64(len(s.waiting)))
+	metrics.ReviewsRunning.Set(float64(len(s.running)))
+
+	var once sync.Once
+	return func() { once.Do(func() { s.release(ticket) }) }, true
+}
+
+// Forget removes a review from the queue, such as when it is deleted or
+// finishes without running
+func (s *Scheduler) Forget(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.waiting, key)
+	metrics.ReviewsQueued.Set(float64(len(s.waiting)))
+}
+
+// release frees the slot of a running review
+func (s *Scheduler) release(ticket Ticket) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.running, ticket.Key)
+	s.byProvider[ticket.Provider]--
+	if s.byProvider[ticket.Provider] <= 0 {
+		delete(s.byProvider, ticket.Provider)
+	}
+	s.byRepository[ticket.Repository]--
+	if s.byRepository[ticket.Repository] <= 0 {
+		delete(s.byRepository, ticket.Repository)
+	}
+	metrics.ReviewsRunning.Set(float64(len(s.running)))
+}
+
+// fits reports whether a ticket fits the limits next to the running reviews
+func (s *Scheduler) fits(ticket Ticket) bool {
+	return under(len(s.running), s.limits.Global) &&
+		under(s.byProvider[ticket.Provider], s.limits.PerProvider) &&
+		under(s.byRepository[ticket.Repository], s.limits.PerRepository)
+}
+
+// Entries lists the running reviews, then the waiting ones in the order they
+// are admitted in when slots free up
+func (s *Scheduler) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(time.Now())
+	entries := make([]Entry, 0, len(s.running)+len(s.waiting))
+	for _, ticket := range s.running {
+		entries = append(entries, Entry{Ticket: ticket, Running: true})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+
+	waiting := make([]*waiter, 0, len(s.waiting))
+	for _, w := range s.waiting {
+		waiting = append(waiting, w)
+	}
+	sort.Slice(waiting, func(i, j int) bool { return before(waiting[i], waiting[j]) })
+	for i, w := range waiting {
+		entries = append(entries, Entry{Ticket: w.ticket, Position: i + 1, WaitingSince: w.since})
+	}
+
+	return entries
+}
+
+// preferred reports whether another waiting review that fits the limits goes
+// before w
+func (s *Scheduler) preferred(w *waiter) bool {
+	for key, other := range s.waiting {
+		if key == w.ticket.Key || !s.fits(other.ticket) {
+			continue
+		}
+		if before(other, w) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// before reports whether waiting review a goes before b: one of higher
+// priority, a smaller one, or one of the same size that has waited longer
+func before(a, b *waiter) bool {
+	if a.ticket.Priority != b.ticket.Priority {
+		return a.ticket.Priority > b.ticket.Priority
+	}
+	if a.ticket.Size != b.ticket.Size {
+		return a.ticket.Size < b.ticket.Size
+	}
+	if !a.since.Equal(b.since) {
+		return a.since.Before(b.since)
+	}
+
+	return a.ticket.Key < b.ticket.Key
+}
+
+// expire drops waiting reviews that stopped asking for a slot
+func (s *Scheduler) expire(now time.Time) {
+	for key, w := range s.waiting {
+		if now.Sub(w.seen) > s.waitTimeout {
+			delete(s.waiting, key)
+		}
+	}
+}
+
+// under reports whether a count is below a limit, where zero is no limit
+func under(count, limit int) bool {
+	return limit <= 0 || count < limit
+}
@@ -0,0 +1,193 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Shridhar2104/code-review-operator/pkg/httpx"
+)
+
+// DefaultReportInterval is how often aggregate usage is reported
+const DefaultReportInterval = 24 * time.Hour
+
+// Report holds the aggregate usage of the operator over a period. It contains
+// counts only: no code, repository names, users or review content.
+type Report struct {
+	// PeriodStart and PeriodEnd bound the period the counts cover
+	PeriodStart time.Time `json:"periodStart"`
+	PeriodEnd   time.Time `json:"periodEnd"`
+
+	// Reviews counts finished reviews by outcome (Completed, Failed, TimedOut)
+	Reviews map[string]int `json:"reviews"`
+
+	// Errors counts review errors by reason, including errors that were retried
+	Errors map[string]int `json:"errors"`
+
+	// Comments is the number of comments made by finished reviews
+	Comments int `json:"comments"`
+
+	// LLMProviders counts finished reviews by LLM backend
+	LLMProviders map[string]int `json:"llmProviders"`
+}
+
+// Reporter collects aggregate usage and periodically sends it to a telemetry
+// endpoint. It implements manager.Runnable. The recording methods may be
+// called on a nil Reporter, which records nothing, so callers need not check
+// whether telemetry is enabled.
+type Reporter struct {
+	endpoint   string
+	interval   time.Duration
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	report Report
+}
+
+// NewReporter creates a reporter sending usage to the given endpoint every
+// interval (defaults to DefaultReportInterval)
+func NewReporter(endpoint string, interval time.Duration) *Reporter {
+	if interval <= 0 {
+		interval = DefaultReportInterval
+	}
+
+	r := &Reporter{
+		endpoint:   endpoint,
+		interval:   interval,
+		httpClient: httpx.NewClient(10 * time.Second),
+	}
+	r.reset(time.Now())
+
+	return r
+}
+
+// RecordReview counts a finished review with its outcome, LLM backend and number of comments
+func (r *Reporter) RecordReview(outcome, llmProvider string, comments int) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Reviews[outcome]++
+	r.report.LLMProviders[llmProvider]++
+	r.report.Comments += comments
+}
+
+// RecordError counts a review error by reason
+func (r *Reporter) RecordError(reason string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Errors[reason]++
+}
+
+// Start reports usage every interval until the context is done, sending the
+// remaining usage on shutdown
+func (r *Reporter) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("telemetry")
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+			defer cancel()
+			if err := r.Send(ctx); err != nil {
+				logger.Error(err, "unable to send telemetry")
+			}
+			return nil
+		case <-ticker.C:
+			if err := r.Send(ctx); err != nil {
+				logger.Error(err, "unable to send telemetry")
+			}
+		}
+	}
+}
+
+// Send reports the usage collected since the last report. Usage that cannot
+// be sent is kept for the next report.
+func (r *Reporter) Send(ctx context.Context) error {
+	r.mu.Lock()
+	report := r.report
+	r.reset(time.Now())
+	r.mu.Unlock()
+
+	report.PeriodEnd = time.Now()
+	if err := r.post(ctx, report); err != nil {
+		r.merge(report)
+		return err
+	}
+
+	return nil
+}
+
+// post sends a report to the telemetry endpoint
+func (r *Reporter) post(ctx context.Context, report Report) error {
+	reqBytes, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("error marshaling report: %w", err)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send the request
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check for errors
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("error from telemetry endpoint: %s (status code: %d)", string(body), resp.StatusCode)
+	}
+
+	return nil
+}
+
+// reset starts a new reporting period. The caller must hold r.mu.
+func (r *Reporter) reset(now time.Time) {
+	r.report = Report{
+		PeriodStart:  now,
+		Reviews:      make(map[string]int),
+		Errors:       make(map[string]int),
+		LLMProviders: make(map[string]int),
+	}
+}
+
+// merge adds the counts of an unsent report back into the current period
+func (r *Reporter) merge(report Report) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.report.PeriodStart = report.PeriodStart
+	for outcome, n := range report.Reviews {
+		r.report.Reviews[outcome] += n
+	}
+	for reason, n := range report.Errors {
+		r.report.Errors[reason] += n
+	}
+	for provider, n := range report.LLMProviders {
+		r.report.LLMProviders[provider] += n
+	}
+	r.report.Comments += report.Comments
+}